@@ -8,6 +8,9 @@ import (
 
 	"github.com/finviz/backend/internal/api"
 	"github.com/finviz/backend/internal/db"
+	"github.com/finviz/backend/internal/prices"
+	"github.com/finviz/backend/internal/scheduler"
+	"github.com/finviz/backend/internal/snapshots"
 	"github.com/finviz/backend/internal/storage"
 )
 
@@ -23,6 +26,11 @@ func main() {
 		log.Fatalf("Failed to run migrations: %v", err)
 	}
 
+	// Resolve asset type IDs used for Plaid account mapping
+	if err := api.LoadAssetTypeIDs(); err != nil {
+		log.Printf("WARNING: %v", err)
+	}
+
 	// Initialize document storage
 	storagePath := os.Getenv("STORAGE_PATH")
 	if storagePath == "" {
@@ -37,7 +45,37 @@ func main() {
 	if err := storage.InitStorage(storagePath, encryptionKey); err != nil {
 		log.Fatalf("Failed to initialize storage: %v", err)
 	}
-	log.Printf("Document storage initialized at: %s", storagePath)
+	if os.Getenv("STORAGE_BACKEND") == storage.BackendS3 {
+		log.Printf("Document storage initialized in S3 bucket: %s", os.Getenv("S3_BUCKET"))
+	} else {
+		log.Printf("Document storage initialized at: %s", storagePath)
+	}
+
+	// Gzip simulation results saved before compression was added
+	go api.CompressLegacySimulations()
+
+	// Start periodic maintenance tasks
+	api.StartBackgroundJobs()
+
+	// Keep crypto asset values in line with market prices
+	prices.StartCryptoPricePolling()
+
+	// Monthly client reports, when a schedule is configured (e.g. "0 6 1 * *")
+	if spec := os.Getenv("MONTHLY_REPORTS_CRON"); spec != "" {
+		runner, err := scheduler.NewJobRunner("monthly reports", spec, api.GenerateAllMonthlyReports)
+		if err != nil {
+			log.Printf("WARNING: monthly reports disabled: %v", err)
+		} else {
+			go runner.Run()
+		}
+	}
+
+	// Daily net worth snapshots for the history chart
+	if runner, err := scheduler.NewJobRunner("net worth snapshots", snapshots.DailySchedule, snapshots.TakeDailySnapshots); err != nil {
+		log.Printf("WARNING: net worth snapshots disabled: %v", err)
+	} else {
+		go runner.Run()
+	}
 
 	// Create router
 	router := api.NewRouter()
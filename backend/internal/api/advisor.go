@@ -11,6 +11,7 @@ import (
 
 	"github.com/finviz/backend/internal/auth"
 	"github.com/finviz/backend/internal/db"
+	"github.com/finviz/backend/internal/logger"
 	"github.com/finviz/backend/internal/models"
 )
 
@@ -18,6 +19,7 @@ import (
 type InviteClientRequest struct {
 	Email       string `json:"email"`
 	AccessLevel string `json:"accessLevel,omitempty"` // defaults to "full"
+	NoReminder  bool   `json:"noReminder,omitempty"`  // skip the automatic reminder email
 }
 
 // CreateClientRequest is the request body for creating a client directly
@@ -37,39 +39,229 @@ type UpdateClientRequest struct {
 // ClientSummary is the response for client list with summary info
 type ClientSummary struct {
 	models.User
-	RelationshipID int       `json:"relationshipId"`
-	AccessLevel    string    `json:"accessLevel"`
-	Status         string    `json:"status"`
-	AcceptedAt     *time.Time `json:"acceptedAt,omitempty"`
-	TotalAssets    float64   `json:"totalAssets"`
-	TotalDebts     float64   `json:"totalDebts"`
-	NetWorth       float64   `json:"netWorth"`
-	LastSimulation *time.Time `json:"lastSimulation,omitempty"`
+	RelationshipID    int        `json:"relationshipId"`
+	AccessLevel       string     `json:"accessLevel"`
+	Status            string     `json:"status"`
+	AcceptedAt        *time.Time `json:"acceptedAt,omitempty"`
+	TotalAssets       float64    `json:"totalAssets"`
+	TotalDebts        float64    `json:"totalDebts"`
+	NetWorth          float64    `json:"netWorth"`
+	LastSimulation    *time.Time `json:"lastSimulation,omitempty"`
+	LastActivity      time.Time  `json:"lastActivity"`
+	LatestSuccessRate *float64   `json:"latestSuccessRate,omitempty"`
+	HealthTier        *string    `json:"healthTier,omitempty"` // nil until the client has a simulation (unless net worth is negative)
+
+	PendingDocumentRequests int  `json:"pendingDocumentRequests"`
+	OverdueDocumentRequests int  `json:"overdueDocumentRequests"`
+	HasOverdueRequests      bool `json:"hasOverdueRequests"` // a pending document request is past its due date
 }
 
-// handleListClients returns list of advisor's clients with summary info
-func handleListClients(w http.ResponseWriter, r *http.Request) {
-	user := getUserFromContext(r)
-	if user == nil {
-		respondError(w, http.StatusUnauthorized, "Not authenticated")
-		return
-	}
+// ClientListFilters echoes the filters applied to a client list
+type ClientListFilters struct {
+	HealthTier              string `json:"health_tier,omitempty"`
+	SimulationOlderThanDays int    `json:"has_simulation_older_than_days,omitempty"`
+	NeedsAttention          bool   `json:"needs_attention,omitempty"`
 
-	rows, err := db.DB.Query(`
+	HasUnfulfilledDocumentRequests bool `json:"has_unfulfilled_document_requests,omitempty"`
+}
+
+// ClientListResponse is a page of the advisor's clients
+type ClientListResponse struct {
+	Clients    []ClientSummary   `json:"clients"`
+	NextCursor *string           `json:"next_cursor"` // nil on the last page
+	Filters    ClientListFilters `json:"filters"`
+}
+
+// clientListCursor is the encrypted position of the last row on a page
+type clientListCursor struct {
+	Sort         string    `json:"s"`
+	ID           int       `json:"id"`
+	Name         string    `json:"n,omitempty"`
+	NetWorth     float64   `json:"w,omitempty"`
+	LastActivity time.Time `json:"a,omitempty"`
+}
+
+// Client list sort options, mapped to ORDER BY clauses with id as the tiebreaker
+var clientListSorts = map[string]string{
+	"name_asc":           "name ASC, id ASC",
+	"net_worth_desc":     "net_worth DESC, id DESC",
+	"last_activity_desc": "last_activity DESC, id DESC",
+}
+
+const (
+	defaultClientPageSize = 20
+	maxClientPageSize     = 100
+
+	// Simulations older than this count toward needs_attention
+	staleSimulationDays = 90
+)
+
+// Financial health tiers, from the latest simulation's success rate
+// (same thresholds as the simulation insights)
+const (
+	HealthTierCritical  = "critical"  // negative net worth or success < 50%
+	HealthTierAtRisk    = "at_risk"   // 50-75%
+	HealthTierOnTrack   = "on_track"  // 75-90%
+	HealthTierExcellent = "excellent" // 90%+
+)
+
+// clientListSource is the per-client summary that list filters, sorts and cursors operate on
+const clientListSource = `
+	SELECT t.*,
+		t.total_assets - t.total_debts as net_worth,
+		GREATEST(t.updated_at, COALESCE(t.last_simulation, t.updated_at)) as last_activity,
+		CASE
+			WHEN t.total_assets - t.total_debts < 0 THEN 'critical'
+			WHEN t.latest_success_rate IS NULL THEN NULL
+			WHEN t.latest_success_rate < 50 THEN 'critical'
+			WHEN t.latest_success_rate < 75 THEN 'at_risk'
+			WHEN t.latest_success_rate < 90 THEN 'on_track'
+			ELSE 'excellent'
+		END as health_tier
+	FROM (
 		SELECT
 			u.id, u.email, u.name, u.role, u.created_at, u.updated_at,
 			ac.id as relationship_id, ac.access_level, ac.status, ac.accepted_at,
-			COALESCE(SUM(a.current_value), 0) as total_assets,
+			COALESCE((SELECT SUM(current_value) FROM assets WHERE user_id = u.id AND deleted_at IS NULL), 0) as total_assets,
 			COALESCE((SELECT SUM(current_balance) FROM debts WHERE user_id = u.id), 0) as total_debts,
-			(SELECT MAX(created_at) FROM simulation_history WHERE user_id = u.id) as last_simulation
+			(SELECT MAX(created_at) FROM simulation_history WHERE user_id = u.id) as last_simulation,
+			(SELECT success_rate FROM simulation_history WHERE user_id = u.id
+			 ORDER BY created_at DESC, id DESC LIMIT 1) as latest_success_rate,
+			(SELECT COUNT(*) FROM document_requests
+			 WHERE advisor_id = ac.advisor_id AND client_id = u.id AND status = 'pending') as pending_document_requests,
+			(SELECT COUNT(*) FROM document_requests
+			 WHERE advisor_id = ac.advisor_id AND client_id = u.id AND status = 'pending'
+			   AND due_date < CURDATE()) as overdue_document_requests
 		FROM advisor_clients ac
 		JOIN users u ON ac.client_id = u.id
-		LEFT JOIN assets a ON a.user_id = u.id
 		WHERE ac.advisor_id = ? AND ac.status != 'revoked'
-		GROUP BY u.id, ac.id
-		ORDER BY u.name
-	`, user.ID)
+	) t`
+
+// handleListClients returns a page of the advisor's clients with summary info
+func handleListClients(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+	if user == nil {
+		respondError(w, http.StatusUnauthorized, "Not authenticated")
+		return
+	}
+
+	query := r.URL.Query()
+
+	sort := query.Get("sort")
+	if sort == "" {
+		sort = "name_asc"
+	}
+	orderBy, ok := clientListSorts[sort]
+	if !ok {
+		respondError(w, http.StatusBadRequest, "Invalid sort. Use 'name_asc', 'net_worth_desc', or 'last_activity_desc'")
+		return
+	}
+
+	limit := defaultClientPageSize
+	if v := query.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			respondError(w, http.StatusBadRequest, "Invalid limit")
+			return
+		}
+		limit = n
+	}
+	if limit > maxClientPageSize {
+		limit = maxClientPageSize
+	}
+
+	// Filters apply to both the page and the total count
+	var filters ClientListFilters
+	var conditions []string
+	var filterArgs []interface{}
+
+	if v := query.Get("health_tier"); v != "" {
+		if v != HealthTierCritical && v != HealthTierAtRisk && v != HealthTierOnTrack && v != HealthTierExcellent {
+			respondError(w, http.StatusBadRequest, "Invalid health_tier. Use 'critical', 'at_risk', 'on_track', or 'excellent'")
+			return
+		}
+		filters.HealthTier = v
+		conditions = append(conditions, "health_tier = ?")
+		filterArgs = append(filterArgs, v)
+	}
+
+	if v := query.Get("has_simulation_older_than_days"); v != "" {
+		days, err := strconv.Atoi(v)
+		if err != nil || days < 1 {
+			respondError(w, http.StatusBadRequest, "Invalid has_simulation_older_than_days")
+			return
+		}
+		filters.SimulationOlderThanDays = days
+		// Clients who have never run a simulation are stale too
+		conditions = append(conditions, "(last_simulation IS NULL OR last_simulation < ?)")
+		filterArgs = append(filterArgs, time.Now().AddDate(0, 0, -days))
+	}
+
+	if query.Get("has_unfulfilled_document_requests") == "true" {
+		filters.HasUnfulfilledDocumentRequests = true
+		conditions = append(conditions, "pending_document_requests > 0")
+	}
+
+	if query.Get("needs_attention") == "true" {
+		filters.NeedsAttention = true
+		conditions = append(conditions, "(health_tier = ? OR last_simulation IS NULL OR last_simulation < ?)")
+		filterArgs = append(filterArgs, HealthTierCritical, time.Now().AddDate(0, 0, -staleSimulationDays))
+	}
+
+	var totalCount int
+	countWhere := ""
+	if len(conditions) > 0 {
+		countWhere = "WHERE " + strings.Join(conditions, " AND ")
+	}
+	countArgs := append([]interface{}{user.ID}, filterArgs...)
+	if err := db.DB.QueryRow(
+		"SELECT COUNT(*) FROM ("+clientListSource+") c "+countWhere, countArgs...,
+	).Scan(&totalCount); err != nil {
+		logger.FromContext(r.Context()).Errorf("Error counting clients: %v", err)
+		respondError(w, http.StatusInternalServerError, "Failed to count clients")
+		return
+	}
+
+	// Keyset condition continues strictly after the last row of the previous page
+	args := append([]interface{}{user.ID}, filterArgs...)
+	if v := query.Get("cursor"); v != "" {
+		var cursor clientListCursor
+		if err := auth.DecodeCursor(v, &cursor); err != nil || cursor.Sort != sort {
+			respondError(w, http.StatusBadRequest, "Invalid cursor")
+			return
+		}
+		switch sort {
+		case "name_asc":
+			conditions = append(conditions, "(name > ? OR (name = ? AND id > ?))")
+			args = append(args, cursor.Name, cursor.Name, cursor.ID)
+		case "net_worth_desc":
+			conditions = append(conditions, "(net_worth < ? OR (net_worth = ? AND id < ?))")
+			args = append(args, cursor.NetWorth, cursor.NetWorth, cursor.ID)
+		case "last_activity_desc":
+			conditions = append(conditions, "(last_activity < ? OR (last_activity = ? AND id < ?))")
+			args = append(args, cursor.LastActivity, cursor.LastActivity, cursor.ID)
+		}
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	// Fetch one extra row to know whether another page exists
+	args = append(args, limit+1)
+	rows, err := db.DB.Query(`
+		SELECT id, email, name, role, created_at, updated_at,
+		       relationship_id, access_level, status, accepted_at,
+		       total_assets, total_debts, net_worth, last_simulation, last_activity,
+		       latest_success_rate, health_tier, pending_document_requests, overdue_document_requests
+		FROM (`+clientListSource+`) c
+		`+where+`
+		ORDER BY `+orderBy+`
+		LIMIT ?
+	`, args...)
 	if err != nil {
+		logger.FromContext(r.Context()).Errorf("Error fetching clients: %v", err)
 		respondError(w, http.StatusInternalServerError, "Failed to fetch clients")
 		return
 	}
@@ -83,17 +275,38 @@ func handleListClients(w http.ResponseWriter, r *http.Request) {
 			&client.ID, &client.Email, &client.Name, &client.Role,
 			&client.CreatedAt, &client.UpdatedAt,
 			&client.RelationshipID, &client.AccessLevel, &client.Status, &client.AcceptedAt,
-			&client.TotalAssets, &client.TotalDebts, &lastSim,
+			&client.TotalAssets, &client.TotalDebts, &client.NetWorth, &lastSim, &client.LastActivity,
+			&client.LatestSuccessRate, &client.HealthTier,
+			&client.PendingDocumentRequests, &client.OverdueDocumentRequests,
 		)
 		if err != nil {
 			continue
 		}
-		client.NetWorth = client.TotalAssets - client.TotalDebts
 		client.LastSimulation = lastSim
+		client.HasOverdueRequests = client.OverdueDocumentRequests > 0
 		clients = append(clients, client)
 	}
 
-	respondJSON(w, http.StatusOK, clients)
+	resp := ClientListResponse{Clients: clients, Filters: filters}
+	if len(clients) > limit {
+		resp.Clients = clients[:limit]
+		last := resp.Clients[limit-1]
+		next, err := auth.EncodeCursor(clientListCursor{
+			Sort:         sort,
+			ID:           last.ID,
+			Name:         last.Name,
+			NetWorth:     last.NetWorth,
+			LastActivity: last.LastActivity,
+		})
+		if err != nil {
+			respondError(w, http.StatusInternalServerError, "Failed to build cursor")
+			return
+		}
+		resp.NextCursor = &next
+	}
+
+	w.Header().Set("X-Total-Count", strconv.Itoa(totalCount))
+	respondJSON(w, http.StatusOK, resp)
 }
 
 // handleInviteClient sends an invitation to a client
@@ -170,10 +383,10 @@ func handleInviteClient(w http.ResponseWriter, r *http.Request) {
 
 	// User doesn't exist - create invitation
 	_, err = db.DB.Exec(`
-		INSERT INTO client_invitations (advisor_id, client_email, invitation_token, expires_at)
-		VALUES (?, ?, ?, ?)
-		ON DUPLICATE KEY UPDATE invitation_token = ?, expires_at = ?, status = 'pending'
-	`, user.ID, req.Email, token, expiresAt, token, expiresAt)
+		INSERT INTO client_invitations (advisor_id, client_email, invitation_token, expires_at, no_reminder)
+		VALUES (?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE invitation_token = ?, expires_at = ?, status = 'pending', no_reminder = ?, last_reminder_at = NULL
+	`, user.ID, req.Email, token, expiresAt, req.NoReminder, token, expiresAt, req.NoReminder)
 
 	if err != nil {
 		respondError(w, http.StatusInternalServerError, "Failed to create invitation")
@@ -348,6 +561,7 @@ func handleUpdateClient(w http.ResponseWriter, r *http.Request) {
 		respondError(w, http.StatusInternalServerError, "Failed to update relationship")
 		return
 	}
+	db.InvalidateClientAccess(user.ID, clientID)
 
 	respondJSON(w, http.StatusOK, map[string]string{"message": "Client updated"})
 }
@@ -376,6 +590,7 @@ func handleRemoveClient(w http.ResponseWriter, r *http.Request) {
 		respondError(w, http.StatusInternalServerError, "Failed to remove client")
 		return
 	}
+	db.InvalidateClientAccess(user.ID, clientID)
 
 	rowsAffected, _ := result.RowsAffected()
 	if rowsAffected == 0 {
@@ -772,6 +987,7 @@ func handleDeleteAdvisor(w http.ResponseWriter, r *http.Request) {
 		respondError(w, http.StatusInternalServerError, "Failed to revoke client relationships")
 		return
 	}
+	db.InvalidateAdvisorAccess(advisorID)
 
 	// Delete the advisor (or convert to client if you want to preserve account)
 	_, err = db.DB.Exec("DELETE FROM users WHERE id = ?", advisorID)
@@ -936,6 +1152,7 @@ func handleAssignClient(w http.ResponseWriter, r *http.Request) {
 				respondError(w, http.StatusInternalServerError, "Failed to reactivate relationship")
 				return
 			}
+			db.InvalidateClientAccess(req.AdvisorID, req.ClientID)
 			respondJSON(w, http.StatusOK, map[string]string{"message": "Client relationship reactivated"})
 			return
 		}
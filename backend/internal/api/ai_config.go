@@ -0,0 +1,156 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/finviz/backend/internal/claude"
+	"github.com/finviz/backend/internal/db"
+	"github.com/finviz/backend/internal/models"
+)
+
+// handleGetAIConfig returns the advisor's Aurelia customization
+func handleGetAIConfig(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+	if user == nil {
+		respondError(w, http.StatusUnauthorized, "Not authenticated")
+		return
+	}
+
+	config, err := fetchAdvisorAIConfig(user.ID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch AI config")
+		return
+	}
+	if config == nil {
+		// No customization yet - return an empty config so the UI can render defaults
+		config = &models.AdvisorAIConfig{AdvisorID: user.ID}
+	}
+
+	respondJSON(w, http.StatusOK, config)
+}
+
+// handleUpdateAIConfig creates or replaces the advisor's Aurelia customization
+func handleUpdateAIConfig(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+	if user == nil {
+		respondError(w, http.StatusUnauthorized, "Not authenticated")
+		return
+	}
+
+	var req models.UpdateAdvisorAIConfigRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	// Validate tool names against the known Aurelia tool set
+	var enabledToolsJSON interface{}
+	if req.EnabledTools != nil {
+		known := make(map[string]bool)
+		for _, t := range claude.GetAureliaTools() {
+			known[t.Name] = true
+		}
+		for _, name := range req.EnabledTools {
+			if !known[name] {
+				respondError(w, http.StatusBadRequest, "Unknown tool: "+name)
+				return
+			}
+		}
+		toolsBytes, err := json.Marshal(req.EnabledTools)
+		if err != nil {
+			respondError(w, http.StatusInternalServerError, "Failed to serialize enabled tools")
+			return
+		}
+		enabledToolsJSON = string(toolsBytes)
+	}
+
+	_, err := db.DB.Exec(`
+		INSERT INTO advisor_ai_config
+		(advisor_id, firm_name_for_ai, custom_system_prompt_suffix, enabled_tools, disclaimer_text)
+		VALUES (?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE
+			firm_name_for_ai = VALUES(firm_name_for_ai),
+			custom_system_prompt_suffix = VALUES(custom_system_prompt_suffix),
+			enabled_tools = VALUES(enabled_tools),
+			disclaimer_text = VALUES(disclaimer_text)
+	`, user.ID, req.FirmNameForAI, req.CustomSystemPromptSuffix, enabledToolsJSON, req.DisclaimerText)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to save AI config")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{"message": "AI config saved"})
+}
+
+// fetchAdvisorAIConfig loads an advisor's AI config, returning nil if none is set
+func fetchAdvisorAIConfig(advisorID int) (*models.AdvisorAIConfig, error) {
+	var config models.AdvisorAIConfig
+	var enabledTools sql.NullString
+	err := db.DB.QueryRow(`
+		SELECT id, advisor_id, firm_name_for_ai, custom_system_prompt_suffix,
+		       enabled_tools, disclaimer_text, created_at, updated_at
+		FROM advisor_ai_config
+		WHERE advisor_id = ?
+	`, advisorID).Scan(
+		&config.ID, &config.AdvisorID, &config.FirmNameForAI, &config.CustomSystemPromptSuffix,
+		&enabledTools, &config.DisclaimerText, &config.CreatedAt, &config.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if enabledTools.Valid {
+		if err := json.Unmarshal([]byte(enabledTools.String), &config.EnabledTools); err != nil {
+			return nil, err
+		}
+	}
+
+	return &config, nil
+}
+
+// fetchClientAIConfig loads the AI config of a client's active advisor, if any
+func fetchClientAIConfig(clientID int) (*models.AdvisorAIConfig, error) {
+	var advisorID int
+	err := db.DB.QueryRow(`
+		SELECT advisor_id FROM advisor_clients
+		WHERE client_id = ? AND status = 'active'
+		ORDER BY accepted_at DESC
+		LIMIT 1
+	`, clientID).Scan(&advisorID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return fetchAdvisorAIConfig(advisorID)
+}
+
+// buildAureliaPrompt appends an advisor's firm customizations to the base prompt
+func buildAureliaPrompt(basePrompt string, config *models.AdvisorAIConfig) string {
+	if config == nil {
+		return basePrompt
+	}
+
+	var sb strings.Builder
+	sb.WriteString(basePrompt)
+
+	if config.FirmNameForAI != nil && *config.FirmNameForAI != "" {
+		sb.WriteString("\n\nYou are assisting clients of " + *config.FirmNameForAI + ". Refer to the firm by this name when relevant.")
+	}
+	if config.CustomSystemPromptSuffix != nil && *config.CustomSystemPromptSuffix != "" {
+		sb.WriteString("\n\n" + *config.CustomSystemPromptSuffix)
+	}
+	if config.DisclaimerText != nil && *config.DisclaimerText != "" {
+		sb.WriteString("\n\nWhen providing a disclaimer, use the following text: " + *config.DisclaimerText)
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,272 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/finviz/backend/internal/auth"
+	"github.com/finviz/backend/internal/db"
+	"github.com/finviz/backend/internal/logger"
+	"github.com/finviz/backend/internal/models"
+)
+
+// Characters of a key kept for display, including the fv_ prefix
+const apiKeyDisplayPrefixLen = 11
+
+// apiKeyResources maps the first path segment after /api/ (or after
+// /api/advisor/clients/{clientId}/ for client context routes) to the resource
+// named in scopes. Paths not listed here can't be reached with an API key;
+// that includes /api/me, so a key can't manage keys or credentials.
+var apiKeyResources = map[string]string{
+	"assets":             "assets",
+	"debts":              "debts",
+	"monte-carlo":        "simulations",
+	"simulate":           "simulations",
+	"simulations":        "simulations",
+	"transactions":       "transactions",
+	"import":             "transactions",
+	"transaction-rules":  "transactions",
+	"documents":          "documents",
+	"document-requests":  "documents",
+	"signature-requests": "documents",
+	"goals":              "goals",
+	"notes":              "notes",
+	"messages":           "messages",
+	"reports":            "reports",
+	"dossier-export":     "reports",
+	"tax":                "tax",
+	"clients":            "clients",
+	"invitations":        "clients",
+}
+
+// validAPIKeyScope reports whether scope is read:<resource> or write:<resource>
+// for a known resource
+func validAPIKeyScope(scope string) bool {
+	action, resource, ok := strings.Cut(scope, ":")
+	if !ok || (action != "read" && action != "write") {
+		return false
+	}
+	for _, r := range apiKeyResources {
+		if r == resource {
+			return true
+		}
+	}
+	return false
+}
+
+// requiredAPIKeyScope returns the scope a request needs: read for GET and
+// HEAD, write otherwise. Returns "" for paths API keys can't use.
+func requiredAPIKeyScope(r *http.Request) string {
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(parts) < 2 || parts[0] != "api" {
+		return ""
+	}
+
+	segment := parts[1]
+	if segment == "advisor" && len(parts) >= 3 {
+		segment = parts[2]
+		// /api/advisor/clients/{clientId}/<resource>/...
+		if segment == "clients" && len(parts) >= 5 {
+			segment = parts[4]
+		}
+	}
+
+	resource, ok := apiKeyResources[segment]
+	if !ok {
+		return ""
+	}
+	if r.Method == http.MethodGet || r.Method == http.MethodHead {
+		return "read:" + resource
+	}
+	return "write:" + resource
+}
+
+// authenticateAPIKey looks up an API key and checks it grants the scope the
+// request needs. Writes the error response and returns nil on failure.
+func authenticateAPIKey(w http.ResponseWriter, r *http.Request, key string) *models.User {
+	var keyID int
+	var scopesJSON string
+	var user models.User
+	err := db.DB.QueryRow(`
+		SELECT k.id, k.scopes, u.id, u.email, u.name, u.role, u.created_at, u.updated_at
+		FROM api_keys k JOIN users u ON u.id = k.user_id
+		WHERE k.key_hash = ? AND k.revoked_at IS NULL AND (k.expires_at IS NULL OR k.expires_at > NOW())
+	`, auth.HashAPIKey(key)).Scan(&keyID, &scopesJSON, &user.ID, &user.Email, &user.Name, &user.Role,
+		&user.CreatedAt, &user.UpdatedAt)
+	if err != nil {
+		respondError(w, http.StatusUnauthorized, "Invalid or expired API key")
+		return nil
+	}
+
+	required := requiredAPIKeyScope(r)
+	if required == "" {
+		respondError(w, http.StatusForbidden, "This endpoint is not available to API keys")
+		return nil
+	}
+
+	var scopes []string
+	json.Unmarshal([]byte(scopesJSON), &scopes)
+	granted := false
+	for _, scope := range scopes {
+		if scope == required {
+			granted = true
+			break
+		}
+	}
+	if !granted {
+		respondError(w, http.StatusForbidden, "API key is missing scope "+required)
+		return nil
+	}
+
+	if _, err := db.DB.Exec("UPDATE api_keys SET last_used_at = NOW() WHERE id = ?", keyID); err != nil {
+		logger.FromContext(r.Context()).Errorf("Error updating API key %d last use: %v", keyID, err)
+	}
+	return &user
+}
+
+// handleCreateAPIKey creates an API key for the current advisor. The full key
+// is only ever returned here.
+// POST /api/me/api-keys
+func handleCreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+	if user == nil || !user.IsAdvisor() {
+		respondError(w, http.StatusForbidden, "Only advisors can create API keys")
+		return
+	}
+
+	var req models.CreateAPIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" || len(req.Name) > 100 {
+		respondError(w, http.StatusBadRequest, "Name is required and must be at most 100 characters")
+		return
+	}
+	if len(req.Scopes) == 0 {
+		respondError(w, http.StatusBadRequest, "At least one scope is required")
+		return
+	}
+	for _, scope := range req.Scopes {
+		if !validAPIKeyScope(scope) {
+			respondError(w, http.StatusBadRequest, "Invalid scope: "+scope)
+			return
+		}
+	}
+
+	var expiresAt *time.Time
+	if req.ExpiresInDays != nil {
+		if *req.ExpiresInDays <= 0 {
+			respondError(w, http.StatusBadRequest, "expiresInDays must be positive")
+			return
+		}
+		t := time.Now().AddDate(0, 0, *req.ExpiresInDays)
+		expiresAt = &t
+	}
+
+	key, err := auth.GenerateAPIKey()
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to generate API key")
+		return
+	}
+	scopesJSON, _ := json.Marshal(req.Scopes)
+
+	result, err := db.DB.Exec(`
+		INSERT INTO api_keys (user_id, key_hash, key_prefix, name, scopes, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, user.ID, auth.HashAPIKey(key), key[:apiKeyDisplayPrefixLen], req.Name, string(scopesJSON), expiresAt)
+	if err != nil {
+		logger.FromContext(r.Context()).Errorf("Error creating API key for user %d: %v", user.ID, err)
+		respondError(w, http.StatusInternalServerError, "Failed to create API key")
+		return
+	}
+	keyID, _ := result.LastInsertId()
+
+	logAuditEvent(r, user.ID, AuditActionAPIKeyCreated, fmt.Sprintf("api_key_id=%d scopes=%s", keyID, strings.Join(req.Scopes, ",")))
+
+	respondJSON(w, http.StatusCreated, models.CreateAPIKeyResponse{
+		APIKey: models.APIKey{
+			ID:        int(keyID),
+			Name:      req.Name,
+			KeyPrefix: key[:apiKeyDisplayPrefixLen],
+			Scopes:    req.Scopes,
+			ExpiresAt: expiresAt,
+			CreatedAt: time.Now(),
+		},
+		Key: key,
+	})
+}
+
+// handleListAPIKeys lists the current user's active API keys
+// GET /api/me/api-keys
+func handleListAPIKeys(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+	if user == nil {
+		respondError(w, http.StatusUnauthorized, "Not authenticated")
+		return
+	}
+
+	rows, err := db.DB.Query(`
+		SELECT id, name, key_prefix, scopes, last_used_at, expires_at, created_at
+		FROM api_keys
+		WHERE user_id = ? AND revoked_at IS NULL
+		ORDER BY created_at DESC
+	`, user.ID)
+	if err != nil {
+		logger.FromContext(r.Context()).Errorf("Error listing API keys for user %d: %v", user.ID, err)
+		respondError(w, http.StatusInternalServerError, "Failed to fetch API keys")
+		return
+	}
+	defer rows.Close()
+
+	keys := []models.APIKey{}
+	for rows.Next() {
+		var k models.APIKey
+		var scopesJSON string
+		if err := rows.Scan(&k.ID, &k.Name, &k.KeyPrefix, &scopesJSON, &k.LastUsedAt, &k.ExpiresAt, &k.CreatedAt); err != nil {
+			continue
+		}
+		json.Unmarshal([]byte(scopesJSON), &k.Scopes)
+		keys = append(keys, k)
+	}
+
+	respondJSON(w, http.StatusOK, keys)
+}
+
+// handleRevokeAPIKey permanently disables one of the current user's API keys
+// DELETE /api/me/api-keys/{id}
+func handleRevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+	if user == nil {
+		respondError(w, http.StatusUnauthorized, "Not authenticated")
+		return
+	}
+
+	keyID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid API key ID")
+		return
+	}
+
+	result, err := db.DB.Exec(`
+		UPDATE api_keys SET revoked_at = NOW() WHERE id = ? AND user_id = ? AND revoked_at IS NULL
+	`, keyID, user.ID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to revoke API key")
+		return
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		respondError(w, http.StatusNotFound, "API key not found")
+		return
+	}
+
+	logAuditEvent(r, user.ID, AuditActionAPIKeyRevoked, fmt.Sprintf("api_key_id=%d", keyID))
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -5,11 +5,32 @@ import (
 	"encoding/json"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/finviz/backend/internal/db"
 	"github.com/finviz/backend/internal/models"
+	"github.com/finviz/backend/internal/prices"
 )
 
+// maxTickerSymbolLength matches assets.ticker_symbol
+const maxTickerSymbolLength = 20
+
+// normalizeTickerSymbol upper-cases a ticker, returning nil for an empty one
+// and a message if it's invalid
+func normalizeTickerSymbol(ticker *string) (*string, string) {
+	if ticker == nil {
+		return nil, ""
+	}
+	symbol := strings.ToUpper(strings.TrimSpace(*ticker))
+	if symbol == "" {
+		return nil, ""
+	}
+	if len(symbol) > maxTickerSymbolLength || strings.ContainsAny(symbol, " ,") {
+		return nil, "tickerSymbol must be a single symbol of at most 20 characters"
+	}
+	return &symbol, ""
+}
+
 func handleGetAssetTypes(w http.ResponseWriter, r *http.Request) {
 	rows, err := db.DB.Query(`SELECT id, name, default_return, default_volatility, created_at FROM asset_types ORDER BY name`)
 	if err != nil {
@@ -42,14 +63,23 @@ func handleGetAssets(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// ?deleted=true lists the assets that can still be restored instead
+	filter := "a.deleted_at IS NULL"
+	if r.URL.Query().Get("deleted") == "true" {
+		filter = "a.deleted_at >= NOW() - INTERVAL " + strconv.Itoa(deletedRecoveryDays) + " DAY"
+	}
+
+	// Crypto assets are valued at the latest polled price
 	rows, err := db.DB.Query(`
 		SELECT a.id, a.user_id, a.name, a.type_id, a.current_value, a.custom_return, a.custom_volatility,
-		       a.plaid_account_id, a.created_at, a.updated_at, t.id, t.name, t.default_return, t.default_volatility
+		       a.plaid_account_id, a.ticker_symbol, a.quantity, a.created_at, a.updated_at, a.deleted_at,
+		       t.id, t.name, t.default_return, t.default_volatility, p.price_usd, p.fetched_at
 		FROM assets a
 		JOIN asset_types t ON a.type_id = t.id
-		WHERE a.user_id = ?
+		LEFT JOIN crypto_prices p ON t.name = ? AND p.symbol = UPPER(a.ticker_symbol)
+		WHERE a.user_id = ? AND `+filter+`
 		ORDER BY a.name
-	`, userID)
+	`, prices.CryptoAssetType, userID)
 	if err != nil {
 		respondError(w, http.StatusInternalServerError, err.Error())
 		return
@@ -62,9 +92,12 @@ func handleGetAssets(w http.ResponseWriter, r *http.Request) {
 		var t models.AssetType
 		var customReturn, customVolatility sql.NullFloat64
 		var plaidAccountID sql.NullString
+		var price sql.NullFloat64
+		var priceFetchedAt sql.NullTime
 		if err := rows.Scan(
 			&a.ID, &a.UserID, &a.Name, &a.TypeID, &a.CurrentValue, &customReturn, &customVolatility,
-			&plaidAccountID, &a.CreatedAt, &a.UpdatedAt, &t.ID, &t.Name, &t.DefaultReturn, &t.DefaultVolatility,
+			&plaidAccountID, &a.TickerSymbol, &a.Quantity, &a.CreatedAt, &a.UpdatedAt, &a.DeletedAt,
+			&t.ID, &t.Name, &t.DefaultReturn, &t.DefaultVolatility, &price, &priceFetchedAt,
 		); err != nil {
 			respondError(w, http.StatusInternalServerError, err.Error())
 			return
@@ -78,6 +111,11 @@ func handleGetAssets(w http.ResponseWriter, r *http.Request) {
 		if plaidAccountID.Valid {
 			a.PlaidAccountID = &plaidAccountID.String
 		}
+		if price.Valid {
+			a.PriceUSD = &price.Float64
+			a.PriceFetchedAt = &priceFetchedAt.Time
+			a.CurrentValue = roundCents(price.Float64 * a.Quantity)
+		}
 		a.AssetType = &t
 		assets = append(assets, a)
 	}
@@ -112,9 +150,23 @@ func handleCreateAsset(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	quantity := 1.0
+	if req.Quantity != nil {
+		quantity = *req.Quantity
+	}
+	if quantity <= 0 {
+		respondError(w, http.StatusBadRequest, "quantity must be positive")
+		return
+	}
+	ticker, msg := normalizeTickerSymbol(req.TickerSymbol)
+	if msg != "" {
+		respondError(w, http.StatusBadRequest, msg)
+		return
+	}
+
 	result, err := db.DB.Exec(
-		`INSERT INTO assets (user_id, name, type_id, current_value, custom_return, custom_volatility) VALUES (?, ?, ?, ?, ?, ?)`,
-		userID, req.Name, req.TypeID, req.CurrentValue, req.CustomReturn, req.CustomVolatility,
+		`INSERT INTO assets (user_id, name, type_id, current_value, custom_return, custom_volatility, ticker_symbol, quantity) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		userID, req.Name, req.TypeID, req.CurrentValue, req.CustomReturn, req.CustomVolatility, ticker, quantity,
 	)
 	if err != nil {
 		respondError(w, http.StatusInternalServerError, err.Error())
@@ -174,8 +226,25 @@ func handleUpdateAsset(w http.ResponseWriter, r *http.Request) {
 		query += ", custom_volatility = ?"
 		args = append(args, *req.CustomVolatility)
 	}
+	if req.TickerSymbol != nil {
+		ticker, msg := normalizeTickerSymbol(req.TickerSymbol)
+		if msg != "" {
+			respondError(w, http.StatusBadRequest, msg)
+			return
+		}
+		query += ", ticker_symbol = ?"
+		args = append(args, ticker)
+	}
+	if req.Quantity != nil {
+		if *req.Quantity <= 0 {
+			respondError(w, http.StatusBadRequest, "quantity must be positive")
+			return
+		}
+		query += ", quantity = ?"
+		args = append(args, *req.Quantity)
+	}
 
-	query += " WHERE id = ? AND user_id = ?"
+	query += " WHERE id = ? AND user_id = ? AND deleted_at IS NULL"
 	args = append(args, id, userID)
 
 	result, err := db.DB.Exec(query, args...)
@@ -212,7 +281,9 @@ func handleDeleteAsset(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	result, err := db.DB.Exec("DELETE FROM assets WHERE id = ? AND user_id = ?", id, userID)
+	// Soft delete so the asset can be restored; Plaid-linked assets also stay
+	// hidden rather than being re-created by the next sync
+	result, err := db.DB.Exec("UPDATE assets SET deleted_at = NOW() WHERE id = ? AND user_id = ? AND deleted_at IS NULL", id, userID)
 	if err != nil {
 		respondError(w, http.StatusInternalServerError, err.Error())
 		return
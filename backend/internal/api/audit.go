@@ -0,0 +1,97 @@
+package api
+
+import (
+	"net"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/finviz/backend/internal/db"
+	"github.com/finviz/backend/internal/logger"
+)
+
+// Audit action constants
+const (
+	AuditActionPasswordChanged        = "password_changed"
+	AuditActionBankStatementImported  = "bank_statement_imported"
+	AuditActionDossierExported        = "dossier_exported"
+	AuditActionConversationArchived   = "conversation_archived"
+	AuditActionConversationUnarchived = "conversation_unarchived"
+	AuditActionConversationExported   = "conversation_exported"
+	AuditActionMFAEnabled             = "mfa_enabled"
+	AuditActionMFADisabled            = "mfa_disabled"
+	AuditActionMFABackupCodeUsed      = "mfa_backup_code_used"
+	AuditActionRefreshTokenReused     = "refresh_token_reused"
+	AuditActionAccountUnlocked        = "account_unlocked"
+	AuditActionDataExported           = "data_exported"
+	AuditActionAPIKeyCreated          = "api_key_created"
+	AuditActionAPIKeyRevoked          = "api_key_revoked"
+)
+
+// logAuditEvent records a security-relevant event for a user
+func logAuditEvent(r *http.Request, userID int, action, details string) {
+	var detailsArg interface{}
+	if details != "" {
+		detailsArg = details
+	}
+
+	_, err := db.DB.Exec(
+		"INSERT INTO audit_log (user_id, action, ip_address, details) VALUES (?, ?, ?, ?)",
+		userID, action, getClientIP(r), detailsArg,
+	)
+	if err != nil {
+		logger.FromContext(r.Context()).Errorf("Error writing audit log for user %d (%s): %v", userID, action, err)
+	}
+}
+
+// getClientIP returns the originating client IP. X-Forwarded-For and
+// X-Real-IP are only honored when the connection comes from a proxy listed
+// in TRUSTED_PROXIES (comma-separated IPs or CIDRs); otherwise anyone could
+// set them to pick the IP recorded in the audit log.
+func getClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	trusted := os.Getenv("TRUSTED_PROXIES")
+	if !ipInList(host, trusted) {
+		return host
+	}
+
+	if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+		// Each proxy appends the address it received the request from, so
+		// the client is the last entry that isn't one of our proxies
+		entries := strings.Split(forwarded, ",")
+		for i := len(entries) - 1; i >= 0; i-- {
+			ip := strings.TrimSpace(entries[i])
+			if i == 0 || !ipInList(ip, trusted) {
+				return ip
+			}
+		}
+	}
+	if realIP := r.Header.Get("X-Real-IP"); realIP != "" {
+		return realIP
+	}
+	return host
+}
+
+// ipInList reports whether ip matches an entry of a comma-separated list of
+// IPs and CIDRs
+func ipInList(ip, list string) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+	for _, entry := range strings.Split(list, ",") {
+		entry = strings.TrimSpace(entry)
+		if _, network, err := net.ParseCIDR(entry); err == nil {
+			if network.Contains(parsed) {
+				return true
+			}
+		} else if allowed := net.ParseIP(entry); allowed != nil && allowed.Equal(parsed) {
+			return true
+		}
+	}
+	return false
+}
@@ -2,6 +2,7 @@ package api
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
 	"net/http"
 	"strconv"
@@ -9,15 +10,16 @@ import (
 
 	"github.com/finviz/backend/internal/auth"
 	"github.com/finviz/backend/internal/db"
+	"github.com/finviz/backend/internal/logger"
 	"github.com/finviz/backend/internal/models"
 )
 
 type contextKey string
 
 const (
-	userContextKey       contextKey = "user"
-	clientContextKey     contextKey = "client"       // The client being acted upon (for advisors)
-	actingAsAdvisorKey   contextKey = "actingAsAdvisor"
+	userContextKey     contextKey = "user"
+	clientContextKey   contextKey = "client" // The client being acted upon (for advisors)
+	actingAsAdvisorKey contextKey = "actingAsAdvisor"
 )
 
 func handleRegister(w http.ResponseWriter, r *http.Request) {
@@ -79,21 +81,11 @@ func handleRegister(w http.ResponseWriter, r *http.Request) {
 
 	userID, _ := result.LastInsertId()
 
-	// Generate token
-	token, err := auth.GenerateToken(int(userID), req.Email)
-	if err != nil {
-		respondError(w, http.StatusInternalServerError, "Failed to generate token")
-		return
-	}
-
-	respondJSON(w, http.StatusCreated, models.AuthResponse{
-		Token: token,
-		User: models.User{
-			ID:    int(userID),
-			Email: req.Email,
-			Name:  req.Name,
-			Role:  role,
-		},
+	respondWithSession(w, r, http.StatusCreated, models.User{
+		ID:    int(userID),
+		Email: req.Email,
+		Name:  req.Name,
+		Role:  role,
 	})
 }
 
@@ -112,33 +104,49 @@ func handleLogin(w http.ResponseWriter, r *http.Request) {
 	// Get user
 	var user models.User
 	var passwordHash string
+	var mfaEnabled bool
 	err := db.DB.QueryRow(
-		"SELECT id, email, password_hash, name, role FROM users WHERE email = ?",
+		"SELECT id, email, password_hash, name, role, mfa_enabled FROM users WHERE email = ?",
 		req.Email,
-	).Scan(&user.ID, &user.Email, &passwordHash, &user.Name, &user.Role)
+	).Scan(&user.ID, &user.Email, &passwordHash, &user.Name, &user.Role, &mfaEnabled)
 
 	if err != nil {
 		respondError(w, http.StatusUnauthorized, "Invalid credentials")
 		return
 	}
 
+	// Refuse locked accounts before checking the password, so guesses made
+	// during the lockout reveal nothing
+	if isAccountLocked(user.ID) {
+		respondError(w, http.StatusTooManyRequests, "Account temporarily locked")
+		return
+	}
+
 	// Check password
 	if !auth.CheckPassword(req.Password, passwordHash) {
+		recordLoginAttempt(user.ID, false)
 		respondError(w, http.StatusUnauthorized, "Invalid credentials")
 		return
 	}
 
-	// Generate token
-	token, err := auth.GenerateToken(user.ID, user.Email)
-	if err != nil {
-		respondError(w, http.StatusInternalServerError, "Failed to generate token")
+	// With 2FA on, the password only earns a partial token for
+	// handleValidateMFA, which records the successful login. Recording it
+	// here would reset the failure count before the second factor.
+	if mfaEnabled {
+		mfaToken, err := auth.GenerateMFAToken(user.ID, user.Email)
+		if err != nil {
+			respondError(w, http.StatusInternalServerError, "Failed to generate token")
+			return
+		}
+		respondJSON(w, http.StatusOK, models.MFAChallengeResponse{
+			MFARequired: true,
+			MFAToken:    mfaToken,
+		})
 		return
 	}
 
-	respondJSON(w, http.StatusOK, models.AuthResponse{
-		Token: token,
-		User:  user,
-	})
+	recordLoginAttempt(user.ID, true)
+	respondWithSession(w, r, http.StatusOK, user)
 }
 
 func handleGetMe(w http.ResponseWriter, r *http.Request) {
@@ -150,6 +158,85 @@ func handleGetMe(w http.ResponseWriter, r *http.Request) {
 	respondJSON(w, http.StatusOK, user)
 }
 
+// handleChangePassword lets an authenticated user change their own password
+func handleChangePassword(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+	if user == nil {
+		respondError(w, http.StatusUnauthorized, "Not authenticated")
+		return
+	}
+
+	var req models.ChangePasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if req.CurrentPassword == "" || req.NewPassword == "" {
+		respondError(w, http.StatusBadRequest, "Current password and new password are required")
+		return
+	}
+
+	if req.NewPassword != req.ConfirmPassword {
+		respondError(w, http.StatusBadRequest, "New password and confirmation do not match")
+		return
+	}
+
+	if err := auth.ValidatePasswordStrength(req.NewPassword); err != nil {
+		respondError(w, http.StatusBadRequest, "Password must be at least 8 characters and include a letter and a number")
+		return
+	}
+
+	// Verify current password
+	var passwordHash string
+	var mfaSecret sql.NullString
+	var mfaEnabled bool
+	err := db.DB.QueryRow("SELECT password_hash, mfa_enabled, mfa_secret FROM users WHERE id = ?", user.ID).
+		Scan(&passwordHash, &mfaEnabled, &mfaSecret)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Database error")
+		return
+	}
+
+	if !auth.CheckPassword(req.CurrentPassword, passwordHash) {
+		respondError(w, http.StatusUnauthorized, "Current password is incorrect")
+		return
+	}
+
+	// With 2FA on, a stolen password alone isn't enough to change it
+	if mfaEnabled {
+		if req.Code == "" {
+			respondError(w, http.StatusBadRequest, "Verification code is required")
+			return
+		}
+		if !mfaSecret.Valid || !useTOTPCode(user.ID, mfaSecret.String, req.Code) {
+			respondError(w, http.StatusUnauthorized, "Invalid verification code")
+			return
+		}
+	}
+
+	hashedPassword, err := auth.HashPassword(req.NewPassword)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to hash password")
+		return
+	}
+
+	_, err = db.DB.Exec("UPDATE users SET password_hash = ? WHERE id = ?", hashedPassword, user.ID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to update password")
+		return
+	}
+
+	logAuditEvent(r, user.ID, AuditActionPasswordChanged, "")
+
+	// Sign out other devices; this one keeps its access token until it expires
+	if err := revokeAllRefreshTokens(user.ID); err != nil {
+		logger.FromContext(r.Context()).Errorf("Error revoking sessions for user %d after password change: %v", user.ID, err)
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
 // AuthMiddleware validates the JWT token and adds user to context
 func AuthMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -169,6 +256,18 @@ func AuthMiddleware(next http.Handler) http.Handler {
 
 		tokenString := parts[1]
 
+		// API keys carry their own scopes and are checked against the route
+		if strings.HasPrefix(tokenString, auth.APIKeyPrefix) {
+			user := authenticateAPIKey(w, r, tokenString)
+			if user == nil {
+				return
+			}
+			logger.SetUserID(r.Context(), user.ID)
+			ctx := context.WithValue(r.Context(), userContextKey, user)
+			next.ServeHTTP(w, r.WithContext(ctx))
+			return
+		}
+
 		// Validate token
 		token, err := auth.ValidateToken(tokenString)
 		if err != nil {
@@ -189,6 +288,7 @@ func AuthMiddleware(next http.Handler) http.Handler {
 		}
 
 		// Add user to context
+		logger.SetUserID(r.Context(), user.ID)
 		ctx := context.WithValue(r.Context(), userContextKey, &user)
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
@@ -0,0 +1,151 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/finviz/backend/internal/db"
+	"github.com/finviz/backend/internal/logger"
+	"github.com/finviz/backend/internal/statementparser"
+)
+
+// Maximum bank statement upload size (10MB, same as CSV import)
+const maxStatementSize = 10 << 20
+
+// Account name recorded on transactions imported from each institution's statements
+var statementAccountNames = map[statementparser.Institution]string{
+	statementparser.InstitutionChase:         "Chase Statement",
+	statementparser.InstitutionWellsFargo:    "Wells Fargo Statement",
+	statementparser.InstitutionBankOfAmerica: "Bank of America Statement",
+	statementparser.InstitutionUnknown:       "Bank Statement",
+}
+
+// BankStatementImportResponse is the result of a bank statement upload.
+// With dry_run=true nothing is stored and Transactions is the preview.
+type BankStatementImportResponse struct {
+	Institution  statementparser.Institution   `json:"institution"`
+	DryRun       bool                          `json:"dryRun"`
+	Transactions []statementparser.Transaction `json:"transactions"`
+	Imported     int                           `json:"imported"`
+	Duplicates   int                           `json:"duplicates"` // already-stored rows skipped on confirm
+	Warnings     []string                      `json:"warnings"`
+}
+
+// statementTxnKey identifies a statement line for duplicate detection
+type statementTxnKey struct {
+	date   string
+	amount float64
+	name   string
+}
+
+// handleBankStatementImport parses transactions from an uploaded PDF bank statement.
+// POST /api/import/bank-statement?dry_run=true returns a preview; without
+// dry_run the parsed transactions are inserted.
+func handleBankStatementImport(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+	if user == nil {
+		respondError(w, http.StatusUnauthorized, "Not authenticated")
+		return
+	}
+
+	if err := r.ParseMultipartForm(maxStatementSize); err != nil {
+		respondError(w, http.StatusBadRequest, "Failed to parse form data")
+		return
+	}
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "No file provided")
+		return
+	}
+	defer file.Close()
+
+	if header.Size > maxStatementSize {
+		respondError(w, http.StatusBadRequest, "File too large (max 10MB)")
+		return
+	}
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Failed to read file")
+		return
+	}
+	if !bytes.HasPrefix(data, []byte("%PDF")) {
+		respondError(w, http.StatusBadRequest, "Bank statement must be a PDF file")
+		return
+	}
+
+	statement, err := statementparser.ParsePDFContent(data)
+	if err != nil {
+		logger.FromContext(r.Context()).Errorf("Error parsing bank statement: %v", err)
+		respondError(w, http.StatusBadRequest, "Failed to read PDF")
+		return
+	}
+
+	resp := BankStatementImportResponse{
+		Institution:  statement.Institution,
+		DryRun:       r.URL.Query().Get("dry_run") == "true",
+		Transactions: statement.Transactions,
+		Warnings:     statement.ParseErrors,
+	}
+	if resp.Warnings == nil {
+		resp.Warnings = []string{}
+	}
+
+	if resp.DryRun || len(statement.Transactions) == 0 {
+		respondJSON(w, http.StatusOK, resp)
+		return
+	}
+
+	// Re-uploading the same statement should not double-count. Each stored
+	// transaction matches one statement line, so two identical purchases on
+	// the same day are both imported unless both are already stored.
+	existing := make(map[statementTxnKey]int)
+	for _, txn := range statement.Transactions {
+		key := statementTxnKey{txn.Date, txn.Amount, txn.Name}
+		if _, ok := existing[key]; ok {
+			continue
+		}
+		var count int
+		db.DB.QueryRow(`
+			SELECT COUNT(*) FROM transactions
+			WHERE user_id = ? AND date = ? AND amount = ? AND name = ?
+		`, user.ID, txn.Date, txn.Amount, txn.Name).Scan(&count)
+		existing[key] = count
+	}
+
+	accountName := statementAccountNames[statement.Institution]
+	for _, txn := range statement.Transactions {
+		key := statementTxnKey{txn.Date, txn.Amount, txn.Name}
+		if existing[key] > 0 {
+			existing[key]--
+			resp.Duplicates++
+			continue
+		}
+
+		// Match CSV import: money in is categorized as INCOME (Plaid convention)
+		var category interface{}
+		if txn.Amount < 0 {
+			category = "INCOME"
+		}
+
+		_, err := db.DB.Exec(`
+			INSERT INTO transactions (user_id, account_name, amount, date, name, category, pending)
+			VALUES (?, ?, ?, ?, ?, ?, FALSE)
+		`, user.ID, accountName, txn.Amount, txn.Date, txn.Name, category)
+		if err != nil {
+			logger.FromContext(r.Context()).Errorf("Error inserting statement transaction: %v", err)
+			resp.Warnings = append(resp.Warnings, fmt.Sprintf("Failed to save %s %q", txn.Date, strings.TrimSpace(txn.Name)))
+			continue
+		}
+		resp.Imported++
+	}
+
+	logAuditEvent(r, user.ID, AuditActionBankStatementImported,
+		fmt.Sprintf("institution=%s imported=%d duplicates=%d", statement.Institution, resp.Imported, resp.Duplicates))
+
+	respondJSON(w, http.StatusOK, resp)
+}
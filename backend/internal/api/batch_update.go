@@ -0,0 +1,163 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/finviz/backend/internal/db"
+	"github.com/finviz/backend/internal/logger"
+	"github.com/finviz/backend/internal/models"
+	"github.com/finviz/backend/internal/snapshots"
+)
+
+// maxBatchUpdates caps how many assets or debts one batch request can change
+const maxBatchUpdates = 100
+
+// batchTable describes the rows a batch update changes
+type batchTable struct {
+	table       string // assets or debts
+	valueColumn string // current_value or current_balance
+	valueField  string // JSON name of the value, for error messages
+	scope       string // extra condition a row must meet to be updated
+}
+
+var (
+	assetBatchTable = batchTable{table: "assets", valueColumn: "current_value", valueField: "currentValue", scope: "AND deleted_at IS NULL"}
+	debtBatchTable  = batchTable{table: "debts", valueColumn: "current_balance", valueField: "currentBalance"}
+)
+
+// batchUpdate is one item of a batch: the new value or balance, and
+// optionally a new name
+type batchUpdate struct {
+	id    int
+	value *float64
+	name  *string
+}
+
+// handleBatchUpdateAssets sets the values (and optionally names) of many
+// assets at once, e.g. after a quarterly statement
+// PATCH /api/assets/batch
+func handleBatchUpdateAssets(w http.ResponseWriter, r *http.Request) {
+	var req []models.BatchAssetUpdate
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	updates := make([]batchUpdate, len(req))
+	for i, u := range req {
+		updates[i] = batchUpdate{id: u.ID, value: u.CurrentValue, name: u.Name}
+	}
+	runBatchUpdate(w, r, assetBatchTable, updates)
+}
+
+// handleBatchUpdateDebts sets the balances (and optionally names) of many
+// debts at once
+// PATCH /api/debts/batch
+func handleBatchUpdateDebts(w http.ResponseWriter, r *http.Request) {
+	var req []models.BatchDebtUpdate
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	updates := make([]batchUpdate, len(req))
+	for i, u := range req {
+		updates[i] = batchUpdate{id: u.ID, value: u.CurrentBalance, name: u.Name}
+	}
+	runBatchUpdate(w, r, debtBatchTable, updates)
+}
+
+// runBatchUpdate applies a batch in one transaction. Items that are invalid,
+// repeated or don't belong to the effective user are reported as failed and
+// the rest are still applied. Afterwards today's net worth snapshot is
+// refreshed so the history shows the new totals.
+func runBatchUpdate(w http.ResponseWriter, r *http.Request, t batchTable, updates []batchUpdate) {
+	userID := getEffectiveUserID(r)
+	if userID == 0 {
+		respondError(w, http.StatusUnauthorized, "Not authenticated")
+		return
+	}
+
+	if !canEdit(r) {
+		respondError(w, http.StatusForbidden, "No permission to edit client data")
+		return
+	}
+
+	if len(updates) == 0 {
+		respondError(w, http.StatusBadRequest, "At least one update is required")
+		return
+	}
+	if len(updates) > maxBatchUpdates {
+		respondError(w, http.StatusBadRequest, fmt.Sprintf("Maximum %d %s per request", maxBatchUpdates, t.table))
+		return
+	}
+
+	log := logger.FromContext(r.Context())
+	tx, err := db.DB.Begin()
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to update "+t.table)
+		return
+	}
+	defer tx.Rollback()
+
+	resp := models.BatchUpdateResponse{Failed: []models.FailedUpdate{}}
+	seen := make(map[int]bool)
+	for _, u := range updates {
+		if seen[u.id] {
+			resp.Failed = append(resp.Failed, models.FailedUpdate{ID: u.id, Error: "Duplicate ID in request"})
+			continue
+		}
+		seen[u.id] = true
+
+		if u.value == nil {
+			resp.Failed = append(resp.Failed, models.FailedUpdate{ID: u.id, Error: t.valueField + " is required"})
+			continue
+		}
+		if u.name != nil {
+			name := strings.TrimSpace(*u.name)
+			if name == "" {
+				resp.Failed = append(resp.Failed, models.FailedUpdate{ID: u.id, Error: "name can't be empty"})
+				continue
+			}
+			u.name = &name
+		}
+
+		// Lock the row, which also confirms it belongs to the user
+		var id int
+		err := tx.QueryRow(
+			"SELECT id FROM "+t.table+" WHERE id = ? AND user_id = ? "+t.scope+" FOR UPDATE",
+			u.id, userID,
+		).Scan(&id)
+		if err != nil {
+			resp.Failed = append(resp.Failed, models.FailedUpdate{ID: u.id, Error: "Not found"})
+			continue
+		}
+
+		_, err = tx.Exec(
+			"UPDATE "+t.table+" SET "+t.valueColumn+" = ?, name = COALESCE(?, name), updated_at = NOW() WHERE id = ?",
+			*u.value, u.name, id,
+		)
+		if err != nil {
+			log.Errorf("Error batch updating %s %d: %v", t.table, id, err)
+			respondError(w, http.StatusInternalServerError, "Failed to update "+t.table)
+			return
+		}
+		resp.Updated++
+	}
+
+	if err := tx.Commit(); err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to update "+t.table)
+		return
+	}
+
+	if resp.Updated > 0 {
+		if err := snapshots.TakeUserSnapshot(userID); err != nil {
+			log.Errorf("Error recording net worth snapshot for user %d: %v", userID, err)
+		}
+	}
+
+	respondJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,154 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/finviz/backend/internal/db"
+	"github.com/finviz/backend/internal/models"
+	"github.com/finviz/backend/internal/reports"
+)
+
+// maxBrandingLogoSize caps logo uploads at 1MB
+const maxBrandingLogoSize = 1 << 20
+
+// handleGetBranding returns the advisor's report branding
+func handleGetBranding(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+	if user == nil {
+		respondError(w, http.StatusUnauthorized, "Not authenticated")
+		return
+	}
+
+	branding, err := fetchAdvisorBranding(user.ID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch branding")
+		return
+	}
+	if branding == nil {
+		// No branding yet - return an empty config so the UI can render defaults
+		branding = &models.AdvisorBranding{AdvisorID: user.ID}
+	}
+
+	respondJSON(w, http.StatusOK, branding)
+}
+
+// handleUpdateBranding creates or replaces the advisor's report branding
+func handleUpdateBranding(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+	if user == nil {
+		respondError(w, http.StatusUnauthorized, "Not authenticated")
+		return
+	}
+
+	var req models.UpdateAdvisorBrandingRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if req.FirmName != nil && len(*req.FirmName) > 255 {
+		respondError(w, http.StatusBadRequest, "Firm name must be 255 characters or less")
+		return
+	}
+	for _, c := range []*string{req.PrimaryColorHex, req.SecondaryColorHex} {
+		if c != nil && !reports.ValidHexColor(*c) {
+			respondError(w, http.StatusBadRequest, "Colors must be in #RRGGBB format")
+			return
+		}
+	}
+
+	var logo interface{}
+	var logoMimeType *string
+	if len(req.Logo) > 0 {
+		if len(req.Logo) > maxBrandingLogoSize {
+			respondError(w, http.StatusBadRequest, fmt.Sprintf("Logo must be %dKB or less", maxBrandingLogoSize/1024))
+			return
+		}
+		mimeType := http.DetectContentType(req.Logo)
+		if mimeType != "image/png" && mimeType != "image/jpeg" {
+			respondError(w, http.StatusBadRequest, "Logo must be a PNG or JPEG image")
+			return
+		}
+		logo = req.Logo
+		logoMimeType = &mimeType
+	}
+
+	_, err := db.DB.Exec(`
+		INSERT INTO advisor_branding
+		(advisor_id, firm_name, logo, logo_mime_type, primary_color_hex, secondary_color_hex)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE
+			firm_name = VALUES(firm_name),
+			logo = VALUES(logo),
+			logo_mime_type = VALUES(logo_mime_type),
+			primary_color_hex = VALUES(primary_color_hex),
+			secondary_color_hex = VALUES(secondary_color_hex)
+	`, user.ID, req.FirmName, logo, logoMimeType, req.PrimaryColorHex, req.SecondaryColorHex)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to save branding")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{"message": "Branding saved"})
+}
+
+// fetchAdvisorBranding loads an advisor's branding, returning nil if none is set
+func fetchAdvisorBranding(advisorID int) (*models.AdvisorBranding, error) {
+	var branding models.AdvisorBranding
+	err := db.DB.QueryRow(`
+		SELECT id, advisor_id, firm_name, logo, logo_mime_type,
+		       primary_color_hex, secondary_color_hex, created_at, updated_at
+		FROM advisor_branding
+		WHERE advisor_id = ?
+	`, advisorID).Scan(
+		&branding.ID, &branding.AdvisorID, &branding.FirmName, &branding.Logo, &branding.LogoMimeType,
+		&branding.PrimaryColorHex, &branding.SecondaryColorHex, &branding.CreatedAt, &branding.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &branding, nil
+}
+
+// fetchClientBranding loads the branding of a client's active advisor, if any
+func fetchClientBranding(clientID int) (*models.AdvisorBranding, error) {
+	var advisorID int
+	err := db.DB.QueryRow(`
+		SELECT advisor_id FROM advisor_clients
+		WHERE client_id = ? AND status = 'active'
+		ORDER BY accepted_at DESC
+		LIMIT 1
+	`, clientID).Scan(&advisorID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return fetchAdvisorBranding(advisorID)
+}
+
+// reportBranding converts stored branding to the report generator's form
+func reportBranding(b *models.AdvisorBranding) *reports.Branding {
+	if b == nil {
+		return nil
+	}
+	branding := &reports.Branding{LogoBytes: b.Logo}
+	if b.FirmName != nil {
+		branding.FirmName = *b.FirmName
+	}
+	if b.PrimaryColorHex != nil {
+		branding.PrimaryColorHex = *b.PrimaryColorHex
+	}
+	if b.SecondaryColorHex != nil {
+		branding.SecondaryColorHex = *b.SecondaryColorHex
+	}
+	return branding
+}
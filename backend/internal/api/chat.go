@@ -8,6 +8,8 @@ import (
 	"strings"
 
 	"github.com/finviz/backend/internal/claude"
+	"github.com/finviz/backend/internal/logger"
+	"github.com/finviz/backend/internal/models"
 )
 
 var claudeClient *claude.Client
@@ -32,12 +34,16 @@ func init() {
 
 	claudeClient.SetSystemPrompt(aureliaPrompt)
 	claudeClient.SetTools(claude.GetAureliaTools())
+
+	// Lets tools like generate_report save into the document vault
+	claude.SaveDocument = SaveDocumentFromBytes
 }
 
-// ChatRequest represents the incoming chat request
+// ChatRequest represents the incoming chat request. With ConversationID set,
+// Messages holds only the new messages and the saved history is prepended.
 type ChatRequest struct {
 	Messages       []ChatMessage `json:"messages"`
-	ConversationID string        `json:"conversationId,omitempty"`
+	ConversationID int           `json:"conversationId,omitempty"`
 }
 
 // ChatMessage represents a message in the conversation
@@ -52,6 +58,8 @@ type ChatResponse struct {
 	ToolsUsed  []string                 `json:"toolsUsed,omitempty"`
 	Artifacts  []map[string]interface{} `json:"artifacts,omitempty"`
 	TokenUsage map[string]int           `json:"tokenUsage,omitempty"`
+
+	ConversationID int `json:"conversationId,omitempty"` // saved conversation to continue with
 }
 
 // handleChat handles chat requests with the Aurelia agent
@@ -78,20 +86,31 @@ func handleChat(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	systemPrompt, tools, err := chatConfigForUser(user)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to load AI configuration")
+		return
+	}
+
+	history, err := loadChatHistory(user.ID, req.ConversationID)
+	if err == errChatConversationNotFound {
+		respondError(w, http.StatusNotFound, "Conversation not found")
+		return
+	}
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to load conversation")
+		return
+	}
+
 	// Convert chat messages to Claude format
-	messages := convertToClaude(req.Messages)
+	messages := convertToClaude(append(history, req.Messages...))
 
 	// Create tool executor for this user
-	toolExecutor := claude.NewToolExecutor(user.ID)
-
-	// Track tools used and artifacts
-	var toolsUsed []string
-	var artifacts []map[string]interface{}
+	turn := &chatTurn{executor: claude.NewToolExecutor(user.ID)}
 
 	// Agentic loop: continue until we get a final response (not tool_use)
-	maxIterations := 10
-	for i := 0; i < maxIterations; i++ {
-		response, err := claudeClient.SendMessage(messages)
+	for i := 0; i < maxChatIterations; i++ {
+		response, err := claudeClient.SendMessageWithConfig(messages, systemPrompt, tools)
 		if err != nil {
 			respondError(w, http.StatusInternalServerError, fmt.Sprintf("Chat error: %v", err))
 			return
@@ -100,27 +119,7 @@ func handleChat(w http.ResponseWriter, r *http.Request) {
 		// Check if we have tool calls to execute
 		if claudeClient.HasToolUse(response) {
 			toolCalls := claudeClient.ExtractToolCalls(response)
-
-			// Execute each tool
-			results := make(map[string]string)
-			for _, tc := range toolCalls {
-				toolsUsed = append(toolsUsed, tc.Name)
-
-				result, err := toolExecutor.ExecuteTool(tc.Name, tc.Input)
-				if err != nil {
-					results[tc.ID] = fmt.Sprintf("Error: %v", err)
-				} else {
-					results[tc.ID] = result
-
-					// Check if this is an artifact (chart, table, metric_card)
-					if isArtifactTool(tc.Name) {
-						var artifact map[string]interface{}
-						if json.Unmarshal([]byte(result), &artifact) == nil {
-							artifacts = append(artifacts, artifact)
-						}
-					}
-				}
-			}
+			results := turn.executeTools(toolCalls, nil)
 
 			// Add the assistant's response (with tool use) to messages
 			messages = append(messages, claudeClient.CreateAssistantMessage(response))
@@ -135,23 +134,97 @@ func handleChat(w http.ResponseWriter, r *http.Request) {
 		// No tool use - we have a final response
 		textResponse := claudeClient.ExtractTextResponse(response)
 
-		resp := ChatResponse{
-			Response:  textResponse,
-			ToolsUsed: uniqueStrings(toolsUsed),
-			Artifacts: artifacts,
-			TokenUsage: map[string]int{
-				"input":  response.Usage.InputTokens,
-				"output": response.Usage.OutputTokens,
-			},
-		}
-
-		respondJSON(w, http.StatusOK, resp)
+		respondJSON(w, http.StatusOK, turn.finish(user.ID, &req, textResponse, response.Usage))
 		return
 	}
 
 	respondError(w, http.StatusInternalServerError, "Max iterations reached without a final response")
 }
 
+// maxChatIterations bounds the agentic tool-use loop of a single chat turn
+const maxChatIterations = 10
+
+// chatConfigForUser returns the system prompt and tools for a user's chat,
+// applying the advisor's firm customizations for clients
+func chatConfigForUser(user *models.User) (string, []claude.Tool, error) {
+	systemPrompt := claudeClient.GetSystemPrompt()
+	tools := claudeClient.GetTools()
+	if user.IsClient() {
+		aiConfig, err := fetchClientAIConfig(user.ID)
+		if err != nil {
+			return "", nil, err
+		}
+		if aiConfig != nil {
+			systemPrompt = buildAureliaPrompt(systemPrompt, aiConfig)
+			tools = claude.FilterTools(tools, aiConfig.EnabledTools)
+		}
+	}
+	return systemPrompt, tools, nil
+}
+
+// chatTurn runs the tools of one chat turn and collects what they produced
+type chatTurn struct {
+	executor  *claude.ToolExecutor
+	toolsUsed []string
+	toolCalls []chatToolCall
+	artifacts []map[string]interface{}
+}
+
+// executeTools runs each tool call and returns results keyed by tool use ID.
+// notify, if set, is called with "tool_start" before and "tool_end" after each call.
+func (t *chatTurn) executeTools(toolCalls []claude.ToolCall, notify func(event string, tc claude.ToolCall, err error)) map[string]string {
+	results := make(map[string]string)
+	for _, tc := range toolCalls {
+		t.toolsUsed = append(t.toolsUsed, tc.Name)
+		t.toolCalls = append(t.toolCalls, chatToolCall{Name: tc.Name, Input: tc.Input})
+		if notify != nil {
+			notify("tool_start", tc, nil)
+		}
+
+		result, err := t.executor.ExecuteTool(tc.Name, tc.Input)
+		if err != nil {
+			results[tc.ID] = fmt.Sprintf("Error: %v", err)
+		} else {
+			results[tc.ID] = result
+
+			// Check if this is an artifact (chart, table, metric_card)
+			if isArtifactTool(tc.Name) {
+				var artifact map[string]interface{}
+				if json.Unmarshal([]byte(result), &artifact) == nil {
+					t.artifacts = append(t.artifacts, artifact)
+				}
+			}
+		}
+
+		if notify != nil {
+			notify("tool_end", tc, err)
+		}
+	}
+	return results
+}
+
+// finish saves the turn to the conversation and builds the final chat
+// response. A failed save is logged; the reply is still returned.
+func (t *chatTurn) finish(userID int, req *ChatRequest, text string, usage claude.Usage) ChatResponse {
+	resp := ChatResponse{
+		Response:  text,
+		ToolsUsed: uniqueStrings(t.toolsUsed),
+		Artifacts: t.artifacts,
+		TokenUsage: map[string]int{
+			"input":  usage.InputTokens,
+			"output": usage.OutputTokens,
+		},
+	}
+
+	conversationID, err := saveChatTurn(userID, req.ConversationID, req.Messages, text, t.toolCalls)
+	if err != nil {
+		logger.Default().Errorf("Error saving chat conversation: %v", err)
+	} else {
+		resp.ConversationID = conversationID
+	}
+	return resp
+}
+
 // handleChatStatus returns whether chat is available
 func handleChatStatus(w http.ResponseWriter, r *http.Request) {
 	respondJSON(w, http.StatusOK, map[string]bool{
@@ -180,7 +253,7 @@ func convertToClaude(messages []ChatMessage) []claude.Message {
 
 // isArtifactTool checks if a tool produces a renderable artifact
 func isArtifactTool(name string) bool {
-	artifactTools := []string{"create_chart", "create_table", "create_metric_card"}
+	artifactTools := []string{"create_chart", "create_table", "create_metric_card", "generate_report"}
 	for _, t := range artifactTools {
 		if name == t {
 			return true
@@ -0,0 +1,272 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/finviz/backend/internal/db"
+	"github.com/finviz/backend/internal/logger"
+	"github.com/finviz/backend/internal/models"
+)
+
+// maxChatHistoryMessages caps the stored messages replayed into a chat so the
+// prompt stays within the context window
+const maxChatHistoryMessages = 50
+
+// maxChatTitleLength is how much of the first message becomes the conversation title
+const maxChatTitleLength = 80
+
+var errChatConversationNotFound = errors.New("chat conversation not found")
+
+// chatToolCall is a tool the assistant used for a reply, as stored in tool_calls_json
+type chatToolCall struct {
+	Name  string                 `json:"name"`
+	Input map[string]interface{} `json:"input"`
+}
+
+// loadChatHistory returns the most recent messages of a saved conversation
+// owned by the user, ready to prefix a new chat request. conversationID 0
+// means a new conversation with no history.
+func loadChatHistory(userID, conversationID int) ([]ChatMessage, error) {
+	if conversationID == 0 {
+		return nil, nil
+	}
+
+	var ownerID int
+	err := db.DB.QueryRow(`SELECT user_id FROM chat_conversations WHERE id = ?`, conversationID).Scan(&ownerID)
+	if err == sql.ErrNoRows || (err == nil && ownerID != userID) {
+		return nil, errChatConversationNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Newest first so the LIMIT keeps the latest messages
+	rows, err := db.DB.Query(`
+		SELECT role, content_text FROM chat_messages
+		WHERE conversation_id = ?
+		ORDER BY id DESC
+		LIMIT ?
+	`, conversationID, maxChatHistoryMessages)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var history []ChatMessage
+	for rows.Next() {
+		var msg ChatMessage
+		if err := rows.Scan(&msg.Role, &msg.Content); err != nil {
+			return nil, err
+		}
+		history = append(history, msg)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i, j := 0, len(history)-1; i < j; i, j = i+1, j-1 {
+		history[i], history[j] = history[j], history[i]
+	}
+	return truncateChatHistory(history, maxChatHistoryMessages), nil
+}
+
+// truncateChatHistory keeps the most recent max messages, then drops any
+// leading assistant messages since Claude requires the first message to be
+// from the user
+func truncateChatHistory(messages []ChatMessage, max int) []ChatMessage {
+	if len(messages) > max {
+		messages = messages[len(messages)-max:]
+	}
+	for len(messages) > 0 && messages[0].Role != "user" {
+		messages = messages[1:]
+	}
+	return messages
+}
+
+// saveChatTurn stores the new user messages and the assistant's reply,
+// creating the conversation when conversationID is 0. Returns the conversation ID.
+func saveChatTurn(userID, conversationID int, newMessages []ChatMessage, reply string, toolCalls []chatToolCall) (int, error) {
+	tx, err := db.DB.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if conversationID == 0 {
+		res, err := tx.Exec(`INSERT INTO chat_conversations (user_id, title) VALUES (?, ?)`, userID, chatTitle(newMessages))
+		if err != nil {
+			return 0, err
+		}
+		id, err := res.LastInsertId()
+		if err != nil {
+			return 0, err
+		}
+		conversationID = int(id)
+	} else if _, err := tx.Exec(`UPDATE chat_conversations SET updated_at = NOW() WHERE id = ?`, conversationID); err != nil {
+		return 0, err
+	}
+
+	for _, msg := range newMessages {
+		if _, err := tx.Exec(`
+			INSERT INTO chat_messages (conversation_id, role, content_text) VALUES (?, ?, ?)
+		`, conversationID, msg.Role, msg.Content); err != nil {
+			return 0, err
+		}
+	}
+
+	var toolCallsJSON interface{}
+	if len(toolCalls) > 0 {
+		encoded, err := json.Marshal(toolCalls)
+		if err != nil {
+			return 0, err
+		}
+		toolCallsJSON = string(encoded)
+	}
+	if _, err := tx.Exec(`
+		INSERT INTO chat_messages (conversation_id, role, content_text, tool_calls_json) VALUES (?, 'assistant', ?, ?)
+	`, conversationID, reply, toolCallsJSON); err != nil {
+		return 0, err
+	}
+
+	return conversationID, tx.Commit()
+}
+
+// chatTitle names a conversation after its first user message
+func chatTitle(messages []ChatMessage) string {
+	for _, msg := range messages {
+		if msg.Role != "user" {
+			continue
+		}
+		title := strings.Join(strings.Fields(msg.Content), " ")
+		if utf8.RuneCountInString(title) > maxChatTitleLength {
+			title = string([]rune(title)[:maxChatTitleLength-3]) + "..."
+		}
+		if title != "" {
+			return title
+		}
+	}
+	return "New conversation"
+}
+
+// handleListChatConversations lists the user's saved chats, most recent first
+func handleListChatConversations(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+	if user == nil {
+		respondError(w, http.StatusUnauthorized, "Not authenticated")
+		return
+	}
+
+	rows, err := db.DB.Query(`
+		SELECT id, user_id, title, created_at, updated_at
+		FROM chat_conversations
+		WHERE user_id = ?
+		ORDER BY updated_at DESC
+	`, user.ID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch conversations")
+		return
+	}
+	defer rows.Close()
+
+	conversations := []models.ChatConversation{}
+	for rows.Next() {
+		var c models.ChatConversation
+		if err := rows.Scan(&c.ID, &c.UserID, &c.Title, &c.CreatedAt, &c.UpdatedAt); err != nil {
+			continue
+		}
+		conversations = append(conversations, c)
+	}
+
+	respondJSON(w, http.StatusOK, conversations)
+}
+
+// handleGetChatConversation returns a saved chat with all of its messages
+func handleGetChatConversation(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+	if user == nil {
+		respondError(w, http.StatusUnauthorized, "Not authenticated")
+		return
+	}
+
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid conversation ID")
+		return
+	}
+
+	var c models.ChatConversation
+	err = db.DB.QueryRow(`
+		SELECT id, user_id, title, created_at, updated_at
+		FROM chat_conversations
+		WHERE id = ? AND user_id = ?
+	`, id, user.ID).Scan(&c.ID, &c.UserID, &c.Title, &c.CreatedAt, &c.UpdatedAt)
+	if err == sql.ErrNoRows {
+		respondError(w, http.StatusNotFound, "Conversation not found")
+		return
+	}
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch conversation")
+		return
+	}
+
+	rows, err := db.DB.Query(`
+		SELECT id, conversation_id, role, content_text, tool_calls_json, created_at
+		FROM chat_messages
+		WHERE conversation_id = ?
+		ORDER BY id
+	`, c.ID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch messages")
+		return
+	}
+	defer rows.Close()
+
+	c.Messages = []models.ChatMessageRecord{}
+	for rows.Next() {
+		var m models.ChatMessageRecord
+		var toolCalls sql.NullString
+		if err := rows.Scan(&m.ID, &m.ConversationID, &m.Role, &m.ContentText, &toolCalls, &m.CreatedAt); err != nil {
+			logger.FromContext(r.Context()).Errorf("Error scanning chat message: %v", err)
+			continue
+		}
+		if toolCalls.Valid {
+			m.ToolCalls = json.RawMessage(toolCalls.String)
+		}
+		c.Messages = append(c.Messages, m)
+	}
+
+	respondJSON(w, http.StatusOK, c)
+}
+
+// handleDeleteChatConversation deletes a saved chat and its messages
+func handleDeleteChatConversation(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+	if user == nil {
+		respondError(w, http.StatusUnauthorized, "Not authenticated")
+		return
+	}
+
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid conversation ID")
+		return
+	}
+
+	result, err := db.DB.Exec(`DELETE FROM chat_conversations WHERE id = ? AND user_id = ?`, id, user.ID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to delete conversation")
+		return
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		respondError(w, http.StatusNotFound, "Conversation not found")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{"status": "deleted"})
+}
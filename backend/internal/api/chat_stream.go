@@ -0,0 +1,137 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/finviz/backend/internal/claude"
+	"github.com/finviz/backend/internal/logger"
+)
+
+// sseWriter writes server-sent events and flushes each one immediately
+type sseWriter struct {
+	w       http.ResponseWriter
+	flusher http.Flusher
+}
+
+// send writes one event with a JSON data payload
+func (s *sseWriter) send(event string, data interface{}) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		logger.Default().Errorf("Error encoding %s event: %v", event, err)
+		return
+	}
+	fmt.Fprintf(s.w, "event: %s\ndata: %s\n\n", event, payload)
+	s.flusher.Flush()
+}
+
+// handleChatStream is handleChat over server-sent events. Assistant text is
+// sent as it is generated (event: delta, data: {"text": "..."}), each tool
+// call is bracketed by tool_start and tool_end events, and the turn ends with
+// a done event carrying the same body handleChat returns, or an error event.
+// POST /api/chat/stream
+func handleChatStream(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+	if user == nil {
+		respondError(w, http.StatusUnauthorized, "Not authenticated")
+		return
+	}
+
+	if !claudeClient.IsConfigured() {
+		respondError(w, http.StatusServiceUnavailable, "Chat service is not configured. Please set ANTHROPIC_API_KEY.")
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		respondError(w, http.StatusInternalServerError, "Streaming is not supported")
+		return
+	}
+
+	var req ChatRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if len(req.Messages) == 0 {
+		respondError(w, http.StatusBadRequest, "At least one message is required")
+		return
+	}
+
+	systemPrompt, tools, err := chatConfigForUser(user)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to load AI configuration")
+		return
+	}
+
+	history, err := loadChatHistory(user.ID, req.ConversationID)
+	if err == errChatConversationNotFound {
+		respondError(w, http.StatusNotFound, "Conversation not found")
+		return
+	}
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to load conversation")
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no") // keep nginx from buffering the stream
+	w.WriteHeader(http.StatusOK)
+	sse := &sseWriter{w: w, flusher: flusher}
+
+	messages := convertToClaude(append(history, req.Messages...))
+	turn := &chatTurn{executor: claude.NewToolExecutor(user.ID)}
+
+	notify := func(event string, tc claude.ToolCall, err error) {
+		data := map[string]string{"id": tc.ID, "name": tc.Name}
+		if err != nil {
+			data["error"] = err.Error()
+		}
+		sse.send(event, data)
+	}
+
+	// Agentic loop, as in handleChat, streaming each round's text
+	for i := 0; i < maxChatIterations; i++ {
+		events, err := claudeClient.SendMessageStreamWithConfig(messages, systemPrompt, tools)
+		if err != nil {
+			sse.send("error", map[string]string{"error": fmt.Sprintf("Chat error: %v", err)})
+			return
+		}
+
+		// Drain the whole channel so the reader goroutine always finishes
+		var response *claude.Response
+		var streamErr error
+		for event := range events {
+			switch event.Type {
+			case claude.StreamEventDelta:
+				sse.send("delta", map[string]string{"text": event.Text})
+			case claude.StreamEventMessage:
+				response = event.Response
+			case claude.StreamEventError:
+				streamErr = event.Err
+			}
+		}
+		if streamErr != nil || response == nil {
+			sse.send("error", map[string]string{"error": fmt.Sprintf("Chat error: %v", streamErr)})
+			return
+		}
+
+		if claudeClient.HasToolUse(response) {
+			toolCalls := claudeClient.ExtractToolCalls(response)
+			results := turn.executeTools(toolCalls, notify)
+
+			messages = append(messages, claudeClient.CreateAssistantMessage(response))
+			messages = append(messages, claudeClient.CreateToolResultMessage(toolCalls, results))
+			continue
+		}
+
+		sse.send("done", turn.finish(user.ID, &req, claudeClient.ExtractTextResponse(response), response.Usage))
+		return
+	}
+
+	sse.send("error", map[string]string{"error": "Max iterations reached without a final response"})
+}
@@ -0,0 +1,97 @@
+package api
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/finviz/backend/internal/models"
+	"github.com/finviz/backend/internal/taxcalc"
+)
+
+// handleProject529 projects a 529 college savings plan to the year college
+// starts with deterministic monthly compounding at the default simulation
+// return, and works out the extra monthly contribution any shortfall needs
+// POST /api/simulate/529
+func handleProject529(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+	if user == nil {
+		respondError(w, http.StatusUnauthorized, "Not authenticated")
+		return
+	}
+
+	if isActingAsAdvisor(r) && !canRunSimulations(r) {
+		respondError(w, http.StatusForbidden, "No permission to run simulations for this client")
+		return
+	}
+
+	var req models.College529Request
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	currentYear := time.Now().Year()
+	if req.ChildBirthYear < currentYear-30 || req.ChildBirthYear > currentYear+1 {
+		respondError(w, http.StatusBadRequest, "Child birth year must be within the last 30 years")
+		return
+	}
+	if req.TargetYear <= currentYear || req.TargetYear > currentYear+40 {
+		respondError(w, http.StatusBadRequest, "Target year must be in the next 40 years")
+		return
+	}
+	if req.TargetYear < req.ChildBirthYear {
+		respondError(w, http.StatusBadRequest, "Target year can't be before the child's birth year")
+		return
+	}
+	if req.CurrentBalance < 0 || req.MonthlyContribution < 0 || req.StateDeduction < 0 || req.ExpectedCostOfCollege < 0 {
+		respondError(w, http.StatusBadRequest, "Amounts cannot be negative")
+		return
+	}
+
+	annualReturn := models.DefaultSimulationParams().ExpectedReturn
+	monthlyReturn := annualReturn / 12
+	stateRate := taxcalc.StateIncomeTaxRate(req.State)
+
+	resp := models.College529Response{ExpectedReturn: annualReturn, BreakdownByYear: []models.YearProjection{}}
+	balance := req.CurrentBalance
+	years := req.TargetYear - currentYear
+	for i := 1; i <= years; i++ {
+		for m := 0; m < 12; m++ {
+			balance = balance*(1+monthlyReturn) + req.MonthlyContribution
+		}
+		contributed := req.MonthlyContribution * 12
+		resp.TotalContributions += contributed
+		// Only contributions actually made can be deducted
+		resp.StateTaxSavings += math.Min(req.StateDeduction, contributed) * stateRate
+
+		year := currentYear + i
+		rounded := roundCents(balance)
+		resp.BreakdownByYear = append(resp.BreakdownByYear, models.YearProjection{
+			Year:          year,
+			Age:           year - req.ChildBirthYear,
+			P10:           rounded,
+			P50:           rounded,
+			P90:           rounded,
+			Phase:         "accumulation",
+			Contributions: roundCents(contributed),
+		})
+	}
+
+	resp.ProjectedBalance = roundCents(balance)
+	resp.Shortfall = roundCents(math.Max(0, req.ExpectedCostOfCollege-balance))
+	if resp.Shortfall > 0 {
+		// Future value of one dollar a month over the same period
+		months := float64(years * 12)
+		annuity := months
+		if monthlyReturn != 0 {
+			annuity = (math.Pow(1+monthlyReturn, months) - 1) / monthlyReturn
+		}
+		resp.RequiredAdditionalMonthly = roundCents(resp.Shortfall / annuity)
+	}
+	resp.TotalContributions = roundCents(resp.TotalContributions)
+	resp.StateTaxSavings = roundCents(resp.StateTaxSavings)
+
+	respondJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,185 @@
+package api
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/finviz/backend/internal/db"
+	"github.com/finviz/backend/internal/logger"
+	"github.com/finviz/backend/internal/models"
+)
+
+// ConversationExport is the JSON form of a conversation export
+type ConversationExport struct {
+	Conversation models.Conversation `json:"conversation"`
+	ExportedAt   time.Time           `json:"exportedAt"`
+	ExportedBy   int                 `json:"exportedBy"`
+	Messages     []models.Message    `json:"messages"`
+}
+
+// advisorConversationFromPath loads the conversation named by the id path
+// value if the caller is an advisor taking part in it. Writes the error
+// response and returns false otherwise.
+func advisorConversationFromPath(w http.ResponseWriter, r *http.Request) (*models.User, models.Conversation, bool) {
+	user := getUserFromContext(r)
+	if user == nil || !user.IsAdvisor() {
+		respondError(w, http.StatusForbidden, "Only advisors can manage conversation records")
+		return nil, models.Conversation{}, false
+	}
+
+	convID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid conversation ID")
+		return nil, models.Conversation{}, false
+	}
+
+	conv, err := loadConversation(convID, user.ID)
+	if err != nil {
+		respondError(w, http.StatusNotFound, "Conversation not found")
+		return nil, conv, false
+	}
+	return user, conv, true
+}
+
+// handleArchiveConversation archives a conversation, which keeps its messages
+// but stops new ones being sent
+// PUT /api/messages/conversations/{id}/archive
+func handleArchiveConversation(w http.ResponseWriter, r *http.Request) {
+	setConversationStatus(w, r, models.ConversationStatusArchived)
+}
+
+// handleUnarchiveConversation reopens an archived conversation
+// PUT /api/messages/conversations/{id}/unarchive
+func handleUnarchiveConversation(w http.ResponseWriter, r *http.Request) {
+	setConversationStatus(w, r, models.ConversationStatusActive)
+}
+
+func setConversationStatus(w http.ResponseWriter, r *http.Request, status string) {
+	user, conv, ok := advisorConversationFromPath(w, r)
+	if !ok {
+		return
+	}
+	if conv.Status == status {
+		respondError(w, http.StatusConflict, "Conversation is already "+status)
+		return
+	}
+
+	var err error
+	if status == models.ConversationStatusArchived {
+		_, err = db.DB.Exec(`
+			UPDATE conversations SET status = ?, archived_at = NOW(), archived_by = ? WHERE id = ?
+		`, status, user.ID, conv.ID)
+	} else {
+		_, err = db.DB.Exec(`
+			UPDATE conversations SET status = ?, archived_at = NULL, archived_by = NULL WHERE id = ?
+		`, status, conv.ID)
+	}
+	if err != nil {
+		logger.FromContext(r.Context()).Errorf("Error setting conversation %d to %s: %v", conv.ID, status, err)
+		respondError(w, http.StatusInternalServerError, "Failed to update conversation")
+		return
+	}
+
+	action := AuditActionConversationArchived
+	if status == models.ConversationStatusActive {
+		action = AuditActionConversationUnarchived
+	}
+	logAuditEvent(r, user.ID, action, fmt.Sprintf("conversation_id=%d", conv.ID))
+
+	updated, err := loadConversation(conv.ID, user.ID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch conversation")
+		return
+	}
+	respondJSON(w, http.StatusOK, updated)
+}
+
+// handleExportConversation returns every message in a conversation, oldest
+// first, for record retention. Message bodies are exported as stored, so E2E
+// encrypted messages stay encrypted alongside their nonce.
+// GET /api/messages/conversations/{id}/export?format=json|csv
+func handleExportConversation(w http.ResponseWriter, r *http.Request) {
+	user, conv, ok := advisorConversationFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "json"
+	}
+	if format != "json" && format != "csv" {
+		respondError(w, http.StatusBadRequest, "Invalid format. Use 'json' or 'csv'")
+		return
+	}
+
+	rows, err := db.DB.Query(`
+		SELECT m.id, m.conversation_id, m.sender_id, m.encrypted_content, m.nonce,
+		       m.read_at, m.delivered_at, m.created_at, u.name as sender_name
+		FROM messages m
+		JOIN users u ON m.sender_id = u.id
+		WHERE m.conversation_id = ?
+		ORDER BY m.created_at ASC, m.id ASC
+	`, conv.ID)
+	if err != nil {
+		logger.FromContext(r.Context()).Errorf("Error exporting conversation %d: %v", conv.ID, err)
+		respondError(w, http.StatusInternalServerError, "Failed to export conversation")
+		return
+	}
+	defer rows.Close()
+
+	messages := []models.Message{}
+	for rows.Next() {
+		var m models.Message
+		if err := rows.Scan(&m.ID, &m.ConversationID, &m.SenderID, &m.EncryptedContent,
+			&m.Nonce, &m.ReadAt, &m.DeliveredAt, &m.CreatedAt, &m.SenderName); err != nil {
+			respondError(w, http.StatusInternalServerError, "Failed to export conversation")
+			return
+		}
+		messages = append(messages, m)
+	}
+
+	logAuditEvent(r, user.ID, AuditActionConversationExported,
+		fmt.Sprintf("conversation_id=%d format=%s messages=%d", conv.ID, format, len(messages)))
+
+	filename := fmt.Sprintf("conversation-%d-%s.%s", conv.ID, time.Now().Format("2006-01-02"), format)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+
+	if format == "json" {
+		respondJSON(w, http.StatusOK, ConversationExport{
+			Conversation: conv,
+			ExportedAt:   time.Now().UTC(),
+			ExportedBy:   user.ID,
+			Messages:     messages,
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv")
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"message_id", "sender_id", "sender_name", "sent_at", "delivered_at", "read_at", "encrypted_content", "nonce"})
+	for _, m := range messages {
+		cw.Write([]string{
+			strconv.Itoa(m.ID),
+			strconv.Itoa(m.SenderID),
+			m.SenderName,
+			m.CreatedAt.UTC().Format(time.RFC3339),
+			formatOptionalTime(m.DeliveredAt),
+			formatOptionalTime(m.ReadAt),
+			m.EncryptedContent,
+			m.Nonce,
+		})
+	}
+	cw.Flush()
+}
+
+// formatOptionalTime formats a timestamp as RFC 3339 UTC, or "" if unset
+func formatOptionalTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,377 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/finviz/backend/internal/db"
+	"github.com/finviz/backend/internal/logger"
+	"github.com/finviz/backend/internal/models"
+	"github.com/finviz/backend/internal/storage"
+)
+
+// How long a finished data export stays downloadable
+const dataExportRetention = 7 * 24 * time.Hour
+
+// UserDataExport is the JSON document produced by a GDPR data export. Rows
+// are exported column by column; secrets such as password hashes and MFA
+// secrets are left out.
+type UserDataExport struct {
+	ExportedAt        time.Time                `json:"exported_at"`
+	User              map[string]interface{}   `json:"user"`
+	Assets            []map[string]interface{} `json:"assets"`
+	Debts             []map[string]interface{} `json:"debts"`
+	Transactions      []map[string]interface{} `json:"transactions"`
+	Documents         []ExportedDocument       `json:"documents"`
+	SimulationHistory []map[string]interface{} `json:"simulation_history"`
+	ClientNotes       []map[string]interface{} `json:"client_notes"`
+	Conversations     []map[string]interface{} `json:"conversations"`
+	Messages          []map[string]interface{} `json:"messages"`
+	ClientGoals       []map[string]interface{} `json:"client_goals"`
+}
+
+// ExportedDocument is a document's metadata in a data export. File contents
+// are not included; each document downloads separately.
+type ExportedDocument struct {
+	models.Document
+	DownloadPath string `json:"download_path"`
+}
+
+// handleExportUserData starts building a copy of all the current user's
+// personal data. The export runs in the background; poll the returned job
+// for the document to download.
+// POST /api/me/export
+func handleExportUserData(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+	if user == nil {
+		respondError(w, http.StatusUnauthorized, "Not authenticated")
+		return
+	}
+
+	var pending int
+	db.DB.QueryRow("SELECT COUNT(*) FROM data_exports WHERE user_id = ? AND status = ?",
+		user.ID, models.DataExportStatusPending).Scan(&pending)
+	if pending > 0 {
+		respondError(w, http.StatusConflict, "A data export is already in progress")
+		return
+	}
+
+	result, err := db.DB.Exec("INSERT INTO data_exports (user_id) VALUES (?)", user.ID)
+	if err != nil {
+		logger.FromContext(r.Context()).Errorf("Error creating data export for user %d: %v", user.ID, err)
+		respondError(w, http.StatusInternalServerError, "Failed to start data export")
+		return
+	}
+	exportID, _ := result.LastInsertId()
+
+	logAuditEvent(r, user.ID, AuditActionDataExported, fmt.Sprintf("export_id=%d", exportID))
+
+	go generateUserDataExport(int(exportID), user.ID)
+
+	export, err := getDataExport(int(exportID), user.ID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch data export")
+		return
+	}
+	respondJSON(w, http.StatusAccepted, export)
+}
+
+// handleGetUserDataExport reports the progress of a data export
+// GET /api/me/export/{jobId}
+func handleGetUserDataExport(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+	if user == nil {
+		respondError(w, http.StatusUnauthorized, "Not authenticated")
+		return
+	}
+
+	exportID, err := strconv.Atoi(r.PathValue("jobId"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid job ID")
+		return
+	}
+
+	export, err := getDataExport(exportID, user.ID)
+	if err != nil {
+		respondError(w, http.StatusNotFound, "Data export not found")
+		return
+	}
+	respondJSON(w, http.StatusOK, export)
+}
+
+func getDataExport(exportID, userID int) (models.DataExport, error) {
+	var e models.DataExport
+	err := db.DB.QueryRow(`
+		SELECT id, status, document_id, error, created_at, completed_at, expires_at
+		FROM data_exports WHERE id = ? AND user_id = ?
+	`, exportID, userID).Scan(&e.ID, &e.Status, &e.DocumentID, &e.Error, &e.CreatedAt, &e.CompletedAt, &e.ExpiresAt)
+	return e, err
+}
+
+// generateUserDataExport collects the user's data, files it as a JSON
+// document and marks the export completed, or failed with the reason
+func generateUserDataExport(exportID, userID int) {
+	data, err := collectUserData(userID)
+	var docID int64
+	if err == nil {
+		var payload []byte
+		payload, err = json.MarshalIndent(data, "", "  ")
+		if err == nil {
+			filename := fmt.Sprintf("finviz_data_export_%s.json", time.Now().Format("2006-01-02"))
+			docID, err = SaveDocumentFromBytes(userID, userID, filename, models.DocCategoryOther, "application/json", payload)
+		}
+	}
+
+	if err != nil {
+		logger.Default().Errorf("Error generating data export %d for user %d: %v", exportID, userID, err)
+		db.DB.Exec("UPDATE data_exports SET status = ?, error = ?, completed_at = NOW() WHERE id = ?",
+			models.DataExportStatusFailed, "Failed to generate export", exportID)
+		return
+	}
+
+	_, err = db.DB.Exec(`
+		UPDATE data_exports SET status = ?, document_id = ?, completed_at = NOW(), expires_at = ?
+		WHERE id = ?
+	`, models.DataExportStatusCompleted, docID, time.Now().Add(dataExportRetention), exportID)
+	if err != nil {
+		logger.Default().Errorf("Error completing data export %d: %v", exportID, err)
+	}
+}
+
+// collectUserData gathers every row that belongs to or is about the user
+func collectUserData(userID int) (*UserDataExport, error) {
+	export := &UserDataExport{ExportedAt: time.Now().UTC()}
+
+	users, err := exportRows(`
+		SELECT id, email, name, role, created_by_advisor_id, mfa_enabled, created_at, updated_at
+		FROM users WHERE id = ?
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("users: %w", err)
+	}
+	if len(users) == 0 {
+		return nil, fmt.Errorf("user %d not found", userID)
+	}
+	export.User = users[0]
+
+	sections := []struct {
+		name  string
+		dest  *[]map[string]interface{}
+		query string
+		args  []interface{}
+	}{
+		{"assets", &export.Assets, `
+			SELECT a.id, a.name, t.name AS type, a.current_value, a.custom_return, a.custom_volatility,
+			       a.plaid_account_id, a.created_at, a.updated_at
+			FROM assets a JOIN asset_types t ON t.id = a.type_id
+			WHERE a.user_id = ? AND a.deleted_at IS NULL ORDER BY a.id`, []interface{}{userID}},
+		{"debts", &export.Debts, `
+			SELECT id, name, current_balance, interest_rate, minimum_payment, plaid_account_id, created_at, updated_at
+			FROM debts WHERE user_id = ? ORDER BY id`, []interface{}{userID}},
+		{"transactions", &export.Transactions, `
+			SELECT id, plaid_transaction_id, plaid_account_id, account_name, amount, date, name, merchant_name,
+			       category, subcategory, pending, transaction_type, iso_currency_code, created_at, updated_at
+			FROM transactions WHERE user_id = ? ORDER BY date, id`, []interface{}{userID}},
+		{"client_notes", &export.ClientNotes, `
+			SELECT n.id, n.advisor_id, u.name AS advisor_name, n.note, n.category, n.is_pinned, n.created_at, n.updated_at
+			FROM client_notes n JOIN users u ON u.id = n.advisor_id
+			WHERE n.client_id = ? ORDER BY n.id`, []interface{}{userID}},
+		{"conversations", &export.Conversations, `
+			SELECT c.id, c.advisor_id, c.client_id, c.is_group, c.status, c.last_message_at, c.created_at, p.joined_at
+			FROM conversations c JOIN conversation_participants p ON p.conversation_id = c.id
+			WHERE p.user_id = ? ORDER BY c.id`, []interface{}{userID}},
+		// Messages are exported as stored: E2E encrypted bodies stay encrypted
+		{"messages", &export.Messages, `
+			SELECT m.id, m.conversation_id, m.sender_id, u.name AS sender_name, m.encrypted_content, m.nonce,
+			       m.read_at, m.delivered_at, m.created_at
+			FROM messages m
+			JOIN conversation_participants p ON p.conversation_id = m.conversation_id
+			JOIN users u ON u.id = m.sender_id
+			WHERE p.user_id = ? ORDER BY m.conversation_id, m.created_at, m.id`, []interface{}{userID}},
+		{"client_goals", &export.ClientGoals, `
+			SELECT id, advisor_id, title, description, category, status, priority, target_amount, current_amount,
+			       target_date, completed_at, created_at, updated_at
+			FROM client_goals WHERE client_id = ? ORDER BY id`, []interface{}{userID}},
+	}
+	for _, section := range sections {
+		rows, err := exportRows(section.query, section.args...)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", section.name, err)
+		}
+		*section.dest = rows
+	}
+
+	if export.Documents, err = exportDocuments(userID); err != nil {
+		return nil, fmt.Errorf("documents: %w", err)
+	}
+	if export.SimulationHistory, err = exportSimulations(userID); err != nil {
+		return nil, fmt.Errorf("simulation_history: %w", err)
+	}
+
+	return export, nil
+}
+
+// exportDocuments lists the user's documents without their contents
+func exportDocuments(userID int) ([]ExportedDocument, error) {
+	rows, err := db.DB.Query(`
+		SELECT id, user_id, uploaded_by, name, original_name, mime_type, size, category,
+		       encrypted, description, year, created_at, updated_at, document_id_original
+		FROM documents WHERE user_id = ? AND deleted_at IS NULL ORDER BY id
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	docs := []ExportedDocument{}
+	for rows.Next() {
+		var d ExportedDocument
+		if err := rows.Scan(&d.ID, &d.UserID, &d.UploadedBy, &d.Name, &d.OriginalName, &d.MimeType, &d.Size,
+			&d.Category, &d.Encrypted, &d.Description, &d.Year, &d.CreatedAt, &d.UpdatedAt, &d.DocumentIDOriginal); err != nil {
+			return nil, err
+		}
+		d.DownloadPath = fmt.Sprintf("/api/documents/%d/download", d.ID)
+		docs = append(docs, d)
+	}
+	return docs, rows.Err()
+}
+
+// exportSimulations exports saved simulations with their parameters and
+// results decoded, since results may be stored compressed
+func exportSimulations(userID int) ([]map[string]interface{}, error) {
+	rows, err := db.DB.Query(`
+		SELECT id, run_by_user_id, name, notes, params, results, results_compressed, starting_net_worth,
+		       final_p50, success_rate, time_horizon_years, is_favorite, created_at
+		FROM simulation_history WHERE user_id = ? ORDER BY id
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sims := []map[string]interface{}{}
+	for rows.Next() {
+		var sim models.SimulationHistory
+		var plainResults sql.NullString
+		var compressedResults []byte
+		if err := rows.Scan(&sim.ID, &sim.RunByUserID, &sim.Name, &sim.Notes, &sim.Params, &plainResults,
+			&compressedResults, &sim.StartingNetWorth, &sim.FinalP50, &sim.SuccessRate, &sim.TimeHorizonYears,
+			&sim.IsFavorite, &sim.CreatedAt); err != nil {
+			return nil, err
+		}
+		results, err := db.SimulationResultsJSON(plainResults, compressedResults)
+		if err != nil {
+			return nil, err
+		}
+
+		sims = append(sims, map[string]interface{}{
+			"id":                 sim.ID,
+			"run_by_user_id":     sim.RunByUserID,
+			"name":               sim.Name,
+			"notes":              sim.Notes,
+			"params":             json.RawMessage(sim.Params),
+			"results":            optionalRawJSON(results),
+			"starting_net_worth": sim.StartingNetWorth,
+			"final_p50":          sim.FinalP50,
+			"success_rate":       sim.SuccessRate,
+			"time_horizon_years": sim.TimeHorizonYears,
+			"is_favorite":        sim.IsFavorite,
+			"created_at":         sim.CreatedAt,
+		})
+	}
+	return sims, rows.Err()
+}
+
+func optionalRawJSON(b []byte) interface{} {
+	if len(b) == 0 {
+		return nil
+	}
+	return json.RawMessage(b)
+}
+
+// exportRows runs a query and returns each row as a column name to value map.
+// Text comes back from the driver as bytes and DECIMALs as numeric text, so
+// both are converted to JSON-friendly values.
+func exportRows(query string, args ...interface{}) ([]map[string]interface{}, error) {
+	rows, err := db.DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+
+	result := []map[string]interface{}{}
+	for rows.Next() {
+		values := make([]interface{}, len(columns))
+		dest := make([]interface{}, len(columns))
+		for i := range values {
+			dest[i] = &values[i]
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+
+		row := make(map[string]interface{}, len(columns))
+		for i, col := range columns {
+			v := values[i]
+			if b, ok := v.([]byte); ok {
+				if col.DatabaseTypeName() == "DECIMAL" {
+					if f, err := strconv.ParseFloat(string(b), 64); err == nil {
+						v = f
+					} else {
+						v = string(b)
+					}
+				} else {
+					v = string(b)
+				}
+			}
+			row[col.Name()] = v
+		}
+		result = append(result, row)
+	}
+	return result, rows.Err()
+}
+
+// expireDataExports deletes export files past their retention period. The
+// document row and file are removed outright rather than soft-deleted, since
+// they hold a full copy of the user's data.
+func expireDataExports() {
+	rows, err := db.DB.Query(`
+		SELECT e.id, d.id, d.storage_path
+		FROM data_exports e JOIN documents d ON d.id = e.document_id
+		WHERE e.expires_at < NOW()
+	`)
+	if err != nil {
+		logger.Default().Errorf("Error finding expired data exports: %v", err)
+		return
+	}
+	type expired struct {
+		exportID, docID int
+		storagePath     string
+	}
+	var exports []expired
+	for rows.Next() {
+		var e expired
+		if err := rows.Scan(&e.exportID, &e.docID, &e.storagePath); err == nil {
+			exports = append(exports, e)
+		}
+	}
+	rows.Close()
+
+	for _, e := range exports {
+		if err := storage.DefaultStorage.Delete(e.storagePath); err != nil {
+			logger.Default().Errorf("Error deleting data export file %d: %v", e.exportID, err)
+		}
+		if _, err := db.DB.Exec("DELETE FROM documents WHERE id = ?", e.docID); err != nil {
+			logger.Default().Errorf("Error deleting data export document %d: %v", e.exportID, err)
+		}
+	}
+}
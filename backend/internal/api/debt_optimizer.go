@@ -0,0 +1,234 @@
+package api
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"sort"
+
+	"github.com/finviz/backend/internal/models"
+)
+
+// Payoff schedules stop after this many months if debts remain
+const maxPayoffMonths = 50 * 12
+
+// Under the hybrid strategy, debts the extra payment (plus their own minimum)
+// clears within this many months are paid off first as quick wins
+const hybridQuickWinMonths = 3
+
+var payoffStrategies = []string{models.PayoffStrategyAvalanche, models.PayoffStrategySnowball, models.PayoffStrategyHybrid}
+
+// handleOptimizeDebtPayoff compares strategies for paying off the user's
+// debts with an extra monthly payment on top of the minimums
+// POST /api/debts/optimize-payoff
+func handleOptimizeDebtPayoff(w http.ResponseWriter, r *http.Request) {
+	userID := getEffectiveUserID(r)
+	if userID == 0 {
+		respondError(w, http.StatusUnauthorized, "Not authenticated")
+		return
+	}
+
+	var req models.DebtPayoffRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if req.ExtraMonthlyPayment < 0 {
+		respondError(w, http.StatusBadRequest, "extraMonthlyPayment can't be negative")
+		return
+	}
+
+	strategies := payoffStrategies
+	if req.Strategy != "" {
+		switch req.Strategy {
+		case models.PayoffStrategyAvalanche, models.PayoffStrategySnowball, models.PayoffStrategyHybrid:
+			strategies = []string{req.Strategy}
+		default:
+			respondError(w, http.StatusBadRequest, "Invalid strategy. Use 'avalanche', 'snowball', or 'hybrid'")
+			return
+		}
+	}
+
+	debts, err := fetchDebtsForUser(userID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch debts")
+		return
+	}
+
+	plans := []models.DebtPayoffPlan{}
+	for _, strategy := range strategies {
+		plans = append(plans, simulateDebtPayoff(debts, strategy, req.ExtraMonthlyPayment))
+	}
+	respondJSON(w, http.StatusOK, plans)
+}
+
+// payoffDebt is a debt's state during a payoff simulation
+type payoffDebt struct {
+	debt         models.Debt
+	balance      float64
+	rate         float64 // annual percentage
+	minimum      float64
+	interestPaid float64
+	payoffMonth  int
+}
+
+// simulateDebtPayoff pays debts down month by month. Each month interest
+// compounds as in the Monte Carlo simulation, every debt gets its minimum
+// payment, and the rest of the budget (the extra payment plus minimums freed
+// by debts already paid off) goes to debts in strategy order.
+func simulateDebtPayoff(debts []models.Debt, strategy string, extra float64) models.DebtPayoffPlan {
+	plan := models.DebtPayoffPlan{Strategy: strategy, PayoffOrder: []models.DebtPayoff{}, DebtSchedule: []models.PayoffYear{}}
+
+	var active []*payoffDebt
+	budget := extra
+	for _, d := range debts {
+		if d.CurrentBalance <= 0 {
+			continue
+		}
+		pd := &payoffDebt{debt: d, balance: d.CurrentBalance}
+		if d.InterestRate != nil {
+			pd.rate = *d.InterestRate
+		}
+		if d.MinimumPayment != nil {
+			pd.minimum = *d.MinimumPayment
+		}
+		budget += pd.minimum
+		active = append(active, pd)
+	}
+	orderPayoffDebts(active, strategy, extra)
+
+	year := models.PayoffYear{Year: 1, StartingBalance: totalPayoffBalance(active)}
+	remaining := len(active)
+	month := 0
+	for remaining > 0 && month < maxPayoffMonths {
+		month++
+
+		for _, pd := range active {
+			if pd.balance > 0 && pd.rate > 0 {
+				interest := pd.balance * pd.rate / 100.0 / 12.0
+				pd.balance += interest
+				pd.interestPaid += interest
+				year.InterestPaid += interest
+			}
+		}
+
+		available := budget
+		pay := func(pd *payoffDebt, amount float64) {
+			payment := math.Min(amount, pd.balance)
+			pd.balance -= payment
+			available -= payment
+		}
+		for _, pd := range active {
+			if pd.balance > 0 {
+				pay(pd, math.Min(pd.minimum, available))
+			}
+		}
+		for _, pd := range active {
+			if available <= 0 {
+				break
+			}
+			if pd.balance > 0 {
+				pay(pd, available)
+			}
+		}
+
+		for _, pd := range active {
+			// Ignore sub-cent residue from floating point
+			if pd.payoffMonth == 0 && pd.balance < 0.005 {
+				pd.balance = 0
+				pd.payoffMonth = month
+				remaining--
+				plan.PayoffOrder = append(plan.PayoffOrder, models.DebtPayoff{
+					DebtID:       pd.debt.ID,
+					Name:         pd.debt.Name,
+					PayoffMonth:  month,
+					InterestPaid: roundCents(pd.interestPaid),
+				})
+			}
+		}
+
+		if month%12 == 0 || remaining == 0 || month == maxPayoffMonths {
+			plan.DebtSchedule = append(plan.DebtSchedule, closePayoffYear(year, active))
+			year = models.PayoffYear{Year: year.Year + 1, StartingBalance: totalPayoffBalance(active)}
+		}
+	}
+
+	// Debts the budget never cleared, listed after those it did
+	for _, pd := range active {
+		if pd.payoffMonth == 0 {
+			plan.PayoffOrder = append(plan.PayoffOrder, models.DebtPayoff{
+				DebtID:       pd.debt.ID,
+				Name:         pd.debt.Name,
+				InterestPaid: roundCents(pd.interestPaid),
+			})
+		}
+	}
+
+	var interest float64
+	for _, pd := range active {
+		interest += pd.interestPaid
+	}
+	plan.TotalInterestPaid = roundCents(interest)
+	plan.TotalMonths = month
+	plan.PaidOff = remaining == 0
+	return plan
+}
+
+// orderPayoffDebts sorts debts into the order a strategy sends extra payments
+func orderPayoffDebts(debts []*payoffDebt, strategy string, extra float64) {
+	byRate := func(a, b *payoffDebt) bool {
+		if a.rate != b.rate {
+			return a.rate > b.rate
+		}
+		return a.balance < b.balance
+	}
+	byBalance := func(a, b *payoffDebt) bool {
+		if a.balance != b.balance {
+			return a.balance < b.balance
+		}
+		return a.rate > b.rate
+	}
+
+	switch strategy {
+	case models.PayoffStrategySnowball:
+		sort.SliceStable(debts, func(i, j int) bool { return byBalance(debts[i], debts[j]) })
+	case models.PayoffStrategyHybrid:
+		quickWin := func(pd *payoffDebt) bool {
+			return pd.balance <= (extra+pd.minimum)*hybridQuickWinMonths
+		}
+		sort.SliceStable(debts, func(i, j int) bool {
+			qi, qj := quickWin(debts[i]), quickWin(debts[j])
+			if qi != qj {
+				return qi
+			}
+			if qi {
+				return byBalance(debts[i], debts[j])
+			}
+			return byRate(debts[i], debts[j])
+		})
+	default:
+		sort.SliceStable(debts, func(i, j int) bool { return byRate(debts[i], debts[j]) })
+	}
+}
+
+// closePayoffYear rounds a year's totals and records each debt's balance
+func closePayoffYear(year models.PayoffYear, debts []*payoffDebt) models.PayoffYear {
+	year.BalancesByDebtID = make(map[int]float64, len(debts))
+	for _, pd := range debts {
+		year.BalancesByDebtID[pd.debt.ID] = roundCents(pd.balance)
+	}
+	year.StartingBalance = roundCents(year.StartingBalance)
+	year.InterestPaid = roundCents(year.InterestPaid)
+	year.EndingBalance = roundCents(totalPayoffBalance(debts))
+	// Payments beyond the year's interest went to principal
+	year.PrincipalPaid = roundCents(year.StartingBalance - year.EndingBalance)
+	return year
+}
+
+func totalPayoffBalance(debts []*payoffDebt) float64 {
+	var total float64
+	for _, pd := range debts {
+		total += pd.balance
+	}
+	return total
+}
@@ -18,7 +18,7 @@ func handleGetDebts(w http.ResponseWriter, r *http.Request) {
 	}
 
 	rows, err := db.DB.Query(`
-		SELECT id, user_id, name, current_balance, interest_rate, minimum_payment, plaid_account_id, created_at, updated_at
+		SELECT id, user_id, name, current_balance, interest_rate, minimum_payment, plaid_account_id, debt_subtype, created_at, updated_at
 		FROM debts
 		WHERE user_id = ?
 		ORDER BY name
@@ -33,8 +33,8 @@ func handleGetDebts(w http.ResponseWriter, r *http.Request) {
 	for rows.Next() {
 		var d models.Debt
 		var interestRate, minimumPayment sql.NullFloat64
-		var plaidAccountID sql.NullString
-		if err := rows.Scan(&d.ID, &d.UserID, &d.Name, &d.CurrentBalance, &interestRate, &minimumPayment, &plaidAccountID, &d.CreatedAt, &d.UpdatedAt); err != nil {
+		var plaidAccountID, debtSubtype sql.NullString
+		if err := rows.Scan(&d.ID, &d.UserID, &d.Name, &d.CurrentBalance, &interestRate, &minimumPayment, &plaidAccountID, &debtSubtype, &d.CreatedAt, &d.UpdatedAt); err != nil {
 			respondError(w, http.StatusInternalServerError, err.Error())
 			return
 		}
@@ -47,6 +47,9 @@ func handleGetDebts(w http.ResponseWriter, r *http.Request) {
 		if plaidAccountID.Valid {
 			d.PlaidAccountID = &plaidAccountID.String
 		}
+		if debtSubtype.Valid {
+			d.DebtSubtype = &debtSubtype.String
+		}
 		debts = append(debts, d)
 	}
 
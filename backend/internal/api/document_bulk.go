@@ -0,0 +1,144 @@
+package api
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/finviz/backend/internal/db"
+	"github.com/finviz/backend/internal/logger"
+	"github.com/finviz/backend/internal/models"
+	"github.com/finviz/backend/internal/storage"
+)
+
+const (
+	// Largest ZIP a bulk download will build in memory
+	maxBulkDownloadBytes = 100 << 20
+
+	// Most documents one bulk download can name
+	maxBulkDownloadDocuments = 200
+)
+
+// HandleDocumentBulkDownload serves several documents as one ZIP archive.
+// Documents are added in the requested order; if their total size would pass
+// 100MB nothing is served and the 413 response lists the documents that
+// didn't fit.
+// POST /api/documents/bulk-download
+func HandleDocumentBulkDownload(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req models.BulkDownloadRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if len(req.DocumentIDs) == 0 {
+		http.Error(w, "document_ids is required", http.StatusBadRequest)
+		return
+	}
+	if len(req.DocumentIDs) > maxBulkDownloadDocuments {
+		http.Error(w, fmt.Sprintf("At most %d documents can be downloaded at once", maxBulkDownloadDocuments), http.StatusBadRequest)
+		return
+	}
+
+	// Check every document before loading any file
+	var docs []models.Document
+	seen := make(map[int]bool)
+	for _, id := range req.DocumentIDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		var doc models.Document
+		err := db.DB.QueryRow(`
+			SELECT id, user_id, uploaded_by, name, original_name, mime_type, size, storage_path, encrypted
+			FROM documents WHERE id = ? AND deleted_at IS NULL
+		`, id).Scan(&doc.ID, &doc.UserID, &doc.UploadedBy, &doc.Name, &doc.OriginalName, &doc.MimeType, &doc.Size, &doc.StoragePath, &doc.Encrypted)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Document %d not found", id), http.StatusNotFound)
+			return
+		}
+		if !canAccessDocument(user, &doc) {
+			http.Error(w, fmt.Sprintf("Access denied to document %d", id), http.StatusForbidden)
+			return
+		}
+		docs = append(docs, doc)
+	}
+
+	var total int64
+	var excluded []models.ExcludedDocument
+	for _, doc := range docs {
+		if total+doc.Size > maxBulkDownloadBytes {
+			excluded = append(excluded, models.ExcludedDocument{ID: doc.ID, Name: doc.Name, Size: doc.Size})
+			continue
+		}
+		total += doc.Size
+	}
+	if len(excluded) > 0 {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusRequestEntityTooLarge)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":     "Selected documents exceed the 100MB download limit",
+			"excluded":  excluded,
+			"max_bytes": maxBulkDownloadBytes,
+		})
+		return
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	names := make(map[string]int)
+	for _, doc := range docs {
+		data, err := storage.DefaultStorage.Load(doc.StoragePath, doc.Encrypted)
+		if err != nil {
+			logger.FromContext(r.Context()).Errorf("Error loading document %d for bulk download: %v", doc.ID, err)
+			http.Error(w, fmt.Sprintf("Failed to load document %d", doc.ID), http.StatusInternalServerError)
+			return
+		}
+
+		f, err := zw.Create(uniqueArchiveName(doc.OriginalName, names))
+		if err != nil {
+			http.Error(w, "Failed to build archive", http.StatusInternalServerError)
+			return
+		}
+		if _, err := f.Write(data); err != nil {
+			http.Error(w, "Failed to build archive", http.StatusInternalServerError)
+			return
+		}
+	}
+	if err := zw.Close(); err != nil {
+		http.Error(w, "Failed to build archive", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", `attachment; filename="documents.zip"`)
+	w.Header().Set("Content-Length", strconv.Itoa(buf.Len()))
+	w.Write(buf.Bytes())
+}
+
+// uniqueArchiveName returns a flat file name for a ZIP entry, numbering
+// repeats as "name (2).ext"
+func uniqueArchiveName(name string, used map[string]int) string {
+	name = path.Base(strings.ReplaceAll(name, "\\", "/"))
+	if name == "." || name == "/" {
+		name = "document"
+	}
+
+	used[name]++
+	if used[name] == 1 {
+		return name
+	}
+	ext := path.Ext(name)
+	return fmt.Sprintf("%s (%d)%s", strings.TrimSuffix(name, ext), used[name], ext)
+}
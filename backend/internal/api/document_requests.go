@@ -0,0 +1,359 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/finviz/backend/internal/db"
+	"github.com/finviz/backend/internal/logger"
+	"github.com/finviz/backend/internal/models"
+)
+
+// documentRequestColumns is the select list scanned by scanDocumentRequest
+const documentRequestColumns = `
+	dr.id, dr.advisor_id, dr.client_id, dr.title, dr.description, dr.category,
+	DATE_FORMAT(dr.due_date, '%Y-%m-%d'), dr.status, dr.fulfilled_document_id, dr.fulfilled_at,
+	dr.status = 'pending' AND dr.due_date IS NOT NULL AND dr.due_date < CURDATE(),
+	u.name, dr.created_at, dr.updated_at`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanDocumentRequest reads one row selected with documentRequestColumns
+func scanDocumentRequest(row rowScanner) (models.DocumentRequest, error) {
+	var req models.DocumentRequest
+	var description, dueDate sql.NullString
+	var fulfilledDocID sql.NullInt64
+	var fulfilledAt sql.NullTime
+	err := row.Scan(
+		&req.ID, &req.AdvisorID, &req.ClientID, &req.Title, &description, &req.Category,
+		&dueDate, &req.Status, &fulfilledDocID, &fulfilledAt,
+		&req.Overdue, &req.AdvisorName, &req.CreatedAt, &req.UpdatedAt,
+	)
+	if err != nil {
+		return req, err
+	}
+	if description.Valid {
+		req.Description = &description.String
+	}
+	if dueDate.Valid {
+		req.DueDate = &dueDate.String
+	}
+	if fulfilledDocID.Valid {
+		id := int(fulfilledDocID.Int64)
+		req.FulfilledDocumentID = &id
+	}
+	if fulfilledAt.Valid {
+		req.FulfilledAt = &fulfilledAt.Time
+	}
+	return req, nil
+}
+
+// queryDocumentRequests runs a document request query filtered by where
+func queryDocumentRequests(where string, args ...interface{}) ([]models.DocumentRequest, error) {
+	rows, err := db.DB.Query(`
+		SELECT `+documentRequestColumns+`
+		FROM document_requests dr
+		JOIN users u ON u.id = dr.advisor_id
+		WHERE `+where+`
+		ORDER BY dr.status = 'pending' DESC, dr.due_date IS NULL, dr.due_date ASC, dr.created_at DESC
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	requests := []models.DocumentRequest{}
+	for rows.Next() {
+		req, err := scanDocumentRequest(rows)
+		if err != nil {
+			return nil, err
+		}
+		requests = append(requests, req)
+	}
+	return requests, rows.Err()
+}
+
+// getDocumentRequest loads one request by ID
+func getDocumentRequest(id int) (models.DocumentRequest, error) {
+	return scanDocumentRequest(db.DB.QueryRow(`
+		SELECT `+documentRequestColumns+`
+		FROM document_requests dr
+		JOIN users u ON u.id = dr.advisor_id
+		WHERE dr.id = ?
+	`, id))
+}
+
+// validDueDate checks a YYYY-MM-DD due date
+func validDueDate(s string) bool {
+	_, err := time.Parse("2006-01-02", s)
+	return err == nil
+}
+
+// advisorClientFromPath parses the clientId path value and checks the advisor
+// can access that client. Writes the error response and returns false otherwise.
+func advisorClientFromPath(w http.ResponseWriter, r *http.Request) (*models.User, int, bool) {
+	user := getUserFromContext(r)
+	if user == nil || !user.IsAdvisor() {
+		respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return nil, 0, false
+	}
+
+	clientID, err := strconv.Atoi(r.PathValue("clientId"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid client ID")
+		return nil, 0, false
+	}
+
+	if !db.AdvisorHasClientAccess(user.ID, clientID) {
+		respondError(w, http.StatusForbidden, "Access denied")
+		return nil, 0, false
+	}
+	return user, clientID, true
+}
+
+// handleListDocumentRequests lists the advisor's document requests for a client
+// GET /api/advisor/clients/{clientId}/document-requests?status=pending
+func handleListDocumentRequests(w http.ResponseWriter, r *http.Request) {
+	user, clientID, ok := advisorClientFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	where := "dr.advisor_id = ? AND dr.client_id = ?"
+	args := []interface{}{user.ID, clientID}
+	if status := r.URL.Query().Get("status"); status != "" {
+		if status != models.DocRequestStatusPending && status != models.DocRequestStatusFulfilled && status != models.DocRequestStatusCancelled {
+			respondError(w, http.StatusBadRequest, "Invalid status. Use 'pending', 'fulfilled', or 'cancelled'")
+			return
+		}
+		where += " AND dr.status = ?"
+		args = append(args, status)
+	}
+
+	requests, err := queryDocumentRequests(where, args...)
+	if err != nil {
+		logger.FromContext(r.Context()).Errorf("Error fetching document requests: %v", err)
+		respondError(w, http.StatusInternalServerError, "Failed to fetch document requests")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, requests)
+}
+
+// handleCreateDocumentRequest asks a client to upload a document
+// POST /api/advisor/clients/{clientId}/document-requests
+func handleCreateDocumentRequest(w http.ResponseWriter, r *http.Request) {
+	user, clientID, ok := advisorClientFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.DocumentRequestCreate
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	req.Title = strings.TrimSpace(req.Title)
+	if req.Title == "" {
+		respondError(w, http.StatusBadRequest, "Title is required")
+		return
+	}
+	if req.Category == "" {
+		req.Category = models.DocCategoryOther
+	}
+	if !models.IsValidCategory(req.Category) {
+		respondError(w, http.StatusBadRequest, "Invalid category")
+		return
+	}
+	if req.DueDate != nil && *req.DueDate == "" {
+		req.DueDate = nil
+	}
+	if req.DueDate != nil && !validDueDate(*req.DueDate) {
+		respondError(w, http.StatusBadRequest, "Invalid due_date. Use YYYY-MM-DD")
+		return
+	}
+
+	result, err := db.DB.Exec(`
+		INSERT INTO document_requests (advisor_id, client_id, title, description, category, due_date)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, user.ID, clientID, req.Title, req.Description, req.Category, req.DueDate)
+	if err != nil {
+		logger.FromContext(r.Context()).Errorf("Error creating document request: %v", err)
+		respondError(w, http.StatusInternalServerError, "Failed to create document request")
+		return
+	}
+
+	id, _ := result.LastInsertId()
+	created, err := getDocumentRequest(int(id))
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch created document request")
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, created)
+}
+
+// advisorDocumentRequestFromPath loads the request named by the requestId path
+// value, if the advisor made it for the client. Writes the error response and
+// returns false otherwise.
+func advisorDocumentRequestFromPath(w http.ResponseWriter, r *http.Request, advisorID, clientID int) (models.DocumentRequest, bool) {
+	requestID, err := strconv.Atoi(r.PathValue("requestId"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request ID")
+		return models.DocumentRequest{}, false
+	}
+
+	existing, err := getDocumentRequest(requestID)
+	if err == sql.ErrNoRows || (err == nil && (existing.AdvisorID != advisorID || existing.ClientID != clientID)) {
+		respondError(w, http.StatusNotFound, "Document request not found")
+		return existing, false
+	}
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch document request")
+		return existing, false
+	}
+	return existing, true
+}
+
+// handleUpdateDocumentRequest edits or cancels a document request
+// PUT /api/advisor/clients/{clientId}/document-requests/{requestId}
+func handleUpdateDocumentRequest(w http.ResponseWriter, r *http.Request) {
+	user, clientID, ok := advisorClientFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	existing, ok := advisorDocumentRequestFromPath(w, r, user.ID, clientID)
+	if !ok {
+		return
+	}
+
+	var req models.DocumentRequestUpdate
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if existing.Status == models.DocRequestStatusFulfilled {
+		respondError(w, http.StatusConflict, "Fulfilled document requests cannot be changed")
+		return
+	}
+
+	if req.Title != nil {
+		title := strings.TrimSpace(*req.Title)
+		if title == "" {
+			respondError(w, http.StatusBadRequest, "Title cannot be empty")
+			return
+		}
+		existing.Title = title
+	}
+	if req.Description != nil {
+		existing.Description = req.Description
+	}
+	if req.Category != nil {
+		if !models.IsValidCategory(*req.Category) {
+			respondError(w, http.StatusBadRequest, "Invalid category")
+			return
+		}
+		existing.Category = *req.Category
+	}
+	if req.DueDate != nil {
+		if *req.DueDate == "" {
+			existing.DueDate = nil
+		} else if !validDueDate(*req.DueDate) {
+			respondError(w, http.StatusBadRequest, "Invalid due_date. Use YYYY-MM-DD")
+			return
+		} else {
+			existing.DueDate = req.DueDate
+		}
+	}
+	if req.Status != nil {
+		if *req.Status != models.DocRequestStatusPending && *req.Status != models.DocRequestStatusCancelled {
+			respondError(w, http.StatusBadRequest, "Invalid status. Use 'pending' or 'cancelled'")
+			return
+		}
+		existing.Status = *req.Status
+	}
+
+	_, err := db.DB.Exec(`
+		UPDATE document_requests SET title = ?, description = ?, category = ?, due_date = ?, status = ?
+		WHERE id = ?
+	`, existing.Title, existing.Description, existing.Category, existing.DueDate, existing.Status, existing.ID)
+	if err != nil {
+		logger.FromContext(r.Context()).Errorf("Error updating document request: %v", err)
+		respondError(w, http.StatusInternalServerError, "Failed to update document request")
+		return
+	}
+
+	updated, err := getDocumentRequest(existing.ID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch updated document request")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, updated)
+}
+
+// handleDeleteDocumentRequest deletes a document request. The uploaded
+// document of a fulfilled request is kept.
+// DELETE /api/advisor/clients/{clientId}/document-requests/{requestId}
+func handleDeleteDocumentRequest(w http.ResponseWriter, r *http.Request) {
+	user, clientID, ok := advisorClientFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	existing, ok := advisorDocumentRequestFromPath(w, r, user.ID, clientID)
+	if !ok {
+		return
+	}
+
+	if _, err := db.DB.Exec("DELETE FROM document_requests WHERE id = ?", existing.ID); err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to delete document request")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{"message": "Document request deleted successfully"})
+}
+
+// handleGetMyDocumentRequests lists the pending document requests of the
+// current client, overdue ones first
+// GET /api/me/document-requests
+func handleGetMyDocumentRequests(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+	if user == nil {
+		respondError(w, http.StatusUnauthorized, "Not authenticated")
+		return
+	}
+
+	requests, err := queryDocumentRequests("dr.client_id = ? AND dr.status = 'pending'", user.ID)
+	if err != nil {
+		logger.FromContext(r.Context()).Errorf("Error fetching document requests: %v", err)
+		respondError(w, http.StatusInternalServerError, "Failed to fetch document requests")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, requests)
+}
+
+// fulfillDocumentRequest marks a client's pending request as fulfilled by an
+// uploaded document. It returns false if no such pending request exists.
+func fulfillDocumentRequest(requestID, clientID int, docID int64) (bool, error) {
+	result, err := db.DB.Exec(`
+		UPDATE document_requests
+		SET status = 'fulfilled', fulfilled_document_id = ?, fulfilled_at = NOW()
+		WHERE id = ? AND client_id = ? AND status = 'pending'
+	`, docID, requestID, clientID)
+	if err != nil {
+		return false, err
+	}
+	n, _ := result.RowsAffected()
+	return n > 0, nil
+}
@@ -0,0 +1,194 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/finviz/backend/internal/db"
+	"github.com/finviz/backend/internal/logger"
+	"github.com/finviz/backend/internal/models"
+)
+
+// Characters of context kept before the first match in a context snippet
+const snippetLeadChars = 60
+
+var (
+	documentsFullTextOnce      sync.Once
+	documentsFullTextAvailable bool
+)
+
+// HandleDocumentSearch searches the extracted text of every document the user
+// can read: their own, ones shared with them, and for advisors their clients'.
+// GET /api/documents/search?q=capital+gains&category=tax_returns&client_id=12
+func HandleDocumentSearch(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	q := r.URL.Query()
+	search := strings.TrimSpace(q.Get("q"))
+	if search == "" {
+		http.Error(w, "Search text is required", http.StatusBadRequest)
+		return
+	}
+
+	conditions := []string{
+		"d.deleted_at IS NULL",
+		`(d.user_id = ? OR d.uploaded_by = ?
+		  OR EXISTS (SELECT 1 FROM document_shares s
+		             WHERE s.document_id = d.id AND s.shared_with_id = ?
+		               AND s.revoked_at IS NULL AND (s.expires_at IS NULL OR s.expires_at > NOW()))
+		  OR d.user_id IN (SELECT client_id FROM advisor_clients WHERE advisor_id = ? AND status = 'active'))`,
+	}
+	args := []interface{}{user.ID, user.ID, user.ID, user.ID}
+
+	if category := q.Get("category"); category != "" {
+		if !models.IsValidCategory(category) {
+			http.Error(w, "Invalid category", http.StatusBadRequest)
+			return
+		}
+		conditions = append(conditions, "d.category = ?")
+		args = append(args, category)
+	}
+	if clientIDStr := q.Get("client_id"); clientIDStr != "" {
+		clientID, err := strconv.Atoi(clientIDStr)
+		if err != nil {
+			http.Error(w, "Invalid client ID", http.StatusBadRequest)
+			return
+		}
+		conditions = append(conditions, "d.user_id = ?")
+		args = append(args, clientID)
+	}
+
+	limit := 50
+	if limitStr := q.Get("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 100 {
+			limit = l
+		}
+	}
+	offset := 0
+	if offsetStr := q.Get("offset"); offsetStr != "" {
+		if o, err := strconv.Atoi(offsetStr); err == nil && o >= 0 {
+			offset = o
+		}
+	}
+
+	// FULLTEXT when available, LIKE otherwise
+	searchMode := "like"
+	relevanceExpr := ""
+	var relevanceArgs []interface{}
+	booleanQuery := buildBooleanModeQuery(search)
+	if booleanQuery != "" && documentsFullTextEnabled() {
+		searchMode = "fulltext"
+		relevanceExpr = "MATCH(t.extracted_text) AGAINST (? IN BOOLEAN MODE)"
+		relevanceArgs = []interface{}{booleanQuery}
+		conditions = append(conditions, relevanceExpr)
+		args = append(args, booleanQuery)
+	} else {
+		conditions = append(conditions, "t.extracted_text LIKE ?")
+		args = append(args, "%"+search+"%")
+	}
+
+	whereClause := strings.Join(conditions, " AND ")
+	fromClause := `FROM documents d
+		JOIN document_text_index t ON t.document_id = d.id
+		JOIN users u ON u.id = d.user_id`
+
+	var totalCount int
+	if err := db.DB.QueryRow("SELECT COUNT(*) "+fromClause+" WHERE "+whereClause, args...).Scan(&totalCount); err != nil {
+		logger.FromContext(r.Context()).Errorf("Error counting document search results: %v", err)
+		http.Error(w, "Failed to search documents", http.StatusInternalServerError)
+		return
+	}
+
+	selectCols := `d.id, d.user_id, d.uploaded_by, d.name, d.original_name, d.mime_type, d.size, d.category,
+		d.encrypted, d.description, d.year, d.created_at, d.updated_at, u.name, t.extracted_text, t.extraction_confidence`
+	orderBy := "d.created_at DESC"
+	queryArgs := []interface{}{}
+	if relevanceExpr != "" {
+		selectCols += ", " + relevanceExpr + " AS relevance"
+		queryArgs = append(queryArgs, relevanceArgs...)
+		orderBy = "relevance DESC, d.created_at DESC"
+	}
+	queryArgs = append(queryArgs, args...)
+	queryArgs = append(queryArgs, limit, offset)
+
+	rows, err := db.DB.Query(fmt.Sprintf(`SELECT %s %s WHERE %s ORDER BY %s LIMIT ? OFFSET ?`,
+		selectCols, fromClause, whereClause, orderBy), queryArgs...)
+	if err != nil {
+		logger.FromContext(r.Context()).Errorf("Error searching documents: %v", err)
+		http.Error(w, "Failed to search documents", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	results := []models.DocumentSearchResult{}
+	for rows.Next() {
+		var res models.DocumentSearchResult
+		var text string
+		var relevance float64
+		dest := []interface{}{
+			&res.ID, &res.UserID, &res.UploadedBy, &res.Name, &res.OriginalName, &res.MimeType, &res.Size, &res.Category,
+			&res.Encrypted, &res.Description, &res.Year, &res.CreatedAt, &res.UpdatedAt, &res.OwnerName, &text, &res.ExtractionConfidence,
+		}
+		if relevanceExpr != "" {
+			dest = append(dest, &relevance)
+		}
+		if err := rows.Scan(dest...); err != nil {
+			http.Error(w, "Failed to parse search results", http.StatusInternalServerError)
+			return
+		}
+		if relevanceExpr != "" {
+			res.Relevance = &relevance
+		}
+		res.Snippet = buildContextSnippet(text, search)
+		results = append(results, res)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(models.DocumentSearchResponse{
+		Results:    results,
+		TotalCount: totalCount,
+		SearchMode: searchMode,
+	})
+}
+
+// documentsFullTextEnabled reports whether the document_text_index FULLTEXT
+// index can be used. The result is cached for the process.
+func documentsFullTextEnabled() bool {
+	documentsFullTextOnce.Do(func() {
+		documentsFullTextAvailable = fullTextIndexExists("document_text_index")
+	})
+	return documentsFullTextAvailable
+}
+
+// buildContextSnippet is buildNoteSnippet applied from a little before the
+// first match, for long text where matches are rarely near the start
+func buildContextSnippet(text, search string) string {
+	var terms []string
+	for _, word := range strings.Fields(booleanModeOperators.ReplaceAllString(search, " ")) {
+		terms = append(terms, regexp.QuoteMeta(word))
+	}
+	if len(terms) == 0 {
+		return buildNoteSnippet(text, search)
+	}
+
+	loc := regexp.MustCompile("(?i)" + strings.Join(terms, "|")).FindStringIndex(text)
+	if loc == nil {
+		return buildNoteSnippet(text, search)
+	}
+
+	runes := []rune(text)
+	start := len([]rune(text[:loc[0]])) - snippetLeadChars
+	if start <= 0 {
+		return buildNoteSnippet(text, search)
+	}
+	return "..." + buildNoteSnippet(string(runes[start:]), search)
+}
@@ -0,0 +1,151 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/finviz/backend/internal/db"
+	"github.com/finviz/backend/internal/logger"
+	"github.com/finviz/backend/internal/models"
+)
+
+// maintenance_stats counter for expired shares retired by the cleanup job
+const statSharesCleanedUp = "shares_cleaned_up"
+
+// HandleDocumentShares returns a document's active shares and its share history.
+// GET /api/documents/{id}/shares
+func HandleDocumentShares(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	docID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid document ID", http.StatusBadRequest)
+		return
+	}
+
+	var doc models.Document
+	err = db.DB.QueryRow(`
+		SELECT id, user_id, uploaded_by FROM documents WHERE id = ? AND deleted_at IS NULL
+	`, docID).Scan(&doc.ID, &doc.UserID, &doc.UploadedBy)
+	if err != nil {
+		http.Error(w, "Document not found", http.StatusNotFound)
+		return
+	}
+
+	// Same people who can share a document can audit its shares
+	canView := doc.UploadedBy == user.ID || doc.UserID == user.ID
+	if !canView && user.Role == "advisor" {
+		canView = db.AdvisorHasClientAccess(user.ID, doc.UserID)
+	}
+	if !canView {
+		http.Error(w, "Access denied", http.StatusForbidden)
+		return
+	}
+
+	rows, err := db.DB.Query(`
+		SELECT ds.id, ds.document_id, ds.shared_with_id, ds.shared_by_id, ds.permission,
+		       ds.created_at, ds.expires_at, ds.revoked_at, ds.revoked_reason,
+		       COALESCE(sw.name, ''), COALESCE(sb.name, '')
+		FROM document_shares ds
+		LEFT JOIN users sw ON ds.shared_with_id = sw.id
+		LEFT JOIN users sb ON ds.shared_by_id = sb.id
+		WHERE ds.document_id = ?
+		ORDER BY ds.created_at DESC, ds.id DESC
+	`, docID)
+	if err != nil {
+		http.Error(w, "Failed to fetch shares", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	resp := models.DocumentSharesResponse{
+		ActiveShares: []models.DocumentShare{},
+		ShareHistory: []models.DocumentShare{},
+	}
+	for rows.Next() {
+		var share models.DocumentShare
+		var expiresAt, revokedAt sql.NullTime
+		var revokedReason sql.NullString
+		if err := rows.Scan(&share.ID, &share.DocumentID, &share.SharedWithID, &share.SharedByID, &share.Permission,
+			&share.CreatedAt, &expiresAt, &revokedAt, &revokedReason,
+			&share.SharedWithName, &share.SharedByName); err != nil {
+			continue
+		}
+		if expiresAt.Valid {
+			share.ExpiresAt = &expiresAt.Time
+		}
+		if revokedAt.Valid {
+			share.RevokedAt = &revokedAt.Time
+		}
+		if revokedReason.Valid {
+			share.RevokedReason = &revokedReason.String
+		}
+
+		// Shares past their expiry count as history even before the cleanup job runs
+		expired := share.ExpiresAt != nil && !share.ExpiresAt.After(time.Now())
+		if share.RevokedAt != nil || expired {
+			resp.ShareHistory = append(resp.ShareHistory, share)
+		} else {
+			resp.ActiveShares = append(resp.ActiveShares, share)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// handleCleanupExpiredShares runs the expired-share cleanup on demand.
+// POST /api/admin/documents/cleanup-expired-shares
+func handleCleanupExpiredShares(w http.ResponseWriter, r *http.Request) {
+	cleaned, err := retireExpiredShares()
+	if err != nil {
+		logger.FromContext(r.Context()).Errorf("Error cleaning up expired shares: %v", err)
+		respondError(w, http.StatusInternalServerError, "Failed to clean up expired shares")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]int64{"shares_cleaned_up": cleaned})
+}
+
+// cleanupExpiredShares is the background job wrapper for retireExpiredShares
+func cleanupExpiredShares() {
+	if _, err := retireExpiredShares(); err != nil {
+		logger.Default().Errorf("Error cleaning up expired shares: %v", err)
+	}
+}
+
+// retireExpiredShares marks shares past their expiry as revoked, keeping the
+// rows for the share history, and adds the count to maintenance_stats
+func retireExpiredShares() (int64, error) {
+	result, err := db.DB.Exec(`
+		UPDATE document_shares SET revoked_at = NOW(), revoked_reason = ?
+		WHERE expires_at IS NOT NULL AND expires_at < NOW() AND revoked_at IS NULL
+	`, models.ShareRevokedExpired)
+	if err != nil {
+		return 0, err
+	}
+
+	cleaned, _ := result.RowsAffected()
+	if cleaned > 0 {
+		incrementMaintenanceStat(statSharesCleanedUp, cleaned)
+	}
+	return cleaned, nil
+}
+
+// incrementMaintenanceStat adds delta to a named maintenance counter
+func incrementMaintenanceStat(name string, delta int64) {
+	_, err := db.DB.Exec(`
+		INSERT INTO maintenance_stats (stat_name, value) VALUES (?, ?)
+		ON DUPLICATE KEY UPDATE value = value + VALUES(value)
+	`, name, delta)
+	if err != nil {
+		logger.Default().Errorf("Error updating maintenance stat %s: %v", name, err)
+	}
+}
@@ -0,0 +1,194 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/finviz/backend/internal/db"
+	"github.com/finviz/backend/internal/logger"
+	"github.com/finviz/backend/internal/models"
+	"github.com/finviz/backend/internal/storage"
+)
+
+// maxDocumentVersions caps how many versions one document can have
+const maxDocumentVersions = 10
+
+// documentVersionCount returns how many versions a document's chain holds,
+// counting the document itself
+func documentVersionCount(docID int) (int, error) {
+	count := 1
+	current := docID
+	for count <= maxDocumentVersions {
+		var previous *int
+		if err := db.DB.QueryRow(`SELECT document_id_original FROM documents WHERE id = ?`, current).Scan(&previous); err != nil {
+			return 0, err
+		}
+		if previous == nil {
+			break
+		}
+		current = *previous
+		count++
+	}
+	return count, nil
+}
+
+// canReplaceDocument checks write permission: owner, uploader, or advisor
+// with edit access to the owner
+func canReplaceDocument(user *models.User, doc *models.Document) bool {
+	if doc.UserID == user.ID || doc.UploadedBy == user.ID {
+		return true
+	}
+	if user.Role != "advisor" {
+		return false
+	}
+	var accessLevel string
+	db.DB.QueryRow(`
+		SELECT access_level FROM advisor_clients
+		WHERE advisor_id = ? AND client_id = ? AND status = 'active'
+	`, user.ID, doc.UserID).Scan(&accessLevel)
+	return accessLevel == "edit" || accessLevel == "full"
+}
+
+// HandleDocumentReplace uploads a new version of a document. The new row
+// points at the one it replaces via document_id_original and inherits its
+// metadata and active shares; the old row is soft-deleted.
+// PUT /api/documents/{id}/replace
+func HandleDocumentReplace(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	docID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid document ID", http.StatusBadRequest)
+		return
+	}
+
+	var doc models.Document
+	err = db.DB.QueryRow(`
+		SELECT id, user_id, uploaded_by, name, category, description, year
+		FROM documents
+		WHERE id = ? AND deleted_at IS NULL
+	`, docID).Scan(&doc.ID, &doc.UserID, &doc.UploadedBy, &doc.Name, &doc.Category, &doc.Description, &doc.Year)
+	if err != nil {
+		http.Error(w, "Document not found", http.StatusNotFound)
+		return
+	}
+
+	if !canReplaceDocument(user, &doc) {
+		http.Error(w, "Cannot replace this document", http.StatusForbidden)
+		return
+	}
+
+	versions, err := documentVersionCount(doc.ID)
+	if err != nil {
+		http.Error(w, "Failed to check document versions", http.StatusInternalServerError)
+		return
+	}
+	if versions >= maxDocumentVersions {
+		http.Error(w, fmt.Sprintf("Document already has the maximum of %d versions; upload it as a new document instead", maxDocumentVersions), http.StatusBadRequest)
+		return
+	}
+
+	if err := r.ParseMultipartForm(maxFileSize); err != nil {
+		http.Error(w, "File too large (max 25MB)", http.StatusBadRequest)
+		return
+	}
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		http.Error(w, "No file provided", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	if header.Size > maxFileSize {
+		http.Error(w, "File too large (max 25MB)", http.StatusBadRequest)
+		return
+	}
+
+	mimeType := detectMimeType(header)
+	if !allowedMimeTypes[mimeType] {
+		http.Error(w, "File type not allowed", http.StatusBadRequest)
+		return
+	}
+
+	// The new version keeps the document's name unless a new one is given
+	name := r.FormValue("name")
+	if name == "" {
+		name = doc.Name
+	}
+
+	fileBytes, err := io.ReadAll(file)
+	if err != nil {
+		http.Error(w, "Failed to read file", http.StatusInternalServerError)
+		return
+	}
+
+	storagePath, err := storage.DefaultStorage.Save(fileBytes, header.Filename, true)
+	if err != nil {
+		http.Error(w, "Failed to save file", http.StatusInternalServerError)
+		return
+	}
+
+	newID, err := replaceDocumentRecord(&doc, user.ID, name, header.Filename, mimeType, header.Size, storagePath)
+	if err != nil {
+		storage.DefaultStorage.Delete(storagePath)
+		logger.FromContext(r.Context()).Errorf("Error replacing document %d: %v", doc.ID, err)
+		http.Error(w, "Failed to save document record", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"id":                   newID,
+		"document_id_original": doc.ID,
+		"version":              versions + 1,
+		"name":                 name,
+		"category":             doc.Category,
+		"size":                 header.Size,
+		"message":              "Document replaced successfully",
+	})
+}
+
+// replaceDocumentRecord inserts the new version, carries over the old
+// version's active shares and soft-deletes it, all in one transaction
+func replaceDocumentRecord(old *models.Document, uploadedBy int, name, originalName, mimeType string, size int64, storagePath string) (int64, error) {
+	tx, err := db.DB.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+		INSERT INTO documents (user_id, uploaded_by, name, original_name, mime_type, size, category, storage_path, encrypted, description, year, document_id_original)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, old.UserID, uploadedBy, name, originalName, mimeType, size, old.Category, storagePath, storage.EncryptsClientSide(), old.Description, old.Year, old.ID)
+	if err != nil {
+		return 0, err
+	}
+	newID, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err := tx.Exec(`
+		INSERT INTO document_shares (document_id, shared_with_id, shared_by_id, permission, expires_at)
+		SELECT ?, shared_with_id, shared_by_id, permission, expires_at
+		FROM document_shares
+		WHERE document_id = ? AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > NOW())
+	`, newID, old.ID); err != nil {
+		return 0, err
+	}
+
+	if _, err := tx.Exec(`UPDATE documents SET deleted_at = NOW() WHERE id = ?`, old.ID); err != nil {
+		return 0, err
+	}
+
+	return newID, tx.Commit()
+}
@@ -1,15 +1,18 @@
 package api
 
 import (
+	"database/sql"
 	"encoding/json"
 	"fmt"
 	"io"
+	"mime/multipart"
 	"net/http"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/finviz/backend/internal/db"
+	"github.com/finviz/backend/internal/logger"
 	"github.com/finviz/backend/internal/models"
 	"github.com/finviz/backend/internal/storage"
 )
@@ -17,6 +20,9 @@ import (
 // Maximum file size: 25MB
 const maxFileSize = 25 << 20
 
+// How long a presigned download URL stays valid
+const presignedURLExpirySeconds = 300
+
 // Allowed MIME types
 var allowedMimeTypes = map[string]bool{
 	"application/pdf":                                                        true,
@@ -96,29 +102,33 @@ func HandleDocumentUpload(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	// Detect MIME type
-	mimeType := header.Header.Get("Content-Type")
-	if mimeType == "" || mimeType == "application/octet-stream" {
-		// Try to detect from extension
-		ext := strings.ToLower(strings.TrimPrefix(header.Filename[strings.LastIndex(header.Filename, "."):], "."))
-		switch ext {
-		case "pdf":
-			mimeType = "application/pdf"
-		case "jpg", "jpeg":
-			mimeType = "image/jpeg"
-		case "png":
-			mimeType = "image/png"
-		case "xlsx":
-			mimeType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
-		case "csv":
-			mimeType = "text/csv"
-		case "docx":
-			mimeType = "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
-		default:
-			mimeType = "application/octet-stream"
+	// An upload can answer one of the client's pending document requests
+	var requestID int
+	if v := r.FormValue("request_id"); v != "" {
+		requestID, err = strconv.Atoi(v)
+		if err != nil {
+			http.Error(w, "Invalid request_id", http.StatusBadRequest)
+			return
+		}
+		var status string
+		err = db.DB.QueryRow(
+			"SELECT status FROM document_requests WHERE id = ? AND client_id = ?", requestID, targetUserID,
+		).Scan(&status)
+		if err == sql.ErrNoRows {
+			http.Error(w, "Document request not found", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			http.Error(w, "Failed to fetch document request", http.StatusInternalServerError)
+			return
+		}
+		if status != models.DocRequestStatusPending {
+			http.Error(w, "Document request is not pending", http.StatusConflict)
+			return
 		}
 	}
 
+	mimeType := detectMimeType(header)
 	if !allowedMimeTypes[mimeType] {
 		http.Error(w, "File type not allowed", http.StatusBadRequest)
 		return
@@ -155,8 +165,8 @@ func HandleDocumentUpload(w http.ResponseWriter, r *http.Request) {
 
 	result, err := db.DB.Exec(`
 		INSERT INTO documents (user_id, uploaded_by, name, original_name, mime_type, size, category, storage_path, encrypted, description, year)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, TRUE, ?, ?)
-	`, targetUserID, uploadedBy, name, header.Filename, mimeType, header.Size, category, storagePath, descPtr, year)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, targetUserID, uploadedBy, name, header.Filename, mimeType, header.Size, category, storagePath, storage.EncryptsClientSide(), descPtr, year)
 
 	if err != nil {
 		// Clean up stored file on DB error
@@ -175,14 +185,55 @@ func HandleDocumentUpload(w http.ResponseWriter, r *http.Request) {
 		`, docID, targetUserID, uploadedBy)
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
+	resp := map[string]interface{}{
 		"id":       docID,
 		"name":     name,
 		"category": category,
 		"size":     header.Size,
 		"message":  "Document uploaded successfully",
-	})
+	}
+	if requestID != 0 {
+		fulfilled, err := fulfillDocumentRequest(requestID, targetUserID, docID)
+		if err != nil {
+			logger.FromContext(r.Context()).Errorf("Error fulfilling document request %d: %v", requestID, err)
+		}
+		if fulfilled {
+			resp["fulfilled_request_id"] = requestID
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// detectMimeType returns an upload's MIME type, falling back to its file
+// extension when the client didn't send a specific one
+func detectMimeType(header *multipart.FileHeader) string {
+	mimeType := header.Header.Get("Content-Type")
+	if mimeType == "" || mimeType == "application/octet-stream" {
+		// Try to detect from extension
+		ext := ""
+		if i := strings.LastIndex(header.Filename, "."); i >= 0 {
+			ext = strings.ToLower(header.Filename[i+1:])
+		}
+		switch ext {
+		case "pdf":
+			mimeType = "application/pdf"
+		case "jpg", "jpeg":
+			mimeType = "image/jpeg"
+		case "png":
+			mimeType = "image/png"
+		case "xlsx":
+			mimeType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+		case "csv":
+			mimeType = "text/csv"
+		case "docx":
+			mimeType = "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
+		default:
+			mimeType = "application/octet-stream"
+		}
+	}
+	return mimeType
 }
 
 // HandleDocumentList lists documents for a user
@@ -196,6 +247,7 @@ func HandleDocumentList(w http.ResponseWriter, r *http.Request) {
 	// Get optional filters
 	category := r.URL.Query().Get("category")
 	clientIDStr := r.URL.Query().Get("client_id")
+	includeVersions := r.URL.Query().Get("include_versions") == "true"
 
 	targetUserID := user.ID
 
@@ -215,15 +267,26 @@ func HandleDocumentList(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	// Build query
+	if r.URL.Query().Get("deleted") == "true" {
+		handleListDeletedDocuments(w, r, targetUserID)
+		return
+	}
+
+	// Build query. Replaced versions are soft-deleted; include_versions
+	// brings them back (but not documents that were actually deleted).
 	query := `
 		SELECT d.id, d.user_id, d.uploaded_by, d.name, d.original_name, d.mime_type,
 		       d.size, d.category, d.encrypted, d.description, d.year, d.created_at, d.updated_at,
-		       u.name as uploader_name
+		       d.deleted_at, d.document_id_original, u.name as uploader_name
 		FROM documents d
 		LEFT JOIN users u ON d.uploaded_by = u.id
-		WHERE d.user_id = ? AND d.deleted_at IS NULL
+		WHERE d.user_id = ?
 	`
+	if includeVersions {
+		query += " AND (d.deleted_at IS NULL OR EXISTS (SELECT 1 FROM documents v WHERE v.document_id_original = d.id))"
+	} else {
+		query += " AND d.deleted_at IS NULL"
+	}
 	args := []interface{}{targetUserID}
 
 	if category != "" && models.IsValidCategory(category) {
@@ -252,7 +315,8 @@ func HandleDocumentList(w http.ResponseWriter, r *http.Request) {
 		err := rows.Scan(
 			&doc.ID, &doc.UserID, &doc.UploadedBy, &doc.Name, &doc.OriginalName,
 			&doc.MimeType, &doc.Size, &doc.Category, &doc.Encrypted,
-			&description, &year, &doc.CreatedAt, &doc.UpdatedAt, &uploaderName,
+			&description, &year, &doc.CreatedAt, &doc.UpdatedAt,
+			&doc.DeletedAt, &doc.DocumentIDOriginal, &uploaderName,
 		)
 		if err != nil {
 			continue
@@ -264,6 +328,13 @@ func HandleDocumentList(w http.ResponseWriter, r *http.Request) {
 			doc.UploadedByName = *uploaderName
 		}
 
+		// Older versions are read-only history
+		if doc.DeletedAt != nil {
+			doc.Superseded = true
+			documents = append(documents, doc)
+			continue
+		}
+
 		// Set permissions
 		doc.CanEdit = doc.UploadedBy == user.ID || user.Role == "advisor"
 		doc.CanDelete = doc.UploadedBy == user.ID || user.Role == "advisor"
@@ -283,7 +354,7 @@ func HandleDocumentList(w http.ResponseWriter, r *http.Request) {
 			JOIN document_shares ds ON d.id = ds.document_id
 			LEFT JOIN users u ON d.uploaded_by = u.id
 			WHERE ds.shared_with_id = ? AND d.deleted_at IS NULL
-			  AND (ds.expires_at IS NULL OR ds.expires_at > NOW())
+			  AND ds.revoked_at IS NULL AND (ds.expires_at IS NULL OR ds.expires_at > NOW())
 			ORDER BY d.created_at DESC
 		`, user.ID)
 
@@ -352,12 +423,12 @@ func HandleDocumentDownload(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Check access
+	// Check access. Replaced versions stay downloadable.
 	var doc models.Document
 	err = db.DB.QueryRow(`
 		SELECT id, user_id, uploaded_by, name, original_name, mime_type, size, storage_path, encrypted
-		FROM documents
-		WHERE id = ? AND deleted_at IS NULL
+		FROM documents d
+		WHERE id = ? AND (deleted_at IS NULL OR EXISTS (SELECT 1 FROM documents v WHERE v.document_id_original = d.id))
 	`, docID).Scan(&doc.ID, &doc.UserID, &doc.UploadedBy, &doc.Name, &doc.OriginalName, &doc.MimeType, &doc.Size, &doc.StoragePath, &doc.Encrypted)
 
 	if err != nil {
@@ -365,35 +436,21 @@ func HandleDocumentDownload(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Check permission: owner, uploader, shared, or advisor with access
-	hasAccess := doc.UserID == user.ID || doc.UploadedBy == user.ID
-
-	if !hasAccess {
-		// Check if shared
-		var shareCount int
-		db.DB.QueryRow(`
-			SELECT COUNT(*) FROM document_shares
-			WHERE document_id = ? AND shared_with_id = ?
-			  AND (expires_at IS NULL OR expires_at > NOW())
-		`, docID, user.ID).Scan(&shareCount)
-		hasAccess = shareCount > 0
-	}
-
-	if !hasAccess && user.Role == "advisor" {
-		// Check if advisor has access to document owner
-		var accessLevel string
-		db.DB.QueryRow(`
-			SELECT access_level FROM advisor_clients
-			WHERE advisor_id = ? AND client_id = ? AND status = 'active'
-		`, user.ID, doc.UserID).Scan(&accessLevel)
-		hasAccess = accessLevel != ""
-	}
-
-	if !hasAccess {
+	if !canAccessDocument(user, &doc) {
 		http.Error(w, "Access denied", http.StatusForbidden)
 		return
 	}
 
+	// Let the client fetch unencrypted files straight from the storage backend
+	if presigner, ok := storage.DefaultStorage.(storage.PresignedURLStorage); ok && !doc.Encrypted {
+		url, err := presigner.Presign(doc.StoragePath, presignedURLExpirySeconds)
+		if err == nil {
+			http.Redirect(w, r, url, http.StatusFound)
+			return
+		}
+		logger.FromContext(r.Context()).Errorf("Error presigning document %d, streaming instead: %v", doc.ID, err)
+	}
+
 	// Load file from storage
 	data, err := storage.DefaultStorage.Load(doc.StoragePath, doc.Encrypted)
 	if err != nil {
@@ -408,6 +465,36 @@ func HandleDocumentDownload(w http.ResponseWriter, r *http.Request) {
 	w.Write(data)
 }
 
+// canAccessDocument checks read permission: owner, uploader, shared, or advisor with access
+func canAccessDocument(user *models.User, doc *models.Document) bool {
+	if doc.UserID == user.ID || doc.UploadedBy == user.ID {
+		return true
+	}
+
+	// Check if shared
+	var shareCount int
+	db.DB.QueryRow(`
+		SELECT COUNT(*) FROM document_shares
+		WHERE document_id = ? AND shared_with_id = ?
+		  AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > NOW())
+	`, doc.ID, user.ID).Scan(&shareCount)
+	if shareCount > 0 {
+		return true
+	}
+
+	if user.Role == "advisor" {
+		// Check if advisor has access to document owner
+		var accessLevel string
+		db.DB.QueryRow(`
+			SELECT access_level FROM advisor_clients
+			WHERE advisor_id = ? AND client_id = ? AND status = 'active'
+		`, user.ID, doc.UserID).Scan(&accessLevel)
+		return accessLevel != ""
+	}
+
+	return false
+}
+
 // HandleDocumentDelete handles document deletion (soft delete)
 func HandleDocumentDelete(w http.ResponseWriter, r *http.Request) {
 	user := getUserFromContext(r)
@@ -457,9 +544,9 @@ func HandleDocumentDelete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Soft delete
-	_, err = db.DB.Exec(`UPDATE documents SET deleted_at = NOW() WHERE id = ?`, docID)
-	if err != nil {
+	// Soft delete, so handleRestoreDocument can bring it back
+	if err := softDeleteDocument(docID); err != nil {
+		logger.FromContext(r.Context()).Errorf("Error deleting document %d: %v", docID, err)
 		http.Error(w, "Failed to delete document", http.StatusInternalServerError)
 		return
 	}
@@ -536,11 +623,20 @@ func HandleDocumentShare(w http.ResponseWriter, r *http.Request) {
 		expiresAt = &exp
 	}
 
-	// Create share record (upsert)
+	// Retire any current share for this recipient, keeping it in the history
+	_, err = db.DB.Exec(`
+		UPDATE document_shares SET revoked_at = NOW(), revoked_reason = ?
+		WHERE document_id = ? AND shared_with_id = ? AND revoked_at IS NULL
+	`, models.ShareRevokedReplaced, docID, req.ShareWithID)
+	if err != nil {
+		http.Error(w, "Failed to share document", http.StatusInternalServerError)
+		return
+	}
+
+	// Create share record
 	_, err = db.DB.Exec(`
 		INSERT INTO document_shares (document_id, shared_with_id, shared_by_id, permission, expires_at)
 		VALUES (?, ?, ?, ?, ?)
-		ON DUPLICATE KEY UPDATE permission = VALUES(permission), expires_at = VALUES(expires_at)
 	`, docID, req.ShareWithID, user.ID, req.Permission, expiresAt)
 
 	if err != nil {
@@ -563,8 +659,8 @@ func SaveDocumentFromBytes(userID int, uploadedBy int, name string, category str
 	// Save document record
 	result, err := db.DB.Exec(`
 		INSERT INTO documents (user_id, uploaded_by, name, original_name, mime_type, size, category, storage_path, encrypted)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, TRUE)
-	`, userID, uploadedBy, name, name, mimeType, len(data), category, storagePath)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, userID, uploadedBy, name, name, mimeType, len(data), category, storagePath, storage.EncryptsClientSide())
 
 	if err != nil {
 		storage.DefaultStorage.Delete(storagePath)
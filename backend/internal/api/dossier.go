@@ -0,0 +1,321 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/finviz/backend/internal/db"
+	"github.com/finviz/backend/internal/logger"
+	"github.com/finviz/backend/internal/models"
+	"github.com/finviz/backend/internal/reports"
+)
+
+// Most recent audit log entries included in a dossier's compliance chapter
+const dossierAuditEventLimit = 200
+
+// DossierExportRequest selects the chapters of a client dossier
+type DossierExportRequest struct {
+	Sections []string `json:"sections"` // notes, goals, documents, simulation, compliance; empty means all
+}
+
+// handleDossierExport assembles a client dossier PDF, files it in the client's
+// document vault and returns it (advisor only)
+// POST /api/advisor/clients/{clientId}/dossier-export
+func handleDossierExport(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+	if user == nil || !user.IsAdvisor() {
+		respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	clientID, err := strconv.Atoi(r.PathValue("clientId"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid client ID")
+		return
+	}
+
+	if !db.AdvisorHasClientAccess(user.ID, clientID) {
+		respondError(w, http.StatusForbidden, "Access denied")
+		return
+	}
+
+	var req DossierExportRequest
+	if r.Body != nil && r.ContentLength > 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			respondError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+	}
+
+	// Chapters always follow the canonical order, whatever order was requested
+	requested := make(map[string]bool)
+	for _, section := range req.Sections {
+		requested[section] = true
+	}
+	var sections []string
+	for _, section := range reports.DossierSections {
+		if len(requested) == 0 || requested[section] {
+			sections = append(sections, section)
+			delete(requested, section)
+		}
+	}
+	if len(requested) > 0 {
+		respondError(w, http.StatusBadRequest, "Sections must be one of: "+strings.Join(reports.DossierSections, ", "))
+		return
+	}
+
+	data := reports.DossierData{
+		AdvisorName: user.Name,
+		GeneratedAt: time.Now(),
+		Sections:    sections,
+	}
+
+	var clientSince sql.NullTime
+	err = db.DB.QueryRow(`
+		SELECT u.name, u.email, COALESCE(ac.accepted_at, ac.created_at)
+		FROM users u
+		JOIN advisor_clients ac ON ac.client_id = u.id AND ac.advisor_id = ?
+		WHERE u.id = ?
+	`, user.ID, clientID).Scan(&data.ClientName, &data.ClientEmail, &clientSince)
+	if err != nil {
+		respondError(w, http.StatusNotFound, "Client not found")
+		return
+	}
+	if clientSince.Valid {
+		data.ClientSince = &clientSince.Time
+	}
+
+	for _, section := range sections {
+		switch section {
+		case reports.DossierSectionNotes:
+			data.Notes, err = fetchDossierNotes(user.ID, clientID)
+		case reports.DossierSectionGoals:
+			data.Goals, err = fetchDossierGoals(clientID)
+		case reports.DossierSectionDocuments:
+			data.Documents, err = fetchDossierDocuments(clientID)
+		case reports.DossierSectionSimulation:
+			err = fetchDossierSimulation(clientID, &data)
+		case reports.DossierSectionCompliance:
+			err = fetchDossierCompliance(clientID, &data)
+		}
+		if err != nil {
+			logger.FromContext(r.Context()).Errorf("Error loading dossier %s for client %d: %v", section, clientID, err)
+			respondError(w, http.StatusInternalServerError, "Failed to load client "+section)
+			return
+		}
+	}
+
+	pdfBytes, err := reports.GenerateClientDossier(data)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to generate PDF: %v", err))
+		return
+	}
+
+	filename := fmt.Sprintf("client_dossier_%s_%s.pdf",
+		sanitizeFilename(data.ClientName),
+		data.GeneratedAt.Format("2006-01-02"))
+
+	docID, err := SaveDocumentFromBytes(clientID, user.ID, filename, models.DocCategoryAdvisorReport, "application/pdf", pdfBytes)
+	if err != nil {
+		logger.FromContext(r.Context()).Errorf("Error saving dossier for client %d: %v", clientID, err)
+		respondError(w, http.StatusInternalServerError, "Failed to save dossier")
+		return
+	}
+
+	logAuditEvent(r, user.ID, AuditActionDossierExported,
+		fmt.Sprintf("client_id=%d document_id=%d sections=%s", clientID, docID, strings.Join(sections, ",")))
+
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
+	w.Header().Set("Content-Length", fmt.Sprintf("%d", len(pdfBytes)))
+	w.Header().Set("X-Document-ID", strconv.FormatInt(docID, 10))
+	w.WriteHeader(http.StatusOK)
+	w.Write(pdfBytes)
+}
+
+// fetchDossierNotes returns the advisor's notes on a client, pinned first
+func fetchDossierNotes(advisorID, clientID int) ([]models.ClientNote, error) {
+	rows, err := db.DB.Query(`
+		SELECT id, advisor_id, client_id, note, category, is_pinned, created_at, updated_at
+		FROM client_notes
+		WHERE advisor_id = ? AND client_id = ?
+		ORDER BY is_pinned DESC, created_at DESC
+	`, advisorID, clientID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var notes []models.ClientNote
+	for rows.Next() {
+		var note models.ClientNote
+		if err := rows.Scan(&note.ID, &note.AdvisorID, &note.ClientID, &note.Note, &note.Category, &note.IsPinned, &note.CreatedAt, &note.UpdatedAt); err != nil {
+			return nil, err
+		}
+		notes = append(notes, note)
+	}
+	return notes, rows.Err()
+}
+
+// fetchDossierGoals returns a client's goals, active ones first
+func fetchDossierGoals(clientID int) ([]models.ClientGoal, error) {
+	rows, err := db.DB.Query(`
+		SELECT id, advisor_id, client_id, title, category, status, priority,
+			target_amount, current_amount, target_date, created_at, updated_at
+		FROM client_goals
+		WHERE client_id = ?
+		ORDER BY
+			CASE status WHEN 'in_progress' THEN 1 WHEN 'pending' THEN 2 WHEN 'on_hold' THEN 3 ELSE 4 END,
+			CASE priority WHEN 'high' THEN 1 WHEN 'medium' THEN 2 ELSE 3 END,
+			created_at DESC
+	`, clientID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var goals []models.ClientGoal
+	for rows.Next() {
+		var goal models.ClientGoal
+		var targetDate sql.NullString
+		var targetAmount, currentAmount sql.NullFloat64
+		if err := rows.Scan(&goal.ID, &goal.AdvisorID, &goal.ClientID, &goal.Title, &goal.Category, &goal.Status, &goal.Priority,
+			&targetAmount, &currentAmount, &targetDate, &goal.CreatedAt, &goal.UpdatedAt); err != nil {
+			return nil, err
+		}
+		if targetDate.Valid {
+			goal.TargetDate = &targetDate.String
+		}
+		if targetAmount.Valid {
+			goal.TargetAmount = &targetAmount.Float64
+		}
+		if currentAmount.Valid {
+			goal.CurrentAmount = &currentAmount.Float64
+		}
+		goals = append(goals, goal)
+	}
+	return goals, rows.Err()
+}
+
+// fetchDossierDocuments returns metadata for a client's vault documents
+func fetchDossierDocuments(clientID int) ([]models.Document, error) {
+	rows, err := db.DB.Query(`
+		SELECT id, name, category, size, year, created_at
+		FROM documents
+		WHERE user_id = ? AND deleted_at IS NULL
+		ORDER BY category, created_at DESC
+	`, clientID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var docs []models.Document
+	for rows.Next() {
+		var doc models.Document
+		var year sql.NullInt64
+		if err := rows.Scan(&doc.ID, &doc.Name, &doc.Category, &doc.Size, &year, &doc.CreatedAt); err != nil {
+			return nil, err
+		}
+		if year.Valid {
+			y := int(year.Int64)
+			doc.Year = &y
+		}
+		docs = append(docs, doc)
+	}
+	return docs, rows.Err()
+}
+
+// fetchDossierSimulation loads the client's most recent saved simulation
+func fetchDossierSimulation(clientID int, data *reports.DossierData) error {
+	var name sql.NullString
+	var plainResults sql.NullString
+	var compressedResults []byte
+	err := db.DB.QueryRow(`
+		SELECT name, results, results_compressed, created_at
+		FROM simulation_history
+		WHERE user_id = ?
+		ORDER BY created_at DESC
+		LIMIT 1
+	`, clientID).Scan(&name, &plainResults, &compressedResults, &data.SimulationRunAt)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	resultsJSON, err := db.SimulationResultsJSON(plainResults, compressedResults)
+	if err != nil {
+		return err
+	}
+
+	var results models.MonteCarloResponse
+	if err := json.Unmarshal(resultsJSON, &results); err != nil {
+		return err
+	}
+	data.Simulation = &results
+	data.SimulationName = name.String
+	return nil
+}
+
+// fetchDossierCompliance loads signature requests and recent audit events
+func fetchDossierCompliance(clientID int, data *reports.DossierData) error {
+	rows, err := db.DB.Query(`
+		SELECT sr.id, sr.document_id, sr.advisor_id, sr.client_id, sr.status, sr.external_provider,
+		       sr.sent_at, sr.signed_at, sr.created_at, sr.updated_at, d.name
+		FROM document_signature_requests sr
+		JOIN documents d ON sr.document_id = d.id
+		WHERE sr.client_id = ?
+		ORDER BY sr.created_at DESC
+	`, clientID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var req reports.DossierSignatureRequest
+		var sentAt, signedAt sql.NullTime
+		if err := rows.Scan(&req.ID, &req.DocumentID, &req.AdvisorID, &req.ClientID, &req.Status, &req.ExternalProvider,
+			&sentAt, &signedAt, &req.CreatedAt, &req.UpdatedAt, &req.DocumentName); err != nil {
+			return err
+		}
+		if sentAt.Valid {
+			req.SentAt = &sentAt.Time
+		}
+		if signedAt.Valid {
+			req.SignedAt = &signedAt.Time
+		}
+		data.SignatureRequests = append(data.SignatureRequests, req)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	auditRows, err := db.DB.Query(`
+		SELECT action, COALESCE(ip_address, ''), COALESCE(details, ''), created_at
+		FROM audit_log
+		WHERE user_id = ?
+		ORDER BY created_at DESC
+		LIMIT ?
+	`, clientID, dossierAuditEventLimit)
+	if err != nil {
+		return err
+	}
+	defer auditRows.Close()
+
+	for auditRows.Next() {
+		var event reports.DossierAuditEvent
+		if err := auditRows.Scan(&event.Action, &event.IPAddress, &event.Details, &event.CreatedAt); err != nil {
+			return err
+		}
+		data.AuditEvents = append(data.AuditEvents, event)
+	}
+	return auditRows.Err()
+}
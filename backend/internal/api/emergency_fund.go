@@ -0,0 +1,113 @@
+package api
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/finviz/backend/internal/classifier"
+	"github.com/finviz/backend/internal/db"
+	"github.com/finviz/backend/internal/logger"
+	"github.com/finviz/backend/internal/models"
+)
+
+// Emergency fund defaults: months of expenses to hold, the allowed range for
+// the recommended_months override, and how much history sets the baseline
+const (
+	defaultEmergencyFundMonths = 6
+	maxEmergencyFundMonths     = 24
+	emergencyFundExpenseMonths = 3
+)
+
+// averageMonthlyExpenses returns a user's average monthly spending over the
+// last few months, counting expenses the same way as the transaction summary
+func averageMonthlyExpenses(userID, months int) (float64, error) {
+	rows, err := db.DB.Query(`
+		SELECT amount, date, name, merchant_name, category, subcategory
+		FROM transactions
+		WHERE user_id = ? AND deleted_at IS NULL AND date >= ? AND pending = FALSE
+	`, userID, time.Now().AddDate(0, -months, 0).Format("2006-01-02"))
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	var total float64
+	for rows.Next() {
+		var t models.Transaction
+		if err := rows.Scan(&t.Amount, &t.Date, &t.Name, &t.MerchantName, &t.Category, &t.Subcategory); err != nil {
+			continue
+		}
+		if isIncome, _ := classifier.ClassifyTransaction(t); isIncome || t.Amount <= 0 {
+			continue
+		}
+		total += t.Amount
+	}
+	return total / float64(months), rows.Err()
+}
+
+// handleCalculateEmergencyFund reports how many months of expenses the
+// user's cash savings cover
+// GET /api/me/emergency-fund?recommended_months=6
+func handleCalculateEmergencyFund(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+	if user == nil {
+		respondError(w, http.StatusUnauthorized, "Not authenticated")
+		return
+	}
+
+	recommended := defaultEmergencyFundMonths
+	if v := r.URL.Query().Get("recommended_months"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxEmergencyFundMonths {
+			respondError(w, http.StatusBadRequest, fmt.Sprintf("recommended_months must be between 1 and %d", maxEmergencyFundMonths))
+			return
+		}
+		recommended = n
+	}
+
+	expenses, err := averageMonthlyExpenses(user.ID, emergencyFundExpenseMonths)
+	if err != nil {
+		logger.FromContext(r.Context()).Errorf("Error calculating expenses for user %d: %v", user.ID, err)
+		respondError(w, http.StatusInternalServerError, "Failed to calculate expenses")
+		return
+	}
+	if expenses <= 0 {
+		respondError(w, http.StatusUnprocessableEntity, "No recent spending to base an emergency fund on")
+		return
+	}
+
+	var liquid float64
+	err = db.DB.QueryRow(`
+		SELECT COALESCE(SUM(a.current_value), 0)
+		FROM assets a JOIN asset_types t ON t.id = a.type_id
+		WHERE a.user_id = ? AND a.deleted_at IS NULL AND t.name = ?
+	`, user.ID, cashSavingsAssetType).Scan(&liquid)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch assets")
+		return
+	}
+
+	coverage := liquid / expenses
+	status := models.EmergencyFundStatus{
+		MonthlyExpenses:   roundCents(expenses),
+		LiquidAssets:      roundCents(liquid),
+		CoverageMonths:    math.Round(coverage*10) / 10,
+		RecommendedMonths: recommended,
+		ShortfallAmount:   roundCents(math.Max(0, expenses*float64(recommended)-liquid)),
+	}
+	switch target := float64(recommended); {
+	case coverage < target/2:
+		status.Status = models.EmergencyFundCritical
+	case coverage < target:
+		status.Status = models.EmergencyFundLow
+	case coverage < target*1.5:
+		status.Status = models.EmergencyFundAdequate
+	default:
+		status.Status = models.EmergencyFundStrong
+	}
+
+	respondJSON(w, http.StatusOK, status)
+}
@@ -0,0 +1,422 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/finviz/backend/internal/db"
+	"github.com/finviz/backend/internal/logger"
+	"github.com/finviz/backend/internal/models"
+	"github.com/finviz/backend/internal/simulation"
+)
+
+var errGoalNotLinked = fmt.Errorf("goal is not linked to a simulation")
+
+// canAccessGoal reports whether the user is the goal's client or one of their advisors
+func canAccessGoal(user *models.User, goal *models.ClientGoal) bool {
+	if user.ID == goal.ClientID {
+		return true
+	}
+	return user.IsAdvisor() && db.AdvisorHasClientAccess(user.ID, goal.ClientID)
+}
+
+// goalFromPath loads the goal named by the goalId path value if the user can
+// access it. Writes the error response and returns nil otherwise.
+func goalFromPath(w http.ResponseWriter, r *http.Request, user *models.User) *models.ClientGoal {
+	goalID, err := strconv.Atoi(r.PathValue("goalId"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid goal ID")
+		return nil
+	}
+
+	goal, err := getGoalByID(goalID)
+	if err != nil || !canAccessGoal(user, goal) {
+		respondError(w, http.StatusNotFound, "Goal not found")
+		return nil
+	}
+	return goal
+}
+
+// savedSimulationResults loads a saved simulation's name, run time and results
+func savedSimulationResults(simulationID int) (string, time.Time, *models.MonteCarloResponse, error) {
+	var name sql.NullString
+	var createdAt time.Time
+	var plainResults sql.NullString
+	var compressedResults []byte
+	err := db.DB.QueryRow(`
+		SELECT name, created_at, results, results_compressed
+		FROM simulation_history WHERE id = ?
+	`, simulationID).Scan(&name, &createdAt, &plainResults, &compressedResults)
+	if err != nil {
+		return "", time.Time{}, nil, err
+	}
+
+	resultsJSON, err := db.SimulationResultsJSON(plainResults, compressedResults)
+	if err != nil {
+		return "", time.Time{}, nil, err
+	}
+	var results models.MonteCarloResponse
+	if len(resultsJSON) > 0 {
+		if err := json.Unmarshal(resultsJSON, &results); err != nil {
+			return "", time.Time{}, nil, err
+		}
+	}
+	return name.String, createdAt, &results, nil
+}
+
+// linkedSimulationID returns the simulation a goal is linked to, or
+// errGoalNotLinked
+func linkedSimulationID(goalID int) (int, time.Time, error) {
+	var simulationID int
+	var linkedAt time.Time
+	err := db.DB.QueryRow(`
+		SELECT simulation_id, linked_at FROM goal_simulation_links WHERE goal_id = ?
+	`, goalID).Scan(&simulationID, &linkedAt)
+	if err == sql.ErrNoRows {
+		return 0, time.Time{}, errGoalNotLinked
+	}
+	return simulationID, linkedAt, err
+}
+
+// goalProjection forecasts a goal from its linked simulation. Returns
+// errGoalNotLinked if the goal has no link.
+func goalProjection(goal *models.ClientGoal) (*models.GoalProjection, error) {
+	simulationID, linkedAt, err := linkedSimulationID(goal.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	name, runAt, results, err := savedSimulationResults(simulationID)
+	if err != nil {
+		return nil, err
+	}
+
+	projection := &models.GoalProjection{
+		GoalID:         goal.ID,
+		SimulationID:   simulationID,
+		SimulationName: name,
+		LinkedAt:       linkedAt,
+	}
+	if goal.TargetAmount == nil {
+		return projection, nil
+	}
+
+	projection.TargetAmount = *goal.TargetAmount
+	milestone := simulation.GoalMilestone(results.Projections, *goal.TargetAmount)
+	projection.ProbabilityPct = milestone.ProbabilityPct
+	if milestone.MedianYear > 0 {
+		years := milestone.MedianYear
+		reachYear := runAt.Year() + years
+		projection.YearsToReach = &years
+		projection.ProjectedReachYear = &reachYear
+	}
+	return projection, nil
+}
+
+// attachGoalProjections sets LinkedSimulationID and ProjectedReachYear on
+// goals linked to a simulation. Failures are logged and leave the goal as is.
+func attachGoalProjections(goals []models.ClientGoal) {
+	if len(goals) == 0 {
+		return
+	}
+
+	placeholders := make([]string, len(goals))
+	args := make([]interface{}, len(goals))
+	for i, g := range goals {
+		placeholders[i] = "?"
+		args[i] = g.ID
+	}
+	rows, err := db.DB.Query(`
+		SELECT goal_id, simulation_id FROM goal_simulation_links
+		WHERE goal_id IN (`+strings.Join(placeholders, ",")+`)
+	`, args...)
+	if err != nil {
+		logger.Default().Errorf("Error fetching goal simulation links: %v", err)
+		return
+	}
+	links := make(map[int]int)
+	for rows.Next() {
+		var goalID, simulationID int
+		if err := rows.Scan(&goalID, &simulationID); err == nil {
+			links[goalID] = simulationID
+		}
+	}
+	rows.Close()
+
+	// Goals often share a simulation; load each one once
+	type loaded struct {
+		runAt       time.Time
+		projections []models.YearProjection
+		err         error
+	}
+	cache := make(map[int]*loaded)
+
+	for i := range goals {
+		simulationID, ok := links[goals[i].ID]
+		if !ok {
+			continue
+		}
+		id := simulationID
+		goals[i].LinkedSimulationID = &id
+		if goals[i].TargetAmount == nil {
+			continue
+		}
+
+		sim, ok := cache[simulationID]
+		if !ok {
+			sim = &loaded{}
+			var results *models.MonteCarloResponse
+			_, sim.runAt, results, sim.err = savedSimulationResults(simulationID)
+			if sim.err == nil {
+				sim.projections = results.Projections
+			} else {
+				logger.Default().Errorf("Error loading simulation %d for goal projection: %v", simulationID, sim.err)
+			}
+			cache[simulationID] = sim
+		}
+		if sim.err != nil {
+			continue
+		}
+
+		if milestone := simulation.GoalMilestone(sim.projections, *goals[i].TargetAmount); milestone.MedianYear > 0 {
+			reachYear := sim.runAt.Year() + milestone.MedianYear
+			goals[i].ProjectedReachYear = &reachYear
+		}
+	}
+}
+
+// handleLinkGoalToSimulation links a goal to one of its client's saved
+// simulations, replacing any earlier link
+// POST /api/goals/{goalId}/link-simulation
+func handleLinkGoalToSimulation(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+	if user == nil {
+		respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	goal := goalFromPath(w, r, user)
+	if goal == nil {
+		return
+	}
+
+	var req models.LinkGoalSimulationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if req.SimulationID <= 0 {
+		respondError(w, http.StatusBadRequest, "simulationId is required")
+		return
+	}
+
+	// The simulation must be one of the goal's client's
+	var count int
+	if err := db.DB.QueryRow(
+		"SELECT COUNT(*) FROM simulation_history WHERE id = ? AND user_id = ?",
+		req.SimulationID, goal.ClientID,
+	).Scan(&count); err != nil || count == 0 {
+		respondError(w, http.StatusNotFound, "Simulation not found")
+		return
+	}
+
+	_, err := db.DB.Exec(`
+		INSERT INTO goal_simulation_links (goal_id, simulation_id) VALUES (?, ?)
+		ON DUPLICATE KEY UPDATE simulation_id = VALUES(simulation_id), linked_at = CURRENT_TIMESTAMP
+	`, goal.ID, req.SimulationID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to link simulation")
+		return
+	}
+
+	goals := []models.ClientGoal{*goal}
+	attachGoalProjections(goals)
+	respondJSON(w, http.StatusOK, goals[0])
+}
+
+// handleUnlinkGoalSimulation removes a goal's simulation link
+// DELETE /api/goals/{goalId}/link-simulation
+func handleUnlinkGoalSimulation(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+	if user == nil {
+		respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	goal := goalFromPath(w, r, user)
+	if goal == nil {
+		return
+	}
+
+	if _, err := db.DB.Exec(`DELETE FROM goal_simulation_links WHERE goal_id = ?`, goal.ID); err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to unlink simulation")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleGetGoalProjection returns when the linked simulation's median
+// projection reaches the goal's target, and the chance of reaching it
+// GET /api/goals/{goalId}/projection
+func handleGetGoalProjection(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+	if user == nil {
+		respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	goal := goalFromPath(w, r, user)
+	if goal == nil {
+		return
+	}
+
+	if goal.TargetAmount == nil || *goal.TargetAmount <= 0 {
+		respondError(w, http.StatusBadRequest, "Goal has no target amount")
+		return
+	}
+
+	projection, err := goalProjection(goal)
+	if err == errGoalNotLinked {
+		respondError(w, http.StatusNotFound, "Goal is not linked to a simulation")
+		return
+	}
+	if err != nil {
+		logger.FromContext(r.Context()).Errorf("Error projecting goal %d: %v", goal.ID, err)
+		respondError(w, http.StatusInternalServerError, "Failed to load linked simulation")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, projection)
+}
+
+// handleGetGoalTimeline returns monthly projected values from today to the
+// goal's target date. Linked goals follow the simulation's median projection;
+// others assume equal monthly contributions that close the remaining gap.
+// GET /api/goals/{goalId}/timeline
+func handleGetGoalTimeline(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+	if user == nil {
+		respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	goal := goalFromPath(w, r, user)
+	if goal == nil {
+		return
+	}
+
+	if goal.TargetDate == nil {
+		respondError(w, http.StatusBadRequest, "Goal has no target date")
+		return
+	}
+	targetDate, err := parseGoalDate(*goal.TargetDate)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Goal has an invalid target date")
+		return
+	}
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if !targetDate.After(today) {
+		respondError(w, http.StatusBadRequest, "Goal target date has passed")
+		return
+	}
+	dates := goalTimelineDates(today, targetDate)
+
+	timeline := models.GoalTimeline{GoalID: goal.ID}
+
+	simulationID, _, err := linkedSimulationID(goal.ID)
+	switch {
+	case err == nil:
+		_, runAt, results, err := savedSimulationResults(simulationID)
+		if err != nil {
+			logger.FromContext(r.Context()).Errorf("Error loading simulation %d for goal timeline: %v", simulationID, err)
+			respondError(w, http.StatusInternalServerError, "Failed to load linked simulation")
+			return
+		}
+		timeline.Source = "simulation"
+		timeline.SimulationID = &simulationID
+		for _, d := range dates {
+			years := d.Sub(runAt).Hours() / 24 / 365.25
+			timeline.Points = append(timeline.Points, models.GoalTimelinePoint{
+				Date:           d.Format("2006-01-02"),
+				ProjectedValue: medianValueAt(results, years),
+			})
+		}
+
+	case err == errGoalNotLinked:
+		if goal.TargetAmount == nil {
+			respondError(w, http.StatusBadRequest, "Goal has no target amount")
+			return
+		}
+		current := 0.0
+		if goal.CurrentAmount != nil {
+			current = *goal.CurrentAmount
+		}
+		monthly := (*goal.TargetAmount - current) / float64(len(dates)-1)
+		timeline.Source = "linear"
+		timeline.MonthlyContribution = &monthly
+		for i, d := range dates {
+			timeline.Points = append(timeline.Points, models.GoalTimelinePoint{
+				Date:           d.Format("2006-01-02"),
+				ProjectedValue: current + monthly*float64(i),
+			})
+		}
+
+	default:
+		respondError(w, http.StatusInternalServerError, "Failed to fetch goal link")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, timeline)
+}
+
+// parseGoalDate parses a goal's target_date, which may be scanned as a
+// plain date or a full timestamp
+func parseGoalDate(s string) (time.Time, error) {
+	if len(s) > 10 {
+		s = s[:10]
+	}
+	return time.Parse("2006-01-02", s)
+}
+
+// goalTimelineDates returns today, the same day of each following month
+// before the target date, and the target date itself
+func goalTimelineDates(today, target time.Time) []time.Time {
+	dates := []time.Time{today}
+	for i := 1; ; i++ {
+		d := today.AddDate(0, i, 0)
+		if !d.Before(target) {
+			break
+		}
+		dates = append(dates, d)
+	}
+	return append(dates, target)
+}
+
+// medianValueAt interpolates a simulation's P50 projection at a fractional
+// number of years after it was run. Year 0 is the starting net worth; past the
+// horizon the last projected value is held.
+func medianValueAt(results *models.MonteCarloResponse, years float64) float64 {
+	values := []float64{results.Summary.StartingNetWorth}
+	for _, p := range results.Projections {
+		values = append(values, p.P50)
+	}
+
+	if years <= 0 {
+		return values[0]
+	}
+	last := len(values) - 1
+	if years >= float64(last) {
+		return values[last]
+	}
+	lo := int(years)
+	frac := years - float64(lo)
+	return values[lo] + (values[lo+1]-values[lo])*frac
+}
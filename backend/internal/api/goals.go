@@ -3,11 +3,13 @@ package api
 import (
 	"database/sql"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/finviz/backend/internal/db"
+	"github.com/finviz/backend/internal/logger"
 	"github.com/finviz/backend/internal/models"
 )
 
@@ -30,7 +32,7 @@ func handleListGoals(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 		// Verify advisor has access to this client
-		if !advisorHasClientAccess(user.ID, clientID) {
+		if !db.AdvisorHasClientAccess(user.ID, clientID) {
 			respondError(w, http.StatusForbidden, "Access denied")
 			return
 		}
@@ -113,6 +115,7 @@ func handleListGoals(w http.ResponseWriter, r *http.Request) {
 	if goals == nil {
 		goals = []models.ClientGoal{}
 	}
+	attachGoalProjections(goals)
 
 	respondJSON(w, http.StatusOK, goals)
 }
@@ -132,7 +135,7 @@ func handleCreateGoal(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Verify advisor has access to this client
-	if !advisorHasClientAccess(user.ID, clientID) {
+	if !db.AdvisorHasClientAccess(user.ID, clientID) {
 		respondError(w, http.StatusForbidden, "Access denied")
 		return
 	}
@@ -157,17 +160,7 @@ func handleCreateGoal(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Validate category
-	validCategories := map[string]bool{
-		models.GoalCategoryRetirement:    true,
-		models.GoalCategorySavings:       true,
-		models.GoalCategoryDebt:          true,
-		models.GoalCategoryInvestment:    true,
-		models.GoalCategoryEducation:     true,
-		models.GoalCategoryEmergency:     true,
-		models.GoalCategoryMajorPurchase: true,
-		models.GoalCategoryOther:         true,
-	}
-	if !validCategories[req.Category] {
+	if !models.IsValidGoalCategory(req.Category) {
 		respondError(w, http.StatusBadRequest, "Invalid category")
 		return
 	}
@@ -229,7 +222,7 @@ func handleUpdateGoal(w http.ResponseWriter, r *http.Request) {
 			respondError(w, http.StatusBadRequest, "Invalid client ID")
 			return
 		}
-		if !advisorHasClientAccess(user.ID, clientID) {
+		if !db.AdvisorHasClientAccess(user.ID, clientID) {
 			respondError(w, http.StatusForbidden, "Access denied")
 			return
 		}
@@ -459,6 +452,7 @@ func handleGetMyGoals(w http.ResponseWriter, r *http.Request) {
 	if goals == nil {
 		goals = []models.ClientGoal{}
 	}
+	attachGoalProjections(goals)
 
 	respondJSON(w, http.StatusOK, goals)
 }
@@ -550,3 +544,161 @@ func getGoalByID(goalID int) (*models.ClientGoal, error) {
 
 	return &goal, nil
 }
+
+// maxBulkGoalUpdates caps how many goals one bulk request can change
+const maxBulkGoalUpdates = 100
+
+// handleBulkUpdateGoals applies several goal updates in one transaction
+// (advisor only). Goals that fail validation are reported and skipped; the
+// rest are updated together.
+// PATCH /api/advisor/clients/{clientId}/goals/bulk
+func handleBulkUpdateGoals(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+	if user == nil || !user.IsAdvisor() {
+		respondError(w, http.StatusUnauthorized, "Only advisors can update goals in bulk")
+		return
+	}
+
+	clientID, err := strconv.Atoi(r.PathValue("clientId"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid client ID")
+		return
+	}
+
+	if !db.AdvisorHasClientAccess(user.ID, clientID) {
+		respondError(w, http.StatusForbidden, "Access denied")
+		return
+	}
+
+	var updates []models.BulkGoalUpdate
+	if err := json.NewDecoder(r.Body).Decode(&updates); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if len(updates) == 0 {
+		respondError(w, http.StatusBadRequest, "At least one goal update is required")
+		return
+	}
+	if len(updates) > maxBulkGoalUpdates {
+		respondError(w, http.StatusBadRequest, fmt.Sprintf("Maximum %d goals per request", maxBulkGoalUpdates))
+		return
+	}
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to update goals")
+		return
+	}
+	defer tx.Rollback()
+
+	resp := models.BulkGoalUpdateResponse{
+		Updated: []int{},
+		Failed:  []models.BulkGoalUpdateFailure{},
+	}
+	seen := make(map[int]bool)
+	for _, u := range updates {
+		if seen[u.GoalID] {
+			resp.Failed = append(resp.Failed, models.BulkGoalUpdateFailure{GoalID: u.GoalID, Error: "Duplicate goal in request"})
+			continue
+		}
+		seen[u.GoalID] = true
+
+		goal, err := getGoalByID(u.GoalID)
+		if err != nil || goal.ClientID != clientID {
+			resp.Failed = append(resp.Failed, models.BulkGoalUpdateFailure{GoalID: u.GoalID, Error: "Goal not found"})
+			continue
+		}
+
+		if msg := applyBulkGoalUpdate(goal, u); msg != "" {
+			resp.Failed = append(resp.Failed, models.BulkGoalUpdateFailure{GoalID: u.GoalID, Error: msg})
+			continue
+		}
+
+		_, err = tx.Exec(
+			`UPDATE client_goals SET title = ?, description = ?, category = ?, status = ?, priority = ?,
+			target_amount = ?, current_amount = ?, target_date = ?, completed_at = ? WHERE id = ?`,
+			goal.Title, goal.Description, goal.Category, goal.Status, goal.Priority,
+			goal.TargetAmount, goal.CurrentAmount, goal.TargetDate, goal.CompletedAt, goal.ID,
+		)
+		if err != nil {
+			logger.FromContext(r.Context()).Errorf("Error bulk updating goal %d: %v", goal.ID, err)
+			respondError(w, http.StatusInternalServerError, "Failed to update goals")
+			return
+		}
+		resp.Updated = append(resp.Updated, goal.ID)
+	}
+
+	if err := tx.Commit(); err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to update goals")
+		return
+	}
+
+	resp.UpdatedCount = len(resp.Updated)
+	resp.FailedCount = len(resp.Failed)
+	respondJSON(w, http.StatusOK, resp)
+}
+
+// applyBulkGoalUpdate validates one bulk item and applies it to the goal,
+// returning why it was rejected or "" on success
+func applyBulkGoalUpdate(goal *models.ClientGoal, u models.BulkGoalUpdate) string {
+	if u.Status != "" && u.Status != goal.Status {
+		validStatuses := map[string]bool{
+			models.GoalStatusPending:    true,
+			models.GoalStatusInProgress: true,
+			models.GoalStatusCompleted:  true,
+			models.GoalStatusOnHold:     true,
+		}
+		if !validStatuses[u.Status] {
+			return "Invalid status"
+		}
+		if goal.Status == models.GoalStatusCompleted && !u.ReopenCompleted {
+			return "Goal is completed; set reopenCompleted to change its status"
+		}
+
+		goal.Status = u.Status
+		if u.Status == models.GoalStatusCompleted {
+			now := time.Now()
+			goal.CompletedAt = &now
+		} else {
+			goal.CompletedAt = nil
+		}
+	}
+
+	if u.Category != "" {
+		if !models.IsValidGoalCategory(u.Category) {
+			return "Invalid category"
+		}
+		goal.Category = u.Category
+	}
+	if u.Priority != "" {
+		validPriorities := map[string]bool{
+			models.GoalPriorityLow:    true,
+			models.GoalPriorityMedium: true,
+			models.GoalPriorityHigh:   true,
+		}
+		if !validPriorities[u.Priority] {
+			return "Invalid priority"
+		}
+		goal.Priority = u.Priority
+	}
+
+	if u.CurrentAmount != nil {
+		if *u.CurrentAmount < 0 {
+			return "Current amount cannot be negative"
+		}
+		goal.CurrentAmount = u.CurrentAmount
+	}
+	if u.Title != "" {
+		goal.Title = u.Title
+	}
+	if u.Description != nil {
+		goal.Description = u.Description
+	}
+	if u.TargetAmount != nil {
+		goal.TargetAmount = u.TargetAmount
+	}
+	if u.TargetDate != nil {
+		goal.TargetDate = u.TargetDate
+	}
+	return ""
+}
@@ -0,0 +1,99 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/finviz/backend/internal/db"
+	"github.com/finviz/backend/internal/logger"
+	"github.com/finviz/backend/internal/storage"
+)
+
+// Dependency checks give up after this long so probes get a timely answer
+const healthCheckTimeout = 3 * time.Second
+
+// Component states reported by the health endpoints
+const (
+	healthOK             = "ok"
+	healthError          = "error"
+	healthNotConfigured  = "not_configured"
+	healthSchemaOutdated = "outdated"
+)
+
+// checkDependencies pings the database and document storage, returning each
+// component's state and whether all are healthy. Plaid is reported but
+// optional, so it never fails the check.
+func checkDependencies(ctx context.Context) (map[string]string, bool) {
+	status := map[string]string{"db": healthOK, "storage": healthOK, "plaid": healthOK}
+	healthy := true
+
+	if db.DB == nil {
+		status["db"] = healthNotConfigured
+		healthy = false
+	} else if err := db.DB.PingContext(ctx); err != nil {
+		logger.FromContext(ctx).Errorf("Health check: database ping failed: %v", err)
+		status["db"] = healthError
+		healthy = false
+	}
+
+	if storage.DefaultStorage == nil {
+		status["storage"] = healthNotConfigured
+		healthy = false
+	} else if err := storage.DefaultStorage.Ping(); err != nil {
+		logger.FromContext(ctx).Errorf("Health check: storage ping failed: %v", err)
+		status["storage"] = healthError
+		healthy = false
+	}
+
+	if !plaidClient.IsConfigured() {
+		status["plaid"] = healthNotConfigured
+	}
+	return status, healthy
+}
+
+// handleHealthCheck is the liveness probe: 200 when the database and
+// storage respond, otherwise 503 naming the failing component
+// GET /health
+func handleHealthCheck(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), healthCheckTimeout)
+	defer cancel()
+
+	status, healthy := checkDependencies(ctx)
+	respondHealth(w, status, healthy)
+}
+
+// handleReadiness is the readiness probe: the liveness checks plus a check
+// that this build's migrations have been applied
+// GET /ready
+func handleReadiness(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), healthCheckTimeout)
+	defer cancel()
+
+	status, healthy := checkDependencies(ctx)
+	status["schema"] = healthOK
+	if status["db"] != healthOK {
+		status["schema"] = healthError
+	} else if current, err := db.SchemaUpToDate(ctx); err != nil {
+		logger.FromContext(ctx).Errorf("Readiness check: schema version lookup failed: %v", err)
+		status["schema"] = healthError
+		healthy = false
+	} else if !current {
+		status["schema"] = healthSchemaOutdated
+		healthy = false
+	}
+	respondHealth(w, status, healthy)
+}
+
+func respondHealth(w http.ResponseWriter, status map[string]string, healthy bool) {
+	body := map[string]string{"status": healthOK}
+	for component, state := range status {
+		body[component] = state
+	}
+	if !healthy {
+		body["status"] = "unavailable"
+		respondJSON(w, http.StatusServiceUnavailable, body)
+		return
+	}
+	respondJSON(w, http.StatusOK, body)
+}
@@ -1,14 +1,284 @@
 package api
 
 import (
+	"database/sql"
 	"encoding/csv"
+	"fmt"
+	"io"
+	"mime/multipart"
 	"net/http"
+	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/finviz/backend/internal/db"
+	"github.com/finviz/backend/internal/logger"
+	"github.com/finviz/backend/internal/models"
 )
 
+// Number of successfully imported rows echoed back in the report preview
+// (the last rows imported, or the first rows parsed in a dry run)
+const importPreviewSize = 5
+
+// Import error severities
+const (
+	ImportSeverityError   = "error"
+	ImportSeverityWarning = "warning"
+)
+
+// Per-row import statuses used in the annotated CSV
+const (
+	importStatusImported    = "imported"
+	importStatusWouldImport = "would_import"
+	importStatusSkipped     = "skipped"
+	importStatusError       = "error"
+)
+
+// ImportError describes a problem with one field of one CSV row
+type ImportError struct {
+	RowNumber int    `json:"rowNumber"` // 1-indexed, header is row 1 (0 for file-level errors)
+	Field     string `json:"field"`
+	Value     string `json:"value"`
+	Message   string `json:"message"`
+	Severity  string `json:"severity"` // "error" blocks the row, "warning" does not
+}
+
+// ImportedRow is a stored row as written to the database
+type ImportedRow map[string]interface{}
+
+// ImportReport is the structured result of a CSV import. In a dry run nothing
+// is stored: Imported stays 0 and WouldImport counts the rows that passed.
+type ImportReport struct {
+	Type        string        `json:"type"`
+	DryRun      bool          `json:"dryRun"`
+	Imported    int           `json:"imported"`
+	WouldImport int           `json:"wouldImport,omitempty"`
+	Skipped     int           `json:"skipped"`
+	Errors      []ImportError `json:"errors"`
+	Preview     []ImportedRow `json:"preview"`
+
+	UnmatchedColumns []string `json:"unmatchedColumns,omitempty"` // headers that weren't mapped to any column
+
+	rowStatus map[int]importRowResult // per-row outcome for the annotated CSV
+}
+
+type importRowResult struct {
+	status  string
+	message string
+}
+
+func newImportReport(importType string, execute bool) *ImportReport {
+	return &ImportReport{
+		Type:      importType,
+		DryRun:    !execute,
+		Errors:    []ImportError{},
+		Preview:   []ImportedRow{},
+		rowStatus: make(map[int]importRowResult),
+	}
+}
+
+// fail records a blocking error for a row and marks it as not imported
+func (rep *ImportReport) fail(rowNum int, field, value, message string) {
+	rep.Errors = append(rep.Errors, ImportError{RowNumber: rowNum, Field: field, Value: value, Message: message, Severity: ImportSeverityError})
+	rep.Skipped++
+	rep.rowStatus[rowNum] = importRowResult{status: importStatusError, message: message}
+}
+
+// warn records a non-blocking warning for a row
+func (rep *ImportReport) warn(rowNum int, field, value, message string) {
+	rep.Errors = append(rep.Errors, ImportError{RowNumber: rowNum, Field: field, Value: value, Message: message, Severity: ImportSeverityWarning})
+}
+
+// skip marks a row as intentionally not imported (e.g. blank lines)
+func (rep *ImportReport) skip(rowNum int, message string) {
+	rep.Skipped++
+	rep.rowStatus[rowNum] = importRowResult{status: importStatusSkipped, message: message}
+}
+
+// success records an imported row, keeping the most recent rows for the
+// preview. In a dry run it records a row that would be imported and keeps the
+// first rows instead.
+func (rep *ImportReport) success(rowNum int, row ImportedRow) {
+	status := importStatusImported
+	if rep.DryRun {
+		status = importStatusWouldImport
+		rep.WouldImport++
+	} else {
+		rep.Imported++
+	}
+
+	var warnings []string
+	for _, e := range rep.Errors {
+		if e.RowNumber == rowNum && e.Severity == ImportSeverityWarning {
+			warnings = append(warnings, e.Message)
+		}
+	}
+	rep.rowStatus[rowNum] = importRowResult{status: status, message: strings.Join(warnings, "; ")}
+
+	if rep.DryRun {
+		if len(rep.Preview) < importPreviewSize {
+			rep.Preview = append(rep.Preview, row)
+		}
+		return
+	}
+	rep.Preview = append(rep.Preview, row)
+	if len(rep.Preview) > importPreviewSize {
+		rep.Preview = rep.Preview[len(rep.Preview)-importPreviewSize:]
+	}
+}
+
+// headerError reports a file-level problem (missing columns); no rows are imported
+func (rep *ImportReport) headerError(message string) {
+	rep.Errors = append(rep.Errors, ImportError{Message: message, Severity: ImportSeverityError})
+}
+
+// isBlankRow reports whether every cell in a CSV row is empty
+func isBlankRow(row []string) bool {
+	for _, cell := range row {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// cell returns the trimmed value at idx, or "" if the row is too short
+func cell(row []string, idx int) string {
+	if idx < 0 || idx >= len(row) {
+		return ""
+	}
+	return strings.TrimSpace(row[idx])
+}
+
+// Expected columns for each CSV import type
+var (
+	assetImportColumns       = []string{"name", "type_id", "type_name", "current_value", "custom_return", "custom_volatility"}
+	debtImportColumns        = []string{"name", "current_balance", "interest_rate", "minimum_payment"}
+	transactionImportColumns = []string{"date", "amount", "category", "description", "name"}
+)
+
+// importColumnAliases lists other headers bank and spreadsheet exports use for
+// each expected column, already normalized (see normalizeColumnName)
+var importColumnAliases = map[string][]string{
+	"name":              {"description", "account", "account_name", "title", "payee", "merchant", "holding"},
+	"type_name":         {"type", "account_type", "asset_type", "asset_class"},
+	"current_value":     {"value", "balance", "current_balance", "market_value", "amount"},
+	"custom_return":     {"expected_return", "return", "annual_return"},
+	"custom_volatility": {"volatility", "std_dev", "standard_deviation"},
+	"current_balance":   {"balance", "amount_owed", "principal", "outstanding_balance", "current_value"},
+	"interest_rate":     {"apr", "rate", "interest", "apy"},
+	"minimum_payment":   {"min_payment", "minimum_due", "payment", "monthly_payment"},
+	"date":              {"transaction_date", "posted_date", "posting_date", "trans_date", "post_date"},
+	"amount":            {"transaction_amount", "amt"},
+	"description":       {"memo", "details", "narrative"},
+}
+
+// Largest edit distance at which a header is taken as a misspelled column,
+// further limited to a quarter of the column name's length
+const maxColumnNameDistance = 2
+
+// normalizeColumnName lowercases a header and joins its words with
+// underscores, so "Current Value" and "current-value" match current_value
+func normalizeColumnName(name string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return r == ' ' || r == '_' || r == '-' || r == '.'
+	}), "_")
+}
+
+// detectColumnMapping maps each expected column to a header index. Exact
+// names win, then aliases, then the closest header within a small edit
+// distance; a misspelling equally close to two unused headers is left
+// unmapped. Each header is used at most once.
+func detectColumnMapping(header []string, targetCols []string) map[string]int {
+	normalized := make([]string, len(header))
+	for i, h := range header {
+		normalized[i] = normalizeColumnName(h)
+	}
+
+	cols := make(map[string]int)
+	used := make(map[int]bool)
+	assign := func(target string, idx int) {
+		cols[target] = idx
+		used[idx] = true
+	}
+	find := func(name string) int {
+		for i, h := range normalized {
+			if h == name && !used[i] {
+				return i
+			}
+		}
+		return -1
+	}
+
+	for _, target := range targetCols {
+		if idx := find(target); idx >= 0 {
+			assign(target, idx)
+		}
+	}
+
+	for _, target := range targetCols {
+		if _, ok := cols[target]; ok {
+			continue
+		}
+		for _, alias := range importColumnAliases[target] {
+			if idx := find(alias); idx >= 0 {
+				assign(target, idx)
+				break
+			}
+		}
+	}
+
+	for _, target := range targetCols {
+		if _, ok := cols[target]; ok {
+			continue
+		}
+		names := append([]string{target}, importColumnAliases[target]...)
+		best, bestDist, tied := -1, maxColumnNameDistance+1, false
+		for i, h := range normalized {
+			if used[i] || h == "" {
+				continue
+			}
+			for _, name := range names {
+				// Short names tolerate fewer edits so "date" doesn't match "name"
+				d := levenshtein(h, name)
+				if d > len(name)/4 {
+					continue
+				}
+				if d < bestDist {
+					best, bestDist, tied = i, d, false
+				} else if d == bestDist && i != best {
+					tied = true
+				}
+			}
+		}
+		if best >= 0 && !tied {
+			assign(target, best)
+		}
+	}
+
+	return cols
+}
+
+// unmatchedColumns returns the non-empty headers that no expected column was
+// mapped to, so users can see what an import ignored
+func unmatchedColumns(header []string, cols map[string]int) []string {
+	used := make(map[int]bool)
+	for _, idx := range cols {
+		used[idx] = true
+	}
+	var unmatched []string
+	for i, h := range header {
+		if !used[i] && strings.TrimSpace(h) != "" {
+			unmatched = append(unmatched, strings.TrimSpace(h))
+		}
+	}
+	return unmatched
+}
+
+// handleCSVImport imports assets, debts or transactions from an uploaded CSV,
+// or transactions from an OFX/QFX statement
+// POST /api/import/csv?dry_run=true validates and previews without storing
 func handleCSVImport(w http.ResponseWriter, r *http.Request) {
 	// Parse multipart form (max 10MB)
 	if err := r.ParseMultipartForm(10 << 20); err != nil {
@@ -23,6 +293,26 @@ func handleCSVImport(w http.ResponseWriter, r *http.Request) {
 	}
 	defer file.Close()
 
+	user := getUserFromContext(r)
+	if user == nil {
+		respondError(w, http.StatusUnauthorized, "Not authenticated")
+		return
+	}
+
+	// With dry_run=true rows are validated and previewed but not stored
+	execute := r.URL.Query().Get("dry_run") != "true"
+
+	// OFX/QFX statements are always transactions and have their own format
+	if isOFXUpload(header) {
+		data, err := io.ReadAll(file)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "Failed to read file")
+			return
+		}
+		respondJSON(w, http.StatusOK, importOFX(data, user.ID, execute))
+		return
+	}
+
 	// Determine import type from form field or filename
 	importType := r.FormValue("type")
 	if importType == "" {
@@ -37,6 +327,7 @@ func handleCSVImport(w http.ResponseWriter, r *http.Request) {
 	}
 
 	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1 // Allow ragged rows; they're reported per row
 	records, err := reader.ReadAll()
 	if err != nil {
 		respondError(w, http.StatusBadRequest, "Failed to parse CSV file")
@@ -48,216 +339,400 @@ func handleCSVImport(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var imported int
-	var errors []string
-
-	user := getUserFromContext(r)
-	if user == nil {
-		respondError(w, http.StatusUnauthorized, "Not authenticated")
-		return
-	}
-
+	var report *ImportReport
 	switch importType {
 	case "assets":
-		imported, errors = importAssets(records, user.ID)
+		report = importAssets(records, user.ID, execute)
 	case "debts":
-		imported, errors = importDebts(records, user.ID)
+		report = importDebts(records, user.ID, execute)
 	case "transactions":
-		imported, errors = importTransactions(records, user.ID)
+		report = importTransactions(records, user.ID, execute)
 	default:
 		respondError(w, http.StatusBadRequest, "Invalid import type. Use 'assets', 'debts', or 'transactions'")
 		return
 	}
 
-	response := map[string]interface{}{
-		"imported": imported,
-		"type":     importType,
-	}
-	if len(errors) > 0 {
-		response["errors"] = errors
+	// Annotated CSV so users can fix failed rows in a spreadsheet
+	if strings.Contains(r.Header.Get("Accept"), "text/csv") {
+		writeImportResultsCSV(w, records, report, header.Filename)
+		return
 	}
 
-	respondJSON(w, http.StatusOK, response)
+	respondJSON(w, http.StatusOK, report)
 }
 
-// importAssets imports assets from CSV
-// Expected columns: name, type_id, current_value, custom_return (optional), custom_volatility (optional)
-func importAssets(records [][]string, userID int) (int, []string) {
-	var imported int
-	var errors []string
+// writeImportResultsCSV streams the uploaded CSV with _import_status and _import_message columns
+func writeImportResultsCSV(w http.ResponseWriter, records [][]string, report *ImportReport, filename string) {
+	base := strings.TrimSuffix(filepath.Base(filename), filepath.Ext(filename))
+	if base == "" || base == "." {
+		base = "import"
+	}
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s_import_results.csv"`, sanitizeFilename(base)))
+	w.WriteHeader(http.StatusOK)
+
+	cw := csv.NewWriter(w)
+	cw.Write(append(append([]string{}, records[0]...), "_import_status", "_import_message"))
+
+	// File-level errors (e.g. missing columns) apply to every row
+	var fileError string
+	for _, e := range report.Errors {
+		if e.RowNumber == 0 {
+			fileError = e.Message
+			break
+		}
+	}
 
-	// Find column indices from header
-	header := records[0]
-	cols := make(map[string]int)
-	for i, col := range header {
-		cols[strings.ToLower(strings.TrimSpace(col))] = i
+	for i, row := range records[1:] {
+		rowNum := i + 2
+		result, ok := report.rowStatus[rowNum]
+		if !ok {
+			result = importRowResult{status: importStatusError, message: fileError}
+		}
+		cw.Write(append(append([]string{}, row...), result.status, result.message))
 	}
+	cw.Flush()
+}
+
+// importAssets imports assets from CSV
+// Expected columns: name, type_name or type_id, current_value, custom_return (optional), custom_volatility (optional)
+// type_id takes precedence over type_name when a row has both
+func importAssets(records [][]string, userID int, execute bool) *ImportReport {
+	report := newImportReport("assets", execute)
+	cols := detectColumnMapping(records[0], assetImportColumns)
+	report.UnmatchedColumns = unmatchedColumns(records[0], cols)
 
 	// Required columns
 	nameIdx, hasName := cols["name"]
-	typeIdx, hasType := cols["type_id"]
+	typeIdx, hasTypeID := cols["type_id"]
+	typeNameIdx, hasTypeName := cols["type_name"]
 	valueIdx, hasValue := cols["current_value"]
 
-	if !hasName || !hasType || !hasValue {
-		return 0, []string{"CSV must have columns: name, type_id, current_value"}
+	if !hasName || (!hasTypeID && !hasTypeName) || !hasValue {
+		report.headerError("CSV must have columns: name, type_name (or type_id), current_value")
+		return report
+	}
+	if !hasTypeID {
+		typeIdx = -1
 	}
+	if !hasTypeName {
+		typeNameIdx = -1
+	}
+
+	types := newAssetTypeResolver()
 
 	// Optional columns
 	returnIdx, hasReturn := cols["custom_return"]
+	if !hasReturn {
+		returnIdx = -1
+	}
 	volIdx, hasVol := cols["custom_volatility"]
+	if !hasVol {
+		volIdx = -1
+	}
 
 	for i, row := range records[1:] {
 		rowNum := i + 2 // 1-indexed, skip header
 
-		if len(row) <= nameIdx || len(row) <= typeIdx || len(row) <= valueIdx {
-			errors = append(errors, "Row "+strconv.Itoa(rowNum)+": missing required columns")
+		if isBlankRow(row) {
+			report.skip(rowNum, "blank row")
 			continue
 		}
 
-		name := strings.TrimSpace(row[nameIdx])
+		name := cell(row, nameIdx)
 		if name == "" {
-			errors = append(errors, "Row "+strconv.Itoa(rowNum)+": name is required")
+			report.fail(rowNum, "name", "", "name is required")
 			continue
 		}
 
-		typeID, err := strconv.Atoi(strings.TrimSpace(row[typeIdx]))
-		if err != nil {
-			errors = append(errors, "Row "+strconv.Itoa(rowNum)+": invalid type_id")
+		typeIDStr, typeName := cell(row, typeIdx), cell(row, typeNameIdx)
+		var typeID int
+		var err error
+		if typeIDStr != "" {
+			typeID, err = strconv.Atoi(typeIDStr)
+			if err != nil {
+				report.fail(rowNum, "type_id", typeIDStr, "invalid type_id")
+				continue
+			}
+		} else if typeName != "" {
+			typeID, err = types.resolve(typeName)
+			if err != nil {
+				report.fail(rowNum, "type_name", typeName, err.Error())
+				continue
+			}
+		} else {
+			report.fail(rowNum, "type_name", "", "type_name or type_id is required")
 			continue
 		}
 
-		value, err := strconv.ParseFloat(strings.TrimSpace(row[valueIdx]), 64)
+		valueStr := cell(row, valueIdx)
+		value, err := strconv.ParseFloat(valueStr, 64)
 		if err != nil {
-			errors = append(errors, "Row "+strconv.Itoa(rowNum)+": invalid current_value")
+			report.fail(rowNum, "current_value", valueStr, "invalid current_value")
 			continue
 		}
+		if value < 0 {
+			report.warn(rowNum, "current_value", valueStr, "negative asset value - debts should be imported separately")
+		}
 
 		var customReturn, customVol *float64
-		if hasReturn && len(row) > returnIdx && row[returnIdx] != "" {
-			r, err := strconv.ParseFloat(strings.TrimSpace(row[returnIdx]), 64)
-			if err == nil {
+		if v := cell(row, returnIdx); v != "" {
+			if r, err := strconv.ParseFloat(v, 64); err == nil {
 				customReturn = &r
+			} else {
+				report.warn(rowNum, "custom_return", v, "invalid custom_return ignored")
 			}
 		}
-		if hasVol && len(row) > volIdx && row[volIdx] != "" {
-			v, err := strconv.ParseFloat(strings.TrimSpace(row[volIdx]), 64)
-			if err == nil {
-				customVol = &v
+		if v := cell(row, volIdx); v != "" {
+			if vol, err := strconv.ParseFloat(v, 64); err == nil {
+				customVol = &vol
+			} else {
+				report.warn(rowNum, "custom_volatility", v, "invalid custom_volatility ignored")
 			}
 		}
 
-		_, err = db.DB.Exec(
-			`INSERT INTO assets (user_id, name, type_id, current_value, custom_return, custom_volatility) VALUES (?, ?, ?, ?, ?, ?)`,
-			userID, name, typeID, value, customReturn, customVol,
-		)
+		row := ImportedRow{
+			"name":              name,
+			"type_id":           typeID,
+			"current_value":     value,
+			"custom_return":     customReturn,
+			"custom_volatility": customVol,
+		}
+		if execute {
+			result, err := db.DB.Exec(
+				`INSERT INTO assets (user_id, name, type_id, current_value, custom_return, custom_volatility) VALUES (?, ?, ?, ?, ?, ?)`,
+				userID, name, typeID, value, customReturn, customVol,
+			)
+			if err != nil {
+				report.fail(rowNum, "", "", err.Error())
+				continue
+			}
+			row["id"], _ = result.LastInsertId()
+		}
+		report.success(rowNum, row)
+	}
+
+	return report
+}
+
+// assetTypeResolver looks up asset type IDs by name, caching results per import
+type assetTypeResolver struct {
+	ids   map[string]int
+	names []string // Known type names, loaded lazily for suggestions
+}
+
+func newAssetTypeResolver() *assetTypeResolver {
+	return &assetTypeResolver{ids: make(map[string]int)}
+}
+
+// resolve returns the asset type ID for a case-insensitive name match
+func (t *assetTypeResolver) resolve(typeName string) (int, error) {
+	key := strings.ToLower(typeName)
+	if id, ok := t.ids[key]; ok {
+		return id, nil
+	}
+
+	var id int
+	err := db.DB.QueryRow(`SELECT id FROM asset_types WHERE LOWER(name) = LOWER(?)`, typeName).Scan(&id)
+	if err == sql.ErrNoRows {
+		if suggestion := t.suggest(typeName); suggestion != "" {
+			return 0, fmt.Errorf("Unknown type '%s' - did you mean '%s'?", typeName, suggestion)
+		}
+		return 0, fmt.Errorf("Unknown type '%s'", typeName)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	t.ids[key] = id
+	return id, nil
+}
+
+// suggest returns the closest known type name within a Levenshtein distance of 2.
+// Names are also compared without their parenthetical qualifier and by each
+// slash-separated part, so "stoks" matches "Stocks (US)" and "savngs" matches "Cash/Savings".
+func (t *assetTypeResolver) suggest(typeName string) string {
+	if t.names == nil {
+		t.names = []string{}
+		rows, err := db.DB.Query(`SELECT name FROM asset_types ORDER BY id`)
 		if err != nil {
-			errors = append(errors, "Row "+strconv.Itoa(rowNum)+": "+err.Error())
-			continue
+			return ""
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var name string
+			if rows.Scan(&name) == nil {
+				t.names = append(t.names, name)
+			}
 		}
-		imported++
 	}
 
-	return imported, errors
+	input := strings.ToLower(typeName)
+	best, bestDist := "", 3
+	for _, name := range t.names {
+		lower := strings.ToLower(name)
+		candidates := []string{lower}
+		if i := strings.Index(lower, "("); i > 0 {
+			candidates = append(candidates, strings.TrimSpace(lower[:i]))
+		}
+		if strings.Contains(lower, "/") {
+			candidates = append(candidates, strings.Split(lower, "/")...)
+		}
+		for _, c := range candidates {
+			if d := levenshtein(input, c); d < bestDist {
+				best, bestDist = name, d
+			}
+		}
+	}
+	return best
+}
+
+// levenshtein returns the edit distance between two strings
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
 }
 
 // importDebts imports debts from CSV
 // Expected columns: name, current_balance, interest_rate (optional), minimum_payment (optional)
-func importDebts(records [][]string, userID int) (int, []string) {
-	var imported int
-	var errors []string
-
-	// Find column indices from header
-	header := records[0]
-	cols := make(map[string]int)
-	for i, col := range header {
-		cols[strings.ToLower(strings.TrimSpace(col))] = i
-	}
+func importDebts(records [][]string, userID int, execute bool) *ImportReport {
+	report := newImportReport("debts", execute)
+	cols := detectColumnMapping(records[0], debtImportColumns)
+	report.UnmatchedColumns = unmatchedColumns(records[0], cols)
 
 	// Required columns
 	nameIdx, hasName := cols["name"]
 	balanceIdx, hasBalance := cols["current_balance"]
 
 	if !hasName || !hasBalance {
-		return 0, []string{"CSV must have columns: name, current_balance"}
+		report.headerError("CSV must have columns: name, current_balance")
+		return report
 	}
 
 	// Optional columns
 	rateIdx, hasRate := cols["interest_rate"]
+	if !hasRate {
+		rateIdx = -1
+	}
 	paymentIdx, hasPayment := cols["minimum_payment"]
+	if !hasPayment {
+		paymentIdx = -1
+	}
 
 	for i, row := range records[1:] {
 		rowNum := i + 2
 
-		if len(row) <= nameIdx || len(row) <= balanceIdx {
-			errors = append(errors, "Row "+strconv.Itoa(rowNum)+": missing required columns")
+		if isBlankRow(row) {
+			report.skip(rowNum, "blank row")
 			continue
 		}
 
-		name := strings.TrimSpace(row[nameIdx])
+		name := cell(row, nameIdx)
 		if name == "" {
-			errors = append(errors, "Row "+strconv.Itoa(rowNum)+": name is required")
+			report.fail(rowNum, "name", "", "name is required")
 			continue
 		}
 
-		balance, err := strconv.ParseFloat(strings.TrimSpace(row[balanceIdx]), 64)
+		balanceStr := cell(row, balanceIdx)
+		balance, err := strconv.ParseFloat(balanceStr, 64)
 		if err != nil {
-			errors = append(errors, "Row "+strconv.Itoa(rowNum)+": invalid current_balance")
+			report.fail(rowNum, "current_balance", balanceStr, "invalid current_balance")
 			continue
 		}
 
 		var rate, payment *float64
-		if hasRate && len(row) > rateIdx && row[rateIdx] != "" {
-			r, err := strconv.ParseFloat(strings.TrimSpace(row[rateIdx]), 64)
-			if err == nil {
+		if v := cell(row, rateIdx); v != "" {
+			if r, err := strconv.ParseFloat(v, 64); err == nil {
 				rate = &r
+				// Rates are stored as percentages (18.99 = 18.99%)
+				if r > 0 && r < 1 {
+					report.warn(rowNum, "interest_rate", v, fmt.Sprintf("interest_rate looks like a decimal - stored as %.2f%%, did you mean %.2f%%?", r, r*100))
+				} else if r > 50 {
+					report.warn(rowNum, "interest_rate", v, "interest_rate above 50% is unusual")
+				}
+			} else {
+				report.warn(rowNum, "interest_rate", v, "invalid interest_rate ignored")
 			}
 		}
-		if hasPayment && len(row) > paymentIdx && row[paymentIdx] != "" {
-			p, err := strconv.ParseFloat(strings.TrimSpace(row[paymentIdx]), 64)
-			if err == nil {
+		if v := cell(row, paymentIdx); v != "" {
+			if p, err := strconv.ParseFloat(v, 64); err == nil {
 				payment = &p
+				if p > balance && balance > 0 {
+					report.warn(rowNum, "minimum_payment", v, "minimum_payment exceeds current_balance")
+				}
+			} else {
+				report.warn(rowNum, "minimum_payment", v, "invalid minimum_payment ignored")
 			}
 		}
 
-		_, err = db.DB.Exec(
-			`INSERT INTO debts (user_id, name, current_balance, interest_rate, minimum_payment) VALUES (?, ?, ?, ?, ?)`,
-			userID, name, balance, rate, payment,
-		)
-		if err != nil {
-			errors = append(errors, "Row "+strconv.Itoa(rowNum)+": "+err.Error())
-			continue
+		row := ImportedRow{
+			"name":            name,
+			"current_balance": balance,
+			"interest_rate":   rate,
+			"minimum_payment": payment,
+		}
+		if execute {
+			result, err := db.DB.Exec(
+				`INSERT INTO debts (user_id, name, current_balance, interest_rate, minimum_payment) VALUES (?, ?, ?, ?, ?)`,
+				userID, name, balance, rate, payment,
+			)
+			if err != nil {
+				report.fail(rowNum, "", "", err.Error())
+				continue
+			}
+			row["id"], _ = result.LastInsertId()
 		}
-		imported++
+		report.success(rowNum, row)
 	}
 
-	return imported, errors
+	return report
 }
 
 // importTransactions imports transactions from CSV
 // Expected columns: date, amount, category (optional), description (optional)
-func importTransactions(records [][]string, userID int) (int, []string) {
-	var imported int
-	var errors []string
-
-	// Find column indices from header
-	header := records[0]
-	cols := make(map[string]int)
-	for i, col := range header {
-		cols[strings.ToLower(strings.TrimSpace(col))] = i
-	}
+func importTransactions(records [][]string, userID int, execute bool) *ImportReport {
+	report := newImportReport("transactions", execute)
+	cols := detectColumnMapping(records[0], transactionImportColumns)
+	report.UnmatchedColumns = unmatchedColumns(records[0], cols)
 
 	// Required columns
 	dateIdx, hasDate := cols["date"]
 	amountIdx, hasAmount := cols["amount"]
 
 	if !hasDate || !hasAmount {
-		return 0, []string{"CSV must have columns: date, amount"}
+		report.headerError("CSV must have columns: date, amount")
+		return report
 	}
 
 	// Optional columns
 	categoryIdx, hasCategory := cols["category"]
+	if !hasCategory {
+		categoryIdx = -1
+	}
 	descIdx, hasDesc := cols["description"]
+	if !hasDesc {
+		descIdx = -1
+	}
 	nameIdx, hasName := cols["name"]
+	if !hasName {
+		nameIdx = -1
+	}
 
 	// Income keywords for classification
 	incomeKeywords := []string{"income", "salary", "paycheck", "deposit", "dividend", "interest", "refund", "transfer in"}
@@ -265,38 +740,30 @@ func importTransactions(records [][]string, userID int) (int, []string) {
 	for i, row := range records[1:] {
 		rowNum := i + 2
 
-		if len(row) <= dateIdx || len(row) <= amountIdx {
-			errors = append(errors, "Row "+strconv.Itoa(rowNum)+": missing required columns")
+		if isBlankRow(row) {
+			report.skip(rowNum, "blank row")
 			continue
 		}
 
-		dateStr := strings.TrimSpace(row[dateIdx])
+		dateStr := cell(row, dateIdx)
 		if dateStr == "" {
-			errors = append(errors, "Row "+strconv.Itoa(rowNum)+": date is required")
+			report.fail(rowNum, "date", "", "date is required")
 			continue
 		}
 
-		amountStr := strings.TrimSpace(row[amountIdx])
-		amountStr = strings.ReplaceAll(amountStr, "$", "")
+		rawAmount := cell(row, amountIdx)
+		amountStr := strings.ReplaceAll(rawAmount, "$", "")
 		amountStr = strings.ReplaceAll(amountStr, ",", "")
 		amount, err := strconv.ParseFloat(amountStr, 64)
 		if err != nil {
-			errors = append(errors, "Row "+strconv.Itoa(rowNum)+": invalid amount '"+row[amountIdx]+"'")
+			report.fail(rowNum, "amount", rawAmount, "invalid amount '"+rawAmount+"'")
 			continue
 		}
 
 		// Get optional fields
-		var category, description, name string
-
-		if hasCategory && len(row) > categoryIdx {
-			category = strings.TrimSpace(row[categoryIdx])
-		}
-		if hasDesc && len(row) > descIdx {
-			description = strings.TrimSpace(row[descIdx])
-		}
-		if hasName && len(row) > nameIdx {
-			name = strings.TrimSpace(row[nameIdx])
-		}
+		category := cell(row, categoryIdx)
+		description := cell(row, descIdx)
+		name := cell(row, nameIdx)
 
 		// Use description as name if name not provided
 		if name == "" && description != "" {
@@ -304,6 +771,7 @@ func importTransactions(records [][]string, userID int) (int, []string) {
 		}
 		if name == "" {
 			name = "Imported Transaction"
+			report.warn(rowNum, "name", "", "no name or description - using 'Imported Transaction'")
 		}
 
 		// Determine if income based on category/description keywords
@@ -327,16 +795,205 @@ func importTransactions(records [][]string, userID int) (int, []string) {
 			category = "INCOME"
 		}
 
-		_, err = db.DB.Exec(
-			`INSERT INTO transactions (user_id, amount, date, name, category, pending) VALUES (?, ?, ?, ?, ?, FALSE)`,
-			userID, amount, dateStr, name, category,
-		)
+		row := ImportedRow{
+			"date":     dateStr,
+			"amount":   amount,
+			"name":     name,
+			"category": category,
+		}
+		if execute {
+			result, err := db.DB.Exec(
+				`INSERT INTO transactions (user_id, amount, date, name, category, pending) VALUES (?, ?, ?, ?, ?, FALSE)`,
+				userID, amount, dateStr, name, category,
+			)
+			if err != nil {
+				report.fail(rowNum, "", "", err.Error())
+				continue
+			}
+			row["id"], _ = result.LastInsertId()
+		}
+		report.success(rowNum, row)
+	}
+
+	return report
+}
+
+// isOFXUpload reports whether an uploaded file is an OFX or QFX statement,
+// going by its extension or content type
+func isOFXUpload(header *multipart.FileHeader) bool {
+	switch strings.ToLower(filepath.Ext(header.Filename)) {
+	case ".ofx", ".qfx":
+		return true
+	}
+	contentType := strings.ToLower(header.Header.Get("Content-Type"))
+	return strings.Contains(contentType, "ofx") || strings.Contains(contentType, "qfx")
+}
+
+// ofxTransaction holds the fields of one STMTTRN block, as written in the file
+type ofxTransaction struct {
+	FITID      string
+	TrnType    string
+	DatePosted string
+	Amount     string
+	Name       string
+	Memo       string
+}
+
+// parseOFXTransactions extracts the STMTTRN blocks from an OFX statement.
+// OFX 1.x is SGML where leaf elements have no closing tag (<TRNAMT>-12.50),
+// while 2.x is XML; reading each leaf up to the next tag handles both.
+func parseOFXTransactions(data string) []ofxTransaction {
+	var txns []ofxTransaction
+	for {
+		start := strings.Index(data, "<STMTTRN>")
+		if start < 0 {
+			break
+		}
+		data = data[start+len("<STMTTRN>"):]
+
+		end := strings.Index(data, "</STMTTRN>")
+		if end < 0 {
+			end = len(data)
+		}
+		block := data[:end]
+		data = data[end:]
+
+		txns = append(txns, ofxTransaction{
+			FITID:      ofxField(block, "FITID"),
+			TrnType:    ofxField(block, "TRNTYPE"),
+			DatePosted: ofxField(block, "DTPOSTED"),
+			Amount:     ofxField(block, "TRNAMT"),
+			Name:       ofxField(block, "NAME"),
+			Memo:       ofxField(block, "MEMO"),
+		})
+	}
+	return txns
+}
+
+var ofxEntities = strings.NewReplacer("&amp;", "&", "&lt;", "<", "&gt;", ">", "&quot;", `"`, "&apos;", "'", "&nbsp;", " ")
+
+// ofxField returns the value of a leaf element in an OFX block, or ""
+func ofxField(block, tag string) string {
+	start := strings.Index(block, "<"+tag+">")
+	if start < 0 {
+		return ""
+	}
+	value := block[start+len(tag)+2:]
+	if end := strings.Index(value, "<"); end >= 0 {
+		value = value[:end]
+	}
+	return strings.TrimSpace(ofxEntities.Replace(value))
+}
+
+// parseOFXDate converts an OFX datetime (YYYYMMDD, optionally followed by a
+// time and timezone such as 20240115120000.000[-5:EST]) to YYYY-MM-DD
+func parseOFXDate(value string) (string, error) {
+	if len(value) < 8 {
+		return "", fmt.Errorf("invalid date")
+	}
+	t, err := time.Parse("20060102", value[:8])
+	if err != nil {
+		return "", err
+	}
+	return t.Format("2006-01-02"), nil
+}
+
+// importOFX imports the transactions in an OFX/QFX statement. Report row
+// numbers are the 1-indexed position of each STMTTRN in the file. Transactions
+// whose FITID was already imported are skipped, so re-uploading a statement,
+// or one that overlaps it, is safe.
+func importOFX(data []byte, userID int, execute bool) *ImportReport {
+	report := newImportReport("transactions", execute)
+
+	txns := parseOFXTransactions(string(data))
+	if len(txns) == 0 {
+		report.headerError("No transactions found in OFX file")
+		return report
+	}
+
+	rules, err := loadCategorizationRules(db.DB, userID)
+	if err != nil {
+		logger.Default().Errorf("Error loading categorization rules for user %d: %v", userID, err)
+	}
+
+	seenFITIDs := make(map[string]bool)
+	for i, t := range txns {
+		rowNum := i + 1
+
+		date, err := parseOFXDate(t.DatePosted)
+		if err != nil {
+			report.fail(rowNum, "DTPOSTED", t.DatePosted, "invalid date '"+t.DatePosted+"'")
+			continue
+		}
+
+		trnAmount, err := strconv.ParseFloat(t.Amount, 64)
 		if err != nil {
-			errors = append(errors, "Row "+strconv.Itoa(rowNum)+": "+err.Error())
+			report.fail(rowNum, "TRNAMT", t.Amount, "invalid amount '"+t.Amount+"'")
 			continue
 		}
-		imported++
+		// OFX amounts are negative for money out; Plaid's convention is the reverse
+		amount := -trnAmount
+
+		var fitID *string
+		if t.FITID != "" {
+			var existing int
+			db.DB.QueryRow("SELECT COUNT(*) FROM transactions WHERE user_id = ? AND fitid = ?", userID, t.FITID).Scan(&existing)
+			if existing > 0 || seenFITIDs[t.FITID] {
+				report.skip(rowNum, "already imported (FITID "+t.FITID+")")
+				continue
+			}
+			// Repeats within the file aren't stored yet during a dry run
+			seenFITIDs[t.FITID] = true
+			fitID = &t.FITID
+		} else {
+			report.warn(rowNum, "FITID", "", "no FITID - re-importing this file will duplicate the transaction")
+		}
+
+		txn := models.Transaction{Name: t.Name, Amount: amount, Date: date}
+		if txn.Name == "" {
+			txn.Name = t.Memo
+		}
+		if txn.Name == "" {
+			txn.Name = "Imported Transaction"
+			report.warn(rowNum, "NAME", "", "no name or memo - using 'Imported Transaction'")
+		}
+		if len(txn.Name) > 255 {
+			txn.Name = txn.Name[:255]
+		}
+
+		// Match CSV import: money in is categorized as INCOME (Plaid convention)
+		if amount < 0 {
+			income := "INCOME"
+			txn.Category = &income
+		} else {
+			applyRules(rules, &txn)
+		}
+
+		var txnType *string
+		if t.TrnType != "" {
+			lower := strings.ToLower(t.TrnType)
+			txnType = &lower
+		}
+
+		row := ImportedRow{
+			"date":     txn.Date,
+			"amount":   txn.Amount,
+			"name":     txn.Name,
+			"category": txn.Category,
+		}
+		if execute {
+			result, err := db.DB.Exec(`
+				INSERT INTO transactions (user_id, amount, date, name, category, subcategory, pending, transaction_type, source, fitid)
+				VALUES (?, ?, ?, ?, ?, ?, FALSE, ?, ?, ?)
+			`, userID, txn.Amount, txn.Date, txn.Name, txn.Category, txn.Subcategory, txnType, models.TransactionSourceOFX, fitID)
+			if err != nil {
+				report.fail(rowNum, "", "", err.Error())
+				continue
+			}
+			row["id"], _ = result.LastInsertId()
+		}
+		report.success(rowNum, row)
 	}
 
-	return imported, errors
+	return report
 }
@@ -0,0 +1,183 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/finviz/backend/internal/db"
+	"github.com/finviz/backend/internal/email"
+	"github.com/finviz/backend/internal/logger"
+)
+
+// Invitations older than this without acceptance get one reminder email
+const invitationReminderDelay = 2 * 24 * time.Hour
+
+// Maximum reminders sent per job run
+const invitationReminderBatchSize = 50
+
+// ClientInvitation is a pending or past invitation as shown to the advisor
+type ClientInvitation struct {
+	ID             int        `json:"id"`
+	ClientEmail    string     `json:"clientEmail"`
+	Status         string     `json:"status"`
+	ExpiresAt      time.Time  `json:"expiresAt"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	AcceptedAt     *time.Time `json:"acceptedAt,omitempty"`
+	LastReminderAt *time.Time `json:"lastReminderAt"`
+	NoReminder     bool       `json:"noReminder"`
+}
+
+// UpdateInvitationRequest is the request body for changing invitation settings
+type UpdateInvitationRequest struct {
+	NoReminder *bool `json:"noReminder"`
+}
+
+// handleListInvitations returns the advisor's client invitations, newest first
+func handleListInvitations(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+	if user == nil {
+		respondError(w, http.StatusUnauthorized, "Not authenticated")
+		return
+	}
+
+	query := `
+		SELECT id, client_email, status, expires_at, created_at, accepted_at, last_reminder_at, no_reminder
+		FROM client_invitations
+		WHERE advisor_id = ?`
+	args := []interface{}{user.ID}
+	if status := r.URL.Query().Get("status"); status != "" {
+		query += " AND status = ?"
+		args = append(args, status)
+	}
+	query += " ORDER BY created_at DESC"
+
+	rows, err := db.DB.Query(query, args...)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch invitations")
+		return
+	}
+	defer rows.Close()
+
+	invitations := []ClientInvitation{}
+	for rows.Next() {
+		var inv ClientInvitation
+		if err := rows.Scan(
+			&inv.ID, &inv.ClientEmail, &inv.Status, &inv.ExpiresAt, &inv.CreatedAt,
+			&inv.AcceptedAt, &inv.LastReminderAt, &inv.NoReminder,
+		); err != nil {
+			continue
+		}
+		invitations = append(invitations, inv)
+	}
+
+	respondJSON(w, http.StatusOK, invitations)
+}
+
+// handleUpdateInvitation lets an advisor opt a single invitation out of reminders
+func handleUpdateInvitation(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+	if user == nil {
+		respondError(w, http.StatusUnauthorized, "Not authenticated")
+		return
+	}
+
+	invitationID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid invitation ID")
+		return
+	}
+
+	var req UpdateInvitationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if req.NoReminder == nil {
+		respondError(w, http.StatusBadRequest, "noReminder is required")
+		return
+	}
+
+	result, err := db.DB.Exec(
+		"UPDATE client_invitations SET no_reminder = ? WHERE id = ? AND advisor_id = ?",
+		*req.NoReminder, invitationID, user.ID,
+	)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to update invitation")
+		return
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		// RowsAffected is 0 for an unchanged value too, so confirm the row exists
+		var exists int
+		err := db.DB.QueryRow(
+			"SELECT 1 FROM client_invitations WHERE id = ? AND advisor_id = ?",
+			invitationID, user.ID,
+		).Scan(&exists)
+		if err == sql.ErrNoRows {
+			respondError(w, http.StatusNotFound, "Invitation not found")
+			return
+		}
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"message":    "Invitation updated",
+		"noReminder": *req.NoReminder,
+	})
+}
+
+// sendInvitationReminders emails a single reminder for invitations that have
+// sat unaccepted for a couple of days. Runs as a background job.
+func sendInvitationReminders() {
+	rows, err := db.DB.Query(`
+		SELECT ci.id, ci.client_email, ci.invitation_token, u.name
+		FROM client_invitations ci
+		JOIN users u ON ci.advisor_id = u.id
+		WHERE ci.status = 'pending'
+		  AND ci.expires_at > NOW()
+		  AND ci.created_at < ?
+		  AND ci.last_reminder_at IS NULL
+		  AND ci.no_reminder = FALSE
+		ORDER BY ci.created_at
+		LIMIT ?
+	`, time.Now().Add(-invitationReminderDelay), invitationReminderBatchSize)
+	if err != nil {
+		logger.Default().Errorf("Error fetching invitations for reminders: %v", err)
+		return
+	}
+
+	type pendingReminder struct {
+		id          int
+		email       string
+		token       string
+		advisorName string
+	}
+	var pending []pendingReminder
+	for rows.Next() {
+		var p pendingReminder
+		if err := rows.Scan(&p.id, &p.email, &p.token, &p.advisorName); err != nil {
+			continue
+		}
+		pending = append(pending, p)
+	}
+	rows.Close()
+
+	sender := email.NewSender()
+	sent := 0
+	for _, p := range pending {
+		if err := sender.SendInvitationReminder(p.email, p.advisorName, p.token); err != nil {
+			logger.Default().Errorf("Error sending invitation reminder %d: %v", p.id, err)
+			continue
+		}
+		if _, err := db.DB.Exec("UPDATE client_invitations SET last_reminder_at = NOW() WHERE id = ?", p.id); err != nil {
+			logger.Default().Errorf("Error recording invitation reminder %d: %v", p.id, err)
+			continue
+		}
+		sent++
+	}
+
+	if sent > 0 {
+		logger.Default().Infof("Sent %d invitation reminders", sent)
+	}
+}
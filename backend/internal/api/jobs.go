@@ -0,0 +1,55 @@
+package api
+
+import (
+	"time"
+
+	"github.com/finviz/backend/internal/documents"
+	"github.com/finviz/backend/internal/logger"
+	"github.com/finviz/backend/internal/middleware"
+)
+
+// backgroundJob is a periodic maintenance task
+type backgroundJob struct {
+	name     string
+	interval time.Duration
+	run      func()
+}
+
+// Periodic maintenance tasks, each on its own ticker
+var backgroundJobs = []backgroundJob{
+	{name: "oauth session cleanup", interval: 15 * time.Minute, run: cleanupOAuthSessions},
+	{name: "invitation reminders", interval: 6 * time.Hour, run: sendInvitationReminders},
+	{name: "expired share cleanup", interval: time.Hour, run: cleanupExpiredShares},
+	{name: "document text indexing", interval: 2 * time.Minute, run: documents.IndexPendingDocuments},
+	{name: "message search indexing", interval: time.Minute, run: indexPlaintextMessages},
+	{name: "login attempt pruning", interval: 24 * time.Hour, run: pruneLoginAttempts},
+	{name: "data export expiry", interval: time.Hour, run: expireDataExports},
+	{name: "MFA token attempt pruning", interval: time.Hour, run: pruneMFATokenFailures},
+	{name: "idempotency key pruning", interval: time.Hour, run: middleware.PruneIdempotencyKeys},
+}
+
+// runBackgroundJob runs one pass of a job, logging a panic instead of
+// letting it take down the server
+func runBackgroundJob(job backgroundJob) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Default().Errorf("Background job %s panicked: %v", job.name, r)
+		}
+	}()
+	job.run()
+}
+
+// StartBackgroundJobs launches a ticker for each periodic maintenance task
+func StartBackgroundJobs() {
+	for _, job := range backgroundJobs {
+		go func(job backgroundJob) {
+			ticker := time.NewTicker(job.interval)
+			defer ticker.Stop()
+
+			for range ticker.C {
+				runBackgroundJob(job)
+			}
+		}(job)
+		logger.Default().Infof("Background job started: %s (interval %s)", job.name, job.interval)
+	}
+}
@@ -0,0 +1,111 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/finviz/backend/internal/db"
+	"github.com/finviz/backend/internal/logger"
+)
+
+// Lockout defaults, overridable with AUTH_LOCKOUT_WINDOW_MINUTES and AUTH_MAX_ATTEMPTS
+const (
+	defaultLockoutWindowMinutes = 15
+	defaultMaxLoginAttempts     = 5
+)
+
+// Login attempts older than this are pruned
+const loginAttemptRetentionDays = 30
+
+// envPositiveInt reads a positive integer setting from the environment
+func envPositiveInt(key string, defaultValue int) int {
+	if n, err := strconv.Atoi(os.Getenv(key)); err == nil && n > 0 {
+		return n
+	}
+	return defaultValue
+}
+
+// isAccountLocked reports whether a user has reached the failed login limit
+// within the lockout window. Only failures since the last successful login
+// count. If attempts can't be read the account is treated as unlocked.
+func isAccountLocked(userID int) bool {
+	window := envPositiveInt("AUTH_LOCKOUT_WINDOW_MINUTES", defaultLockoutWindowMinutes)
+	maxAttempts := envPositiveInt("AUTH_MAX_ATTEMPTS", defaultMaxLoginAttempts)
+
+	var failures int
+	err := db.DB.QueryRow(`
+		SELECT COUNT(*) FROM login_attempts
+		WHERE user_id = ? AND success = FALSE
+		  AND attempt_at > NOW() - INTERVAL ? MINUTE
+		  AND id > COALESCE((SELECT MAX(id) FROM login_attempts WHERE user_id = ? AND success = TRUE), 0)
+	`, userID, window, userID).Scan(&failures)
+	if err != nil {
+		logger.Default().Errorf("Error checking login attempts for user %d: %v", userID, err)
+		return false
+	}
+	return failures >= maxAttempts
+}
+
+// recordLoginAttempt stores the outcome of a password check
+func recordLoginAttempt(userID int, success bool) {
+	if _, err := db.DB.Exec("INSERT INTO login_attempts (user_id, success) VALUES (?, ?)", userID, success); err != nil {
+		logger.Default().Errorf("Error recording login attempt for user %d: %v", userID, err)
+	}
+}
+
+// handleUnlockAccount clears a client's failed login attempts so they can
+// sign in again before the lockout window passes. Advisors can only unlock
+// clients they actively manage, and never their own account, since anyone
+// can register as an advisor.
+// POST /api/admin/users/{id}/unlock
+func handleUnlockAccount(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+	if user == nil {
+		respondError(w, http.StatusUnauthorized, "Not authenticated")
+		return
+	}
+
+	targetID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	if targetID == user.ID {
+		respondError(w, http.StatusForbidden, "You can't unlock your own account")
+		return
+	}
+
+	// Checked against the database rather than the access cache so a revoked
+	// relationship can't be used
+	var linked int
+	err = db.DB.QueryRow(`
+		SELECT COUNT(*) FROM advisor_clients WHERE advisor_id = ? AND client_id = ? AND status = 'active'
+	`, user.ID, targetID).Scan(&linked)
+	if err != nil || linked == 0 {
+		respondError(w, http.StatusForbidden, "You can only unlock accounts of your active clients")
+		return
+	}
+
+	result, err := db.DB.Exec("DELETE FROM login_attempts WHERE user_id = ? AND success = FALSE", targetID)
+	if err != nil {
+		logger.FromContext(r.Context()).Errorf("Error unlocking user %d: %v", targetID, err)
+		respondError(w, http.StatusInternalServerError, "Failed to unlock account")
+		return
+	}
+	cleared, _ := result.RowsAffected()
+
+	logAuditEvent(r, targetID, AuditActionAccountUnlocked, fmt.Sprintf("unlocked_by=%d", user.ID))
+
+	respondJSON(w, http.StatusOK, map[string]int64{"failed_attempts_cleared": cleared})
+}
+
+// pruneLoginAttempts removes login attempts past the retention period
+func pruneLoginAttempts() {
+	_, err := db.DB.Exec("DELETE FROM login_attempts WHERE attempt_at < NOW() - INTERVAL ? DAY", loginAttemptRetentionDays)
+	if err != nil {
+		logger.Default().Errorf("Error pruning login attempts: %v", err)
+	}
+}
@@ -0,0 +1,237 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/finviz/backend/internal/classifier"
+	"github.com/finviz/backend/internal/db"
+	"github.com/finviz/backend/internal/logger"
+	"github.com/finviz/backend/internal/models"
+)
+
+// validateManualTransaction checks a manual transaction request, returning a
+// message for the first problem found
+func validateManualTransaction(req *models.ManualTransactionRequest) string {
+	req.Name = strings.TrimSpace(req.Name)
+	req.Date = strings.TrimSpace(req.Date)
+
+	if req.Source != "" && req.Source != models.TransactionSourceManual {
+		return "source must be 'manual'"
+	}
+	if _, err := time.Parse("2006-01-02", req.Date); err != nil {
+		return "date must be YYYY-MM-DD"
+	}
+	if req.Amount == 0 {
+		return "amount must be non-zero"
+	}
+	if req.Name == "" || len(req.Name) > 255 {
+		return "name is required and must be at most 255 characters"
+	}
+	if req.Category != nil && len(*req.Category) > 100 {
+		return "category must be at most 100 characters"
+	}
+	if req.MerchantName != nil && len(*req.MerchantName) > 255 {
+		return "merchantName must be at most 255 characters"
+	}
+	if req.AccountName != nil && len(*req.AccountName) > 255 {
+		return "accountName must be at most 255 characters"
+	}
+	return ""
+}
+
+// manualTransactionFromRequest builds the stored fields for a manual
+// transaction. Without an explicit category the user's rules pick one.
+func manualTransactionFromRequest(userID int, req models.ManualTransactionRequest) models.Transaction {
+	txn := models.Transaction{
+		UserID:       userID,
+		Date:         req.Date,
+		Amount:       req.Amount,
+		Name:         req.Name,
+		MerchantName: req.MerchantName,
+		AccountName:  req.AccountName,
+		Source:       models.TransactionSourceManual,
+	}
+	if req.Category != nil && strings.TrimSpace(*req.Category) != "" {
+		category := strings.TrimSpace(*req.Category)
+		txn.Category = &category
+	} else if _, err := applyCategorizationRules(db.DB, userID, &txn); err != nil {
+		logger.Default().Errorf("Error applying categorization rules for user %d: %v", userID, err)
+	}
+	return txn
+}
+
+// handleCreateTransaction records a transaction entered by hand, for users
+// without (or in addition to) a Plaid connection
+// POST /api/transactions
+func handleCreateTransaction(w http.ResponseWriter, r *http.Request) {
+	userID := getEffectiveUserID(r)
+	if userID == 0 {
+		respondError(w, http.StatusUnauthorized, "Not authenticated")
+		return
+	}
+
+	if !canEdit(r) {
+		respondError(w, http.StatusForbidden, "No permission to edit client data")
+		return
+	}
+
+	var req models.ManualTransactionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if msg := validateManualTransaction(&req); msg != "" {
+		respondError(w, http.StatusBadRequest, msg)
+		return
+	}
+
+	txn := manualTransactionFromRequest(userID, req)
+	result, err := db.DB.Exec(`
+		INSERT INTO transactions (user_id, account_name, amount, date, name, merchant_name, category, subcategory, pending, source)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, FALSE, ?)
+	`, userID, txn.AccountName, txn.Amount, txn.Date, txn.Name, txn.MerchantName, txn.Category, txn.Subcategory, txn.Source)
+	if err != nil {
+		logger.FromContext(r.Context()).Errorf("Error creating transaction for user %d: %v", userID, err)
+		respondError(w, http.StatusInternalServerError, "Failed to create transaction")
+		return
+	}
+	txnID, _ := result.LastInsertId()
+
+	created, err := getTransaction(int(txnID), userID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch transaction")
+		return
+	}
+	respondJSON(w, http.StatusCreated, created)
+}
+
+// handleUpdateTransaction replaces a manual or imported transaction. Plaid
+// transactions can't be edited since the next sync would overwrite the changes.
+// PUT /api/transactions/{id}
+func handleUpdateTransaction(w http.ResponseWriter, r *http.Request) {
+	userID := getEffectiveUserID(r)
+	if userID == 0 {
+		respondError(w, http.StatusUnauthorized, "Not authenticated")
+		return
+	}
+
+	if !canEdit(r) {
+		respondError(w, http.StatusForbidden, "No permission to edit client data")
+		return
+	}
+
+	txnID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid transaction ID")
+		return
+	}
+
+	var req models.ManualTransactionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if msg := validateManualTransaction(&req); msg != "" {
+		respondError(w, http.StatusBadRequest, msg)
+		return
+	}
+
+	existing, err := getTransaction(txnID, userID)
+	if err != nil {
+		respondError(w, http.StatusNotFound, "Transaction not found")
+		return
+	}
+	if existing.Source == models.TransactionSourcePlaid {
+		respondError(w, http.StatusConflict, "Transactions synced from Plaid can't be edited")
+		return
+	}
+
+	txn := manualTransactionFromRequest(userID, req)
+	_, err = db.DB.Exec(`
+		UPDATE transactions
+		SET account_name = ?, amount = ?, date = ?, name = ?, merchant_name = ?, category = ?, subcategory = ?
+		WHERE id = ? AND user_id = ?
+	`, txn.AccountName, txn.Amount, txn.Date, txn.Name, txn.MerchantName, txn.Category, txn.Subcategory, txnID, userID)
+	if err != nil {
+		logger.FromContext(r.Context()).Errorf("Error updating transaction %d: %v", txnID, err)
+		respondError(w, http.StatusInternalServerError, "Failed to update transaction")
+		return
+	}
+
+	updated, err := getTransaction(txnID, userID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch transaction")
+		return
+	}
+	respondJSON(w, http.StatusOK, updated)
+}
+
+// handleDeleteTransaction removes a transaction. Manual and imported
+// transactions are deleted outright; Plaid ones are only hidden, since deleting the row would
+// let the next sync insert it again.
+// DELETE /api/transactions/{id}
+func handleDeleteTransaction(w http.ResponseWriter, r *http.Request) {
+	userID := getEffectiveUserID(r)
+	if userID == 0 {
+		respondError(w, http.StatusUnauthorized, "Not authenticated")
+		return
+	}
+
+	if !canEdit(r) {
+		respondError(w, http.StatusForbidden, "No permission to edit client data")
+		return
+	}
+
+	txnID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid transaction ID")
+		return
+	}
+
+	existing, err := getTransaction(txnID, userID)
+	if err != nil {
+		respondError(w, http.StatusNotFound, "Transaction not found")
+		return
+	}
+
+	if existing.Source != models.TransactionSourcePlaid {
+		_, err = db.DB.Exec("DELETE FROM transactions WHERE id = ? AND user_id = ?", txnID, userID)
+	} else {
+		_, err = db.DB.Exec("UPDATE transactions SET deleted_at = NOW() WHERE id = ? AND user_id = ?", txnID, userID)
+	}
+	if err != nil {
+		logger.FromContext(r.Context()).Errorf("Error deleting transaction %d: %v", txnID, err)
+		respondError(w, http.StatusInternalServerError, "Failed to delete transaction")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// getTransaction loads one of a user's transactions that hasn't been deleted
+func getTransaction(txnID, userID int) (models.Transaction, error) {
+	var t models.Transaction
+	err := db.DB.QueryRow(`
+		SELECT id, user_id, plaid_transaction_id, plaid_account_id, account_name, amount, date,
+		       name, merchant_name, category, subcategory, pending, transaction_type, iso_currency_code,
+		       source, created_at, updated_at
+		FROM transactions
+		WHERE id = ? AND user_id = ? AND deleted_at IS NULL
+	`, txnID, userID).Scan(
+		&t.ID, &t.UserID, &t.PlaidTransactionID, &t.PlaidAccountID, &t.AccountName, &t.Amount, &t.Date,
+		&t.Name, &t.MerchantName, &t.Category, &t.Subcategory, &t.Pending, &t.TransactionType, &t.ISOCurrencyCode,
+		&t.Source, &t.CreatedAt, &t.UpdatedAt,
+	)
+	if err != nil {
+		return t, err
+	}
+
+	isIncome, confidence := classifier.ClassifyTransaction(t)
+	t.IsIncome = isIncome
+	t.NeedsReview = classifier.NeedsReview(confidence)
+	return t, nil
+}
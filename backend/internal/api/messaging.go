@@ -3,6 +3,7 @@ package api
 import (
 	"database/sql"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
@@ -12,7 +13,7 @@ import (
 	"github.com/finviz/backend/internal/models"
 )
 
-// handleListConversations lists all conversations for the current user
+// handleListConversations lists all conversations the current user participates in
 func handleListConversations(w http.ResponseWriter, r *http.Request) {
 	user := getUserFromContext(r)
 	if user == nil {
@@ -20,33 +21,17 @@ func handleListConversations(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var rows *sql.Rows
-	var err error
-
-	if user.IsAdvisor() {
-		// Advisor sees conversations with their clients
-		rows, err = db.DB.Query(`
-			SELECT c.id, c.advisor_id, c.client_id, c.last_message_at,
-			       c.unread_count_advisor, c.unread_count_client, c.created_at, c.updated_at,
-			       u.name as client_name, u.email as client_email
-			FROM conversations c
-			JOIN users u ON c.client_id = u.id
-			WHERE c.advisor_id = ?
-			ORDER BY COALESCE(c.last_message_at, c.created_at) DESC
-		`, user.ID)
-	} else {
-		// Client sees conversations with their advisors
-		rows, err = db.DB.Query(`
-			SELECT c.id, c.advisor_id, c.client_id, c.last_message_at,
-			       c.unread_count_advisor, c.unread_count_client, c.created_at, c.updated_at,
-			       u.name as advisor_name
-			FROM conversations c
-			JOIN users u ON c.advisor_id = u.id
-			WHERE c.client_id = ?
-			ORDER BY COALESCE(c.last_message_at, c.created_at) DESC
-		`, user.ID)
-	}
-
+	rows, err := db.DB.Query(`
+		SELECT c.id, c.advisor_id, c.client_id, c.is_group, c.status, c.archived_at, c.last_message_at,
+		       p.role, p.unread_count, c.created_at, c.updated_at,
+		       cu.name as client_name, cu.email as client_email, au.name as advisor_name
+		FROM conversation_participants p
+		JOIN conversations c ON p.conversation_id = c.id
+		JOIN users cu ON c.client_id = cu.id
+		JOIN users au ON c.advisor_id = au.id
+		WHERE p.user_id = ?
+		ORDER BY COALESCE(c.last_message_at, c.created_at) DESC
+	`, user.ID)
 	if err != nil {
 		respondError(w, http.StatusInternalServerError, "Failed to fetch conversations")
 		return
@@ -56,24 +41,23 @@ func handleListConversations(w http.ResponseWriter, r *http.Request) {
 	var conversations []models.Conversation
 	for rows.Next() {
 		var c models.Conversation
-		if user.IsAdvisor() {
-			err = rows.Scan(&c.ID, &c.AdvisorID, &c.ClientID, &c.LastMessageAt,
-				&c.UnreadCountAdvisor, &c.UnreadCountClient, &c.CreatedAt, &c.UpdatedAt,
-				&c.ClientName, &c.ClientEmail)
-		} else {
-			err = rows.Scan(&c.ID, &c.AdvisorID, &c.ClientID, &c.LastMessageAt,
-				&c.UnreadCountAdvisor, &c.UnreadCountClient, &c.CreatedAt, &c.UpdatedAt,
-				&c.AdvisorName)
-		}
-		if err != nil {
+		var role string
+		if err := rows.Scan(&c.ID, &c.AdvisorID, &c.ClientID, &c.IsGroup, &c.Status, &c.ArchivedAt, &c.LastMessageAt,
+			&role, &c.UnreadCount, &c.CreatedAt, &c.UpdatedAt,
+			&c.ClientName, &c.ClientEmail, &c.AdvisorName); err != nil {
 			continue
 		}
+		setRoleUnreadCount(&c, role)
 		conversations = append(conversations, c)
 	}
+	rows.Close()
 
 	if conversations == nil {
 		conversations = []models.Conversation{}
 	}
+	for i := range conversations {
+		conversations[i].Participants = loadConversationParticipants(conversations[i].ID)
+	}
 
 	respondJSON(w, http.StatusOK, conversations)
 }
@@ -102,39 +86,13 @@ func handleGetConversation(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Verify user has access to this conversation
-	var conv models.Conversation
-	err = db.DB.QueryRow(`
-		SELECT id, advisor_id, client_id, last_message_at,
-		       unread_count_advisor, unread_count_client, created_at, updated_at
-		FROM conversations
-		WHERE id = ? AND (advisor_id = ? OR client_id = ?)
-	`, convID, user.ID, user.ID).Scan(&conv.ID, &conv.AdvisorID, &conv.ClientID,
-		&conv.LastMessageAt, &conv.UnreadCountAdvisor, &conv.UnreadCountClient,
-		&conv.CreatedAt, &conv.UpdatedAt)
-
+	// Only participants can see the conversation
+	conv, err := loadConversation(convID, user.ID)
 	if err != nil {
 		respondError(w, http.StatusNotFound, "Conversation not found")
 		return
 	}
 
-	// Get the other party's name
-	var otherUserID int
-	if user.ID == conv.AdvisorID {
-		otherUserID = conv.ClientID
-	} else {
-		otherUserID = conv.AdvisorID
-	}
-
-	var otherName string
-	db.DB.QueryRow(`SELECT name FROM users WHERE id = ?`, otherUserID).Scan(&otherName)
-
-	if user.IsAdvisor() {
-		conv.ClientName = otherName
-	} else {
-		conv.AdvisorName = otherName
-	}
-
 	respondJSON(w, http.StatusOK, conv)
 }
 
@@ -165,12 +123,7 @@ func handleGetMessages(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Verify user has access
-	var accessCheck int
-	err = db.DB.QueryRow(`
-		SELECT 1 FROM conversations
-		WHERE id = ? AND (advisor_id = ? OR client_id = ?)
-	`, convID, user.ID, user.ID).Scan(&accessCheck)
-	if err != nil {
+	if !isConversationParticipant(convID, user.ID) {
 		respondError(w, http.StatusForbidden, "Access denied")
 		return
 	}
@@ -195,7 +148,7 @@ func handleGetMessages(w http.ResponseWriter, r *http.Request) {
 	if beforeID > 0 {
 		rows, err = db.DB.Query(`
 			SELECT m.id, m.conversation_id, m.sender_id, m.encrypted_content, m.nonce,
-			       m.read_at, m.created_at, u.name as sender_name
+			       m.read_at, m.delivered_at, m.created_at, u.name as sender_name
 			FROM messages m
 			JOIN users u ON m.sender_id = u.id
 			WHERE m.conversation_id = ? AND m.id < ?
@@ -205,7 +158,7 @@ func handleGetMessages(w http.ResponseWriter, r *http.Request) {
 	} else {
 		rows, err = db.DB.Query(`
 			SELECT m.id, m.conversation_id, m.sender_id, m.encrypted_content, m.nonce,
-			       m.read_at, m.created_at, u.name as sender_name
+			       m.read_at, m.delivered_at, m.created_at, u.name as sender_name
 			FROM messages m
 			JOIN users u ON m.sender_id = u.id
 			WHERE m.conversation_id = ?
@@ -224,7 +177,7 @@ func handleGetMessages(w http.ResponseWriter, r *http.Request) {
 	for rows.Next() {
 		var m models.Message
 		if err := rows.Scan(&m.ID, &m.ConversationID, &m.SenderID, &m.EncryptedContent,
-			&m.Nonce, &m.ReadAt, &m.CreatedAt, &m.SenderName); err != nil {
+			&m.Nonce, &m.ReadAt, &m.DeliveredAt, &m.CreatedAt, &m.SenderName); err != nil {
 			continue
 		}
 		m.IsOwn = m.SenderID == user.ID
@@ -267,16 +220,8 @@ func handleSendMessage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Verify user has access and get conversation details
-	var conv struct {
-		AdvisorID int
-		ClientID  int
-	}
-	err = db.DB.QueryRow(`
-		SELECT advisor_id, client_id FROM conversations
-		WHERE id = ? AND (advisor_id = ? OR client_id = ?)
-	`, convID, user.ID, user.ID).Scan(&conv.AdvisorID, &conv.ClientID)
-	if err != nil {
+	// Verify user has access
+	if !isConversationParticipant(convID, user.ID) {
 		respondError(w, http.StatusForbidden, "Access denied")
 		return
 	}
@@ -292,6 +237,16 @@ func handleSendMessage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	var status string
+	if err := db.DB.QueryRow("SELECT status FROM conversations WHERE id = ?", convID).Scan(&status); err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to send message")
+		return
+	}
+	if status == models.ConversationStatusArchived {
+		respondError(w, http.StatusForbidden, "Conversation is archived")
+		return
+	}
+
 	// Insert message
 	result, err := db.DB.Exec(`
 		INSERT INTO messages (conversation_id, sender_id, encrypted_content, nonce)
@@ -304,39 +259,36 @@ func handleSendMessage(w http.ResponseWriter, r *http.Request) {
 
 	msgID, _ := result.LastInsertId()
 
-	// Update conversation last_message_at and increment unread count
-	if user.ID == conv.AdvisorID {
-		// Advisor sent message, increment client's unread count
-		db.DB.Exec(`
-			UPDATE conversations
-			SET last_message_at = NOW(), unread_count_client = unread_count_client + 1
-			WHERE id = ?
-		`, convID)
-	} else {
-		// Client sent message, increment advisor's unread count
-		db.DB.Exec(`
-			UPDATE conversations
-			SET last_message_at = NOW(), unread_count_advisor = unread_count_advisor + 1
-			WHERE id = ?
-		`, convID)
-	}
+	// Update conversation last_message_at and increment every other participant's unread count
+	db.DB.Exec(`UPDATE conversations SET last_message_at = NOW() WHERE id = ?`, convID)
+	db.DB.Exec(`
+		UPDATE conversation_participants
+		SET unread_count = unread_count + 1
+		WHERE conversation_id = ? AND user_id != ?
+	`, convID, user.ID)
 
 	// Return the created message
 	var msg models.Message
 	db.DB.QueryRow(`
 		SELECT m.id, m.conversation_id, m.sender_id, m.encrypted_content, m.nonce,
-		       m.read_at, m.created_at, u.name as sender_name
+		       m.read_at, m.delivered_at, m.created_at, u.name as sender_name
 		FROM messages m
 		JOIN users u ON m.sender_id = u.id
 		WHERE m.id = ?
 	`, msgID).Scan(&msg.ID, &msg.ConversationID, &msg.SenderID, &msg.EncryptedContent,
-		&msg.Nonce, &msg.ReadAt, &msg.CreatedAt, &msg.SenderName)
+		&msg.Nonce, &msg.ReadAt, &msg.DeliveredAt, &msg.CreatedAt, &msg.SenderName)
 	msg.IsOwn = true
 
+	// Push to participants with an open messaging socket
+	go notifyNewMessage(msg)
+
 	respondJSON(w, http.StatusCreated, msg)
 }
 
-// handleStartConversation starts a new conversation (advisor to client)
+// handleStartConversation starts a new conversation. With participantIds it can
+// include several advisors alongside one client; every advisor must have an
+// active relationship with that client. Without participantIds it falls back to
+// the original 1:1 behavior (advisor to clientId, or client to their advisor).
 func handleStartConversation(w http.ResponseWriter, r *http.Request) {
 	user := getUserFromContext(r)
 	if user == nil {
@@ -344,92 +296,148 @@ func handleStartConversation(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var req struct {
-		ClientID int `json:"clientId"`
-	}
+	var req models.StartConversationRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		respondError(w, http.StatusBadRequest, "Invalid request body")
 		return
 	}
 
-	var advisorID, clientID int
+	var clientID int
+	var advisorIDs []int
 
-	if user.IsAdvisor() {
-		advisorID = user.ID
-		clientID = req.ClientID
+	if len(req.ParticipantIDs) == 0 {
+		if user.IsAdvisor() {
+			clientID = req.ClientID
+			advisorIDs = []int{user.ID}
+		} else {
+			// Client starting conversation - find their advisor
+			clientID = user.ID
+			var advisorID int
+			err := db.DB.QueryRow(`
+				SELECT advisor_id FROM advisor_clients
+				WHERE client_id = ? AND status = 'active'
+				LIMIT 1
+			`, clientID).Scan(&advisorID)
+			if err != nil {
+				respondError(w, http.StatusBadRequest, "No advisor found")
+				return
+			}
+			advisorIDs = []int{advisorID}
+		}
+	} else {
+		// The caller is always a participant; list them first so an advisor
+		// starting the conversation becomes its owner
+		seen := make(map[int]bool)
+		for _, id := range append([]int{user.ID}, req.ParticipantIDs...) {
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
 
-		// Verify advisor has access to this client
-		var relationID int
-		err := db.DB.QueryRow(`
-			SELECT id FROM advisor_clients
-			WHERE advisor_id = ? AND client_id = ? AND status = 'active'
-		`, advisorID, clientID).Scan(&relationID)
-		if err != nil {
-			respondError(w, http.StatusForbidden, "You don't have access to this client")
+			var role string
+			if err := db.DB.QueryRow(`SELECT role FROM users WHERE id = ?`, id).Scan(&role); err != nil {
+				respondError(w, http.StatusBadRequest, fmt.Sprintf("Participant %d not found", id))
+				return
+			}
+			if role == models.RoleClient {
+				if clientID != 0 {
+					respondError(w, http.StatusBadRequest, "A conversation can include only one client")
+					return
+				}
+				clientID = id
+			} else {
+				advisorIDs = append(advisorIDs, id)
+			}
+		}
+		if clientID == 0 {
+			respondError(w, http.StatusBadRequest, "A conversation must include a client")
 			return
 		}
-	} else {
-		// Client starting conversation - find their advisor
-		clientID = user.ID
-		err := db.DB.QueryRow(`
-			SELECT advisor_id FROM advisor_clients
-			WHERE client_id = ? AND status = 'active'
-			LIMIT 1
-		`, clientID).Scan(&advisorID)
-		if err != nil {
-			respondError(w, http.StatusBadRequest, "No advisor found")
+		if len(advisorIDs) == 0 {
+			respondError(w, http.StatusBadRequest, "A conversation must include at least one advisor")
 			return
 		}
 	}
 
-	// Check if conversation already exists
-	var existingID int
-	err := db.DB.QueryRow(`
-		SELECT id FROM conversations
-		WHERE advisor_id = ? AND client_id = ?
-	`, advisorID, clientID).Scan(&existingID)
+	// Verify every advisor has access to this client
+	for _, advisorID := range advisorIDs {
+		if !db.AdvisorHasClientAccess(advisorID, clientID) {
+			if advisorID == user.ID {
+				respondError(w, http.StatusForbidden, "You don't have access to this client")
+			} else {
+				respondError(w, http.StatusForbidden, fmt.Sprintf("Advisor %d does not have access to this client", advisorID))
+			}
+			return
+		}
+	}
 
-	if err == nil {
-		// Conversation exists, return it
-		var conv models.Conversation
-		db.DB.QueryRow(`
-			SELECT id, advisor_id, client_id, last_message_at,
-			       unread_count_advisor, unread_count_client, created_at, updated_at
-			FROM conversations WHERE id = ?
-		`, existingID).Scan(&conv.ID, &conv.AdvisorID, &conv.ClientID,
-			&conv.LastMessageAt, &conv.UnreadCountAdvisor, &conv.UnreadCountClient,
-			&conv.CreatedAt, &conv.UpdatedAt)
+	ownerID := advisorIDs[0]
+	isGroup := len(advisorIDs) > 1
 
-		respondJSON(w, http.StatusOK, conv)
+	// A 1:1 conversation is reused if one already exists
+	if !isGroup {
+		var existingID int
+		err := db.DB.QueryRow(`
+			SELECT id FROM conversations
+			WHERE advisor_id = ? AND client_id = ? AND is_group = FALSE
+			LIMIT 1
+		`, ownerID, clientID).Scan(&existingID)
+		if err == nil {
+			conv, err := loadConversation(existingID, user.ID)
+			if err != nil {
+				respondError(w, http.StatusInternalServerError, "Failed to load conversation")
+				return
+			}
+			respondJSON(w, http.StatusOK, conv)
+			return
+		}
+	}
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to create conversation")
 		return
 	}
+	defer tx.Rollback()
 
-	// Create new conversation
-	result, err := db.DB.Exec(`
-		INSERT INTO conversations (advisor_id, client_id)
-		VALUES (?, ?)
-	`, advisorID, clientID)
+	result, err := tx.Exec(`
+		INSERT INTO conversations (advisor_id, client_id, is_group)
+		VALUES (?, ?, ?)
+	`, ownerID, clientID, isGroup)
 	if err != nil {
 		respondError(w, http.StatusInternalServerError, "Failed to create conversation")
 		return
 	}
 
-	convID, _ := result.LastInsertId()
+	convID64, _ := result.LastInsertId()
+	convID := int(convID64)
 
-	var conv models.Conversation
-	db.DB.QueryRow(`
-		SELECT id, advisor_id, client_id, last_message_at,
-		       unread_count_advisor, unread_count_client, created_at, updated_at
-		FROM conversations WHERE id = ?
-	`, convID).Scan(&conv.ID, &conv.AdvisorID, &conv.ClientID,
-		&conv.LastMessageAt, &conv.UnreadCountAdvisor, &conv.UnreadCountClient,
-		&conv.CreatedAt, &conv.UpdatedAt)
-
-	// Get names
-	if user.IsAdvisor() {
-		db.DB.QueryRow(`SELECT name FROM users WHERE id = ?`, clientID).Scan(&conv.ClientName)
-	} else {
-		db.DB.QueryRow(`SELECT name FROM users WHERE id = ?`, advisorID).Scan(&conv.AdvisorName)
+	if _, err := tx.Exec(`
+		INSERT INTO conversation_participants (conversation_id, user_id, role)
+		VALUES (?, ?, 'client')
+	`, convID, clientID); err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to create conversation")
+		return
+	}
+	for _, advisorID := range advisorIDs {
+		if _, err := tx.Exec(`
+			INSERT INTO conversation_participants (conversation_id, user_id, role)
+			VALUES (?, ?, 'advisor')
+		`, convID, advisorID); err != nil {
+			respondError(w, http.StatusInternalServerError, "Failed to create conversation")
+			return
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to create conversation")
+		return
+	}
+
+	conv, err := loadConversation(convID, user.ID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to load conversation")
+		return
 	}
 
 	respondJSON(w, http.StatusCreated, conv)
@@ -460,6 +468,11 @@ func handleMarkAsRead(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !isConversationParticipant(convID, user.ID) {
+		respondError(w, http.StatusForbidden, "Access denied")
+		return
+	}
+
 	markMessagesAsRead(convID, user.ID)
 
 	respondJSON(w, http.StatusOK, map[string]string{"status": "ok"})
@@ -474,18 +487,10 @@ func handleGetUnreadCounts(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var counts models.UnreadCounts
-
-	if user.IsAdvisor() {
-		db.DB.QueryRow(`
-			SELECT COALESCE(SUM(unread_count_advisor), 0), COUNT(CASE WHEN unread_count_advisor > 0 THEN 1 END)
-			FROM conversations WHERE advisor_id = ?
-		`, user.ID).Scan(&counts.TotalUnread, &counts.Conversations)
-	} else {
-		db.DB.QueryRow(`
-			SELECT COALESCE(SUM(unread_count_client), 0), COUNT(CASE WHEN unread_count_client > 0 THEN 1 END)
-			FROM conversations WHERE client_id = ?
-		`, user.ID).Scan(&counts.TotalUnread, &counts.Conversations)
-	}
+	db.DB.QueryRow(`
+		SELECT COALESCE(SUM(unread_count), 0), COUNT(CASE WHEN unread_count > 0 THEN 1 END)
+		FROM conversation_participants WHERE user_id = ?
+	`, user.ID).Scan(&counts.TotalUnread, &counts.Conversations)
 
 	respondJSON(w, http.StatusOK, counts)
 }
@@ -562,6 +567,17 @@ func handleGetPublicKey(w http.ResponseWriter, r *http.Request) {
 		hasAccess = count > 0 || user.ID == targetUserID
 	}
 
+	// Members of the same group conversation need each other's keys too
+	if !hasAccess {
+		var count int
+		db.DB.QueryRow(`
+			SELECT COUNT(*) FROM conversation_participants a
+			JOIN conversation_participants b ON a.conversation_id = b.conversation_id
+			WHERE a.user_id = ? AND b.user_id = ?
+		`, user.ID, targetUserID).Scan(&count)
+		hasAccess = count > 0
+	}
+
 	if !hasAccess {
 		respondError(w, http.StatusForbidden, "Access denied")
 		return
@@ -588,22 +604,88 @@ func handleGetPublicKey(w http.ResponseWriter, r *http.Request) {
 func markMessagesAsRead(convID, userID int) {
 	now := time.Now()
 
-	// Mark messages from others as read
+	// Mark messages from others as read (and so delivered)
 	db.DB.Exec(`
 		UPDATE messages
-		SET read_at = ?
+		SET read_at = ?, delivered_at = COALESCE(delivered_at, ?)
 		WHERE conversation_id = ? AND sender_id != ? AND read_at IS NULL
-	`, now, convID, userID)
+	`, now, now, convID, userID)
 
 	// Reset unread count
-	var advisorID, clientID int
-	db.DB.QueryRow(`
-		SELECT advisor_id, client_id FROM conversations WHERE id = ?
-	`, convID).Scan(&advisorID, &clientID)
+	db.DB.Exec(`
+		UPDATE conversation_participants SET unread_count = 0
+		WHERE conversation_id = ? AND user_id = ?
+	`, convID, userID)
+}
+
+// isConversationParticipant reports whether the user is a member of the conversation
+func isConversationParticipant(convID, userID int) bool {
+	var count int
+	err := db.DB.QueryRow(`
+		SELECT COUNT(*) FROM conversation_participants
+		WHERE conversation_id = ? AND user_id = ?
+	`, convID, userID).Scan(&count)
+	return err == nil && count > 0
+}
+
+// loadConversation fetches a conversation as seen by one of its participants,
+// returning sql.ErrNoRows if the user is not a member
+func loadConversation(convID, userID int) (models.Conversation, error) {
+	var conv models.Conversation
+	var role string
+	err := db.DB.QueryRow(`
+		SELECT c.id, c.advisor_id, c.client_id, c.is_group, c.status, c.archived_at, c.last_message_at,
+		       p.role, p.unread_count, c.created_at, c.updated_at,
+		       cu.name, cu.email, au.name
+		FROM conversation_participants p
+		JOIN conversations c ON p.conversation_id = c.id
+		JOIN users cu ON c.client_id = cu.id
+		JOIN users au ON c.advisor_id = au.id
+		WHERE c.id = ? AND p.user_id = ?
+	`, convID, userID).Scan(&conv.ID, &conv.AdvisorID, &conv.ClientID, &conv.IsGroup,
+		&conv.Status, &conv.ArchivedAt, &conv.LastMessageAt, &role, &conv.UnreadCount, &conv.CreatedAt, &conv.UpdatedAt,
+		&conv.ClientName, &conv.ClientEmail, &conv.AdvisorName)
+	if err != nil {
+		return conv, err
+	}
+
+	setRoleUnreadCount(&conv, role)
+	conv.Participants = loadConversationParticipants(convID)
+	return conv, nil
+}
+
+// loadConversationParticipants returns the members of a conversation with their names
+func loadConversationParticipants(convID int) []models.ConversationParticipant {
+	participants := []models.ConversationParticipant{}
+
+	rows, err := db.DB.Query(`
+		SELECT p.user_id, u.name, p.role, p.joined_at
+		FROM conversation_participants p
+		JOIN users u ON p.user_id = u.id
+		WHERE p.conversation_id = ?
+		ORDER BY p.role DESC, p.joined_at
+	`, convID)
+	if err != nil {
+		return participants
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var p models.ConversationParticipant
+		if err := rows.Scan(&p.UserID, &p.Name, &p.Role, &p.JoinedAt); err != nil {
+			continue
+		}
+		participants = append(participants, p)
+	}
+	return participants
+}
 
-	if userID == advisorID {
-		db.DB.Exec(`UPDATE conversations SET unread_count_advisor = 0 WHERE id = ?`, convID)
+// setRoleUnreadCount mirrors the caller's own unread count into the role-specific
+// field older clients read (unreadCountAdvisor / unreadCountClient)
+func setRoleUnreadCount(conv *models.Conversation, role string) {
+	if role == models.RoleAdvisor {
+		conv.UnreadCountAdvisor = conv.UnreadCount
 	} else {
-		db.DB.Exec(`UPDATE conversations SET unread_count_client = 0 WHERE id = ?`, convID)
+		conv.UnreadCountClient = conv.UnreadCount
 	}
 }
@@ -0,0 +1,152 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/finviz/backend/internal/db"
+	"github.com/finviz/backend/internal/logger"
+	"github.com/finviz/backend/internal/models"
+)
+
+// Plaintext messages copied into the search index per job run
+const plaintextIndexBatchSize = 500
+
+var (
+	messagesFullTextOnce      sync.Once
+	messagesFullTextAvailable bool
+)
+
+// handleSearchMessages searches one conversation. The server can only read
+// messages sent in plaintext, so those are matched against the search index
+// and returned with a highlighted snippet. E2E encrypted messages are
+// returned as-is, newest first, for the client to decrypt and filter.
+// GET /api/messages/conversations/{id}/messages/search?q=...&page=1&limit=50
+func handleSearchMessages(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+	if user == nil {
+		respondError(w, http.StatusUnauthorized, "Not authenticated")
+		return
+	}
+
+	convID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid conversation ID")
+		return
+	}
+
+	if !isConversationParticipant(convID, user.ID) {
+		respondError(w, http.StatusForbidden, "Access denied")
+		return
+	}
+
+	q := r.URL.Query()
+	search := strings.TrimSpace(q.Get("q"))
+	if search == "" {
+		respondError(w, http.StatusBadRequest, "Search text is required")
+		return
+	}
+
+	limit := 50
+	if limitStr := q.Get("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 100 {
+			limit = l
+		}
+	}
+	page := 1
+	if pageStr := q.Get("page"); pageStr != "" {
+		p, err := strconv.Atoi(pageStr)
+		if err != nil || p < 1 {
+			respondError(w, http.StatusBadRequest, "Invalid page")
+			return
+		}
+		page = p
+	}
+
+	// FULLTEXT when available, LIKE otherwise
+	searchMode := "like"
+	match := "p.content LIKE ?"
+	matchArg := "%" + search + "%"
+	if booleanQuery := buildBooleanModeQuery(search); booleanQuery != "" && messagesFullTextEnabled() {
+		searchMode = "fulltext"
+		match = "MATCH(p.content) AGAINST (? IN BOOLEAN MODE)"
+		matchArg = booleanQuery
+	}
+
+	from := `FROM messages m
+		JOIN users u ON m.sender_id = u.id
+		LEFT JOIN messages_plaintext_index p ON p.message_id = m.id
+		WHERE m.conversation_id = ? AND (m.nonce != ? OR ` + match + `)`
+	args := []interface{}{convID, models.MessageNoncePlaintext, matchArg}
+
+	var totalCount int
+	if err := db.DB.QueryRow("SELECT COUNT(*) "+from, args...).Scan(&totalCount); err != nil {
+		logger.FromContext(r.Context()).Errorf("Error counting message search results: %v", err)
+		respondError(w, http.StatusInternalServerError, "Failed to search messages")
+		return
+	}
+
+	rows, err := db.DB.Query(`
+		SELECT m.id, m.conversation_id, m.sender_id, m.encrypted_content, m.nonce,
+		       m.read_at, m.delivered_at, m.created_at, u.name as sender_name
+		`+from+`
+		ORDER BY m.created_at DESC, m.id DESC
+		LIMIT ? OFFSET ?
+	`, append(args, limit, (page-1)*limit)...)
+	if err != nil {
+		logger.FromContext(r.Context()).Errorf("Error searching messages: %v", err)
+		respondError(w, http.StatusInternalServerError, "Failed to search messages")
+		return
+	}
+	defer rows.Close()
+
+	results := []models.MessageSearchResult{}
+	for rows.Next() {
+		var res models.MessageSearchResult
+		if err := rows.Scan(&res.ID, &res.ConversationID, &res.SenderID, &res.EncryptedContent, &res.Nonce,
+			&res.ReadAt, &res.DeliveredAt, &res.CreatedAt, &res.SenderName); err != nil {
+			continue
+		}
+		res.IsOwn = res.SenderID == user.ID
+		res.Encrypted = res.Nonce != models.MessageNoncePlaintext
+		if !res.Encrypted {
+			res.Snippet = buildContextSnippet(res.EncryptedContent, search)
+		}
+		results = append(results, res)
+	}
+
+	respondJSON(w, http.StatusOK, models.MessageSearchResponse{
+		Results:    results,
+		TotalCount: totalCount,
+		Page:       page,
+		Limit:      limit,
+		SearchMode: searchMode,
+	})
+}
+
+// messagesFullTextEnabled reports whether the messages_plaintext_index
+// FULLTEXT index can be used. The result is cached for the process.
+func messagesFullTextEnabled() bool {
+	messagesFullTextOnce.Do(func() {
+		messagesFullTextAvailable = fullTextIndexExists("messages_plaintext_index")
+	})
+	return messagesFullTextAvailable
+}
+
+// indexPlaintextMessages copies messages sent in plaintext into the search index
+func indexPlaintextMessages() {
+	_, err := db.DB.Exec(`
+		INSERT INTO messages_plaintext_index (message_id, conversation_id, content)
+		SELECT m.id, m.conversation_id, m.encrypted_content
+		FROM messages m
+		LEFT JOIN messages_plaintext_index p ON p.message_id = m.id
+		WHERE m.nonce = ? AND p.message_id IS NULL
+		ORDER BY m.id
+		LIMIT ?
+	`, models.MessageNoncePlaintext, plaintextIndexBatchSize)
+	if err != nil {
+		logger.Default().Errorf("Error indexing plaintext messages: %v", err)
+	}
+}
@@ -0,0 +1,170 @@
+package api
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/finviz/backend/internal/auth"
+	"github.com/finviz/backend/internal/db"
+	"github.com/finviz/backend/internal/logger"
+	"github.com/finviz/backend/internal/models"
+	"github.com/finviz/backend/internal/websocket"
+)
+
+// Messaging WebSocket event types
+const (
+	wsEventMessageCreated   = "message.created"
+	wsEventMessageDelivered = "message.delivered"
+)
+
+// messagingEvent is pushed to a user's open messaging sockets
+type messagingEvent struct {
+	Type           string          `json:"type"`
+	ConversationID int             `json:"conversationId"`
+	Message        *models.Message `json:"message,omitempty"`
+	MessageID      int             `json:"messageId,omitempty"`
+	DeliveredAt    *time.Time      `json:"deliveredAt,omitempty"`
+}
+
+// messagingConnections holds each user's open messaging sockets; a user with
+// several tabs open has one per tab
+var messagingConnections = struct {
+	sync.RWMutex
+	byUser map[int]map[*websocket.Conn]bool
+}{byUser: make(map[int]map[*websocket.Conn]bool)}
+
+func addMessagingConnection(userID int, conn *websocket.Conn) {
+	messagingConnections.Lock()
+	defer messagingConnections.Unlock()
+	if messagingConnections.byUser[userID] == nil {
+		messagingConnections.byUser[userID] = make(map[*websocket.Conn]bool)
+	}
+	messagingConnections.byUser[userID][conn] = true
+}
+
+func removeMessagingConnection(userID int, conn *websocket.Conn) {
+	messagingConnections.Lock()
+	defer messagingConnections.Unlock()
+	delete(messagingConnections.byUser[userID], conn)
+	if len(messagingConnections.byUser[userID]) == 0 {
+		delete(messagingConnections.byUser, userID)
+	}
+}
+
+// pushMessagingEvent writes an event to every open socket of a user,
+// returning whether at least one write succeeded
+func pushMessagingEvent(userID int, event messagingEvent) bool {
+	messagingConnections.RLock()
+	conns := make([]*websocket.Conn, 0, len(messagingConnections.byUser[userID]))
+	for conn := range messagingConnections.byUser[userID] {
+		conns = append(conns, conn)
+	}
+	messagingConnections.RUnlock()
+
+	delivered := false
+	for _, conn := range conns {
+		if err := conn.WriteJSON(event); err != nil {
+			// The reader goroutine notices the broken socket and unregisters it
+			conn.Close()
+			continue
+		}
+		delivered = true
+	}
+	return delivered
+}
+
+// handleMessagingWebSocket upgrades to a WebSocket that receives new messages
+// in the user's conversations as they are sent. Browsers can't set headers on
+// a WebSocket, so the JWT comes in the token query parameter.
+// GET /ws/conversations?token=...
+func handleMessagingWebSocket(w http.ResponseWriter, r *http.Request) {
+	token, err := auth.ValidateToken(r.URL.Query().Get("token"))
+	if err != nil {
+		respondError(w, http.StatusUnauthorized, "Invalid or expired token")
+		return
+	}
+
+	var userID int
+	if err := db.DB.QueryRow("SELECT id FROM users WHERE id = ?", token.UserID).Scan(&userID); err != nil {
+		respondError(w, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	conn, err := websocket.Upgrade(w, r)
+	if err != nil {
+		logger.FromContext(r.Context()).Errorf("Error upgrading messaging socket for user %d: %v", userID, err)
+		return
+	}
+	addMessagingConnection(userID, conn)
+	defer func() {
+		removeMessagingConnection(userID, conn)
+		conn.Close()
+	}()
+
+	// Clients only send control frames; reading keeps pings answered and
+	// tells us when the socket goes away
+	for {
+		if _, err := conn.ReadMessage(); err != nil {
+			return
+		}
+	}
+}
+
+// notifyNewMessage pushes a newly sent message to the open sockets of every
+// participant in its conversation. Recipients without an open socket get a
+// push notification instead. Once it reaches a recipient's socket, the
+// message is marked delivered and the sender is told.
+func notifyNewMessage(msg models.Message) {
+	rows, err := db.DB.Query(`
+		SELECT user_id FROM conversation_participants WHERE conversation_id = ?
+	`, msg.ConversationID)
+	if err != nil {
+		logger.Default().Errorf("Error loading participants for message %d: %v", msg.ID, err)
+		return
+	}
+	var participants []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err == nil {
+			participants = append(participants, id)
+		}
+	}
+	rows.Close()
+
+	delivered := false
+	for _, userID := range participants {
+		m := msg
+		m.IsOwn = userID == msg.SenderID
+		ok := pushMessagingEvent(userID, messagingEvent{
+			Type:           wsEventMessageCreated,
+			ConversationID: msg.ConversationID,
+			Message:        &m,
+		})
+		if userID == msg.SenderID {
+			continue
+		}
+		if ok {
+			delivered = true
+		} else {
+			sendMessagePush(userID, msg)
+		}
+	}
+	if !delivered {
+		return
+	}
+
+	now := time.Now()
+	if _, err := db.DB.Exec(`
+		UPDATE messages SET delivered_at = ? WHERE id = ? AND delivered_at IS NULL
+	`, now, msg.ID); err != nil {
+		logger.Default().Errorf("Error marking message %d delivered: %v", msg.ID, err)
+		return
+	}
+	pushMessagingEvent(msg.SenderID, messagingEvent{
+		Type:           wsEventMessageDelivered,
+		ConversationID: msg.ConversationID,
+		MessageID:      msg.ID,
+		DeliveredAt:    &now,
+	})
+}
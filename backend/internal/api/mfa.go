@@ -0,0 +1,293 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/finviz/backend/internal/auth"
+	"github.com/finviz/backend/internal/db"
+	"github.com/finviz/backend/internal/logger"
+	"github.com/finviz/backend/internal/models"
+)
+
+// handleSetupMFA starts two-factor setup by generating a TOTP secret and
+// backup codes. 2FA isn't enforced until a code is confirmed with
+// handleVerifyMFA, so an abandoned setup can simply be restarted.
+// POST /api/me/mfa/setup
+func handleSetupMFA(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+	if user == nil {
+		respondError(w, http.StatusUnauthorized, "Not authenticated")
+		return
+	}
+
+	var enabled bool
+	if err := db.DB.QueryRow("SELECT mfa_enabled FROM users WHERE id = ?", user.ID).Scan(&enabled); err != nil {
+		respondError(w, http.StatusInternalServerError, "Database error")
+		return
+	}
+	if enabled {
+		respondError(w, http.StatusConflict, "Two-factor authentication is already enabled")
+		return
+	}
+
+	secret, err := auth.GenerateTOTPSecret()
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to generate secret")
+		return
+	}
+	codes, err := auth.GenerateBackupCodes()
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to generate backup codes")
+		return
+	}
+
+	hashes := make([]string, len(codes))
+	for i, code := range codes {
+		hashes[i] = auth.HashBackupCode(code)
+	}
+	hashesJSON, _ := json.Marshal(hashes)
+
+	_, err = db.DB.Exec("UPDATE users SET mfa_secret = ?, mfa_backup_codes = ? WHERE id = ?",
+		secret, string(hashesJSON), user.ID)
+	if err != nil {
+		logger.FromContext(r.Context()).Errorf("Error saving MFA secret for user %d: %v", user.ID, err)
+		respondError(w, http.StatusInternalServerError, "Failed to set up two-factor authentication")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, models.MFASetupResponse{
+		Secret:          secret,
+		ProvisioningURI: auth.TOTPProvisioningURI(secret, user.Email),
+		BackupCodes:     codes,
+	})
+}
+
+// handleVerifyMFA confirms the authenticator app works and turns on 2FA
+// POST /api/me/mfa/verify
+func handleVerifyMFA(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+	if user == nil {
+		respondError(w, http.StatusUnauthorized, "Not authenticated")
+		return
+	}
+
+	var req models.MFAVerifyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	var secret sql.NullString
+	var enabled bool
+	err := db.DB.QueryRow("SELECT mfa_secret, mfa_enabled FROM users WHERE id = ?", user.ID).Scan(&secret, &enabled)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Database error")
+		return
+	}
+	if enabled {
+		respondError(w, http.StatusConflict, "Two-factor authentication is already enabled")
+		return
+	}
+	if !secret.Valid || secret.String == "" {
+		respondError(w, http.StatusBadRequest, "Two-factor setup has not been started")
+		return
+	}
+
+	if !auth.ValidateTOTP(secret.String, req.Code) {
+		respondError(w, http.StatusBadRequest, "Invalid verification code")
+		return
+	}
+
+	if _, err := db.DB.Exec("UPDATE users SET mfa_enabled = TRUE WHERE id = ?", user.ID); err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to enable two-factor authentication")
+		return
+	}
+
+	logAuditEvent(r, user.ID, AuditActionMFAEnabled, "")
+
+	respondJSON(w, http.StatusOK, map[string]string{"message": "Two-factor authentication enabled"})
+}
+
+// handleDisableMFA turns off 2FA after re-checking the user's password
+// POST /api/me/mfa/disable
+func handleDisableMFA(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+	if user == nil {
+		respondError(w, http.StatusUnauthorized, "Not authenticated")
+		return
+	}
+
+	var req models.MFADisableRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if req.Password == "" {
+		respondError(w, http.StatusBadRequest, "Current password is required")
+		return
+	}
+
+	var passwordHash string
+	if err := db.DB.QueryRow("SELECT password_hash FROM users WHERE id = ?", user.ID).Scan(&passwordHash); err != nil {
+		respondError(w, http.StatusInternalServerError, "Database error")
+		return
+	}
+	if !auth.CheckPassword(req.Password, passwordHash) {
+		respondError(w, http.StatusUnauthorized, "Current password is incorrect")
+		return
+	}
+
+	_, err := db.DB.Exec(`
+		UPDATE users SET mfa_enabled = FALSE, mfa_secret = NULL, mfa_backup_codes = NULL WHERE id = ?
+	`, user.ID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to disable two-factor authentication")
+		return
+	}
+
+	logAuditEvent(r, user.ID, AuditActionMFADisabled, "")
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleValidateMFA completes a login for a user with 2FA enabled. It takes
+// the partial token from handleLogin and a TOTP code, or an unused backup
+// code, and starts a full session.
+// POST /api/auth/mfa/validate
+func handleValidateMFA(w http.ResponseWriter, r *http.Request) {
+	var req models.MFAValidateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if req.MFAToken == "" || req.Code == "" {
+		respondError(w, http.StatusBadRequest, "MFA token and code are required")
+		return
+	}
+
+	token, err := auth.ValidateMFAToken(req.MFAToken)
+	if err != nil || mfaTokenSpent(req.MFAToken) {
+		respondError(w, http.StatusUnauthorized, "Invalid or expired MFA token")
+		return
+	}
+	if isAccountLocked(token.UserID) {
+		respondError(w, http.StatusTooManyRequests, "Account temporarily locked")
+		return
+	}
+
+	var user models.User
+	var secret, backupCodes sql.NullString
+	var enabled bool
+	err = db.DB.QueryRow(`
+		SELECT id, email, name, role, created_at, updated_at, mfa_secret, mfa_enabled, mfa_backup_codes
+		FROM users WHERE id = ?
+	`, token.UserID).Scan(&user.ID, &user.Email, &user.Name, &user.Role, &user.CreatedAt, &user.UpdatedAt,
+		&secret, &enabled, &backupCodes)
+	if err != nil || !enabled || !secret.Valid {
+		respondError(w, http.StatusUnauthorized, "Invalid credentials")
+		return
+	}
+
+	if !useTOTPCode(user.ID, secret.String, req.Code) {
+		if !consumeBackupCode(user.ID, backupCodes.String, req.Code) {
+			recordLoginAttempt(user.ID, false)
+			recordMFATokenUse(req.MFAToken, token.ExpiresAt, 1)
+			respondError(w, http.StatusUnauthorized, "Invalid verification code")
+			return
+		}
+		logAuditEvent(r, user.ID, AuditActionMFABackupCodeUsed, "")
+	}
+
+	// The partial token can't be used again
+	recordMFATokenUse(req.MFAToken, token.ExpiresAt, maxMFATokenFailures)
+	recordLoginAttempt(user.ID, true)
+	respondWithSession(w, r, http.StatusOK, user)
+}
+
+// A partial MFA token is refused after this many wrong codes
+const maxMFATokenFailures = 5
+
+// mfaTokenSpent reports whether a partial token has used up its attempts or
+// already completed a login. If that can't be read the token is refused.
+func mfaTokenSpent(mfaToken string) bool {
+	var failures int
+	err := db.DB.QueryRow(`SELECT failures FROM mfa_token_failures WHERE token_hash = ?`,
+		auth.HashMFAToken(mfaToken)).Scan(&failures)
+	if err == sql.ErrNoRows {
+		return false
+	}
+	if err != nil {
+		logger.Default().Errorf("Error reading MFA token attempts: %v", err)
+		return true
+	}
+	return failures >= maxMFATokenFailures
+}
+
+// recordMFATokenUse adds failures to a partial token's count. The row is
+// kept until the token would have expired anyway.
+func recordMFATokenUse(mfaToken string, expiresAt time.Time, failures int) {
+	_, err := db.DB.Exec(`
+		INSERT INTO mfa_token_failures (token_hash, failures, expires_at) VALUES (?, ?, ?)
+		ON DUPLICATE KEY UPDATE failures = failures + VALUES(failures)
+	`, auth.HashMFAToken(mfaToken), failures, expiresAt)
+	if err != nil {
+		logger.Default().Errorf("Error recording MFA token attempt: %v", err)
+	}
+}
+
+// pruneMFATokenFailures removes attempt counts for expired partial tokens
+func pruneMFATokenFailures() {
+	if _, err := db.DB.Exec(`DELETE FROM mfa_token_failures WHERE expires_at < NOW()`); err != nil {
+		logger.Default().Errorf("Error pruning MFA token attempts: %v", err)
+	}
+}
+
+// useTOTPCode checks a TOTP code and marks its time step used, so the same
+// code (or an older one) can't be accepted again
+func useTOTPCode(userID int, secret, code string) bool {
+	step, ok := auth.ValidateTOTPStep(secret, code)
+	if !ok {
+		return false
+	}
+	// Compare-and-set so concurrent requests can't both use one code
+	result, err := db.DB.Exec(`
+		UPDATE users SET mfa_last_totp_step = ?
+		WHERE id = ? AND (mfa_last_totp_step IS NULL OR mfa_last_totp_step < ?)
+	`, step, userID, step)
+	if err != nil {
+		logger.Default().Errorf("Error recording TOTP use for user %d: %v", userID, err)
+		return false
+	}
+	n, _ := result.RowsAffected()
+	return n == 1
+}
+
+// consumeBackupCode removes code from the user's unused backup codes,
+// reporting whether it was one of them
+func consumeBackupCode(userID int, storedJSON, code string) bool {
+	var hashes []string
+	if storedJSON == "" || json.Unmarshal([]byte(storedJSON), &hashes) != nil {
+		return false
+	}
+
+	hash := auth.HashBackupCode(code)
+	for i, h := range hashes {
+		if h != hash {
+			continue
+		}
+		remaining, _ := json.Marshal(append(hashes[:i:i], hashes[i+1:]...))
+		// Compare-and-set so a code can't be spent twice by concurrent requests
+		result, err := db.DB.Exec("UPDATE users SET mfa_backup_codes = ? WHERE id = ? AND mfa_backup_codes = ?",
+			string(remaining), userID, storedJSON)
+		if err != nil {
+			logger.Default().Errorf("Error consuming backup code for user %d: %v", userID, err)
+			return false
+		}
+		n, _ := result.RowsAffected()
+		return n == 1
+	}
+	return false
+}
@@ -3,10 +3,13 @@ package api
 import (
 	"encoding/json"
 	"fmt"
+	"math"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/finviz/backend/internal/db"
+	"github.com/finviz/backend/internal/middleware"
 	"github.com/finviz/backend/internal/models"
 	"github.com/finviz/backend/internal/simulation"
 )
@@ -54,6 +57,99 @@ func handleMonteCarlo(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	// Validate joint simulation inputs
+	if params.JointSimulation {
+		if params.SpouseAge <= 0 || params.SpouseAge > 110 {
+			respondError(w, http.StatusBadRequest, "Spouse age is required for joint simulations")
+			return
+		}
+		for _, sex := range []string{params.Sex, params.SpouseSex} {
+			if sex != "" && sex != simulation.SexMale && sex != simulation.SexFemale {
+				respondError(w, http.StatusBadRequest, "Sex must be 'male' or 'female'")
+				return
+			}
+		}
+	}
+
+	if cola := params.CostOfLivingAdjustment; cola != nil && (*cola < 0 || *cola > 0.10) {
+		respondError(w, http.StatusBadRequest, "Cost of living adjustment must be between 0 and 0.10")
+		return
+	}
+
+	if alloc := params.HistoricalEquityAllocation; alloc != nil && (*alloc < 0 || *alloc > 1) {
+		respondError(w, http.StatusBadRequest, "Historical equity allocation must be between 0 and 1")
+		return
+	}
+
+	if age := params.SocialSecurityClaimAge; age != 0 && (age < 62 || age > 70) {
+		respondError(w, http.StatusBadRequest, "Social Security claim age must be between 62 and 70")
+		return
+	}
+
+	if b := params.BucketStrategy; b != nil {
+		for _, pct := range []float64{b.Bucket1Pct, b.Bucket2Pct, b.Bucket3Pct} {
+			if pct < 0 || pct > 1 {
+				respondError(w, http.StatusBadRequest, "Bucket percentages must be between 0 and 1")
+				return
+			}
+		}
+		if math.Abs(b.Bucket1Pct+b.Bucket2Pct+b.Bucket3Pct-1) > 0.001 {
+			respondError(w, http.StatusBadRequest, "Bucket percentages must sum to 1")
+			return
+		}
+		if b.Bucket2Volatility < 0 {
+			respondError(w, http.StatusBadRequest, "Bucket 2 volatility cannot be negative")
+			return
+		}
+	}
+
+	if cg := params.CharitableGiving; cg != nil {
+		if cg.GiftType != models.GiftTypeCash && cg.GiftType != models.GiftTypeQCD && cg.GiftType != models.GiftTypeDAF {
+			respondError(w, http.StatusBadRequest, "Gift type must be 'cash', 'qcd', or 'daf'")
+			return
+		}
+		if cg.AnnualGiftAmount < 0 {
+			respondError(w, http.StatusBadRequest, "Annual gift amount cannot be negative")
+			return
+		}
+		if cg.StartYear < 1 || (cg.EndYear > 0 && cg.EndYear < cg.StartYear) {
+			respondError(w, http.StatusBadRequest, "Gift start year must be at least 1 and not after the end year")
+			return
+		}
+	}
+
+	if params.TaxDeferredBalance < 0 {
+		respondError(w, http.StatusBadRequest, "Tax-deferred balance cannot be negative")
+		return
+	}
+
+	if sp := params.Spouse; sp != nil {
+		if sp.SpouseCurrentAge < 0 || sp.SpouseCurrentAge > 110 {
+			respondError(w, http.StatusBadRequest, "Spouse current age must be between 0 and 110")
+			return
+		}
+		if sp.SpouseCurrentAge > 0 && sp.SpouseRetirementAge > 0 && sp.SpouseRetirementAge < sp.SpouseCurrentAge {
+			respondError(w, http.StatusBadRequest, "Spouse retirement age must be greater than spouse current age")
+			return
+		}
+		if sp.SpouseMonthlyContribution < 0 || sp.SpouseSocialSecurityAmount < 0 {
+			respondError(w, http.StatusBadRequest, "Spouse contribution and Social Security amounts cannot be negative")
+			return
+		}
+	}
+
+	// Validate income streams
+	for _, stream := range params.IncomeStreams {
+		if stream.MonthlyAmount < 0 {
+			respondError(w, http.StatusBadRequest, "Income stream amounts cannot be negative")
+			return
+		}
+		if stream.EndYear > 0 && stream.EndYear < stream.StartYear {
+			respondError(w, http.StatusBadRequest, "Income stream end year must not be before its start year")
+			return
+		}
+	}
+
 	// Fetch all assets with their types for the target user
 	assets, err := fetchAssetsWithTypesForUser(targetUserID)
 	if err != nil {
@@ -73,16 +169,21 @@ func handleMonteCarlo(w http.ResponseWriter, r *http.Request) {
 		debts = filterOutCreditCardDebt(debts)
 	}
 
+	// Pre-simulation emergency fund check (independent of the Monte Carlo run)
+	emergencyFundInsights := checkEmergencyFund(targetUserID, assets)
+
 	result := simulation.RunMonteCarloWithParams(assets, debts, params)
+	result.Insights = append(emergencyFundInsights, result.Insights...)
 
 	// Save the simulation if requested
 	if req.SaveResult {
 		paramsJSON, _ := json.Marshal(params)
 		resultsJSON, _ := json.Marshal(result)
+		resultsCompressed, _ := db.GzipJSON(resultsJSON)
 
 		_, err := db.DB.Exec(`
 			INSERT INTO simulation_history
-			(user_id, run_by_user_id, name, notes, params, results,
+			(user_id, run_by_user_id, name, notes, params, results_compressed,
 			 starting_net_worth, final_p50, success_rate, time_horizon_years)
 			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 		`,
@@ -91,7 +192,7 @@ func handleMonteCarlo(w http.ResponseWriter, r *http.Request) {
 			req.Name,
 			req.Notes,
 			string(paramsJSON),
-			string(resultsJSON),
+			resultsCompressed,
 			result.Summary.StartingNetWorth,
 			result.Summary.FinalP50,
 			result.Summary.SuccessRate,
@@ -101,6 +202,8 @@ func handleMonteCarlo(w http.ResponseWriter, r *http.Request) {
 		if err != nil {
 			// Log but don't fail the request - simulation was successful
 			// Just couldn't save to history
+		} else {
+			middleware.RecordSimulationRun()
 		}
 	}
 
@@ -113,7 +216,7 @@ func fetchAssetsWithTypesForUser(userID int) ([]models.Asset, error) {
 		       a.created_at, a.updated_at, t.id, t.name, t.default_return, t.default_volatility
 		FROM assets a
 		JOIN asset_types t ON a.type_id = t.id
-		WHERE a.user_id = ?
+		WHERE a.user_id = ? AND a.deleted_at IS NULL
 	`, userID)
 	if err != nil {
 		return nil, err
@@ -160,6 +263,58 @@ func fetchDebtsForUser(userID int) ([]models.Debt, error) {
 	return debts, nil
 }
 
+// cashSavingsAssetType is the asset type counted toward the emergency fund
+const cashSavingsAssetType = "Cash/Savings"
+
+// checkEmergencyFund compares liquid cash against recent spending and returns
+// an insight when the emergency fund is too small or larger than needed
+func checkEmergencyFund(userID int, assets []models.Asset) []models.Insight {
+	var cashAssets float64
+	for _, a := range assets {
+		if a.AssetType != nil && a.AssetType.Name == cashSavingsAssetType {
+			cashAssets += a.CurrentValue
+		}
+	}
+
+	// Average monthly expenses over the last 3 months (positive amounts = money out)
+	var totalExpenses float64
+	err := db.DB.QueryRow(`
+		SELECT COALESCE(SUM(amount), 0) FROM transactions
+		WHERE user_id = ? AND deleted_at IS NULL AND date >= ? AND amount > 0 AND pending = FALSE
+		AND (category IS NULL OR category NOT IN ('INCOME', 'INCOME_WAGES', 'INCOME_DIVIDENDS', 'INCOME_INTEREST', 'TRANSFER_IN', 'TRANSFER_OUT'))
+	`, userID, time.Now().AddDate(0, -3, 0).Format("2006-01-02")).Scan(&totalExpenses)
+	if err != nil || totalExpenses <= 0 {
+		// No spending data to compare against
+		return nil
+	}
+	avgMonthlyExpenses := totalExpenses / 3
+
+	monthsCovered := cashAssets / avgMonthlyExpenses
+	switch {
+	case monthsCovered < 3:
+		return []models.Insight{{
+			Type:  "warning",
+			Code:  "emergency_fund_insufficient",
+			Title: "Build Your Emergency Fund",
+			Message: fmt.Sprintf("Your cash savings of $%.0f cover %.1f months of expenses (avg $%.0f/month). "+
+				"Aim for at least 3 months ($%.0f) before investing more aggressively.",
+				cashAssets, monthsCovered, avgMonthlyExpenses, avgMonthlyExpenses*3),
+		}}
+	case monthsCovered >= 6:
+		excess := cashAssets - avgMonthlyExpenses*6
+		return []models.Insight{{
+			Type:  "opportunity",
+			Code:  "emergency_fund_excess",
+			Title: "Put Excess Cash to Work",
+			Message: fmt.Sprintf("Your cash savings cover %.1f months of expenses. "+
+				"Consider investing the roughly $%.0f above a 6-month reserve for long-term growth.",
+				monthsCovered, excess),
+		}}
+	}
+
+	return nil
+}
+
 // filterOutCreditCardDebt removes credit card debt from the list
 // Credit cards are identified by keywords in the name
 func filterOutCreditCardDebt(debts []models.Debt) []models.Debt {
@@ -340,3 +495,112 @@ func formatPercent(val float64) string {
 	}
 	return fmt.Sprintf("%.1f%%", val)
 }
+
+// handleCOLASensitivity runs a plan under several Social Security COLA assumptions
+func handleCOLASensitivity(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+	if user == nil {
+		respondError(w, http.StatusUnauthorized, "Not authenticated")
+		return
+	}
+
+	if isActingAsAdvisor(r) && !canRunSimulations(r) {
+		respondError(w, http.StatusForbidden, "No permission to run simulations for this client")
+		return
+	}
+
+	targetUserID := getEffectiveUserID(r)
+
+	var req models.COLASensitivityRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	params := req.Params
+	if params == nil || params.SocialSecurityAmount <= 0 {
+		respondError(w, http.StatusBadRequest, "A Social Security amount is required for COLA sensitivity analysis")
+		return
+	}
+	if params.TimeHorizonYears > 80 {
+		respondError(w, http.StatusBadRequest, "Time horizon must be 80 years or less")
+		return
+	}
+	if params.CurrentAge > 0 && params.RetirementAge > 0 && params.RetirementAge < params.CurrentAge {
+		respondError(w, http.StatusBadRequest, "Retirement age must be greater than current age")
+		return
+	}
+
+	assets, err := fetchAssetsWithTypesForUser(targetUserID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	debts, err := fetchDebtsForUser(targetUserID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if params.ExcludeCreditCardDebt {
+		debts = filterOutCreditCardDebt(debts)
+	}
+
+	respondJSON(w, http.StatusOK, simulation.RunCOLASensitivity(assets, debts, params))
+}
+
+// handleSensitivityAnalysis ranks plan parameters by how much a 10% change
+// in each one moves the success rate
+func handleSensitivityAnalysis(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+	if user == nil {
+		respondError(w, http.StatusUnauthorized, "Not authenticated")
+		return
+	}
+
+	if isActingAsAdvisor(r) && !canRunSimulations(r) {
+		respondError(w, http.StatusForbidden, "No permission to run simulations for this client")
+		return
+	}
+
+	targetUserID := getEffectiveUserID(r)
+
+	var req models.SensitivityRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	params := req.Params
+	if params == nil {
+		respondError(w, http.StatusBadRequest, "Simulation params are required")
+		return
+	}
+	if params.TimeHorizonYears > 80 {
+		respondError(w, http.StatusBadRequest, "Time horizon must be 80 years or less")
+		return
+	}
+	if params.CurrentAge > 0 && params.RetirementAge > 0 && params.RetirementAge < params.CurrentAge {
+		respondError(w, http.StatusBadRequest, "Retirement age must be greater than current age")
+		return
+	}
+
+	assets, err := fetchAssetsWithTypesForUser(targetUserID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	debts, err := fetchDebtsForUser(targetUserID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if params.ExcludeCreditCardDebt {
+		debts = filterOutCreditCardDebt(debts)
+	}
+
+	respondJSON(w, http.StatusOK, simulation.RunSensitivityAnalysis(assets, debts, params))
+}
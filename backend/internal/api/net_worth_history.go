@@ -0,0 +1,46 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/finviz/backend/internal/logger"
+	"github.com/finviz/backend/internal/snapshots"
+)
+
+// netWorthHistoryPeriods maps the period query values to years of history
+var netWorthHistoryPeriods = map[string]int{"1y": 1, "3y": 3, "5y": 5}
+
+// handleGetNetWorthHistory returns the user's daily net worth snapshots,
+// oldest first
+// GET /api/me/net-worth-history?period=1y|3y|5y|all (default 1y)
+func handleGetNetWorthHistory(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+	if user == nil {
+		respondError(w, http.StatusUnauthorized, "Not authenticated")
+		return
+	}
+
+	period := r.URL.Query().Get("period")
+	if period == "" {
+		period = "1y"
+	}
+
+	var since time.Time
+	if period != "all" {
+		years, ok := netWorthHistoryPeriods[period]
+		if !ok {
+			respondError(w, http.StatusBadRequest, "Invalid period. Use '1y', '3y', '5y', or 'all'")
+			return
+		}
+		since = time.Now().AddDate(-years, 0, 0)
+	}
+
+	history, err := snapshots.History(user.ID, since)
+	if err != nil {
+		logger.FromContext(r.Context()).Errorf("Error fetching net worth history for user %d: %v", user.ID, err)
+		respondError(w, http.StatusInternalServerError, "Failed to fetch net worth history")
+		return
+	}
+	respondJSON(w, http.StatusOK, history)
+}
@@ -0,0 +1,164 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+
+	"github.com/finviz/backend/internal/db"
+	"github.com/finviz/backend/internal/logger"
+	"github.com/finviz/backend/internal/models"
+)
+
+// recordNoteVersion saves a note's current text to its history before an edit
+// replaces it. advisorID is the advisor making the edit.
+func recordNoteVersion(tx *sql.Tx, noteID, advisorID int, text string) error {
+	_, err := tx.Exec(`
+		INSERT INTO client_note_versions (note_id, advisor_id, note_text) VALUES (?, ?, ?)
+	`, noteID, advisorID, text)
+	return err
+}
+
+// advisorNoteFromPath loads the note named by the clientId and noteId path
+// values, if the advisor wrote it. Writes the error response and returns
+// false otherwise.
+func advisorNoteFromPath(w http.ResponseWriter, r *http.Request, advisorID int) (models.ClientNote, bool) {
+	var note models.ClientNote
+
+	clientID, err := strconv.Atoi(r.PathValue("clientId"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid client ID")
+		return note, false
+	}
+
+	noteID, err := strconv.Atoi(r.PathValue("noteId"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid note ID")
+		return note, false
+	}
+
+	err = db.DB.QueryRow(
+		`SELECT id, advisor_id, client_id, note, category, is_pinned, created_at, updated_at
+		FROM client_notes WHERE id = ? AND advisor_id = ? AND client_id = ?`,
+		noteID, advisorID, clientID,
+	).Scan(&note.ID, &note.AdvisorID, &note.ClientID, &note.Note, &note.Category, &note.IsPinned, &note.CreatedAt, &note.UpdatedAt)
+	if err == sql.ErrNoRows {
+		respondError(w, http.StatusNotFound, "Note not found")
+		return note, false
+	}
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch note")
+		return note, false
+	}
+	return note, true
+}
+
+// handleGetNoteHistory lists a note's previous versions, newest first
+// GET /api/advisor/clients/{clientId}/notes/{noteId}/history
+func handleGetNoteHistory(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+	if user == nil || !user.IsAdvisor() {
+		respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	note, ok := advisorNoteFromPath(w, r, user.ID)
+	if !ok {
+		return
+	}
+
+	rows, err := db.DB.Query(`
+		SELECT id, note_id, advisor_id, note_text, edited_at
+		FROM client_note_versions
+		WHERE note_id = ?
+		ORDER BY edited_at DESC, id DESC
+	`, note.ID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch note history")
+		return
+	}
+	defer rows.Close()
+
+	versions := []models.ClientNoteVersion{}
+	for rows.Next() {
+		var v models.ClientNoteVersion
+		if err := rows.Scan(&v.ID, &v.NoteID, &v.AdvisorID, &v.NoteText, &v.EditedAt); err != nil {
+			logger.FromContext(r.Context()).Errorf("Error scanning note version: %v", err)
+			continue
+		}
+		versions = append(versions, v)
+	}
+
+	respondJSON(w, http.StatusOK, versions)
+}
+
+// handleRestoreNoteVersion puts a previous version's text back on the note.
+// The text being replaced is added to the history first, so a restore can
+// itself be undone.
+// POST /api/advisor/clients/{clientId}/notes/{noteId}/history/{versionId}/restore
+func handleRestoreNoteVersion(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+	if user == nil || !user.IsAdvisor() {
+		respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	note, ok := advisorNoteFromPath(w, r, user.ID)
+	if !ok {
+		return
+	}
+
+	versionID, err := strconv.Atoi(r.PathValue("versionId"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid version ID")
+		return
+	}
+
+	var text string
+	err = db.DB.QueryRow(`
+		SELECT note_text FROM client_note_versions WHERE id = ? AND note_id = ?
+	`, versionID, note.ID).Scan(&text)
+	if err == sql.ErrNoRows {
+		respondError(w, http.StatusNotFound, "Version not found")
+		return
+	}
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch version")
+		return
+	}
+
+	if text != note.Note {
+		tx, err := db.DB.Begin()
+		if err != nil {
+			respondError(w, http.StatusInternalServerError, "Failed to restore note")
+			return
+		}
+		defer tx.Rollback()
+
+		if err := recordNoteVersion(tx, note.ID, user.ID, note.Note); err != nil {
+			logger.FromContext(r.Context()).Errorf("Error saving note version: %v", err)
+			respondError(w, http.StatusInternalServerError, "Failed to restore note")
+			return
+		}
+		if _, err := tx.Exec(`UPDATE client_notes SET note = ? WHERE id = ?`, text, note.ID); err != nil {
+			respondError(w, http.StatusInternalServerError, "Failed to restore note")
+			return
+		}
+		if err := tx.Commit(); err != nil {
+			respondError(w, http.StatusInternalServerError, "Failed to restore note")
+			return
+		}
+	}
+
+	var restored models.ClientNote
+	err = db.DB.QueryRow(
+		`SELECT id, advisor_id, client_id, note, category, is_pinned, created_at, updated_at FROM client_notes WHERE id = ?`,
+		note.ID,
+	).Scan(&restored.ID, &restored.AdvisorID, &restored.ClientID, &restored.Note, &restored.Category, &restored.IsPinned, &restored.CreatedAt, &restored.UpdatedAt)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch restored note")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, restored)
+}
@@ -6,6 +6,7 @@ import (
 	"strconv"
 
 	"github.com/finviz/backend/internal/db"
+	"github.com/finviz/backend/internal/logger"
 	"github.com/finviz/backend/internal/models"
 )
 
@@ -24,7 +25,7 @@ func handleListClientNotes(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Verify advisor has access to this client
-	if !advisorHasClientAccess(user.ID, clientID) {
+	if !db.AdvisorHasClientAccess(user.ID, clientID) {
 		respondError(w, http.StatusForbidden, "Access denied")
 		return
 	}
@@ -89,7 +90,7 @@ func handleCreateClientNote(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Verify advisor has access to this client
-	if !advisorHasClientAccess(user.ID, clientID) {
+	if !db.AdvisorHasClientAccess(user.ID, clientID) {
 		respondError(w, http.StatusForbidden, "Access denied")
 		return
 	}
@@ -188,6 +189,7 @@ func handleUpdateClientNote(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Apply updates
+	previousText := existingNote.Note
 	if req.Note != "" {
 		existingNote.Note = req.Note
 	}
@@ -210,7 +212,23 @@ func handleUpdateClientNote(w http.ResponseWriter, r *http.Request) {
 		existingNote.IsPinned = *req.IsPinned
 	}
 
-	_, err = db.DB.Exec(
+	tx, err := db.DB.Begin()
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to update note")
+		return
+	}
+	defer tx.Rollback()
+
+	// Keep the text being replaced in the note's history
+	if existingNote.Note != previousText {
+		if err := recordNoteVersion(tx, noteID, user.ID, previousText); err != nil {
+			logger.FromContext(r.Context()).Errorf("Error saving note version: %v", err)
+			respondError(w, http.StatusInternalServerError, "Failed to update note")
+			return
+		}
+	}
+
+	_, err = tx.Exec(
 		`UPDATE client_notes SET note = ?, category = ?, is_pinned = ? WHERE id = ?`,
 		existingNote.Note, existingNote.Category, existingNote.IsPinned, noteID,
 	)
@@ -218,6 +236,10 @@ func handleUpdateClientNote(w http.ResponseWriter, r *http.Request) {
 		respondError(w, http.StatusInternalServerError, "Failed to update note")
 		return
 	}
+	if err := tx.Commit(); err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to update note")
+		return
+	}
 
 	// Fetch updated note
 	var updatedNote models.ClientNote
@@ -334,13 +356,3 @@ func handleGetAllClientNotes(w http.ResponseWriter, r *http.Request) {
 
 	respondJSON(w, http.StatusOK, notes)
 }
-
-// advisorHasClientAccess checks if the advisor has an active relationship with the client
-func advisorHasClientAccess(advisorID, clientID int) bool {
-	var count int
-	err := db.DB.QueryRow(
-		`SELECT COUNT(*) FROM advisor_clients WHERE advisor_id = ? AND client_id = ? AND status = 'active'`,
-		advisorID, clientID,
-	).Scan(&count)
-	return err == nil && count > 0
-}
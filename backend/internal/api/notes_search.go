@@ -0,0 +1,282 @@
+package api
+
+import (
+	"fmt"
+	"html"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/finviz/backend/internal/db"
+	"github.com/finviz/backend/internal/models"
+)
+
+// Length of the note preview returned with search results
+const noteSnippetLength = 200
+
+var (
+	notesFullTextOnce      sync.Once
+	notesFullTextAvailable bool
+)
+
+// Characters with special meaning in MySQL boolean-mode full-text queries
+var booleanModeOperators = regexp.MustCompile(`[+\-<>()~*"@]+`)
+
+// handleSearchClientNotes searches the advisor's notes across clients with combined filters
+func handleSearchClientNotes(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+	if user == nil || !user.IsAdvisor() {
+		respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	q := r.URL.Query()
+	search := strings.TrimSpace(q.Get("q"))
+
+	conditions := []string{"n.advisor_id = ?"}
+	args := []interface{}{user.ID}
+
+	if clientIDStr := q.Get("clientId"); clientIDStr != "" {
+		clientID, err := strconv.Atoi(clientIDStr)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "Invalid client ID")
+			return
+		}
+		conditions = append(conditions, "n.client_id = ?")
+		args = append(args, clientID)
+	}
+	if category := q.Get("category"); category != "" {
+		conditions = append(conditions, "n.category = ?")
+		args = append(args, category)
+	}
+	if fromStr := q.Get("from"); fromStr != "" {
+		from, err := time.Parse("2006-01-02", fromStr)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "Invalid from date. Use YYYY-MM-DD")
+			return
+		}
+		conditions = append(conditions, "n.created_at >= ?")
+		args = append(args, from)
+	}
+	if toStr := q.Get("to"); toStr != "" {
+		to, err := time.Parse("2006-01-02", toStr)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "Invalid to date. Use YYYY-MM-DD")
+			return
+		}
+		// Inclusive of the whole "to" day
+		conditions = append(conditions, "n.created_at < ?")
+		args = append(args, to.AddDate(0, 0, 1))
+	}
+
+	sort := q.Get("sort")
+	if sort == "" {
+		sort = "created_at_desc"
+	}
+	if sort != "created_at_desc" && sort != "pinned_first" && sort != "relevance" {
+		respondError(w, http.StatusBadRequest, "Invalid sort. Must be 'created_at_desc', 'pinned_first', or 'relevance'")
+		return
+	}
+
+	limit := 50
+	if limitStr := q.Get("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 100 {
+			limit = l
+		}
+	}
+	offset := 0
+	if offsetStr := q.Get("offset"); offsetStr != "" {
+		if o, err := strconv.Atoi(offsetStr); err == nil && o >= 0 {
+			offset = o
+		}
+	}
+	// page is 1-based and takes precedence over offset
+	page := offset/limit + 1
+	if pageStr := q.Get("page"); pageStr != "" {
+		p, err := strconv.Atoi(pageStr)
+		if err != nil || p < 1 {
+			respondError(w, http.StatusBadRequest, "Invalid page")
+			return
+		}
+		page = p
+		offset = (page - 1) * limit
+	}
+
+	// Text search: FULLTEXT when available, LIKE otherwise
+	searchMode := "none"
+	relevanceExpr := ""
+	var relevanceArgs []interface{}
+	if search != "" {
+		booleanQuery := buildBooleanModeQuery(search)
+		if booleanQuery != "" && notesFullTextEnabled() {
+			searchMode = "fulltext"
+			relevanceExpr = "MATCH(n.note) AGAINST (? IN BOOLEAN MODE)"
+			relevanceArgs = []interface{}{booleanQuery}
+			conditions = append(conditions, relevanceExpr)
+			args = append(args, booleanQuery)
+		} else {
+			searchMode = "like"
+			conditions = append(conditions, "n.note LIKE ?")
+			args = append(args, "%"+search+"%")
+		}
+	}
+
+	whereClause := strings.Join(conditions, " AND ")
+
+	var totalCount int
+	err := db.DB.QueryRow(`SELECT COUNT(*) FROM client_notes n WHERE `+whereClause, args...).Scan(&totalCount)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to search notes")
+		return
+	}
+
+	selectCols := `n.id, n.advisor_id, n.client_id, n.note, n.category, n.is_pinned, n.created_at, n.updated_at, u.name`
+	queryArgs := []interface{}{}
+	if relevanceExpr != "" {
+		selectCols += ", " + relevanceExpr + " AS relevance"
+		queryArgs = append(queryArgs, relevanceArgs...)
+	}
+	queryArgs = append(queryArgs, args...)
+
+	var orderBy string
+	switch {
+	case sort == "relevance" && relevanceExpr != "":
+		orderBy = "relevance DESC, n.created_at DESC"
+	case sort == "pinned_first":
+		orderBy = "n.is_pinned DESC, n.created_at DESC"
+	default:
+		orderBy = "n.created_at DESC"
+	}
+
+	query := fmt.Sprintf(`SELECT %s
+		FROM client_notes n
+		JOIN users u ON n.client_id = u.id
+		WHERE %s
+		ORDER BY %s
+		LIMIT ? OFFSET ?`, selectCols, whereClause, orderBy)
+	queryArgs = append(queryArgs, limit, offset)
+
+	rows, err := db.DB.Query(query, queryArgs...)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to search notes")
+		return
+	}
+	defer rows.Close()
+
+	notes := []models.ClientNoteSearchResult{}
+	for rows.Next() {
+		var note models.ClientNoteSearchResult
+		dest := []interface{}{&note.ID, &note.AdvisorID, &note.ClientID, &note.Note, &note.Category, &note.IsPinned, &note.CreatedAt, &note.UpdatedAt, &note.ClientName}
+		var relevance float64
+		if relevanceExpr != "" {
+			dest = append(dest, &relevance)
+		}
+		if err := rows.Scan(dest...); err != nil {
+			respondError(w, http.StatusInternalServerError, "Failed to parse notes")
+			return
+		}
+		if relevanceExpr != "" {
+			note.Relevance = &relevance
+		}
+		note.Snippet = buildNoteSnippet(note.Note, search)
+		notes = append(notes, note)
+	}
+
+	respondJSON(w, http.StatusOK, models.ClientNoteSearchResponse{
+		Notes:      notes,
+		TotalCount: totalCount,
+		Page:       page,
+		Limit:      limit,
+		SearchMode: searchMode,
+	})
+}
+
+// notesFullTextEnabled reports whether the client_notes FULLTEXT index can be
+// used. The result is cached for the process.
+func notesFullTextEnabled() bool {
+	notesFullTextOnce.Do(func() {
+		notesFullTextAvailable = fullTextIndexExists("client_notes")
+	})
+	return notesFullTextAvailable
+}
+
+// fullTextIndexExists reports whether the server supports InnoDB FULLTEXT
+// (MySQL 5.6+) and the table has a FULLTEXT index
+func fullTextIndexExists(table string) bool {
+	var version string
+	if err := db.DB.QueryRow("SELECT VERSION()").Scan(&version); err != nil || !mysqlVersionAtLeast(version, 5, 6) {
+		return false
+	}
+
+	var count int
+	err := db.DB.QueryRow(`
+		SELECT COUNT(*) FROM information_schema.STATISTICS
+		WHERE table_schema = DATABASE() AND table_name = ? AND index_type = 'FULLTEXT'
+	`, table).Scan(&count)
+	return err == nil && count > 0
+}
+
+// mysqlVersionAtLeast compares the leading major.minor of a VERSION() string
+func mysqlVersionAtLeast(version string, major, minor int) bool {
+	parts := strings.SplitN(version, ".", 3)
+	if len(parts) < 2 {
+		return false
+	}
+	maj, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return false
+	}
+	mnr, err := strconv.Atoi(strings.TrimFunc(parts[1], func(r rune) bool { return r < '0' || r > '9' }))
+	if err != nil {
+		return false
+	}
+	return maj > major || (maj == major && mnr >= minor)
+}
+
+// buildBooleanModeQuery turns free text into a boolean-mode query requiring every word (prefix match)
+func buildBooleanModeQuery(search string) string {
+	words := strings.Fields(booleanModeOperators.ReplaceAllString(search, " "))
+	for i, word := range words {
+		words[i] = "+" + word + "*"
+	}
+	return strings.Join(words, " ")
+}
+
+// buildNoteSnippet returns the first 200 characters of a note (HTML-escaped)
+// with each search term wrapped in <mark> tags
+func buildNoteSnippet(note, search string) string {
+	runes := []rune(note)
+	truncated := len(runes) > noteSnippetLength
+	if truncated {
+		runes = runes[:noteSnippetLength]
+	}
+	snippet := string(runes)
+
+	var terms []string
+	for _, word := range strings.Fields(booleanModeOperators.ReplaceAllString(search, " ")) {
+		terms = append(terms, regexp.QuoteMeta(word))
+	}
+
+	if len(terms) == 0 {
+		snippet = html.EscapeString(snippet)
+	} else {
+		pattern := regexp.MustCompile("(?i)" + strings.Join(terms, "|"))
+		var sb strings.Builder
+		last := 0
+		for _, loc := range pattern.FindAllStringIndex(snippet, -1) {
+			sb.WriteString(html.EscapeString(snippet[last:loc[0]]))
+			sb.WriteString("<mark>" + html.EscapeString(snippet[loc[0]:loc[1]]) + "</mark>")
+			last = loc[1]
+		}
+		sb.WriteString(html.EscapeString(snippet[last:]))
+		snippet = sb.String()
+	}
+
+	if truncated {
+		snippet += "..."
+	}
+	return snippet
+}
@@ -0,0 +1,546 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"os"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/finviz/backend/internal/middleware"
+	"github.com/finviz/backend/internal/models"
+)
+
+// Hosted Swagger UI that /api/docs redirects to, unless SWAGGER_UI_URL is set
+const defaultSwaggerUIURL = "https://petstore.swagger.io/"
+
+// apiRoute is a method and path registered on one of the router's muxes
+type apiRoute struct {
+	Method  string
+	Path    string
+	Secured bool // behind AuthMiddleware
+}
+
+// routeMux is a ServeMux that records every method-qualified pattern
+// registered on it, so the OpenAPI spec always matches the router. Prefix
+// mounts such as mux.Handle("/api/assets", ...) aren't endpoints and aren't
+// recorded.
+type routeMux struct {
+	*http.ServeMux
+	routes  *[]apiRoute
+	secured bool
+}
+
+func newRouteMux(routes *[]apiRoute, secured bool) *routeMux {
+	return &routeMux{ServeMux: http.NewServeMux(), routes: routes, secured: secured}
+}
+
+func (m *routeMux) HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request)) {
+	m.Handle(pattern, http.HandlerFunc(handler))
+}
+
+// Handle registers handler and, for routes, records the pattern both for the
+// spec and, per request, for the metrics path label
+func (m *routeMux) Handle(pattern string, handler http.Handler) {
+	method, path, ok := strings.Cut(pattern, " ")
+	if !ok {
+		m.ServeMux.Handle(pattern, handler)
+		return
+	}
+	*m.routes = append(*m.routes, apiRoute{Method: method, Path: path, Secured: m.secured})
+	m.ServeMux.Handle(pattern, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		middleware.SetRoute(r.Context(), path)
+		handler.ServeHTTP(w, r)
+	}))
+}
+
+// openAPIOperation documents the bodies of one route. Request and Response
+// hold zero values of the model types, which are turned into schemas.
+type openAPIOperation struct {
+	Summary  string
+	Status   int // success status; defaults to 200
+	Request  interface{}
+	Response interface{}
+	Example  interface{} // request body example
+}
+
+// openAPIOperations documents the most used routes, keyed by "METHOD path".
+// Advisor client context routes (/api/advisor/clients/{clientId}/...) share
+// the entry for the matching /api/... route. Routes not listed still appear
+// in the spec with generic responses.
+var openAPIOperations = map[string]openAPIOperation{
+	"POST /api/auth/register": {
+		Summary:  "Create an account and start a session",
+		Status:   http.StatusCreated,
+		Request:  models.RegisterRequest{},
+		Response: models.AuthResponse{},
+		Example:  models.RegisterRequest{Email: "jane@example.com", Password: "correct-horse-battery", Name: "Jane Doe"},
+	},
+	"POST /api/auth/login": {
+		Summary:  "Log in; returns a session, or an MFA challenge when MFA is enabled",
+		Request:  models.LoginRequest{},
+		Response: models.AuthResponse{},
+		Example:  models.LoginRequest{Email: "jane@example.com", Password: "correct-horse-battery"},
+	},
+	"POST /api/auth/refresh": {
+		Summary:  "Rotate a refresh token for a new access token",
+		Request:  models.RefreshTokenRequest{},
+		Response: models.AuthResponse{},
+	},
+	"GET /api/auth/me": {
+		Summary:  "Get the current user",
+		Response: models.User{},
+	},
+	"GET /api/asset-types": {
+		Summary:  "List asset types",
+		Response: []models.AssetType{},
+	},
+	"GET /api/assets": {
+		Summary:  "List assets (deleted=true lists those that can be restored)",
+		Response: []models.Asset{},
+	},
+	"POST /api/assets": {
+		Summary:  "Create an asset",
+		Status:   http.StatusCreated,
+		Request:  models.CreateAssetRequest{},
+		Response: map[string]int64{},
+		Example:  models.CreateAssetRequest{Name: "Vanguard Brokerage", TypeID: 1, CurrentValue: 125000},
+	},
+	"PUT /api/assets/{id}": {
+		Summary: "Update an asset",
+		Request: models.UpdateAssetRequest{},
+	},
+	"PATCH /api/assets/batch": {
+		Summary:  "Update the values of up to 100 assets at once",
+		Request:  []models.BatchAssetUpdate{},
+		Response: models.BatchUpdateResponse{},
+	},
+	"POST /api/assets/{id}/restore": {
+		Summary: "Restore an asset deleted in the last 30 days",
+	},
+	"GET /api/debts": {
+		Summary:  "List debts",
+		Response: []models.Debt{},
+	},
+	"POST /api/debts": {
+		Summary:  "Create a debt",
+		Status:   http.StatusCreated,
+		Request:  models.CreateDebtRequest{},
+		Response: map[string]int64{},
+		Example:  models.CreateDebtRequest{Name: "Car Loan", CurrentBalance: 18500, InterestRate: 6.9, MinimumPayment: 425},
+	},
+	"PATCH /api/debts/batch": {
+		Summary:  "Update the balances of up to 100 debts at once",
+		Request:  []models.BatchDebtUpdate{},
+		Response: models.BatchUpdateResponse{},
+	},
+	"PUT /api/debts/{id}": {
+		Summary: "Update a debt",
+		Request: models.UpdateDebtRequest{},
+	},
+	"POST /api/debts/optimize-payoff": {
+		Summary:  "Compare debt payoff strategies",
+		Request:  models.DebtPayoffRequest{},
+		Response: []models.DebtPayoffPlan{},
+	},
+	"POST /api/monte-carlo": {
+		Summary:  "Run a Monte Carlo retirement simulation",
+		Request:  models.MonteCarloRequest{},
+		Response: models.MonteCarloResponse{},
+		Example: models.MonteCarloRequest{Params: &models.SimulationParams{
+			TimeHorizonYears:    30,
+			CurrentAge:          40,
+			RetirementAge:       65,
+			MonthlyContribution: 1500,
+			ExpectedReturn:      0.07,
+			Volatility:          0.15,
+			InflationRate:       0.03,
+		}},
+	},
+	"POST /api/monte-carlo/scenarios": {
+		Summary:  "Compare simulation scenarios",
+		Request:  models.ScenarioComparisonRequest{},
+		Response: models.ScenarioComparisonResponse{},
+	},
+	"POST /api/simulate/purchase-impact": {
+		Summary:  "Model a major purchase against a saved simulation",
+		Request:  models.PurchaseImpactRequest{},
+		Response: models.PurchaseImpactResponse{},
+	},
+	"POST /api/simulate/roth-vs-traditional": {
+		Summary:  "Compare Roth and traditional contributions",
+		Request:  models.RothVsTraditionalRequest{},
+		Response: models.RothVsTraditionalResponse{},
+	},
+	"POST /api/simulate/sensitivity": {
+		Summary:  "Rank simulation parameters by their effect on the success rate",
+		Request:  models.SensitivityRequest{},
+		Response: models.SensitivityResponse{},
+	},
+	"POST /api/simulate/estimate-ss": {
+		Summary:  "Estimate a Social Security benefit",
+		Request:  models.SocialSecurityEstimateRequest{},
+		Response: models.SocialSecurityEstimateResponse{},
+	},
+	"POST /api/simulate/roth-optimizer": {
+		Summary:  "Plan bracket-filling Roth conversions",
+		Request:  models.RothConversionRequest{},
+		Response: models.RothConversionResponse{},
+		Example:  models.RothConversionRequest{TraditionalBalance: 800000, CurrentAge: 62, TaxableIncome: 60000, TargetTaxBracket: 0.22, FilingStatus: "married_filing_jointly"},
+	},
+	"POST /api/simulate/529": {
+		Summary:  "Project a 529 college savings plan",
+		Request:  models.College529Request{},
+		Response: models.College529Response{},
+	},
+	"GET /api/simulations": {
+		Summary:  "List saved simulations",
+		Response: []models.SimulationHistorySummary{},
+	},
+	"GET /api/simulations/{id}": {
+		Summary:  "Get a saved simulation",
+		Response: models.SimulationHistoryFull{},
+	},
+	"POST /api/import/csv": {
+		Summary:  "Import assets, debts or transactions from a CSV upload (multipart form)",
+		Response: ImportReport{},
+	},
+	"GET /api/plaid/accounts": {
+		Summary:  "List linked Plaid accounts",
+		Response: []models.PlaidAccount{},
+	},
+	"POST /api/plaid/sync": {
+		Summary:  "Sync Plaid balances into assets and debts",
+		Response: models.SyncResponse{},
+	},
+	"GET /api/plaid/holdings": {
+		Summary:  "List synced investment holdings",
+		Response: []models.InvestmentHolding{},
+	},
+	"GET /api/transactions": {
+		Summary:  "List transactions",
+		Response: models.TransactionPage{},
+	},
+	"POST /api/transactions": {
+		Summary:  "Record a manual transaction",
+		Status:   http.StatusCreated,
+		Request:  models.ManualTransactionRequest{},
+		Response: models.Transaction{},
+		Example:  models.ManualTransactionRequest{Date: "2024-03-15", Amount: 42.5, Name: "Farmers Market"},
+	},
+	"PUT /api/transactions/{id}": {
+		Summary:  "Update a manual or imported transaction",
+		Request:  models.ManualTransactionRequest{},
+		Response: models.Transaction{},
+	},
+	"GET /api/transactions/summary": {
+		Summary:  "Summarize transactions by category and month",
+		Response: models.TransactionSummary{},
+	},
+	"GET /api/transactions/anomalies": {
+		Summary:  "Detect unusual spending by category",
+		Response: []models.AnomalyAlert{},
+	},
+	"GET /api/transaction-rules": {
+		Summary:  "List categorization rules",
+		Response: []models.TransactionRule{},
+	},
+	"POST /api/transaction-rules": {
+		Summary:  "Create a categorization rule",
+		Status:   http.StatusCreated,
+		Request:  models.TransactionRuleRequest{},
+		Response: models.TransactionRule{},
+	},
+	"GET /api/goals": {
+		Summary:  "List goals",
+		Response: []models.ClientGoal{},
+	},
+	"POST /api/goals": {
+		Summary:  "Create a goal",
+		Status:   http.StatusCreated,
+		Request:  models.CreateGoalRequest{},
+		Response: models.ClientGoal{},
+		Example:  models.CreateGoalRequest{Title: "Emergency fund", Category: "savings", TargetDate: "2026-12-31"},
+	},
+	"GET /api/me/net-worth-history": {
+		Summary:  "Get daily net worth history",
+		Response: []models.NetWorthSnapshot{},
+	},
+	"GET /api/me/emergency-fund": {
+		Summary:  "Check emergency fund coverage",
+		Response: models.EmergencyFundStatus{},
+	},
+	"GET /api/me/rebalancing": {
+		Summary:  "Compare asset allocation with a target (target_allocation=stocks=60,bonds=30,cash=10)",
+		Response: []models.RebalancingAction{},
+	},
+	"GET /api/me/api-keys": {
+		Summary:  "List API keys",
+		Response: []models.APIKey{},
+	},
+	"POST /api/me/api-keys": {
+		Summary:  "Create an API key",
+		Status:   http.StatusCreated,
+		Request:  models.CreateAPIKeyRequest{},
+		Response: models.CreateAPIKeyResponse{},
+	},
+}
+
+var (
+	clientContextPrefix = "/api/advisor/clients/{clientId}/"
+	pathParamPattern    = regexp.MustCompile(`\{([A-Za-z0-9_]+)\.{0,3}\}`)
+)
+
+// openAPIHandler serves the spec for routes, built on first request once
+// NewRouter has registered everything
+// GET /api/openapi.json
+func openAPIHandler(routes *[]apiRoute) http.HandlerFunc {
+	var once sync.Once
+	var spec []byte
+	return func(w http.ResponseWriter, r *http.Request) {
+		once.Do(func() {
+			spec, _ = json.Marshal(buildOpenAPISpec(*routes))
+		})
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(spec)
+	}
+}
+
+// handleAPIDocs redirects to Swagger UI pointed at this server's spec
+// GET /api/docs
+func handleAPIDocs(w http.ResponseWriter, r *http.Request) {
+	scheme := "http"
+	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	specURL := scheme + "://" + r.Host + "/api/openapi.json"
+
+	uiURL := os.Getenv("SWAGGER_UI_URL")
+	if uiURL == "" {
+		uiURL = defaultSwaggerUIURL
+	}
+	http.Redirect(w, r, uiURL+"?url="+url.QueryEscape(specURL), http.StatusFound)
+}
+
+// buildOpenAPISpec builds an OpenAPI 3.0 document for the registered routes
+func buildOpenAPISpec(routes []apiRoute) map[string]interface{} {
+	schemas := map[string]interface{}{
+		"Error": map[string]interface{}{
+			"type":       "object",
+			"properties": map[string]interface{}{"error": map[string]interface{}{"type": "string"}},
+			"required":   []string{"error"},
+		},
+	}
+	errorResponse := func(description string) map[string]interface{} {
+		return map[string]interface{}{
+			"description": description,
+			"content": map[string]interface{}{
+				"application/json": map[string]interface{}{"schema": map[string]interface{}{"$ref": "#/components/schemas/Error"}},
+			},
+		}
+	}
+
+	paths := map[string]map[string]interface{}{}
+	for _, route := range routes {
+		key := route.Method + " " + route.Path
+		clientContext := strings.HasPrefix(route.Path, clientContextPrefix)
+		if clientContext {
+			key = route.Method + " /api/" + strings.TrimPrefix(route.Path, clientContextPrefix)
+		}
+		doc, documented := openAPIOperations[key]
+
+		op := map[string]interface{}{"tags": []string{openAPITag(route.Path)}}
+		summary := doc.Summary
+		if !documented {
+			summary = route.Method + " " + route.Path
+		}
+		if clientContext {
+			summary += " (as an advisor, for a client)"
+			op["description"] = "Acts on the client identified by clientId. Requires an advisor with access to that client."
+		}
+		op["summary"] = summary
+
+		var params []interface{}
+		for _, m := range pathParamPattern.FindAllStringSubmatch(route.Path, -1) {
+			params = append(params, map[string]interface{}{
+				"name":     m[1],
+				"in":       "path",
+				"required": true,
+				"schema":   map[string]interface{}{"type": "string"},
+			})
+		}
+		if params != nil {
+			op["parameters"] = params
+		}
+
+		if doc.Request != nil {
+			media := map[string]interface{}{"schema": openAPISchema(reflect.TypeOf(doc.Request), schemas)}
+			if doc.Example != nil {
+				media["example"] = doc.Example
+			}
+			op["requestBody"] = map[string]interface{}{
+				"required": true,
+				"content":  map[string]interface{}{"application/json": media},
+			}
+		}
+
+		status := doc.Status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		success := map[string]interface{}{"description": http.StatusText(status)}
+		if doc.Response != nil {
+			success["content"] = map[string]interface{}{
+				"application/json": map[string]interface{}{"schema": openAPISchema(reflect.TypeOf(doc.Response), schemas)},
+			}
+		}
+		responses := map[string]interface{}{
+			strconv.Itoa(status): success,
+			"default":            errorResponse("Error"),
+		}
+		if route.Secured {
+			responses["401"] = errorResponse("Missing or invalid credentials")
+			responses["403"] = errorResponse("Not permitted")
+		} else {
+			op["security"] = []interface{}{}
+		}
+		op["responses"] = responses
+
+		// OpenAPI paths don't include ServeMux's {name...} wildcard suffix
+		path := strings.ReplaceAll(route.Path, "...}", "}")
+		if paths[path] == nil {
+			paths[path] = map[string]interface{}{}
+		}
+		paths[path][strings.ToLower(route.Method)] = op
+	}
+
+	return map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":   "FinViz API",
+			"version": "1.0",
+		},
+		"security": []interface{}{map[string]interface{}{"bearerAuth": []string{}}},
+		"paths":    paths,
+		"components": map[string]interface{}{
+			"securitySchemes": map[string]interface{}{
+				"bearerAuth": map[string]interface{}{
+					"type":         "http",
+					"scheme":       "bearer",
+					"bearerFormat": "JWT",
+					"description":  "Access token from /api/auth/login, or an fv_ API key for the routes its scopes allow",
+				},
+			},
+			"schemas": schemas,
+		},
+	}
+}
+
+// openAPITag groups a route by its resource, e.g. "assets" for
+// /api/assets/{id} and /api/advisor/clients/{clientId}/assets
+func openAPITag(path string) string {
+	path = strings.TrimPrefix(path, clientContextPrefix)
+	path = strings.TrimPrefix(path, "/api/")
+	if strings.HasPrefix(path, "advisor/") {
+		return "advisor"
+	}
+	segment, _, _ := strings.Cut(strings.TrimPrefix(path, "/"), "/")
+	return segment
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// openAPISchema returns the JSON schema for t. Named structs are added to
+// schemas once and referenced by name.
+func openAPISchema(t reflect.Type, schemas map[string]interface{}) map[string]interface{} {
+	if t == timeType {
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		schema := openAPISchema(t.Elem(), schemas)
+		if _, isRef := schema["$ref"]; isRef {
+			return map[string]interface{}{"allOf": []interface{}{schema}, "nullable": true}
+		}
+		schema["nullable"] = true
+		return schema
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]interface{}{"type": "string", "format": "byte"}
+		}
+		return map[string]interface{}{"type": "array", "items": openAPISchema(t.Elem(), schemas)}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": openAPISchema(t.Elem(), schemas)}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return openAPIStructSchema(t, schemas)
+		}
+		ref := map[string]interface{}{"$ref": "#/components/schemas/" + t.Name()}
+		if _, done := schemas[t.Name()]; !done {
+			// Placeholder first, so self-referencing types terminate
+			schemas[t.Name()] = map[string]interface{}{}
+			schemas[t.Name()] = openAPIStructSchema(t, schemas)
+		}
+		return ref
+	}
+	return map[string]interface{}{}
+}
+
+// openAPIStructSchema lists a struct's JSON fields, flattening embedded
+// structs the way encoding/json does. Fields without omitempty are required.
+func openAPIStructSchema(t reflect.Type, schemas map[string]interface{}) map[string]interface{} {
+	properties := map[string]interface{}{}
+	var required []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" || (!field.IsExported() && !field.Anonymous) {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				inner := openAPIStructSchema(embedded, schemas)
+				for k, v := range inner["properties"].(map[string]interface{}) {
+					properties[k] = v
+				}
+				if req, ok := inner["required"].([]string); ok {
+					required = append(required, req...)
+				}
+				continue
+			}
+		}
+		if name == "" {
+			name = field.Name
+		}
+		properties[name] = openAPISchema(field.Type, schemas)
+		if !strings.Contains(opts, "omitempty") && field.Type.Kind() != reflect.Ptr {
+			required = append(required, name)
+		}
+	}
+
+	schema := map[string]interface{}{"type": "object", "properties": properties}
+	if required != nil {
+		schema["required"] = required
+	}
+	return schema
+}
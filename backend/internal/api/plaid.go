@@ -2,12 +2,15 @@ package api
 
 import (
 	"encoding/json"
-	"fmt"
+	"errors"
+	"io"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/finviz/backend/internal/db"
+	"github.com/finviz/backend/internal/logger"
 	"github.com/finviz/backend/internal/models"
 	"github.com/finviz/backend/internal/plaid"
 )
@@ -18,10 +21,11 @@ func init() {
 	plaidClient = plaid.NewClient()
 }
 
-// liabilityDetails holds interest rate and minimum payment for a liability account
+// liabilityDetails holds interest rate, minimum payment and kind for a liability account
 type liabilityDetails struct {
 	InterestRate   *float64
 	MinimumPayment *float64
+	Subtype        string
 }
 
 // handlePlaidStatus returns whether Plaid is configured
@@ -31,6 +35,126 @@ func handlePlaidStatus(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// maxWebhookBodySize limits Plaid webhook payloads
+const maxWebhookBodySize = 1 << 20
+
+// PlaidWebhook is the common envelope of Plaid webhook payloads
+type PlaidWebhook struct {
+	WebhookType string                 `json:"webhook_type"`
+	WebhookCode string                 `json:"webhook_code"`
+	ItemID      string                 `json:"item_id"`
+	Error       map[string]interface{} `json:"error,omitempty"`
+}
+
+// handlePlaidWebhook receives Plaid webhooks after verifying the Plaid-Verification JWT
+func handlePlaidWebhook(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxWebhookBodySize))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Failed to read request body")
+		return
+	}
+
+	if err := plaidClient.VerifyWebhook(body, r.Header.Get("Plaid-Verification")); err != nil {
+		logger.FromContext(r.Context()).Warnf("Rejected Plaid webhook: %v", err)
+		respondError(w, http.StatusUnauthorized, "Invalid webhook signature")
+		return
+	}
+
+	var webhook PlaidWebhook
+	if err := json.Unmarshal(body, &webhook); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	logger.FromContext(r.Context()).Infof("Plaid webhook received: %s/%s for item %s", webhook.WebhookType, webhook.WebhookCode, webhook.ItemID)
+
+	if _, err := db.DB.Exec(`
+		INSERT INTO plaid_webhook_events (item_id, webhook_type, webhook_code, payload)
+		VALUES (?, ?, ?, ?)
+	`, webhook.ItemID, webhook.WebhookType, webhook.WebhookCode, string(body)); err != nil {
+		logger.FromContext(r.Context()).Errorf("Error logging Plaid webhook: %v", err)
+	}
+
+	switch {
+	case webhook.WebhookType == "TRANSACTIONS" && webhook.WebhookCode == "SYNC_UPDATES_AVAILABLE":
+		// Plaid expects a fast 200; fetch the new transactions in the background
+		go syncPlaidItemTransactions(webhook.ItemID)
+	case webhook.WebhookType == "ITEM" && webhook.WebhookCode == "ERROR":
+		if code, ok := webhook.Error["error_code"].(string); ok {
+			setPlaidItemError(webhook.ItemID, code)
+		}
+	case webhook.WebhookType == "ITEM" && webhook.WebhookCode == "PENDING_EXPIRATION":
+		if _, err := db.DB.Exec(`UPDATE plaid_items SET status = 'needs_relink' WHERE item_id = ?`, webhook.ItemID); err != nil {
+			logger.FromContext(r.Context()).Errorf("Error flagging Plaid item %s for relink: %v", webhook.ItemID, err)
+		}
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{"status": "received"})
+}
+
+// plaidWebhookSyncDays is how far back a webhook-triggered sync fetches transactions
+const plaidWebhookSyncDays = 30
+
+// syncPlaidItemTransactions fetches recent transactions for one Plaid item and
+// upserts them, as handleSyncTransactions does for all of a user's items
+func syncPlaidItemTransactions(plaidItemID string) {
+	var itemID, userID int
+	var accessToken string
+	err := db.DB.QueryRow(`
+		SELECT id, user_id, access_token FROM plaid_items WHERE item_id = ? AND status = 'active'
+	`, plaidItemID).Scan(&itemID, &userID, &accessToken)
+	if err != nil {
+		logger.Default().Infof("Plaid webhook sync skipped for item %s: %v", plaidItemID, err)
+		return
+	}
+
+	endDate := time.Now().Format("2006-01-02")
+	startDate := time.Now().AddDate(0, 0, -plaidWebhookSyncDays).Format("2006-01-02")
+	txnResp, err := plaidClient.GetTransactions(accessToken, startDate, endDate)
+	if err != nil {
+		logger.Default().Errorf("Error getting transactions for item %d: %v", itemID, err)
+		recordPlaidItemError(itemID, err)
+		return
+	}
+
+	accountMap := make(map[string]string)
+	for _, acc := range txnResp.Accounts {
+		accountMap[acc.AccountID] = acc.Name
+	}
+
+	created, updated := upsertPlaidTransactions(userID, txnResp.Transactions, accountMap)
+	if _, err := recategorizeTransactions(userID, startDate, endDate); err != nil {
+		logger.Default().Errorf("Error applying categorization rules for user %d: %v", userID, err)
+	}
+	logger.Default().Infof("Plaid webhook sync for item %d: %d new, %d updated transactions", itemID, created, updated)
+}
+
+// recordPlaidItemError stores the error code of a failed Plaid call on the
+// item. Errors that need the user to re-authenticate also mark the item
+// needs_relink so syncs skip it until handleCompletePlaidUpdate.
+func recordPlaidItemError(itemID int, err error) {
+	var plaidErr *plaid.PlaidError
+	if !errors.As(err, &plaidErr) {
+		return
+	}
+
+	needsRelink := plaidErr.ErrorCode == plaid.ErrorCodeItemLoginRequired
+	if _, err := db.DB.Exec(`
+		UPDATE plaid_items SET error_code = ?, status = IF(?, 'needs_relink', status) WHERE id = ?
+	`, plaidErr.ErrorCode, needsRelink, itemID); err != nil {
+		logger.Default().Errorf("Error recording Plaid error for item %d: %v", itemID, err)
+	}
+}
+
+// setPlaidItemError is recordPlaidItemError for webhooks, which name items by Plaid item_id
+func setPlaidItemError(plaidItemID, errorCode string) {
+	var itemID int
+	if err := db.DB.QueryRow(`SELECT id FROM plaid_items WHERE item_id = ?`, plaidItemID).Scan(&itemID); err != nil {
+		return
+	}
+	recordPlaidItemError(itemID, &plaid.PlaidError{ErrorCode: errorCode})
+}
+
 // handleCreateLinkToken creates a Plaid Link token
 func handleCreateLinkToken(w http.ResponseWriter, r *http.Request) {
 	user := getUserFromContext(r)
@@ -53,12 +177,132 @@ func handleCreateLinkToken(w http.ResponseWriter, r *http.Request) {
 
 	expiration, _ := time.Parse(time.RFC3339, resp.Expiration)
 
+	// Track the OAuth state so Link can be resumed after a bank redirect
+	var oauthStateID string
+	if plaidClient.SupportsOAuth() {
+		oauthStateID, err = createOAuthSession(user.ID)
+		if err != nil {
+			respondError(w, http.StatusInternalServerError, "Failed to create OAuth session")
+			return
+		}
+	}
+
+	respondJSON(w, http.StatusOK, models.LinkTokenResponse{
+		LinkToken:    resp.LinkToken,
+		Expiration:   expiration,
+		OAuthStateID: oauthStateID,
+	})
+}
+
+// handleCreateUpdateLinkToken creates a Link token in update mode so the user
+// can re-authenticate an item that needs re-linking
+func handleCreateUpdateLinkToken(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+	if user == nil {
+		respondError(w, http.StatusUnauthorized, "Not authenticated")
+		return
+	}
+
+	if !plaidClient.IsConfigured() {
+		respondError(w, http.StatusServiceUnavailable, "Plaid is not configured")
+		return
+	}
+
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid ID")
+		return
+	}
+
+	var accessToken string
+	err = db.DB.QueryRow(`SELECT access_token FROM plaid_items WHERE id = ? AND user_id = ?`, id, user.ID).Scan(&accessToken)
+	if err != nil {
+		respondError(w, http.StatusNotFound, "Item not found")
+		return
+	}
+
+	resp, err := plaidClient.CreateUpdateLinkToken(strconv.Itoa(user.ID), accessToken)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	expiration, _ := time.Parse(time.RFC3339, resp.Expiration)
+
+	var oauthStateID string
+	if plaidClient.SupportsOAuth() {
+		oauthStateID, err = createOAuthSession(user.ID)
+		if err != nil {
+			respondError(w, http.StatusInternalServerError, "Failed to create OAuth session")
+			return
+		}
+	}
+
 	respondJSON(w, http.StatusOK, models.LinkTokenResponse{
-		LinkToken:  resp.LinkToken,
-		Expiration: expiration,
+		LinkToken:    resp.LinkToken,
+		Expiration:   expiration,
+		OAuthStateID: oauthStateID,
 	})
 }
 
+// handleCompletePlaidUpdate finishes an update-mode re-link: the new public
+// token is exchanged, and the item gets the fresh access token and is active again
+func handleCompletePlaidUpdate(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+	if user == nil {
+		respondError(w, http.StatusUnauthorized, "Not authenticated")
+		return
+	}
+
+	if !plaidClient.IsConfigured() {
+		respondError(w, http.StatusServiceUnavailable, "Plaid is not configured")
+		return
+	}
+
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid ID")
+		return
+	}
+
+	var req models.ExchangeTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if req.PublicToken == "" {
+		respondError(w, http.StatusBadRequest, "Public token is required")
+		return
+	}
+
+	var plaidItemID string
+	err = db.DB.QueryRow(`SELECT item_id FROM plaid_items WHERE id = ? AND user_id = ?`, id, user.ID).Scan(&plaidItemID)
+	if err != nil {
+		respondError(w, http.StatusNotFound, "Item not found")
+		return
+	}
+
+	exchangeResp, err := plaidClient.ExchangePublicToken(req.PublicToken)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to exchange token: "+err.Error())
+		return
+	}
+	if exchangeResp.ItemID != plaidItemID {
+		respondError(w, http.StatusBadRequest, "Public token belongs to a different item")
+		return
+	}
+
+	_, err = db.DB.Exec(`
+		UPDATE plaid_items SET access_token = ?, status = 'active', error_code = NULL WHERE id = ?
+	`, exchangeResp.AccessToken, id)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{"status": "active"})
+}
+
 // handleExchangeToken exchanges a public token for an access token
 func handleExchangeToken(w http.ResponseWriter, r *http.Request) {
 	user := getUserFromContext(r)
@@ -83,6 +327,22 @@ func handleExchangeToken(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Link resumed after an OAuth redirect - the pending session must still be valid
+	if req.OAuthStateID != "" {
+		switch err := verifyOAuthSession(req.OAuthStateID, user.ID); err {
+		case nil:
+		case errOAuthSessionNotFound:
+			respondError(w, http.StatusNotFound, "OAuth session not found")
+			return
+		case errOAuthSessionExpired:
+			respondError(w, http.StatusGone, "OAuth session has expired. Please restart the link flow")
+			return
+		default:
+			respondError(w, http.StatusInternalServerError, "Failed to verify OAuth session")
+			return
+		}
+	}
+
 	// Exchange public token for access token
 	exchangeResp, err := plaidClient.ExchangePublicToken(req.PublicToken)
 	if err != nil {
@@ -118,6 +378,10 @@ func handleExchangeToken(w http.ResponseWriter, r *http.Request) {
 
 	plaidItemID, _ := result.LastInsertId()
 
+	if req.OAuthStateID != "" {
+		deleteOAuthSession(req.OAuthStateID)
+	}
+
 	// Store accounts
 	var plaidAccounts []models.PlaidAccount
 	now := time.Now()
@@ -130,7 +394,7 @@ func handleExchangeToken(w http.ResponseWriter, r *http.Request) {
 			acc.Balances.Current, acc.Balances.Available, acc.Balances.Limit, acc.Balances.ISOCurrencyCode, now)
 		if err != nil {
 			// Log but continue
-			fmt.Printf("Error storing account %s: %v\n", acc.AccountID, err)
+			logger.FromContext(r.Context()).Errorf("Error storing account %s: %v", acc.AccountID, err)
 			continue
 		}
 
@@ -165,7 +429,7 @@ func handleGetPlaidItems(w http.ResponseWriter, r *http.Request) {
 	}
 
 	rows, err := db.DB.Query(`
-		SELECT id, user_id, item_id, institution_id, institution_name, status, created_at, updated_at
+		SELECT id, user_id, item_id, institution_id, institution_name, status, error_code, created_at, updated_at
 		FROM plaid_items
 		WHERE user_id = ?
 		ORDER BY created_at DESC
@@ -179,7 +443,7 @@ func handleGetPlaidItems(w http.ResponseWriter, r *http.Request) {
 	var items []models.PlaidItem
 	for rows.Next() {
 		var item models.PlaidItem
-		if err := rows.Scan(&item.ID, &item.UserID, &item.ItemID, &item.InstitutionID, &item.InstitutionName, &item.Status, &item.CreatedAt, &item.UpdatedAt); err != nil {
+		if err := rows.Scan(&item.ID, &item.UserID, &item.ItemID, &item.InstitutionID, &item.InstitutionName, &item.Status, &item.ErrorCode, &item.CreatedAt, &item.UpdatedAt); err != nil {
 			respondError(w, http.StatusInternalServerError, err.Error())
 			return
 		}
@@ -267,20 +531,32 @@ func handleSyncAccounts(w http.ResponseWriter, r *http.Request) {
 		// Get updated account balances
 		accountsResp, err := plaidClient.GetAccounts(accessToken)
 		if err != nil {
-			fmt.Printf("Error getting accounts for item %d: %v\n", itemID, err)
+			logger.FromContext(r.Context()).Errorf("Error getting accounts for item %d: %v", itemID, err)
+			recordPlaidItemError(itemID, err)
 			continue
 		}
 
-		// Fetch liability details (interest rates, minimum payments)
+		// Fetch liability details (interest rates, minimum payments) for items with debts
 		liabilityInfo := make(map[string]liabilityDetails)
-		liabResp, err := plaidClient.GetLiabilities(accessToken)
-		if err != nil {
-			// Liabilities may not be available for all account types - continue without
-			fmt.Printf("Note: Could not fetch liabilities for item %d: %v\n", itemID, err)
-		} else {
+		hasLiabilities := false
+		for _, acc := range accountsResp.Accounts {
+			if acc.Type == "credit" || acc.Type == "loan" {
+				hasLiabilities = true
+				break
+			}
+		}
+		var liabResp *plaid.LiabilitiesResponse
+		if hasLiabilities {
+			liabResp, err = plaidClient.GetLiabilities(accessToken)
+			if err != nil {
+				// Liabilities may not be available for all account types - continue without
+				logger.FromContext(r.Context()).Infof("Could not fetch liabilities for item %d: %v", itemID, err)
+			}
+		}
+		if liabResp != nil {
 			// Build lookup map from liability data
 			for _, credit := range liabResp.Liabilities.Credit {
-				details := liabilityDetails{MinimumPayment: credit.MinimumPayment}
+				details := liabilityDetails{MinimumPayment: credit.MinimumPayment, Subtype: models.DebtSubtypeCreditCard}
 				// Use purchase APR if available, otherwise first APR
 				for _, apr := range credit.APRs {
 					if apr.APRType == "purchase_apr" || details.InterestRate == nil {
@@ -294,12 +570,14 @@ func handleSyncAccounts(w http.ResponseWriter, r *http.Request) {
 				liabilityInfo[mortgage.AccountID] = liabilityDetails{
 					InterestRate:   &mortgage.InterestRatePercentage,
 					MinimumPayment: mortgage.NextMonthlyPayment,
+					Subtype:        models.DebtSubtypeMortgage,
 				}
 			}
 			for _, student := range liabResp.Liabilities.Student {
 				liabilityInfo[student.AccountID] = liabilityDetails{
 					InterestRate:   &student.InterestRatePercentage,
 					MinimumPayment: student.MinimumPaymentAmount,
+					Subtype:        models.DebtSubtypeStudentLoan,
 				}
 			}
 		}
@@ -314,7 +592,7 @@ func handleSyncAccounts(w http.ResponseWriter, r *http.Request) {
 				WHERE account_id = ? AND user_id = ?
 			`, acc.Balances.Current, acc.Balances.Available, acc.Balances.Limit, now, acc.AccountID, user.ID)
 			if err != nil {
-				fmt.Printf("Error updating account %s: %v\n", acc.AccountID, err)
+				logger.FromContext(r.Context()).Errorf("Error updating account %s: %v", acc.AccountID, err)
 			}
 
 			// Determine if asset or debt based on account type
@@ -330,37 +608,45 @@ func handleSyncAccounts(w http.ResponseWriter, r *http.Request) {
 					balance = *acc.Balances.Current
 				}
 
-				// Get interest rate and minimum payment from liabilities
+				// Get interest rate, minimum payment and kind from liabilities
 				var interestRate, minPayment *float64
+				var subtype *string
 				if details, ok := liabilityInfo[acc.AccountID]; ok {
 					interestRate = details.InterestRate
 					minPayment = details.MinimumPayment
+					subtype = &details.Subtype
 				}
 
 				if err == nil {
-					// Update existing debt with interest rate and minimum payment
+					// Update existing debt, keeping stored details Plaid didn't report
 					_, err = db.DB.Exec(`
 						UPDATE debts
-						SET current_balance = ?, interest_rate = ?, minimum_payment = ?, updated_at = NOW()
+						SET current_balance = ?, interest_rate = COALESCE(?, interest_rate),
+						    minimum_payment = COALESCE(?, minimum_payment), debt_subtype = COALESCE(?, debt_subtype), updated_at = NOW()
 						WHERE id = ?
-					`, balance, interestRate, minPayment, existingID)
+					`, balance, interestRate, minPayment, subtype, existingID)
 					if err == nil {
 						syncResult.UpdatedDebts++
 					}
 				} else {
 					// Create new debt with interest rate and minimum payment
 					_, err = db.DB.Exec(`
-						INSERT INTO debts (user_id, name, current_balance, interest_rate, minimum_payment, plaid_account_id)
-						VALUES (?, ?, ?, ?, ?, ?)
-					`, user.ID, acc.Name, balance, interestRate, minPayment, acc.AccountID)
+						INSERT INTO debts (user_id, name, current_balance, interest_rate, minimum_payment, plaid_account_id, debt_subtype)
+						VALUES (?, ?, ?, ?, ?, ?, ?)
+					`, user.ID, acc.Name, balance, interestRate, minPayment, acc.AccountID, subtype)
 					if err == nil {
 						syncResult.NewDebts++
 					}
 				}
 			} else {
+				// Accounts itemized by handleSyncInvestmentHoldings are tracked per security
+				if hasHoldingAssets(user.ID, acc.AccountID) {
+					continue
+				}
+
 				// Check if asset exists with this plaid_account_id
 				var existingID int
-				err := db.DB.QueryRow(`SELECT id FROM assets WHERE plaid_account_id = ? AND user_id = ?`, acc.AccountID, user.ID).Scan(&existingID)
+				err := db.DB.QueryRow(`SELECT id FROM assets WHERE plaid_account_id = ? AND user_id = ? AND plaid_security_id IS NULL`, acc.AccountID, user.ID).Scan(&existingID)
 
 				value := float64(0)
 				if acc.Balances.Current != nil {
@@ -393,19 +679,235 @@ func handleSyncAccounts(w http.ResponseWriter, r *http.Request) {
 	respondJSON(w, http.StatusOK, syncResult)
 }
 
-// getAssetTypeIDForPlaidType maps Plaid account types to our asset types
-func getAssetTypeIDForPlaidType(accType, subtype string) int {
-	// Default to Cash/Savings (ID 5)
-	switch accType {
-	case "investment":
-		return 1 // Stocks (US)
-	case "brokerage":
-		return 1 // Stocks (US)
-	case "depository":
-		return 5 // Cash/Savings
-	default:
-		return 5 // Cash/Savings
+// handleSyncInvestmentHoldings pulls security-level holdings for investment
+// accounts and keeps one asset per holding, keyed by account and security.
+// A holding with a ticker adopts an existing unlinked asset named after the
+// ticker instead of creating a duplicate. Once an account is itemized its
+// account-level balance asset is removed so the value isn't counted twice.
+func handleSyncInvestmentHoldings(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+	if user == nil {
+		respondError(w, http.StatusUnauthorized, "Not authenticated")
+		return
+	}
+
+	if !plaidClient.IsConfigured() {
+		respondError(w, http.StatusServiceUnavailable, "Plaid is not configured")
+		return
+	}
+
+	rows, err := db.DB.Query(`SELECT id, access_token FROM plaid_items WHERE user_id = ? AND status = 'active'`, user.ID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	defer rows.Close()
+
+	var syncResult models.SyncResponse
+
+	for rows.Next() {
+		var itemID int
+		var accessToken string
+		if err := rows.Scan(&itemID, &accessToken); err != nil {
+			continue
+		}
+
+		holdingsResp, err := plaidClient.GetInvestmentHoldings(accessToken)
+		if err != nil {
+			// Items without investment accounts don't support the product - continue without
+			logger.FromContext(r.Context()).Infof("Could not fetch holdings for item %d: %v", itemID, err)
+			continue
+		}
+
+		accounts := make(map[string]plaid.Account)
+		for _, acc := range holdingsResp.Accounts {
+			accounts[acc.AccountID] = acc
+		}
+
+		// Security IDs synced per account
+		itemized := make(map[string][]interface{})
+		for _, holding := range holdingsResp.Holdings {
+			acc := accounts[holding.AccountID]
+			sec := holdingsResp.Securities[holding.SecurityID]
+			typeID := getAssetTypeIDForSecurity(sec.Type, acc.Type, acc.Subtype)
+
+			existingID, found := findHoldingAsset(user.ID, holding.AccountID, holding.SecurityID, sec.TickerSymbol)
+			if found {
+				_, err = db.DB.Exec(`
+					UPDATE assets
+					SET current_value = ?, plaid_account_id = ?, plaid_security_id = ?, updated_at = NOW()
+					WHERE id = ?
+				`, holding.InstitutionValue, holding.AccountID, holding.SecurityID, existingID)
+				if err == nil {
+					syncResult.UpdatedAssets++
+				}
+			} else {
+				_, err = db.DB.Exec(`
+					INSERT INTO assets (user_id, name, type_id, current_value, plaid_account_id, plaid_security_id)
+					VALUES (?, ?, ?, ?, ?, ?)
+				`, user.ID, holdingAssetName(sec), typeID, holding.InstitutionValue, holding.AccountID, holding.SecurityID)
+				if err == nil {
+					syncResult.NewAssets++
+				}
+			}
+			if err != nil {
+				logger.FromContext(r.Context()).Errorf("Error saving holding %s in account %s: %v", holding.SecurityID, holding.AccountID, err)
+				continue
+			}
+			if err := upsertInvestmentHolding(user.ID, holding, sec); err != nil {
+				logger.FromContext(r.Context()).Errorf("Error recording holding %s in account %s: %v", holding.SecurityID, holding.AccountID, err)
+			}
+			if itemized[holding.AccountID] == nil {
+				itemized[holding.AccountID] = []interface{}{}
+			}
+			itemized[holding.AccountID] = append(itemized[holding.AccountID], holding.SecurityID)
+		}
+
+		for accountID, securityIDs := range itemized {
+			syncResult.SyncedAccounts++
+			if _, err := db.DB.Exec(`
+				DELETE FROM assets WHERE user_id = ? AND plaid_account_id = ? AND plaid_security_id IS NULL
+			`, user.ID, accountID); err != nil {
+				logger.FromContext(r.Context()).Errorf("Error removing balance asset for account %s: %v", accountID, err)
+			}
+			refreshHoldingAccount(user.ID, accountID, securityIDs)
+		}
+	}
+
+	respondJSON(w, http.StatusOK, syncResult)
+}
+
+// upsertInvestmentHolding records a holding's latest position
+func upsertInvestmentHolding(userID int, holding plaid.Holding, sec plaid.Security) error {
+	name := sec.Name
+	if name == "" {
+		name = "Unknown Security"
+	}
+	var ticker *string
+	if sec.TickerSymbol != nil && *sec.TickerSymbol != "" {
+		symbol := strings.ToUpper(*sec.TickerSymbol)
+		ticker = &symbol
+	}
+	_, err := db.DB.Exec(`
+		INSERT INTO plaid_investment_holdings
+			(user_id, account_id, security_id, ticker_symbol, name, quantity, institution_value, cost_basis)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE ticker_symbol = VALUES(ticker_symbol), name = VALUES(name),
+			quantity = VALUES(quantity), institution_value = VALUES(institution_value), cost_basis = VALUES(cost_basis)
+	`, userID, holding.AccountID, holding.SecurityID, ticker, name, holding.Quantity, holding.InstitutionValue, holding.CostBasis)
+	return err
+}
+
+// refreshHoldingAccount drops holdings an account no longer reports (sold
+// positions) and sets the account's balance to the sum of what remains
+func refreshHoldingAccount(userID int, accountID string, securityIDs []interface{}) {
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(securityIDs)), ", ")
+	args := append([]interface{}{userID, accountID}, securityIDs...)
+	if _, err := db.DB.Exec(`
+		DELETE FROM plaid_investment_holdings
+		WHERE user_id = ? AND account_id = ? AND security_id NOT IN (`+placeholders+`)
+	`, args...); err != nil {
+		logger.Default().Errorf("Error removing sold holdings for account %s: %v", accountID, err)
+	}
+
+	if _, err := db.DB.Exec(`
+		UPDATE plaid_accounts
+		SET current_balance = (
+			SELECT COALESCE(SUM(institution_value), 0) FROM plaid_investment_holdings WHERE user_id = ? AND account_id = ?
+		), last_synced_at = NOW()
+		WHERE user_id = ? AND account_id = ?
+	`, userID, accountID, userID, accountID); err != nil {
+		logger.Default().Errorf("Error updating balance for account %s: %v", accountID, err)
+	}
+}
+
+// handleGetInvestmentHoldings lists the user's synced investment holdings
+// with unrealized gain or loss
+// GET /api/plaid/holdings
+func handleGetInvestmentHoldings(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+	if user == nil {
+		respondError(w, http.StatusUnauthorized, "Not authenticated")
+		return
+	}
+
+	rows, err := db.DB.Query(`
+		SELECT h.id, h.account_id, a.name, h.security_id, h.ticker_symbol, h.name, h.quantity,
+		       h.institution_value, h.cost_basis, h.updated_at
+		FROM plaid_investment_holdings h
+		LEFT JOIN plaid_accounts a ON a.account_id = h.account_id AND a.user_id = h.user_id
+		WHERE h.user_id = ?
+		ORDER BY h.institution_value DESC
+	`, user.ID)
+	if err != nil {
+		logger.FromContext(r.Context()).Errorf("Error fetching holdings for user %d: %v", user.ID, err)
+		respondError(w, http.StatusInternalServerError, "Failed to fetch holdings")
+		return
+	}
+	defer rows.Close()
+
+	holdings := []models.InvestmentHolding{}
+	for rows.Next() {
+		var h models.InvestmentHolding
+		if err := rows.Scan(&h.ID, &h.AccountID, &h.AccountName, &h.SecurityID, &h.TickerSymbol, &h.Name, &h.Quantity,
+			&h.InstitutionValue, &h.CostBasis, &h.UpdatedAt); err != nil {
+			continue
+		}
+		if h.CostBasis != nil {
+			gainLoss := roundCents(h.InstitutionValue - *h.CostBasis)
+			h.GainLoss = &gainLoss
+		}
+		holdings = append(holdings, h)
+	}
+
+	respondJSON(w, http.StatusOK, holdings)
+}
+
+// findHoldingAsset returns the asset tracking a holding: the one already
+// linked to the account and security, or else an unlinked asset whose name
+// is the security's ticker (e.g. "VTI" or "VTI - Vanguard Total Stock Market ETF")
+func findHoldingAsset(userID int, accountID, securityID string, ticker *string) (int, bool) {
+	var id int
+	err := db.DB.QueryRow(`
+		SELECT id FROM assets WHERE user_id = ? AND plaid_account_id = ? AND plaid_security_id = ?
+	`, userID, accountID, securityID).Scan(&id)
+	if err == nil {
+		return id, true
+	}
+
+	if ticker == nil || *ticker == "" {
+		return 0, false
 	}
+	symbol := strings.ToUpper(*ticker)
+	err = db.DB.QueryRow(`
+		SELECT id FROM assets
+		WHERE user_id = ? AND plaid_security_id IS NULL AND plaid_account_id IS NULL AND deleted_at IS NULL
+		  AND (UPPER(name) = ? OR UPPER(name) LIKE CONCAT(?, ' - %'))
+		ORDER BY id
+		LIMIT 1
+	`, userID, symbol, symbol).Scan(&id)
+	return id, err == nil
+}
+
+// holdingAssetName names a new holding asset "TICKER - Security Name"
+func holdingAssetName(sec plaid.Security) string {
+	name := sec.Name
+	if name == "" {
+		name = "Unknown Security"
+	}
+	if sec.TickerSymbol != nil && *sec.TickerSymbol != "" {
+		return strings.ToUpper(*sec.TickerSymbol) + " - " + name
+	}
+	return name
+}
+
+// hasHoldingAssets reports whether an account's value is tracked per holding
+func hasHoldingAssets(userID int, accountID string) bool {
+	var count int
+	db.DB.QueryRow(`
+		SELECT COUNT(*) FROM assets WHERE user_id = ? AND plaid_account_id = ? AND plaid_security_id IS NOT NULL
+	`, userID, accountID).Scan(&count)
+	return count > 0
 }
 
 // handleDeletePlaidItem removes a Plaid item and optionally associated data
@@ -448,6 +950,11 @@ func handleDeletePlaidItem(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	db.DB.Exec(`
+		DELETE FROM plaid_investment_holdings
+		WHERE user_id = ? AND account_id IN (SELECT account_id FROM plaid_accounts WHERE plaid_item_id = ? AND user_id = ?)
+	`, user.ID, id, user.ID)
+
 	// Delete will cascade to plaid_accounts
 	result, err := db.DB.Exec(`DELETE FROM plaid_items WHERE id = ? AND user_id = ?`, id, user.ID)
 	if err != nil {
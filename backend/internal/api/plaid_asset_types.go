@@ -0,0 +1,199 @@
+package api
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/finviz/backend/internal/db"
+	"github.com/finviz/backend/internal/logger"
+)
+
+// Seeded asset type names that Plaid accounts map onto
+const (
+	assetTypeStocksUS = "Stocks (US)"
+	assetTypeBonds    = "Bonds"
+	assetTypeCash     = "Cash/Savings"
+	assetTypeCrypto   = "Crypto"
+)
+
+// plaidSubtypeAssetTypes maps Plaid account subtypes to asset type names.
+// Retirement and brokerage accounts are treated as US equities until their
+// holdings are synced; fixed-income products map to bonds.
+var plaidSubtypeAssetTypes = map[string]string{
+	// Depository
+	"checking":        assetTypeCash,
+	"savings":         assetTypeCash,
+	"money market":    assetTypeCash,
+	"cd":              assetTypeCash,
+	"cash management": assetTypeCash,
+	"paypal":          assetTypeCash,
+	"prepaid":         assetTypeCash,
+	"ebt":             assetTypeCash,
+	"hsa":             assetTypeCash, // investment HSAs are handled in getAssetTypeIDForPlaidType
+
+	// Investment - US retirement
+	"401a":                             assetTypeStocksUS,
+	"401k":                             assetTypeStocksUS,
+	"403b":                             assetTypeStocksUS,
+	"457b":                             assetTypeStocksUS,
+	"ira":                              assetTypeStocksUS,
+	"roth":                             assetTypeStocksUS,
+	"roth 401k":                        assetTypeStocksUS,
+	"sep ira":                          assetTypeStocksUS,
+	"simple ira":                       assetTypeStocksUS,
+	"sarsep":                           assetTypeStocksUS,
+	"keogh":                            assetTypeStocksUS,
+	"pension":                          assetTypeStocksUS,
+	"profit sharing plan":              assetTypeStocksUS,
+	"retirement":                       assetTypeStocksUS,
+	"stock plan":                       assetTypeStocksUS,
+	"health reimbursement arrangement": assetTypeCash,
+
+	// Investment - taxable and education
+	"brokerage":                     assetTypeStocksUS,
+	"non-taxable brokerage account": assetTypeStocksUS,
+	"mutual fund":                   assetTypeStocksUS,
+	"trust":                         assetTypeStocksUS,
+	"ugma":                          assetTypeStocksUS,
+	"utma":                          assetTypeStocksUS,
+	"529":                           assetTypeStocksUS,
+	"education savings account":     assetTypeStocksUS,
+	"variable annuity":              assetTypeStocksUS,
+	"other annuity":                 assetTypeBonds,
+	"fixed annuity":                 assetTypeBonds,
+	"gic":                           assetTypeBonds,
+	"life insurance":                assetTypeBonds,
+	"other insurance":               assetTypeBonds,
+
+	// Investment - Canada / UK
+	"rrsp":     assetTypeStocksUS,
+	"rrif":     assetTypeStocksUS,
+	"tfsa":     assetTypeStocksUS,
+	"resp":     assetTypeStocksUS,
+	"rdsp":     assetTypeStocksUS,
+	"lira":     assetTypeStocksUS,
+	"lif":      assetTypeStocksUS,
+	"lrif":     assetTypeStocksUS,
+	"lrsp":     assetTypeStocksUS,
+	"prif":     assetTypeStocksUS,
+	"rlif":     assetTypeStocksUS,
+	"qshr":     assetTypeStocksUS,
+	"isa":      assetTypeStocksUS,
+	"cash isa": assetTypeCash,
+	"sipp":     assetTypeStocksUS,
+
+	// Crypto
+	"crypto exchange":      assetTypeCrypto,
+	"non-custodial wallet": assetTypeCrypto,
+}
+
+// plaidTypeAssetTypes is the type-level fallback for unknown subtypes
+var plaidTypeAssetTypes = map[string]string{
+	"investment": assetTypeStocksUS,
+	"brokerage":  assetTypeStocksUS,
+	"depository": assetTypeCash,
+}
+
+// plaidSecurityAssetTypes maps Plaid security types of synced holdings; other
+// types (equity, etf, mutual fund, ...) follow the account's mapping
+var plaidSecurityAssetTypes = map[string]string{
+	"fixed income":   assetTypeBonds,
+	"cash":           assetTypeCash,
+	"cryptocurrency": assetTypeCrypto,
+}
+
+var (
+	assetTypeIDsMu sync.RWMutex
+	assetTypeIDs   map[string]int // asset type name -> ID for this installation
+)
+
+// LoadAssetTypeIDs reads asset type IDs by name so Plaid mappings don't depend
+// on IDs that differ across installations. Called at startup after migrations.
+func LoadAssetTypeIDs() error {
+	rows, err := db.DB.Query("SELECT id, name FROM asset_types")
+	if err != nil {
+		return fmt.Errorf("failed to load asset types: %w", err)
+	}
+	defer rows.Close()
+
+	ids := make(map[string]int)
+	for rows.Next() {
+		var id int
+		var name string
+		if err := rows.Scan(&id, &name); err != nil {
+			return err
+		}
+		ids[name] = id
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, name := range []string{assetTypeStocksUS, assetTypeBonds, assetTypeCash, assetTypeCrypto} {
+		if _, ok := ids[name]; !ok {
+			logger.Default().Warnf("asset type %q not found; Plaid accounts of that kind will use the type-level mapping", name)
+		}
+	}
+
+	assetTypeIDsMu.Lock()
+	assetTypeIDs = ids
+	assetTypeIDsMu.Unlock()
+	return nil
+}
+
+// assetTypeIDByName returns the ID of a named asset type, if loaded
+func assetTypeIDByName(name string) (int, bool) {
+	assetTypeIDsMu.RLock()
+	defer assetTypeIDsMu.RUnlock()
+	id, ok := assetTypeIDs[name]
+	return id, ok
+}
+
+// getAssetTypeIDForPlaidType maps a Plaid account's subtype (or type, for
+// unknown subtypes) to one of our asset types
+func getAssetTypeIDForPlaidType(accType, subtype string) int {
+	subtype = strings.ToLower(strings.TrimSpace(subtype))
+
+	// HSA is both a depository and an investment subtype
+	if subtype == "hsa" && accType == "investment" {
+		if id, ok := assetTypeIDByName(assetTypeStocksUS); ok {
+			return id
+		}
+	}
+
+	if subtype != "" {
+		if name, ok := plaidSubtypeAssetTypes[subtype]; ok {
+			if id, ok := assetTypeIDByName(name); ok {
+				return id
+			}
+		} else {
+			logger.Default().Warnf("unmapped Plaid subtype %q (type %q); using type-level mapping", subtype, accType)
+		}
+	}
+
+	name, ok := plaidTypeAssetTypes[accType]
+	if !ok {
+		name = assetTypeCash
+	}
+	if id, ok := assetTypeIDByName(name); ok {
+		return id
+	}
+
+	// Asset types not loaded - fall back to the default seed IDs
+	if name == assetTypeStocksUS {
+		return 1 // Stocks (US)
+	}
+	return 5 // Cash/Savings
+}
+
+// getAssetTypeIDForSecurity maps a holding's security type to an asset type,
+// falling back to the mapping of the account that holds it
+func getAssetTypeIDForSecurity(securityType, accType, subtype string) int {
+	if name, ok := plaidSecurityAssetTypes[strings.ToLower(securityType)]; ok {
+		if id, ok := assetTypeIDByName(name); ok {
+			return id
+		}
+	}
+	return getAssetTypeIDForPlaidType(accType, subtype)
+}
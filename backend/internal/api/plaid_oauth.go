@@ -0,0 +1,131 @@
+package api
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/finviz/backend/internal/db"
+	"github.com/finviz/backend/internal/logger"
+	"github.com/finviz/backend/internal/models"
+)
+
+// OAuth sessions must be completed within this window after Link starts
+const plaidOAuthSessionTTL = 10 * time.Minute
+
+var (
+	errOAuthSessionNotFound = errors.New("oauth session not found")
+	errOAuthSessionExpired  = errors.New("oauth session expired")
+)
+
+// handleGetOAuthStatus reports whether a pending OAuth session is still valid
+func handleGetOAuthStatus(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+	if user == nil {
+		respondError(w, http.StatusUnauthorized, "Not authenticated")
+		return
+	}
+
+	session, err := fetchOAuthSession(r.PathValue("oauthStateId"), user.ID)
+	if err == errOAuthSessionNotFound {
+		respondError(w, http.StatusNotFound, "OAuth session not found")
+		return
+	}
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch OAuth session")
+		return
+	}
+
+	expired := time.Now().After(session.ExpiresAt)
+	status := http.StatusOK
+	if expired {
+		status = http.StatusGone
+	}
+
+	respondJSON(w, status, models.OAuthStatusResponse{
+		OAuthStateID: session.OAuthStateID,
+		Valid:        !expired,
+		Expired:      expired,
+		ExpiresAt:    session.ExpiresAt,
+	})
+}
+
+// createOAuthSession records a pending OAuth state for the user
+func createOAuthSession(userID int) (string, error) {
+	stateID, err := newUUID()
+	if err != nil {
+		return "", err
+	}
+
+	_, err = db.DB.Exec(`
+		INSERT INTO plaid_oauth_sessions (oauth_state_id, user_id, expires_at)
+		VALUES (?, ?, ?)
+	`, stateID, userID, time.Now().Add(plaidOAuthSessionTTL))
+	if err != nil {
+		return "", err
+	}
+
+	return stateID, nil
+}
+
+// fetchOAuthSession loads an OAuth session owned by the user
+func fetchOAuthSession(stateID string, userID int) (*models.PlaidOAuthSession, error) {
+	var session models.PlaidOAuthSession
+	err := db.DB.QueryRow(`
+		SELECT oauth_state_id, user_id, created_at, expires_at
+		FROM plaid_oauth_sessions
+		WHERE oauth_state_id = ? AND user_id = ?
+	`, stateID, userID).Scan(&session.OAuthStateID, &session.UserID, &session.CreatedAt, &session.ExpiresAt)
+	if err == sql.ErrNoRows {
+		return nil, errOAuthSessionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// verifyOAuthSession checks that an OAuth session exists for the user and has not expired
+func verifyOAuthSession(stateID string, userID int) error {
+	session, err := fetchOAuthSession(stateID, userID)
+	if err != nil {
+		return err
+	}
+	if time.Now().After(session.ExpiresAt) {
+		return errOAuthSessionExpired
+	}
+	return nil
+}
+
+// deleteOAuthSession removes a completed OAuth session
+func deleteOAuthSession(stateID string) {
+	if _, err := db.DB.Exec(`DELETE FROM plaid_oauth_sessions WHERE oauth_state_id = ?`, stateID); err != nil {
+		logger.Default().Errorf("Error deleting OAuth session %s: %v", stateID, err)
+	}
+}
+
+// cleanupOAuthSessions deletes OAuth sessions older than an hour
+func cleanupOAuthSessions() {
+	result, err := db.DB.Exec(`DELETE FROM plaid_oauth_sessions WHERE created_at < ?`, time.Now().Add(-time.Hour))
+	if err != nil {
+		logger.Default().Errorf("Error cleaning up OAuth sessions: %v", err)
+		return
+	}
+	if n, _ := result.RowsAffected(); n > 0 {
+		logger.Default().Infof("Cleaned up %d expired OAuth sessions", n)
+	}
+}
+
+// newUUID generates a random (version 4) UUID string
+func newUUID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
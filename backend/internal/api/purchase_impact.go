@@ -0,0 +1,162 @@
+package api
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+
+	"github.com/finviz/backend/internal/db"
+	"github.com/finviz/backend/internal/models"
+	"github.com/finviz/backend/internal/simulation"
+)
+
+// handlePurchaseImpact re-runs a saved simulation with a major purchase and compares outcomes
+func handlePurchaseImpact(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+	if user == nil {
+		respondError(w, http.StatusUnauthorized, "Not authenticated")
+		return
+	}
+
+	if isActingAsAdvisor(r) && !canRunSimulations(r) {
+		respondError(w, http.StatusForbidden, "No permission to run simulations for this client")
+		return
+	}
+
+	targetUserID := getEffectiveUserID(r)
+
+	var req models.PurchaseImpactRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if req.PurchaseAmount <= 0 {
+		respondError(w, http.StatusBadRequest, "Purchase amount must be greater than zero")
+		return
+	}
+	if req.FinanceAmount < 0 || req.FinanceAmount > req.PurchaseAmount {
+		respondError(w, http.StatusBadRequest, "Finance amount must be between zero and the purchase amount")
+		return
+	}
+	if req.FinanceAmount > 0 && req.LoanTermYears <= 0 {
+		respondError(w, http.StatusBadRequest, "Loan term is required when financing")
+		return
+	}
+	if req.LoanRate < 0 {
+		respondError(w, http.StatusBadRequest, "Loan rate cannot be negative")
+		return
+	}
+	if req.PurchaseYear < 1 {
+		req.PurchaseYear = 1
+	}
+
+	// Load the base simulation's params
+	var paramsJSON string
+	err := db.DB.QueryRow(`
+		SELECT params FROM simulation_history WHERE id = ? AND user_id = ?
+	`, req.BaseSimulationID, targetUserID).Scan(&paramsJSON)
+	if err != nil {
+		respondError(w, http.StatusNotFound, "Simulation not found")
+		return
+	}
+
+	var baseParams models.SimulationParams
+	if err := json.Unmarshal([]byte(paramsJSON), &baseParams); err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to parse simulation params")
+		return
+	}
+	baseParams.ApplyDefaults()
+
+	if req.PurchaseYear > baseParams.TimeHorizonYears {
+		respondError(w, http.StatusBadRequest, "Purchase year must be within the simulation time horizon")
+		return
+	}
+
+	assets, err := fetchAssetsWithTypesForUser(targetUserID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	debts, err := fetchDebtsForUser(targetUserID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if baseParams.ExcludeCreditCardDebt {
+		debts = filterOutCreditCardDebt(debts)
+	}
+
+	// Build the purchase scenario: down payment event plus the loan as a negative income stream
+	downPayment := req.PurchaseAmount - req.FinanceAmount
+	monthlyPayment := calculateLoanPayment(req.FinanceAmount, req.LoanRate, req.LoanTermYears)
+
+	purchaseParams := baseParams
+	purchaseParams.OneTimeEvents = append([]models.Event{}, baseParams.OneTimeEvents...)
+	purchaseParams.IncomeStreams = append([]models.IncomeStream{}, baseParams.IncomeStreams...)
+
+	if downPayment > 0 {
+		purchaseParams.OneTimeEvents = append(purchaseParams.OneTimeEvents, models.Event{
+			Year:        req.PurchaseYear,
+			Amount:      -downPayment,
+			Description: "Purchase down payment",
+		})
+	}
+	if monthlyPayment > 0 {
+		purchaseParams.IncomeStreams = append(purchaseParams.IncomeStreams, models.IncomeStream{
+			Type:          "loan_payment",
+			MonthlyAmount: -monthlyPayment,
+			StartYear:     req.PurchaseYear,
+			EndYear:       req.PurchaseYear + req.LoanTermYears - 1,
+		})
+	}
+
+	// Re-run both scenarios against current balances so they're directly comparable
+	without := simulation.RunMonteCarloWithParams(assets, debts, &baseParams)
+	with := simulation.RunMonteCarloWithParams(assets, debts, &purchaseParams)
+
+	respondJSON(w, http.StatusOK, models.PurchaseImpactResponse{
+		WithoutPurchaseP50:      without.Summary.FinalP50,
+		WithPurchaseP50:         with.Summary.FinalP50,
+		WithoutSuccessRate:      without.Summary.SuccessRate,
+		WithPurchaseSuccessRate: with.Summary.SuccessRate,
+		DownPayment:             roundCents(downPayment),
+		MonthlyPayment:          roundCents(monthlyPayment),
+		BreakEvenRecoveryYear:   findRecoveryYear(without.Projections, with.Projections, req.PurchaseYear),
+	})
+}
+
+// calculateLoanPayment returns the monthly payment for an amortizing loan
+func calculateLoanPayment(principal, annualRate float64, termYears int) float64 {
+	if principal <= 0 || termYears <= 0 {
+		return 0
+	}
+	n := float64(termYears * 12)
+	monthlyRate := annualRate / 12
+	if monthlyRate == 0 {
+		return principal / n
+	}
+	return principal * monthlyRate / (1 - math.Pow(1+monthlyRate, -n))
+}
+
+// findRecoveryYear returns the first year the with-purchase median regains the
+// median net worth the plan would have had in the year before the purchase
+func findRecoveryYear(without, with []models.YearProjection, purchaseYear int) *int {
+	baseline := 0.0
+	if purchaseYear >= 2 && purchaseYear-2 < len(without) {
+		baseline = without[purchaseYear-2].P50
+	} else if len(without) > 0 {
+		// Purchase in year 1 - compare against the first projected year
+		baseline = without[0].P50
+	}
+
+	for i := purchaseYear - 1; i < len(with); i++ {
+		if i >= 0 && with[i].P50 >= baseline {
+			year := with[i].Year
+			return &year
+		}
+	}
+	return nil
+}
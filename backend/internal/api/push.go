@@ -0,0 +1,81 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/finviz/backend/internal/db"
+	"github.com/finviz/backend/internal/logger"
+	"github.com/finviz/backend/internal/models"
+	"github.com/finviz/backend/internal/notifications"
+)
+
+// handleRegisterPushToken saves a device's push token for the current user.
+// A token moves to whoever registered it last, since devices can change hands.
+// POST /api/push/register
+func handleRegisterPushToken(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+	if user == nil {
+		respondError(w, http.StatusUnauthorized, "Not authenticated")
+		return
+	}
+
+	var req models.RegisterPushTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	req.Token = strings.TrimSpace(req.Token)
+	if req.Token == "" {
+		respondError(w, http.StatusBadRequest, "Token is required")
+		return
+	}
+	if req.Platform != notifications.PlatformIOS && req.Platform != notifications.PlatformAndroid && req.Platform != notifications.PlatformWeb {
+		respondError(w, http.StatusBadRequest, "Invalid platform. Use 'ios', 'android', or 'web'")
+		return
+	}
+
+	_, err := db.DB.Exec(`
+		INSERT INTO push_tokens (user_id, token, platform) VALUES (?, ?, ?)
+		ON DUPLICATE KEY UPDATE user_id = VALUES(user_id), platform = VALUES(platform)
+	`, user.ID, req.Token, req.Platform)
+	if err != nil {
+		logger.FromContext(r.Context()).Errorf("Error registering push token for user %d: %v", user.ID, err)
+		respondError(w, http.StatusInternalServerError, "Failed to register push token")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{"message": "Push token registered"})
+}
+
+// sendMessagePush notifies a recipient's devices of a new message. Message
+// bodies are usually E2E encrypted, so the notification only names the sender.
+func sendMessagePush(userID int, msg models.Message) {
+	rows, err := db.DB.Query("SELECT token FROM push_tokens WHERE user_id = ?", userID)
+	if err != nil {
+		logger.Default().Errorf("Error loading push tokens for user %d: %v", userID, err)
+		return
+	}
+	var tokens []string
+	for rows.Next() {
+		var token string
+		if err := rows.Scan(&token); err == nil {
+			tokens = append(tokens, token)
+		}
+	}
+	rows.Close()
+
+	title := "New message from " + msg.SenderName
+	for _, token := range tokens {
+		err := notifications.SendPushNotification(token, title, "Open FinViz to read it.")
+		if err == notifications.ErrInvalidToken {
+			db.DB.Exec("DELETE FROM push_tokens WHERE token = ?", token)
+			continue
+		}
+		if err != nil {
+			logger.Default().Errorf("Error sending push notification to user %d: %v", userID, err)
+		}
+	}
+}
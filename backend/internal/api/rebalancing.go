@@ -0,0 +1,188 @@
+package api
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/finviz/backend/internal/db"
+	"github.com/finviz/backend/internal/logger"
+	"github.com/finviz/backend/internal/models"
+)
+
+// Classes hold until they drift this many percentage points from target
+const (
+	defaultRebalanceThreshold = 5.0
+	maxRebalanceThreshold     = 50.0
+)
+
+// Allocation classes used in target_allocation
+const (
+	allocationStocks     = "stocks"
+	allocationBonds      = "bonds"
+	allocationCash       = "cash"
+	allocationRealEstate = "real_estate"
+	allocationCrypto     = "crypto"
+	allocationOther      = "other"
+)
+
+// allocationClasses groups seeded asset types into the classes a target
+// allocation is written in. Types not listed count as other.
+var allocationClasses = map[string]string{
+	assetTypeStocksUS: allocationStocks,
+	"Stocks (Intl)":   allocationStocks,
+	assetTypeBonds:    allocationBonds,
+	assetTypeCash:     allocationCash,
+	"Real Estate":     allocationRealEstate,
+	assetTypeCrypto:   allocationCrypto,
+}
+
+func isAllocationClass(class string) bool {
+	switch class {
+	case allocationStocks, allocationBonds, allocationCash, allocationRealEstate, allocationCrypto, allocationOther:
+		return true
+	}
+	return false
+}
+
+// parseTargetAllocation reads "stocks=60,bonds=30,cash=10" into percentages
+// by class, which must add up to 100
+func parseTargetAllocation(s string) (map[string]float64, error) {
+	targets := make(map[string]float64)
+	var total float64
+	for _, part := range strings.Split(s, ",") {
+		class, pct, ok := strings.Cut(strings.TrimSpace(part), "=")
+		class = strings.ToLower(strings.TrimSpace(class))
+		if !ok || !isAllocationClass(class) {
+			return nil, fmt.Errorf("target_allocation entries must be class=percent, with class one of stocks, bonds, cash, real_estate, crypto, other")
+		}
+		if _, dup := targets[class]; dup {
+			return nil, fmt.Errorf("target_allocation lists %s more than once", class)
+		}
+		p, err := strconv.ParseFloat(strings.TrimSpace(pct), 64)
+		if err != nil || p < 0 || p > 100 {
+			return nil, fmt.Errorf("target_allocation percent for %s must be between 0 and 100", class)
+		}
+		targets[class] = p
+		total += p
+	}
+	if math.Abs(total-100) > 0.01 {
+		return nil, fmt.Errorf("target_allocation percentages must add up to 100, got %g", total)
+	}
+	return targets, nil
+}
+
+// handleGetRebalancingRecommendations compares the user's current allocation
+// with a target and suggests how much of each class to buy or sell
+// GET /api/me/rebalancing?target_allocation=stocks=60,bonds=30,cash=10&threshold=5
+func handleGetRebalancingRecommendations(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+	if user == nil {
+		respondError(w, http.StatusUnauthorized, "Not authenticated")
+		return
+	}
+
+	q := r.URL.Query()
+	if q.Get("target_allocation") == "" {
+		respondError(w, http.StatusBadRequest, "target_allocation is required, e.g. stocks=60,bonds=30,cash=10")
+		return
+	}
+	targets, err := parseTargetAllocation(q.Get("target_allocation"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	threshold := defaultRebalanceThreshold
+	if v := q.Get("threshold"); v != "" {
+		t, err := strconv.ParseFloat(v, 64)
+		if err != nil || t < 0 || t > maxRebalanceThreshold {
+			respondError(w, http.StatusBadRequest, fmt.Sprintf("threshold must be between 0 and %g", maxRebalanceThreshold))
+			return
+		}
+		threshold = t
+	}
+
+	rows, err := db.DB.Query(`
+		SELECT t.name, SUM(a.current_value)
+		FROM assets a JOIN asset_types t ON t.id = a.type_id
+		WHERE a.user_id = ? AND a.deleted_at IS NULL
+		GROUP BY t.name
+	`, user.ID)
+	if err != nil {
+		logger.FromContext(r.Context()).Errorf("Error fetching allocation for user %d: %v", user.ID, err)
+		respondError(w, http.StatusInternalServerError, "Failed to fetch assets")
+		return
+	}
+	defer rows.Close()
+
+	holdings := make(map[string]float64)
+	var total float64
+	for rows.Next() {
+		var typeName string
+		var value float64
+		if err := rows.Scan(&typeName, &value); err != nil {
+			respondError(w, http.StatusInternalServerError, "Failed to fetch assets")
+			return
+		}
+		class, ok := allocationClasses[typeName]
+		if !ok {
+			class = allocationOther
+		}
+		holdings[class] += value
+		total += value
+	}
+	if total <= 0 {
+		respondError(w, http.StatusUnprocessableEntity, "No assets to rebalance")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, rebalancingActions(holdings, targets, total, threshold))
+}
+
+// rebalancingActions compares each class held or targeted with its target,
+// largest drift first
+func rebalancingActions(holdings, targets map[string]float64, total, threshold float64) []models.RebalancingAction {
+	classes := make(map[string]bool)
+	for class := range holdings {
+		classes[class] = true
+	}
+	for class := range targets {
+		classes[class] = true
+	}
+
+	actions := []models.RebalancingAction{}
+	for class := range classes {
+		current := holdings[class] / total * 100
+		drift := current - targets[class]
+
+		action := models.RebalancingAction{
+			AssetType:    class,
+			CurrentValue: roundCents(holdings[class]),
+			CurrentPct:   roundCents(current),
+			TargetPct:    targets[class],
+			Drift:        roundCents(drift),
+			Action:       models.RebalanceHold,
+		}
+		if math.Abs(drift) > threshold {
+			action.Action = models.RebalanceBuy
+			if drift > 0 {
+				action.Action = models.RebalanceSell
+			}
+			action.SuggestedAmount = roundCents(math.Abs(drift) / 100 * total)
+		}
+		actions = append(actions, action)
+	}
+
+	sort.Slice(actions, func(i, j int) bool {
+		di, dj := math.Abs(actions[i].Drift), math.Abs(actions[j].Drift)
+		if di != dj {
+			return di > dj
+		}
+		return actions[i].AssetType < actions[j].AssetType
+	})
+	return actions
+}
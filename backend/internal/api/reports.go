@@ -1,12 +1,15 @@
 package api
 
 import (
+	"database/sql"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/finviz/backend/internal/db"
+	"github.com/finviz/backend/internal/logger"
 	"github.com/finviz/backend/internal/models"
 	"github.com/finviz/backend/internal/reports"
 	"github.com/finviz/backend/internal/simulation"
@@ -16,8 +19,12 @@ import (
 type ReportRequest struct {
 	IncludeSimulation bool                     `json:"includeSimulation"`
 	SimulationParams  *models.SimulationParams `json:"simulationParams,omitempty"`
+	Scenarios         []models.Scenario        `json:"scenarios,omitempty"` // alternatives compared against the base simulation
 }
 
+// maxReportScenarios caps the alternatives so the comparison table fits the page
+const maxReportScenarios = 3
+
 // handleGenerateReport generates a PDF financial plan report
 func handleGenerateReport(w http.ResponseWriter, r *http.Request) {
 	user := getUserFromContext(r)
@@ -41,6 +48,11 @@ func handleGenerateReport(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	if len(req.Scenarios) > maxReportScenarios {
+		respondError(w, http.StatusBadRequest, fmt.Sprintf("Maximum %d comparison scenarios allowed", maxReportScenarios))
+		return
+	}
+
 	// Get client name (either the client being viewed or the user themselves)
 	clientName := user.Name
 	advisorName := ""
@@ -85,6 +97,19 @@ func handleGenerateReport(w http.ResponseWriter, r *http.Request) {
 		NetWorth:    netWorth,
 	}
 
+	// White-label the report with the advisor's branding: the acting advisor's
+	// own, or the client's advisor's when a client downloads it
+	var branding *models.AdvisorBranding
+	if isActingAsAdvisor(r) {
+		branding, err = fetchAdvisorBranding(user.ID)
+	} else {
+		branding, err = fetchClientBranding(userID)
+	}
+	if err != nil {
+		logger.FromContext(r.Context()).Errorf("Error fetching report branding: %v", err)
+	}
+	reportData.Branding = reportBranding(branding)
+
 	// Run simulation if requested
 	if req.IncludeSimulation {
 		params := models.DefaultSimulationParams()
@@ -98,6 +123,28 @@ func handleGenerateReport(w http.ResponseWriter, r *http.Request) {
 		reportData.Params = &params
 	}
 
+	// Run each comparison scenario against the same assets and debts
+	for i, scenario := range req.Scenarios {
+		params := models.DefaultSimulationParams()
+		if scenario.Params != nil {
+			params = *scenario.Params
+		}
+
+		scenarioDebts := debts
+		if params.ExcludeCreditCardDebt {
+			scenarioDebts = filterOutCreditCardDebt(debts)
+		}
+
+		label := scenario.Name
+		if label == "" {
+			label = fmt.Sprintf("Scenario %d", i+1)
+		}
+		reportData.Scenarios = append(reportData.Scenarios, reports.ScenarioSummary{
+			Label:  label,
+			Result: simulation.RunMonteCarloWithParams(assets, scenarioDebts, &params),
+		})
+	}
+
 	// Generate PDF
 	pdfBytes, err := reports.GenerateFinancialPlanReport(reportData)
 	if err != nil {
@@ -118,6 +165,91 @@ func handleGenerateReport(w http.ResponseWriter, r *http.Request) {
 	w.Write(pdfBytes)
 }
 
+// handleDownloadSimulationXLSX exports a saved simulation's projections as a spreadsheet
+func handleDownloadSimulationXLSX(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+	if user == nil {
+		respondError(w, http.StatusUnauthorized, "Not authenticated")
+		return
+	}
+
+	userID := getEffectiveUserID(r)
+	if userID == 0 {
+		respondError(w, http.StatusUnauthorized, "Unable to determine user")
+		return
+	}
+
+	simID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid simulation ID")
+		return
+	}
+
+	var paramsJSON string
+	var plainResults sql.NullString
+	var compressedResults []byte
+	var createdAt time.Time
+	err = db.DB.QueryRow(`
+		SELECT params, results, results_compressed, created_at
+		FROM simulation_history
+		WHERE id = ? AND user_id = ?
+	`, simID, userID).Scan(&paramsJSON, &plainResults, &compressedResults, &createdAt)
+	if err == sql.ErrNoRows {
+		respondError(w, http.StatusNotFound, "Simulation not found")
+		return
+	}
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch simulation")
+		return
+	}
+
+	resultsJSON, err := db.SimulationResultsJSON(plainResults, compressedResults)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to decompress simulation results")
+		return
+	}
+
+	var params models.SimulationParams
+	var results models.MonteCarloResponse
+	if err := json.Unmarshal([]byte(paramsJSON), &params); err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to parse simulation params")
+		return
+	}
+	if err := json.Unmarshal(resultsJSON, &results); err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to parse simulation results")
+		return
+	}
+
+	clientName := user.Name
+	advisorName := ""
+	if client := getClientContext(r); client != nil {
+		clientName = client.Name
+		advisorName = user.Name
+	}
+
+	xlsxBytes, err := reports.GenerateProjectionXLSX(reports.ReportData{
+		ClientName:  clientName,
+		AdvisorName: advisorName,
+		GeneratedAt: createdAt,
+		Simulation:  &results,
+		Params:      &params,
+	})
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to generate spreadsheet: %v", err))
+		return
+	}
+
+	filename := fmt.Sprintf("projections_%s_%s.xlsx",
+		sanitizeFilename(clientName),
+		createdAt.Format("2006-01-02"))
+
+	w.Header().Set("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
+	w.Header().Set("Content-Length", fmt.Sprintf("%d", len(xlsxBytes)))
+	w.WriteHeader(http.StatusOK)
+	w.Write(xlsxBytes)
+}
+
 // fetchUserAssets retrieves all assets with their types for a user
 func fetchUserAssets(userID int) ([]models.Asset, error) {
 	rows, err := db.DB.Query(`
@@ -126,7 +258,7 @@ func fetchUserAssets(userID int) ([]models.Asset, error) {
 			   t.id, t.name, t.default_return, t.default_volatility
 		FROM assets a
 		LEFT JOIN asset_types t ON a.type_id = t.id
-		WHERE a.user_id = ?
+		WHERE a.user_id = ? AND a.deleted_at IS NULL
 		ORDER BY a.current_value DESC
 	`, userID)
 	if err != nil {
@@ -0,0 +1,199 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/finviz/backend/internal/db"
+	"github.com/finviz/backend/internal/logger"
+	"github.com/finviz/backend/internal/models"
+)
+
+// Deleted assets and documents can be restored for this many days
+const deletedRecoveryDays = 30
+
+// restorableDocument matches a deleted document within the recovery window.
+// Older versions are soft-deleted too when a document is replaced; those
+// have a successor pointing at them and aren't restorable on their own.
+var restorableDocument = `d.deleted_at >= NOW() - INTERVAL ` + strconv.Itoa(deletedRecoveryDays) + ` DAY
+	AND NOT EXISTS (SELECT 1 FROM documents v WHERE v.document_id_original = d.id)`
+
+// softDeleteDocument hides a document and revokes its active shares, marking
+// them so they come back if the document is restored
+func softDeleteDocument(docID int) error {
+	tx, err := db.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`UPDATE documents SET deleted_at = NOW() WHERE id = ?`, docID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`
+		UPDATE document_shares SET revoked_at = NOW(), revoked_reason = ?
+		WHERE document_id = ? AND revoked_at IS NULL
+	`, models.ShareRevokedDeleted, docID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// handleListDeletedDocuments lists a user's documents deleted within the
+// recovery window, most recently deleted first
+// GET /api/documents?deleted=true
+func handleListDeletedDocuments(w http.ResponseWriter, r *http.Request, userID int) {
+	rows, err := db.DB.Query(`
+		SELECT d.id, d.user_id, d.uploaded_by, d.name, d.original_name, d.mime_type,
+		       d.size, d.category, d.encrypted, d.description, d.year, d.created_at, d.updated_at,
+		       d.deleted_at, d.document_id_original, u.name as uploader_name
+		FROM documents d
+		LEFT JOIN users u ON d.uploaded_by = u.id
+		WHERE d.user_id = ? AND `+restorableDocument+`
+		ORDER BY d.deleted_at DESC
+	`, userID)
+	if err != nil {
+		logger.FromContext(r.Context()).Errorf("Error listing deleted documents for user %d: %v", userID, err)
+		http.Error(w, "Failed to fetch documents", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	documents := []models.DocumentWithShares{}
+	categoryCount := make(map[string]int)
+	for rows.Next() {
+		var doc models.DocumentWithShares
+		var uploaderName *string
+		if err := rows.Scan(
+			&doc.ID, &doc.UserID, &doc.UploadedBy, &doc.Name, &doc.OriginalName,
+			&doc.MimeType, &doc.Size, &doc.Category, &doc.Encrypted,
+			&doc.Description, &doc.Year, &doc.CreatedAt, &doc.UpdatedAt,
+			&doc.DeletedAt, &doc.DocumentIDOriginal, &uploaderName,
+		); err != nil {
+			continue
+		}
+		if uploaderName != nil {
+			doc.UploadedByName = *uploaderName
+		}
+		documents = append(documents, doc)
+		categoryCount[doc.Category]++
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(models.DocumentListResponse{
+		Documents:  documents,
+		TotalCount: len(documents),
+		Categories: categoryCount,
+	})
+}
+
+// handleRestoreDocument undoes a document delete within the recovery window,
+// reinstating the shares that were revoked by the delete and haven't expired
+// since. The owner, the uploader, or an advisor with full access can restore.
+// POST /api/documents/{id}/restore
+func handleRestoreDocument(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	docID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid document ID", http.StatusBadRequest)
+		return
+	}
+
+	var doc models.Document
+	err = db.DB.QueryRow(`
+		SELECT d.id, d.user_id, d.uploaded_by FROM documents d
+		WHERE d.id = ? AND `+restorableDocument, docID).Scan(&doc.ID, &doc.UserID, &doc.UploadedBy)
+	if err != nil {
+		http.Error(w, "Deleted document not found", http.StatusNotFound)
+		return
+	}
+
+	canRestore := doc.UploadedBy == user.ID || doc.UserID == user.ID
+	if !canRestore && user.Role == "advisor" {
+		var accessLevel string
+		db.DB.QueryRow(`
+			SELECT access_level FROM advisor_clients
+			WHERE advisor_id = ? AND client_id = ? AND status = 'active'
+		`, user.ID, doc.UserID).Scan(&accessLevel)
+		canRestore = accessLevel == "full"
+	}
+	if !canRestore {
+		http.Error(w, "Cannot restore this document", http.StatusForbidden)
+		return
+	}
+
+	if err := restoreDocument(docID); err != nil {
+		logger.FromContext(r.Context()).Errorf("Error restoring document %d: %v", docID, err)
+		http.Error(w, "Failed to restore document", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "Document restored"})
+}
+
+// restoreDocument clears a document's deleted_at and reinstates the shares
+// softDeleteDocument revoked
+func restoreDocument(docID int) error {
+	tx, err := db.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`UPDATE documents SET deleted_at = NULL WHERE id = ? AND deleted_at IS NOT NULL`, docID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`
+		UPDATE document_shares SET revoked_at = NULL, revoked_reason = NULL
+		WHERE document_id = ? AND revoked_reason = ? AND (expires_at IS NULL OR expires_at > NOW())
+	`, docID, models.ShareRevokedDeleted); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// handleRestoreAsset undoes an asset delete within the recovery window
+// POST /api/assets/{id}/restore
+func handleRestoreAsset(w http.ResponseWriter, r *http.Request) {
+	userID := getEffectiveUserID(r)
+	if userID == 0 {
+		respondError(w, http.StatusUnauthorized, "Not authenticated")
+		return
+	}
+
+	if !canEdit(r) {
+		respondError(w, http.StatusForbidden, "No permission to edit client data")
+		return
+	}
+
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid ID")
+		return
+	}
+
+	result, err := db.DB.Exec(`
+		UPDATE assets SET deleted_at = NULL
+		WHERE id = ? AND user_id = ? AND deleted_at >= NOW() - INTERVAL `+strconv.Itoa(deletedRecoveryDays)+` DAY
+	`, id, userID)
+	if err != nil {
+		logger.FromContext(r.Context()).Errorf("Error restoring asset %d: %v", id, err)
+		respondError(w, http.StatusInternalServerError, "Failed to restore asset")
+		return
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		respondError(w, http.StatusNotFound, "Deleted asset not found")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{"status": "restored"})
+}
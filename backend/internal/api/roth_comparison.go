@@ -0,0 +1,181 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/finviz/backend/internal/models"
+	"github.com/finviz/backend/internal/simulation"
+)
+
+// Years of retirement simulated after the contribution period
+const rothComparisonRetirementYears = 30
+
+// Marginal rate gap (in either direction) treated as a meaningful tax difference
+const rothRateThreshold = 0.02
+
+// handleRothVsTraditional simulates the same contributions as Roth and as traditional IRA money
+func handleRothVsTraditional(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+	if user == nil {
+		respondError(w, http.StatusUnauthorized, "Not authenticated")
+		return
+	}
+
+	if isActingAsAdvisor(r) && !canRunSimulations(r) {
+		respondError(w, http.StatusForbidden, "No permission to run simulations for this client")
+		return
+	}
+
+	targetUserID := getEffectiveUserID(r)
+
+	var req models.RothVsTraditionalRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if req.CurrentAge < 18 || req.CurrentAge > 100 {
+		respondError(w, http.StatusBadRequest, "Current age must be between 18 and 100")
+		return
+	}
+	if req.AnnualContribution <= 0 {
+		respondError(w, http.StatusBadRequest, "Annual contribution must be greater than zero")
+		return
+	}
+	if req.CurrentMarginalRate < 0 || req.CurrentMarginalRate >= 1 || req.ProjectedRetirementRate < 0 || req.ProjectedRetirementRate >= 1 {
+		respondError(w, http.StatusBadRequest, "Tax rates must be decimals between 0 and 1 (e.g., 0.24 = 24%)")
+		return
+	}
+	if req.YearsToRetirement < 1 || req.YearsToRetirement > 60 {
+		respondError(w, http.StatusBadRequest, "Years to retirement must be between 1 and 60")
+		return
+	}
+	if req.RetirementSpending < 0 {
+		respondError(w, http.StatusBadRequest, "Retirement spending cannot be negative")
+		return
+	}
+
+	assets, err := fetchAssetsWithTypesForUser(targetUserID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	debts, err := fetchDebtsForUser(targetUserID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	// Without a spending target, draw 4% a year of what was contributed so
+	// retirement withdrawals (and their taxation) are part of the comparison
+	spending := req.RetirementSpending
+	if spending == 0 {
+		spending = req.AnnualContribution * float64(req.YearsToRetirement) * 0.04 / 12
+	}
+
+	base := models.SimulationParams{
+		TimeHorizonYears:    req.YearsToRetirement + rothComparisonRetirementYears,
+		MonthlyContribution: req.AnnualContribution / 12,
+		CurrentAge:          req.CurrentAge,
+		RetirementAge:       req.CurrentAge + req.YearsToRetirement,
+		RetirementSpending:  spending,
+	}
+	base.ApplyDefaults()
+
+	// Roth: full after-tax contribution, tax-free withdrawals
+	rothParams := base
+	rothParams.TaxFreeWithdrawals = true
+
+	// Traditional: the contribution's current-year tax effect comes off starting
+	// assets, and withdrawals are taxed at the projected retirement rate
+	traditionalParams := base
+	traditionalParams.RetirementTaxRate = req.ProjectedRetirementRate
+	if taxEffect := req.CurrentMarginalRate * req.AnnualContribution; taxEffect > 0 {
+		traditionalParams.OneTimeEvents = []models.Event{{
+			Year:        1,
+			Amount:      -taxEffect,
+			Description: "Traditional IRA tax adjustment",
+		}}
+	}
+
+	roth := simulation.RunMonteCarloWithParams(assets, debts, &rothParams)
+	traditional := simulation.RunMonteCarloWithParams(assets, debts, &traditionalParams)
+
+	crossoverAge := findRothCrossoverAge(roth.Projections, traditional.Projections, req.CurrentAge)
+	recommendation, rationale := rothRecommendation(req.CurrentMarginalRate, req.ProjectedRetirementRate,
+		roth.Summary.FinalP50, traditional.Summary.FinalP50, crossoverAge)
+
+	respondJSON(w, http.StatusOK, models.RothVsTraditionalResponse{
+		RothP50:                roth.Summary.FinalP50,
+		TraditionalP50:         traditional.Summary.FinalP50,
+		RothSuccessRate:        roth.Summary.SuccessRate,
+		TraditionalSuccessRate: traditional.Summary.SuccessRate,
+		CrossoverAge:           crossoverAge,
+		Recommendation:         recommendation,
+		Rationale:              rationale,
+	})
+}
+
+// findRothCrossoverAge returns the age from which the Roth median stays at or
+// above the traditional median through the end of the projection
+func findRothCrossoverAge(roth, traditional []models.YearProjection, currentAge int) *int {
+	n := len(roth)
+	if len(traditional) < n {
+		n = len(traditional)
+	}
+
+	crossover := -1
+	for i := 0; i < n; i++ {
+		if roth[i].P50 >= traditional[i].P50 {
+			if crossover < 0 {
+				crossover = i
+			}
+		} else {
+			crossover = -1
+		}
+	}
+	if crossover < 0 {
+		return nil
+	}
+
+	age := currentAge + roth[crossover].Year
+	if roth[crossover].Age > 0 {
+		age = roth[crossover].Age
+	}
+	return &age
+}
+
+// rothRecommendation picks a strategy from the expected change in marginal
+// rate, falling back to the simulated medians when the rates are close
+func rothRecommendation(currentRate, retirementRate, rothP50, traditionalP50 float64, crossoverAge *int) (string, string) {
+	rateGap := retirementRate - currentRate
+
+	switch {
+	case rateGap > rothRateThreshold:
+		return "roth", fmt.Sprintf("Your tax rate is expected to rise from %.0f%% to %.0f%% in retirement, so paying tax now at the lower rate favors Roth contributions.",
+			currentRate*100, retirementRate*100)
+	case rateGap < -rothRateThreshold:
+		rationale := fmt.Sprintf("Your tax rate is expected to fall from %.0f%% to %.0f%% in retirement, so deferring tax with traditional contributions saves more.",
+			currentRate*100, retirementRate*100)
+		if crossoverAge != nil {
+			rationale += fmt.Sprintf(" Roth balances do pull ahead from age %d, so consider splitting contributions if you expect a long retirement.", *crossoverAge)
+		}
+		return "traditional", rationale
+	}
+
+	// Similar rates - the tax treatment roughly cancels out, so defer to the simulations
+	diff := rothP50 - traditionalP50
+	if traditionalP50 != 0 && (diff/traditionalP50 > 0.02 || diff/traditionalP50 < -0.02) {
+		if diff > 0 {
+			return "roth", fmt.Sprintf("Your current and retirement tax rates are similar, but Roth contributions end with a $%.0f higher median because withdrawals are tax-free.",
+				diff)
+		}
+		return "traditional", fmt.Sprintf("Your current and retirement tax rates are similar, but traditional contributions end with a $%.0f higher median.",
+			-diff)
+	}
+
+	return "either", "Your current and retirement tax rates are similar and both strategies produce nearly the same outcome. Splitting contributions adds tax diversification."
+}
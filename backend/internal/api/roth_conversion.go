@@ -0,0 +1,139 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/finviz/backend/internal/models"
+	"github.com/finviz/backend/internal/simulation"
+	"github.com/finviz/backend/internal/taxcalc"
+)
+
+// Roth conversion ladders are planned this many years ahead
+const rothConversionYears = 10
+
+// Annual growth assumed for both accounts when the request doesn't set one
+const defaultRothConversionReturn = 0.06
+
+// handleOptimizeRothConversion plans yearly Roth conversions that fill the
+// client's income up to the top of a target tax bracket, and estimates what
+// they save against leaving the money to come out as RMDs
+// POST /api/simulate/roth-optimizer
+func handleOptimizeRothConversion(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+	if user == nil {
+		respondError(w, http.StatusUnauthorized, "Not authenticated")
+		return
+	}
+
+	if isActingAsAdvisor(r) && !canRunSimulations(r) {
+		respondError(w, http.StatusForbidden, "No permission to run simulations for this client")
+		return
+	}
+
+	var req models.RothConversionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if req.TraditionalBalance <= 0 {
+		respondError(w, http.StatusBadRequest, "Traditional balance must be greater than zero")
+		return
+	}
+	if req.RothBalance < 0 || req.TaxableIncome < 0 {
+		respondError(w, http.StatusBadRequest, "Roth balance and taxable income cannot be negative")
+		return
+	}
+	if req.CurrentAge < 18 || req.CurrentAge > 100 {
+		respondError(w, http.StatusBadRequest, "Current age must be between 18 and 100")
+		return
+	}
+	filingStatus := req.FilingStatus
+	if filingStatus == "" {
+		filingStatus = taxcalc.FilingSingle
+	}
+	if !taxcalc.IsValidFilingStatus(filingStatus) {
+		respondError(w, http.StatusBadRequest, "Invalid filing status")
+		return
+	}
+	growth := req.ExpectedReturn
+	if growth == 0 {
+		growth = defaultRothConversionReturn
+	}
+	if growth < -0.5 || growth > 0.5 {
+		respondError(w, http.StatusBadRequest, "Expected return must be a decimal between -0.5 and 0.5")
+		return
+	}
+
+	// Conversions fill income up to the top of the target bracket
+	ceiling := -1.0
+	for _, b := range taxcalc.Brackets(filingStatus) {
+		if math.Abs(b.Rate-req.TargetTaxBracket) < 0.0001 {
+			ceiling = b.UpTo
+			break
+		}
+	}
+	if ceiling < 0 {
+		respondError(w, http.StatusBadRequest, fmt.Sprintf("targetTaxBracket %.2f is not a %s bracket rate (e.g. 0.22)", req.TargetTaxBracket, filingStatus))
+		return
+	}
+
+	resp := models.RothConversionResponse{YearlyConversions: []models.RothConversionYear{}}
+
+	// Project the traditional balance with and without conversions, through
+	// the schedule and at least until the first RMD
+	rmdAge := req.CurrentAge
+	if rmdAge < simulation.RMDStartAge {
+		rmdAge = simulation.RMDStartAge
+	}
+	converted, unconverted, roth := req.TraditionalBalance, req.TraditionalBalance, req.RothBalance
+	var firstRMDWith, firstRMDWithout float64
+	startYear := time.Now().Year()
+	for i := 0; i < rothConversionYears || req.CurrentAge+i <= rmdAge; i++ {
+		age := req.CurrentAge + i
+		rmd := simulation.RequiredMinimumDistribution(converted, age)
+		if age == rmdAge {
+			firstRMDWith = rmd
+			firstRMDWithout = simulation.RequiredMinimumDistribution(unconverted, age)
+		}
+		converted -= rmd
+		unconverted -= simulation.RequiredMinimumDistribution(unconverted, age)
+
+		if i < rothConversionYears {
+			income := req.TaxableIncome + rmd
+			amount := math.Max(0, math.Min(ceiling-income, converted))
+			tax := taxcalc.FederalIncomeTax(income+amount, filingStatus) - taxcalc.FederalIncomeTax(income, filingStatus)
+			converted -= amount
+			roth += amount
+
+			resp.TotalConverted += amount
+			resp.TotalTax += tax
+			resp.YearlyConversions = append(resp.YearlyConversions, models.RothConversionYear{
+				Year:               startYear + i,
+				Age:                age,
+				Amount:             roundCents(amount),
+				EstimatedTax:       roundCents(tax),
+				TraditionalBalance: roundCents(converted * (1 + growth)),
+				RothBalance:        roundCents(roth * (1 + growth)),
+			})
+		}
+
+		converted *= 1 + growth
+		unconverted *= 1 + growth
+		roth *= 1 + growth
+	}
+
+	// Converted dollars would otherwise have been taxed at the marginal rate
+	// the unconverted RMDs push income into; growth is the same either way
+	futureRate := taxcalc.MarginalRate(req.TaxableIncome+firstRMDWithout, filingStatus)
+	resp.EstimatedTaxSavings = roundCents(resp.TotalConverted*futureRate - resp.TotalTax)
+	resp.ProjectedRMDReduction = roundCents(firstRMDWithout - firstRMDWith)
+	resp.TotalConverted = roundCents(resp.TotalConverted)
+	resp.TotalTax = roundCents(resp.TotalTax)
+
+	respondJSON(w, http.StatusOK, resp)
+}
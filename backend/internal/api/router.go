@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"net/http"
 	"regexp"
+
+	"github.com/finviz/backend/internal/middleware"
 )
 
 // clientIDPattern matches paths with a numeric client ID followed by more path segments
@@ -11,18 +13,31 @@ import (
 var clientIDPattern = regexp.MustCompile(`^/api/advisor/clients/\d+/.+`)
 
 func NewRouter() http.Handler {
-	mux := http.NewServeMux()
+	// Every method-qualified route is recorded for the OpenAPI spec
+	var routes []apiRoute
+	mux := newRouteMux(&routes, false)
 
 	// Public routes (no auth required)
 	mux.HandleFunc("POST /api/auth/register", handleRegister)
 	mux.HandleFunc("POST /api/auth/login", handleLogin)
+	mux.HandleFunc("POST /api/auth/mfa/validate", handleValidateMFA) // Authenticated by the partial token from login
+	mux.HandleFunc("POST /api/auth/refresh", handleRefreshToken)     // Authenticated by the refresh token in the body
+	mux.HandleFunc("POST /api/auth/revoke", handleRevokeToken)
 	mux.HandleFunc("GET /api/health", handleHealth)
+	mux.HandleFunc("GET /health", handleHealthCheck) // Liveness probe
+	mux.HandleFunc("GET /ready", handleReadiness)    // Readiness probe
+	mux.HandleFunc("GET /api/openapi.json", openAPIHandler(&routes))
+	mux.HandleFunc("GET /api/docs", handleAPIDocs)
+	mux.HandleFunc("GET /metrics", middleware.HandleMetrics) // Restricted by METRICS_ALLOWED_IPS when set
+	// Authenticates with the token query parameter, since browsers can't send headers on a WebSocket
+	mux.HandleFunc("GET /ws/conversations", handleMessagingWebSocket)
 
 	// Asset types (public - needed for registration form)
 	mux.HandleFunc("GET /api/asset-types", handleGetAssetTypes)
 
 	// Plaid status (public - to check if configured)
 	mux.HandleFunc("GET /api/plaid/status", handlePlaidStatus)
+	mux.HandleFunc("POST /api/plaid/webhook", handlePlaidWebhook) // Authenticated by Plaid-Verification JWT
 
 	// Chat status (public - to check if configured)
 	mux.HandleFunc("GET /api/chat/status", handleChatStatus)
@@ -32,68 +47,133 @@ func NewRouter() http.Handler {
 	mux.HandleFunc("POST /api/invitation/{token}/accept", handleAcceptInvitation)
 
 	// Protected routes - wrap with auth middleware
-	protectedMux := http.NewServeMux()
+	protectedMux := newRouteMux(&routes, true)
+
+	// Chat calls Claude, so each user gets an hourly request allowance
+	chatLimiter := middleware.NewChatRateLimiter(getUserFromContext)
+
+	// Creates that clients may retry accept an X-Idempotency-Key
+	idempotent := middleware.NewIdempotency(getUserFromContext)
 
 	// User info
 	protectedMux.HandleFunc("GET /api/auth/me", handleGetMe)
+	protectedMux.HandleFunc("PUT /api/me/password", handleChangePassword)
+	protectedMux.HandleFunc("POST /api/me/mfa/setup", handleSetupMFA)
+	protectedMux.HandleFunc("POST /api/me/mfa/verify", handleVerifyMFA)
+	protectedMux.HandleFunc("POST /api/me/mfa/disable", handleDisableMFA)
+	protectedMux.HandleFunc("GET /api/me/sessions", handleListSessions)
+	protectedMux.HandleFunc("DELETE /api/me/sessions", handleRevokeAllSessions)
+	protectedMux.HandleFunc("DELETE /api/me/sessions/{id}", handleRevokeSession)
+	protectedMux.HandleFunc("POST /api/me/export", handleExportUserData)
+	protectedMux.HandleFunc("GET /api/me/export/{jobId}", handleGetUserDataExport)
+	protectedMux.HandleFunc("GET /api/me/api-keys", handleListAPIKeys)
+	protectedMux.HandleFunc("POST /api/me/api-keys", handleCreateAPIKey)
+	protectedMux.HandleFunc("DELETE /api/me/api-keys/{id}", handleRevokeAPIKey)
+	protectedMux.HandleFunc("GET /api/me/document-requests", handleGetMyDocumentRequests)
+	protectedMux.HandleFunc("GET /api/me/net-worth-history", handleGetNetWorthHistory)
+	protectedMux.HandleFunc("GET /api/me/emergency-fund", handleCalculateEmergencyFund)
+	protectedMux.HandleFunc("GET /api/me/rebalancing", handleGetRebalancingRecommendations)
 
 	// Assets CRUD
 	protectedMux.HandleFunc("GET /api/assets", handleGetAssets)
 	protectedMux.HandleFunc("POST /api/assets", handleCreateAsset)
 	protectedMux.HandleFunc("PUT /api/assets/{id}", handleUpdateAsset)
+	protectedMux.HandleFunc("PATCH /api/assets/batch", handleBatchUpdateAssets)
 	protectedMux.HandleFunc("DELETE /api/assets/{id}", handleDeleteAsset)
+	protectedMux.HandleFunc("POST /api/assets/{id}/restore", handleRestoreAsset)
 
 	// Debts CRUD
 	protectedMux.HandleFunc("GET /api/debts", handleGetDebts)
 	protectedMux.HandleFunc("POST /api/debts", handleCreateDebt)
 	protectedMux.HandleFunc("PUT /api/debts/{id}", handleUpdateDebt)
+	protectedMux.HandleFunc("PATCH /api/debts/batch", handleBatchUpdateDebts)
 	protectedMux.HandleFunc("DELETE /api/debts/{id}", handleDeleteDebt)
+	protectedMux.HandleFunc("POST /api/debts/optimize-payoff", handleOptimizeDebtPayoff)
 
 	// Monte Carlo
 	protectedMux.HandleFunc("POST /api/monte-carlo", handleMonteCarlo)
 	protectedMux.HandleFunc("POST /api/monte-carlo/scenarios", handleScenarioComparison)
+	protectedMux.HandleFunc("POST /api/simulate/purchase-impact", handlePurchaseImpact)
+	protectedMux.HandleFunc("POST /api/simulate/roth-vs-traditional", handleRothVsTraditional)
+	protectedMux.HandleFunc("POST /api/simulate/ss-cola-sensitivity", handleCOLASensitivity)
+	protectedMux.HandleFunc("POST /api/simulate/sensitivity", handleSensitivityAnalysis)
+	protectedMux.HandleFunc("POST /api/simulate/estimate-ss", handleEstimateSocialSecurity)
+	protectedMux.HandleFunc("POST /api/simulate/roth-optimizer", handleOptimizeRothConversion)
+	protectedMux.HandleFunc("POST /api/simulate/529", handleProject529)
 
 	// Simulation History
 	protectedMux.HandleFunc("GET /api/simulations", handleListSimulations)
 	protectedMux.HandleFunc("GET /api/simulations/{id}", handleGetSimulation)
-	protectedMux.HandleFunc("POST /api/simulations", handleSaveSimulation)
+	protectedMux.HandleFunc("POST /api/simulations", idempotent.Wrap(handleSaveSimulation))
 	protectedMux.HandleFunc("PUT /api/simulations/{id}", handleUpdateSimulation)
 	protectedMux.HandleFunc("DELETE /api/simulations/{id}", handleDeleteSimulation)
+	protectedMux.HandleFunc("GET /api/simulations/{id}/xlsx", handleDownloadSimulationXLSX)
 
-	// CSV Import
+	// CSV / bank statement import
 	protectedMux.HandleFunc("POST /api/import/csv", handleCSVImport)
+	protectedMux.HandleFunc("POST /api/import/bank-statement", handleBankStatementImport)
 
 	// Plaid endpoints
 	protectedMux.HandleFunc("POST /api/plaid/link-token", handleCreateLinkToken)
 	protectedMux.HandleFunc("POST /api/plaid/exchange-token", handleExchangeToken)
 	protectedMux.HandleFunc("GET /api/plaid/items", handleGetPlaidItems)
 	protectedMux.HandleFunc("DELETE /api/plaid/items/{id}", handleDeletePlaidItem)
+	protectedMux.HandleFunc("POST /api/plaid/items/{id}/update-link-token", handleCreateUpdateLinkToken)
+	protectedMux.HandleFunc("POST /api/plaid/items/{id}/complete-update", handleCompletePlaidUpdate)
 	protectedMux.HandleFunc("GET /api/plaid/accounts", handleGetPlaidAccounts)
 	protectedMux.HandleFunc("POST /api/plaid/sync", handleSyncAccounts)
+	protectedMux.HandleFunc("POST /api/plaid/sync-holdings", handleSyncInvestmentHoldings)
+	protectedMux.HandleFunc("GET /api/plaid/holdings", handleGetInvestmentHoldings)
+	protectedMux.HandleFunc("GET /api/plaid/oauth-status/{oauthStateId}", handleGetOAuthStatus)
 
 	// Transactions endpoints
 	protectedMux.HandleFunc("GET /api/transactions", handleGetTransactions)
 	protectedMux.HandleFunc("GET /api/transactions/summary", handleGetTransactionSummary)
+	protectedMux.HandleFunc("GET /api/transactions/anomalies", handleDetectSpendingAnomalies)
 	protectedMux.HandleFunc("GET /api/transactions/categories", handleGetCategories)
 	protectedMux.HandleFunc("GET /api/transactions/debug", handleGetTransactionDebug)
 	protectedMux.HandleFunc("POST /api/transactions/sync", handleSyncTransactions)
+	protectedMux.HandleFunc("POST /api/transactions", handleCreateTransaction)
+	protectedMux.HandleFunc("PUT /api/transactions/{id}", handleUpdateTransaction)
+	protectedMux.HandleFunc("DELETE /api/transactions/{id}", handleDeleteTransaction)
+
+	// Transaction categorization rules
+	protectedMux.HandleFunc("GET /api/transaction-rules", handleGetTransactionRules)
+	protectedMux.HandleFunc("POST /api/transaction-rules", handleCreateTransactionRule)
+	protectedMux.HandleFunc("POST /api/transaction-rules/apply", handleReCategorizeTransactions)
+	protectedMux.HandleFunc("PUT /api/transaction-rules/{id}", handleUpdateTransactionRule)
+	protectedMux.HandleFunc("DELETE /api/transaction-rules/{id}", handleDeleteTransactionRule)
 
 	// Chat endpoint
-	protectedMux.HandleFunc("POST /api/chat", handleChat)
+	protectedMux.HandleFunc("POST /api/chat", chatLimiter.Limit(handleChat))
+	protectedMux.HandleFunc("POST /api/chat/stream", chatLimiter.Limit(handleChatStream)) // server-sent events
+	protectedMux.HandleFunc("GET /api/chat/conversations", handleListChatConversations)
+	protectedMux.HandleFunc("GET /api/chat/conversations/{id}", handleGetChatConversation)
+	protectedMux.HandleFunc("DELETE /api/chat/conversations/{id}", handleDeleteChatConversation)
 
 	// Report generation
 	protectedMux.HandleFunc("POST /api/reports/generate", handleGenerateReport)
 
+	// Tax planning calculators
+	protectedMux.HandleFunc("POST /api/tax/estimated-payments", handleEstimatedPayments)
+	protectedMux.HandleFunc("POST /api/tax/parse-batch", HandleTaxDocumentBatchParse)
+	protectedMux.HandleFunc("POST /api/tax/to-simulation-params", handleAutoPopulateSimulationParams)
+
 	// Messaging endpoints
 	protectedMux.HandleFunc("GET /api/messages/conversations", handleListConversations)
 	protectedMux.HandleFunc("POST /api/messages/conversations", handleStartConversation)
 	protectedMux.HandleFunc("GET /api/messages/conversations/{id}", handleGetConversation)
 	protectedMux.HandleFunc("GET /api/messages/conversations/{id}/messages", handleGetMessages)
+	protectedMux.HandleFunc("GET /api/messages/conversations/{id}/messages/search", handleSearchMessages)
 	protectedMux.HandleFunc("POST /api/messages/conversations/{id}/messages", handleSendMessage)
 	protectedMux.HandleFunc("POST /api/messages/conversations/{id}/read", handleMarkAsRead)
+	protectedMux.HandleFunc("PUT /api/messages/conversations/{id}/archive", handleArchiveConversation)
+	protectedMux.HandleFunc("PUT /api/messages/conversations/{id}/unarchive", handleUnarchiveConversation)
+	protectedMux.HandleFunc("GET /api/messages/conversations/{id}/export", handleExportConversation)
 	protectedMux.HandleFunc("GET /api/messages/unread", handleGetUnreadCounts)
 	protectedMux.HandleFunc("POST /api/messages/keys", handleRegisterPublicKey)
 	protectedMux.HandleFunc("GET /api/messages/keys/{userId}", handleGetPublicKey)
+	protectedMux.HandleFunc("POST /api/push/register", handleRegisterPushToken)
 
 	// Client pending invitations
 	protectedMux.HandleFunc("GET /api/invitations/pending", handleListPendingInvitations)
@@ -103,25 +183,47 @@ func NewRouter() http.Handler {
 	// Document vault endpoints
 	protectedMux.HandleFunc("POST /api/documents/upload", HandleDocumentUpload)
 	protectedMux.HandleFunc("GET /api/documents", HandleDocumentList)
+	protectedMux.HandleFunc("GET /api/documents/search", HandleDocumentSearch)
 	protectedMux.HandleFunc("GET /api/documents/{id}/download", HandleDocumentDownload)
+	protectedMux.HandleFunc("POST /api/documents/bulk-download", HandleDocumentBulkDownload)
 	protectedMux.HandleFunc("DELETE /api/documents/{id}", HandleDocumentDelete)
+	protectedMux.HandleFunc("POST /api/documents/{id}/restore", handleRestoreDocument)
+	protectedMux.HandleFunc("PUT /api/documents/{id}/replace", HandleDocumentReplace)
 	protectedMux.HandleFunc("POST /api/documents/{id}/share", HandleDocumentShare)
+	protectedMux.HandleFunc("GET /api/documents/{id}/shares", HandleDocumentShares)
+	protectedMux.HandleFunc("POST /api/documents/{id}/signature-request", HandleCreateSignatureRequest)
+	protectedMux.HandleFunc("GET /api/signature-requests/{id}/sign", HandleStubSign)
 
 	// Client goals endpoints (for clients viewing their own goals)
 	protectedMux.HandleFunc("GET /api/goals", handleGetMyGoals)
 	protectedMux.HandleFunc("PUT /api/goals/{goalId}/progress", handleUpdateMyGoalProgress)
+	protectedMux.HandleFunc("POST /api/goals/{goalId}/link-simulation", handleLinkGoalToSimulation)
+	protectedMux.HandleFunc("DELETE /api/goals/{goalId}/link-simulation", handleUnlinkGoalSimulation)
+	protectedMux.HandleFunc("GET /api/goals/{goalId}/projection", handleGetGoalProjection)
+	protectedMux.HandleFunc("GET /api/goals/{goalId}/timeline", handleGetGoalTimeline)
 
 	// Advisor-only routes (handled in advisor mux)
-	advisorMux := http.NewServeMux()
+	advisorMux := newRouteMux(&routes, true)
 	advisorMux.HandleFunc("GET /api/advisor/clients", handleListClients)
-	advisorMux.HandleFunc("POST /api/advisor/clients/invite", handleInviteClient)
-	advisorMux.HandleFunc("POST /api/advisor/clients/create", handleCreateClient)
+	advisorMux.HandleFunc("POST /api/advisor/clients/invite", idempotent.Wrap(handleInviteClient, "invitationToken"))
+	advisorMux.HandleFunc("POST /api/advisor/clients/create", idempotent.Wrap(handleCreateClient, "temporaryPassword"))
 	advisorMux.HandleFunc("POST /api/advisor/clients/add", handleAddExistingClient)
 	advisorMux.HandleFunc("PUT /api/advisor/clients/{id}", handleUpdateClient)
 	advisorMux.HandleFunc("DELETE /api/advisor/clients/{id}", handleRemoveClient)
+	advisorMux.HandleFunc("GET /api/advisor/invitations", handleListInvitations)
+	advisorMux.HandleFunc("PUT /api/advisor/invitations/{id}", handleUpdateInvitation)
 
 	// Client notes (advisor-only)
 	advisorMux.HandleFunc("GET /api/advisor/notes", handleGetAllClientNotes)
+	advisorMux.HandleFunc("GET /api/advisor/notes/search", handleSearchClientNotes)
+
+	// Aurelia customization for the advisor's clients
+	advisorMux.HandleFunc("GET /api/advisor/ai-config", handleGetAIConfig)
+	advisorMux.HandleFunc("PUT /api/advisor/ai-config", handleUpdateAIConfig)
+
+	// White-label branding for the advisor's PDF reports
+	advisorMux.HandleFunc("GET /api/advisor/branding", handleGetBranding)
+	advisorMux.HandleFunc("PUT /api/advisor/branding", handleUpdateBranding)
 
 	// Admin routes (advisor-only) for managing advisors and users
 	advisorMux.HandleFunc("GET /api/advisor/admin/advisors", handleListAdvisors)
@@ -132,40 +234,68 @@ func NewRouter() http.Handler {
 	advisorMux.HandleFunc("GET /api/advisor/admin/users", handleListAllUsers)
 	advisorMux.HandleFunc("POST /api/advisor/admin/assign-client", handleAssignClient)
 	advisorMux.HandleFunc("POST /api/advisor/admin/claim-client", handleClaimClient)
+	advisorMux.HandleFunc("POST /api/admin/documents/cleanup-expired-shares", handleCleanupExpiredShares)
+	advisorMux.HandleFunc("POST /api/admin/users/{id}/unlock", handleUnlockAccount)
 
 	// Advisor client context routes (for viewing/managing specific client's data)
-	clientContextMux := http.NewServeMux()
+	clientContextMux := newRouteMux(&routes, true)
 	clientContextMux.HandleFunc("GET /api/advisor/clients/{clientId}/assets", handleGetAssets)
 	clientContextMux.HandleFunc("POST /api/advisor/clients/{clientId}/assets", handleCreateAsset)
 	clientContextMux.HandleFunc("PUT /api/advisor/clients/{clientId}/assets/{id}", handleUpdateAsset)
+	clientContextMux.HandleFunc("PATCH /api/advisor/clients/{clientId}/assets/batch", handleBatchUpdateAssets)
 	clientContextMux.HandleFunc("DELETE /api/advisor/clients/{clientId}/assets/{id}", handleDeleteAsset)
+	clientContextMux.HandleFunc("POST /api/advisor/clients/{clientId}/assets/{id}/restore", handleRestoreAsset)
 	clientContextMux.HandleFunc("GET /api/advisor/clients/{clientId}/debts", handleGetDebts)
 	clientContextMux.HandleFunc("POST /api/advisor/clients/{clientId}/debts", handleCreateDebt)
 	clientContextMux.HandleFunc("PUT /api/advisor/clients/{clientId}/debts/{id}", handleUpdateDebt)
+	clientContextMux.HandleFunc("PATCH /api/advisor/clients/{clientId}/debts/batch", handleBatchUpdateDebts)
 	clientContextMux.HandleFunc("DELETE /api/advisor/clients/{clientId}/debts/{id}", handleDeleteDebt)
+	clientContextMux.HandleFunc("POST /api/advisor/clients/{clientId}/debts/optimize-payoff", handleOptimizeDebtPayoff)
 	clientContextMux.HandleFunc("POST /api/advisor/clients/{clientId}/monte-carlo", handleMonteCarlo)
 	clientContextMux.HandleFunc("POST /api/advisor/clients/{clientId}/monte-carlo/scenarios", handleScenarioComparison)
+	clientContextMux.HandleFunc("POST /api/advisor/clients/{clientId}/simulate/purchase-impact", handlePurchaseImpact)
+	clientContextMux.HandleFunc("POST /api/advisor/clients/{clientId}/simulate/roth-vs-traditional", handleRothVsTraditional)
+	clientContextMux.HandleFunc("POST /api/advisor/clients/{clientId}/simulate/roth-optimizer", handleOptimizeRothConversion)
+	clientContextMux.HandleFunc("POST /api/advisor/clients/{clientId}/simulate/529", handleProject529)
+	clientContextMux.HandleFunc("POST /api/advisor/clients/{clientId}/simulate/ss-cola-sensitivity", handleCOLASensitivity)
+	clientContextMux.HandleFunc("POST /api/advisor/clients/{clientId}/simulate/sensitivity", handleSensitivityAnalysis)
 	clientContextMux.HandleFunc("GET /api/advisor/clients/{clientId}/simulations", handleListSimulations)
 	clientContextMux.HandleFunc("GET /api/advisor/clients/{clientId}/simulations/{id}", handleGetSimulation)
-	clientContextMux.HandleFunc("POST /api/advisor/clients/{clientId}/simulations", handleSaveSimulation)
-	clientContextMux.HandleFunc("POST /api/advisor/clients/{clientId}/chat", handleChat)
+	clientContextMux.HandleFunc("GET /api/advisor/clients/{clientId}/simulations/{id}/xlsx", handleDownloadSimulationXLSX)
+	clientContextMux.HandleFunc("POST /api/advisor/clients/{clientId}/simulations", idempotent.Wrap(handleSaveSimulation))
+	clientContextMux.HandleFunc("POST /api/advisor/clients/{clientId}/chat", chatLimiter.Limit(handleChat))
+	clientContextMux.HandleFunc("POST /api/advisor/clients/{clientId}/chat/stream", chatLimiter.Limit(handleChatStream))
 	clientContextMux.HandleFunc("GET /api/advisor/clients/{clientId}/transactions", handleGetTransactions)
 	clientContextMux.HandleFunc("GET /api/advisor/clients/{clientId}/transactions/summary", handleGetTransactionSummary)
+	clientContextMux.HandleFunc("GET /api/advisor/clients/{clientId}/transactions/anomalies", handleDetectSpendingAnomalies)
 	clientContextMux.HandleFunc("GET /api/advisor/clients/{clientId}/transactions/categories", handleGetCategories)
+	clientContextMux.HandleFunc("POST /api/advisor/clients/{clientId}/transactions", handleCreateTransaction)
+	clientContextMux.HandleFunc("PUT /api/advisor/clients/{clientId}/transactions/{id}", handleUpdateTransaction)
+	clientContextMux.HandleFunc("DELETE /api/advisor/clients/{clientId}/transactions/{id}", handleDeleteTransaction)
 	clientContextMux.HandleFunc("POST /api/advisor/clients/{clientId}/reports/generate", handleGenerateReport)
+	clientContextMux.HandleFunc("POST /api/advisor/clients/{clientId}/dossier-export", handleDossierExport)
 	// Client notes routes (advisor-only, not visible to clients)
 	clientContextMux.HandleFunc("GET /api/advisor/clients/{clientId}/notes", handleListClientNotes)
 	clientContextMux.HandleFunc("POST /api/advisor/clients/{clientId}/notes", handleCreateClientNote)
 	clientContextMux.HandleFunc("PUT /api/advisor/clients/{clientId}/notes/{noteId}", handleUpdateClientNote)
 	clientContextMux.HandleFunc("DELETE /api/advisor/clients/{clientId}/notes/{noteId}", handleDeleteClientNote)
+	clientContextMux.HandleFunc("GET /api/advisor/clients/{clientId}/notes/{noteId}/history", handleGetNoteHistory)
+	clientContextMux.HandleFunc("POST /api/advisor/clients/{clientId}/notes/{noteId}/history/{versionId}/restore", handleRestoreNoteVersion)
 	// Client goals routes (visible to both advisors and clients)
 	clientContextMux.HandleFunc("GET /api/advisor/clients/{clientId}/goals", handleListGoals)
 	clientContextMux.HandleFunc("POST /api/advisor/clients/{clientId}/goals", handleCreateGoal)
+	clientContextMux.HandleFunc("PATCH /api/advisor/clients/{clientId}/goals/bulk", handleBulkUpdateGoals)
 	clientContextMux.HandleFunc("PUT /api/advisor/clients/{clientId}/goals/{goalId}", handleUpdateGoal)
 	clientContextMux.HandleFunc("DELETE /api/advisor/clients/{clientId}/goals/{goalId}", handleDeleteGoal)
+	// Document requests
+	clientContextMux.HandleFunc("GET /api/advisor/clients/{clientId}/document-requests", handleListDocumentRequests)
+	clientContextMux.HandleFunc("POST /api/advisor/clients/{clientId}/document-requests", handleCreateDocumentRequest)
+	clientContextMux.HandleFunc("PUT /api/advisor/clients/{clientId}/document-requests/{requestId}", handleUpdateDocumentRequest)
+	clientContextMux.HandleFunc("DELETE /api/advisor/clients/{clientId}/document-requests/{requestId}", handleDeleteDocumentRequest)
 
 	// Apply auth middleware to protected routes
 	mux.Handle("/api/auth/me", AuthMiddleware(protectedMux))
+	mux.Handle("/api/me/", AuthMiddleware(protectedMux))
 	mux.Handle("/api/assets", AuthMiddleware(protectedMux))
 	mux.Handle("/api/assets/", AuthMiddleware(protectedMux))
 	mux.Handle("/api/debts", AuthMiddleware(protectedMux))
@@ -173,16 +303,23 @@ func NewRouter() http.Handler {
 	mux.Handle("/api/monte-carlo", AuthMiddleware(protectedMux))
 	mux.Handle("/api/simulations", AuthMiddleware(protectedMux))
 	mux.Handle("/api/simulations/", AuthMiddleware(protectedMux))
+	mux.Handle("/api/simulate/", AuthMiddleware(protectedMux))
 	mux.Handle("/api/import/", AuthMiddleware(protectedMux))
 	mux.Handle("/api/plaid/", AuthMiddleware(protectedMux))
 	mux.Handle("/api/transactions", AuthMiddleware(protectedMux))
 	mux.Handle("/api/transactions/", AuthMiddleware(protectedMux))
+	mux.Handle("/api/transaction-rules", AuthMiddleware(protectedMux))
+	mux.Handle("/api/transaction-rules/", AuthMiddleware(protectedMux))
 	mux.Handle("/api/chat", AuthMiddleware(protectedMux))
+	mux.Handle("/api/chat/", AuthMiddleware(protectedMux))
 	mux.Handle("/api/invitations/", AuthMiddleware(protectedMux))
 	mux.Handle("/api/reports/", AuthMiddleware(protectedMux))
+	mux.Handle("/api/tax/", AuthMiddleware(protectedMux))
 	mux.Handle("/api/messages/", AuthMiddleware(protectedMux))
+	mux.Handle("/api/push/", AuthMiddleware(protectedMux))
 	mux.Handle("/api/documents", AuthMiddleware(protectedMux))
 	mux.Handle("/api/documents/", AuthMiddleware(protectedMux))
+	mux.Handle("/api/signature-requests/", AuthMiddleware(protectedMux))
 	mux.Handle("/api/goals", AuthMiddleware(protectedMux))
 	mux.Handle("/api/goals/", AuthMiddleware(protectedMux))
 
@@ -200,15 +337,31 @@ func NewRouter() http.Handler {
 
 	// Admin routes (advisor-only) for managing advisors
 	mux.Handle("/api/advisor/admin/", AuthMiddleware(AdvisorMiddleware(advisorMux)))
+	mux.Handle("/api/admin/", AuthMiddleware(AdvisorMiddleware(advisorMux)))
+
+	// Advisor notes across all clients
+	mux.Handle("/api/advisor/notes", AuthMiddleware(AdvisorMiddleware(advisorMux)))
+	mux.Handle("/api/advisor/notes/", AuthMiddleware(AdvisorMiddleware(advisorMux)))
+
+	// Advisor AI configuration
+	mux.Handle("/api/advisor/ai-config", AuthMiddleware(AdvisorMiddleware(advisorMux)))
+
+	// Advisor report branding
+	mux.Handle("/api/advisor/branding", AuthMiddleware(AdvisorMiddleware(advisorMux)))
+
+	// Advisor client invitations
+	mux.Handle("/api/advisor/invitations", AuthMiddleware(AdvisorMiddleware(advisorMux)))
+	mux.Handle("/api/advisor/invitations/", AuthMiddleware(AdvisorMiddleware(advisorMux)))
 
-	return corsMiddleware(mux)
+	return middleware.RequestLogger(middleware.Metrics(corsMiddleware(mux)))
 }
 
 func corsMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Request-ID, X-Idempotency-Key")
+		w.Header().Set("Access-Control-Expose-Headers", "X-Total-Count, X-Document-ID, X-Request-ID, Idempotent-Replayed")
 
 		if r.Method == "OPTIONS" {
 			w.WriteHeader(http.StatusOK)
@@ -0,0 +1,180 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/finviz/backend/internal/db"
+	"github.com/finviz/backend/internal/logger"
+	"github.com/finviz/backend/internal/models"
+	"github.com/finviz/backend/internal/reports"
+	"github.com/finviz/backend/internal/scheduler"
+	"github.com/finviz/backend/internal/simulation"
+)
+
+// Each client's monthly report is tried this many times before giving up
+const (
+	monthlyReportAttempts   = 3
+	monthlyReportRetryDelay = 30 * time.Second
+)
+
+// GenerateAllMonthlyReports generates monthly reports for every advisor with
+// active clients. It is the job the monthly report scheduler runs.
+func GenerateAllMonthlyReports() {
+	rows, err := db.DB.Query(`
+		SELECT DISTINCT advisor_id FROM advisor_clients WHERE status = 'active'
+	`)
+	if err != nil {
+		logger.Default().Errorf("Error fetching advisors for monthly reports: %v", err)
+		return
+	}
+
+	var advisorIDs []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			continue
+		}
+		advisorIDs = append(advisorIDs, id)
+	}
+	rows.Close()
+
+	for _, advisorID := range advisorIDs {
+		GenerateMonthlyReports(advisorID)
+	}
+}
+
+// GenerateMonthlyReports runs a fresh simulation for each of an advisor's
+// active clients and saves the financial plan PDF to the client's documents
+func GenerateMonthlyReports(advisorID int) {
+	rows, err := db.DB.Query(`
+		SELECT ac.client_id, u.name
+		FROM advisor_clients ac
+		JOIN users u ON ac.client_id = u.id
+		WHERE ac.advisor_id = ? AND ac.status = 'active'
+	`, advisorID)
+	if err != nil {
+		logger.Default().Errorf("Error fetching clients of advisor %d for monthly reports: %v", advisorID, err)
+		return
+	}
+
+	type reportClient struct {
+		id   int
+		name string
+	}
+	var clients []reportClient
+	for rows.Next() {
+		var c reportClient
+		if err := rows.Scan(&c.id, &c.name); err != nil {
+			continue
+		}
+		clients = append(clients, c)
+	}
+	rows.Close()
+
+	var advisorName string
+	if err := db.DB.QueryRow("SELECT name FROM users WHERE id = ?", advisorID).Scan(&advisorName); err != nil {
+		logger.Default().Errorf("Error fetching advisor %d for monthly reports: %v", advisorID, err)
+		return
+	}
+	branding, err := fetchAdvisorBranding(advisorID)
+	if err != nil {
+		logger.Default().Errorf("Error fetching branding of advisor %d: %v", advisorID, err)
+	}
+
+	generated := 0
+	for _, c := range clients {
+		err := scheduler.Retry(monthlyReportAttempts, monthlyReportRetryDelay, func() error {
+			return generateMonthlyReport(advisorID, advisorName, c.id, c.name, reportBranding(branding))
+		})
+		if err != nil {
+			logger.Default().Errorf("Error generating monthly report for client %d after %d attempts: %v", c.id, monthlyReportAttempts, err)
+			continue
+		}
+		generated++
+	}
+
+	if generated > 0 {
+		logger.Default().Infof("Generated %d monthly reports for advisor %d", generated, advisorID)
+	}
+}
+
+// generateMonthlyReport builds one client's report from their current assets
+// and debts, using the parameters of their latest saved simulation if any
+func generateMonthlyReport(advisorID int, advisorName string, clientID int, clientName string, branding *reports.Branding) error {
+	assets, err := fetchUserAssets(clientID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch assets: %w", err)
+	}
+	debts, err := fetchUserDebts(clientID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch debts: %w", err)
+	}
+
+	params, err := fetchLatestSimulationParams(clientID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch simulation params: %w", err)
+	}
+
+	var totalAssets, totalDebts float64
+	for _, a := range assets {
+		totalAssets += a.CurrentValue
+	}
+	for _, d := range debts {
+		totalDebts += d.CurrentBalance
+	}
+
+	simResult := simulation.RunMonteCarloWithParams(assets, debts, params)
+
+	now := time.Now()
+	pdfBytes, err := reports.GenerateFinancialPlanReport(reports.ReportData{
+		Title:       "Monthly Financial Plan Report",
+		ClientName:  clientName,
+		AdvisorName: advisorName,
+		GeneratedAt: now,
+		Assets:      assets,
+		Debts:       debts,
+		Simulation:  &simResult,
+		Params:      params,
+		TotalAssets: totalAssets,
+		TotalDebts:  totalDebts,
+		NetWorth:    totalAssets - totalDebts,
+		Branding:    branding,
+	})
+	if err != nil {
+		return err
+	}
+
+	filename := fmt.Sprintf("monthly_report_%s_%s.pdf", sanitizeFilename(clientName), now.Format("2006-01"))
+	if _, err := SaveDocumentFromBytes(clientID, advisorID, filename, models.DocCategoryReports, "application/pdf", pdfBytes); err != nil {
+		return err
+	}
+	return nil
+}
+
+// fetchLatestSimulationParams returns the params of a user's most recent saved
+// simulation, or the defaults if they have none
+func fetchLatestSimulationParams(userID int) (*models.SimulationParams, error) {
+	params := models.DefaultSimulationParams()
+
+	var paramsJSON string
+	err := db.DB.QueryRow(`
+		SELECT params FROM simulation_history
+		WHERE user_id = ?
+		ORDER BY created_at DESC
+		LIMIT 1
+	`, userID).Scan(&paramsJSON)
+	if err == sql.ErrNoRows {
+		return &params, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(paramsJSON), &params); err != nil {
+		return nil, err
+	}
+	return &params, nil
+}
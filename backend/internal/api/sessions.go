@@ -0,0 +1,278 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/finviz/backend/internal/auth"
+	"github.com/finviz/backend/internal/db"
+	"github.com/finviz/backend/internal/logger"
+	"github.com/finviz/backend/internal/models"
+)
+
+// Longest user agent kept on a session row
+const maxSessionUserAgent = 255
+
+// respondWithSession starts a new session for user: it issues an access
+// token and a refresh token and writes them as an AuthResponse
+func respondWithSession(w http.ResponseWriter, r *http.Request, status int, user models.User) {
+	token, err := auth.GenerateToken(user.ID, user.Email)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to generate token")
+		return
+	}
+
+	refreshToken, _, err := issueRefreshToken(db.DB, r, user.ID)
+	if err != nil {
+		logger.FromContext(r.Context()).Errorf("Error issuing refresh token for user %d: %v", user.ID, err)
+		respondError(w, http.StatusInternalServerError, "Failed to generate token")
+		return
+	}
+
+	respondJSON(w, status, models.AuthResponse{
+		Token:        token,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int(auth.AccessTokenTTL.Seconds()),
+		User:         user,
+	})
+}
+
+// execer is satisfied by both *sql.DB and *sql.Tx
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+// issueRefreshToken stores a new refresh token for userID and returns it
+// along with its row ID
+func issueRefreshToken(ex execer, r *http.Request, userID int) (string, int64, error) {
+	token, err := auth.GenerateRefreshToken()
+	if err != nil {
+		return "", 0, err
+	}
+
+	userAgent := r.UserAgent()
+	if len(userAgent) > maxSessionUserAgent {
+		userAgent = userAgent[:maxSessionUserAgent]
+	}
+
+	result, err := ex.Exec(`
+		INSERT INTO refresh_tokens (user_id, token_hash, expires_at, user_agent, ip_address)
+		VALUES (?, ?, ?, ?, ?)
+	`, userID, auth.HashRefreshToken(token), time.Now().Add(auth.RefreshTokenTTL), userAgent, getClientIP(r))
+	if err != nil {
+		return "", 0, err
+	}
+	id, _ := result.LastInsertId()
+	return token, id, nil
+}
+
+// revokeAllRefreshTokens ends every active session for a user
+func revokeAllRefreshTokens(userID int) error {
+	_, err := db.DB.Exec(`
+		UPDATE refresh_tokens SET revoked_at = NOW() WHERE user_id = ? AND revoked_at IS NULL
+	`, userID)
+	return err
+}
+
+// handleRefreshToken exchanges a refresh token for a new access token and
+// refresh token. The presented token is revoked, so each one works once. If
+// an already-revoked token is presented it has probably been stolen, and
+// every session for the user is ended.
+// POST /api/auth/refresh
+func handleRefreshToken(w http.ResponseWriter, r *http.Request) {
+	var req models.RefreshTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.RefreshToken == "" {
+		respondError(w, http.StatusBadRequest, "Refresh token is required")
+		return
+	}
+
+	var tokenID, userID int
+	var expiresAt time.Time
+	var revokedAt sql.NullTime
+	err := db.DB.QueryRow(`
+		SELECT id, user_id, expires_at, revoked_at FROM refresh_tokens WHERE token_hash = ?
+	`, auth.HashRefreshToken(req.RefreshToken)).Scan(&tokenID, &userID, &expiresAt, &revokedAt)
+	if err != nil {
+		respondError(w, http.StatusUnauthorized, "Invalid refresh token")
+		return
+	}
+
+	if revokedAt.Valid {
+		if err := revokeAllRefreshTokens(userID); err != nil {
+			logger.FromContext(r.Context()).Errorf("Error revoking sessions for user %d after refresh token reuse: %v", userID, err)
+		}
+		logAuditEvent(r, userID, AuditActionRefreshTokenReused, fmt.Sprintf("refresh_token_id=%d", tokenID))
+		respondError(w, http.StatusUnauthorized, "Invalid refresh token")
+		return
+	}
+	if time.Now().After(expiresAt) {
+		respondError(w, http.StatusUnauthorized, "Refresh token expired")
+		return
+	}
+
+	var user models.User
+	err = db.DB.QueryRow(
+		"SELECT id, email, name, role, created_at, updated_at FROM users WHERE id = ?",
+		userID,
+	).Scan(&user.ID, &user.Email, &user.Name, &user.Role, &user.CreatedAt, &user.UpdatedAt)
+	if err != nil {
+		respondError(w, http.StatusUnauthorized, "User not found")
+		return
+	}
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Database error")
+		return
+	}
+	defer tx.Rollback()
+
+	// Only one of two concurrent refreshes with the same token may win
+	result, err := tx.Exec("UPDATE refresh_tokens SET revoked_at = NOW() WHERE id = ? AND revoked_at IS NULL", tokenID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to refresh token")
+		return
+	}
+	if n, _ := result.RowsAffected(); n != 1 {
+		respondError(w, http.StatusUnauthorized, "Invalid refresh token")
+		return
+	}
+
+	refreshToken, newID, err := issueRefreshToken(tx, r, user.ID)
+	if err != nil {
+		logger.FromContext(r.Context()).Errorf("Error rotating refresh token for user %d: %v", user.ID, err)
+		respondError(w, http.StatusInternalServerError, "Failed to refresh token")
+		return
+	}
+	if _, err := tx.Exec("UPDATE refresh_tokens SET replaced_by = ? WHERE id = ?", newID, tokenID); err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to refresh token")
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to refresh token")
+		return
+	}
+
+	token, err := auth.GenerateToken(user.ID, user.Email)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to generate token")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, models.AuthResponse{
+		Token:        token,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int(auth.AccessTokenTTL.Seconds()),
+		User:         user,
+	})
+}
+
+// handleRevokeToken ends the session a refresh token belongs to, for logout.
+// Holding the token is proof enough, so this works after the access token has
+// expired. Unknown tokens are ignored so the response reveals nothing.
+// POST /api/auth/revoke
+func handleRevokeToken(w http.ResponseWriter, r *http.Request) {
+	var req models.RefreshTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.RefreshToken == "" {
+		respondError(w, http.StatusBadRequest, "Refresh token is required")
+		return
+	}
+
+	_, err := db.DB.Exec(`
+		UPDATE refresh_tokens SET revoked_at = NOW() WHERE token_hash = ? AND revoked_at IS NULL
+	`, auth.HashRefreshToken(req.RefreshToken))
+	if err != nil {
+		logger.FromContext(r.Context()).Errorf("Error revoking refresh token: %v", err)
+		respondError(w, http.StatusInternalServerError, "Failed to revoke token")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleListSessions lists the current user's active sessions
+// GET /api/me/sessions
+func handleListSessions(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+	if user == nil {
+		respondError(w, http.StatusUnauthorized, "Not authenticated")
+		return
+	}
+
+	rows, err := db.DB.Query(`
+		SELECT id, issued_at, expires_at, user_agent, ip_address
+		FROM refresh_tokens
+		WHERE user_id = ? AND revoked_at IS NULL AND expires_at > NOW()
+		ORDER BY issued_at DESC
+	`, user.ID)
+	if err != nil {
+		logger.FromContext(r.Context()).Errorf("Error listing sessions for user %d: %v", user.ID, err)
+		respondError(w, http.StatusInternalServerError, "Failed to fetch sessions")
+		return
+	}
+	defer rows.Close()
+
+	sessions := []models.Session{}
+	for rows.Next() {
+		var s models.Session
+		if err := rows.Scan(&s.ID, &s.IssuedAt, &s.ExpiresAt, &s.UserAgent, &s.IPAddress); err != nil {
+			continue
+		}
+		sessions = append(sessions, s)
+	}
+
+	respondJSON(w, http.StatusOK, sessions)
+}
+
+// handleRevokeSession ends one of the current user's sessions
+// DELETE /api/me/sessions/{id}
+func handleRevokeSession(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+	if user == nil {
+		respondError(w, http.StatusUnauthorized, "Not authenticated")
+		return
+	}
+
+	sessionID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid session ID")
+		return
+	}
+
+	result, err := db.DB.Exec(`
+		UPDATE refresh_tokens SET revoked_at = NOW() WHERE id = ? AND user_id = ? AND revoked_at IS NULL
+	`, sessionID, user.ID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to revoke session")
+		return
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		respondError(w, http.StatusNotFound, "Session not found")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleRevokeAllSessions ends every session for the current user. Access
+// tokens already issued stay valid until they expire.
+// DELETE /api/me/sessions
+func handleRevokeAllSessions(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+	if user == nil {
+		respondError(w, http.StatusUnauthorized, "Not authenticated")
+		return
+	}
+
+	if err := revokeAllRefreshTokens(user.ID); err != nil {
+		logger.FromContext(r.Context()).Errorf("Error revoking sessions for user %d: %v", user.ID, err)
+		respondError(w, http.StatusInternalServerError, "Failed to revoke sessions")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
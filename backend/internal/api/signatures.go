@@ -0,0 +1,201 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/finviz/backend/internal/db"
+	"github.com/finviz/backend/internal/logger"
+	"github.com/finviz/backend/internal/models"
+	"github.com/finviz/backend/internal/signature"
+	"github.com/finviz/backend/internal/storage"
+)
+
+var signatureProvider signature.Provider
+
+func init() {
+	signatureProvider = signature.NewProvider()
+}
+
+// HandleCreateSignatureRequest sends a client's document out for e-signature
+func HandleCreateSignatureRequest(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+	if user == nil {
+		respondError(w, http.StatusUnauthorized, "Not authenticated")
+		return
+	}
+
+	if !user.IsAdvisor() {
+		respondError(w, http.StatusForbidden, "Only advisors can request signatures")
+		return
+	}
+
+	docID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid document ID")
+		return
+	}
+
+	var req models.SignatureRequestCreate
+	if r.ContentLength > 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			respondError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+	}
+
+	var doc models.Document
+	err = db.DB.QueryRow(`
+		SELECT id, user_id, name, original_name, mime_type, storage_path, encrypted
+		FROM documents WHERE id = ? AND deleted_at IS NULL
+	`, docID).Scan(&doc.ID, &doc.UserID, &doc.Name, &doc.OriginalName, &doc.MimeType, &doc.StoragePath, &doc.Encrypted)
+	if err != nil {
+		respondError(w, http.StatusNotFound, "Document not found")
+		return
+	}
+
+	// The document must belong to one of the advisor's active clients
+	var clientName, clientEmail string
+	err = db.DB.QueryRow(`
+		SELECT u.name, u.email FROM advisor_clients ac
+		JOIN users u ON u.id = ac.client_id
+		WHERE ac.advisor_id = ? AND ac.client_id = ? AND ac.status = 'active'
+	`, user.ID, doc.UserID).Scan(&clientName, &clientEmail)
+	if err != nil {
+		respondError(w, http.StatusForbidden, "Document does not belong to one of your clients")
+		return
+	}
+
+	data, err := storage.DefaultStorage.Load(doc.StoragePath, doc.Encrypted)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to load document")
+		return
+	}
+
+	subject := "Please sign: " + doc.Name
+	if req.Subject != nil && *req.Subject != "" {
+		subject = *req.Subject
+	}
+	message := fmt.Sprintf("%s has requested your signature on %s.", user.Name, doc.Name)
+	if req.Message != nil && *req.Message != "" {
+		message = *req.Message
+	}
+
+	result, err := db.DB.Exec(`
+		INSERT INTO document_signature_requests (document_id, advisor_id, client_id, status, external_provider)
+		VALUES (?, ?, ?, ?, ?)
+	`, doc.ID, user.ID, doc.UserID, models.SignatureStatusPending, signatureProvider.Name())
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to create signature request")
+		return
+	}
+	requestID, _ := result.LastInsertId()
+
+	externalID, err := signatureProvider.Send(signature.Request{
+		DocumentName: doc.OriginalName,
+		DocumentData: data,
+		MimeType:     doc.MimeType,
+		SignerName:   clientName,
+		SignerEmail:  clientEmail,
+		Subject:      subject,
+		Message:      message,
+	})
+	if err != nil {
+		// Leave the request pending so it can be inspected or retried
+		logger.FromContext(r.Context()).Errorf("Error sending signature request %d: %v", requestID, err)
+		respondError(w, http.StatusBadGateway, "Failed to send signature request")
+		return
+	}
+
+	now := time.Now()
+	_, err = db.DB.Exec(`
+		UPDATE document_signature_requests
+		SET status = ?, external_signature_id = ?, sent_at = ?
+		WHERE id = ?
+	`, models.SignatureStatusSent, externalID, now, requestID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to update signature request")
+		return
+	}
+
+	sigReq, err := fetchSignatureRequest(int(requestID))
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch signature request")
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, sigReq)
+}
+
+// HandleStubSign simulates a client signing a stub-provider signature request
+func HandleStubSign(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+	if user == nil {
+		respondError(w, http.StatusUnauthorized, "Not authenticated")
+		return
+	}
+
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid signature request ID")
+		return
+	}
+
+	sigReq, err := fetchSignatureRequest(id)
+	if err == sql.ErrNoRows {
+		respondError(w, http.StatusNotFound, "Signature request not found")
+		return
+	}
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch signature request")
+		return
+	}
+
+	// Real providers report signing through their own callbacks
+	if sigReq.ExternalProvider != signature.ProviderStub {
+		respondError(w, http.StatusBadRequest, "Only stub signature requests can be signed here")
+		return
+	}
+	if sigReq.Status != models.SignatureStatusSent {
+		respondError(w, http.StatusConflict, "Signature request is not awaiting signature")
+		return
+	}
+
+	_, err = db.DB.Exec(`
+		UPDATE document_signature_requests SET status = ?, signed_at = ? WHERE id = ?
+	`, models.SignatureStatusSigned, time.Now(), id)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to sign request")
+		return
+	}
+
+	sigReq, err = fetchSignatureRequest(id)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch signature request")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, sigReq)
+}
+
+// fetchSignatureRequest loads a signature request by ID
+func fetchSignatureRequest(id int) (*models.SignatureRequest, error) {
+	var sr models.SignatureRequest
+	err := db.DB.QueryRow(`
+		SELECT id, document_id, advisor_id, client_id, status, external_signature_id,
+		       external_provider, sent_at, signed_at, created_at, updated_at
+		FROM document_signature_requests
+		WHERE id = ?
+	`, id).Scan(
+		&sr.ID, &sr.DocumentID, &sr.AdvisorID, &sr.ClientID, &sr.Status, &sr.ExternalSignatureID,
+		&sr.ExternalProvider, &sr.SentAt, &sr.SignedAt, &sr.CreatedAt, &sr.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &sr, nil
+}
@@ -0,0 +1,90 @@
+package api
+
+import (
+	"database/sql"
+
+	"github.com/finviz/backend/internal/db"
+	"github.com/finviz/backend/internal/logger"
+)
+
+// Rows processed per batch when compressing legacy simulation results
+const compressBatchSize = 100
+
+// legacyCompression summarizes a legacy results compression run
+type legacyCompression struct {
+	compressed, failed      int
+	bytesBefore, bytesAfter int64
+}
+
+// CompressLegacySimulations gzips simulation results saved before compression
+// was added, in batches of compressBatchSize. It runs once at startup and
+// finds nothing to do after the first complete pass.
+func CompressLegacySimulations() {
+	log := logger.Default()
+	var stats legacyCompression
+	lastID := 0
+
+	for {
+		rows, err := db.DB.Query(`
+			SELECT id, results FROM simulation_history
+			WHERE id > ? AND results_compressed IS NULL AND results IS NOT NULL
+			ORDER BY id
+			LIMIT ?
+		`, lastID, compressBatchSize)
+		if err != nil {
+			log.Errorf("Error fetching legacy simulations: %v", err)
+			return
+		}
+
+		type legacyRow struct {
+			id      int
+			results sql.NullString
+		}
+		var batch []legacyRow
+		for rows.Next() {
+			var row legacyRow
+			if err := rows.Scan(&row.id, &row.results); err == nil {
+				batch = append(batch, row)
+			}
+		}
+		rows.Close()
+
+		if len(batch) == 0 {
+			break
+		}
+
+		for _, row := range batch {
+			lastID = row.id
+
+			compressed, err := db.GzipJSON([]byte(row.results.String))
+			if err != nil {
+				log.Errorf("Error compressing simulation %d: %v", row.id, err)
+				stats.failed++
+				continue
+			}
+
+			_, err = db.DB.Exec(`
+				UPDATE simulation_history SET results_compressed = ?, results = NULL WHERE id = ?
+			`, compressed, row.id)
+			if err != nil {
+				log.Errorf("Error storing compressed simulation %d: %v", row.id, err)
+				stats.failed++
+				continue
+			}
+
+			stats.compressed++
+			stats.bytesBefore += int64(len(row.results.String))
+			stats.bytesAfter += int64(len(compressed))
+		}
+	}
+
+	if stats.compressed == 0 && stats.failed == 0 {
+		return
+	}
+	ratio := 0.0
+	if stats.bytesAfter > 0 {
+		ratio = float64(stats.bytesBefore) / float64(stats.bytesAfter)
+	}
+	log.Infof("Compressed %d legacy simulations (%d failed): %d -> %d bytes, %.1fx",
+		stats.compressed, stats.failed, stats.bytesBefore, stats.bytesAfter, ratio)
+}
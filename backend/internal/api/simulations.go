@@ -1,11 +1,13 @@
 package api
 
 import (
+	"database/sql"
 	"encoding/json"
 	"net/http"
 	"strconv"
 
 	"github.com/finviz/backend/internal/db"
+	"github.com/finviz/backend/internal/middleware"
 	"github.com/finviz/backend/internal/models"
 )
 
@@ -110,9 +112,11 @@ func handleGetSimulation(w http.ResponseWriter, r *http.Request) {
 
 	var sim models.SimulationHistory
 	var runByUserName string
+	var plainResults sql.NullString
+	var compressedResults []byte
 	err = db.DB.QueryRow(`
 		SELECT sh.id, sh.user_id, sh.run_by_user_id, sh.name, sh.notes,
-		       sh.params, sh.results, sh.starting_net_worth, sh.final_p50,
+		       sh.params, sh.results, sh.results_compressed, sh.starting_net_worth, sh.final_p50,
 		       sh.success_rate, sh.time_horizon_years, sh.is_favorite, sh.created_at,
 		       COALESCE(u.name, '') as run_by_user_name
 		FROM simulation_history sh
@@ -120,7 +124,7 @@ func handleGetSimulation(w http.ResponseWriter, r *http.Request) {
 		WHERE sh.id = ? AND sh.user_id = ?
 	`, simID, userID).Scan(
 		&sim.ID, &sim.UserID, &sim.RunByUserID, &sim.Name, &sim.Notes,
-		&sim.Params, &sim.Results, &sim.StartingNetWorth, &sim.FinalP50,
+		&sim.Params, &plainResults, &compressedResults, &sim.StartingNetWorth, &sim.FinalP50,
 		&sim.SuccessRate, &sim.TimeHorizonYears, &sim.IsFavorite, &sim.CreatedAt,
 		&runByUserName,
 	)
@@ -130,6 +134,13 @@ func handleGetSimulation(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	resultsJSON, err := db.SimulationResultsJSON(plainResults, compressedResults)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to decompress simulation results")
+		return
+	}
+	sim.Results = string(resultsJSON)
+
 	// Parse the JSON fields
 	var params models.SimulationParams
 	var results models.MonteCarloResponse
@@ -177,10 +188,17 @@ func handleSaveSimulation(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Results can be several MB of projection data - store them gzip-compressed
+	resultsCompressed, err := db.GzipJSON(resultsJSON)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to compress results")
+		return
+	}
+
 	// Insert into database
 	result, err := db.DB.Exec(`
 		INSERT INTO simulation_history
-		(user_id, run_by_user_id, name, notes, params, results,
+		(user_id, run_by_user_id, name, notes, params, results_compressed,
 		 starting_net_worth, final_p50, success_rate, time_horizon_years)
 		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`,
@@ -189,7 +207,7 @@ func handleSaveSimulation(w http.ResponseWriter, r *http.Request) {
 		req.Name,
 		req.Notes,
 		string(paramsJSON),
-		string(resultsJSON),
+		resultsCompressed,
 		req.Results.Summary.StartingNetWorth,
 		req.Results.Summary.FinalP50,
 		req.Results.Summary.SuccessRate,
@@ -202,6 +220,7 @@ func handleSaveSimulation(w http.ResponseWriter, r *http.Request) {
 	}
 
 	id, _ := result.LastInsertId()
+	middleware.RecordSimulationRun()
 
 	respondJSON(w, http.StatusCreated, map[string]interface{}{
 		"id":      id,
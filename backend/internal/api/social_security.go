@@ -0,0 +1,72 @@
+package api
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/finviz/backend/internal/models"
+	"github.com/finviz/backend/internal/simulation"
+	"github.com/finviz/backend/internal/taxcalc"
+)
+
+// handleEstimateSocialSecurity gives a rough Social Security benefit for the
+// simulation setup, from a steady income history and the SSA PIA formula
+// POST /api/simulate/estimate-ss
+func handleEstimateSocialSecurity(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+	if user == nil {
+		respondError(w, http.StatusUnauthorized, "Not authenticated")
+		return
+	}
+
+	var req models.SocialSecurityEstimateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if req.BirthYear < 1900 || req.BirthYear > time.Now().Year() {
+		respondError(w, http.StatusBadRequest, "Invalid birth year")
+		return
+	}
+	if req.CurrentAnnualIncome < 0 {
+		respondError(w, http.StatusBadRequest, "Current annual income can't be negative")
+		return
+	}
+	if req.YearsWorked < 0 || req.YearsWorked > 60 {
+		respondError(w, http.StatusBadRequest, "Years worked must be between 0 and 60")
+		return
+	}
+	if req.ClaimingAge < 62 || req.ClaimingAge > 70 {
+		respondError(w, http.StatusBadRequest, "Claiming age must be between 62 and 70")
+		return
+	}
+
+	aime := simulation.EstimateAIME(req.CurrentAnnualIncome, taxcalc.SocialSecurityWageBase, req.YearsWorked)
+	pia := simulation.EstimatePIA(aime)
+	fraMonths := simulation.FullRetirementAgeMonths(req.BirthYear)
+	claimMonths := req.ClaimingAge * 12
+	benefit := pia * simulation.ClaimingFactor(fraMonths, claimMonths)
+
+	resp := models.SocialSecurityEstimateResponse{
+		EstimatedMonthlyBenefit:  roundCents(benefit),
+		FullRetirementAgeBenefit: roundCents(pia),
+		FullRetirementAge:        math.Round(float64(fraMonths)/12*100) / 100,
+		AIME:                     roundCents(aime),
+	}
+
+	// Cumulative benefits from the earlier start and the larger later one are
+	// equal at age t (in months): early*(t - earlyStart) = late*(t - lateStart)
+	earlyStart, earlyBenefit, lateStart, lateBenefit := claimMonths, benefit, fraMonths, pia
+	if claimMonths > fraMonths {
+		earlyStart, earlyBenefit, lateStart, lateBenefit = fraMonths, pia, claimMonths, benefit
+	}
+	if lateStart != earlyStart && lateBenefit > earlyBenefit {
+		t := (lateBenefit*float64(lateStart) - earlyBenefit*float64(earlyStart)) / (lateBenefit - earlyBenefit)
+		age := int(math.Ceil(t / 12))
+		resp.BreakEvenAge = &age
+	}
+
+	respondJSON(w, http.StatusOK, resp)
+}
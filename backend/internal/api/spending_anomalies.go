@@ -0,0 +1,118 @@
+package api
+
+import (
+	"math"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/finviz/backend/internal/classifier"
+	"github.com/finviz/backend/internal/db"
+	"github.com/finviz/backend/internal/models"
+)
+
+// Spending anomaly detection compares the current month against this many
+// full months before it, and flags spending this many standard deviations
+// above the mean
+const (
+	anomalyHistoryMonths = 6
+	anomalyZThreshold    = 2.0
+)
+
+// handleDetectSpendingAnomalies flags categories where spending so far this
+// month exceeds the mean of the previous six months by more than two standard
+// deviations. Months without spending in a category count as zero. Categories
+// whose history never varies are skipped, since any change would be infinitely
+// unusual.
+// GET /api/transactions/anomalies
+func handleDetectSpendingAnomalies(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+	if user == nil {
+		respondError(w, http.StatusUnauthorized, "Not authenticated")
+		return
+	}
+
+	// Use effective user ID for client context support
+	userID := getEffectiveUserID(r)
+
+	now := time.Now()
+	currentMonth := now.Format("2006-01")
+	start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location()).AddDate(0, -anomalyHistoryMonths, 0)
+
+	historyMonths := make([]string, anomalyHistoryMonths)
+	for i := range historyMonths {
+		historyMonths[i] = start.AddDate(0, i, 0).Format("2006-01")
+	}
+
+	rows, err := db.DB.Query(`
+		SELECT amount, date, name, merchant_name, category, subcategory
+		FROM transactions
+		WHERE user_id = ? AND deleted_at IS NULL AND date >= ? AND date <= ? AND pending = FALSE
+	`, userID, start.Format("2006-01-02"), now.Format("2006-01-02"))
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	defer rows.Close()
+
+	// category -> month -> spending, classified the same way as the summary
+	spending := make(map[string]map[string]float64)
+	for rows.Next() {
+		var t models.Transaction
+		if err := rows.Scan(&t.Amount, &t.Date, &t.Name, &t.MerchantName, &t.Category, &t.Subcategory); err != nil {
+			continue
+		}
+		if isIncome, _ := classifier.ClassifyTransaction(t); isIncome || t.Amount <= 0 {
+			continue
+		}
+
+		category := "Uncategorized"
+		if t.Category != nil && *t.Category != "" {
+			category = *t.Category
+		}
+		if spending[category] == nil {
+			spending[category] = make(map[string]float64)
+		}
+		spending[category][t.Date[:7]] += t.Amount
+	}
+
+	alerts := []models.AnomalyAlert{}
+	for category, months := range spending {
+		current := months[currentMonth]
+		if current == 0 {
+			continue
+		}
+
+		var sum float64
+		for _, m := range historyMonths {
+			sum += months[m]
+		}
+		mean := sum / float64(len(historyMonths))
+
+		var variance float64
+		for _, m := range historyMonths {
+			variance += (months[m] - mean) * (months[m] - mean)
+		}
+		stdDev := math.Sqrt(variance / float64(len(historyMonths)))
+		if stdDev == 0 {
+			continue
+		}
+
+		if current > mean+anomalyZThreshold*stdDev {
+			alerts = append(alerts, models.AnomalyAlert{
+				Category:           category,
+				CurrentMonthAmount: math.Round(current*100) / 100,
+				HistoricalMean:     math.Round(mean*100) / 100,
+				HistoricalStdDev:   math.Round(stdDev*100) / 100,
+				ZScore:             math.Round((current-mean)/stdDev*100) / 100,
+			})
+		}
+	}
+
+	// Most unusual first
+	sort.Slice(alerts, func(i, j int) bool {
+		return alerts[i].ZScore > alerts[j].ZScore
+	})
+
+	respondJSON(w, http.StatusOK, alerts)
+}
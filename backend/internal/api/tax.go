@@ -0,0 +1,104 @@
+package api
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/finviz/backend/internal/taxcalc"
+)
+
+// EstimatedPaymentsRequest is the request body for the quarterly estimated tax calculator
+type EstimatedPaymentsRequest struct {
+	AnnualNetIncome float64 `json:"annualNetIncome"`
+	FilingStatus    string  `json:"filingStatus"`
+	OtherIncome     float64 `json:"otherIncome"`
+	Withholding     float64 `json:"withholding"`
+	PriorYearTax    float64 `json:"priorYearTax,omitempty"` // Optional: enables the prior-year safe harbor test
+	TaxYear         int     `json:"taxYear,omitempty"`      // Defaults to the current year
+}
+
+// EstimatedPaymentsResponse contains the quarterly payment schedule
+type EstimatedPaymentsResponse struct {
+	Q1Amount          float64 `json:"q1Amount"`
+	Q1DueDate         string  `json:"q1DueDate"`
+	Q2Amount          float64 `json:"q2Amount"`
+	Q2DueDate         string  `json:"q2DueDate"`
+	Q3Amount          float64 `json:"q3Amount"`
+	Q3DueDate         string  `json:"q3DueDate"`
+	Q4Amount          float64 `json:"q4Amount"`
+	Q4DueDate         string  `json:"q4DueDate"`
+	SelfEmploymentTax float64 `json:"selfEmploymentTax"`
+	IncomeTax         float64 `json:"incomeTax"`
+	QBIDeduction      float64 `json:"qbiDeduction"`
+	TaxableIncome     float64 `json:"taxableIncome"`
+	TotalAnnualTax    float64 `json:"totalAnnualTax"`
+	EffectiveRate     float64 `json:"effectiveRate"`
+	PenaltyRisk       bool    `json:"penaltyRisk"`
+}
+
+// handleEstimatedPayments computes quarterly estimated tax payments for self-employed income
+func handleEstimatedPayments(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+	if user == nil {
+		respondError(w, http.StatusUnauthorized, "Not authenticated")
+		return
+	}
+
+	var req EstimatedPaymentsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if req.AnnualNetIncome < 0 || req.OtherIncome < 0 || req.Withholding < 0 {
+		respondError(w, http.StatusBadRequest, "Income and withholding amounts cannot be negative")
+		return
+	}
+
+	if req.FilingStatus == "" {
+		req.FilingStatus = taxcalc.FilingSingle
+	}
+	if !taxcalc.IsValidFilingStatus(req.FilingStatus) {
+		respondError(w, http.StatusBadRequest, "Invalid filing status. Must be 'single', 'married_filing_jointly', 'married_filing_separate', or 'head_of_household'")
+		return
+	}
+
+	if req.TaxYear == 0 {
+		req.TaxYear = time.Now().Year()
+	}
+
+	result := taxcalc.CalculateEstimatedTax(taxcalc.EstimatedTaxInput{
+		AnnualNetIncome: req.AnnualNetIncome,
+		FilingStatus:    req.FilingStatus,
+		OtherIncome:     req.OtherIncome,
+		Withholding:     req.Withholding,
+		PriorYearTax:    req.PriorYearTax,
+		TaxYear:         req.TaxYear,
+	})
+
+	quarterly := roundCents(result.QuarterlyAmount)
+	respondJSON(w, http.StatusOK, EstimatedPaymentsResponse{
+		Q1Amount:          quarterly,
+		Q1DueDate:         result.DueDates[0].Format("2006-01-02"),
+		Q2Amount:          quarterly,
+		Q2DueDate:         result.DueDates[1].Format("2006-01-02"),
+		Q3Amount:          quarterly,
+		Q3DueDate:         result.DueDates[2].Format("2006-01-02"),
+		Q4Amount:          quarterly,
+		Q4DueDate:         result.DueDates[3].Format("2006-01-02"),
+		SelfEmploymentTax: roundCents(result.SelfEmploymentTax),
+		IncomeTax:         roundCents(result.IncomeTax),
+		QBIDeduction:      roundCents(result.QBIDeduction),
+		TaxableIncome:     roundCents(result.TaxableIncome),
+		TotalAnnualTax:    roundCents(result.TotalAnnualTax),
+		EffectiveRate:     roundCents(result.EffectiveRate),
+		PenaltyRisk:       result.PenaltyRisk,
+	})
+}
+
+// roundCents rounds a dollar amount to two decimal places
+func roundCents(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,206 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/finviz/backend/internal/db"
+	"github.com/finviz/backend/internal/logger"
+	"github.com/finviz/backend/internal/models"
+	"github.com/finviz/backend/internal/storage"
+	"github.com/finviz/backend/internal/taxparser"
+)
+
+// Maximum number of files accepted by a single batch parse request
+const maxTaxBatchFiles = 10
+
+// HandleTaxDocumentBatchParse parses a tax package (W-2s, 1099s, 1040, 8606)
+// uploaded as multiple "files" parts. Files are parsed concurrently and the
+// response holds one entry per file in upload order; a file that can't be
+// parsed gets ParseError set instead of failing the whole batch.
+// POST /api/tax/parse-batch
+func HandleTaxDocumentBatchParse(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+	if user == nil {
+		respondError(w, http.StatusUnauthorized, "Not authenticated")
+		return
+	}
+
+	if err := r.ParseMultipartForm(maxFileSize); err != nil {
+		respondError(w, http.StatusBadRequest, "Failed to parse form data")
+		return
+	}
+
+	headers := r.MultipartForm.File["files"]
+	if len(headers) == 0 {
+		respondError(w, http.StatusBadRequest, "No files provided")
+		return
+	}
+	if len(headers) > maxTaxBatchFiles {
+		respondError(w, http.StatusBadRequest, fmt.Sprintf("Too many files (max %d)", maxTaxBatchFiles))
+		return
+	}
+
+	results := make([]taxparser.ExtractedTaxData, len(headers))
+	var wg sync.WaitGroup
+	for i, header := range headers {
+		wg.Add(1)
+		go func(i int, header *multipart.FileHeader) {
+			defer wg.Done()
+			// net/http only recovers panics on the request goroutine, and the
+			// PDF library panics on some malformed files
+			defer func() {
+				if rec := recover(); rec != nil {
+					logger.FromContext(r.Context()).Errorf("Recovered from panic parsing tax document %s: %v", header.Filename, rec)
+					results[i] = taxparser.ExtractedTaxData{
+						DocumentType: taxparser.DocTypeUnknown,
+						ParseError:   header.Filename + ": failed to read PDF",
+					}
+				}
+			}()
+			results[i] = parseTaxUpload(header)
+		}(i, header)
+	}
+	wg.Wait()
+
+	respondJSON(w, http.StatusOK, results)
+}
+
+// parseTaxUpload parses a single uploaded file, recording any failure on the entry
+func parseTaxUpload(header *multipart.FileHeader) taxparser.ExtractedTaxData {
+	failed := func(msg string) taxparser.ExtractedTaxData {
+		return taxparser.ExtractedTaxData{
+			DocumentType: taxparser.DocTypeUnknown,
+			ParseError:   fmt.Sprintf("%s: %s", header.Filename, msg),
+		}
+	}
+
+	if header.Size > maxFileSize {
+		return failed("file too large (max 25MB)")
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		return failed("failed to open file")
+	}
+	defer file.Close()
+
+	content, err := io.ReadAll(file)
+	if err != nil {
+		return failed("failed to read file")
+	}
+	if !bytes.HasPrefix(content, []byte("%PDF")) {
+		return failed("not a PDF file")
+	}
+
+	data, err := taxparser.ParsePDFContent(content)
+	if err != nil {
+		logger.Default().Errorf("Error parsing tax document %s: %v", header.Filename, err)
+		return failed("failed to read PDF")
+	}
+	return *data
+}
+
+// AutoPopulateParamsRequest identifies the stored tax document to read
+type AutoPopulateParamsRequest struct {
+	DocumentID   int `json:"documentId"`
+	AgeInTaxYear int `json:"ageInTaxYear,omitempty"` // Optional: client's age in the return's tax year, rolled forward to CurrentAge
+}
+
+// AutoPopulateParamsResponse holds the pre-filled simulation inputs
+type AutoPopulateParamsResponse struct {
+	Params       models.SimulationParams   `json:"params"`
+	DocumentType taxparser.TaxDocumentType `json:"documentType"`
+	TaxYear      int                       `json:"taxYear,omitempty"`
+	FilingStatus string                    `json:"filingStatus,omitempty"`
+	Income       *float64                  `json:"income,omitempty"` // AGI, or W-2 wages when no AGI was found
+	Confidence   float64                   `json:"confidence"`
+}
+
+// handleAutoPopulateSimulationParams re-parses a stored tax document and maps
+// it to simulation inputs the advisor can review before running a plan.
+// POST /api/tax/to-simulation-params
+func handleAutoPopulateSimulationParams(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+	if user == nil {
+		respondError(w, http.StatusUnauthorized, "Not authenticated")
+		return
+	}
+
+	var req AutoPopulateParamsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if req.DocumentID <= 0 {
+		respondError(w, http.StatusBadRequest, "documentId is required")
+		return
+	}
+	if req.AgeInTaxYear < 0 || req.AgeInTaxYear > 120 {
+		respondError(w, http.StatusBadRequest, "ageInTaxYear must be between 0 and 120")
+		return
+	}
+
+	var doc models.Document
+	err := db.DB.QueryRow(`
+		SELECT id, user_id, uploaded_by, name, mime_type, storage_path, encrypted
+		FROM documents
+		WHERE id = ? AND deleted_at IS NULL
+	`, req.DocumentID).Scan(&doc.ID, &doc.UserID, &doc.UploadedBy, &doc.Name, &doc.MimeType, &doc.StoragePath, &doc.Encrypted)
+	if err != nil {
+		respondError(w, http.StatusNotFound, "Document not found")
+		return
+	}
+	if !canAccessDocument(user, &doc) {
+		respondError(w, http.StatusForbidden, "Access denied")
+		return
+	}
+	if doc.MimeType != "application/pdf" {
+		respondError(w, http.StatusBadRequest, "Only PDF tax documents are supported")
+		return
+	}
+
+	content, err := storage.DefaultStorage.Load(doc.StoragePath, doc.Encrypted)
+	if err != nil {
+		logger.FromContext(r.Context()).Errorf("Error loading document %d: %v", doc.ID, err)
+		respondError(w, http.StatusInternalServerError, "Failed to load document")
+		return
+	}
+
+	data, err := taxparser.ParsePDFContent(content)
+	if err != nil {
+		logger.FromContext(r.Context()).Errorf("Error parsing tax document %d: %v", doc.ID, err)
+		respondError(w, http.StatusBadRequest, "Failed to read PDF")
+		return
+	}
+	if data.DocumentType == taxparser.DocTypeUnknown {
+		respondError(w, http.StatusUnprocessableEntity, "Document is not a recognized tax form")
+		return
+	}
+
+	params := taxparser.ToSimulationParams(data)
+	if req.AgeInTaxYear > 0 && data.TaxYear > 0 {
+		params.CurrentAge = req.AgeInTaxYear + time.Now().Year() - data.TaxYear
+	}
+
+	resp := AutoPopulateParamsResponse{
+		Params:       params,
+		DocumentType: data.DocumentType,
+		TaxYear:      data.TaxYear,
+		FilingStatus: data.FilingStatus,
+		Confidence:   data.Confidence,
+	}
+	if data.AGI != nil {
+		resp.Income = data.AGI
+	} else {
+		resp.Income = data.WagesTips
+	}
+
+	respondJSON(w, http.StatusOK, resp)
+}
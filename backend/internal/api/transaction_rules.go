@@ -0,0 +1,329 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/finviz/backend/internal/db"
+	"github.com/finviz/backend/internal/logger"
+	"github.com/finviz/backend/internal/models"
+)
+
+// Rules are tried most specific first, then oldest first
+const transactionRuleOrder = `ORDER BY CASE match_operator
+		WHEN 'equals' THEN 0 WHEN 'starts_with' THEN 1 ELSE 2 END, id`
+
+// loadCategorizationRules returns a user's rules in the order they're tried
+func loadCategorizationRules(conn *sql.DB, userID int) ([]models.TransactionRule, error) {
+	rows, err := conn.Query(`
+		SELECT id, user_id, match_field, match_operator, match_value, category, subcategory, created_at
+		FROM transaction_rules WHERE user_id = ?
+		`+transactionRuleOrder, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rules := []models.TransactionRule{}
+	for rows.Next() {
+		var rule models.TransactionRule
+		if err := rows.Scan(&rule.ID, &rule.UserID, &rule.MatchField, &rule.MatchOperator, &rule.MatchValue,
+			&rule.Category, &rule.Subcategory, &rule.CreatedAt); err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, rows.Err()
+}
+
+// applyCategorizationRules sets txn's category from the first of the user's
+// rules that matches it, reporting whether the category changed
+func applyCategorizationRules(conn *sql.DB, userID int, txn *models.Transaction) (bool, error) {
+	rules, err := loadCategorizationRules(conn, userID)
+	if err != nil {
+		return false, err
+	}
+	return applyRules(rules, txn), nil
+}
+
+// applyRules applies the first matching rule from an already loaded list
+func applyRules(rules []models.TransactionRule, txn *models.Transaction) bool {
+	for _, rule := range rules {
+		if !ruleMatches(rule, txn) {
+			continue
+		}
+		if txn.Category != nil && *txn.Category == rule.Category && equalOptional(txn.Subcategory, rule.Subcategory) {
+			return false
+		}
+		category := rule.Category
+		txn.Category = &category
+		txn.Subcategory = rule.Subcategory
+		return true
+	}
+	return false
+}
+
+func ruleMatches(rule models.TransactionRule, txn *models.Transaction) bool {
+	var field string
+	switch rule.MatchField {
+	case models.RuleFieldName:
+		field = txn.Name
+	case models.RuleFieldMerchantName:
+		if txn.MerchantName == nil {
+			return false
+		}
+		field = *txn.MerchantName
+	}
+
+	field = strings.ToLower(field)
+	value := strings.ToLower(rule.MatchValue)
+	switch rule.MatchOperator {
+	case models.RuleOperatorEquals:
+		return field == value
+	case models.RuleOperatorStartsWith:
+		return strings.HasPrefix(field, value)
+	case models.RuleOperatorContains:
+		return strings.Contains(field, value)
+	}
+	return false
+}
+
+func equalOptional(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// recategorizeTransactions applies a user's rules to their Plaid-synced
+// transactions, optionally limited to a date range, and returns how many
+// changed category. Manual and imported transactions carry categories the
+// user chose, so rules never overwrite them.
+func recategorizeTransactions(userID int, startDate, endDate string) (int, error) {
+	rules, err := loadCategorizationRules(db.DB, userID)
+	if err != nil || len(rules) == 0 {
+		return 0, err
+	}
+
+	query := "SELECT id, name, merchant_name, category, subcategory FROM transactions WHERE user_id = ? AND source = 'plaid'"
+	args := []interface{}{userID}
+	if startDate != "" && endDate != "" {
+		query += " AND date >= ? AND date <= ?"
+		args = append(args, startDate, endDate)
+	}
+
+	rows, err := db.DB.Query(query, args...)
+	if err != nil {
+		return 0, err
+	}
+	var changed []models.Transaction
+	for rows.Next() {
+		var t models.Transaction
+		if err := rows.Scan(&t.ID, &t.Name, &t.MerchantName, &t.Category, &t.Subcategory); err != nil {
+			continue
+		}
+		if applyRules(rules, &t) {
+			changed = append(changed, t)
+		}
+	}
+	rows.Close()
+
+	for _, t := range changed {
+		if _, err := db.DB.Exec("UPDATE transactions SET category = ?, subcategory = ? WHERE id = ?",
+			t.Category, t.Subcategory, t.ID); err != nil {
+			return 0, err
+		}
+	}
+	return len(changed), nil
+}
+
+// validateTransactionRule checks a rule request, returning a message for the
+// first problem found
+func validateTransactionRule(req *models.TransactionRuleRequest) string {
+	req.MatchValue = strings.TrimSpace(req.MatchValue)
+	req.Category = strings.TrimSpace(req.Category)
+
+	if req.MatchField != models.RuleFieldName && req.MatchField != models.RuleFieldMerchantName {
+		return "Invalid matchField. Use 'name' or 'merchant_name'"
+	}
+	switch req.MatchOperator {
+	case models.RuleOperatorEquals, models.RuleOperatorStartsWith, models.RuleOperatorContains:
+	default:
+		return "Invalid matchOperator. Use 'contains', 'equals', or 'starts_with'"
+	}
+	if req.MatchValue == "" || len(req.MatchValue) > 255 {
+		return "matchValue is required and must be at most 255 characters"
+	}
+	if req.Category == "" || len(req.Category) > 100 {
+		return "category is required and must be at most 100 characters"
+	}
+	if req.Subcategory != nil && len(*req.Subcategory) > 100 {
+		return "subcategory must be at most 100 characters"
+	}
+	return ""
+}
+
+// handleGetTransactionRules lists the user's rules in the order they're tried
+// GET /api/transaction-rules
+func handleGetTransactionRules(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+	if user == nil {
+		respondError(w, http.StatusUnauthorized, "Not authenticated")
+		return
+	}
+
+	rules, err := loadCategorizationRules(db.DB, user.ID)
+	if err != nil {
+		logger.FromContext(r.Context()).Errorf("Error fetching transaction rules: %v", err)
+		respondError(w, http.StatusInternalServerError, "Failed to fetch rules")
+		return
+	}
+	respondJSON(w, http.StatusOK, rules)
+}
+
+// handleCreateTransactionRule adds a categorization rule. It applies to
+// transactions synced from now on; use the apply endpoint for existing ones.
+// POST /api/transaction-rules
+func handleCreateTransactionRule(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+	if user == nil {
+		respondError(w, http.StatusUnauthorized, "Not authenticated")
+		return
+	}
+
+	var req models.TransactionRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if msg := validateTransactionRule(&req); msg != "" {
+		respondError(w, http.StatusBadRequest, msg)
+		return
+	}
+
+	result, err := db.DB.Exec(`
+		INSERT INTO transaction_rules (user_id, match_field, match_operator, match_value, category, subcategory)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, user.ID, req.MatchField, req.MatchOperator, req.MatchValue, req.Category, req.Subcategory)
+	if err != nil {
+		logger.FromContext(r.Context()).Errorf("Error creating transaction rule: %v", err)
+		respondError(w, http.StatusInternalServerError, "Failed to create rule")
+		return
+	}
+	ruleID, _ := result.LastInsertId()
+
+	rule, err := getTransactionRule(int(ruleID), user.ID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch rule")
+		return
+	}
+	respondJSON(w, http.StatusCreated, rule)
+}
+
+// handleUpdateTransactionRule replaces a rule's match and category
+// PUT /api/transaction-rules/{id}
+func handleUpdateTransactionRule(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+	if user == nil {
+		respondError(w, http.StatusUnauthorized, "Not authenticated")
+		return
+	}
+
+	ruleID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid rule ID")
+		return
+	}
+
+	var req models.TransactionRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if msg := validateTransactionRule(&req); msg != "" {
+		respondError(w, http.StatusBadRequest, msg)
+		return
+	}
+
+	if _, err := getTransactionRule(ruleID, user.ID); err != nil {
+		respondError(w, http.StatusNotFound, "Rule not found")
+		return
+	}
+
+	_, err = db.DB.Exec(`
+		UPDATE transaction_rules
+		SET match_field = ?, match_operator = ?, match_value = ?, category = ?, subcategory = ?
+		WHERE id = ? AND user_id = ?
+	`, req.MatchField, req.MatchOperator, req.MatchValue, req.Category, req.Subcategory, ruleID, user.ID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to update rule")
+		return
+	}
+
+	rule, err := getTransactionRule(ruleID, user.ID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to fetch rule")
+		return
+	}
+	respondJSON(w, http.StatusOK, rule)
+}
+
+// handleDeleteTransactionRule removes a rule. Categories it already set are kept.
+// DELETE /api/transaction-rules/{id}
+func handleDeleteTransactionRule(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+	if user == nil {
+		respondError(w, http.StatusUnauthorized, "Not authenticated")
+		return
+	}
+
+	ruleID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid rule ID")
+		return
+	}
+
+	result, err := db.DB.Exec("DELETE FROM transaction_rules WHERE id = ? AND user_id = ?", ruleID, user.ID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to delete rule")
+		return
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		respondError(w, http.StatusNotFound, "Rule not found")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleReCategorizeTransactions applies the user's rules to every
+// Plaid-synced transaction
+// POST /api/transaction-rules/apply
+func handleReCategorizeTransactions(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r)
+	if user == nil {
+		respondError(w, http.StatusUnauthorized, "Not authenticated")
+		return
+	}
+
+	count, err := recategorizeTransactions(user.ID, "", "")
+	if err != nil {
+		logger.FromContext(r.Context()).Errorf("Error recategorizing transactions for user %d: %v", user.ID, err)
+		respondError(w, http.StatusInternalServerError, "Failed to apply rules")
+		return
+	}
+	respondJSON(w, http.StatusOK, models.RecategorizeResponse{Recategorized: count})
+}
+
+func getTransactionRule(ruleID, userID int) (models.TransactionRule, error) {
+	var rule models.TransactionRule
+	err := db.DB.QueryRow(`
+		SELECT id, user_id, match_field, match_operator, match_value, category, subcategory, created_at
+		FROM transaction_rules WHERE id = ? AND user_id = ?
+	`, ruleID, userID).Scan(&rule.ID, &rule.UserID, &rule.MatchField, &rule.MatchOperator, &rule.MatchValue,
+		&rule.Category, &rule.Subcategory, &rule.CreatedAt)
+	return rule, err
+}
@@ -3,15 +3,35 @@ package api
 import (
 	"database/sql"
 	"encoding/json"
-	"fmt"
+	"math"
 	"net/http"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/finviz/backend/internal/auth"
+	"github.com/finviz/backend/internal/classifier"
 	"github.com/finviz/backend/internal/db"
+	"github.com/finviz/backend/internal/logger"
 	"github.com/finviz/backend/internal/models"
+	"github.com/finviz/backend/internal/plaid"
 )
 
-// handleGetTransactions returns transactions for the authenticated user
+// Transaction list page sizes
+const (
+	defaultTransactionPageSize = 50
+	maxTransactionPageSize     = 200
+)
+
+// transactionCursor is the position after the last row of a transaction page
+type transactionCursor struct {
+	Date string `json:"d"` // YYYY-MM-DD
+	ID   int    `json:"id"`
+}
+
+// handleGetTransactions returns a page of transactions for the authenticated
+// user, newest first. Pass the returned nextCursor as ?cursor= for the next page.
 func handleGetTransactions(w http.ResponseWriter, r *http.Request) {
 	user := getUserFromContext(r)
 	if user == nil {
@@ -23,9 +43,10 @@ func handleGetTransactions(w http.ResponseWriter, r *http.Request) {
 	userID := getEffectiveUserID(r)
 
 	// Get query params for filtering
-	startDate := r.URL.Query().Get("start_date")
-	endDate := r.URL.Query().Get("end_date")
-	category := r.URL.Query().Get("category")
+	q := r.URL.Query()
+	startDate := q.Get("start_date")
+	endDate := q.Get("end_date")
+	category := q.Get("category")
 
 	// Default to last 30 days if no dates provided
 	if startDate == "" {
@@ -35,23 +56,96 @@ func handleGetTransactions(w http.ResponseWriter, r *http.Request) {
 		endDate = time.Now().Format("2006-01-02")
 	}
 
-	query := `
-		SELECT id, user_id, plaid_transaction_id, plaid_account_id, account_name, amount, date,
-		       name, merchant_name, category, subcategory, pending, transaction_type, iso_currency_code,
-		       created_at, updated_at
-		FROM transactions
-		WHERE user_id = ? AND date >= ? AND date <= ?
-	`
+	// Build dynamic WHERE clause
+	// user_id + date range lead so MySQL can use idx_user_date (user_id, date)
+	conditions := []string{"user_id = ?", "date >= ?", "date <= ?", "deleted_at IS NULL"}
 	args := []interface{}{userID, startDate, endDate}
 
 	if category != "" {
-		query += " AND category = ?"
+		conditions = append(conditions, "category = ?")
 		args = append(args, category)
 	}
+	if search := strings.TrimSpace(q.Get("q")); search != "" {
+		// Case-insensitive match on name or merchant
+		conditions = append(conditions, "(LOWER(name) LIKE ? OR LOWER(merchant_name) LIKE ?)")
+		pattern := "%" + strings.ToLower(search) + "%"
+		args = append(args, pattern, pattern)
+	}
+	if minStr := q.Get("min_amount"); minStr != "" {
+		minAmount, err := strconv.ParseFloat(minStr, 64)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "Invalid min_amount")
+			return
+		}
+		conditions = append(conditions, "amount >= ?")
+		args = append(args, minAmount)
+	}
+	if maxStr := q.Get("max_amount"); maxStr != "" {
+		maxAmount, err := strconv.ParseFloat(maxStr, 64)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "Invalid max_amount")
+			return
+		}
+		conditions = append(conditions, "amount <= ?")
+		args = append(args, maxAmount)
+	}
+	if accountName := q.Get("account_name"); accountName != "" {
+		conditions = append(conditions, "account_name = ?")
+		args = append(args, accountName)
+	}
+	if pendingStr := q.Get("pending"); pendingStr != "" {
+		pending, err := strconv.ParseBool(pendingStr)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "Invalid pending value. Must be 'true' or 'false'")
+			return
+		}
+		conditions = append(conditions, "pending = ?")
+		args = append(args, pending)
+	}
 
-	query += " ORDER BY date DESC, id DESC"
+	limit := defaultTransactionPageSize
+	if limitStr := q.Get("limit"); limitStr != "" {
+		l, err := strconv.Atoi(limitStr)
+		if err != nil || l <= 0 {
+			respondError(w, http.StatusBadRequest, "Invalid limit")
+			return
+		}
+		limit = l
+	}
+	if limit > maxTransactionPageSize {
+		limit = maxTransactionPageSize
+	}
 
-	rows, err := db.DB.Query(query, args...)
+	// Total matching rows across all pages
+	var totalCount int
+	if err := db.DB.QueryRow("SELECT COUNT(*) FROM transactions WHERE "+strings.Join(conditions, " AND "), args...).Scan(&totalCount); err != nil {
+		respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.Header().Set("X-Total-Count", strconv.Itoa(totalCount))
+
+	// Keyset condition continues strictly after the last row of the previous
+	// page, i.e. (date, id) < (cursor date, cursor id)
+	if v := q.Get("cursor"); v != "" {
+		var cursor transactionCursor
+		if err := auth.DecodeCursor(v, &cursor); err != nil || cursor.Date == "" {
+			respondError(w, http.StatusBadRequest, "Invalid cursor")
+			return
+		}
+		conditions = append(conditions, "(date < ? OR (date = ? AND id < ?))")
+		args = append(args, cursor.Date, cursor.Date, cursor.ID)
+	}
+
+	// Fetch one extra row to know whether another page exists
+	args = append(args, limit+1)
+	rows, err := db.DB.Query(`
+		SELECT id, user_id, plaid_transaction_id, plaid_account_id, account_name, amount, date,
+		       name, merchant_name, category, subcategory, pending, transaction_type, iso_currency_code,
+		       source, created_at, updated_at
+		FROM transactions
+		WHERE `+strings.Join(conditions, " AND ")+`
+		ORDER BY date DESC, id DESC
+		LIMIT ?`, args...)
 	if err != nil {
 		respondError(w, http.StatusInternalServerError, err.Error())
 		return
@@ -66,7 +160,7 @@ func handleGetTransactions(w http.ResponseWriter, r *http.Request) {
 		if err := rows.Scan(
 			&t.ID, &t.UserID, &plaidTxnID, &plaidAcctID, &accountName, &t.Amount, &t.Date,
 			&t.Name, &merchantName, &category, &subcategory, &t.Pending, &txnType, &currency,
-			&t.CreatedAt, &t.UpdatedAt,
+			&t.Source, &t.CreatedAt, &t.UpdatedAt,
 		); err != nil {
 			respondError(w, http.StatusInternalServerError, err.Error())
 			return
@@ -97,14 +191,39 @@ func handleGetTransactions(w http.ResponseWriter, r *http.Request) {
 			t.ISOCurrencyCode = &currency.String
 		}
 
+		isIncome, confidence := classifier.ClassifyTransaction(t)
+		t.IsIncome = isIncome
+		t.NeedsReview = classifier.NeedsReview(confidence)
+
 		transactions = append(transactions, t)
 	}
 
-	if transactions == nil {
-		transactions = []models.Transaction{}
+	page := models.TransactionPage{Items: transactions}
+	if page.Items == nil {
+		page.Items = []models.Transaction{}
+	}
+	if len(transactions) > limit {
+		page.Items = transactions[:limit]
+		page.HasMore = true
+		last := page.Items[limit-1]
+		next, err := auth.EncodeCursor(transactionCursor{Date: dateOnly(last.Date), ID: last.ID})
+		if err != nil {
+			respondError(w, http.StatusInternalServerError, "Failed to build cursor")
+			return
+		}
+		page.NextCursor = next
 	}
 
-	respondJSON(w, http.StatusOK, transactions)
+	respondJSON(w, http.StatusOK, page)
+}
+
+// dateOnly trims a scanned DATE value, which parseTime renders as an
+// RFC 3339 timestamp, to YYYY-MM-DD
+func dateOnly(date string) string {
+	if len(date) > 10 {
+		return date[:10]
+	}
+	return date
 }
 
 // handleGetTransactionSummary returns aggregated transaction data
@@ -130,105 +249,81 @@ func handleGetTransactionSummary(w http.ResponseWriter, r *http.Request) {
 		endDate = time.Now().Format("2006-01-02")
 	}
 
-	var summary models.TransactionSummary
-
-	// Get total income (negative amounts in Plaid = money coming in)
-	// Also include INCOME and TRANSFER_IN categories regardless of amount sign (in case of data issues)
-	err := db.DB.QueryRow(`
-		SELECT COALESCE(SUM(ABS(amount)), 0) FROM transactions
-		WHERE user_id = ? AND date >= ? AND date <= ? AND pending = FALSE
-		AND (
-			amount < 0
-			OR category IN ('INCOME', 'INCOME_WAGES', 'INCOME_DIVIDENDS', 'INCOME_INTEREST', 'TRANSFER_IN')
-			OR subcategory LIKE 'INCOME%'
-			OR subcategory LIKE 'TRANSFER_IN%'
-		)
-	`, userID, startDate, endDate).Scan(&summary.TotalIncome)
-	if err != nil {
-		respondError(w, http.StatusInternalServerError, err.Error())
-		return
-	}
-
-	// Get total expenses (positive amounts in Plaid = money going out)
-	// Exclude income categories that might be miscategorized
-	err = db.DB.QueryRow(`
-		SELECT COALESCE(SUM(amount), 0) FROM transactions
-		WHERE user_id = ? AND date >= ? AND date <= ? AND amount > 0 AND pending = FALSE
-		AND category NOT IN ('INCOME', 'INCOME_WAGES', 'INCOME_DIVIDENDS', 'INCOME_INTEREST', 'TRANSFER_IN')
-		AND (subcategory IS NULL OR (subcategory NOT LIKE 'INCOME%' AND subcategory NOT LIKE 'TRANSFER_IN%'))
-	`, userID, startDate, endDate).Scan(&summary.TotalExpenses)
-	if err != nil {
-		respondError(w, http.StatusInternalServerError, err.Error())
-		return
-	}
-
-	summary.NetCashFlow = summary.TotalIncome - summary.TotalExpenses
-
-	// Get spending by category (only expenses, excluding income categories)
-	catRows, err := db.DB.Query(`
-		SELECT COALESCE(category, 'Uncategorized') as cat, SUM(amount) as total, COUNT(*) as cnt
+	// Income/expense classification happens in Go (see classifier) so every
+	// view of cash flow agrees on which transactions are income
+	rows, err := db.DB.Query(`
+		SELECT amount, date, name, merchant_name, category, subcategory
 		FROM transactions
-		WHERE user_id = ? AND date >= ? AND date <= ? AND amount > 0 AND pending = FALSE
-		AND category NOT IN ('INCOME', 'INCOME_WAGES', 'INCOME_DIVIDENDS', 'INCOME_INTEREST', 'TRANSFER_IN')
-		AND (subcategory IS NULL OR (subcategory NOT LIKE 'INCOME%' AND subcategory NOT LIKE 'TRANSFER_IN%'))
-		GROUP BY category
-		ORDER BY total DESC
+		WHERE user_id = ? AND deleted_at IS NULL AND date >= ? AND date <= ? AND pending = FALSE
+		ORDER BY date
 	`, userID, startDate, endDate)
 	if err != nil {
 		respondError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
-	defer catRows.Close()
+	defer rows.Close()
 
-	for catRows.Next() {
-		var cs models.CategorySummary
-		if err := catRows.Scan(&cs.Category, &cs.Amount, &cs.Count); err != nil {
+	var summary models.TransactionSummary
+	byCategory := make(map[string]*models.CategorySummary)
+	var byMonth []models.MonthSummary
+
+	for rows.Next() {
+		var t models.Transaction
+		if err := rows.Scan(&t.Amount, &t.Date, &t.Name, &t.MerchantName, &t.Category, &t.Subcategory); err != nil {
 			continue
 		}
-		summary.ByCategory = append(summary.ByCategory, cs)
-	}
-
-	if summary.ByCategory == nil {
-		summary.ByCategory = []models.CategorySummary{}
-	}
-
-	// Get monthly breakdown with proper income/expense classification
-	monthRows, err := db.DB.Query(`
-		SELECT
-			DATE_FORMAT(date, '%Y-%m') as month,
-			COALESCE(SUM(CASE
-				WHEN amount < 0 THEN ABS(amount)
-				WHEN category IN ('INCOME', 'INCOME_WAGES', 'INCOME_DIVIDENDS', 'INCOME_INTEREST', 'TRANSFER_IN') THEN ABS(amount)
-				WHEN subcategory LIKE 'INCOME%' OR subcategory LIKE 'TRANSFER_IN%' THEN ABS(amount)
-				ELSE 0
-			END), 0) as income,
-			COALESCE(SUM(CASE
-				WHEN amount > 0
-				AND category NOT IN ('INCOME', 'INCOME_WAGES', 'INCOME_DIVIDENDS', 'INCOME_INTEREST', 'TRANSFER_IN')
-				AND (subcategory IS NULL OR (subcategory NOT LIKE 'INCOME%' AND subcategory NOT LIKE 'TRANSFER_IN%'))
-				THEN amount
-				ELSE 0
-			END), 0) as expenses
-		FROM transactions
-		WHERE user_id = ? AND date >= ? AND date <= ? AND pending = FALSE
-		GROUP BY DATE_FORMAT(date, '%Y-%m')
-		ORDER BY month
-	`, userID, startDate, endDate)
-	if err != nil {
-		respondError(w, http.StatusInternalServerError, err.Error())
-		return
-	}
-	defer monthRows.Close()
 
-	for monthRows.Next() {
-		var ms models.MonthSummary
-		if err := monthRows.Scan(&ms.Month, &ms.Income, &ms.Expenses); err != nil {
+		isIncome, confidence := classifier.ClassifyTransaction(t)
+		if classifier.NeedsReview(confidence) {
+			summary.UnclassifiedCount++
+		}
+
+		// Rows are date-ordered, so months arrive in sequence
+		month := t.Date[:7]
+		if len(byMonth) == 0 || byMonth[len(byMonth)-1].Month != month {
+			byMonth = append(byMonth, models.MonthSummary{Month: month})
+		}
+		ms := &byMonth[len(byMonth)-1]
+
+		if isIncome {
+			summary.TotalIncome += math.Abs(t.Amount)
+			ms.Income += math.Abs(t.Amount)
 			continue
 		}
-		ms.Net = ms.Income - ms.Expenses
-		summary.ByMonth = append(summary.ByMonth, ms)
+		if t.Amount <= 0 {
+			continue // negative amounts that aren't income (e.g. refunds) don't count as spending
+		}
+
+		summary.TotalExpenses += t.Amount
+		ms.Expenses += t.Amount
+
+		category := "Uncategorized"
+		if t.Category != nil && *t.Category != "" {
+			category = *t.Category
+		}
+		cs, ok := byCategory[category]
+		if !ok {
+			cs = &models.CategorySummary{Category: category}
+			byCategory[category] = cs
+		}
+		cs.Amount += t.Amount
+		cs.Count++
+	}
+
+	summary.NetCashFlow = summary.TotalIncome - summary.TotalExpenses
+
+	summary.ByCategory = []models.CategorySummary{}
+	for _, cs := range byCategory {
+		summary.ByCategory = append(summary.ByCategory, *cs)
 	}
+	sort.Slice(summary.ByCategory, func(i, j int) bool {
+		return summary.ByCategory[i].Amount > summary.ByCategory[j].Amount
+	})
 
+	for i := range byMonth {
+		byMonth[i].Net = byMonth[i].Income - byMonth[i].Expenses
+	}
+	summary.ByMonth = byMonth
 	if summary.ByMonth == nil {
 		summary.ByMonth = []models.MonthSummary{}
 	}
@@ -299,7 +394,8 @@ func handleSyncTransactions(w http.ResponseWriter, r *http.Request) {
 		// Get transactions from Plaid
 		txnResp, err := plaidClient.GetTransactions(accessToken, startDate, endDate)
 		if err != nil {
-			fmt.Printf("Error getting transactions for item %d: %v\n", itemID, err)
+			logger.FromContext(r.Context()).Errorf("Error getting transactions for item %d: %v", itemID, err)
+			recordPlaidItemError(itemID, err)
 			continue
 		}
 
@@ -308,52 +404,68 @@ func handleSyncTransactions(w http.ResponseWriter, r *http.Request) {
 			accountMap[acc.AccountID] = acc.Name
 		}
 
-		// Process transactions
-		for _, txn := range txnResp.Transactions {
-			// Determine category
-			var category, subcategory string
-			if txn.PersonalFinanceCat != nil {
-				category = txn.PersonalFinanceCat.Primary
-				subcategory = txn.PersonalFinanceCat.Detailed
-			} else if len(txn.Category) > 0 {
-				category = txn.Category[0]
-				if len(txn.Category) > 1 {
-					subcategory = txn.Category[1]
-				}
-			}
+		created, updated := upsertPlaidTransactions(user.ID, txnResp.Transactions, accountMap)
+		result.NewTransactions += created
+		result.UpdatedTransactions += updated
+	}
 
-			accountName := accountMap[txn.AccountID]
-
-			// Try to insert, update if exists
-			res, err := db.DB.Exec(`
-				INSERT INTO transactions (user_id, plaid_transaction_id, plaid_account_id, account_name, amount, date, name, merchant_name, category, subcategory, pending, transaction_type, iso_currency_code)
-				VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-				ON DUPLICATE KEY UPDATE
-					amount = VALUES(amount),
-					name = VALUES(name),
-					merchant_name = VALUES(merchant_name),
-					category = VALUES(category),
-					subcategory = VALUES(subcategory),
-					pending = VALUES(pending),
-					updated_at = NOW()
-			`, user.ID, txn.TransactionID, txn.AccountID, accountName, txn.Amount, txn.Date, txn.Name,
-				txn.MerchantName, category, subcategory, txn.Pending, txn.TransactionType, txn.ISOCurrencyCode)
-
-			if err != nil {
-				fmt.Printf("Error inserting transaction %s: %v\n", txn.TransactionID, err)
-				continue
-			}
+	// The upsert restores Plaid's categories, so re-apply the user's rules
+	recategorized, err := recategorizeTransactions(user.ID, startDate, endDate)
+	if err != nil {
+		logger.FromContext(r.Context()).Errorf("Error applying categorization rules for user %d: %v", user.ID, err)
+	}
+	result.RecategorizedTransactions = recategorized
 
-			rowsAffected, _ := res.RowsAffected()
-			if rowsAffected == 1 {
-				result.NewTransactions++
-			} else {
-				result.UpdatedTransactions++
+	respondJSON(w, http.StatusOK, result)
+}
+
+// upsertPlaidTransactions inserts Plaid transactions for a user, updating any
+// already stored, and returns how many were new and how many were updated
+func upsertPlaidTransactions(userID int, txns []plaid.Transaction, accountMap map[string]string) (created, updated int) {
+	for _, txn := range txns {
+		// Determine category
+		var category, subcategory string
+		if txn.PersonalFinanceCat != nil {
+			category = txn.PersonalFinanceCat.Primary
+			subcategory = txn.PersonalFinanceCat.Detailed
+		} else if len(txn.Category) > 0 {
+			category = txn.Category[0]
+			if len(txn.Category) > 1 {
+				subcategory = txn.Category[1]
 			}
 		}
-	}
 
-	respondJSON(w, http.StatusOK, result)
+		accountName := accountMap[txn.AccountID]
+
+		// Try to insert, update if exists
+		res, err := db.DB.Exec(`
+			INSERT INTO transactions (user_id, plaid_transaction_id, plaid_account_id, account_name, amount, date, name, merchant_name, category, subcategory, pending, transaction_type, iso_currency_code, source)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			ON DUPLICATE KEY UPDATE
+				amount = VALUES(amount),
+				name = VALUES(name),
+				merchant_name = VALUES(merchant_name),
+				category = VALUES(category),
+				subcategory = VALUES(subcategory),
+				pending = VALUES(pending),
+				updated_at = NOW()
+		`, userID, txn.TransactionID, txn.AccountID, accountName, txn.Amount, txn.Date, txn.Name,
+			txn.MerchantName, category, subcategory, txn.Pending, txn.TransactionType, txn.ISOCurrencyCode,
+			models.TransactionSourcePlaid)
+
+		if err != nil {
+			logger.Default().Errorf("Error inserting transaction %s: %v", txn.TransactionID, err)
+			continue
+		}
+
+		rowsAffected, _ := res.RowsAffected()
+		if rowsAffected == 1 {
+			created++
+		} else {
+			updated++
+		}
+	}
+	return created, updated
 }
 
 // handleGetTransactionDebug returns transaction statistics for debugging
@@ -385,7 +497,7 @@ func handleGetTransactionDebug(w http.ResponseWriter, r *http.Request) {
 	// Get all transactions (no date filter)
 	rows, err := db.DB.Query(`
 		SELECT amount, pending, COALESCE(category, 'NULL') as cat, name, date
-		FROM transactions WHERE user_id = ?
+		FROM transactions WHERE user_id = ? AND deleted_at IS NULL
 		ORDER BY date DESC
 	`, userID)
 	if err != nil {
@@ -448,7 +560,7 @@ func handleGetCategories(w http.ResponseWriter, r *http.Request) {
 	rows, err := db.DB.Query(`
 		SELECT DISTINCT COALESCE(category, 'Uncategorized') as cat
 		FROM transactions
-		WHERE user_id = ?
+		WHERE user_id = ? AND deleted_at IS NULL
 		ORDER BY cat
 	`, userID)
 	if err != nil {
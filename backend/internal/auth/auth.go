@@ -6,7 +6,9 @@ import (
 	"errors"
 	"os"
 	"strconv"
+	"strings"
 	"time"
+	"unicode"
 
 	"golang.org/x/crypto/bcrypt"
 )
@@ -15,6 +17,7 @@ var (
 	ErrInvalidCredentials = errors.New("invalid credentials")
 	ErrUserExists         = errors.New("user already exists")
 	ErrInvalidToken       = errors.New("invalid token")
+	ErrWeakPassword       = errors.New("password must be at least 8 characters and include a letter and a number")
 )
 
 var jwtSecret []byte
@@ -46,6 +49,28 @@ func CheckPassword(password, hash string) bool {
 	return err == nil
 }
 
+// ValidatePasswordStrength checks that a password meets the minimum requirements
+func ValidatePasswordStrength(password string) error {
+	if len(password) < 8 {
+		return ErrWeakPassword
+	}
+
+	var hasLetter, hasDigit bool
+	for _, c := range password {
+		switch {
+		case unicode.IsLetter(c):
+			hasLetter = true
+		case unicode.IsDigit(c):
+			hasDigit = true
+		}
+	}
+	if !hasLetter || !hasDigit {
+		return ErrWeakPassword
+	}
+
+	return nil
+}
+
 // Token represents a simple JWT-like token structure
 type Token struct {
 	UserID    int
@@ -53,11 +78,17 @@ type Token struct {
 	ExpiresAt time.Time
 }
 
-// GenerateToken creates a simple base64 encoded token
+// Token lifetimes. Access tokens are short-lived; clients renew them with a
+// refresh token, which is rotated on every use.
+const (
+	AccessTokenTTL  = 15 * time.Minute
+	RefreshTokenTTL = 30 * 24 * time.Hour
+)
+
+// GenerateToken creates a simple base64 encoded access token
 // In production, use a proper JWT library
 func GenerateToken(userID int, email string) (string, error) {
-	// Create expiration time (24 hours from now)
-	expiresAt := time.Now().Add(24 * time.Hour)
+	expiresAt := time.Now().Add(AccessTokenTTL)
 
 	// Simple token format: userID:email:expiry:signature
 	tokenData := []byte(encodeTokenData(userID, email, expiresAt))
@@ -106,6 +137,52 @@ func ValidateToken(tokenString string) (*Token, error) {
 	return token, nil
 }
 
+// mfaTokenPrefix marks partial tokens issued between the password and second
+// factor steps of login. The prefix makes decodeTokenData reject them, so
+// they can't be used as full session tokens.
+const mfaTokenPrefix = "mfa|"
+
+// GenerateMFAToken creates a short-lived partial token that only allows the
+// second factor to be submitted
+func GenerateMFAToken(userID int, email string) (string, error) {
+	expiresAt := time.Now().Add(5 * time.Minute)
+	tokenData := []byte(mfaTokenPrefix + encodeTokenData(userID, email, expiresAt))
+	combined := append(tokenData, createHMAC(tokenData)...)
+	return base64.URLEncoding.EncodeToString(combined), nil
+}
+
+// ValidateMFAToken validates a partial token from GenerateMFAToken
+func ValidateMFAToken(tokenString string) (*Token, error) {
+	combined, err := base64.URLEncoding.DecodeString(tokenString)
+	if err != nil || len(combined) < 32 {
+		return nil, ErrInvalidToken
+	}
+
+	tokenData := combined[:len(combined)-32]
+	if !hmacEqual(combined[len(combined)-32:], createHMAC(tokenData)) {
+		return nil, ErrInvalidToken
+	}
+
+	data, ok := strings.CutPrefix(string(tokenData), mfaTokenPrefix)
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+	token, err := decodeTokenData(data)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	if time.Now().After(token.ExpiresAt) {
+		return nil, ErrInvalidToken
+	}
+	return token, nil
+}
+
+// HashMFAToken returns the hex SHA-256 of a partial token, so its attempts
+// can be tracked without storing the token itself
+func HashMFAToken(token string) string {
+	return HashRefreshToken(token)
+}
+
 // Helper functions for simple token encoding
 func encodeTokenData(userID int, email string, expiresAt time.Time) string {
 	return strconv.Itoa(userID) + ":" + email + ":" + expiresAt.Format(time.RFC3339)
@@ -0,0 +1,77 @@
+package auth
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+)
+
+// ErrInvalidCursor is returned when a pagination cursor can't be decrypted or decoded
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// cursorKey derives a separate AES-256 key for pagination cursors from the token secret
+func cursorKey() []byte {
+	key := sha256.Sum256(append([]byte("pagination-cursor:"), jwtSecret...))
+	return key[:]
+}
+
+// EncodeCursor encrypts a pagination position into an opaque URL-safe string
+func EncodeCursor(v interface{}) (string, error) {
+	plaintext, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+
+	block, err := aes.NewCipher(cursorKey())
+	if err != nil {
+		return "", err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+
+	sealed := gcm.Seal(nonce, nonce, plaintext, nil)
+	return base64.RawURLEncoding.EncodeToString(sealed), nil
+}
+
+// DecodeCursor decrypts a cursor produced by EncodeCursor into v
+func DecodeCursor(cursor string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return ErrInvalidCursor
+	}
+
+	block, err := aes.NewCipher(cursorKey())
+	if err != nil {
+		return err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return err
+	}
+
+	if len(data) < gcm.NonceSize() {
+		return ErrInvalidCursor
+	}
+	nonce, ciphertext := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return ErrInvalidCursor
+	}
+
+	if err := json.Unmarshal(plaintext, v); err != nil {
+		return ErrInvalidCursor
+	}
+	return nil
+}
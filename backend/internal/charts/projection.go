@@ -0,0 +1,154 @@
+package charts
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image/color"
+
+	"github.com/finviz/backend/internal/models"
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+)
+
+// ErrNoProjectionData is returned when there are no projections to plot
+var ErrNoProjectionData = errors.New("no projection data")
+
+// Series colors match the report's color palette
+var (
+	p10Color        = color.RGBA{R: 200, G: 50, B: 50, A: 255}
+	p50Color        = color.RGBA{R: 0, G: 82, B: 147, A: 255}
+	p90Color        = color.RGBA{R: 0, G: 150, B: 100, A: 255}
+	retirementColor = color.RGBA{R: 100, G: 100, B: 100, A: 255}
+)
+
+// GenerateProjectionChart draws a P10/P50/P90 line chart of yearly projections
+// and returns it as PNG bytes. A dashed vertical line marks the first year of
+// the distribution phase, if any.
+func GenerateProjectionChart(projections []models.YearProjection) ([]byte, error) {
+	if len(projections) == 0 {
+		return nil, ErrNoProjectionData
+	}
+
+	p := plot.New()
+	p.Title.Text = "Net Worth Projection"
+	p.X.Label.Text = "Year"
+	p.Y.Label.Text = "Net Worth"
+	p.Y.Tick.Marker = currencyTicks{}
+	p.Legend.Top = true
+	p.Legend.Left = true
+
+	p10 := make(plotter.XYs, len(projections))
+	p50 := make(plotter.XYs, len(projections))
+	p90 := make(plotter.XYs, len(projections))
+	maxValue := 0.0
+	minValue := 0.0
+	retirementYear := 0
+
+	for i, proj := range projections {
+		x := float64(proj.Year)
+		p10[i] = plotter.XY{X: x, Y: proj.P10}
+		p50[i] = plotter.XY{X: x, Y: proj.P50}
+		p90[i] = plotter.XY{X: x, Y: proj.P90}
+
+		if proj.P90 > maxValue {
+			maxValue = proj.P90
+		}
+		if proj.P10 < minValue {
+			minValue = proj.P10
+		}
+		if retirementYear == 0 && proj.Phase == "distribution" {
+			retirementYear = proj.Year
+		}
+	}
+
+	series := []struct {
+		name  string
+		data  plotter.XYs
+		color color.Color
+	}{
+		{"Optimistic (P90)", p90, p90Color},
+		{"Median (P50)", p50, p50Color},
+		{"Conservative (P10)", p10, p10Color},
+	}
+
+	for _, s := range series {
+		line, err := plotter.NewLine(s.data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create %s series: %w", s.name, err)
+		}
+		line.Color = s.color
+		line.Width = vg.Points(1.5)
+		p.Add(line)
+		p.Legend.Add(s.name, line)
+	}
+
+	// Mark the retirement boundary (skipped for accumulation-only or already-retired runs)
+	if retirementYear > projections[0].Year {
+		x := float64(retirementYear)
+		boundary, err := plotter.NewLine(plotter.XYs{{X: x, Y: minValue}, {X: x, Y: maxValue}})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create retirement marker: %w", err)
+		}
+		boundary.Color = retirementColor
+		boundary.Dashes = []vg.Length{vg.Points(4), vg.Points(4)}
+		p.Add(boundary)
+
+		labels, err := plotter.NewLabels(plotter.XYLabels{
+			XYs:    []plotter.XY{{X: x, Y: maxValue}},
+			Labels: []string{"Retirement"},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create retirement label: %w", err)
+		}
+		for i := range labels.TextStyle {
+			labels.TextStyle[i].Color = retirementColor
+			labels.TextStyle[i].XAlign = draw.XCenter
+		}
+		labels.Offset = vg.Point{Y: vg.Points(4)}
+		p.Add(labels)
+	}
+
+	writer, err := p.WriterTo(7*vg.Inch, 3.5*vg.Inch, "png")
+	if err != nil {
+		return nil, fmt.Errorf("failed to render chart: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if _, err := writer.WriteTo(&buf); err != nil {
+		return nil, fmt.Errorf("failed to encode chart: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// currencyTicks formats Y-axis ticks as abbreviated dollar amounts
+type currencyTicks struct{}
+
+func (currencyTicks) Ticks(min, max float64) []plot.Tick {
+	ticks := plot.DefaultTicks{}.Ticks(min, max)
+	for i := range ticks {
+		if ticks[i].Label != "" {
+			ticks[i].Label = formatAxisCurrency(ticks[i].Value)
+		}
+	}
+	return ticks
+}
+
+// formatAxisCurrency formats a value as a compact currency label
+func formatAxisCurrency(amount float64) string {
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	if amount >= 1000000 {
+		return fmt.Sprintf("%s$%.1fM", sign, amount/1000000)
+	}
+	if amount >= 1000 {
+		return fmt.Sprintf("%s$%.0fK", sign, amount/1000)
+	}
+	return fmt.Sprintf("%s$%.0f", sign, amount)
+}
@@ -0,0 +1,120 @@
+package classifier
+
+import (
+	"math"
+	"strings"
+
+	"github.com/finviz/backend/internal/models"
+)
+
+// MinConfidence is the confidence below which a transaction is left
+// unclassified and surfaced for user review
+const MinConfidence = 0.5
+
+// Signal weights. Positive evidence points to income, negative to expense.
+// The amount sign alone is enough for a confident call.
+const (
+	weightAmountSign  = 0.5
+	weightCategory    = 0.3
+	weightMerchant    = 0.2
+	weightSubcategory = 0.1
+)
+
+// incomeCategories are Plaid primary categories that mean money coming in
+var incomeCategories = map[string]bool{
+	"INCOME":           true,
+	"INCOME_WAGES":     true,
+	"INCOME_DIVIDENDS": true,
+	"INCOME_INTEREST":  true,
+	"TRANSFER_IN":      true,
+}
+
+// incomeSubcategoryPrefixes match Plaid detailed categories for money coming in
+var incomeSubcategoryPrefixes = []string{"INCOME", "TRANSFER_IN"}
+
+// incomeKeywords in a merchant or transaction name suggest a deposit
+var incomeKeywords = []string{
+	"payroll", "salary", "direct dep", "paycheck", "dividend",
+	"interest paid", "interest earned", "refund", "reimbursement", "deposit",
+}
+
+// ClassifyTransaction decides whether a transaction is income, and how sure
+// that call is. A transaction is income when its amount is negative (Plaid
+// convention: money in) or its category or detailed category is an income
+// one, whatever the sign, in case of data issues.
+//
+// Confidence is the net evidence for that call, from 0 to 1. Each signal
+// adds evidence for income or expense:
+//   - amount sign: ±0.5
+//   - primary category is or isn't an income category: ±0.3
+//   - merchant or transaction name has an income keyword: +0.2
+//   - detailed category is or isn't an income subcategory: ±0.1
+//
+// Below MinConfidence, when the signals disagree, the transaction should be
+// treated as unclassified.
+func ClassifyTransaction(txn models.Transaction) (isIncome bool, confidence float64) {
+	category := strings.ToUpper(stringValue(txn.Category))
+	subcategory := strings.ToUpper(stringValue(txn.Subcategory))
+	incomeCategory := incomeCategories[category]
+	incomeSubcategory := hasAnyPrefix(subcategory, incomeSubcategoryPrefixes)
+
+	isIncome = txn.Amount < 0 || incomeCategory || incomeSubcategory
+
+	score := 0.0
+	switch {
+	case txn.Amount < 0:
+		score += weightAmountSign
+	case txn.Amount > 0:
+		score -= weightAmountSign
+	}
+
+	if category != "" {
+		if incomeCategory {
+			score += weightCategory
+		} else {
+			score -= weightCategory
+		}
+	}
+
+	name := strings.ToLower(stringValue(txn.MerchantName) + " " + txn.Name)
+	for _, kw := range incomeKeywords {
+		if strings.Contains(name, kw) {
+			score += weightMerchant
+			break
+		}
+	}
+
+	if subcategory != "" {
+		if incomeSubcategory {
+			score += weightSubcategory
+		} else {
+			score -= weightSubcategory
+		}
+	}
+
+	if !isIncome {
+		score = -score
+	}
+	return isIncome, math.Max(0, math.Min(score, 1))
+}
+
+// NeedsReview reports whether a classification is too uncertain to use
+func NeedsReview(confidence float64) bool {
+	return confidence < MinConfidence
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, p := range prefixes {
+		if strings.HasPrefix(s, p) {
+			return true
+		}
+	}
+	return false
+}
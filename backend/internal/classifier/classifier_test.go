@@ -0,0 +1,96 @@
+package classifier
+
+import (
+	"math"
+	"testing"
+
+	"github.com/finviz/backend/internal/models"
+)
+
+func strPtr(s string) *string { return &s }
+
+func TestClassifyTransaction(t *testing.T) {
+	tests := []struct {
+		name           string
+		txn            models.Transaction
+		wantIncome     bool
+		wantConfidence float64
+		wantReview     bool
+	}{
+		{
+			name:           "uncategorized expense",
+			txn:            models.Transaction{Amount: 12.50, Name: "Corner Cafe"},
+			wantConfidence: 0.5,
+		},
+		{
+			name:           "uncategorized deposit",
+			txn:            models.Transaction{Amount: -500, Name: "Transfer from savings"},
+			wantIncome:     true,
+			wantConfidence: 0.5,
+		},
+		{
+			name:           "categorized expense",
+			txn:            models.Transaction{Amount: 40, Name: "Grocer", Category: strPtr("FOOD_AND_DRINK"), Subcategory: strPtr("FOOD_AND_DRINK_GROCERIES")},
+			wantConfidence: 0.9,
+		},
+		{
+			name:           "payroll with every signal agreeing",
+			txn:            models.Transaction{Amount: -3000, Name: "ACME PAYROLL", Category: strPtr("INCOME"), Subcategory: strPtr("INCOME_WAGES")},
+			wantIncome:     true,
+			wantConfidence: 1,
+		},
+		{
+			name:           "income keyword only",
+			txn:            models.Transaction{Amount: -25, Name: "Dividend reinvestment"},
+			wantIncome:     true,
+			wantConfidence: 0.7,
+		},
+		{
+			name:           "income category wins over a positive amount",
+			txn:            models.Transaction{Amount: 3000, Name: "Salary", Category: strPtr("INCOME")},
+			wantIncome:     true,
+			wantConfidence: 0,
+			wantReview:     true,
+		},
+		{
+			name:           "income subcategory wins over a positive amount",
+			txn:            models.Transaction{Amount: 100, Name: "Transfer", Subcategory: strPtr("TRANSFER_IN_ACCOUNT_TRANSFER")},
+			wantIncome:     true,
+			wantConfidence: 0,
+			wantReview:     true,
+		},
+		{
+			name:           "refund in an expense category",
+			txn:            models.Transaction{Amount: -20, Name: "Store refund", Category: strPtr("GENERAL_MERCHANDISE")},
+			wantIncome:     true,
+			wantConfidence: 0.4,
+			wantReview:     true,
+		},
+		{
+			name:           "expense named like a deposit",
+			txn:            models.Transaction{Amount: 1200, Name: "Security deposit"},
+			wantConfidence: 0.3,
+			wantReview:     true,
+		},
+		{
+			name:       "zero amount with nothing to go on",
+			txn:        models.Transaction{Amount: 0, Name: "Adjustment"},
+			wantReview: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			isIncome, confidence := ClassifyTransaction(tt.txn)
+			if isIncome != tt.wantIncome {
+				t.Errorf("isIncome = %v, want %v", isIncome, tt.wantIncome)
+			}
+			if math.Abs(confidence-tt.wantConfidence) > 1e-9 {
+				t.Errorf("confidence = %.2f, want %.2f", confidence, tt.wantConfidence)
+			}
+			if got := NeedsReview(confidence); got != tt.wantReview {
+				t.Errorf("NeedsReview(%.2f) = %v, want %v", confidence, got, tt.wantReview)
+			}
+		})
+	}
+}
@@ -1,17 +1,22 @@
 package claude
 
 import (
-	"encoding/base64"
+	"database/sql"
 	"encoding/json"
 	"fmt"
 	"math"
+	"sort"
+	"strings"
 	"time"
 
+	"github.com/finviz/backend/internal/classifier"
 	"github.com/finviz/backend/internal/db"
 	"github.com/finviz/backend/internal/models"
 	"github.com/finviz/backend/internal/reports"
 	"github.com/finviz/backend/internal/simulation"
+	"github.com/finviz/backend/internal/snapshots"
 	"github.com/finviz/backend/internal/storage"
+	"github.com/finviz/backend/internal/taxcalc"
 	"github.com/finviz/backend/internal/taxparser"
 )
 
@@ -25,8 +30,17 @@ type ToolExecutor struct {
 	UserID        int
 	IsAdvisor     bool
 	ClientContext int // Non-zero when advisor is working with a client
+
+	simulationRuns int // run_simulation calls so far; an executor lives for one chat turn
 }
 
+// SaveDocument stores generated files in the user's document vault. The api
+// package, which imports this one, sets it to api.SaveDocumentFromBytes.
+var SaveDocument func(userID int, uploadedBy int, name string, category string, mimeType string, data []byte) (int64, error)
+
+// maxSimulationRunsPerTurn caps run_simulation calls within a single chat turn
+const maxSimulationRunsPerTurn = 2
+
 // NewToolExecutor creates a new tool executor for a user
 func NewToolExecutor(userID int) *ToolExecutor {
 	return &ToolExecutor{UserID: userID}
@@ -81,6 +95,8 @@ func (e *ToolExecutor) ExecuteTool(name string, input map[string]interface{}) (s
 		return e.compareSimulations(input)
 	case "run_what_if_analysis":
 		return e.runWhatIfAnalysis(input)
+	case "run_simulation":
+		return e.runSimulation(input)
 	case "generate_report":
 		return e.generateReport(input)
 	// Advanced Analysis Tools
@@ -94,8 +110,12 @@ func (e *ToolExecutor) ExecuteTool(name string, input map[string]interface{}) (s
 		return e.projectTaxLiability(input)
 	case "analyze_tax_document":
 		return e.analyzeTaxDocument(input)
+	case "document_analysis":
+		return e.documentAnalysis(input)
 	case "generate_meeting_prep":
 		return e.generateMeetingPrep(input)
+	case "set_goal":
+		return e.setGoal(input)
 	// Advisor-only tools
 	case "list_clients":
 		return e.listClients()
@@ -126,7 +146,7 @@ func (e *ToolExecutor) getUserAssets() (string, error) {
 			   COALESCE(a.custom_volatility, at.default_volatility) as volatility
 		FROM assets a
 		LEFT JOIN asset_types at ON a.type_id = at.id
-		WHERE a.user_id = ?
+		WHERE a.user_id = ? AND a.deleted_at IS NULL
 		ORDER BY a.current_value DESC
 	`, userID)
 	if err != nil {
@@ -223,9 +243,9 @@ func (e *ToolExecutor) getUserTransactions(input map[string]interface{}) (string
 	}
 
 	query := `
-		SELECT id, name, amount, date, category, merchant_name
+		SELECT id, name, amount, date, category, subcategory, merchant_name
 		FROM transactions
-		WHERE user_id = ? AND date >= ? AND date <= ?
+		WHERE user_id = ? AND deleted_at IS NULL AND date >= ? AND date <= ?
 	`
 	args := []interface{}{e.GetEffectiveUserID(), startDate, endDate}
 
@@ -249,34 +269,34 @@ func (e *ToolExecutor) getUserTransactions(input map[string]interface{}) (string
 		Date         string  `json:"date"`
 		Category     *string `json:"category,omitempty"`
 		MerchantName *string `json:"merchant_name,omitempty"`
+		IsIncome     bool    `json:"is_income"`
+		NeedsReview  bool    `json:"needs_review,omitempty"`
 	}
 
 	var transactions []Transaction
 	var totalIncome, totalExpenses float64
-
-	// Income categories that should be counted as income regardless of amount sign
-	incomeCategories := map[string]bool{
-		"INCOME": true, "INCOME_WAGES": true, "INCOME_DIVIDENDS": true,
-		"INCOME_INTEREST": true, "TRANSFER_IN": true,
-	}
+	unclassified := 0
 
 	for rows.Next() {
 		var t Transaction
-		if err := rows.Scan(&t.ID, &t.Name, &t.Amount, &t.Date, &t.Category, &t.MerchantName); err != nil {
+		var subcategory *string
+		if err := rows.Scan(&t.ID, &t.Name, &t.Amount, &t.Date, &t.Category, &subcategory, &t.MerchantName); err != nil {
 			continue
 		}
-		transactions = append(transactions, t)
 
-		// Check if category indicates income
-		cat := ""
-		if t.Category != nil {
-			cat = *t.Category
+		isIncome, confidence := classifier.ClassifyTransaction(models.Transaction{
+			Amount: t.Amount, Name: t.Name, MerchantName: t.MerchantName, Category: t.Category, Subcategory: subcategory,
+		})
+		t.IsIncome = isIncome
+		t.NeedsReview = classifier.NeedsReview(confidence)
+		if t.NeedsReview {
+			unclassified++
 		}
-		isIncomeCategory := incomeCategories[cat]
+		transactions = append(transactions, t)
 
-		if t.Amount < 0 || isIncomeCategory {
+		if isIncome {
 			totalIncome += abs(t.Amount)
-		} else {
+		} else if t.Amount > 0 {
 			totalExpenses += t.Amount
 		}
 	}
@@ -289,6 +309,10 @@ func (e *ToolExecutor) getUserTransactions(input map[string]interface{}) (string
 		"total_expenses": totalExpenses,
 		"net_cash_flow":  totalIncome - totalExpenses,
 	}
+	if unclassified > 0 {
+		result["unclassified_count"] = unclassified
+		result["unclassified_note"] = "Some transactions could not be confidently classified as income or expense; ask the user to review them."
+	}
 
 	jsonBytes, _ := json.MarshalIndent(result, "", "  ")
 	return string(jsonBytes), nil
@@ -302,7 +326,7 @@ func (e *ToolExecutor) getNetWorthSummary() (string, error) {
 		SELECT COALESCE(at.name, 'Other') as type_name, SUM(a.current_value) as total
 		FROM assets a
 		LEFT JOIN asset_types at ON a.type_id = at.id
-		WHERE a.user_id = ?
+		WHERE a.user_id = ? AND a.deleted_at IS NULL
 		GROUP BY at.name
 		ORDER BY total DESC
 	`, userID)
@@ -338,6 +362,19 @@ func (e *ToolExecutor) getNetWorthSummary() (string, error) {
 		"assets_by_type": assetsByType,
 	}
 
+	// Month-end values from the daily snapshots, when there are any
+	history, err := snapshots.History(userID, time.Now().AddDate(-1, 0, 0))
+	if err == nil && len(history) > 0 {
+		var trend []models.NetWorthSnapshot
+		for i, snap := range history {
+			if i == len(history)-1 || history[i+1].Date[:7] != snap.Date[:7] {
+				trend = append(trend, snap)
+			}
+		}
+		result["trend_12_months"] = trend
+		result["change_12_months"] = (totalAssets - totalDebts) - history[0].NetWorth
+	}
+
 	jsonBytes, _ := json.MarshalIndent(result, "", "  ")
 	return string(jsonBytes), nil
 }
@@ -353,25 +390,10 @@ func (e *ToolExecutor) getMonthlyCashFlow(input map[string]interface{}) (string,
 	startDate := time.Now().AddDate(0, -months, 0).Format("2006-01-02")
 
 	rows, err := db.DB.Query(`
-		SELECT
-			DATE_FORMAT(date, '%Y-%m') as month,
-			COALESCE(SUM(CASE
-				WHEN amount < 0 THEN ABS(amount)
-				WHEN category IN ('INCOME', 'INCOME_WAGES', 'INCOME_DIVIDENDS', 'INCOME_INTEREST', 'TRANSFER_IN') THEN ABS(amount)
-				WHEN subcategory LIKE 'INCOME%' OR subcategory LIKE 'TRANSFER_IN%' THEN ABS(amount)
-				ELSE 0
-			END), 0) as income,
-			COALESCE(SUM(CASE
-				WHEN amount > 0
-				AND category NOT IN ('INCOME', 'INCOME_WAGES', 'INCOME_DIVIDENDS', 'INCOME_INTEREST', 'TRANSFER_IN')
-				AND (subcategory IS NULL OR (subcategory NOT LIKE 'INCOME%' AND subcategory NOT LIKE 'TRANSFER_IN%'))
-				THEN amount
-				ELSE 0
-			END), 0) as expenses
+		SELECT amount, date, name, merchant_name, category, subcategory
 		FROM transactions
-		WHERE user_id = ? AND date >= ?
-		GROUP BY DATE_FORMAT(date, '%Y-%m')
-		ORDER BY month DESC
+		WHERE user_id = ? AND deleted_at IS NULL AND date >= ?
+		ORDER BY date DESC
 	`, userID, startDate)
 	if err != nil {
 		return "", err
@@ -387,42 +409,59 @@ func (e *ToolExecutor) getMonthlyCashFlow(input map[string]interface{}) (string,
 
 	var monthlyData []MonthlyData
 	var totalIncome, totalExpenses float64
+	categoryTotals := make(map[string]float64)
+	unclassified := 0
 
 	for rows.Next() {
-		var m MonthlyData
-		if err := rows.Scan(&m.Month, &m.Income, &m.Expenses); err != nil {
+		var t models.Transaction
+		if err := rows.Scan(&t.Amount, &t.Date, &t.Name, &t.MerchantName, &t.Category, &t.Subcategory); err != nil {
 			continue
 		}
-		m.NetCashFlow = m.Income - m.Expenses
-		monthlyData = append(monthlyData, m)
-		totalIncome += m.Income
-		totalExpenses += m.Expenses
-	}
 
-	// Get spending by category (excluding income categories)
-	catRows, err := db.DB.Query(`
-		SELECT COALESCE(category, 'Uncategorized') as category, SUM(amount) as total
-		FROM transactions
-		WHERE user_id = ? AND date >= ? AND amount > 0
-		AND category NOT IN ('INCOME', 'INCOME_WAGES', 'INCOME_DIVIDENDS', 'INCOME_INTEREST', 'TRANSFER_IN')
-		AND (subcategory IS NULL OR (subcategory NOT LIKE 'INCOME%' AND subcategory NOT LIKE 'TRANSFER_IN%'))
-		GROUP BY category
-		ORDER BY total DESC
-		LIMIT 10
-	`, userID, startDate)
-	if err == nil {
-		defer catRows.Close()
+		isIncome, confidence := classifier.ClassifyTransaction(t)
+		if classifier.NeedsReview(confidence) {
+			unclassified++
+		}
+
+		// Rows are newest first, so months arrive in sequence
+		month := t.Date[:7]
+		if len(monthlyData) == 0 || monthlyData[len(monthlyData)-1].Month != month {
+			monthlyData = append(monthlyData, MonthlyData{Month: month})
+		}
+		m := &monthlyData[len(monthlyData)-1]
+
+		if isIncome {
+			m.Income += abs(t.Amount)
+			totalIncome += abs(t.Amount)
+		} else if t.Amount > 0 {
+			m.Expenses += t.Amount
+			totalExpenses += t.Amount
+
+			category := "Uncategorized"
+			if t.Category != nil && *t.Category != "" {
+				category = *t.Category
+			}
+			categoryTotals[category] += t.Amount
+		}
+	}
+	for i := range monthlyData {
+		monthlyData[i].NetCashFlow = monthlyData[i].Income - monthlyData[i].Expenses
 	}
 
+	// Top 10 spending categories
+	categories := make([]string, 0, len(categoryTotals))
+	for category := range categoryTotals {
+		categories = append(categories, category)
+	}
+	sort.Slice(categories, func(i, j int) bool {
+		return categoryTotals[categories[i]] > categoryTotals[categories[j]]
+	})
 	categoryBreakdown := make(map[string]float64)
-	if catRows != nil {
-		for catRows.Next() {
-			var cat string
-			var total float64
-			if catRows.Scan(&cat, &total) == nil {
-				categoryBreakdown[cat] = total
-			}
+	for i, category := range categories {
+		if i == 10 {
+			break
 		}
+		categoryBreakdown[category] = categoryTotals[category]
 	}
 
 	avgMonthlyIncome := float64(0)
@@ -448,40 +487,31 @@ func (e *ToolExecutor) getMonthlyCashFlow(input map[string]interface{}) (string,
 		"category_breakdown":   categoryBreakdown,
 		"period_months":        months,
 	}
+	if unclassified > 0 {
+		result["unclassified_count"] = unclassified
+	}
 
 	jsonBytes, _ := json.MarshalIndent(result, "", "  ")
 	return string(jsonBytes), nil
 }
 
-// runMonteCarlo runs a Monte Carlo simulation and saves it
-func (e *ToolExecutor) runMonteCarlo(input map[string]interface{}) (string, error) {
-	userID := e.GetEffectiveUserID()
-
-	// Get user's current assets and debts
-	assets, err := e.fetchAssets(userID)
-	if err != nil {
-		return "", fmt.Errorf("failed to fetch assets: %w", err)
-	}
-
-	debts, err := e.fetchDebts(userID)
-	if err != nil {
-		return "", fmt.Errorf("failed to fetch debts: %w", err)
-	}
-
-	// Build simulation parameters
+// monteCarloParams builds simulation parameters from run_monte_carlo input.
+// social_security_age is when benefits are claimed; full retirement age stays
+// at its default so early or late claiming adjusts the benefit.
+func monteCarloParams(input map[string]interface{}) (models.SimulationParams, error) {
 	params := models.DefaultSimulationParams()
 
 	// Required parameters
 	if th, ok := input["time_horizon_years"].(float64); ok {
 		params.TimeHorizonYears = int(th)
 	} else {
-		return "", fmt.Errorf("time_horizon_years is required")
+		return params, fmt.Errorf("time_horizon_years is required")
 	}
 
 	if ca, ok := input["current_age"].(float64); ok {
 		params.CurrentAge = int(ca)
 	} else {
-		return "", fmt.Errorf("current_age is required")
+		return params, fmt.Errorf("current_age is required")
 	}
 
 	// Optional parameters with defaults
@@ -507,7 +537,29 @@ func (e *ToolExecutor) runMonteCarlo(input map[string]interface{}) (string, erro
 		params.SocialSecurityAmount = ss
 	}
 	if ssa, ok := input["social_security_age"].(float64); ok {
-		params.SocialSecurityAge = int(ssa)
+		params.SocialSecurityClaimAge = int(ssa)
+	}
+	return params, nil
+}
+
+// runMonteCarlo runs a Monte Carlo simulation and saves it
+func (e *ToolExecutor) runMonteCarlo(input map[string]interface{}) (string, error) {
+	userID := e.GetEffectiveUserID()
+
+	// Get user's current assets and debts
+	assets, err := e.fetchAssets(userID)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch assets: %w", err)
+	}
+
+	debts, err := e.fetchDebts(userID)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch debts: %w", err)
+	}
+
+	params, err := monteCarloParams(input)
+	if err != nil {
+		return "", err
 	}
 
 	// Run the simulation
@@ -540,11 +592,13 @@ func (e *ToolExecutor) runMonteCarlo(input map[string]interface{}) (string, erro
 		finalP50 = result.Projections[len(result.Projections)-1].P50
 	}
 
+	resultsCompressed, _ := db.GzipJSON(resultsJSON)
+
 	_, err = db.DB.Exec(`
 		INSERT INTO simulation_history
-		(user_id, run_by_user_id, name, notes, params, results, starting_net_worth, final_p50, success_rate, time_horizon_years)
+		(user_id, run_by_user_id, name, notes, params, results_compressed, starting_net_worth, final_p50, success_rate, time_horizon_years)
 		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-	`, userID, runByUserID, name, notes, paramsJSON, resultsJSON,
+	`, userID, runByUserID, name, notes, paramsJSON, resultsCompressed,
 		startingNW, finalP50, result.Summary.SuccessRate, params.TimeHorizonYears)
 
 	if err != nil {
@@ -576,6 +630,107 @@ func (e *ToolExecutor) runMonteCarlo(input map[string]interface{}) (string, erro
 	return string(jsonBytes), nil
 }
 
+// runSimulation runs an unsaved simulation and returns a compact summary
+func (e *ToolExecutor) runSimulation(input map[string]interface{}) (string, error) {
+	if e.simulationRuns >= maxSimulationRunsPerTurn {
+		return "", fmt.Errorf("run_simulation may only be called %d times per turn; summarize the results so far", maxSimulationRunsPerTurn)
+	}
+
+	ca, ok := input["current_age"].(float64)
+	if !ok {
+		return "", fmt.Errorf("current_age is required")
+	}
+
+	userID := e.GetEffectiveUserID()
+	assets, err := e.fetchAssets(userID)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch assets: %w", err)
+	}
+	debts, err := e.fetchDebts(userID)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch debts: %w", err)
+	}
+
+	params := models.DefaultSimulationParams()
+	params.CurrentAge = int(ca)
+	if v, ok := input["retirement_age"].(float64); ok {
+		params.RetirementAge = int(v)
+	}
+	if v, ok := input["time_horizon_years"].(float64); ok {
+		params.TimeHorizonYears = int(v)
+	}
+	if v, ok := input["monthly_contribution"].(float64); ok {
+		params.MonthlyContribution = v
+	}
+	if v, ok := input["retirement_spending"].(float64); ok {
+		params.RetirementSpending = v
+	}
+	if v, ok := input["expected_return"].(float64); ok {
+		params.ExpectedReturn = v
+	}
+	if v, ok := input["volatility"].(float64); ok {
+		params.Volatility = v
+	}
+	if v, ok := input["inflation_rate"].(float64); ok {
+		params.InflationRate = v
+	}
+	if v, ok := input["social_security_amount"].(float64); ok {
+		params.SocialSecurityAmount = v
+	}
+	if v, ok := input["social_security_claim_age"].(float64); ok {
+		params.SocialSecurityClaimAge = int(v)
+	}
+	if v, ok := input["withdrawal_strategy"].(string); ok && v != "" {
+		params.WithdrawalStrategy = v
+	}
+	if v, ok := input["retirement_tax_rate"].(float64); ok {
+		params.RetirementTaxRate = v
+	}
+
+	if params.TimeHorizonYears < 1 || params.TimeHorizonYears > 80 {
+		return "", fmt.Errorf("time_horizon_years must be between 1 and 80")
+	}
+	if params.RetirementAge < params.CurrentAge {
+		return "", fmt.Errorf("retirement_age cannot be before current_age")
+	}
+
+	e.simulationRuns++
+	result := simulation.RunMonteCarloWithParams(assets, debts, &params)
+
+	finalP50 := 0.0
+	if len(result.Projections) > 0 {
+		finalP50 = result.Projections[len(result.Projections)-1].P50
+	}
+
+	output := map[string]interface{}{
+		"success_rate":       result.Summary.SuccessRate,
+		"final_p50":          math.Round(finalP50),
+		"top_insights":       topInsights(result.Insights, 3),
+		"current_age":        params.CurrentAge,
+		"retirement_age":     params.RetirementAge,
+		"time_horizon_years": params.TimeHorizonYears,
+		"runs_remaining":     maxSimulationRunsPerTurn - e.simulationRuns,
+	}
+
+	jsonBytes, _ := json.MarshalIndent(output, "", "  ")
+	return string(jsonBytes), nil
+}
+
+// insightPriority orders insights for summaries: problems before opportunities before FYIs
+var insightPriority = map[string]int{"warning": 0, "opportunity": 1, "info": 2, "success": 3}
+
+// topInsights returns the n most important insights
+func topInsights(insights []models.Insight, n int) []models.Insight {
+	sorted := append([]models.Insight(nil), insights...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return insightPriority[sorted[i].Type] < insightPriority[sorted[j].Type]
+	})
+	if len(sorted) > n {
+		sorted = sorted[:n]
+	}
+	return sorted
+}
+
 // fetchAssets retrieves assets for Monte Carlo simulation
 func (e *ToolExecutor) fetchAssets(userID int) ([]models.Asset, error) {
 	rows, err := db.DB.Query(`
@@ -584,7 +739,7 @@ func (e *ToolExecutor) fetchAssets(userID int) ([]models.Asset, error) {
 		       at.id, at.name, at.default_return, at.default_volatility
 		FROM assets a
 		LEFT JOIN asset_types at ON a.type_id = at.id
-		WHERE a.user_id = ?
+		WHERE a.user_id = ? AND a.deleted_at IS NULL
 	`, userID)
 	if err != nil {
 		return nil, err
@@ -708,25 +863,26 @@ func (e *ToolExecutor) getSimulationDetails(input map[string]interface{}) (strin
 	}
 
 	var sim struct {
-		ID               int
-		Name             *string
-		Notes            *string
-		Params           []byte
-		Results          []byte
-		StartingNetWorth float64
-		FinalP50         float64
-		SuccessRate      float64
-		TimeHorizonYears int
-		IsFavorite       bool
-		CreatedAt        time.Time
+		ID                int
+		Name              *string
+		Notes             *string
+		Params            []byte
+		Results           []byte
+		ResultsCompressed []byte
+		StartingNetWorth  float64
+		FinalP50          float64
+		SuccessRate       float64
+		TimeHorizonYears  int
+		IsFavorite        bool
+		CreatedAt         time.Time
 	}
 
 	err := db.DB.QueryRow(`
-		SELECT id, name, notes, params, results, starting_net_worth, final_p50,
+		SELECT id, name, notes, params, results, results_compressed, starting_net_worth, final_p50,
 		       success_rate, time_horizon_years, is_favorite, created_at
 		FROM simulation_history
 		WHERE id = ? AND user_id = ?
-	`, int(simID), userID).Scan(&sim.ID, &sim.Name, &sim.Notes, &sim.Params, &sim.Results,
+	`, int(simID), userID).Scan(&sim.ID, &sim.Name, &sim.Notes, &sim.Params, &sim.Results, &sim.ResultsCompressed,
 		&sim.StartingNetWorth, &sim.FinalP50, &sim.SuccessRate, &sim.TimeHorizonYears,
 		&sim.IsFavorite, &sim.CreatedAt)
 
@@ -734,6 +890,12 @@ func (e *ToolExecutor) getSimulationDetails(input map[string]interface{}) (strin
 		return "", fmt.Errorf("simulation not found")
 	}
 
+	if len(sim.ResultsCompressed) > 0 {
+		if decompressed, err := db.GunzipJSON(sim.ResultsCompressed); err == nil {
+			sim.Results = decompressed
+		}
+	}
+
 	var params map[string]interface{}
 	var results map[string]interface{}
 	json.Unmarshal(sim.Params, &params)
@@ -928,7 +1090,7 @@ func (e *ToolExecutor) runWhatIfAnalysis(input map[string]interface{}) (string,
 	}
 
 	if ssa, ok := input["social_security_age"].(float64); ok {
-		modifiedParams.SocialSecurityAge = int(ssa)
+		modifiedParams.SocialSecurityClaimAge = int(ssa)
 		changes = append(changes, fmt.Sprintf("Taking Social Security at age %d", int(ssa)))
 	}
 
@@ -1058,13 +1220,18 @@ func formatLargeNumber(val float64) string {
 	return fmt.Sprintf("%.0f", val)
 }
 
-// generateReport generates a PDF financial plan report
+// generateReport builds a financial plan PDF from the user's assets, debts and
+// most recent saved simulation, and stores it in their document vault
 func (e *ToolExecutor) generateReport(input map[string]interface{}) (string, error) {
+	if SaveDocument == nil || storage.DefaultStorage == nil {
+		return "", fmt.Errorf("document storage is not configured")
+	}
+
 	userID := e.GetEffectiveUserID()
 
 	// Get user info
-	var userName, userEmail string
-	db.DB.QueryRow(`SELECT name, email FROM users WHERE id = ?`, userID).Scan(&userName, &userEmail)
+	var userName string
+	db.DB.QueryRow(`SELECT name FROM users WHERE id = ?`, userID).Scan(&userName)
 
 	// Determine advisor name if in client context
 	var advisorName string
@@ -1094,8 +1261,12 @@ func (e *ToolExecutor) generateReport(input map[string]interface{}) (string, err
 	}
 	netWorth := totalAssets - totalDebts
 
+	reportName, _ := input["report_name"].(string)
+	reportName = strings.TrimSpace(reportName)
+
 	// Prepare report data
 	reportData := reports.ReportData{
+		Title:       reportName,
 		ClientName:  userName,
 		AdvisorName: advisorName,
 		GeneratedAt: time.Now(),
@@ -1112,29 +1283,23 @@ func (e *ToolExecutor) generateReport(input map[string]interface{}) (string, err
 		includeSim = incl
 	}
 
+	simulationSource := ""
 	if includeSim {
-		params := models.DefaultSimulationParams()
-
-		if th, ok := input["time_horizon_years"].(float64); ok {
-			params.TimeHorizonYears = int(th)
-		}
-		if mc, ok := input["monthly_contribution"].(float64); ok {
-			params.MonthlyContribution = mc
-		}
-		if ra, ok := input["retirement_age"].(float64); ok {
-			params.RetirementAge = int(ra)
-		}
-		if ca, ok := input["current_age"].(float64); ok {
-			params.CurrentAge = int(ca)
+		params, simResult, simName, err := latestSavedSimulation(userID)
+		if err != nil {
+			fmt.Printf("Error loading latest simulation for report: %v\n", err)
 		}
-		if rs, ok := input["retirement_spending"].(float64); ok {
-			params.RetirementSpending = rs
+		if simResult != nil {
+			simulationSource = fmt.Sprintf("saved simulation %q", simName)
+		} else {
+			// Nothing saved yet: run one from the given inputs
+			params = reportSimulationParams(input)
+			result := simulation.RunMonteCarloWithParams(assets, debts, params)
+			simResult = &result
+			simulationSource = "new simulation (no saved simulations found)"
 		}
-
-		params.ApplyDefaults()
-		simResult := simulation.RunMonteCarloWithParams(assets, debts, &params)
-		reportData.Simulation = &simResult
-		reportData.Params = &params
+		reportData.Simulation = simResult
+		reportData.Params = params
 	}
 
 	// Generate PDF
@@ -1144,67 +1309,113 @@ func (e *ToolExecutor) generateReport(input map[string]interface{}) (string, err
 	}
 
 	// Generate filename
-	filename := fmt.Sprintf("financial_plan_%s_%s.pdf",
+	baseName := "financial_plan"
+	if reportName != "" {
+		baseName = sanitizeFilename(reportName)
+	}
+	filename := fmt.Sprintf("%s_%s_%s.pdf",
+		baseName,
 		sanitizeFilename(userName),
 		time.Now().Format("2006-01-02"))
 
-	// Check if we should save to document vault (default: true)
-	saveToVault := true
-	if stv, ok := input["save_to_vault"].(bool); ok {
-		saveToVault = stv
+	docID, err := SaveDocument(userID, e.UserID, filename, models.DocCategoryReports, "application/pdf", pdfBytes)
+	if err != nil {
+		return "", fmt.Errorf("failed to save report: %w", err)
 	}
+	downloadURL := fmt.Sprintf("/api/documents/%d/download", docID)
 
-	var docID int64
-	var downloadURL string
-
-	if saveToVault && storage.DefaultStorage != nil {
-		// Save to document vault
-		storagePath, err := storage.DefaultStorage.Save(pdfBytes, filename, true)
-		if err == nil {
-			// Save document record
-			result, err := db.DB.Exec(`
-				INSERT INTO documents (user_id, uploaded_by, name, original_name, mime_type, size, category, storage_path, encrypted)
-				VALUES (?, ?, ?, ?, 'application/pdf', ?, 'reports', ?, TRUE)
-			`, userID, e.UserID, filename, filename, len(pdfBytes), storagePath)
-
-			if err == nil {
-				docID, _ = result.LastInsertId()
-				downloadURL = fmt.Sprintf("/api/documents/%d/download", docID)
-
-				// If advisor generated for client, auto-share with client
-				if e.ClientContext != 0 && e.ClientContext != e.UserID {
-					db.DB.Exec(`
-						INSERT INTO document_shares (document_id, shared_with_id, shared_by_id, permission)
-						VALUES (?, ?, ?, 'download')
-					`, docID, userID, e.UserID)
-				}
-			}
-		}
+	// If advisor generated for client, auto-share with client
+	if e.ClientContext != 0 && e.ClientContext != e.UserID {
+		db.DB.Exec(`
+			INSERT INTO document_shares (document_id, shared_with_id, shared_by_id, permission)
+			VALUES (?, ?, ?, 'download')
+		`, docID, userID, e.UserID)
 	}
 
-	// Build response
+	// Rendered in chat as a download card (type "document")
 	result := map[string]interface{}{
-		"status":   "success",
-		"filename": filename,
-		"size":     len(pdfBytes),
-		"message":  fmt.Sprintf("Generated financial plan report for %s (%d pages, %d KB)", userName, estimatePages(len(pdfBytes)), len(pdfBytes)/1024),
+		"type":         "document",
+		"status":       "success",
+		"document_id":  docID,
+		"download_url": downloadURL,
+		"filename":     filename,
+		"size":         len(pdfBytes),
+		"message":      fmt.Sprintf("Generated financial plan report for %s (%d pages, %d KB)", userName, estimatePages(len(pdfBytes)), len(pdfBytes)/1024),
 	}
-
-	// Include document vault info if saved
-	if docID > 0 {
-		result["document_id"] = docID
-		result["download_url"] = downloadURL
-		result["saved_to_vault"] = true
+	if simulationSource != "" {
+		result["simulation_source"] = simulationSource
 	}
 
-	// Also include base64 for immediate display/download
-	pdfBase64 := base64.StdEncoding.EncodeToString(pdfBytes)
-	result["pdf_data"] = pdfBase64
-
 	jsonBytes, _ := json.MarshalIndent(result, "", "  ")
 	return string(jsonBytes), nil
 }
 
+// latestSavedSimulation loads the user's most recent simulation_history entry.
+// A nil result with a nil error means nothing has been saved.
+func latestSavedSimulation(userID int) (*models.SimulationParams, *models.MonteCarloResponse, string, error) {
+	var name sql.NullString
+	var paramsJSON []byte
+	var plainResults sql.NullString
+	var compressedResults []byte
+	err := db.DB.QueryRow(`
+		SELECT name, params, results, results_compressed
+		FROM simulation_history
+		WHERE user_id = ?
+		ORDER BY created_at DESC, id DESC
+		LIMIT 1
+	`, userID).Scan(&name, &paramsJSON, &plainResults, &compressedResults)
+	if err == sql.ErrNoRows {
+		return nil, nil, "", nil
+	}
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	resultsJSON, err := db.SimulationResultsJSON(plainResults, compressedResults)
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	var params models.SimulationParams
+	var results models.MonteCarloResponse
+	if err := json.Unmarshal(paramsJSON, &params); err != nil {
+		return nil, nil, "", err
+	}
+	if err := json.Unmarshal(resultsJSON, &results); err != nil {
+		return nil, nil, "", err
+	}
+
+	simName := name.String
+	if simName == "" {
+		simName = "Untitled"
+	}
+	return &params, &results, simName, nil
+}
+
+// reportSimulationParams builds simulation params from generate_report input
+func reportSimulationParams(input map[string]interface{}) *models.SimulationParams {
+	params := models.DefaultSimulationParams()
+
+	if th, ok := input["time_horizon_years"].(float64); ok {
+		params.TimeHorizonYears = int(th)
+	}
+	if mc, ok := input["monthly_contribution"].(float64); ok {
+		params.MonthlyContribution = mc
+	}
+	if ra, ok := input["retirement_age"].(float64); ok {
+		params.RetirementAge = int(ra)
+	}
+	if ca, ok := input["current_age"].(float64); ok {
+		params.CurrentAge = int(ca)
+	}
+	if rs, ok := input["retirement_spending"].(float64); ok {
+		params.RetirementSpending = rs
+	}
+
+	params.ApplyDefaults()
+	return &params
+}
+
 // sanitizeFilename removes/replaces characters that are unsafe for filenames
 func sanitizeFilename(name string) string {
 	result := make([]byte, 0, len(name))
@@ -1243,7 +1454,7 @@ func (e *ToolExecutor) listClients() (string, error) {
 		       COALESCE(SUM(d.current_balance), 0) as total_debts
 		FROM advisor_clients ac
 		JOIN users u ON ac.client_id = u.id
-		LEFT JOIN assets a ON u.id = a.user_id
+		LEFT JOIN assets a ON u.id = a.user_id AND a.deleted_at IS NULL
 		LEFT JOIN debts d ON u.id = d.user_id
 		WHERE ac.advisor_id = ? AND ac.status = 'active'
 		GROUP BY u.id, u.email, u.name, ac.status, ac.access_level, ac.created_at
@@ -1358,55 +1569,17 @@ func (e *ToolExecutor) optimizeSocialSecurity(input map[string]interface{}) (str
 		currentAge--
 	}
 
-	// Determine FRA based on birth year (simplified - 67 for those born 1960+)
-	birthYear := birthDate.Year()
-	var fraAge int
-	var fraMonths int
-	switch {
-	case birthYear <= 1937:
-		fraAge, fraMonths = 65, 0
-	case birthYear == 1938:
-		fraAge, fraMonths = 65, 2
-	case birthYear == 1939:
-		fraAge, fraMonths = 65, 4
-	case birthYear == 1940:
-		fraAge, fraMonths = 65, 6
-	case birthYear == 1941:
-		fraAge, fraMonths = 65, 8
-	case birthYear == 1942:
-		fraAge, fraMonths = 65, 10
-	case birthYear >= 1943 && birthYear <= 1954:
-		fraAge, fraMonths = 66, 0
-	case birthYear == 1955:
-		fraAge, fraMonths = 66, 2
-	case birthYear == 1956:
-		fraAge, fraMonths = 66, 4
-	case birthYear == 1957:
-		fraAge, fraMonths = 66, 6
-	case birthYear == 1958:
-		fraAge, fraMonths = 66, 8
-	case birthYear == 1959:
-		fraAge, fraMonths = 66, 10
-	default: // 1960+
-		fraAge, fraMonths = 67, 0
-	}
+	// Full retirement age by birth year
+	fra := simulation.FullRetirementAgeMonths(birthDate.Year())
+	fraAge, fraMonths := fra/12, fra%12
 
 	// Get PIA (Primary Insurance Amount at FRA)
 	var pia float64
 	if estimatedPia, ok := input["estimated_pia"].(float64); ok && estimatedPia > 0 {
 		pia = estimatedPia
 	} else if annualEarnings, ok := input["current_annual_earnings"].(float64); ok && annualEarnings > 0 {
-		// Rough PIA estimate: ~42% of average indexed monthly earnings for typical earner
-		// This is a simplification; real calculation uses AIME and bend points
-		aime := annualEarnings / 12
-		// 2024 bend points approximation
-		if aime <= 1174 {
-			pia = aime * 0.90
-		} else if aime <= 7078 {
-			pia = 1174*0.90 + (aime-1174)*0.32
-		} else {
-			pia = 1174*0.90 + (7078-1174)*0.32 + (aime-7078)*0.15
-		}
+		// Rough PIA estimate treating current monthly earnings as AIME
+		pia = simulation.EstimatePIA(annualEarnings / 12)
 	} else {
 		return "", fmt.Errorf("either estimated_pia or current_annual_earnings is required")
 	}
@@ -1551,35 +1724,8 @@ func (e *ToolExecutor) projectTaxLiability(input map[string]interface{}) (string
 		filingStatus = fs
 	}
 
-	// 2024 Tax Brackets (will be updated annually)
-	type TaxBracket struct {
-		Rate   float64
-		Single float64
-		MFJ    float64 // Married Filing Jointly
-	}
-
-	brackets := []TaxBracket{
-		{0.10, 11600, 23200},
-		{0.12, 47150, 94300},
-		{0.22, 100525, 201050},
-		{0.24, 191950, 383900},
-		{0.32, 243725, 487450},
-		{0.35, 609350, 731200},
-		{0.37, math.MaxFloat64, math.MaxFloat64},
-	}
-
-	// Standard deductions
-	standardDeductions := map[string]float64{
-		"single":                  14600,
-		"married_filing_jointly":  29200,
-		"married_filing_separate": 14600,
-		"head_of_household":       21900,
-	}
-
-	standardDeduction := standardDeductions["single"]
-	if sd, ok := standardDeductions[filingStatus]; ok {
-		standardDeduction = sd
-	}
+	brackets := taxcalc.Brackets(filingStatus)
+	standardDeduction := taxcalc.StandardDeduction(filingStatus)
 
 	// Get annual income - either from input or estimate from transactions
 	var annualIncome float64
@@ -1597,7 +1743,7 @@ func (e *ToolExecutor) projectTaxLiability(input map[string]interface{}) (string
 		err := db.DB.QueryRow(`
 			SELECT COALESCE(SUM(ABS(amount)), 0)
 			FROM transactions
-			WHERE user_id = ? AND date >= ? AND date <= ?
+			WHERE user_id = ? AND deleted_at IS NULL AND date >= ? AND date <= ?
 			AND (amount < 0 OR category IN ('INCOME', 'INCOME_WAGES', 'INCOME_DIVIDENDS', 'INCOME_INTEREST'))
 		`, userID, ytdStart, ytdEnd).Scan(&ytdIncome)
 
@@ -1643,10 +1789,7 @@ func (e *ToolExecutor) projectTaxLiability(input map[string]interface{}) (string
 	var bracketBreakdown []BracketBreakdown
 
 	for _, bracket := range brackets {
-		bracketMax := bracket.Single
-		if filingStatus == "married_filing_jointly" {
-			bracketMax = bracket.MFJ
-		}
+		bracketMax := bracket.UpTo
 
 		bracketRange := bracketMax - previousBracketMax
 		taxableInBracket := math.Min(remainingIncome, bracketRange)
@@ -1824,7 +1967,7 @@ func (e *ToolExecutor) checkPortfolioDrift(input map[string]interface{}) (string
 		SELECT COALESCE(at.name, 'Other') as type_name, SUM(a.current_value) as total
 		FROM assets a
 		LEFT JOIN asset_types at ON a.type_id = at.id
-		WHERE a.user_id = ?
+		WHERE a.user_id = ? AND a.deleted_at IS NULL
 		GROUP BY at.name
 	`, userID)
 	if err != nil {
@@ -2050,7 +2193,7 @@ func (e *ToolExecutor) analyzeSpendingPatterns(input map[string]interface{}) (st
 	rows, err := db.DB.Query(`
 		SELECT id, name, amount, date, category, subcategory, merchant_name
 		FROM transactions
-		WHERE user_id = ? AND date >= ? AND date <= ?
+		WHERE user_id = ? AND deleted_at IS NULL AND date >= ? AND date <= ?
 		ORDER BY date DESC
 	`, userID, startDate, endDate)
 	if err != nil {
@@ -2082,11 +2225,6 @@ func (e *ToolExecutor) analyzeSpendingPatterns(input map[string]interface{}) (st
 	}
 
 	// Categorize transactions
-	incomeCategories := map[string]bool{
-		"INCOME": true, "INCOME_WAGES": true, "INCOME_DIVIDENDS": true,
-		"INCOME_INTEREST": true, "TRANSFER_IN": true,
-	}
-
 	essentialCategories := map[string]bool{
 		"RENT_AND_UTILITIES": true, "FOOD_AND_DRINK": true, "MEDICAL": true,
 		"TRANSPORTATION": true, "LOAN_PAYMENTS": true, "INSURANCE": true,
@@ -2124,11 +2262,13 @@ func (e *ToolExecutor) analyzeSpendingPatterns(input map[string]interface{}) (st
 			monthlyData[monthKey] = make(map[string]float64)
 		}
 
-		isIncomeCategory := incomeCategories[cat]
-		if t.Amount < 0 || isIncomeCategory {
+		isIncome, _ := classifier.ClassifyTransaction(models.Transaction{
+			Amount: t.Amount, Name: t.Name, MerchantName: t.MerchantName, Category: t.Category, Subcategory: t.Subcategory,
+		})
+		if isIncome {
 			totalIncome += abs(t.Amount)
 			monthlyData[monthKey]["income"] += abs(t.Amount)
-		} else {
+		} else if t.Amount > 0 {
 			totalExpenses += t.Amount
 			monthlyData[monthKey]["expenses"] += t.Amount
 			categoryTotals[cat] += t.Amount
@@ -2360,16 +2500,27 @@ func (e *ToolExecutor) analyzeSpendingPatterns(input map[string]interface{}) (st
 		priorStartDate := now.AddDate(0, -months*2, 0).Format("2006-01-02")
 
 		priorRows, err := db.DB.Query(`
-			SELECT SUM(CASE WHEN amount < 0 OR category IN ('INCOME', 'INCOME_WAGES', 'INCOME_DIVIDENDS', 'INCOME_INTEREST', 'TRANSFER_IN') THEN ABS(amount) ELSE 0 END) as income,
-			       SUM(CASE WHEN amount > 0 AND category NOT IN ('INCOME', 'INCOME_WAGES', 'INCOME_DIVIDENDS', 'INCOME_INTEREST', 'TRANSFER_IN') THEN amount ELSE 0 END) as expenses
+			SELECT amount, name, merchant_name, category, subcategory
 			FROM transactions
-			WHERE user_id = ? AND date >= ? AND date < ?
+			WHERE user_id = ? AND deleted_at IS NULL AND date >= ? AND date < ?
 		`, userID, priorStartDate, priorEndDate)
 		if err == nil {
 			defer priorRows.Close()
-			if priorRows.Next() {
-				var priorIncome, priorExpenses float64
-				priorRows.Scan(&priorIncome, &priorExpenses)
+			var priorIncome, priorExpenses float64
+			priorCount := 0
+			for priorRows.Next() {
+				var t models.Transaction
+				if priorRows.Scan(&t.Amount, &t.Name, &t.MerchantName, &t.Category, &t.Subcategory) != nil {
+					continue
+				}
+				priorCount++
+				if isIncome, _ := classifier.ClassifyTransaction(t); isIncome {
+					priorIncome += abs(t.Amount)
+				} else if t.Amount > 0 {
+					priorExpenses += t.Amount
+				}
+			}
+			if priorCount > 0 {
 
 				incomeChange := 0.0
 				expenseChange := 0.0
@@ -2427,7 +2578,7 @@ func (e *ToolExecutor) getClientSummary(input map[string]interface{}) (string, e
 	// Get asset summary
 	var totalAssets float64
 	var assetCount int
-	db.DB.QueryRow(`SELECT COALESCE(SUM(current_value), 0), COUNT(*) FROM assets WHERE user_id = ?`, id).Scan(&totalAssets, &assetCount)
+	db.DB.QueryRow(`SELECT COALESCE(SUM(current_value), 0), COUNT(*) FROM assets WHERE user_id = ? AND deleted_at IS NULL`, id).Scan(&totalAssets, &assetCount)
 
 	// Get debt summary
 	var totalDebts float64
@@ -2487,63 +2638,15 @@ func (e *ToolExecutor) getClientSummary(input map[string]interface{}) (string, e
 
 // analyzeTaxDocument analyzes an uploaded tax document from the document vault
 func (e *ToolExecutor) analyzeTaxDocument(input map[string]interface{}) (string, error) {
-	userID := e.GetEffectiveUserID()
-
 	// Get document ID from input
 	docID, ok := input["document_id"].(float64)
 	if !ok {
 		return "", fmt.Errorf("document_id is required")
 	}
 
-	// Query document and verify access
-	var doc struct {
-		ID          int
-		UserID      int
-		UploadedBy  int
-		StoragePath string
-		Encrypted   bool
-		MimeType    string
-		Category    string
-		Name        string
-	}
-
-	err := db.DB.QueryRow(`
-		SELECT id, user_id, uploaded_by, storage_path, encrypted, mime_type, category, name
-		FROM documents
-		WHERE id = ? AND deleted_at IS NULL
-	`, int(docID)).Scan(&doc.ID, &doc.UserID, &doc.UploadedBy,
-		&doc.StoragePath, &doc.Encrypted, &doc.MimeType, &doc.Category, &doc.Name)
-
+	doc, err := e.loadAccessibleDocument(int(docID))
 	if err != nil {
-		return "", fmt.Errorf("document not found or access denied")
-	}
-
-	// Check access: user owns document, uploaded it, or has advisor access
-	hasAccess := doc.UserID == userID || doc.UploadedBy == userID
-
-	if !hasAccess {
-		// Check if shared with user
-		var shareCount int
-		db.DB.QueryRow(`
-			SELECT COUNT(*) FROM document_shares
-			WHERE document_id = ? AND shared_with_id = ?
-			AND (expires_at IS NULL OR expires_at > NOW())
-		`, doc.ID, userID).Scan(&shareCount)
-		hasAccess = shareCount > 0
-	}
-
-	if !hasAccess && e.IsAdvisor {
-		// Check advisor-client relationship
-		var accessLevel string
-		db.DB.QueryRow(`
-			SELECT access_level FROM advisor_clients
-			WHERE advisor_id = ? AND client_id = ? AND status = 'active'
-		`, e.UserID, doc.UserID).Scan(&accessLevel)
-		hasAccess = accessLevel != ""
-	}
-
-	if !hasAccess {
-		return "", fmt.Errorf("access denied to this document")
+		return "", err
 	}
 
 	// Verify it's a PDF
@@ -2671,6 +2774,21 @@ func (e *ToolExecutor) generateTaxAnalysis(data *taxparser.ExtractedTaxData, doc
 		if data.GrossIncome != nil {
 			result["gross_income"] = *data.GrossIncome
 		}
+
+	case taxparser.DocType8606:
+		iraBasis := map[string]interface{}{}
+		if data.TraditionalIRABasis != nil {
+			iraBasis["traditional_ira_basis"] = *data.TraditionalIRABasis
+		}
+		if data.TotalRothConversions != nil {
+			iraBasis["roth_conversions"] = *data.TotalRothConversions
+		}
+		if data.NontaxableDistributions != nil {
+			iraBasis["nontaxable_distributions"] = *data.NontaxableDistributions
+		}
+		if len(iraBasis) > 0 {
+			result["ira_basis"] = iraBasis
+		}
 	}
 
 	// Generate optimization suggestions based on extracted data
@@ -2693,7 +2811,7 @@ func (e *ToolExecutor) generateTaxOptimizations(data *taxparser.ExtractedTaxData
 				COALESCE(SUM(CASE WHEN at.name LIKE '%Traditional IRA%' OR at.name LIKE '%IRA%' THEN a.current_value ELSE 0 END), 0)
 			FROM assets a
 			LEFT JOIN asset_types at ON a.type_id = at.id
-			WHERE a.user_id = ?
+			WHERE a.user_id = ? AND a.deleted_at IS NULL
 		`, userID).Scan(&retirement401k, &rothIRA, &traditionalIRA)
 
 		// 401(k) contribution space
@@ -2859,7 +2977,7 @@ func (e *ToolExecutor) generateMeetingPrep(input map[string]interface{}) (string
 	// === NET WORTH ANALYSIS ===
 	// Current totals
 	var totalAssets, totalDebts float64
-	db.DB.QueryRow(`SELECT COALESCE(SUM(current_value), 0) FROM assets WHERE user_id = ?`, clientID).Scan(&totalAssets)
+	db.DB.QueryRow(`SELECT COALESCE(SUM(current_value), 0) FROM assets WHERE user_id = ? AND deleted_at IS NULL`, clientID).Scan(&totalAssets)
 	db.DB.QueryRow(`SELECT COALESCE(SUM(current_balance), 0) FROM debts WHERE user_id = ?`, clientID).Scan(&totalDebts)
 	netWorth := totalAssets - totalDebts
 
@@ -2868,7 +2986,7 @@ func (e *ToolExecutor) generateMeetingPrep(input map[string]interface{}) (string
 		SELECT at.name, SUM(a.current_value) as total
 		FROM assets a
 		LEFT JOIN asset_types at ON a.type_id = at.id
-		WHERE a.user_id = ?
+		WHERE a.user_id = ? AND a.deleted_at IS NULL
 		GROUP BY at.name
 		ORDER BY total DESC
 	`, clientID)
@@ -2893,7 +3011,7 @@ func (e *ToolExecutor) generateMeetingPrep(input map[string]interface{}) (string
 	db.DB.QueryRow(`
 		SELECT COALESCE(SUM(amount), 0)
 		FROM transactions
-		WHERE user_id = ? AND amount > 0 AND date >= ?
+		WHERE user_id = ? AND deleted_at IS NULL AND amount > 0 AND date >= ?
 	`, clientID, oneMonthAgo).Scan(&recentMonthSpending)
 
 	// 3-month average
@@ -2901,7 +3019,7 @@ func (e *ToolExecutor) generateMeetingPrep(input map[string]interface{}) (string
 	db.DB.QueryRow(`
 		SELECT COALESCE(SUM(amount), 0)
 		FROM transactions
-		WHERE user_id = ? AND amount > 0 AND date >= ?
+		WHERE user_id = ? AND deleted_at IS NULL AND amount > 0 AND date >= ?
 	`, clientID, threeMonthsAgo).Scan(&threeMonthSpending)
 	avgMonthlySpending := threeMonthSpending / 3
 
@@ -2909,7 +3027,7 @@ func (e *ToolExecutor) generateMeetingPrep(input map[string]interface{}) (string
 	catRows, _ := db.DB.Query(`
 		SELECT COALESCE(category, 'Uncategorized'), SUM(amount) as total
 		FROM transactions
-		WHERE user_id = ? AND amount > 0 AND date >= ?
+		WHERE user_id = ? AND deleted_at IS NULL AND amount > 0 AND date >= ?
 		GROUP BY category
 		ORDER BY total DESC
 		LIMIT 5
@@ -3407,6 +3525,7 @@ func (e *ToolExecutor) updateClientNote(input map[string]interface{}) (string, e
 	}
 
 	// Apply updates
+	previousText := existingNote
 	if newNote, ok := input["note"].(string); ok && newNote != "" {
 		existingNote = newNote
 	}
@@ -3423,8 +3542,22 @@ func (e *ToolExecutor) updateClientNote(input map[string]interface{}) (string, e
 		existingPinned = newPinned
 	}
 
-	// Update the note
-	_, err = db.DB.Exec(`
+	// Update the note, keeping the replaced text in its history
+	tx, err := db.DB.Begin()
+	if err != nil {
+		return "", fmt.Errorf("failed to update note: %w", err)
+	}
+	defer tx.Rollback()
+
+	if existingNote != previousText {
+		if _, err := tx.Exec(`
+			INSERT INTO client_note_versions (note_id, advisor_id, note_text) VALUES (?, ?, ?)
+		`, int(noteID), e.UserID, previousText); err != nil {
+			return "", fmt.Errorf("failed to save note history: %w", err)
+		}
+	}
+
+	_, err = tx.Exec(`
 		UPDATE client_notes
 		SET note = ?, category = ?, is_pinned = ?
 		WHERE id = ?
@@ -3432,6 +3565,9 @@ func (e *ToolExecutor) updateClientNote(input map[string]interface{}) (string, e
 	if err != nil {
 		return "", fmt.Errorf("failed to update note: %w", err)
 	}
+	if err := tx.Commit(); err != nil {
+		return "", fmt.Errorf("failed to update note: %w", err)
+	}
 
 	// Get client name for response
 	var clientName string
@@ -3502,3 +3638,105 @@ func (e *ToolExecutor) deleteClientNote(input map[string]interface{}) (string, e
 	jsonBytes, _ := json.MarshalIndent(response, "", "  ")
 	return string(jsonBytes), nil
 }
+
+// auditActionGoalCreated is the audit_log action for goals created through Aurelia
+const auditActionGoalCreated = "goal_created"
+
+// setGoal creates a goal for the client. Clients create goals under their own
+// advisor; advisors create them for the client whose context they're in.
+func (e *ToolExecutor) setGoal(input map[string]interface{}) (string, error) {
+	var advisorID, clientID int
+	if e.IsAdvisor {
+		if e.ClientContext == 0 {
+			return "", fmt.Errorf("switch to a client context before setting a goal")
+		}
+		advisorID, clientID = e.UserID, e.ClientContext
+	} else {
+		clientID = e.UserID
+		err := db.DB.QueryRow(`
+			SELECT advisor_id FROM advisor_clients
+			WHERE client_id = ? AND status = 'active'
+			ORDER BY accepted_at DESC
+			LIMIT 1
+		`, clientID).Scan(&advisorID)
+		if err != nil {
+			return "", fmt.Errorf("goals can only be set when you're connected to an advisor")
+		}
+	}
+
+	if !db.AdvisorHasClientAccess(advisorID, clientID) {
+		return "", fmt.Errorf("you don't have access to this client")
+	}
+
+	title, _ := input["title"].(string)
+	if title == "" {
+		return "", fmt.Errorf("title is required")
+	}
+	description, _ := input["description"].(string)
+
+	category := models.GoalCategoryOther
+	if cat, ok := input["category"].(string); ok && cat != "" {
+		if !models.IsValidGoalCategory(cat) {
+			return "", fmt.Errorf("invalid category: %s", cat)
+		}
+		category = cat
+	}
+
+	priority := models.GoalPriorityMedium
+	if p, ok := input["priority"].(string); ok && p != "" {
+		if p != models.GoalPriorityLow && p != models.GoalPriorityMedium && p != models.GoalPriorityHigh {
+			return "", fmt.Errorf("invalid priority: %s", p)
+		}
+		priority = p
+	}
+
+	var targetAmount *float64
+	if amt, ok := input["targetAmount"].(float64); ok {
+		if amt < 0 {
+			return "", fmt.Errorf("targetAmount cannot be negative")
+		}
+		targetAmount = &amt
+	}
+
+	var targetDate *string
+	if d, ok := input["targetDate"].(string); ok && d != "" {
+		if _, err := time.Parse("2006-01-02", d); err != nil {
+			return "", fmt.Errorf("targetDate must be in YYYY-MM-DD format")
+		}
+		targetDate = &d
+	}
+
+	result, err := db.DB.Exec(
+		`INSERT INTO client_goals (advisor_id, client_id, title, description, category, priority, target_amount, target_date)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		advisorID, clientID, title, description, category, priority, targetAmount, targetDate,
+	)
+	if err != nil {
+		return "", fmt.Errorf("failed to create goal: %w", err)
+	}
+
+	goalID, _ := result.LastInsertId()
+
+	logToolAudit(e.UserID, auditActionGoalCreated, fmt.Sprintf("goal_id=%d client_id=%d via=aurelia", goalID, clientID))
+
+	response := map[string]interface{}{
+		"goalId": goalID,
+		"title":  title,
+		"status": "created",
+	}
+
+	jsonBytes, _ := json.MarshalIndent(response, "", "  ")
+	return string(jsonBytes), nil
+}
+
+// logToolAudit records an audit event for an action taken through a tool call.
+// Tool calls have no originating request, so no IP address is recorded.
+func logToolAudit(userID int, action, details string) {
+	_, err := db.DB.Exec(
+		"INSERT INTO audit_log (user_id, action, details) VALUES (?, ?, ?)",
+		userID, action, details,
+	)
+	if err != nil {
+		fmt.Printf("Error writing audit log for user %d (%s): %v\n", userID, action, err)
+	}
+}
@@ -0,0 +1,45 @@
+package claude
+
+import (
+	"testing"
+
+	"github.com/finviz/backend/internal/simulation"
+)
+
+// TestMonteCarloParamsSocialSecurityAge checks that social_security_age is
+// taken as the claiming age, so claiming at 62 pays less than the full
+// retirement age benefit and claiming at 70 pays more
+func TestMonteCarloParamsSocialSecurityAge(t *testing.T) {
+	tests := []struct {
+		claimAge float64
+		lower    bool // benefit below the full retirement age amount
+		higher   bool
+	}{
+		{62, true, false},
+		{67, false, false},
+		{70, false, true},
+	}
+	for _, tt := range tests {
+		params, err := monteCarloParams(map[string]interface{}{
+			"time_horizon_years":     30.0,
+			"current_age":            60.0,
+			"social_security_amount": 2000.0,
+			"social_security_age":    tt.claimAge,
+		})
+		if err != nil {
+			t.Fatalf("monteCarloParams: %v", err)
+		}
+		params.ApplyDefaults()
+
+		if params.SocialSecurityAge != 67 {
+			t.Errorf("claim at %v: full retirement age = %d, want 67", tt.claimAge, params.SocialSecurityAge)
+		}
+		factor := simulation.ClaimingFactor(params.SocialSecurityAge*12, params.SocialSecurityClaimAge*12)
+		if got := factor < 1; got != tt.lower {
+			t.Errorf("claim at %v: claiming factor %.3f, want below 1 = %v", tt.claimAge, factor, tt.lower)
+		}
+		if got := factor > 1; got != tt.higher {
+			t.Errorf("claim at %v: claiming factor %.3f, want above 1 = %v", tt.claimAge, factor, tt.higher)
+		}
+	}
+}
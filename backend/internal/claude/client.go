@@ -123,6 +123,21 @@ func (c *Client) IsConfigured() bool {
 
 // SendMessage sends a message to Claude and returns the response
 func (c *Client) SendMessage(messages []Message) (*Response, error) {
+	return c.SendMessageWithConfig(messages, c.systemPrompt, c.tools)
+}
+
+// GetSystemPrompt returns the default system prompt
+func (c *Client) GetSystemPrompt() string {
+	return c.systemPrompt
+}
+
+// GetTools returns the default tool set
+func (c *Client) GetTools() []Tool {
+	return c.tools
+}
+
+// SendMessageWithConfig sends a message using a per-request system prompt and tool set
+func (c *Client) SendMessageWithConfig(messages []Message, systemPrompt string, tools []Tool) (*Response, error) {
 	if !c.IsConfigured() {
 		return nil, fmt.Errorf("Claude API key not configured")
 	}
@@ -130,9 +145,9 @@ func (c *Client) SendMessage(messages []Message) (*Response, error) {
 	req := Request{
 		Model:     defaultModel,
 		MaxTokens: maxTokens,
-		System:    c.systemPrompt,
+		System:    systemPrompt,
 		Messages:  messages,
-		Tools:     c.tools,
+		Tools:     tools,
 	}
 
 	body, err := json.Marshal(req)
@@ -0,0 +1,153 @@
+package claude
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/finviz/backend/internal/db"
+	"github.com/finviz/backend/internal/storage"
+	"github.com/finviz/backend/internal/taxparser"
+)
+
+// Characters of extracted document text passed back to Claude
+const documentExcerptLength = 2000
+
+// Supported document_analysis analysis types
+const (
+	analysisSummarize       = "summarize"
+	analysisExtractNumbers  = "extract_numbers"
+	analysisTaxImplications = "tax_implications"
+)
+
+// vaultDocument is a document-vault record the current user is allowed to read
+type vaultDocument struct {
+	ID          int
+	UserID      int
+	UploadedBy  int
+	StoragePath string
+	Encrypted   bool
+	MimeType    string
+	Category    string
+	Name        string
+}
+
+// loadAccessibleDocument fetches a document and verifies the user owns it,
+// uploaded it, has an active share, or is an advisor for its owner
+func (e *ToolExecutor) loadAccessibleDocument(docID int) (*vaultDocument, error) {
+	userID := e.GetEffectiveUserID()
+
+	var doc vaultDocument
+	err := db.DB.QueryRow(`
+		SELECT id, user_id, uploaded_by, storage_path, encrypted, mime_type, category, name
+		FROM documents
+		WHERE id = ? AND deleted_at IS NULL
+	`, docID).Scan(&doc.ID, &doc.UserID, &doc.UploadedBy,
+		&doc.StoragePath, &doc.Encrypted, &doc.MimeType, &doc.Category, &doc.Name)
+
+	if err != nil {
+		return nil, fmt.Errorf("document not found or access denied")
+	}
+
+	// Check access: user owns document, uploaded it, or has advisor access
+	hasAccess := doc.UserID == userID || doc.UploadedBy == userID
+
+	if !hasAccess {
+		// Check if shared with user
+		var shareCount int
+		db.DB.QueryRow(`
+			SELECT COUNT(*) FROM document_shares
+			WHERE document_id = ? AND shared_with_id = ?
+			AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > NOW())
+		`, doc.ID, userID).Scan(&shareCount)
+		hasAccess = shareCount > 0
+	}
+
+	if !hasAccess && e.IsAdvisor {
+		// Check advisor-client relationship
+		var accessLevel string
+		db.DB.QueryRow(`
+			SELECT access_level FROM advisor_clients
+			WHERE advisor_id = ? AND client_id = ? AND status = 'active'
+		`, e.UserID, doc.UserID).Scan(&accessLevel)
+		hasAccess = accessLevel != ""
+	}
+
+	if !hasAccess {
+		return nil, fmt.Errorf("access denied to this document")
+	}
+
+	return &doc, nil
+}
+
+// documentAnalysis extracts a document's text so Claude can summarize it,
+// pull out figures, or discuss tax implications
+func (e *ToolExecutor) documentAnalysis(input map[string]interface{}) (string, error) {
+	docID, ok := input["documentId"].(float64)
+	if !ok {
+		return "", fmt.Errorf("documentId is required")
+	}
+
+	analysisType, _ := input["analysisType"].(string)
+	switch analysisType {
+	case analysisSummarize, analysisExtractNumbers, analysisTaxImplications:
+	case "":
+		analysisType = analysisSummarize
+	default:
+		return "", fmt.Errorf("analysisType must be 'summarize', 'extract_numbers', or 'tax_implications'")
+	}
+
+	doc, err := e.loadAccessibleDocument(int(docID))
+	if err != nil {
+		return "", err
+	}
+
+	data, err := storage.DefaultStorage.Load(doc.StoragePath, doc.Encrypted)
+	if err != nil {
+		return "", fmt.Errorf("failed to load document: %w", err)
+	}
+
+	var text string
+	switch {
+	case doc.MimeType == "application/pdf":
+		text, err = taxparser.ExtractText(data)
+		if err != nil {
+			return "", fmt.Errorf("failed to read PDF: %w", err)
+		}
+	case strings.HasPrefix(doc.MimeType, "text/"):
+		text = string(data)
+	default:
+		return "", fmt.Errorf("text extraction is not supported for %s documents", doc.MimeType)
+	}
+
+	text = strings.TrimSpace(strings.ReplaceAll(text, "\n---PAGE BREAK---\n", "\n\n"))
+	excerpt := text
+	if utf8.RuneCountInString(excerpt) > documentExcerptLength {
+		excerpt = string([]rune(excerpt)[:documentExcerptLength])
+	}
+
+	result := map[string]interface{}{
+		"documentId":    doc.ID,
+		"documentName":  doc.Name,
+		"category":      doc.Category,
+		"analysisType":  analysisType,
+		"extractedText": excerpt,
+		"truncated":     len(excerpt) < len(text),
+		"summary":       nil, // Claude writes the summary from extractedText
+	}
+
+	if text == "" {
+		result["note"] = "No text could be extracted; the document may be a scanned image."
+	}
+
+	// Tax forms get the structured parser output as well
+	if analysisType == analysisTaxImplications && doc.MimeType == "application/pdf" && taxparser.LooksLikeTaxForm(text) {
+		if taxData, err := taxparser.ParsePDFContent(data); err == nil {
+			result["taxData"] = taxData
+		}
+	}
+
+	jsonBytes, _ := json.MarshalIndent(result, "", "  ")
+	return string(jsonBytes), nil
+}
@@ -0,0 +1,214 @@
+package claude
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Stream event types delivered on the SendMessageStream channel
+const (
+	StreamEventDelta   = "delta"   // a chunk of assistant text
+	StreamEventMessage = "message" // the complete response, sent last
+	StreamEventError   = "error"   // the stream failed; no message follows
+)
+
+// StreamEvent is one update from a streaming request
+type StreamEvent struct {
+	Type     string
+	Text     string    // StreamEventDelta
+	Response *Response // StreamEventMessage: reassembled as SendMessage would return it
+	Err      error     // StreamEventError
+}
+
+// sseEvent is the JSON payload of an Anthropic streaming event
+type sseEvent struct {
+	Type         string          `json:"type"`
+	Index        int             `json:"index"`
+	Message      *Response       `json:"message,omitempty"`
+	ContentBlock *ContentBlock   `json:"content_block,omitempty"`
+	Delta        json.RawMessage `json:"delta,omitempty"`
+	Usage        *Usage          `json:"usage,omitempty"`
+	Error        *struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+// streamDelta covers content_block_delta and message_delta payloads
+type streamDelta struct {
+	Type        string `json:"type"`
+	Text        string `json:"text"`
+	PartialJSON string `json:"partial_json"`
+	StopReason  string `json:"stop_reason"`
+}
+
+// SendMessageStream streams a response using the default system prompt and tools
+func (c *Client) SendMessageStream(messages []Message) (<-chan StreamEvent, error) {
+	return c.SendMessageStreamWithConfig(messages, c.systemPrompt, c.tools)
+}
+
+// SendMessageStreamWithConfig streams a response using a per-request system
+// prompt and tool set. Text arrives as delta events while Claude writes; the
+// channel ends with a message event carrying the full response (tool calls
+// included) or an error event, and is then closed.
+func (c *Client) SendMessageStreamWithConfig(messages []Message, systemPrompt string, tools []Tool) (<-chan StreamEvent, error) {
+	if !c.IsConfigured() {
+		return nil, fmt.Errorf("Claude API key not configured")
+	}
+
+	req := struct {
+		Request
+		Stream bool `json:"stream"`
+	}{
+		Request: Request{
+			Model:     defaultModel,
+			MaxTokens: maxTokens,
+			System:    systemPrompt,
+			Messages:  messages,
+			Tools:     tools,
+		},
+		Stream: true,
+	}
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequest("POST", anthropicAPIURL, bytes.NewBuffer(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Accept", "text/event-stream")
+	httpReq.Header.Set("x-api-key", c.apiKey)
+	httpReq.Header.Set("anthropic-version", "2023-06-01")
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		respBody, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(respBody))
+	}
+
+	events := make(chan StreamEvent)
+	go func() {
+		defer close(events)
+		defer resp.Body.Close()
+
+		response, err := readStream(resp.Body, events)
+		if err != nil {
+			events <- StreamEvent{Type: StreamEventError, Err: err}
+			return
+		}
+		events <- StreamEvent{Type: StreamEventMessage, Response: response}
+	}()
+
+	return events, nil
+}
+
+// readStream reads server-sent events until message_stop, forwarding text
+// deltas and reassembling the content blocks into a Response
+func readStream(body io.Reader, events chan<- StreamEvent) (*Response, error) {
+	var response *Response
+	toolInputs := make(map[int]*strings.Builder) // partial tool input JSON by block index
+
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "data:") {
+			continue // event: lines repeat the type carried in the data payload
+		}
+
+		var event sseEvent
+		if err := json.Unmarshal([]byte(strings.TrimSpace(strings.TrimPrefix(line, "data:"))), &event); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal stream event: %w", err)
+		}
+
+		switch event.Type {
+		case "message_start":
+			if event.Message == nil {
+				return nil, fmt.Errorf("message_start without a message")
+			}
+			response = event.Message
+			response.Content = nil
+
+		case "content_block_start":
+			if response == nil || event.ContentBlock == nil {
+				continue
+			}
+			for len(response.Content) <= event.Index {
+				response.Content = append(response.Content, ContentBlock{})
+			}
+			response.Content[event.Index] = *event.ContentBlock
+			if event.ContentBlock.Text != "" {
+				events <- StreamEvent{Type: StreamEventDelta, Text: event.ContentBlock.Text}
+			}
+
+		case "content_block_delta":
+			if response == nil || event.Index >= len(response.Content) {
+				continue
+			}
+			var delta streamDelta
+			if err := json.Unmarshal(event.Delta, &delta); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal stream delta: %w", err)
+			}
+			switch delta.Type {
+			case "text_delta":
+				response.Content[event.Index].Text += delta.Text
+				events <- StreamEvent{Type: StreamEventDelta, Text: delta.Text}
+			case "input_json_delta":
+				if toolInputs[event.Index] == nil {
+					toolInputs[event.Index] = &strings.Builder{}
+				}
+				toolInputs[event.Index].WriteString(delta.PartialJSON)
+			}
+
+		case "content_block_stop":
+			if input, ok := toolInputs[event.Index]; ok && response != nil && event.Index < len(response.Content) {
+				response.Content[event.Index].Input = json.RawMessage(input.String())
+			}
+
+		case "message_delta":
+			if response == nil {
+				continue
+			}
+			var delta streamDelta
+			if err := json.Unmarshal(event.Delta, &delta); err == nil && delta.StopReason != "" {
+				response.StopReason = delta.StopReason
+			}
+			if event.Usage != nil {
+				response.Usage.OutputTokens = event.Usage.OutputTokens
+			}
+
+		case "message_stop":
+			if response == nil {
+				return nil, fmt.Errorf("stream ended before message_start")
+			}
+			return response, nil
+
+		case "error":
+			if event.Error != nil {
+				return nil, fmt.Errorf("stream error (%s): %s", event.Error.Type, event.Error.Message)
+			}
+			return nil, fmt.Errorf("stream error")
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read stream: %w", err)
+	}
+	return nil, fmt.Errorf("stream ended before message_stop")
+}
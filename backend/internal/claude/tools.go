@@ -46,7 +46,7 @@ func GetAureliaTools() []Tool {
 		},
 		{
 			Name:        "get_net_worth_summary",
-			Description: "Get a summary of the user's net worth including total assets, total debts, and net worth calculation. Also includes breakdown by asset type.",
+			Description: "Get a summary of the user's net worth including total assets, total debts, and net worth calculation. Also includes breakdown by asset type and, when history has been recorded, month-end net worth over the last 12 months.",
 			InputSchema: map[string]interface{}{
 				"type":       "object",
 				"properties": map[string]interface{}{},
@@ -341,6 +341,65 @@ func GetAureliaTools() []Tool {
 				"required": []string{"question"},
 			},
 		},
+		{
+			Name:        "run_simulation",
+			Description: "Run a quick Monte Carlo simulation on the current client's assets and debts with the given plan inputs, e.g. to answer 'what if they retire at 60 instead of 65?'. Returns only the success rate, the median (P50) ending portfolio and the top 3 insights; nothing is saved. Computationally expensive: run it at most twice per conversation turn (for example a baseline and one alternative) and use run_monte_carlo when the user wants full projections saved.",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"current_age": map[string]interface{}{
+						"type":        "integer",
+						"description": "Current age. Required.",
+					},
+					"retirement_age": map[string]interface{}{
+						"type":        "integer",
+						"description": "Age at which retirement begins. Defaults to 65.",
+					},
+					"time_horizon_years": map[string]interface{}{
+						"type":        "integer",
+						"description": "Number of years to project (1-80). Defaults to 30.",
+					},
+					"monthly_contribution": map[string]interface{}{
+						"type":        "number",
+						"description": "Monthly investment contribution in dollars until retirement.",
+					},
+					"retirement_spending": map[string]interface{}{
+						"type":        "number",
+						"description": "Monthly spending in retirement in dollars.",
+					},
+					"expected_return": map[string]interface{}{
+						"type":        "number",
+						"description": "Expected annual return as decimal (0.07 = 7%).",
+					},
+					"volatility": map[string]interface{}{
+						"type":        "number",
+						"description": "Standard deviation as decimal (0.15 = 15%).",
+					},
+					"inflation_rate": map[string]interface{}{
+						"type":        "number",
+						"description": "Expected inflation rate as decimal (0.03 = 3%).",
+					},
+					"social_security_amount": map[string]interface{}{
+						"type":        "number",
+						"description": "Monthly Social Security benefit at full retirement age in dollars.",
+					},
+					"social_security_claim_age": map[string]interface{}{
+						"type":        "integer",
+						"description": "Age Social Security is claimed (62-70).",
+					},
+					"withdrawal_strategy": map[string]interface{}{
+						"type":        "string",
+						"enum":        []string{"fixed", "dynamic", "guardrails"},
+						"description": "How retirement withdrawals adjust to markets. Defaults to fixed.",
+					},
+					"retirement_tax_rate": map[string]interface{}{
+						"type":        "number",
+						"description": "Effective tax rate on retirement withdrawals as decimal (0.22 = 22%).",
+					},
+				},
+				"required": []string{"current_age"},
+			},
+		},
 
 		// Advanced Analysis Tools
 		{
@@ -455,10 +514,30 @@ func GetAureliaTools() []Tool {
 			},
 		},
 
+		{
+			Name:        "document_analysis",
+			Description: "Read an uploaded document (PDF or text) from the document vault so you can summarize it, extract its key numbers, or explain its tax implications. Returns the document's extracted text (first 2000 characters); write the summary yourself from that text. For tax_implications on tax forms, structured tax data is included too.",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"documentId": map[string]interface{}{
+						"type":        "integer",
+						"description": "The ID of the document in the document vault.",
+					},
+					"analysisType": map[string]interface{}{
+						"type":        "string",
+						"description": "What to do with the document. Defaults to 'summarize'.",
+						"enum":        []string{"summarize", "extract_numbers", "tax_implications"},
+					},
+				},
+				"required": []string{"documentId", "analysisType"},
+			},
+		},
+
 		// Report Generation Tool
 		{
 			Name:        "generate_report",
-			Description: "Generate a professional PDF financial plan report for the user. The report includes net worth summary, asset/debt details, Monte Carlo projections, milestones, insights, and recommendations. Returns a download URL.",
+			Description: "Generate a professional PDF financial plan report for the user and save it to their document vault. The report includes net worth summary, asset/debt details, Monte Carlo projections from their most recent saved simulation, milestones, insights, and recommendations. The simulation inputs below are only used when no simulation has been saved. Returns the document_id and a download URL; the chat shows a download button.",
 			InputSchema: map[string]interface{}{
 				"type": "object",
 				"properties": map[string]interface{}{
@@ -466,6 +545,10 @@ func GetAureliaTools() []Tool {
 						"type":        "boolean",
 						"description": "Whether to include Monte Carlo simulation in the report. Defaults to true.",
 					},
+					"report_name": map[string]interface{}{
+						"type":        "string",
+						"description": "Title for the report, e.g. 'Retirement Plan 2026'. Defaults to 'Financial Plan Report'.",
+					},
 					"time_horizon_years": map[string]interface{}{
 						"type":        "integer",
 						"description": "Number of years to project for the simulation. Defaults to 30.",
@@ -490,7 +573,66 @@ func GetAureliaTools() []Tool {
 				"required": []string{},
 			},
 		},
+
+		// Goal Tools
+		{
+			Name:        "set_goal",
+			Description: "Create a financial goal for the client, e.g. after recommending one in your analysis. The goal is shared with the client's advisor and appears on their goals list. Only use this when the user agrees to set the goal.",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"title": map[string]interface{}{
+						"type":        "string",
+						"description": "Short goal title, e.g. 'Build 6-month emergency fund'. Required.",
+					},
+					"description": map[string]interface{}{
+						"type":        "string",
+						"description": "Details on what the goal involves and why it matters.",
+					},
+					"category": map[string]interface{}{
+						"type":        "string",
+						"description": "Goal category. Defaults to 'other'.",
+						"enum":        []string{"retirement", "savings", "debt", "investment", "education", "emergency", "major_purchase", "other"},
+					},
+					"priority": map[string]interface{}{
+						"type":        "string",
+						"description": "Goal priority. Defaults to 'medium'.",
+						"enum":        []string{"low", "medium", "high"},
+					},
+					"targetAmount": map[string]interface{}{
+						"type":        "number",
+						"description": "Dollar amount to reach, if the goal has one.",
+					},
+					"targetDate": map[string]interface{}{
+						"type":        "string",
+						"description": "Target completion date in YYYY-MM-DD format.",
+					},
+				},
+				"required": []string{"title"},
+			},
+		},
+	}
+}
+
+// FilterTools returns only the tools whose names appear in enabled.
+// A nil enabled list means no filtering.
+func FilterTools(tools []Tool, enabled []string) []Tool {
+	if enabled == nil {
+		return tools
+	}
+
+	allowed := make(map[string]bool, len(enabled))
+	for _, name := range enabled {
+		allowed[name] = true
+	}
+
+	var filtered []Tool
+	for _, t := range tools {
+		if allowed[t.Name] {
+			filtered = append(filtered, t)
+		}
 	}
+	return filtered
 }
 
 // GetAdvisorTools returns additional tools available only to advisors
@@ -0,0 +1,60 @@
+package db
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// How long a confirmed advisor-client relationship is trusted without
+// rechecking the database
+const ClientAccessCacheTTL = 60 * time.Second
+
+// clientAccessCache holds the expiry time of confirmed relationships, keyed
+// by "advisorID:clientID". Only active relationships are cached, so a new or
+// reactivated one takes effect immediately. Handlers that revoke access clear
+// the entry, but this cache is per process: with several API servers, or a
+// change made directly in the database, an advisor can keep access for up to
+// ClientAccessCacheTTL after revocation.
+var clientAccessCache sync.Map
+
+func clientAccessKey(advisorID, clientID int) string {
+	return strconv.Itoa(advisorID) + ":" + strconv.Itoa(clientID)
+}
+
+// AdvisorHasClientAccess checks if the advisor has an active relationship with the client
+func AdvisorHasClientAccess(advisorID, clientID int) bool {
+	key := clientAccessKey(advisorID, clientID)
+	if expires, ok := clientAccessCache.Load(key); ok && time.Now().Before(expires.(time.Time)) {
+		return true
+	}
+
+	var count int
+	err := DB.QueryRow(
+		`SELECT COUNT(*) FROM advisor_clients WHERE advisor_id = ? AND client_id = ? AND status = 'active'`,
+		advisorID, clientID,
+	).Scan(&count)
+	if err != nil || count == 0 {
+		clientAccessCache.Delete(key)
+		return false
+	}
+	clientAccessCache.Store(key, time.Now().Add(ClientAccessCacheTTL))
+	return true
+}
+
+// InvalidateClientAccess drops a cached relationship after it changes
+func InvalidateClientAccess(advisorID, clientID int) {
+	clientAccessCache.Delete(clientAccessKey(advisorID, clientID))
+}
+
+// InvalidateAdvisorAccess drops every cached relationship of an advisor
+func InvalidateAdvisorAccess(advisorID int) {
+	prefix := strconv.Itoa(advisorID) + ":"
+	clientAccessCache.Range(func(key, _ any) bool {
+		if strings.HasPrefix(key.(string), prefix) {
+			clientAccessCache.Delete(key)
+		}
+		return true
+	})
+}
@@ -0,0 +1,88 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"testing"
+	"time"
+)
+
+// accessCheckRoundTrip stands in for the network round trip of a MySQL query
+const accessCheckRoundTrip = 200 * time.Microsecond
+
+// activeLinkDriver is a database/sql driver whose every query sleeps for
+// accessCheckRoundTrip and returns a single row holding 1, as the
+// advisor_clients COUNT(*) would for an active relationship
+type activeLinkDriver struct{}
+
+func (activeLinkDriver) Open(string) (driver.Conn, error) { return activeLinkConn{}, nil }
+
+type activeLinkConnector struct{}
+
+func (activeLinkConnector) Connect(context.Context) (driver.Conn, error) {
+	return activeLinkConn{}, nil
+}
+func (activeLinkConnector) Driver() driver.Driver { return activeLinkDriver{} }
+
+type activeLinkConn struct{}
+
+func (activeLinkConn) Prepare(string) (driver.Stmt, error) { return activeLinkStmt{}, nil }
+func (activeLinkConn) Close() error                        { return nil }
+func (activeLinkConn) Begin() (driver.Tx, error)           { return nil, driver.ErrSkip }
+
+type activeLinkStmt struct{}
+
+func (activeLinkStmt) Close() error                               { return nil }
+func (activeLinkStmt) NumInput() int                              { return -1 }
+func (activeLinkStmt) Exec([]driver.Value) (driver.Result, error) { return driver.RowsAffected(0), nil }
+func (activeLinkStmt) Query([]driver.Value) (driver.Rows, error) {
+	time.Sleep(accessCheckRoundTrip)
+	return &activeLinkRows{}, nil
+}
+
+type activeLinkRows struct{ done bool }
+
+func (*activeLinkRows) Columns() []string { return []string{"count"} }
+func (*activeLinkRows) Close() error      { return nil }
+func (r *activeLinkRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	dest[0] = int64(1)
+	return nil
+}
+
+// BenchmarkAdvisorHasClientAccess compares an access check answered by the
+// cache with one that has to query advisor_clients
+func BenchmarkAdvisorHasClientAccess(b *testing.B) {
+	prev := DB
+	DB = sql.OpenDB(activeLinkConnector{})
+	b.Cleanup(func() {
+		DB.Close()
+		DB = prev
+		InvalidateClientAccess(1, 2)
+	})
+
+	b.Run("uncached", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			InvalidateClientAccess(1, 2)
+			if !AdvisorHasClientAccess(1, 2) {
+				b.Fatal("expected access")
+			}
+		}
+	})
+
+	b.Run("cached", func(b *testing.B) {
+		InvalidateClientAccess(1, 2)
+		AdvisorHasClientAccess(1, 2)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if !AdvisorHasClientAccess(1, 2) {
+				b.Fatal("expected access")
+			}
+		}
+	})
+}
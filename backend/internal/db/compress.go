@@ -0,0 +1,46 @@
+package db
+
+import (
+	"bytes"
+	"compress/gzip"
+	"database/sql"
+	"io"
+)
+
+// GzipJSON compresses a JSON document for storage in a BLOB column
+func GzipJSON(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	zw, err := gzip.NewWriterLevel(&buf, gzip.BestCompression)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := zw.Write(data); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// GunzipJSON decompresses a JSON document written by GzipJSON
+func GunzipJSON(data []byte) ([]byte, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	return io.ReadAll(zr)
+}
+
+// SimulationResultsJSON returns simulation results from the compressed column when
+// present, falling back to the plain JSON column used by legacy rows
+func SimulationResultsJSON(plain sql.NullString, compressed []byte) ([]byte, error) {
+	if len(compressed) > 0 {
+		return GunzipJSON(compressed)
+	}
+	if plain.Valid {
+		return []byte(plain.String), nil
+	}
+	return nil, nil
+}
@@ -1,13 +1,16 @@
 package db
 
 import (
+	"context"
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
 	"fmt"
 	"log"
 	"os"
 	"time"
 
-	_ "github.com/go-sql-driver/mysql"
+	"github.com/go-sql-driver/mysql"
 )
 
 var DB *sql.DB
@@ -21,11 +24,16 @@ func Connect() error {
 
 	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?parseTime=true", user, password, host, port, dbname)
 
-	var err error
-	DB, err = sql.Open("mysql", dsn)
+	cfg, err := mysql.ParseDSN(dsn)
+	if err != nil {
+		return fmt.Errorf("failed to parse database config: %w", err)
+	}
+	connector, err := mysql.NewConnector(cfg)
 	if err != nil {
 		return fmt.Errorf("failed to open database: %w", err)
 	}
+	// Statements are counted for the db_queries_total metric
+	DB = sql.OpenDB(countingConnector{connector})
 
 	DB.SetMaxOpenConns(25)
 	DB.SetMaxIdleConns(5)
@@ -61,6 +69,10 @@ func RunMigrations() error {
 			name VARCHAR(255) NOT NULL,
 			role ENUM('client', 'advisor') NOT NULL DEFAULT 'client',
 			created_by_advisor_id INT NULL,
+			mfa_secret TEXT NULL,
+			mfa_enabled BOOLEAN NOT NULL DEFAULT FALSE,
+			mfa_backup_codes TEXT NULL,
+			mfa_last_totp_step BIGINT NULL,
 			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
 			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
 		)`,
@@ -80,8 +92,12 @@ func RunMigrations() error {
 			custom_return DECIMAL(5,2),
 			custom_volatility DECIMAL(5,2),
 			plaid_account_id VARCHAR(255),
+			plaid_security_id VARCHAR(255),
+			ticker_symbol VARCHAR(20) NULL,
+			quantity DECIMAL(24,8) NOT NULL DEFAULT 1,
 			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
 			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+			deleted_at TIMESTAMP NULL,
 			FOREIGN KEY (type_id) REFERENCES asset_types(id),
 			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
 		)`,
@@ -93,6 +109,7 @@ func RunMigrations() error {
 			interest_rate DECIMAL(5,2),
 			minimum_payment DECIMAL(10,2),
 			plaid_account_id VARCHAR(255),
+			debt_subtype VARCHAR(50) NULL,
 			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
 			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
 			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
@@ -106,6 +123,7 @@ func RunMigrations() error {
 			institution_id VARCHAR(255),
 			institution_name VARCHAR(255),
 			status VARCHAR(50) DEFAULT 'active',
+			error_code VARCHAR(100) NULL,
 			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
 			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
 			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
@@ -146,11 +164,15 @@ func RunMigrations() error {
 			pending BOOLEAN DEFAULT FALSE,
 			transaction_type VARCHAR(50),
 			iso_currency_code VARCHAR(10),
+			source ENUM('plaid', 'manual', 'ofx') NOT NULL DEFAULT 'manual',
+			fitid VARCHAR(255) NULL,
+			deleted_at TIMESTAMP NULL,
 			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
 			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
 			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
 			INDEX idx_user_date (user_id, date),
-			INDEX idx_user_category (user_id, category)
+			INDEX idx_user_category (user_id, category),
+			INDEX idx_user_fitid (user_id, fitid)
 		)`,
 		// Advisor-Client relationships
 		`CREATE TABLE IF NOT EXISTS advisor_clients (
@@ -176,7 +198,8 @@ func RunMigrations() error {
 			name VARCHAR(255),
 			notes TEXT,
 			params JSON NOT NULL,
-			results JSON NOT NULL,
+			results JSON NULL,
+			results_compressed MEDIUMBLOB NULL,
 			starting_net_worth DECIMAL(15,2) NOT NULL,
 			final_p50 DECIMAL(15,2) NOT NULL,
 			success_rate DECIMAL(5,2) NOT NULL,
@@ -197,6 +220,8 @@ func RunMigrations() error {
 			expires_at TIMESTAMP NOT NULL,
 			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
 			accepted_at TIMESTAMP NULL,
+			last_reminder_at TIMESTAMP NULL,
+			no_reminder BOOLEAN NOT NULL DEFAULT FALSE,
 			FOREIGN KEY (advisor_id) REFERENCES users(id) ON DELETE CASCADE,
 			UNIQUE KEY unique_token (invitation_token),
 			INDEX idx_email (client_email)
@@ -220,11 +245,28 @@ func RunMigrations() error {
 			last_message_at TIMESTAMP NULL,
 			unread_count_advisor INT DEFAULT 0,
 			unread_count_client INT DEFAULT 0,
+			is_group BOOLEAN NOT NULL DEFAULT FALSE,
+			status ENUM('active', 'archived') NOT NULL DEFAULT 'active',
+			archived_at TIMESTAMP NULL,
+			archived_by INT NULL,
 			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
 			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
 			FOREIGN KEY (advisor_id) REFERENCES users(id) ON DELETE CASCADE,
 			FOREIGN KEY (client_id) REFERENCES users(id) ON DELETE CASCADE,
-			UNIQUE KEY unique_conversation (advisor_id, client_id)
+			INDEX idx_advisor_client (advisor_id, client_id)
+		)`,
+		// Conversation members - advisor_id/client_id on conversations identify the
+		// owning advisor and the client; group conversations add more advisors here
+		`CREATE TABLE IF NOT EXISTS conversation_participants (
+			conversation_id INT NOT NULL,
+			user_id INT NOT NULL,
+			role ENUM('advisor', 'client') NOT NULL,
+			unread_count INT NOT NULL DEFAULT 0,
+			joined_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (conversation_id, user_id),
+			FOREIGN KEY (conversation_id) REFERENCES conversations(id) ON DELETE CASCADE,
+			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+			INDEX idx_user (user_id)
 		)`,
 		// E2E encrypted messages
 		`CREATE TABLE IF NOT EXISTS messages (
@@ -234,6 +276,7 @@ func RunMigrations() error {
 			encrypted_content TEXT NOT NULL,
 			nonce VARCHAR(64) NOT NULL,
 			read_at TIMESTAMP NULL,
+			delivered_at TIMESTAMP NULL,
 			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
 			FOREIGN KEY (conversation_id) REFERENCES conversations(id) ON DELETE CASCADE,
 			FOREIGN KEY (sender_id) REFERENCES users(id) ON DELETE CASCADE,
@@ -248,7 +291,7 @@ func RunMigrations() error {
 			original_name VARCHAR(255) NOT NULL,
 			mime_type VARCHAR(100) NOT NULL,
 			size BIGINT NOT NULL,
-			category ENUM('tax_returns', 'statements', 'estate_docs', 'insurance', 'investments', 'reports', 'other') NOT NULL DEFAULT 'other',
+			category ENUM('tax_returns', 'statements', 'estate_docs', 'insurance', 'investments', 'reports', 'advisor_report', 'other') NOT NULL DEFAULT 'other',
 			storage_path VARCHAR(500) NOT NULL,
 			encrypted BOOLEAN DEFAULT TRUE,
 			description TEXT,
@@ -256,8 +299,10 @@ func RunMigrations() error {
 			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
 			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
 			deleted_at TIMESTAMP NULL,
+			document_id_original INT NULL,
 			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
 			FOREIGN KEY (uploaded_by) REFERENCES users(id) ON DELETE CASCADE,
+			CONSTRAINT fk_documents_original FOREIGN KEY (document_id_original) REFERENCES documents(id) ON DELETE SET NULL,
 			INDEX idx_user_category (user_id, category),
 			INDEX idx_user_deleted (user_id, deleted_at)
 		)`,
@@ -270,10 +315,38 @@ func RunMigrations() error {
 			permission ENUM('view', 'download') NOT NULL DEFAULT 'view',
 			expires_at TIMESTAMP NULL,
 			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			revoked_at TIMESTAMP NULL,
+			revoked_reason VARCHAR(20) NULL,
 			FOREIGN KEY (document_id) REFERENCES documents(id) ON DELETE CASCADE,
 			FOREIGN KEY (shared_with_id) REFERENCES users(id) ON DELETE CASCADE,
 			FOREIGN KEY (shared_by_id) REFERENCES users(id) ON DELETE CASCADE,
-			UNIQUE KEY unique_share (document_id, shared_with_id)
+			INDEX idx_document_shared_with (document_id, shared_with_id),
+			INDEX idx_expires (expires_at)
+		)`,
+		// Counters for periodic maintenance jobs
+		`CREATE TABLE IF NOT EXISTS maintenance_stats (
+			stat_name VARCHAR(100) PRIMARY KEY,
+			value BIGINT NOT NULL DEFAULT 0,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+		)`,
+		// E-signature requests sent by advisors for client documents
+		`CREATE TABLE IF NOT EXISTS document_signature_requests (
+			id INT PRIMARY KEY AUTO_INCREMENT,
+			document_id INT NOT NULL,
+			advisor_id INT NOT NULL,
+			client_id INT NOT NULL,
+			status ENUM('pending', 'sent', 'signed', 'declined') NOT NULL DEFAULT 'pending',
+			external_signature_id VARCHAR(255),
+			external_provider ENUM('docusign', 'hellosign', 'stub') NOT NULL DEFAULT 'stub',
+			sent_at TIMESTAMP NULL,
+			signed_at TIMESTAMP NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+			FOREIGN KEY (document_id) REFERENCES documents(id) ON DELETE CASCADE,
+			FOREIGN KEY (advisor_id) REFERENCES users(id) ON DELETE CASCADE,
+			FOREIGN KEY (client_id) REFERENCES users(id) ON DELETE CASCADE,
+			INDEX idx_document (document_id),
+			INDEX idx_client_status (client_id, status)
 		)`,
 		// Client notes - advisor notes about clients for meeting prep
 		`CREATE TABLE IF NOT EXISTS client_notes (
@@ -312,6 +385,290 @@ func RunMigrations() error {
 			INDEX idx_advisor_client_goals (advisor_id, client_id),
 			INDEX idx_status (status)
 		)`,
+		// Advisor AI config - per-firm customization of the Aurelia assistant
+		`CREATE TABLE IF NOT EXISTS advisor_ai_config (
+			id INT PRIMARY KEY AUTO_INCREMENT,
+			advisor_id INT NOT NULL,
+			firm_name_for_ai VARCHAR(255),
+			custom_system_prompt_suffix TEXT,
+			enabled_tools JSON NULL,
+			disclaimer_text TEXT,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+			FOREIGN KEY (advisor_id) REFERENCES users(id) ON DELETE CASCADE,
+			UNIQUE KEY unique_advisor (advisor_id)
+		)`,
+		// Advisor branding - white-label firm name, logo and colors for PDF reports
+		`CREATE TABLE IF NOT EXISTS advisor_branding (
+			id INT PRIMARY KEY AUTO_INCREMENT,
+			advisor_id INT NOT NULL,
+			firm_name VARCHAR(255),
+			logo MEDIUMBLOB NULL,
+			logo_mime_type VARCHAR(50),
+			primary_color_hex VARCHAR(7),
+			secondary_color_hex VARCHAR(7),
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+			FOREIGN KEY (advisor_id) REFERENCES users(id) ON DELETE CASCADE,
+			UNIQUE KEY unique_advisor (advisor_id)
+		)`,
+		// Audit log - security-relevant account events
+		`CREATE TABLE IF NOT EXISTS audit_log (
+			id INT PRIMARY KEY AUTO_INCREMENT,
+			user_id INT NOT NULL,
+			action VARCHAR(100) NOT NULL,
+			ip_address VARCHAR(45),
+			details TEXT,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+			INDEX idx_user_created (user_id, created_at DESC)
+		)`,
+		// Pending Plaid OAuth redirects - verified when Link resumes after the bank redirect
+		`CREATE TABLE IF NOT EXISTS plaid_oauth_sessions (
+			oauth_state_id CHAR(36) PRIMARY KEY,
+			user_id INT NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			expires_at TIMESTAMP NOT NULL,
+			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+			INDEX idx_created (created_at)
+		)`,
+		// Plaid webhook log - raw verified payloads kept for debugging
+		`CREATE TABLE IF NOT EXISTS plaid_webhook_events (
+			id INT PRIMARY KEY AUTO_INCREMENT,
+			item_id VARCHAR(255),
+			webhook_type VARCHAR(50) NOT NULL,
+			webhook_code VARCHAR(100) NOT NULL,
+			payload JSON NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			INDEX idx_item_created (item_id, created_at DESC)
+		)`,
+		// Saved Aurelia chats
+		`CREATE TABLE IF NOT EXISTS chat_conversations (
+			id INT PRIMARY KEY AUTO_INCREMENT,
+			user_id INT NOT NULL,
+			title VARCHAR(255) NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+			INDEX idx_user_updated (user_id, updated_at DESC)
+		)`,
+		`CREATE TABLE IF NOT EXISTS chat_messages (
+			id INT PRIMARY KEY AUTO_INCREMENT,
+			conversation_id INT NOT NULL,
+			role ENUM('user', 'assistant') NOT NULL,
+			content_text MEDIUMTEXT NOT NULL,
+			tool_calls_json JSON NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (conversation_id) REFERENCES chat_conversations(id) ON DELETE CASCADE,
+			INDEX idx_conversation_id (conversation_id, id)
+		)`,
+		// Prior contents of edited client notes (compliance audit trail). Rows
+		// outlive the note so deleting a note doesn't erase its history.
+		`CREATE TABLE IF NOT EXISTS client_note_versions (
+			id INT PRIMARY KEY AUTO_INCREMENT,
+			note_id INT NOT NULL,
+			advisor_id INT NOT NULL,
+			note_text TEXT NOT NULL,
+			edited_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (advisor_id) REFERENCES users(id) ON DELETE CASCADE,
+			INDEX idx_note_edited (note_id, edited_at DESC)
+		)`,
+		// The saved simulation each goal's progress is forecast from (one per goal)
+		`CREATE TABLE IF NOT EXISTS goal_simulation_links (
+			goal_id INT PRIMARY KEY,
+			simulation_id INT NOT NULL,
+			linked_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (goal_id) REFERENCES client_goals(id) ON DELETE CASCADE,
+			FOREIGN KEY (simulation_id) REFERENCES simulation_history(id) ON DELETE CASCADE
+		)`,
+		// Documents an advisor has asked a client to upload. An upload that
+		// names the request fills fulfilled_document_id.
+		`CREATE TABLE IF NOT EXISTS document_requests (
+			id INT AUTO_INCREMENT PRIMARY KEY,
+			advisor_id INT NOT NULL,
+			client_id INT NOT NULL,
+			title VARCHAR(255) NOT NULL,
+			description TEXT NULL,
+			category VARCHAR(50) NOT NULL DEFAULT 'other',
+			due_date DATE NULL,
+			status ENUM('pending', 'fulfilled', 'cancelled') NOT NULL DEFAULT 'pending',
+			fulfilled_document_id INT NULL,
+			fulfilled_at TIMESTAMP NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+			FOREIGN KEY (advisor_id) REFERENCES users(id) ON DELETE CASCADE,
+			FOREIGN KEY (client_id) REFERENCES users(id) ON DELETE CASCADE,
+			FOREIGN KEY (fulfilled_document_id) REFERENCES documents(id) ON DELETE SET NULL,
+			INDEX idx_document_requests_client (client_id, status)
+		)`,
+		// Text extracted from uploaded PDFs for document search
+		`CREATE TABLE IF NOT EXISTS document_text_index (
+			document_id INT PRIMARY KEY,
+			extracted_text TEXT NOT NULL,
+			extraction_confidence FLOAT NOT NULL DEFAULT 0,
+			extracted_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (document_id) REFERENCES documents(id) ON DELETE CASCADE
+		)`,
+		// Searchable copy of messages sent without E2E encryption
+		`CREATE TABLE IF NOT EXISTS messages_plaintext_index (
+			message_id INT PRIMARY KEY,
+			conversation_id INT NOT NULL,
+			content TEXT NOT NULL,
+			indexed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (message_id) REFERENCES messages(id) ON DELETE CASCADE,
+			INDEX idx_plaintext_conversation (conversation_id)
+		)`,
+		// Device tokens for push notifications (FCM)
+		`CREATE TABLE IF NOT EXISTS push_tokens (
+			id INT AUTO_INCREMENT PRIMARY KEY,
+			user_id INT NOT NULL,
+			token VARCHAR(512) NOT NULL,
+			platform ENUM('ios', 'android', 'web') NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+			UNIQUE KEY unique_push_token (token),
+			INDEX idx_push_tokens_user (user_id)
+		)`,
+		// Refresh tokens, one per signed-in session. Rotated on every refresh: the
+		// old row is revoked and points at its replacement.
+		`CREATE TABLE IF NOT EXISTS refresh_tokens (
+			id INT AUTO_INCREMENT PRIMARY KEY,
+			user_id INT NOT NULL,
+			token_hash CHAR(64) NOT NULL,
+			issued_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			expires_at TIMESTAMP NOT NULL,
+			revoked_at TIMESTAMP NULL,
+			replaced_by INT NULL,
+			user_agent VARCHAR(255),
+			ip_address VARCHAR(45),
+			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+			UNIQUE KEY unique_token_hash (token_hash),
+			INDEX idx_refresh_tokens_user (user_id, revoked_at)
+		)`,
+		// Password checks at login, for locking accounts after repeated failures
+		`CREATE TABLE IF NOT EXISTS login_attempts (
+			id INT AUTO_INCREMENT PRIMARY KEY,
+			user_id INT NOT NULL,
+			attempt_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			success BOOLEAN NOT NULL,
+			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+			INDEX idx_login_attempts_user (user_id, attempt_at)
+		)`,
+		// GDPR exports of a user's personal data; the JSON file is a document
+		// that is deleted when the export expires
+		`CREATE TABLE IF NOT EXISTS data_exports (
+			id INT AUTO_INCREMENT PRIMARY KEY,
+			user_id INT NOT NULL,
+			status ENUM('pending', 'completed', 'failed') NOT NULL DEFAULT 'pending',
+			document_id INT NULL,
+			error TEXT,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			completed_at TIMESTAMP NULL,
+			expires_at TIMESTAMP NULL,
+			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+			FOREIGN KEY (document_id) REFERENCES documents(id) ON DELETE SET NULL,
+			INDEX idx_data_exports_user (user_id, created_at),
+			INDEX idx_data_exports_expires (expires_at)
+		)`,
+		// API keys for advisors' integrations. scopes is a JSON array such as
+		// ["read:assets", "write:simulations"]; revoked keys are kept for auditing
+		`CREATE TABLE IF NOT EXISTS api_keys (
+			id INT AUTO_INCREMENT PRIMARY KEY,
+			user_id INT NOT NULL,
+			key_hash CHAR(64) NOT NULL,
+			key_prefix VARCHAR(16) NOT NULL,
+			name VARCHAR(100) NOT NULL,
+			scopes JSON NOT NULL,
+			last_used_at TIMESTAMP NULL,
+			expires_at TIMESTAMP NULL,
+			revoked_at TIMESTAMP NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+			UNIQUE KEY unique_key_hash (key_hash),
+			INDEX idx_api_keys_user (user_id, revoked_at)
+		)`,
+		// User-defined rules that override Plaid's transaction categories
+		`CREATE TABLE IF NOT EXISTS transaction_rules (
+			id INT AUTO_INCREMENT PRIMARY KEY,
+			user_id INT NOT NULL,
+			match_field ENUM('name', 'merchant_name') NOT NULL,
+			match_operator ENUM('contains', 'equals', 'starts_with') NOT NULL,
+			match_value VARCHAR(255) NOT NULL,
+			category VARCHAR(100) NOT NULL,
+			subcategory VARCHAR(100),
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+			INDEX idx_transaction_rules_user (user_id)
+		)`,
+		// Daily net worth totals recorded by the snapshots package
+		`CREATE TABLE IF NOT EXISTS net_worth_history (
+			id INT AUTO_INCREMENT PRIMARY KEY,
+			user_id INT NOT NULL,
+			total_assets DECIMAL(15,2) NOT NULL,
+			total_debts DECIMAL(15,2) NOT NULL,
+			net_worth DECIMAL(15,2) NOT NULL,
+			snapshot_date DATE NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+			UNIQUE KEY unique_user_snapshot_date (user_id, snapshot_date)
+		)`,
+		// Security-level positions from Plaid Investments, refreshed by each holdings sync
+		`CREATE TABLE IF NOT EXISTS plaid_investment_holdings (
+			id INT AUTO_INCREMENT PRIMARY KEY,
+			user_id INT NOT NULL,
+			account_id VARCHAR(255) NOT NULL,
+			security_id VARCHAR(255) NOT NULL,
+			ticker_symbol VARCHAR(20),
+			name VARCHAR(255) NOT NULL,
+			quantity DECIMAL(20,6) NOT NULL,
+			institution_value DECIMAL(15,2) NOT NULL,
+			cost_basis DECIMAL(15,2),
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+			UNIQUE KEY unique_account_security (user_id, account_id, security_id)
+		)`,
+		// Schema versions applied by RunMigrations, checked by the readiness probe
+		`CREATE TABLE IF NOT EXISTS schema_migrations (
+			version CHAR(64) PRIMARY KEY,
+			applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
+		// Responses to requests sent with an X-Idempotency-Key, replayed for
+		// retries until expires_at. status_code is NULL while the first request runs.
+		`CREATE TABLE IF NOT EXISTS idempotency_keys (
+			idempotency_key VARCHAR(255) NOT NULL,
+			user_id INT NOT NULL,
+			request_path VARCHAR(512) NOT NULL,
+			status_code INT NULL,
+			response_body MEDIUMBLOB NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			expires_at TIMESTAMP NOT NULL,
+			PRIMARY KEY (user_id, idempotency_key),
+			INDEX idx_idempotency_keys_expires (expires_at),
+			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+		)`,
+		// Latest USD price per crypto ticker, refreshed by the prices poller
+		`CREATE TABLE IF NOT EXISTS crypto_prices (
+			symbol VARCHAR(20) PRIMARY KEY,
+			price_usd DECIMAL(24,8) NOT NULL,
+			source VARCHAR(50) NOT NULL,
+			fetched_at TIMESTAMP NOT NULL
+		)`,
+		// Wrong codes submitted with each partial MFA login token; a token is
+		// refused once it reaches the limit or has been used to sign in
+		`CREATE TABLE IF NOT EXISTS mfa_token_failures (
+			token_hash CHAR(64) PRIMARY KEY,
+			failures INT NOT NULL DEFAULT 0,
+			expires_at TIMESTAMP NOT NULL,
+			INDEX idx_mfa_token_failures_expires (expires_at)
+		)`,
+		// Per-user token buckets for rate-limited endpoints. request_count is the
+		// bucket's spent tokens as of window_start; it drains continuously.
+		`CREATE TABLE IF NOT EXISTS rate_limits (
+			user_id INT PRIMARY KEY,
+			window_start TIMESTAMP(3) NOT NULL,
+			request_count DOUBLE NOT NULL DEFAULT 0,
+			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+		)`,
 	}
 
 	for _, migration := range migrations {
@@ -321,6 +678,54 @@ func RunMigrations() error {
 		}
 	}
 
+	// Columns added since their table was created. New databases get them
+	// from CREATE TABLE above; existing ones get them here.
+	addedColumns := []addedColumn{
+		// TOTP two-factor authentication. mfa_secret is set at setup and only
+		// enforced once mfa_enabled; mfa_backup_codes is a JSON array of hashes.
+		// mfa_last_totp_step is the time step of the last code accepted, so a
+		// code can't be replayed.
+		{"users", "mfa_secret", "TEXT NULL"},
+		{"users", "mfa_enabled", "BOOLEAN NOT NULL DEFAULT FALSE"},
+		{"users", "mfa_backup_codes", "TEXT NULL"},
+		{"users", "mfa_last_totp_step", "BIGINT NULL"},
+		// Archived conversations are read-only
+		{"conversations", "status", "ENUM('active', 'archived') NOT NULL DEFAULT 'active'"},
+		{"conversations", "archived_at", "TIMESTAMP NULL"},
+		{"conversations", "archived_by", "INT NULL"},
+		// Set when a message first reaches a recipient
+		{"messages", "delivered_at", "TIMESTAMP NULL"},
+		// Manually entered transactions. Anything without a Plaid ID (including
+		// file imports) counts as manual; Plaid rows are soft-deleted so a
+		// later sync doesn't bring them back
+		{"transactions", "source", "ENUM('plaid', 'manual', 'ofx') NOT NULL DEFAULT 'manual'"},
+		{"transactions", "deleted_at", "TIMESTAMP NULL"},
+		// OFX/QFX imports, deduplicated on the statement's FITID
+		{"transactions", "fitid", "VARCHAR(255) NULL"},
+		// Deleted assets are kept for a while so they can be restored
+		{"assets", "deleted_at", "TIMESTAMP NULL"},
+		// Crypto assets are valued at the polled price of ticker_symbol times
+		// quantity; quantity defaults to 1 so other assets are unaffected
+		{"assets", "ticker_symbol", "VARCHAR(20) NULL"},
+		{"assets", "quantity", "DECIMAL(24,8) NOT NULL DEFAULT 1"},
+		// Assets synced from Plaid investment holdings: one row per security in an account
+		{"assets", "plaid_security_id", "VARCHAR(255)"},
+		// Last Plaid error for an item (e.g. ITEM_LOGIN_REQUIRED), cleared on re-link
+		{"plaid_items", "error_code", "VARCHAR(100) NULL"},
+		// Document versions: a replacement upload points at the row it replaced,
+		// which is soft-deleted
+		{"documents", "document_id_original", "INT NULL"},
+		// Kind of Plaid liability a debt was synced from (credit_card, mortgage, student_loan)
+		{"debts", "debt_subtype", "VARCHAR(50) NULL"},
+	}
+	columnStatements := make([]string, len(addedColumns))
+	for i, c := range addedColumns {
+		if err := addColumnIfMissing(c); err != nil {
+			return fmt.Errorf("adding column %s.%s failed: %w", c.table, c.column, err)
+		}
+		columnStatements[i] = c.statement()
+	}
+
 	// Add columns if missing (for existing databases)
 	alterMigrations := []string{
 		`ALTER TABLE assets ADD COLUMN IF NOT EXISTS plaid_account_id VARCHAR(255)`,
@@ -328,6 +733,41 @@ func RunMigrations() error {
 		// Add role support to users table for existing databases
 		`ALTER TABLE users ADD COLUMN IF NOT EXISTS role ENUM('client', 'advisor') NOT NULL DEFAULT 'client'`,
 		`ALTER TABLE users ADD COLUMN IF NOT EXISTS created_by_advisor_id INT NULL`,
+		// Gzip-compressed simulation results; results stays as a fallback for legacy rows
+		`ALTER TABLE simulation_history ADD COLUMN IF NOT EXISTS results_compressed MEDIUMBLOB NULL`,
+		`ALTER TABLE simulation_history MODIFY results JSON NULL`,
+		// Full-text search on advisor notes (fails harmlessly if the index already exists)
+		`ALTER TABLE client_notes ADD FULLTEXT INDEX idx_note_fulltext (note)`,
+		`ALTER TABLE document_text_index ADD FULLTEXT INDEX idx_document_text_fulltext (extracted_text)`,
+		`ALTER TABLE messages_plaintext_index ADD FULLTEXT INDEX idx_plaintext_fulltext (content)`,
+		// One-time reminder emails for pending client invitations
+		`ALTER TABLE client_invitations ADD COLUMN IF NOT EXISTS last_reminder_at TIMESTAMP NULL`,
+		`ALTER TABLE client_invitations ADD COLUMN IF NOT EXISTS no_reminder BOOLEAN NOT NULL DEFAULT FALSE`,
+		// Group conversations: 1:1 uniqueness is enforced in code, and existing
+		// conversations get their advisor and client as participants
+		`ALTER TABLE conversations ADD COLUMN IF NOT EXISTS is_group BOOLEAN NOT NULL DEFAULT FALSE`,
+		`ALTER TABLE conversations ADD INDEX idx_advisor_client (advisor_id, client_id)`,
+		`ALTER TABLE conversations DROP INDEX unique_conversation`,
+		`INSERT IGNORE INTO conversation_participants (conversation_id, user_id, role, unread_count, joined_at)
+			SELECT id, advisor_id, 'advisor', unread_count_advisor, created_at FROM conversations`,
+		`INSERT IGNORE INTO conversation_participants (conversation_id, user_id, role, unread_count, joined_at)
+			SELECT id, client_id, 'client', unread_count_client, created_at FROM conversations`,
+		// Share history: revoked/expired shares are kept (soft delete), so a
+		// document can have several share rows for the same recipient
+		`ALTER TABLE document_shares ADD COLUMN IF NOT EXISTS revoked_at TIMESTAMP NULL`,
+		`ALTER TABLE document_shares ADD COLUMN IF NOT EXISTS revoked_reason VARCHAR(20) NULL`,
+		`ALTER TABLE document_shares ADD INDEX idx_document_shared_with (document_id, shared_with_id)`,
+		`ALTER TABLE document_shares ADD INDEX idx_expires (expires_at)`,
+		`ALTER TABLE document_shares DROP INDEX unique_share`,
+		// Client dossiers exported by advisors
+		`ALTER TABLE documents MODIFY category ENUM('tax_returns', 'statements', 'estate_docs', 'insurance', 'investments', 'reports', 'advisor_report', 'other') NOT NULL DEFAULT 'other'`,
+		// Foreign key for document_id_original on databases from before versions
+		`ALTER TABLE documents ADD CONSTRAINT fk_documents_original FOREIGN KEY (document_id_original) REFERENCES documents(id) ON DELETE SET NULL`,
+		// Transactions from before source existed: the ones with a Plaid ID came from Plaid
+		`UPDATE transactions SET source = 'plaid' WHERE plaid_transaction_id IS NOT NULL AND source = 'manual'`,
+		// OFX/QFX imports on databases whose source column predates them
+		`ALTER TABLE transactions MODIFY source ENUM('plaid', 'manual', 'ofx') NOT NULL DEFAULT 'manual'`,
+		`ALTER TABLE transactions ADD INDEX idx_user_fitid (user_id, fitid)`,
 	}
 	for _, m := range alterMigrations {
 		DB.Exec(m) // Ignore errors - column may already exist
@@ -336,10 +776,66 @@ func RunMigrations() error {
 	// Seed default asset types
 	seedAssetTypes()
 
+	statements := append(append(migrations, columnStatements...), alterMigrations...)
+	version := migrationsVersion(statements)
+	if _, err := DB.Exec(`INSERT IGNORE INTO schema_migrations (version) VALUES (?)`, version); err != nil {
+		return fmt.Errorf("failed to record schema version: %w", err)
+	}
+	schemaVersion = version
+
 	log.Println("Database migrations completed")
 	return nil
 }
 
+// addedColumn is a column added to an existing table by a later migration
+type addedColumn struct {
+	table, column, definition string
+}
+
+func (c addedColumn) statement() string {
+	return "ALTER TABLE " + c.table + " ADD COLUMN " + c.column + " " + c.definition
+}
+
+// addColumnIfMissing adds a column unless information_schema shows the table
+// already has it. MySQL 8 doesn't support ADD COLUMN IF NOT EXISTS.
+func addColumnIfMissing(c addedColumn) error {
+	var count int
+	err := DB.QueryRow(`
+		SELECT COUNT(*) FROM information_schema.COLUMNS
+		WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ? AND COLUMN_NAME = ?
+	`, c.table, c.column).Scan(&count)
+	if err != nil || count > 0 {
+		return err
+	}
+	_, err = DB.Exec(c.statement())
+	return err
+}
+
+// schemaVersion identifies the migrations this process applied
+var schemaVersion string
+
+// migrationsVersion hashes the migration statements, so any change to them
+// is a new schema version
+func migrationsVersion(statements []string) string {
+	h := sha256.New()
+	for _, s := range statements {
+		h.Write([]byte(s))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// SchemaUpToDate reports whether the database has this build's migrations
+// applied. It's false until RunMigrations has run in this process.
+func SchemaUpToDate(ctx context.Context) (bool, error) {
+	if schemaVersion == "" {
+		return false, nil
+	}
+	var count int
+	err := DB.QueryRowContext(ctx, `SELECT COUNT(*) FROM schema_migrations WHERE version = ?`, schemaVersion).Scan(&count)
+	return count > 0, err
+}
+
 func seedAssetTypes() {
 	defaults := []struct {
 		name       string
@@ -0,0 +1,135 @@
+// Package documents builds the full-text search index over the document vault
+package documents
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/finviz/backend/internal/db"
+	"github.com/finviz/backend/internal/storage"
+	"github.com/finviz/backend/internal/taxparser"
+)
+
+const (
+	// Documents indexed per run, so a large backlog doesn't hold the job up
+	indexBatchSize = 20
+
+	// extracted_text is a TEXT column
+	maxIndexedTextBytes = 65535
+)
+
+// IndexPendingDocuments extracts the text of PDFs that haven't been indexed yet
+// and stores it in document_text_index. Only PDFs with a text layer yield
+// text; image-only scans are indexed empty with zero confidence so they
+// aren't retried on every run.
+func IndexPendingDocuments() {
+	rows, err := db.DB.Query(`
+		SELECT d.id, d.storage_path, d.encrypted
+		FROM documents d
+		LEFT JOIN document_text_index t ON t.document_id = d.id
+		WHERE t.document_id IS NULL AND d.deleted_at IS NULL AND d.mime_type = 'application/pdf'
+		ORDER BY d.id
+		LIMIT ?
+	`, indexBatchSize)
+	if err != nil {
+		fmt.Printf("Error finding documents to index: %v\n", err)
+		return
+	}
+
+	type pendingDocument struct {
+		id          int
+		storagePath string
+		encrypted   bool
+	}
+	var pending []pendingDocument
+	for rows.Next() {
+		var doc pendingDocument
+		if err := rows.Scan(&doc.id, &doc.storagePath, &doc.encrypted); err != nil {
+			continue
+		}
+		pending = append(pending, doc)
+	}
+	rows.Close()
+
+	for _, doc := range pending {
+		// A storage error may be transient, so leave the document for the next run
+		content, err := storage.DefaultStorage.Load(doc.storagePath, doc.encrypted)
+		if err != nil {
+			fmt.Printf("Error loading document %d for indexing: %v\n", doc.id, err)
+			continue
+		}
+		if err := IndexDocument(doc.id, content); err != nil {
+			fmt.Printf("Error indexing document %d: %v\n", doc.id, err)
+		}
+	}
+}
+
+// IndexDocument extracts a PDF's text and saves it to the index, replacing any
+// earlier extraction. A PDF that can't be read, including one that makes the
+// PDF library panic, is indexed empty so it isn't retried.
+func IndexDocument(documentID int, pdfBytes []byte) error {
+	text := extractIndexText(documentID, pdfBytes)
+	confidence := extractionConfidence(text)
+
+	_, err := db.DB.Exec(`
+		INSERT INTO document_text_index (document_id, extracted_text, extraction_confidence, extracted_at)
+		VALUES (?, ?, ?, NOW())
+		ON DUPLICATE KEY UPDATE extracted_text = VALUES(extracted_text),
+			extraction_confidence = VALUES(extraction_confidence), extracted_at = VALUES(extracted_at)
+	`, documentID, text, confidence)
+	return err
+}
+
+// extractIndexText returns a PDF's normalized text, or "" if it can't be
+// parsed. The PDF library panics on some malformed files.
+func extractIndexText(documentID int, pdfBytes []byte) (text string) {
+	defer func() {
+		if r := recover(); r != nil {
+			fmt.Printf("Recovered from panic extracting text from document %d: %v\n", documentID, r)
+			text = ""
+		}
+	}()
+
+	parsed, err := taxparser.ParsePDFContent(pdfBytes)
+	if err != nil {
+		return ""
+	}
+	return truncateUTF8(normalizeText(parsed.RawText), maxIndexedTextBytes)
+}
+
+// normalizeText drops the page-break markers and collapses runs of whitespace
+func normalizeText(raw string) string {
+	raw = strings.ReplaceAll(raw, "---PAGE BREAK---", " ")
+	return strings.Join(strings.Fields(raw), " ")
+}
+
+// extractionConfidence scores extracted text from 0 to 1 by the share of
+// readable characters. Garbled extraction from unusual font encodings comes
+// out as symbols and control characters and scores low.
+func extractionConfidence(text string) float64 {
+	total, readable := 0, 0
+	for _, r := range text {
+		total++
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsSpace(r) || strings.ContainsRune(".,;:$%()-/'&#", r) {
+			readable++
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	return float64(readable) / float64(total)
+}
+
+// truncateUTF8 cuts s to at most maxBytes without splitting a character
+func truncateUTF8(s string, maxBytes int) string {
+	if len(s) <= maxBytes {
+		return s
+	}
+	cut := maxBytes
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut]
+}
@@ -0,0 +1,110 @@
+package email
+
+import (
+	"fmt"
+	"log"
+	"mime"
+	"net/smtp"
+	"os"
+	"strings"
+)
+
+// Sender delivers transactional emails
+type Sender interface {
+	// SendInvitationReminder re-sends a pending client invitation
+	SendInvitationReminder(to, advisorName, token string) error
+}
+
+// NewSender returns an SMTP sender when SMTP_HOST is set, otherwise a sender
+// that only logs messages (for development)
+func NewSender() Sender {
+	host := os.Getenv("SMTP_HOST")
+	if host == "" {
+		return &LogSender{}
+	}
+
+	port := os.Getenv("SMTP_PORT")
+	if port == "" {
+		port = "587"
+	}
+	from := os.Getenv("SMTP_FROM")
+	if from == "" {
+		from = "no-reply@finviz.local"
+	}
+
+	return &SMTPSender{
+		host:     host,
+		port:     port,
+		username: os.Getenv("SMTP_USERNAME"),
+		password: os.Getenv("SMTP_PASSWORD"),
+		from:     from,
+	}
+}
+
+// invitationURL builds the link a client follows to accept an invitation
+func invitationURL(token string) string {
+	base := os.Getenv("APP_BASE_URL")
+	if base == "" {
+		base = "http://localhost:5173"
+	}
+	return strings.TrimRight(base, "/") + "/invitation/" + token
+}
+
+// invitationReminderBody renders the reminder subject and plain-text body
+func invitationReminderBody(advisorName, token string) (string, string) {
+	subject := fmt.Sprintf("Reminder: %s invited you to FinViz", advisorName)
+	body := fmt.Sprintf("Hi,\n\n%s invited you to connect on FinViz so you can plan your finances together. "+
+		"Your invitation is still waiting - accept it here:\n\n%s\n\n"+
+		"If you weren't expecting this, you can ignore this email.\n",
+		advisorName, invitationURL(token))
+	return subject, body
+}
+
+// SMTPSender sends email through an SMTP relay
+type SMTPSender struct {
+	host     string
+	port     string
+	username string
+	password string
+	from     string
+}
+
+// SendInvitationReminder emails an invitation reminder
+func (s *SMTPSender) SendInvitationReminder(to, advisorName, token string) error {
+	subject, body := invitationReminderBody(advisorName, token)
+	return s.send(to, subject, body)
+}
+
+// send delivers a plain-text message. The subject is MIME-encoded when it
+// has non-ASCII or control characters, so a CR/LF in a user-supplied name
+// can't inject headers.
+func (s *SMTPSender) send(to, subject, body string) error {
+	if strings.ContainsAny(to, "\r\n") {
+		return fmt.Errorf("invalid recipient address")
+	}
+
+	var auth smtp.Auth
+	if s.username != "" {
+		auth = smtp.PlainAuth("", s.username, s.password, s.host)
+	}
+
+	msg := "From: " + s.from + "\r\n" +
+		"To: " + to + "\r\n" +
+		"Subject: " + mime.QEncoding.Encode("UTF-8", subject) + "\r\n" +
+		"MIME-Version: 1.0\r\n" +
+		"Content-Type: text/plain; charset=UTF-8\r\n" +
+		"\r\n" + body
+
+	return smtp.SendMail(s.host+":"+s.port, auth, s.from, []string{to}, []byte(msg))
+}
+
+// LogSender logs emails instead of sending them
+type LogSender struct{}
+
+// SendInvitationReminder logs the reminder that would have been sent. The
+// invitation token is left out of the logged link, since it's a credential.
+func (s *LogSender) SendInvitationReminder(to, advisorName, token string) error {
+	subject, _ := invitationReminderBody(advisorName, token)
+	log.Printf("Email (not sent, SMTP_HOST unset) to %s: %q - %s", to, subject, invitationURL("[redacted]"))
+	return nil
+}
@@ -0,0 +1,83 @@
+// Package logger writes structured JSON logs to stdout. A logger taken from a
+// request's context tags every line with the request ID and, once the request
+// is authenticated, the user ID, so one request's lines can be found together.
+package logger
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync/atomic"
+)
+
+var base = slog.New(slog.NewJSONHandler(os.Stdout, nil))
+
+// Logger is a slog.Logger with printf-style helpers
+type Logger struct {
+	*slog.Logger
+}
+
+// Infof logs a formatted message at info level
+func (l *Logger) Infof(format string, args ...interface{}) {
+	l.Info(fmt.Sprintf(format, args...))
+}
+
+// Warnf logs a formatted message at warn level
+func (l *Logger) Warnf(format string, args ...interface{}) {
+	l.Warn(fmt.Sprintf(format, args...))
+}
+
+// Errorf logs a formatted message at error level
+func (l *Logger) Errorf(format string, args ...interface{}) {
+	l.Error(fmt.Sprintf(format, args...))
+}
+
+type contextKey struct{}
+
+// requestInfo identifies the request a context belongs to. The context holds
+// a pointer so authentication, which runs after the ID is assigned, can add
+// the user.
+type requestInfo struct {
+	id     string
+	userID atomic.Int64
+}
+
+// WithRequestID returns a context whose loggers are tagged with id
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, &requestInfo{id: id})
+}
+
+// SetUserID tags the request's remaining log lines with the authenticated
+// user. Does nothing for contexts without a request ID.
+func SetUserID(ctx context.Context, userID int) {
+	if info, ok := ctx.Value(contextKey{}).(*requestInfo); ok {
+		info.userID.Store(int64(userID))
+	}
+}
+
+// RequestID returns the request ID stored in ctx, or ""
+func RequestID(ctx context.Context) string {
+	if info, ok := ctx.Value(contextKey{}).(*requestInfo); ok {
+		return info.id
+	}
+	return ""
+}
+
+// FromContext returns a logger tagged with ctx's request ID and user ID
+func FromContext(ctx context.Context) *Logger {
+	info, ok := ctx.Value(contextKey{}).(*requestInfo)
+	if !ok {
+		return Default()
+	}
+	l := base.With("request_id", info.id)
+	if userID := info.userID.Load(); userID != 0 {
+		l = l.With("user_id", userID)
+	}
+	return &Logger{l}
+}
+
+// Default returns the logger for work outside a request, such as background jobs
+func Default() *Logger {
+	return &Logger{base}
+}
@@ -0,0 +1,210 @@
+package middleware
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/finviz/backend/internal/db"
+	"github.com/finviz/backend/internal/logger"
+	"github.com/finviz/backend/internal/models"
+)
+
+// IdempotencyKeyHeader lets clients retry a create safely: repeats of a
+// request with the same key get the first response instead of a duplicate
+const IdempotencyKeyHeader = "X-Idempotency-Key"
+
+// Stored responses are replayed for this long after the first request
+const idempotencyTTL = 24 * time.Hour
+
+// Keys longer than this are rejected
+const maxIdempotencyKeyLength = 255
+
+// Idempotency replays stored responses for requests that repeat an
+// X-Idempotency-Key. Keys are scoped to the authenticated user.
+type Idempotency struct {
+	userFrom func(r *http.Request) *models.User
+}
+
+// NewIdempotency returns the idempotency key handler. userFrom returns the
+// authenticated user of a request.
+func NewIdempotency(userFrom func(r *http.Request) *models.User) *Idempotency {
+	return &Idempotency{userFrom: userFrom}
+}
+
+// Wrap runs a handler at most once per idempotency key. The first request
+// claims the key and its response is stored; repeats within the TTL get the
+// stored response, or 409 while the first is still running. Server errors
+// aren't stored so the client can retry them. Requests without a key or a
+// user pass straight through, as do requests when the key table can't be read.
+//
+// secretFields names top-level fields of the JSON response (passwords,
+// tokens) that are left out of the stored copy: only the first response
+// carries them, and replays omit them.
+func (i *Idempotency) Wrap(next http.HandlerFunc, secretFields ...string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(IdempotencyKeyHeader)
+		user := i.userFrom(r)
+		if key == "" || user == nil {
+			next(w, r)
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			writeJSONError(w, http.StatusBadRequest, "X-Idempotency-Key must be at most 255 characters")
+			return
+		}
+
+		log := logger.FromContext(r.Context())
+		path := r.Method + " " + r.URL.Path
+		stored, err := claimIdempotencyKey(key, user.ID, path)
+		if err != nil {
+			log.Errorf("Error claiming idempotency key for user %d: %v", user.ID, err)
+			next(w, r)
+			return
+		}
+		if stored != nil {
+			switch {
+			case stored.path != path:
+				writeJSONError(w, http.StatusUnprocessableEntity, "X-Idempotency-Key was already used for a different request")
+			case stored.statusCode == 0:
+				writeJSONError(w, http.StatusConflict, "A request with this X-Idempotency-Key is still in progress")
+			default:
+				w.Header().Set("Content-Type", "application/json")
+				w.Header().Set("Idempotent-Replayed", "true")
+				w.WriteHeader(stored.statusCode)
+				w.Write(stored.body)
+			}
+			return
+		}
+
+		rec := &responseCapture{ResponseWriter: w, status: http.StatusOK}
+		next(rec, r)
+
+		if rec.status >= http.StatusInternalServerError {
+			_, err = db.DB.Exec(`DELETE FROM idempotency_keys WHERE idempotency_key = ? AND user_id = ?`, key, user.ID)
+		} else {
+			_, err = db.DB.Exec(`
+				UPDATE idempotency_keys SET status_code = ?, response_body = ?
+				WHERE idempotency_key = ? AND user_id = ?
+			`, rec.status, redactJSONFields(rec.body.Bytes(), secretFields), key, user.ID)
+		}
+		if err != nil {
+			log.Errorf("Error storing idempotent response for user %d: %v", user.ID, err)
+		}
+	}
+}
+
+// storedResponse is the recorded outcome of an earlier request with a key.
+// A zero statusCode means that request hasn't finished.
+type storedResponse struct {
+	path       string
+	statusCode int
+	body       []byte
+}
+
+// claimIdempotencyKey reserves a key for a new request, returning nil if it
+// was free (or had expired) and the earlier request's response otherwise
+func claimIdempotencyKey(key string, userID int, path string) (*storedResponse, error) {
+	tx, err := db.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// An expired key is free to reuse
+	if _, err := tx.Exec(`
+		DELETE FROM idempotency_keys WHERE idempotency_key = ? AND user_id = ? AND expires_at <= NOW()
+	`, key, userID); err != nil {
+		return nil, err
+	}
+
+	result, err := tx.Exec(`
+		INSERT IGNORE INTO idempotency_keys (idempotency_key, user_id, request_path, expires_at)
+		VALUES (?, ?, ?, DATE_ADD(NOW(), INTERVAL ? SECOND))
+	`, key, userID, path, int(idempotencyTTL.Seconds()))
+	if err != nil {
+		return nil, err
+	}
+	if inserted, _ := result.RowsAffected(); inserted > 0 {
+		return nil, tx.Commit()
+	}
+
+	var stored storedResponse
+	var statusCode sql.NullInt64
+	err = tx.QueryRow(`
+		SELECT request_path, status_code, response_body FROM idempotency_keys
+		WHERE idempotency_key = ? AND user_id = ?
+	`, key, userID).Scan(&stored.path, &statusCode, &stored.body)
+	if err != nil {
+		return nil, err
+	}
+	stored.statusCode = int(statusCode.Int64)
+	return &stored, tx.Commit()
+}
+
+// redactJSONFields removes the named top-level fields from a JSON object.
+// Anything that isn't a JSON object is returned unchanged.
+func redactJSONFields(body []byte, fields []string) []byte {
+	if len(fields) == 0 {
+		return body
+	}
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(body, &obj); err != nil {
+		return body
+	}
+	for _, f := range fields {
+		delete(obj, f)
+	}
+	redacted, err := json.Marshal(obj)
+	if err != nil {
+		return body
+	}
+	return redacted
+}
+
+// PruneIdempotencyKeys deletes keys past their expiry
+func PruneIdempotencyKeys() {
+	result, err := db.DB.Exec(`DELETE FROM idempotency_keys WHERE expires_at <= NOW()`)
+	if err != nil {
+		logger.Default().Errorf("Error pruning idempotency keys: %v", err)
+		return
+	}
+	if pruned, _ := result.RowsAffected(); pruned > 0 {
+		logger.Default().Infof("Pruned %d expired idempotency keys", pruned)
+	}
+}
+
+// responseCapture records the status and body written by a handler while
+// passing them through to the client
+type responseCapture struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (c *responseCapture) WriteHeader(status int) {
+	if !c.wroteHeader {
+		c.status = status
+		c.wroteHeader = true
+	}
+	c.ResponseWriter.WriteHeader(status)
+}
+
+func (c *responseCapture) Write(b []byte) (int, error) {
+	c.wroteHeader = true
+	c.body.Write(b)
+	return c.ResponseWriter.Write(b)
+}
+
+func (c *responseCapture) Unwrap() http.ResponseWriter {
+	return c.ResponseWriter
+}
+
+func writeJSONError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": message})
+}
@@ -0,0 +1,215 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/finviz/backend/internal/db"
+	"github.com/finviz/backend/internal/logger"
+)
+
+// Request duration histogram buckets in seconds, the Prometheus client defaults
+var durationBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Path label for requests that didn't match a route, so unknown URLs can't
+// create unbounded label values
+const unmatchedRoute = "unmatched"
+
+// Method label for anything outside the standard methods the API serves,
+// for the same reason
+const otherMethod = "OTHER"
+
+type requestLabels struct {
+	method, path, statusCode string
+}
+
+type requestStats struct {
+	count   uint64
+	sum     float64
+	buckets []uint64 // cumulative counts per durationBuckets entry
+}
+
+var (
+	requestMetricsMu sync.Mutex
+	requestMetrics   = map[requestLabels]*requestStats{}
+	simulationsRun   atomic.Uint64
+)
+
+type routeKey struct{}
+
+// SetRoute records the route pattern that matched a request, such as
+// "/api/assets/{id}", for the path label. Routers call it before the handler.
+func SetRoute(ctx context.Context, pattern string) {
+	if route, ok := ctx.Value(routeKey{}).(*string); ok {
+		*route = pattern
+	}
+}
+
+// Metrics records the duration and status of each request for /metrics,
+// labelled by method, route pattern and status code. It writes the
+// metrics in the Prometheus text format by hand rather than pulling in the
+// Prometheus client library.
+func Metrics(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		route := unmatchedRoute
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r.WithContext(context.WithValue(r.Context(), routeKey{}, &route)))
+		observeRequest(requestLabels{methodLabel(r.Method), route, strconv.Itoa(rec.status)}, time.Since(start).Seconds())
+	})
+}
+
+// methodLabel returns the method label for a request method
+func methodLabel(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodOptions, http.MethodHead:
+		return method
+	}
+	return otherMethod
+}
+
+func observeRequest(labels requestLabels, seconds float64) {
+	requestMetricsMu.Lock()
+	defer requestMetricsMu.Unlock()
+
+	stats, ok := requestMetrics[labels]
+	if !ok {
+		stats = &requestStats{buckets: make([]uint64, len(durationBuckets))}
+		requestMetrics[labels] = stats
+	}
+	stats.count++
+	stats.sum += seconds
+	for i, upper := range durationBuckets {
+		if seconds <= upper {
+			stats.buckets[i]++
+		}
+	}
+}
+
+// RecordSimulationRun counts a simulation saved to history
+func RecordSimulationRun() {
+	simulationsRun.Add(1)
+}
+
+// HandleMetrics serves metrics in the Prometheus text format. When
+// METRICS_ALLOWED_IPS is set (comma-separated IPs or CIDRs) only those
+// addresses may scrape; the connection's address is used, not X-Forwarded-For.
+// GET /metrics
+func HandleMetrics(w http.ResponseWriter, r *http.Request) {
+	if !metricsClientAllowed(r.RemoteAddr, os.Getenv("METRICS_ALLOWED_IPS")) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	writeRequestMetrics(w)
+
+	writeMetric(w, "db_queries_total", "counter", "Statements sent to the database.", float64(db.QueriesTotal()))
+	writeMetric(w, "simulations_run_total", "counter", "Simulations saved to history since the server started.", float64(simulationsRun.Load()))
+
+	gauges := []struct {
+		name, help, query string
+	}{
+		{"active_users_total", "Users with an unexpired, unrevoked session.",
+			`SELECT COUNT(DISTINCT user_id) FROM refresh_tokens WHERE revoked_at IS NULL AND expires_at > NOW()`},
+		{"total_simulations_run", "Simulations stored in history.", `SELECT COUNT(*) FROM simulation_history`},
+		{"total_documents_stored", "Documents stored.", `SELECT COUNT(*) FROM documents`},
+	}
+	for _, g := range gauges {
+		var value float64
+		if err := db.DB.QueryRowContext(r.Context(), g.query).Scan(&value); err != nil {
+			logger.FromContext(r.Context()).Errorf("Error reading metric %s: %v", g.name, err)
+			continue
+		}
+		writeMetric(w, g.name, "gauge", g.help, value)
+	}
+}
+
+func writeRequestMetrics(w io.Writer) {
+	requestMetricsMu.Lock()
+	defer requestMetricsMu.Unlock()
+
+	keys := make([]requestLabels, 0, len(requestMetrics))
+	for k := range requestMetrics {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		if a.path != b.path {
+			return a.path < b.path
+		}
+		if a.method != b.method {
+			return a.method < b.method
+		}
+		return a.statusCode < b.statusCode
+	})
+
+	fmt.Fprintln(w, "# HELP http_requests_total HTTP requests handled.")
+	fmt.Fprintln(w, "# TYPE http_requests_total counter")
+	for _, k := range keys {
+		fmt.Fprintf(w, "http_requests_total{%s} %d\n", k.labelString(), requestMetrics[k].count)
+	}
+
+	fmt.Fprintln(w, "# HELP http_request_duration_seconds HTTP request latency.")
+	fmt.Fprintln(w, "# TYPE http_request_duration_seconds histogram")
+	for _, k := range keys {
+		stats := requestMetrics[k]
+		labels := k.labelString()
+		for i, upper := range durationBuckets {
+			fmt.Fprintf(w, "http_request_duration_seconds_bucket{%s,le=\"%s\"} %d\n", labels, formatFloat(upper), stats.buckets[i])
+		}
+		fmt.Fprintf(w, "http_request_duration_seconds_bucket{%s,le=\"+Inf\"} %d\n", labels, stats.count)
+		fmt.Fprintf(w, "http_request_duration_seconds_sum{%s} %s\n", labels, formatFloat(stats.sum))
+		fmt.Fprintf(w, "http_request_duration_seconds_count{%s} %d\n", labels, stats.count)
+	}
+}
+
+func (k requestLabels) labelString() string {
+	return fmt.Sprintf(`method=%q,path=%q,status_code=%q`, k.method, k.path, k.statusCode)
+}
+
+func writeMetric(w io.Writer, name, kind, help string, value float64) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n%s %s\n", name, help, name, kind, name, formatFloat(value))
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// metricsClientAllowed reports whether remoteAddr is in the allowlist. An
+// empty allowlist allows everyone.
+func metricsClientAllowed(remoteAddr, allowlist string) bool {
+	if strings.TrimSpace(allowlist) == "" {
+		return true
+	}
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+	for _, entry := range strings.Split(allowlist, ",") {
+		entry = strings.TrimSpace(entry)
+		if _, network, err := net.ParseCIDR(entry); err == nil {
+			if network.Contains(ip) {
+				return true
+			}
+		} else if allowed := net.ParseIP(entry); allowed != nil && allowed.Equal(ip) {
+			return true
+		}
+	}
+	return false
+}
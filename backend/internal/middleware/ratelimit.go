@@ -0,0 +1,142 @@
+package middleware
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/finviz/backend/internal/db"
+	"github.com/finviz/backend/internal/models"
+)
+
+// Default hourly request limits by role
+const (
+	defaultAdvisorLimit = 100
+	defaultClientLimit  = 20
+)
+
+// RateLimiter limits how often each user can call the handlers it wraps. Each
+// user has a token bucket holding an hour's allowance that refills
+// continuously, so bursts are allowed but the sustained rate is the hourly limit.
+type RateLimiter struct {
+	advisorLimit int
+	clientLimit  int
+	userFrom     func(r *http.Request) *models.User
+}
+
+// NewChatRateLimiter returns the limiter for Aurelia chat. Limits come from
+// CHAT_RATE_LIMIT_ADVISOR and CHAT_RATE_LIMIT_CLIENT (requests per hour).
+// userFrom returns the authenticated user of a request.
+func NewChatRateLimiter(userFrom func(r *http.Request) *models.User) *RateLimiter {
+	return &RateLimiter{
+		advisorLimit: envLimit("CHAT_RATE_LIMIT_ADVISOR", defaultAdvisorLimit),
+		clientLimit:  envLimit("CHAT_RATE_LIMIT_CLIENT", defaultClientLimit),
+		userFrom:     userFrom,
+	}
+}
+
+// envLimit reads a positive integer limit from the environment
+func envLimit(key string, defaultValue int) int {
+	if n, err := strconv.Atoi(os.Getenv(key)); err == nil && n > 0 {
+		return n
+	}
+	return defaultValue
+}
+
+// Limit wraps a handler, responding 429 with retry_after_seconds once the
+// user's bucket is empty. Requests without a user pass through for the
+// handler to reject. If the bucket can't be read the request is allowed.
+func (l *RateLimiter) Limit(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := l.userFrom(r)
+		if user == nil {
+			next(w, r)
+			return
+		}
+
+		limit := l.clientLimit
+		if user.IsAdvisor() {
+			limit = l.advisorLimit
+		}
+
+		retryAfter, err := take(user.ID, limit, time.Now().UTC())
+		if err != nil {
+			fmt.Printf("Error checking rate limit for user %d: %v\n", user.ID, err)
+			next(w, r)
+			return
+		}
+		if retryAfter > 0 {
+			seconds := int(math.Ceil(retryAfter.Seconds()))
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Retry-After", strconv.Itoa(seconds))
+			w.WriteHeader(http.StatusTooManyRequests)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"error":               fmt.Sprintf("Rate limit exceeded: %d requests per hour", limit),
+				"retry_after_seconds": seconds,
+			})
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+// take spends one token from the user's bucket. It returns 0 if the request
+// is allowed, otherwise how long until a token is available.
+func take(userID, limit int, now time.Time) (time.Duration, error) {
+	tx, err := db.DB.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	// Make sure the row exists so FOR UPDATE has something to lock
+	if _, err := tx.Exec(`
+		INSERT IGNORE INTO rate_limits (user_id, window_start, request_count) VALUES (?, ?, 0)
+	`, userID, now); err != nil {
+		return 0, err
+	}
+
+	var windowStart time.Time
+	var spent float64
+	err = tx.QueryRow(`
+		SELECT window_start, request_count FROM rate_limits WHERE user_id = ? FOR UPDATE
+	`, userID).Scan(&windowStart, &spent)
+	if err == sql.ErrNoRows {
+		return 0, fmt.Errorf("rate limit row missing")
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	spent, retryAfter := drain(spent, limit, now.Sub(windowStart))
+	if retryAfter > 0 {
+		return retryAfter, nil
+	}
+
+	if _, err := tx.Exec(`
+		UPDATE rate_limits SET window_start = ?, request_count = ? WHERE user_id = ?
+	`, now, spent+1, userID); err != nil {
+		return 0, err
+	}
+	return 0, tx.Commit()
+}
+
+// drain refills the bucket for the elapsed time, returning the tokens still
+// spent and, if none are left, the wait until the next one
+func drain(spent float64, limit int, elapsed time.Duration) (float64, time.Duration) {
+	perSecond := float64(limit) / time.Hour.Seconds()
+	if elapsed > 0 {
+		spent = math.Max(0, spent-elapsed.Seconds()*perSecond)
+	}
+	if spent+1 <= float64(limit) {
+		return spent, 0
+	}
+	wait := (spent + 1 - float64(limit)) / perSecond
+	return spent, time.Duration(wait * float64(time.Second))
+}
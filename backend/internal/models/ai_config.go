@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// AdvisorAIConfig customizes the Aurelia assistant for an advisor's clients
+type AdvisorAIConfig struct {
+	ID                       int       `json:"id" db:"id"`
+	AdvisorID                int       `json:"advisorId" db:"advisor_id"`
+	FirmNameForAI            *string   `json:"firmNameForAi,omitempty" db:"firm_name_for_ai"`
+	CustomSystemPromptSuffix *string   `json:"customSystemPromptSuffix,omitempty" db:"custom_system_prompt_suffix"`
+	EnabledTools             []string  `json:"enabledTools,omitempty" db:"enabled_tools"` // nil means all tools enabled
+	DisclaimerText           *string   `json:"disclaimerText,omitempty" db:"disclaimer_text"`
+	CreatedAt                time.Time `json:"createdAt" db:"created_at"`
+	UpdatedAt                time.Time `json:"updatedAt" db:"updated_at"`
+}
+
+// UpdateAdvisorAIConfigRequest is the request body for saving an advisor's AI config
+type UpdateAdvisorAIConfigRequest struct {
+	FirmNameForAI            *string  `json:"firmNameForAi,omitempty"`
+	CustomSystemPromptSuffix *string  `json:"customSystemPromptSuffix,omitempty"`
+	EnabledTools             []string `json:"enabledTools,omitempty"`
+	DisclaimerText           *string  `json:"disclaimerText,omitempty"`
+}
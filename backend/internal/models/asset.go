@@ -19,8 +19,13 @@ type Asset struct {
 	CustomReturn     *float64   `json:"customReturn,omitempty" db:"custom_return"`
 	CustomVolatility *float64   `json:"customVolatility,omitempty" db:"custom_volatility"`
 	PlaidAccountID   *string    `json:"plaidAccountId,omitempty" db:"plaid_account_id"`
+	TickerSymbol     *string    `json:"tickerSymbol,omitempty" db:"ticker_symbol"` // crypto assets are priced by ticker
+	Quantity         float64    `json:"quantity" db:"quantity"`
+	PriceUSD         *float64   `json:"priceUsd,omitempty" db:"-"` // latest polled price, for priced assets
+	PriceFetchedAt   *time.Time `json:"priceFetchedAt,omitempty" db:"-"`
 	CreatedAt        time.Time  `json:"createdAt" db:"created_at"`
 	UpdatedAt        time.Time  `json:"updatedAt" db:"updated_at"`
+	DeletedAt        *time.Time `json:"deletedAt,omitempty" db:"deleted_at"`
 	AssetType        *AssetType `json:"assetType,omitempty" db:"-"`
 }
 
@@ -30,6 +35,8 @@ type CreateAssetRequest struct {
 	CurrentValue     float64  `json:"currentValue"`
 	CustomReturn     *float64 `json:"customReturn,omitempty"`
 	CustomVolatility *float64 `json:"customVolatility,omitempty"`
+	TickerSymbol     *string  `json:"tickerSymbol,omitempty"`
+	Quantity         *float64 `json:"quantity,omitempty"` // defaults to 1
 }
 
 type UpdateAssetRequest struct {
@@ -38,6 +45,8 @@ type UpdateAssetRequest struct {
 	CurrentValue     *float64 `json:"currentValue,omitempty"`
 	CustomReturn     *float64 `json:"customReturn,omitempty"`
 	CustomVolatility *float64 `json:"customVolatility,omitempty"`
+	TickerSymbol     *string  `json:"tickerSymbol,omitempty"` // "" clears it
+	Quantity         *float64 `json:"quantity,omitempty"`
 }
 
 // GetReturn returns the effective return rate for this asset
@@ -61,3 +70,43 @@ func (a *Asset) GetVolatility() float64 {
 	}
 	return 0
 }
+
+// BatchAssetUpdate sets one asset's value, and optionally its name, in a
+// batch update
+type BatchAssetUpdate struct {
+	ID           int      `json:"id"`
+	CurrentValue *float64 `json:"currentValue"`
+	Name         *string  `json:"name,omitempty"`
+}
+
+// FailedUpdate explains why one item in a batch update was skipped
+type FailedUpdate struct {
+	ID    int    `json:"id"`
+	Error string `json:"error"`
+}
+
+// BatchUpdateResponse summarizes a batch asset or debt update
+type BatchUpdateResponse struct {
+	Updated int            `json:"updated"`
+	Failed  []FailedUpdate `json:"failed"`
+}
+
+// Rebalancing actions
+const (
+	RebalanceBuy  = "buy"
+	RebalanceSell = "sell"
+	RebalanceHold = "hold"
+)
+
+// RebalancingAction compares one asset class's share of the portfolio with
+// its target. Percentages are of total asset value; Drift is CurrentPct
+// minus TargetPct, in percentage points.
+type RebalancingAction struct {
+	AssetType       string  `json:"assetType"` // allocation class, e.g. stocks
+	CurrentValue    float64 `json:"currentValue"`
+	CurrentPct      float64 `json:"currentPct"`
+	TargetPct       float64 `json:"targetPct"`
+	Drift           float64 `json:"drift"`
+	Action          string  `json:"action"`          // buy, sell or hold
+	SuggestedAmount float64 `json:"suggestedAmount"` // to buy or sell to reach the target; 0 to hold
+}
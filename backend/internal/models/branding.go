@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// AdvisorBranding white-labels the PDF reports an advisor generates
+type AdvisorBranding struct {
+	ID                int       `json:"id" db:"id"`
+	AdvisorID         int       `json:"advisorId" db:"advisor_id"`
+	FirmName          *string   `json:"firmName,omitempty" db:"firm_name"`
+	Logo              []byte    `json:"logo,omitempty" db:"logo"` // PNG or JPEG, base64 in JSON
+	LogoMimeType      *string   `json:"logoMimeType,omitempty" db:"logo_mime_type"`
+	PrimaryColorHex   *string   `json:"primaryColorHex,omitempty" db:"primary_color_hex"`     // e.g. "#005293"
+	SecondaryColorHex *string   `json:"secondaryColorHex,omitempty" db:"secondary_color_hex"` // e.g. "#6c757d"
+	CreatedAt         time.Time `json:"createdAt" db:"created_at"`
+	UpdatedAt         time.Time `json:"updatedAt" db:"updated_at"`
+}
+
+// UpdateAdvisorBrandingRequest is the request body for saving an advisor's branding
+type UpdateAdvisorBrandingRequest struct {
+	FirmName          *string `json:"firmName,omitempty"`
+	Logo              []byte  `json:"logo,omitempty"` // base64-encoded PNG or JPEG; omit to remove
+	PrimaryColorHex   *string `json:"primaryColorHex,omitempty"`
+	SecondaryColorHex *string `json:"secondaryColorHex,omitempty"`
+}
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// ChatConversation is a saved Aurelia chat
+type ChatConversation struct {
+	ID        int       `json:"id" db:"id"`
+	UserID    int       `json:"userId" db:"user_id"`
+	Title     string    `json:"title" db:"title"`
+	CreatedAt time.Time `json:"createdAt" db:"created_at"`
+	UpdatedAt time.Time `json:"updatedAt" db:"updated_at"`
+
+	Messages []ChatMessageRecord `json:"messages,omitempty" db:"-"`
+}
+
+// ChatMessageRecord is one stored chat message. Only the text of each turn is
+// kept; ToolCalls records the tools the assistant used to produce it.
+type ChatMessageRecord struct {
+	ID             int             `json:"id" db:"id"`
+	ConversationID int             `json:"conversationId" db:"conversation_id"`
+	Role           string          `json:"role" db:"role"` // user, assistant
+	ContentText    string          `json:"content" db:"content_text"`
+	ToolCalls      json.RawMessage `json:"toolCalls,omitempty" db:"tool_calls_json"`
+	CreatedAt      time.Time       `json:"createdAt" db:"created_at"`
+}
@@ -2,6 +2,13 @@ package models
 
 import "time"
 
+// Debt subtypes, from the kind of Plaid liability a debt was synced from
+const (
+	DebtSubtypeCreditCard  = "credit_card"
+	DebtSubtypeMortgage    = "mortgage"
+	DebtSubtypeStudentLoan = "student_loan"
+)
+
 type Debt struct {
 	ID             int       `json:"id" db:"id"`
 	UserID         int       `json:"userId" db:"user_id"`
@@ -10,6 +17,7 @@ type Debt struct {
 	InterestRate   *float64  `json:"interestRate" db:"interest_rate"`
 	MinimumPayment *float64  `json:"minimumPayment" db:"minimum_payment"`
 	PlaidAccountID *string   `json:"plaidAccountId,omitempty" db:"plaid_account_id"`
+	DebtSubtype    *string   `json:"debtSubtype,omitempty" db:"debt_subtype"` // set for Plaid liabilities
 	CreatedAt      time.Time `json:"createdAt" db:"created_at"`
 	UpdatedAt      time.Time `json:"updatedAt" db:"updated_at"`
 }
@@ -27,3 +35,53 @@ type UpdateDebtRequest struct {
 	InterestRate   *float64 `json:"interestRate,omitempty"`
 	MinimumPayment *float64 `json:"minimumPayment,omitempty"`
 }
+
+// BatchDebtUpdate sets one debt's balance, and optionally its name, in a
+// batch update
+type BatchDebtUpdate struct {
+	ID             int      `json:"id"`
+	CurrentBalance *float64 `json:"currentBalance"`
+	Name           *string  `json:"name,omitempty"`
+}
+
+// Debt payoff strategies
+const (
+	PayoffStrategyAvalanche = "avalanche" // highest interest rate first
+	PayoffStrategySnowball  = "snowball"  // smallest balance first
+	PayoffStrategyHybrid    = "hybrid"    // quick wins first, then highest rate
+)
+
+// DebtPayoffRequest asks for payoff plans that put ExtraMonthlyPayment on top
+// of every debt's minimum payment
+type DebtPayoffRequest struct {
+	ExtraMonthlyPayment float64 `json:"extraMonthlyPayment"`
+	Strategy            string  `json:"strategy,omitempty"` // one strategy, or all three when empty
+}
+
+// DebtPayoffPlan is the result of paying debts down with one strategy
+type DebtPayoffPlan struct {
+	Strategy          string       `json:"strategy"`
+	PayoffOrder       []DebtPayoff `json:"payoffOrder"`  // in the order debts are paid off
+	DebtSchedule      []PayoffYear `json:"debtSchedule"` // year-end totals
+	TotalInterestPaid float64      `json:"totalInterestPaid"`
+	TotalMonths       int          `json:"totalMonths"`
+	PaidOff           bool         `json:"paidOff"` // false if payments can't outpace interest within the horizon
+}
+
+// DebtPayoff is when one debt reaches zero under a plan
+type DebtPayoff struct {
+	DebtID       int     `json:"debtId"`
+	Name         string  `json:"name"`
+	PayoffMonth  int     `json:"payoffMonth"` // 1-indexed; 0 if never paid off
+	InterestPaid float64 `json:"interestPaid"`
+}
+
+// PayoffYear summarizes one year of a payoff schedule
+type PayoffYear struct {
+	Year             int             `json:"year"` // 1-indexed
+	StartingBalance  float64         `json:"startingBalance"`
+	InterestPaid     float64         `json:"interestPaid"`
+	PrincipalPaid    float64         `json:"principalPaid"`
+	EndingBalance    float64         `json:"endingBalance"`
+	BalancesByDebtID map[int]float64 `json:"balancesByDebtId"` // year-end balance of each debt
+}
@@ -19,17 +19,38 @@ type Document struct {
 	CreatedAt    time.Time  `json:"created_at"`
 	UpdatedAt    time.Time  `json:"updated_at"`
 	DeletedAt    *time.Time `json:"deleted_at,omitempty"` // Soft delete
+
+	DocumentIDOriginal *int `json:"document_id_original,omitempty"` // the version this upload replaced
 }
 
-// DocumentShare represents sharing permissions for a document
+// DocumentShare represents sharing permissions for a document.
+// Shares are never hard-deleted; RevokedAt is set when a share expires or
+// is replaced, so past access stays auditable.
 type DocumentShare struct {
-	ID           int       `json:"id"`
-	DocumentID   int       `json:"document_id"`
-	SharedWithID int       `json:"shared_with_id"` // User ID of recipient
-	SharedByID   int       `json:"shared_by_id"`   // User ID who shared
-	Permission   string    `json:"permission"`     // view, download
-	CreatedAt    time.Time `json:"created_at"`
-	ExpiresAt    *time.Time `json:"expires_at,omitempty"` // Optional expiration
+	ID             int        `json:"id"`
+	DocumentID     int        `json:"document_id"`
+	SharedWithID   int        `json:"shared_with_id"` // User ID of recipient
+	SharedByID     int        `json:"shared_by_id"`   // User ID who shared
+	Permission     string     `json:"permission"`     // view, download
+	CreatedAt      time.Time  `json:"created_at"`
+	ExpiresAt      *time.Time `json:"expires_at,omitempty"`     // Optional expiration
+	RevokedAt      *time.Time `json:"revoked_at,omitempty"`     // When the share stopped granting access
+	RevokedReason  *string    `json:"revoked_reason,omitempty"` // expired, replaced, deleted
+	SharedWithName string     `json:"shared_with_name,omitempty"`
+	SharedByName   string     `json:"shared_by_name,omitempty"`
+}
+
+// Share revocation reasons
+const (
+	ShareRevokedExpired  = "expired"
+	ShareRevokedReplaced = "replaced"
+	ShareRevokedDeleted  = "deleted" // Restored along with the document
+)
+
+// DocumentSharesResponse lists a document's current shares and its past ones
+type DocumentSharesResponse struct {
+	ActiveShares []DocumentShare `json:"active_shares"`
+	ShareHistory []DocumentShare `json:"share_history"` // expired or replaced shares, newest first
 }
 
 // DocumentVersion tracks version history
@@ -43,15 +64,88 @@ type DocumentVersion struct {
 	CreatedAt   time.Time `json:"created_at"`
 }
 
+// SignatureRequest tracks a document sent to a client for e-signature
+type SignatureRequest struct {
+	ID                  int        `json:"id"`
+	DocumentID          int        `json:"document_id"`
+	AdvisorID           int        `json:"advisor_id"`
+	ClientID            int        `json:"client_id"`
+	Status              string     `json:"status"` // pending, sent, signed, declined
+	ExternalSignatureID *string    `json:"external_signature_id,omitempty"`
+	ExternalProvider    string     `json:"external_provider"` // docusign, hellosign, stub
+	SentAt              *time.Time `json:"sent_at,omitempty"`
+	SignedAt            *time.Time `json:"signed_at,omitempty"`
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
+}
+
+// SignatureRequestCreate represents a request to send a document for signature
+type SignatureRequestCreate struct {
+	Subject *string `json:"subject,omitempty"`
+	Message *string `json:"message,omitempty"`
+}
+
+// Signature request status constants
+const (
+	SignatureStatusPending  = "pending"
+	SignatureStatusSent     = "sent"
+	SignatureStatusSigned   = "signed"
+	SignatureStatusDeclined = "declined"
+)
+
+// DocumentRequest is a document an advisor has asked a client to upload
+type DocumentRequest struct {
+	ID                  int        `json:"id"`
+	AdvisorID           int        `json:"advisor_id"`
+	ClientID            int        `json:"client_id"`
+	Title               string     `json:"title"`
+	Description         *string    `json:"description,omitempty"`
+	Category            string     `json:"category"`
+	DueDate             *string    `json:"due_date,omitempty"` // YYYY-MM-DD
+	Status              string     `json:"status"`             // pending, fulfilled, cancelled
+	FulfilledDocumentID *int       `json:"fulfilled_document_id,omitempty"`
+	FulfilledAt         *time.Time `json:"fulfilled_at,omitempty"`
+	Overdue             bool       `json:"overdue"` // pending and past its due date
+	AdvisorName         string     `json:"advisor_name,omitempty"`
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
+}
+
+// DocumentRequestCreate is the request body for requesting a document from a client
+type DocumentRequestCreate struct {
+	Title       string  `json:"title"`
+	Description *string `json:"description,omitempty"`
+	Category    string  `json:"category,omitempty"`
+	DueDate     *string `json:"due_date,omitempty"`
+}
+
+// DocumentRequestUpdate is the request body for editing a document request.
+// Status may only move between pending and cancelled; fulfilment happens on upload.
+type DocumentRequestUpdate struct {
+	Title       *string `json:"title,omitempty"`
+	Description *string `json:"description,omitempty"`
+	Category    *string `json:"category,omitempty"`
+	DueDate     *string `json:"due_date,omitempty"` // "" clears the due date
+	Status      *string `json:"status,omitempty"`
+}
+
+// Document request status constants
+const (
+	DocRequestStatusPending   = "pending"
+	DocRequestStatusFulfilled = "fulfilled"
+	DocRequestStatusCancelled = "cancelled"
+)
+
 // DocumentCategory constants
 const (
-	DocCategoryTaxReturns  = "tax_returns"
-	DocCategoryStatements  = "statements"
-	DocCategoryEstateDocs  = "estate_docs"
-	DocCategoryInsurance   = "insurance"
-	DocCategoryInvestments = "investments"
-	DocCategoryReports     = "reports" // Auto-generated financial plan reports
-	DocCategoryOther       = "other"
+	DocCategoryTaxReturns    = "tax_returns"
+	DocCategoryStatements    = "statements"
+	DocCategoryEstateDocs    = "estate_docs"
+	DocCategoryInsurance     = "insurance"
+	DocCategoryInvestments   = "investments"
+	DocCategoryReports       = "reports"        // Auto-generated financial plan reports
+	DocCategoryAdvisorReport = "advisor_report" // Advisor-generated client dossiers
+	DocCategoryOther         = "other"
 )
 
 // Valid document categories
@@ -62,6 +156,7 @@ var ValidDocumentCategories = []string{
 	DocCategoryInsurance,
 	DocCategoryInvestments,
 	DocCategoryReports,
+	DocCategoryAdvisorReport,
 	DocCategoryOther,
 }
 
@@ -84,11 +179,40 @@ type DocumentListResponse struct {
 // DocumentWithShares includes sharing info
 type DocumentWithShares struct {
 	Document
-	SharedWith   []DocumentShareInfo `json:"shared_with,omitempty"`
-	UploadedByName string            `json:"uploaded_by_name,omitempty"`
-	CanEdit      bool                `json:"can_edit"`
-	CanDelete    bool                `json:"can_delete"`
-	CanShare     bool                `json:"can_share"`
+	SharedWith     []DocumentShareInfo `json:"shared_with,omitempty"`
+	UploadedByName string              `json:"uploaded_by_name,omitempty"`
+	CanEdit        bool                `json:"can_edit"`
+	CanDelete      bool                `json:"can_delete"`
+	CanShare       bool                `json:"can_share"`
+	Superseded     bool                `json:"superseded,omitempty"` // an older version, listed with include_versions=true
+}
+
+// DocumentSearchResult is a document whose extracted text matched a search
+type DocumentSearchResult struct {
+	Document
+	OwnerName            string   `json:"owner_name"`
+	Snippet              string   `json:"snippet"`               // text around the first match, matches wrapped in <mark>
+	ExtractionConfidence float64  `json:"extraction_confidence"` // 0-1, how cleanly the text was extracted
+	Relevance            *float64 `json:"relevance,omitempty"`   // full-text match score, when full-text search is used
+}
+
+// DocumentSearchResponse is a page of document search results
+type DocumentSearchResponse struct {
+	Results    []DocumentSearchResult `json:"results"`
+	TotalCount int                    `json:"total_count"`
+	SearchMode string                 `json:"search_mode"` // fulltext or like
+}
+
+// BulkDownloadRequest is the request body for downloading several documents as one ZIP
+type BulkDownloadRequest struct {
+	DocumentIDs []int `json:"document_ids"`
+}
+
+// ExcludedDocument is a document left out of a bulk download
+type ExcludedDocument struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+	Size int64  `json:"size"`
 }
 
 // DocumentShareInfo is a simplified share record for responses
@@ -2,11 +2,16 @@ package models
 
 import "time"
 
-// Conversation represents a messaging thread between an advisor and client
+// Conversation represents a messaging thread between a client and one or more advisors.
+// AdvisorID is the advisor who started the conversation; every member, including
+// additional advisors in a group conversation, is listed in Participants.
 type Conversation struct {
 	ID                 int        `json:"id" db:"id"`
 	AdvisorID          int        `json:"advisorId" db:"advisor_id"`
 	ClientID           int        `json:"clientId" db:"client_id"`
+	IsGroup            bool       `json:"isGroup" db:"is_group"`
+	Status             string     `json:"status" db:"status"` // active, archived
+	ArchivedAt         *time.Time `json:"archivedAt,omitempty" db:"archived_at"`
 	LastMessageAt      *time.Time `json:"lastMessageAt,omitempty" db:"last_message_at"`
 	UnreadCountAdvisor int        `json:"unreadCountAdvisor" db:"unread_count_advisor"`
 	UnreadCountClient  int        `json:"unreadCountClient" db:"unread_count_client"`
@@ -14,9 +19,34 @@ type Conversation struct {
 	UpdatedAt          time.Time  `json:"updatedAt" db:"updated_at"`
 
 	// Joined fields
-	AdvisorName string `json:"advisorName,omitempty" db:"-"`
-	ClientName  string `json:"clientName,omitempty" db:"-"`
-	ClientEmail string `json:"clientEmail,omitempty" db:"-"`
+	AdvisorName  string                    `json:"advisorName,omitempty" db:"-"`
+	ClientName   string                    `json:"clientName,omitempty" db:"-"`
+	ClientEmail  string                    `json:"clientEmail,omitempty" db:"-"`
+	UnreadCount  int                       `json:"unreadCount" db:"-"`
+	Participants []ConversationParticipant `json:"participants" db:"-"`
+}
+
+// Conversation status constants. Archived conversations are kept for record
+// retention and accept no new messages.
+const (
+	ConversationStatusActive   = "active"
+	ConversationStatusArchived = "archived"
+)
+
+// ConversationParticipant is a member of a conversation
+type ConversationParticipant struct {
+	UserID   int       `json:"userId" db:"user_id"`
+	Name     string    `json:"name" db:"-"`
+	Role     string    `json:"role" db:"role"` // advisor, client
+	JoinedAt time.Time `json:"joinedAt" db:"joined_at"`
+}
+
+// StartConversationRequest is the request body for starting a conversation.
+// ParticipantIDs may list the client and any advisors to include; ClientID is
+// the original single-client form and is still accepted.
+type StartConversationRequest struct {
+	ClientID       int   `json:"clientId,omitempty"`
+	ParticipantIDs []int `json:"participantIds,omitempty"`
 }
 
 // Message represents an E2E encrypted message
@@ -27,6 +57,7 @@ type Message struct {
 	EncryptedContent string     `json:"encryptedContent" db:"encrypted_content"`
 	Nonce            string     `json:"nonce" db:"nonce"`
 	ReadAt           *time.Time `json:"readAt,omitempty" db:"read_at"`
+	DeliveredAt      *time.Time `json:"deliveredAt,omitempty" db:"delivered_at"` // first reached a recipient's socket or fetch
 	CreatedAt        time.Time  `json:"createdAt" db:"created_at"`
 
 	// Joined/computed fields
@@ -43,12 +74,35 @@ type UserPublicKey struct {
 	CreatedAt time.Time `json:"createdAt" db:"created_at"`
 }
 
-// SendMessageRequest is the request body for sending a message
+// SendMessageRequest is the request body for sending a message. A Nonce of
+// MessageNoncePlaintext sends EncryptedContent unencrypted, which makes the
+// message searchable on the server.
 type SendMessageRequest struct {
 	EncryptedContent string `json:"encryptedContent"`
 	Nonce            string `json:"nonce"`
 }
 
+// MessageNoncePlaintext marks a message stored without E2E encryption
+const MessageNoncePlaintext = "plaintext"
+
+// MessageSearchResult is a message returned by a conversation search.
+// Plaintext messages are only returned when they match and carry a Snippet;
+// encrypted messages are returned as-is for the client to decrypt and filter.
+type MessageSearchResult struct {
+	Message
+	Encrypted bool   `json:"encrypted"`
+	Snippet   string `json:"snippet,omitempty"` // match context with matches wrapped in <mark>
+}
+
+// MessageSearchResponse is a page of conversation search results
+type MessageSearchResponse struct {
+	Results    []MessageSearchResult `json:"results"`
+	TotalCount int                   `json:"totalCount"`
+	Page       int                   `json:"page"`
+	Limit      int                   `json:"limit"`
+	SearchMode string                `json:"searchMode"` // fulltext or like, for plaintext messages
+}
+
 // ConversationWithLastMessage includes the last message preview
 type ConversationWithLastMessage struct {
 	Conversation
@@ -62,6 +116,12 @@ type RegisterPublicKeyRequest struct {
 	KeyID     string `json:"keyId"`
 }
 
+// RegisterPushTokenRequest is the request body for registering a device for push notifications
+type RegisterPushTokenRequest struct {
+	Token    string `json:"token"`
+	Platform string `json:"platform"` // ios, android, web
+}
+
 // UnreadCounts represents unread message counts for a user
 type UnreadCounts struct {
 	TotalUnread    int `json:"totalUnread"`
@@ -0,0 +1,27 @@
+package models
+
+// NetWorthSnapshot is a user's asset and debt totals at the end of one day
+type NetWorthSnapshot struct {
+	Date        string  `json:"date"` // YYYY-MM-DD
+	TotalAssets float64 `json:"totalAssets"`
+	TotalDebts  float64 `json:"totalDebts"`
+	NetWorth    float64 `json:"netWorth"`
+}
+
+// Emergency fund coverage levels, relative to the recommended months
+const (
+	EmergencyFundCritical = "critical" // under half the recommendation
+	EmergencyFundLow      = "low"      // under the recommendation
+	EmergencyFundAdequate = "adequate" // up to 1.5x the recommendation
+	EmergencyFundStrong   = "strong"
+)
+
+// EmergencyFundStatus compares liquid savings with recent monthly spending
+type EmergencyFundStatus struct {
+	MonthlyExpenses   float64 `json:"monthlyExpenses"` // average over the last 3 months
+	LiquidAssets      float64 `json:"liquidAssets"`
+	CoverageMonths    float64 `json:"coverageMonths"`
+	RecommendedMonths int     `json:"recommendedMonths"`
+	ShortfallAmount   float64 `json:"shortfallAmount"` // 0 when the recommendation is met
+	Status            string  `json:"status"`
+}
@@ -10,7 +10,8 @@ type PlaidItem struct {
 	AccessToken     string    `json:"-" db:"access_token"` // Never expose
 	InstitutionID   string    `json:"institutionId" db:"institution_id"`
 	InstitutionName string    `json:"institutionName" db:"institution_name"`
-	Status          string    `json:"status" db:"status"`
+	Status          string    `json:"status" db:"status"` // active, or needs_relink until re-linked in update mode
+	ErrorCode       *string   `json:"errorCode,omitempty" db:"error_code"`
 	CreatedAt       time.Time `json:"createdAt" db:"created_at"`
 	UpdatedAt       time.Time `json:"updatedAt" db:"updated_at"`
 }
@@ -41,13 +42,31 @@ type LinkTokenRequest struct {
 
 // LinkTokenResponse is returned when creating a link token
 type LinkTokenResponse struct {
-	LinkToken  string    `json:"linkToken"`
-	Expiration time.Time `json:"expiration"`
+	LinkToken    string    `json:"linkToken"`
+	Expiration   time.Time `json:"expiration"`
+	OAuthStateID string    `json:"oauthStateId,omitempty"` // Set when OAuth redirects are enabled
 }
 
 // ExchangeTokenRequest is the request to exchange a public token
 type ExchangeTokenRequest struct {
-	PublicToken string `json:"publicToken"`
+	PublicToken  string `json:"publicToken"`
+	OAuthStateID string `json:"oauthStateId,omitempty"` // Required when Link resumed after an OAuth redirect
+}
+
+// PlaidOAuthSession tracks a Link flow that may redirect through a bank's OAuth page
+type PlaidOAuthSession struct {
+	OAuthStateID string    `json:"oauthStateId" db:"oauth_state_id"`
+	UserID       int       `json:"userId" db:"user_id"`
+	CreatedAt    time.Time `json:"createdAt" db:"created_at"`
+	ExpiresAt    time.Time `json:"expiresAt" db:"expires_at"`
+}
+
+// OAuthStatusResponse reports whether a pending OAuth session can still be completed
+type OAuthStatusResponse struct {
+	OAuthStateID string    `json:"oauthStateId"`
+	Valid        bool      `json:"valid"`
+	Expired      bool      `json:"expired"`
+	ExpiresAt    time.Time `json:"expiresAt"`
 }
 
 // PlaidItemResponse is returned after linking an institution
@@ -64,3 +83,18 @@ type SyncResponse struct {
 	UpdatedAssets  int `json:"updatedAssets"`
 	UpdatedDebts   int `json:"updatedDebts"`
 }
+
+// InvestmentHolding is one security position in a Plaid investment account
+type InvestmentHolding struct {
+	ID               int       `json:"id"`
+	AccountID        string    `json:"accountId"`
+	AccountName      *string   `json:"accountName,omitempty"`
+	SecurityID       string    `json:"securityId"`
+	TickerSymbol     *string   `json:"tickerSymbol,omitempty"`
+	Name             string    `json:"name"`
+	Quantity         float64   `json:"quantity"`
+	InstitutionValue float64   `json:"institutionValue"`
+	CostBasis        *float64  `json:"costBasis,omitempty"`
+	GainLoss         *float64  `json:"gainLoss,omitempty"` // institutionValue - costBasis, when Plaid reports a cost basis
+	UpdatedAt        time.Time `json:"updatedAt"`
+}
@@ -14,7 +14,11 @@ type SimulationParams struct {
 	ContributionGrowth   float64 `json:"contributionGrowth"`   // default 0.02 (2% annual raise)
 	RetirementSpending   float64 `json:"retirementSpending"`   // monthly spending in retirement
 	SocialSecurityAmount float64 `json:"socialSecurityAmount"` // monthly SS benefit
-	SocialSecurityAge    int     `json:"socialSecurityAge"`    // age SS begins (default 67)
+	SocialSecurityAge    int     `json:"socialSecurityAge"`    // full retirement age for SocialSecurityAmount (default 67)
+
+	SocialSecurityClaimAge int `json:"socialSecurityClaimAge,omitempty"` // age benefits start (default SocialSecurityAge); early/late claiming adjusts the benefit
+
+	CostOfLivingAdjustment *float64 `json:"costOfLivingAdjustment,omitempty"` // annual SS COLA (default 0.025); pointer so 0% is expressible
 
 	// Tier 3 - Advanced (hidden by default)
 	EmployerMatch         float64 `json:"employerMatch"`         // match percentage (e.g., 0.50 = 50%)
@@ -22,14 +26,68 @@ type SimulationParams struct {
 	Volatility            float64 `json:"volatility"`            // default 0.15 (15%)
 	PensionIncome         float64 `json:"pensionIncome"`         // monthly pension
 	OneTimeEvents         []Event `json:"oneTimeEvents"`
+	IncomeStreams         []IncomeStream `json:"incomeStreams,omitempty"` // rental, dividends, side gig, etc.
 	WithdrawalStrategy    string  `json:"withdrawalStrategy"`    // "fixed", "dynamic", "guardrails"
 	RetirementTaxRate     float64 `json:"retirementTaxRate"`     // effective tax rate in retirement
-	RunHistoricalTest     bool    `json:"runHistoricalTest"`     // run against historical sequences
+	TaxFreeWithdrawals    bool    `json:"taxFreeWithdrawals,omitempty"` // portfolio withdrawals untaxed (e.g., all-Roth)
+	TaxDeferredBalance    float64 `json:"taxDeferredBalance,omitempty"` // traditional IRA/401k share of the portfolio, subject to RMDs from age 73
+	RunHistoricalTest     bool    `json:"runHistoricalTest"`     // also replay the plan against each historical starting year (1928-2023)
 	ExcludeCreditCardDebt bool    `json:"excludeCreditCardDebt"` // exclude revolving credit from projections
 	EnableGlidePath       bool    `json:"enableGlidePath"`       // auto-adjust risk by age (target-date style)
+	JointSimulation       bool    `json:"jointSimulation"`       // model a spouse's lifetime alongside the primary
+	SpouseAge             int     `json:"spouseAge"`             // spouse's current age (required for joint)
+	Sex                   string  `json:"sex,omitempty"`         // "male" or "female" for mortality (blank = blended)
+	SpouseSex             string  `json:"spouseSex,omitempty"`   // "male" or "female" for mortality (blank = blended)
 
 	// Tier 4 - Behavioral Risk (experimental)
 	BehavioralRisk *BehavioralParams `json:"behavioralRisk,omitempty"` // Behavioral risk modeling parameters
+
+	BucketStrategy *BucketStrategy `json:"bucketStrategy,omitempty"` // split the portfolio into cash/bond/equity buckets in retirement
+
+	CharitableGiving *CharitableGiving `json:"charitableGiving,omitempty"` // recurring gifts paid from the portfolio
+
+	Spouse *SpouseParams `json:"spouse,omitempty"` // a second earner's contributions and Social Security (nil = single income)
+
+	HistoricalEquityAllocation *float64 `json:"historicalEquityAllocation,omitempty"` // stock share of the historical blend (default 0.6); pointer so 0 is expressible
+}
+
+// SpouseParams describes a working spouse or partner. The spouse contributes
+// until their own retirement age and collects their own Social Security from
+// full retirement age (SocialSecurityAge); spending stays a household figure.
+
+type SpouseParams struct {
+	SpouseCurrentAge           int      `json:"spouseCurrentAge"`
+	SpouseRetirementAge        int      `json:"spouseRetirementAge"`
+	SpouseMonthlyContribution  float64  `json:"spouseMonthlyContribution"`
+	SpouseContributionGrowth   *float64 `json:"spouseContributionGrowth,omitempty"` // annual raise (default ContributionGrowth); pointer so 0 is expressible
+	SpouseSocialSecurityAmount float64  `json:"spouseSocialSecurityAmount"`         // monthly benefit at full retirement age
+}
+
+// Charitable gift types
+const (
+	GiftTypeCash = "cash" // paid from taxable withdrawals
+	GiftTypeQCD  = "qcd"  // Qualified Charitable Distribution straight from a traditional IRA (age 70½+)
+	GiftTypeDAF  = "daf"  // Donor Advised Fund funded up front in StartYear
+)
+
+// CharitableGiving describes a recurring charitable gift
+type CharitableGiving struct {
+	AnnualGiftAmount float64 `json:"annualGiftAmount"`
+	GiftType         string  `json:"giftType"`  // cash, qcd, daf
+	StartYear        int     `json:"startYear"` // year relative to start (1, 2, 3...)
+	EndYear          int     `json:"endYear"`   // last gift year (0 = no end)
+}
+
+// BucketStrategy splits the portfolio at retirement into three pools: cash
+// (near-term spending), bonds (medium term) and equities (long term).
+// Spending is drawn from cash, which is refilled from bonds, which are
+// refilled from equities. Equities use ExpectedReturn/Volatility.
+type BucketStrategy struct {
+	Bucket1Pct        float64 `json:"bucket1Pct"`        // cash share of the portfolio (0-1)
+	Bucket2Pct        float64 `json:"bucket2Pct"`        // bond share of the portfolio (0-1)
+	Bucket3Pct        float64 `json:"bucket3Pct"`        // equity share; the three must sum to 1
+	Bucket2Return     float64 `json:"bucket2Return"`     // bond bucket expected return (default 0.04)
+	Bucket2Volatility float64 `json:"bucket2Volatility"` // bond bucket volatility (default 0.06)
 }
 
 // Event represents a one-time or recurring financial event
@@ -40,6 +98,16 @@ type Event struct {
 	Recurring   bool    `json:"recurring"`   // if true, repeats every year after
 }
 
+// IncomeStream represents a recurring non-salary income source
+type IncomeStream struct {
+	Type          string  `json:"type"`          // e.g., "rental", "dividends", "side_gig"
+	MonthlyAmount float64 `json:"monthlyAmount"` // monthly amount in the start year
+	StartYear     int     `json:"startYear"`     // year relative to start (1, 2, 3...)
+	EndYear       int     `json:"endYear"`       // last year received (0 = no end)
+	GrowthRate    float64 `json:"growthRate"`    // annual growth (0 = flat)
+	IsTaxable     bool    `json:"isTaxable"`     // taxed at the retirement tax rate
+}
+
 // MonteCarloRequest is the API request for running a simulation
 type MonteCarloRequest struct {
 	Params     *SimulationParams `json:"params"`
@@ -48,6 +116,165 @@ type MonteCarloRequest struct {
 	Notes      *string           `json:"notes,omitempty"`      // Optional notes for saved simulation
 }
 
+// PurchaseImpactRequest is the API request for modeling a major purchase against a saved simulation
+type PurchaseImpactRequest struct {
+	BaseSimulationID int     `json:"baseSimulationId"`
+	PurchaseAmount   float64 `json:"purchaseAmount"`
+	FinanceAmount    float64 `json:"financeAmount"` // portion paid with a loan
+	LoanRate         float64 `json:"loanRate"`      // annual rate (e.g., 0.07 = 7%)
+	LoanTermYears    int     `json:"loanTermYears"`
+	PurchaseYear     int     `json:"purchaseYear"` // year relative to start (1, 2, 3...)
+}
+
+// PurchaseImpactResponse compares a simulation with and without the purchase
+type PurchaseImpactResponse struct {
+	WithoutPurchaseP50      float64 `json:"withoutPurchaseP50"`
+	WithPurchaseP50         float64 `json:"withPurchaseP50"`
+	WithoutSuccessRate      float64 `json:"withoutSuccessRate"`
+	WithPurchaseSuccessRate float64 `json:"withPurchaseSuccessRate"`
+	DownPayment             float64 `json:"downPayment"`
+	MonthlyPayment          float64 `json:"monthlyPayment"`
+	BreakEvenRecoveryYear   *int    `json:"breakEvenRecoveryYear"` // nil if median never recovers within the horizon
+}
+
+// RothVsTraditionalRequest is the API request for comparing Roth and traditional IRA contributions
+type RothVsTraditionalRequest struct {
+	CurrentAge              int     `json:"currentAge"`
+	AnnualContribution      float64 `json:"annualContribution"`
+	CurrentMarginalRate     float64 `json:"currentMarginalRate"`     // e.g., 0.24 = 24%
+	ProjectedRetirementRate float64 `json:"projectedRetirementRate"` // e.g., 0.12 = 12%
+	YearsToRetirement       int     `json:"yearsToRetirement"`
+	RetirementSpending      float64 `json:"retirementSpending,omitempty"` // monthly; defaults to 4% of contributions
+}
+
+// RothVsTraditionalResponse compares the two contribution strategies
+type RothVsTraditionalResponse struct {
+	RothP50                float64 `json:"rothP50"`
+	TraditionalP50         float64 `json:"traditionalP50"`
+	RothSuccessRate        float64 `json:"rothSuccessRate"`
+	TraditionalSuccessRate float64 `json:"traditionalSuccessRate"`
+	CrossoverAge           *int    `json:"crossoverAge"`   // age from which Roth stays ahead; nil if it never does
+	Recommendation         string  `json:"recommendation"` // "roth", "traditional", or "either"
+	Rationale              string  `json:"rationale"`
+}
+
+// RothConversionRequest describes a traditional IRA to fill up to a tax
+// bracket with Roth conversions
+type RothConversionRequest struct {
+	TraditionalBalance float64 `json:"traditionalBalance"`
+	RothBalance        float64 `json:"rothBalance"`
+	CurrentAge         int     `json:"currentAge"`
+	TaxableIncome      float64 `json:"taxableIncome"`            // before conversions, after deductions; assumed steady
+	TargetTaxBracket   float64 `json:"targetTaxBracket"`         // marginal rate to fill, e.g. 0.22
+	FilingStatus       string  `json:"filingStatus,omitempty"`   // defaults to single
+	ExpectedReturn     float64 `json:"expectedReturn,omitempty"` // annual growth; defaults to 6%
+}
+
+// RothConversionYear is one year of a conversion schedule
+type RothConversionYear struct {
+	Year               int     `json:"year"`
+	Age                int     `json:"age"`
+	Amount             float64 `json:"amount"`
+	EstimatedTax       float64 `json:"estimatedTax"`       // extra federal tax caused by the conversion
+	TraditionalBalance float64 `json:"traditionalBalance"` // at year end, after growth
+	RothBalance        float64 `json:"rothBalance"`
+}
+
+// RothConversionResponse is a bracket-filling conversion schedule
+type RothConversionResponse struct {
+	YearlyConversions     []RothConversionYear `json:"yearlyConversions"`
+	TotalConverted        float64              `json:"totalConverted"`
+	TotalTax              float64              `json:"totalTax"`
+	EstimatedTaxSavings   float64              `json:"estimatedTaxSavings"`   // tax avoided at the projected RMD-age rate, less conversion tax
+	ProjectedRMDReduction float64              `json:"projectedRMDReduction"` // smaller first RMD than without conversions
+}
+
+// College529Request describes a 529 plan to project to a child's first
+// year of college
+type College529Request struct {
+	ChildBirthYear        int     `json:"childBirthYear"`
+	CurrentBalance        float64 `json:"currentBalance"`
+	MonthlyContribution   float64 `json:"monthlyContribution"`
+	TargetYear            int     `json:"targetYear"`            // year college starts
+	StateDeduction        float64 `json:"stateDeduction"`        // annual state deduction limit for contributions
+	State                 string  `json:"state,omitempty"`       // two-letter code; no tax savings without one
+	ExpectedCostOfCollege float64 `json:"expectedCostOfCollege"` // total cost in target-year dollars
+}
+
+// College529Response is a deterministic 529 projection. BreakdownByYear
+// reuses YearProjection with every percentile set to the single projected
+// balance.
+type College529Response struct {
+	ProjectedBalance          float64          `json:"projectedBalance"`
+	Shortfall                 float64          `json:"shortfall"`
+	RequiredAdditionalMonthly float64          `json:"requiredAdditionalMonthly"` // extra monthly contribution that closes the shortfall
+	TotalContributions        float64          `json:"totalContributions"`
+	StateTaxSavings           float64          `json:"stateTaxSavings"` // total state tax saved by deducting contributions
+	ExpectedReturn            float64          `json:"expectedReturn"`
+	BreakdownByYear           []YearProjection `json:"breakdownByYear"`
+}
+
+// SocialSecurityEstimateRequest describes a steady earnings history for a
+// rough Social Security benefit estimate
+type SocialSecurityEstimateRequest struct {
+	BirthYear           int     `json:"birthYear"`
+	CurrentAnnualIncome float64 `json:"currentAnnualIncome"` // assumed for every year worked, in today's dollars
+	YearsWorked         int     `json:"yearsWorked"`         // total years of covered earnings by retirement
+	ClaimingAge         int     `json:"claimingAge"`         // 62-70
+}
+
+// SocialSecurityEstimateResponse is the estimated monthly benefit, in today's dollars
+type SocialSecurityEstimateResponse struct {
+	EstimatedMonthlyBenefit  float64 `json:"estimatedMonthlyBenefit"` // at the claiming age
+	FullRetirementAgeBenefit float64 `json:"fullRetirementAgeBenefit"`
+	FullRetirementAge        float64 `json:"fullRetirementAge"` // years, e.g. 66.83 for 66 and 10 months
+	AIME                     float64 `json:"aime"`
+	BreakEvenAge             *int    `json:"breakEvenAge"` // age when the later of claiming age and FRA catches up; nil when they're the same
+}
+
+// COLASensitivityRequest is the API request for the Social Security COLA sensitivity analysis
+type COLASensitivityRequest struct {
+	Params *SimulationParams `json:"params"`
+}
+
+// COLAScenario is the outcome of a simulation at one COLA assumption
+type COLAScenario struct {
+	CostOfLivingAdjustment float64 `json:"costOfLivingAdjustment"`
+	SuccessRate            float64 `json:"successRate"`
+	FinalP10               float64 `json:"finalP10"`
+	FinalP50               float64 `json:"finalP50"`
+	FinalP90               float64 `json:"finalP90"`
+}
+
+// COLASensitivityResponse compares plan outcomes across COLA assumptions
+type COLASensitivityResponse struct {
+	Scenarios         []COLAScenario `json:"scenarios"`
+	SuccessRateSpread float64        `json:"successRateSpread"` // max - min success rate, in percentage points
+	Insights          []Insight      `json:"insights"`
+}
+
+// SensitivityRequest is the API request for a parameter sensitivity analysis
+type SensitivityRequest struct {
+	Params *SimulationParams `json:"params"`
+}
+
+// SensitivityResult is the success-rate change from nudging one parameter
+type SensitivityResult struct {
+	Param          string  `json:"param"`  // monthlyContribution, retirementAge, expectedReturn, volatility, retirementSpending
+	Change         float64 `json:"change"` // relative perturbation applied, e.g. 0.10 or -0.10
+	BaseValue      float64 `json:"baseValue"`
+	PerturbedValue float64 `json:"perturbedValue"`
+	BaseRate       float64 `json:"baseRate"`
+	PerturbedRate  float64 `json:"perturbedRate"`
+	Delta          float64 `json:"delta"` // PerturbedRate - BaseRate, in percentage points
+}
+
+// SensitivityResponse ranks parameters by how much they move the success rate
+type SensitivityResponse struct {
+	BaseSuccessRate float64             `json:"baseSuccessRate"`
+	Results         []SensitivityResult `json:"results"` // sorted by |Delta|, largest first
+}
+
 // YearProjection contains projection data for a single year
 type YearProjection struct {
 	Year          int     `json:"year"`
@@ -60,6 +287,12 @@ type YearProjection struct {
 	Phase         string  `json:"phase"`         // "accumulation" or "distribution"
 	Contributions float64 `json:"contributions"` // total contributed this year
 	Withdrawals   float64 `json:"withdrawals"`   // total withdrawn this year
+	RMDAmount     float64 `json:"rmdAmount"`     // average required minimum distribution this year
+
+	// Inflation-adjusted (today's dollars) percentiles: nominal / (1 + InflationRate)^Year
+	RealP10 float64 `json:"realP10"`
+	RealP50 float64 `json:"realP50"`
+	RealP90 float64 `json:"realP90"`
 }
 
 // Milestone represents a financial goal and probability of achieving it
@@ -72,9 +305,10 @@ type Milestone struct {
 
 // Insight represents an actionable recommendation
 type Insight struct {
-	Type    string `json:"type"`    // "warning", "opportunity", "info", "success"
-	Title   string `json:"title"`   // short title
-	Message string `json:"message"` // detailed explanation
+	Type    string `json:"type"`           // "warning", "opportunity", "info", "success"
+	Code    string `json:"code,omitempty"` // stable identifier for programmatic checks
+	Title   string `json:"title"`          // short title
+	Message string `json:"message"`        // detailed explanation
 }
 
 // MonteCarloResponse is the API response for a simulation
@@ -83,6 +317,8 @@ type MonteCarloResponse struct {
 	Summary     ProjectionSummary `json:"summary"`
 	Milestones  []Milestone       `json:"milestones,omitempty"`
 	Insights    []Insight         `json:"insights,omitempty"`
+
+	HistoricalPassRate *float64 `json:"historicalPassRate,omitempty"` // % of historical starting years that didn't run out of money (RunHistoricalTest only)
 }
 
 // ProjectionSummary contains overall simulation results
@@ -101,6 +337,15 @@ type ProjectionSummary struct {
 	TotalWithdrawals     float64 `json:"totalWithdrawals"`               // sum of all withdrawals
 	AccumulationWarnings int     `json:"accumulationWarnings,omitempty"` // simulations with pre-retirement negative net worth
 
+	AccumulationShortfallPct float64  `json:"accumulationShortfallPct"`          // fraction (0-1) of simulations with negative net worth before retirement
+	MedianInflectionYear     *int     `json:"medianInflectionYear,omitempty"`    // first pre-retirement year the median falls year-over-year
+	SingleBucketSuccessRate  *float64 `json:"singleBucketSuccessRate,omitempty"` // same plan without the bucket strategy, when one is set
+
+	// Final percentiles in today's dollars, deflated by InflationRate over Years
+	RealFinalP10 float64 `json:"realFinalP10"`
+	RealFinalP50 float64 `json:"realFinalP50"`
+	RealFinalP90 float64 `json:"realFinalP90"`
+
 	// Enhanced Success Metrics (Priority 3)
 	EnhancedMetrics *EnhancedMetrics `json:"enhancedMetrics,omitempty"`
 }
@@ -229,28 +474,44 @@ type BehavioralParams struct {
 	RecoveryDelay     int     `json:"recoveryDelay"`     // Months before re-entering market
 }
 
+// DefaultCostOfLivingAdjustment is the long-run average Social Security COLA
+const DefaultCostOfLivingAdjustment = 0.025
+
+// DefaultHistoricalEquityAllocation is the stock share used when backtesting
+// against historical stock and bond returns (a 60/40 portfolio)
+const DefaultHistoricalEquityAllocation = 0.6
+
+// Default bond bucket assumptions for the bucket strategy
+const (
+	DefaultBucket2Return     = 0.04
+	DefaultBucket2Volatility = 0.06
+)
+
 // DefaultSimulationParams returns params with sensible defaults
+
 func DefaultSimulationParams() SimulationParams {
+	cola := DefaultCostOfLivingAdjustment
 	return SimulationParams{
-		TimeHorizonYears:     30,
-		MonthlyContribution:  0,
-		RetirementAge:        65,
-		CurrentAge:           35,
-		ExpectedReturn:       0.07,
-		InflationRate:        0.03,
-		ContributionGrowth:   0.02,
-		RetirementSpending:   0,
-		SocialSecurityAmount: 0,
-		SocialSecurityAge:    67,
-		EmployerMatch:        0,
-		EmployerMatchLimit:   0,
-		Volatility:           0.15,
-		PensionIncome:        0,
-		OneTimeEvents:        []Event{},
-		WithdrawalStrategy:   "fixed",
-		RetirementTaxRate:    0.22,
-		RunHistoricalTest:    false,
-		EnableGlidePath:      false,
+		TimeHorizonYears:       30,
+		MonthlyContribution:    0,
+		RetirementAge:          65,
+		CurrentAge:             35,
+		ExpectedReturn:         0.07,
+		InflationRate:          0.03,
+		ContributionGrowth:     0.02,
+		RetirementSpending:     0,
+		SocialSecurityAmount:   0,
+		SocialSecurityAge:      67,
+		CostOfLivingAdjustment: &cola,
+		EmployerMatch:          0,
+		EmployerMatchLimit:     0,
+		Volatility:             0.15,
+		PensionIncome:          0,
+		OneTimeEvents:          []Event{},
+		WithdrawalStrategy:     "fixed",
+		RetirementTaxRate:      0.22,
+		RunHistoricalTest:      false,
+		EnableGlidePath:        false,
 	}
 }
 
@@ -279,6 +540,42 @@ func (p *SimulationParams) ApplyDefaults() {
 	if p.SocialSecurityAge == 0 {
 		p.SocialSecurityAge = defaults.SocialSecurityAge
 	}
+	if p.SocialSecurityClaimAge == 0 {
+		p.SocialSecurityClaimAge = p.SocialSecurityAge
+	}
+	if p.CostOfLivingAdjustment == nil {
+		p.CostOfLivingAdjustment = defaults.CostOfLivingAdjustment
+	}
+	if p.HistoricalEquityAllocation == nil {
+		allocation := DefaultHistoricalEquityAllocation
+		p.HistoricalEquityAllocation = &allocation
+	}
+	if p.BucketStrategy != nil {
+		if p.BucketStrategy.Bucket2Return == 0 {
+			p.BucketStrategy.Bucket2Return = DefaultBucket2Return
+		}
+		if p.BucketStrategy.Bucket2Volatility == 0 {
+			p.BucketStrategy.Bucket2Volatility = DefaultBucket2Volatility
+		}
+	}
+	if p.Spouse != nil {
+		// Default a copy, so the caller's SpouseParams are left as given
+		spouse := *p.Spouse
+		p.Spouse = &spouse
+		if p.Spouse.SpouseCurrentAge == 0 {
+			p.Spouse.SpouseCurrentAge = p.CurrentAge
+			if p.SpouseAge > 0 {
+				p.Spouse.SpouseCurrentAge = p.SpouseAge
+			}
+		}
+		if p.Spouse.SpouseRetirementAge == 0 {
+			p.Spouse.SpouseRetirementAge = defaults.RetirementAge
+		}
+		if p.Spouse.SpouseContributionGrowth == nil {
+			growth := p.ContributionGrowth
+			p.Spouse.SpouseContributionGrowth = &growth
+		}
+	}
 	if p.Volatility == 0 {
 		p.Volatility = defaults.Volatility
 	}
@@ -17,8 +17,41 @@ type Transaction struct {
 	Pending            bool      `json:"pending" db:"pending"`
 	TransactionType    *string   `json:"transactionType,omitempty" db:"transaction_type"`
 	ISOCurrencyCode    *string   `json:"isoCurrencyCode,omitempty" db:"iso_currency_code"`
+	Source             string    `json:"source" db:"source"` // TransactionSourcePlaid or TransactionSourceManual
 	CreatedAt          time.Time `json:"createdAt" db:"created_at"`
 	UpdatedAt          time.Time `json:"updatedAt" db:"updated_at"`
+
+	// Income/expense classification, computed when transactions are listed
+	IsIncome    bool `json:"isIncome"`
+	NeedsReview bool `json:"needsReview"` // classification confidence too low; ask the user
+}
+
+// Where a transaction came from. Manual transactions (entered by the user or
+// imported from a CSV) and OFX imports can be edited and deleted; Plaid ones
+// are soft-deleted.
+const (
+	TransactionSourcePlaid  = "plaid"
+	TransactionSourceManual = "manual"
+	TransactionSourceOFX    = "ofx"
+)
+
+// ManualTransactionRequest creates or replaces a manually entered
+// transaction. Amount follows Plaid's sign convention: positive is money out.
+type ManualTransactionRequest struct {
+	Date         string  `json:"date"` // YYYY-MM-DD
+	Amount       float64 `json:"amount"`
+	Name         string  `json:"name"`
+	Category     *string `json:"category,omitempty"` // categorization rules apply when omitted
+	MerchantName *string `json:"merchantName,omitempty"`
+	AccountName  *string `json:"accountName,omitempty"`
+	Source       string  `json:"source,omitempty"` // must be "manual" if given
+}
+
+// TransactionPage is one page of a transaction listing, newest first
+type TransactionPage struct {
+	Items      []Transaction `json:"items"`
+	NextCursor string        `json:"nextCursor,omitempty"` // pass as ?cursor= for the next page
+	HasMore    bool          `json:"hasMore"`
 }
 
 type TransactionSummary struct {
@@ -27,6 +60,18 @@ type TransactionSummary struct {
 	NetCashFlow   float64           `json:"netCashFlow"`
 	ByCategory    []CategorySummary `json:"byCategory"`
 	ByMonth       []MonthSummary    `json:"byMonth"`
+
+	UnclassifiedCount int `json:"unclassifiedCount"` // transactions counted on a low-confidence guess
+}
+
+// AnomalyAlert flags a category whose spending this month is unusually high
+// against the user's own monthly history
+type AnomalyAlert struct {
+	Category           string  `json:"category"`
+	CurrentMonthAmount float64 `json:"currentMonthAmount"`
+	HistoricalMean     float64 `json:"historicalMean"`
+	HistoricalStdDev   float64 `json:"historicalStdDev"`
+	ZScore             float64 `json:"zScore"` // standard deviations above the mean
 }
 
 type CategorySummary struct {
@@ -43,7 +88,48 @@ type MonthSummary struct {
 }
 
 type SyncTransactionsResponse struct {
-	NewTransactions     int `json:"newTransactions"`
-	UpdatedTransactions int `json:"updatedTransactions"`
-	RemovedTransactions int `json:"removedTransactions"`
+	NewTransactions           int `json:"newTransactions"`
+	UpdatedTransactions       int `json:"updatedTransactions"`
+	RemovedTransactions       int `json:"removedTransactions"`
+	RecategorizedTransactions int `json:"recategorizedTransactions"` // changed by the user's categorization rules
 }
+
+// TransactionRule overrides the category of transactions whose name or
+// merchant matches, e.g. merchant_name starts_with "NETFLIX" -> ENTERTAINMENT
+type TransactionRule struct {
+	ID            int       `json:"id"`
+	UserID        int       `json:"userId"`
+	MatchField    string    `json:"matchField"`    // name, merchant_name
+	MatchOperator string    `json:"matchOperator"` // contains, equals, starts_with
+	MatchValue    string    `json:"matchValue"`    // compared case-insensitively
+	Category      string    `json:"category"`
+	Subcategory   *string   `json:"subcategory,omitempty"`
+	CreatedAt     time.Time `json:"createdAt"`
+}
+
+// TransactionRuleRequest is the request body for creating or replacing a rule
+type TransactionRuleRequest struct {
+	MatchField    string  `json:"matchField"`
+	MatchOperator string  `json:"matchOperator"`
+	MatchValue    string  `json:"matchValue"`
+	Category      string  `json:"category"`
+	Subcategory   *string `json:"subcategory,omitempty"`
+}
+
+// RecategorizeResponse reports the result of re-applying rules to stored transactions
+type RecategorizeResponse struct {
+	Recategorized int `json:"recategorized"`
+}
+
+// Transaction rule match fields
+const (
+	RuleFieldName         = "name"
+	RuleFieldMerchantName = "merchant_name"
+)
+
+// Transaction rule match operators, most specific first
+const (
+	RuleOperatorEquals     = "equals"
+	RuleOperatorStartsWith = "starts_with"
+	RuleOperatorContains   = "contains"
+)
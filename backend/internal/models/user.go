@@ -42,9 +42,64 @@ type LoginRequest struct {
 	Password string `json:"password"`
 }
 
+// ChangePasswordRequest is the request body for a user changing their own password
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"currentPassword"`
+	NewPassword     string `json:"newPassword"`
+	ConfirmPassword string `json:"confirmPassword"`
+	Code            string `json:"code,omitempty"` // TOTP code, required when 2FA is enabled
+}
+
 type AuthResponse struct {
-	Token string `json:"token"`
-	User  User   `json:"user"`
+	Token        string `json:"token"` // access token
+	RefreshToken string `json:"refreshToken,omitempty"`
+	ExpiresIn    int    `json:"expiresIn,omitempty"` // access token lifetime in seconds
+	User         User   `json:"user"`
+}
+
+// RefreshTokenRequest is the request body for rotating or revoking a refresh token
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refreshToken"`
+}
+
+// Session is an active refresh token, i.e. one signed-in device
+type Session struct {
+	ID        int       `json:"id"`
+	IssuedAt  time.Time `json:"issuedAt"`
+	ExpiresAt time.Time `json:"expiresAt"`
+	UserAgent *string   `json:"userAgent,omitempty"`
+	IPAddress *string   `json:"ipAddress,omitempty"`
+}
+
+// MFAChallengeResponse is returned by login instead of an AuthResponse when
+// the user has two-factor authentication enabled
+type MFAChallengeResponse struct {
+	MFARequired bool   `json:"mfaRequired"`
+	MFAToken    string `json:"mfaToken"` // partial token, only accepted by the MFA validate endpoint
+}
+
+// MFASetupResponse carries a new TOTP secret for the user to add to an authenticator app
+type MFASetupResponse struct {
+	Secret          string   `json:"secret"`
+	ProvisioningURI string   `json:"provisioningUri"` // otpauth:// URI to render as a QR code
+	BackupCodes     []string `json:"backupCodes"`     // shown once; each can replace a TOTP code one time
+}
+
+// MFAVerifyRequest is the request body for confirming 2FA setup
+type MFAVerifyRequest struct {
+	Code string `json:"code"`
+}
+
+// MFADisableRequest is the request body for turning off 2FA
+type MFADisableRequest struct {
+	Password string `json:"password"`
+}
+
+// MFAValidateRequest is the second login step: the partial token from login
+// plus a TOTP code or backup code
+type MFAValidateRequest struct {
+	MFAToken string `json:"mfaToken"`
+	Code     string `json:"code"`
 }
 
 type Claims struct {
@@ -52,6 +107,50 @@ type Claims struct {
 	Email  string `json:"email"`
 }
 
+// APIKey lets an advisor's own tools call the API. Scopes such as
+// "read:assets" or "write:simulations" limit what the key can reach.
+type APIKey struct {
+	ID         int        `json:"id"`
+	Name       string     `json:"name"`
+	KeyPrefix  string     `json:"keyPrefix"` // first characters of the key, to tell keys apart
+	Scopes     []string   `json:"scopes"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+	ExpiresAt  *time.Time `json:"expiresAt,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+}
+
+// CreateAPIKeyRequest is the request body for creating an API key
+type CreateAPIKeyRequest struct {
+	Name          string   `json:"name"`
+	Scopes        []string `json:"scopes"`
+	ExpiresInDays *int     `json:"expiresInDays,omitempty"` // omit for a key that doesn't expire
+}
+
+// CreateAPIKeyResponse includes the full key, which is never shown again
+type CreateAPIKeyResponse struct {
+	APIKey
+	Key string `json:"key"`
+}
+
+// DataExport is a user's request for a copy of all their personal data. The
+// export is built in the background and filed as a document for 7 days.
+type DataExport struct {
+	ID          int        `json:"job_id"`
+	Status      string     `json:"status"`                // pending, completed, failed
+	DocumentID  *int       `json:"document_id,omitempty"` // set once completed, until it expires
+	Error       *string    `json:"error,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+}
+
+// Data export status constants
+const (
+	DataExportStatusPending   = "pending"
+	DataExportStatusCompleted = "completed"
+	DataExportStatusFailed    = "failed"
+)
+
 // AdvisorClient represents the relationship between an advisor and a client
 type AdvisorClient struct {
 	ID                  int        `json:"id" db:"id"`
@@ -162,12 +261,37 @@ type ClientNote struct {
 	UpdatedAt time.Time `json:"updatedAt" db:"updated_at"`
 }
 
+// ClientNoteVersion is a note's content before one of its edits
+type ClientNoteVersion struct {
+	ID        int       `json:"id" db:"id"`
+	NoteID    int       `json:"noteId" db:"note_id"`
+	AdvisorID int       `json:"advisorId" db:"advisor_id"` // who made the edit that replaced this text
+	NoteText  string    `json:"noteText" db:"note_text"`
+	EditedAt  time.Time `json:"editedAt" db:"edited_at"`
+}
+
 // ClientNoteWithClient includes the client user details
 type ClientNoteWithClient struct {
 	ClientNote
 	ClientName string `json:"clientName"`
 }
 
+// ClientNoteSearchResult is a note matched by the advisor notes search
+type ClientNoteSearchResult struct {
+	ClientNoteWithClient
+	Snippet   string   `json:"snippet"`             // first 200 characters with matches wrapped in <mark>
+	Relevance *float64 `json:"relevance,omitempty"` // full-text match score, when full-text search is used
+}
+
+// ClientNoteSearchResponse is the response for the advisor notes search
+type ClientNoteSearchResponse struct {
+	Notes      []ClientNoteSearchResult `json:"notes"`
+	TotalCount int                      `json:"total_count"`
+	Page       int                      `json:"page"`
+	Limit      int                      `json:"limit"`
+	SearchMode string                   `json:"searchMode"` // "fulltext", "like", or "none"
+}
+
 // Note category constants
 const (
 	NoteCategoryGeneral    = "general"
@@ -209,6 +333,43 @@ type ClientGoal struct {
 	CompletedAt   *time.Time `json:"completedAt,omitempty" db:"completed_at"`
 	CreatedAt     time.Time  `json:"createdAt" db:"created_at"`
 	UpdatedAt     time.Time  `json:"updatedAt" db:"updated_at"`
+
+	// Set when the goal is linked to a saved simulation
+	LinkedSimulationID *int `json:"linkedSimulationId,omitempty"`
+	ProjectedReachYear *int `json:"projectedReachYear,omitempty"` // calendar year the median projection reaches TargetAmount
+}
+
+// GoalProjection forecasts when a goal's target is reached from its linked simulation
+type GoalProjection struct {
+	GoalID             int       `json:"goalId"`
+	SimulationID       int       `json:"simulationId"`
+	SimulationName     string    `json:"simulationName,omitempty"`
+	LinkedAt           time.Time `json:"linkedAt"`
+	TargetAmount       float64   `json:"targetAmount"`
+	ProjectedReachYear *int      `json:"projectedReachYear"` // nil if the median projection never reaches the target
+	YearsToReach       *int      `json:"yearsToReach"`       // years after the simulation was run
+	ProbabilityPct     float64   `json:"probabilityPct"`     // estimated chance of reaching the target within the horizon
+}
+
+// GoalTimeline is a goal's projected progress curve, one point per month
+// from today to its target date
+type GoalTimeline struct {
+	GoalID              int                 `json:"goalId"`
+	Source              string              `json:"source"`                        // "linear" or "simulation"
+	SimulationID        *int                `json:"simulationId,omitempty"`        // source "simulation"
+	MonthlyContribution *float64            `json:"monthlyContribution,omitempty"` // source "linear"
+	Points              []GoalTimelinePoint `json:"points"`
+}
+
+// GoalTimelinePoint is the projected value on one date
+type GoalTimelinePoint struct {
+	Date           string  `json:"date"` // YYYY-MM-DD
+	ProjectedValue float64 `json:"projectedValue"`
+}
+
+// LinkGoalSimulationRequest is the request body for linking a goal to a saved simulation
+type LinkGoalSimulationRequest struct {
+	SimulationID int `json:"simulationId"`
 }
 
 // Goal category constants
@@ -223,6 +384,28 @@ const (
 	GoalCategoryOther          = "other"
 )
 
+// ValidGoalCategories lists all valid goal categories
+var ValidGoalCategories = []string{
+	GoalCategoryRetirement,
+	GoalCategorySavings,
+	GoalCategoryDebt,
+	GoalCategoryInvestment,
+	GoalCategoryEducation,
+	GoalCategoryEmergency,
+	GoalCategoryMajorPurchase,
+	GoalCategoryOther,
+}
+
+// IsValidGoalCategory checks if a goal category is valid
+func IsValidGoalCategory(category string) bool {
+	for _, c := range ValidGoalCategories {
+		if c == category {
+			return true
+		}
+	}
+	return false
+}
+
 // Goal status constants
 const (
 	GoalStatusPending    = "pending"
@@ -260,3 +443,25 @@ type UpdateGoalRequest struct {
 	CurrentAmount *float64 `json:"currentAmount,omitempty"`
 	TargetDate    *string  `json:"targetDate,omitempty"`
 }
+
+// BulkGoalUpdate is one goal's changes in a bulk update. A completed goal
+// can only move back to another status when ReopenCompleted is set.
+type BulkGoalUpdate struct {
+	GoalID int `json:"goalId"`
+	UpdateGoalRequest
+	ReopenCompleted bool `json:"reopenCompleted,omitempty"`
+}
+
+// BulkGoalUpdateFailure explains why one goal in a bulk update was skipped
+type BulkGoalUpdateFailure struct {
+	GoalID int    `json:"goalId"`
+	Error  string `json:"error"`
+}
+
+// BulkGoalUpdateResponse summarizes a bulk goal update
+type BulkGoalUpdateResponse struct {
+	Updated      []int                   `json:"updated"`
+	Failed       []BulkGoalUpdateFailure `json:"failed"`
+	UpdatedCount int                     `json:"updatedCount"`
+	FailedCount  int                     `json:"failedCount"`
+}
@@ -0,0 +1,99 @@
+// Package notifications delivers mobile and browser push notifications
+package notifications
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"time"
+)
+
+// Firebase Cloud Messaging legacy HTTP endpoint
+const fcmSendURL = "https://fcm.googleapis.com/fcm/send"
+
+// Push token platforms
+const (
+	PlatformIOS     = "ios"
+	PlatformAndroid = "android"
+	PlatformWeb     = "web"
+)
+
+// ErrInvalidToken is returned when FCM no longer recognizes a token, meaning
+// the app was uninstalled or the token rotated; the token should be dropped
+var ErrInvalidToken = errors.New("push token is no longer valid")
+
+var fcmClient = &http.Client{Timeout: 10 * time.Second}
+
+// fcmMessage is the legacy FCM send request body
+type fcmMessage struct {
+	To           string          `json:"to"`
+	Notification fcmNotification `json:"notification"`
+}
+
+type fcmNotification struct {
+	Title string `json:"title"`
+	Body  string `json:"body"`
+}
+
+// fcmResponse is the legacy FCM send response; each result matches a token
+type fcmResponse struct {
+	Success int `json:"success"`
+	Failure int `json:"failure"`
+	Results []struct {
+		Error string `json:"error"`
+	} `json:"results"`
+}
+
+// SendPushNotification sends a notification to one device through FCM using
+// FIREBASE_SERVER_KEY. Without a key it only logs the notification (for
+// development).
+func SendPushNotification(token, title, body string) error {
+	serverKey := os.Getenv("FIREBASE_SERVER_KEY")
+	if serverKey == "" {
+		log.Printf("Push notification (not sent, FIREBASE_SERVER_KEY unset): %s - %s", title, body)
+		return nil
+	}
+
+	payload, err := json.Marshal(fcmMessage{
+		To:           token,
+		Notification: fcmNotification{Title: title, Body: body},
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("POST", fcmSendURL, bytes.NewBuffer(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "key="+serverKey)
+
+	resp, err := fcmClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("FCM request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("FCM returned status %d", resp.StatusCode)
+	}
+
+	var result fcmResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("failed to decode FCM response: %w", err)
+	}
+	if result.Failure > 0 && len(result.Results) > 0 {
+		switch result.Results[0].Error {
+		case "NotRegistered", "InvalidRegistration", "MismatchSenderId":
+			return ErrInvalidToken
+		default:
+			return fmt.Errorf("FCM delivery failed: %s", result.Results[0].Error)
+		}
+	}
+	return nil
+}
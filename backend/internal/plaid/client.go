@@ -12,10 +12,11 @@ import (
 
 // Client handles Plaid API requests
 type Client struct {
-	clientID   string
-	secret     string
-	baseURL    string
-	httpClient *http.Client
+	clientID    string
+	secret      string
+	baseURL     string
+	redirectURI string
+	httpClient  *http.Client
 }
 
 // NewClient creates a new Plaid client
@@ -38,10 +39,11 @@ func NewClient() *Client {
 	}
 
 	return &Client{
-		clientID:   os.Getenv("PLAID_CLIENT_ID"),
-		secret:     os.Getenv("PLAID_SECRET"),
-		baseURL:    baseURL,
-		httpClient: &http.Client{Timeout: 30 * time.Second},
+		clientID:    os.Getenv("PLAID_CLIENT_ID"),
+		secret:      os.Getenv("PLAID_SECRET"),
+		baseURL:     baseURL,
+		redirectURI: os.Getenv("PLAID_REDIRECT_URI"),
+		httpClient:  &http.Client{Timeout: 30 * time.Second},
 	}
 }
 
@@ -50,6 +52,11 @@ func (c *Client) IsConfigured() bool {
 	return c.clientID != "" && c.secret != ""
 }
 
+// SupportsOAuth returns true if a redirect URI is configured for OAuth institutions
+func (c *Client) SupportsOAuth() bool {
+	return c.redirectURI != ""
+}
+
 func (c *Client) post(endpoint string, body interface{}) ([]byte, error) {
 	// Add credentials to body
 	bodyMap := make(map[string]interface{})
@@ -93,7 +100,7 @@ func (c *Client) post(endpoint string, body interface{}) ([]byte, error) {
 	if resp.StatusCode >= 400 {
 		var plaidErr PlaidError
 		if err := json.Unmarshal(respBody, &plaidErr); err == nil && plaidErr.ErrorMessage != "" {
-			return nil, fmt.Errorf("plaid error: %s - %s", plaidErr.ErrorCode, plaidErr.ErrorMessage)
+			return nil, &plaidErr
 		}
 		return nil, fmt.Errorf("plaid API error: %d - %s", resp.StatusCode, string(respBody))
 	}
@@ -109,6 +116,14 @@ type PlaidError struct {
 	DisplayMsg   string `json:"display_message"`
 }
 
+func (e *PlaidError) Error() string {
+	return fmt.Sprintf("plaid error: %s - %s", e.ErrorCode, e.ErrorMessage)
+}
+
+// ErrorCodeItemLoginRequired means the item's credentials must be refreshed
+// through Link in update mode
+const ErrorCodeItemLoginRequired = "ITEM_LOGIN_REQUIRED"
+
 // CreateLinkToken creates a Link token for initializing Plaid Link
 func (c *Client) CreateLinkToken(userID string) (*LinkTokenResponse, error) {
 	body := map[string]interface{}{
@@ -121,6 +136,31 @@ func (c *Client) CreateLinkToken(userID string) (*LinkTokenResponse, error) {
 		"language":      "en",
 	}
 
+	return c.createLinkToken(body)
+}
+
+// CreateUpdateLinkToken creates a Link token in update mode for an existing
+// item, letting the user re-authenticate without creating a new item
+func (c *Client) CreateUpdateLinkToken(userID, accessToken string) (*LinkTokenResponse, error) {
+	body := map[string]interface{}{
+		"user": map[string]string{
+			"client_user_id": userID,
+		},
+		"client_name":   "FinViz",
+		"access_token":  accessToken, // update mode: no products, the item keeps its own
+		"country_codes": []string{"US"},
+		"language":      "en",
+	}
+
+	return c.createLinkToken(body)
+}
+
+func (c *Client) createLinkToken(body map[string]interface{}) (*LinkTokenResponse, error) {
+	// OAuth institutions redirect back to this URI mid-flow
+	if c.redirectURI != "" {
+		body["redirect_uri"] = c.redirectURI
+	}
+
 	resp, err := c.post("/link/token/create", body)
 	if err != nil {
 		return nil, err
@@ -364,3 +404,62 @@ type PFCat struct {
 	Primary   string `json:"primary"`
 	Detailed  string `json:"detailed"`
 }
+
+// GetInvestmentHoldings retrieves security-level holdings for an item's investment accounts
+func (c *Client) GetInvestmentHoldings(accessToken string) (*InvestmentsResponse, error) {
+	body := map[string]interface{}{
+		"access_token": accessToken,
+	}
+
+	resp, err := c.post("/investments/holdings/get", body)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw struct {
+		Accounts   []Account  `json:"accounts"`
+		Holdings   []Holding  `json:"holdings"`
+		Securities []Security `json:"securities"`
+	}
+	if err := json.Unmarshal(resp, &raw); err != nil {
+		return nil, err
+	}
+
+	result := InvestmentsResponse{
+		Accounts:   raw.Accounts,
+		Holdings:   raw.Holdings,
+		Securities: make(map[string]Security, len(raw.Securities)),
+	}
+	for _, sec := range raw.Securities {
+		result.Securities[sec.SecurityID] = sec
+	}
+
+	return &result, nil
+}
+
+// InvestmentsResponse from Plaid, with securities keyed by SecurityID
+type InvestmentsResponse struct {
+	Accounts   []Account
+	Holdings   []Holding
+	Securities map[string]Security
+}
+
+// Holding is a position in one security within an investment account
+type Holding struct {
+	AccountID        string   `json:"account_id"`
+	SecurityID       string   `json:"security_id"`
+	Quantity         float64  `json:"quantity"`
+	InstitutionPrice float64  `json:"institution_price"`
+	InstitutionValue float64  `json:"institution_value"`
+	CostBasis        *float64 `json:"cost_basis"`
+	ISOCurrencyCode  string   `json:"iso_currency_code"`
+}
+
+// Security describes a held security
+type Security struct {
+	SecurityID   string   `json:"security_id"`
+	Name         string   `json:"name"`
+	TickerSymbol *string  `json:"ticker_symbol"`
+	Type         string   `json:"type"` // equity, etf, mutual fund, fixed income, cash, cryptocurrency, ...
+	ClosePrice   *float64 `json:"close_price"`
+}
@@ -0,0 +1,213 @@
+package plaid
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Webhook verification errors
+var (
+	ErrMissingVerificationHeader = errors.New("missing Plaid-Verification header")
+	ErrInvalidWebhookJWT         = errors.New("invalid webhook JWT")
+	ErrUnsupportedWebhookAlg     = errors.New("unsupported webhook JWT algorithm")
+	ErrWebhookSignature          = errors.New("webhook signature verification failed")
+	ErrWebhookExpired            = errors.New("webhook JWT issued outside the allowed window")
+	ErrWebhookBodyMismatch       = errors.New("webhook body hash does not match")
+)
+
+const (
+	// webhookMaxAge is how far the JWT's iat may be from the current time
+	webhookMaxAge = 5 * time.Minute
+	// webhookKeyTTL is how long fetched verification keys are cached
+	webhookKeyTTL = 24 * time.Hour
+)
+
+// WebhookVerificationKey is a JWK returned by /webhook_verification_key/get
+type WebhookVerificationKey struct {
+	Alg       string `json:"alg"`
+	Crv       string `json:"crv"`
+	Kid       string `json:"kid"`
+	Kty       string `json:"kty"`
+	Use       string `json:"use"`
+	X         string `json:"x"`
+	Y         string `json:"y"`
+	CreatedAt int64  `json:"created_at"`
+	ExpiredAt *int64 `json:"expired_at"`
+}
+
+type cachedKey struct {
+	key       *WebhookVerificationKey
+	fetchedAt time.Time
+}
+
+var (
+	webhookKeyCache   = make(map[string]cachedKey)
+	webhookKeyCacheMu sync.Mutex
+)
+
+// GetWebhookVerificationKey fetches the public key for a webhook JWT key ID
+func (c *Client) GetWebhookVerificationKey(kid string) (*WebhookVerificationKey, error) {
+	body := map[string]interface{}{
+		"key_id": kid,
+	}
+
+	resp, err := c.post("/webhook_verification_key/get", body)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Key WebhookVerificationKey `json:"key"`
+	}
+	if err := json.Unmarshal(resp, &result); err != nil {
+		return nil, err
+	}
+
+	return &result.Key, nil
+}
+
+// cachedWebhookKey returns the verification key for kid, using the in-memory cache when fresh
+func (c *Client) cachedWebhookKey(kid string) (*WebhookVerificationKey, error) {
+	webhookKeyCacheMu.Lock()
+	entry, ok := webhookKeyCache[kid]
+	webhookKeyCacheMu.Unlock()
+	if ok && time.Since(entry.fetchedAt) < webhookKeyTTL {
+		return entry.key, nil
+	}
+
+	key, err := c.GetWebhookVerificationKey(kid)
+	if err != nil {
+		return nil, err
+	}
+
+	webhookKeyCacheMu.Lock()
+	webhookKeyCache[kid] = cachedKey{key: key, fetchedAt: time.Now()}
+	webhookKeyCacheMu.Unlock()
+
+	return key, nil
+}
+
+// VerifyWebhook validates the Plaid-Verification JWT against the raw request body.
+// It checks the signature with the key named by the JWT's kid, that iat is within
+// five minutes of now, and that request_body_sha256 matches the body.
+func (c *Client) VerifyWebhook(body []byte, verificationHeader string) error {
+	if verificationHeader == "" {
+		return ErrMissingVerificationHeader
+	}
+
+	parts := strings.Split(verificationHeader, ".")
+	if len(parts) != 3 {
+		return ErrInvalidWebhookJWT
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeJWTSegment(parts[0], &header); err != nil || header.Kid == "" {
+		return ErrInvalidWebhookJWT
+	}
+	if header.Alg != "ES256" && header.Alg != "EdDSA" {
+		return ErrUnsupportedWebhookAlg
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return ErrInvalidWebhookJWT
+	}
+
+	key, err := c.cachedWebhookKey(header.Kid)
+	if err != nil {
+		return fmt.Errorf("fetching verification key: %w", err)
+	}
+	if key.ExpiredAt != nil && *key.ExpiredAt > 0 && time.Now().Unix() > *key.ExpiredAt {
+		return ErrWebhookSignature
+	}
+
+	signingInput := []byte(parts[0] + "." + parts[1])
+	if err := verifyJWTSignature(header.Alg, key, signingInput, signature); err != nil {
+		return err
+	}
+
+	var claims struct {
+		Iat               int64  `json:"iat"`
+		RequestBodySHA256 string `json:"request_body_sha256"`
+	}
+	if err := decodeJWTSegment(parts[1], &claims); err != nil {
+		return ErrInvalidWebhookJWT
+	}
+
+	issuedAt := time.Unix(claims.Iat, 0)
+	if age := time.Since(issuedAt); age > webhookMaxAge || age < -webhookMaxAge {
+		return ErrWebhookExpired
+	}
+
+	bodyHash := sha256.Sum256(body)
+	if subtle.ConstantTimeCompare([]byte(hex.EncodeToString(bodyHash[:])), []byte(claims.RequestBodySHA256)) != 1 {
+		return ErrWebhookBodyMismatch
+	}
+
+	return nil
+}
+
+// verifyJWTSignature checks an ES256 (P-256) or EdDSA (Ed25519) JWT signature against a JWK
+func verifyJWTSignature(alg string, key *WebhookVerificationKey, signingInput, signature []byte) error {
+	x, err := base64.RawURLEncoding.DecodeString(key.X)
+	if err != nil {
+		return ErrWebhookSignature
+	}
+
+	switch alg {
+	case "EdDSA":
+		if key.Kty != "OKP" || key.Crv != "Ed25519" || len(x) != ed25519.PublicKeySize {
+			return ErrWebhookSignature
+		}
+		if !ed25519.Verify(ed25519.PublicKey(x), signingInput, signature) {
+			return ErrWebhookSignature
+		}
+	case "ES256":
+		if key.Kty != "EC" || key.Crv != "P-256" || len(signature) != 64 {
+			return ErrWebhookSignature
+		}
+		y, err := base64.RawURLEncoding.DecodeString(key.Y)
+		if err != nil {
+			return ErrWebhookSignature
+		}
+		pub := &ecdsa.PublicKey{
+			Curve: elliptic.P256(),
+			X:     new(big.Int).SetBytes(x),
+			Y:     new(big.Int).SetBytes(y),
+		}
+		digest := sha256.Sum256(signingInput)
+		r := new(big.Int).SetBytes(signature[:32])
+		s := new(big.Int).SetBytes(signature[32:])
+		if !ecdsa.Verify(pub, digest[:], r, s) {
+			return ErrWebhookSignature
+		}
+	default:
+		return ErrUnsupportedWebhookAlg
+	}
+
+	return nil
+}
+
+// decodeJWTSegment base64url-decodes and unmarshals a JWT header or payload
+func decodeJWTSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
@@ -0,0 +1,161 @@
+// Package prices keeps market prices for assets whose value follows a quote,
+// so their current_value doesn't go stale between manual edits.
+package prices
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/finviz/backend/internal/db"
+	"github.com/finviz/backend/internal/logger"
+)
+
+// CryptoAssetType is the seeded asset type whose assets are priced by ticker
+const CryptoAssetType = "Crypto"
+
+// CryptoPollInterval is how often crypto prices are refreshed
+const CryptoPollInterval = 5 * time.Minute
+
+// Price source recorded in crypto_prices
+const sourceCoinGecko = "coingecko"
+
+const defaultCoinGeckoURL = "https://api.coingecko.com/api/v3"
+
+var httpClient = &http.Client{Timeout: 15 * time.Second}
+
+// StartCryptoPricePolling refreshes crypto prices now and then every
+// CryptoPollInterval in the background. COINGECKO_API_URL overrides the API
+// base URL and COINGECKO_API_KEY sends a demo API key.
+func StartCryptoPricePolling() {
+	go func() {
+		refresh := func() {
+			if err := RefreshCryptoPrices(); err != nil {
+				logger.Default().Errorf("Error refreshing crypto prices: %v", err)
+			}
+		}
+
+		refresh()
+		ticker := time.NewTicker(CryptoPollInterval)
+		defer ticker.Stop()
+		for range ticker.C {
+			refresh()
+		}
+	}()
+	logger.Default().Infof("Background job started: crypto price polling (interval %s)", CryptoPollInterval)
+}
+
+// RefreshCryptoPrices fetches USD prices for every ticker held in a crypto
+// asset, stores them in crypto_prices and revalues those assets at price ×
+// quantity so totals elsewhere (snapshots, projections) stay current.
+// Tickers the API doesn't know keep their last value.
+func RefreshCryptoPrices() error {
+	symbols, err := heldCryptoSymbols()
+	if err != nil {
+		return err
+	}
+	if len(symbols) == 0 {
+		return nil
+	}
+
+	quotes, err := fetchCoinGeckoPrices(symbols)
+	if err != nil {
+		return err
+	}
+
+	for symbol, price := range quotes {
+		if _, err := db.DB.Exec(`
+			INSERT INTO crypto_prices (symbol, price_usd, source, fetched_at)
+			VALUES (?, ?, ?, NOW())
+			ON DUPLICATE KEY UPDATE price_usd = VALUES(price_usd), source = VALUES(source), fetched_at = VALUES(fetched_at)
+		`, symbol, price, sourceCoinGecko); err != nil {
+			return err
+		}
+
+		if _, err := db.DB.Exec(`
+			UPDATE assets a JOIN asset_types t ON t.id = a.type_id
+			SET a.current_value = ROUND(? * a.quantity, 2)
+			WHERE t.name = ? AND UPPER(a.ticker_symbol) = ? AND a.deleted_at IS NULL
+		`, price, CryptoAssetType, symbol); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// heldCryptoSymbols returns the distinct upper-case tickers of crypto assets
+func heldCryptoSymbols() ([]string, error) {
+	rows, err := db.DB.Query(`
+		SELECT DISTINCT UPPER(a.ticker_symbol)
+		FROM assets a JOIN asset_types t ON t.id = a.type_id
+		WHERE t.name = ? AND a.ticker_symbol IS NOT NULL AND a.ticker_symbol != '' AND a.deleted_at IS NULL
+	`, CryptoAssetType)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var symbols []string
+	for rows.Next() {
+		var s string
+		if err := rows.Scan(&s); err != nil {
+			return nil, err
+		}
+		symbols = append(symbols, s)
+	}
+	return symbols, rows.Err()
+}
+
+// fetchCoinGeckoPrices looks up USD prices by ticker with /simple/price,
+// returning them keyed by upper-case ticker
+func fetchCoinGeckoPrices(symbols []string) (map[string]float64, error) {
+	base := os.Getenv("COINGECKO_API_URL")
+	if base == "" {
+		base = defaultCoinGeckoURL
+	}
+
+	lower := make([]string, len(symbols))
+	for i, s := range symbols {
+		lower[i] = strings.ToLower(s)
+	}
+	query := url.Values{
+		"symbols":       {strings.Join(lower, ",")},
+		"vs_currencies": {"usd"},
+	}
+
+	req, err := http.NewRequest(http.MethodGet, strings.TrimRight(base, "/")+"/simple/price?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if key := os.Getenv("COINGECKO_API_KEY"); key != "" {
+		req.Header.Set("x-cg-demo-api-key", key)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("coingecko returned status %d", resp.StatusCode)
+	}
+
+	// e.g. {"btc": {"usd": 67187.34}, "eth": {"usd": 3310.2}}
+	var body map[string]map[string]float64
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("decoding coingecko response: %w", err)
+	}
+
+	quotes := make(map[string]float64, len(body))
+	for symbol, prices := range body {
+		if usd, ok := prices["usd"]; ok && usd > 0 {
+			quotes[strings.ToUpper(symbol)] = usd
+		}
+	}
+	return quotes, nil
+}
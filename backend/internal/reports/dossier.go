@@ -0,0 +1,395 @@
+package reports
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/finviz/backend/internal/models"
+	"github.com/johnfercher/maroto/v2"
+	"github.com/johnfercher/maroto/v2/pkg/components/col"
+	"github.com/johnfercher/maroto/v2/pkg/components/line"
+	"github.com/johnfercher/maroto/v2/pkg/components/page"
+	"github.com/johnfercher/maroto/v2/pkg/components/row"
+	"github.com/johnfercher/maroto/v2/pkg/components/text"
+	"github.com/johnfercher/maroto/v2/pkg/config"
+	"github.com/johnfercher/maroto/v2/pkg/consts/align"
+	"github.com/johnfercher/maroto/v2/pkg/consts/fontstyle"
+	"github.com/johnfercher/maroto/v2/pkg/core"
+	"github.com/johnfercher/maroto/v2/pkg/props"
+)
+
+// Client dossier sections, rendered in this order
+const (
+	DossierSectionNotes      = "notes"
+	DossierSectionGoals      = "goals"
+	DossierSectionDocuments  = "documents"
+	DossierSectionSimulation = "simulation"
+	DossierSectionCompliance = "compliance"
+)
+
+// DossierSections lists every dossier section in chapter order
+var DossierSections = []string{
+	DossierSectionNotes,
+	DossierSectionGoals,
+	DossierSectionDocuments,
+	DossierSectionSimulation,
+	DossierSectionCompliance,
+}
+
+// DossierSignatureRequest is a signature request with the document it covers
+type DossierSignatureRequest struct {
+	models.SignatureRequest
+	DocumentName string
+}
+
+// DossierAuditEvent is an audit log entry for the compliance chapter
+type DossierAuditEvent struct {
+	Action    string
+	IPAddress string
+	Details   string
+	CreatedAt time.Time
+}
+
+// DossierData contains everything needed for a client dossier
+type DossierData struct {
+	ClientName  string
+	ClientEmail string
+	ClientSince *time.Time
+	AdvisorName string
+	GeneratedAt time.Time
+	Sections    []string
+
+	Notes             []models.ClientNote // pinned first, newest first
+	Goals             []models.ClientGoal
+	Documents         []models.Document
+	SimulationName    string
+	SimulationRunAt   time.Time
+	Simulation        *models.MonteCarloResponse // latest saved simulation, if any
+	SignatureRequests []DossierSignatureRequest
+	AuditEvents       []DossierAuditEvent
+}
+
+// GenerateClientDossier creates a PDF client file: a cover page followed by
+// one chapter per requested section
+func GenerateClientDossier(data DossierData) ([]byte, error) {
+	cfg := config.NewBuilder().
+		WithPageNumber().
+		WithLeftMargin(15).
+		WithTopMargin(15).
+		WithRightMargin(15).
+		Build()
+
+	mrt := maroto.New(cfg)
+	m := maroto.NewMetricsDecorator(mrt)
+
+	addDossierCover(m, data)
+
+	for _, section := range data.Sections {
+		switch section {
+		case DossierSectionNotes:
+			addDossierNotes(m, data.Notes)
+		case DossierSectionGoals:
+			addDossierGoals(m, data.Goals)
+		case DossierSectionDocuments:
+			addDossierDocuments(m, data.Documents)
+		case DossierSectionSimulation:
+			addDossierSimulation(m, data)
+		case DossierSectionCompliance:
+			addDossierCompliance(m, data)
+		}
+	}
+
+	addDisclaimer(m)
+
+	doc, err := m.Generate()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate PDF: %w", err)
+	}
+
+	return doc.GetBytes(), nil
+}
+
+func addDossierCover(m core.Maroto, data DossierData) {
+	addHeader(m, ReportData{
+		Title:       "Client Dossier",
+		ClientName:  data.ClientName,
+		AdvisorName: data.AdvisorName,
+		GeneratedAt: data.GeneratedAt,
+	})
+
+	m.AddRow(12,
+		col.New(12).Add(
+			text.New("Client Information", props.Text{
+				Size:  16,
+				Style: fontstyle.Bold,
+				Color: &props.Color{Red: 0, Green: 82, Blue: 147},
+			}),
+		),
+	)
+
+	info := [][2]string{
+		{"Name", data.ClientName},
+		{"Email", data.ClientEmail},
+	}
+	if data.ClientSince != nil {
+		info = append(info, [2]string{"Client since", data.ClientSince.Format("January 2, 2006")})
+	}
+	if data.AdvisorName != "" {
+		info = append(info, [2]string{"Advisor", data.AdvisorName})
+	}
+
+	chapters := make([]string, 0, len(data.Sections))
+	for _, section := range data.Sections {
+		chapters = append(chapters, dossierChapterTitle(section))
+	}
+	info = append(info, [2]string{"Contents", strings.Join(chapters, ", ")})
+
+	for _, item := range info {
+		m.AddRow(7,
+			col.New(3).Add(text.New(item[0], props.Text{Size: 10, Style: fontstyle.Bold})),
+			col.New(9).Add(text.New(item[1], props.Text{Size: 10})),
+		)
+	}
+}
+
+// dossierChapterTitle is the heading used for a dossier section
+func dossierChapterTitle(section string) string {
+	switch section {
+	case DossierSectionNotes:
+		return "Advisor Notes"
+	case DossierSectionGoals:
+		return "Goals"
+	case DossierSectionDocuments:
+		return "Document Manifest"
+	case DossierSectionSimulation:
+		return "Latest Simulation"
+	case DossierSectionCompliance:
+		return "Compliance Record"
+	}
+	return section
+}
+
+// addChapter starts a section on a new page with its heading
+func addChapter(m core.Maroto, section string) {
+	m.AddPages(page.New().Add(
+		row.New(12).Add(
+			col.New(12).Add(
+				text.New(dossierChapterTitle(section), props.Text{
+					Size:  16,
+					Style: fontstyle.Bold,
+					Color: &props.Color{Red: 0, Green: 82, Blue: 147},
+				}),
+			),
+		),
+	))
+}
+
+// addEmptyChapterNote fills a chapter that has nothing to list
+func addEmptyChapterNote(m core.Maroto, message string) {
+	m.AddRow(8,
+		col.New(12).Add(text.New(message, props.Text{
+			Size:  10,
+			Color: &props.Color{Red: 100, Green: 100, Blue: 100},
+		})),
+	)
+}
+
+func addDossierNotes(m core.Maroto, notes []models.ClientNote) {
+	addChapter(m, DossierSectionNotes)
+
+	if len(notes) == 0 {
+		addEmptyChapterNote(m, "No notes recorded for this client.")
+		return
+	}
+
+	for _, note := range notes {
+		heading := fmt.Sprintf("%s  ·  %s", note.CreatedAt.Format("Jan 2, 2006"), strings.ReplaceAll(note.Category, "_", " "))
+		if note.IsPinned {
+			heading += "  ·  pinned"
+		}
+
+		m.AddRow(6,
+			col.New(12).Add(text.New(heading, props.Text{Size: 9, Style: fontstyle.Bold})),
+		)
+		m.AddAutoRow(
+			col.New(12).Add(text.New(note.Note, props.Text{Size: 9})),
+		)
+		m.AddRow(4, line.NewCol(12))
+	}
+}
+
+func addDossierGoals(m core.Maroto, goals []models.ClientGoal) {
+	addChapter(m, DossierSectionGoals)
+
+	if len(goals) == 0 {
+		addEmptyChapterNote(m, "No goals recorded for this client.")
+		return
+	}
+
+	m.AddRow(8,
+		col.New(4).Add(text.New("Goal", props.Text{Size: 10, Style: fontstyle.Bold})),
+		col.New(2).Add(text.New("Status", props.Text{Size: 10, Style: fontstyle.Bold})),
+		col.New(2).Add(text.New("Target Date", props.Text{Size: 10, Style: fontstyle.Bold, Align: align.Center})),
+		col.New(4).Add(text.New("Progress", props.Text{Size: 10, Style: fontstyle.Bold, Align: align.Right})),
+	)
+
+	for _, goal := range goals {
+		targetDate := "-"
+		if goal.TargetDate != nil {
+			targetDate = *goal.TargetDate
+		}
+
+		progress := "-"
+		if goal.TargetAmount != nil && *goal.TargetAmount > 0 {
+			current := 0.0
+			if goal.CurrentAmount != nil {
+				current = *goal.CurrentAmount
+			}
+			progress = fmt.Sprintf("%s of %s (%.0f%%)",
+				formatCurrency(current), formatCurrency(*goal.TargetAmount), current / *goal.TargetAmount * 100)
+		}
+
+		m.AddRow(6,
+			col.New(4).Add(text.New(goal.Title, props.Text{Size: 9})),
+			col.New(2).Add(text.New(strings.ReplaceAll(goal.Status, "_", " "), props.Text{Size: 9})),
+			col.New(2).Add(text.New(targetDate, props.Text{Size: 9, Align: align.Center})),
+			col.New(4).Add(text.New(progress, props.Text{Size: 9, Align: align.Right})),
+		)
+	}
+}
+
+func addDossierDocuments(m core.Maroto, docs []models.Document) {
+	addChapter(m, DossierSectionDocuments)
+
+	if len(docs) == 0 {
+		addEmptyChapterNote(m, "No documents in this client's vault.")
+		return
+	}
+
+	m.AddRow(8,
+		col.New(5).Add(text.New("Document", props.Text{Size: 10, Style: fontstyle.Bold})),
+		col.New(3).Add(text.New("Category", props.Text{Size: 10, Style: fontstyle.Bold})),
+		col.New(2).Add(text.New("Size", props.Text{Size: 10, Style: fontstyle.Bold, Align: align.Right})),
+		col.New(2).Add(text.New("Uploaded", props.Text{Size: 10, Style: fontstyle.Bold, Align: align.Right})),
+	)
+
+	for _, doc := range docs {
+		name := doc.Name
+		if doc.Year != nil {
+			name = fmt.Sprintf("%s (%d)", name, *doc.Year)
+		}
+
+		m.AddRow(6,
+			col.New(5).Add(text.New(name, props.Text{Size: 9})),
+			col.New(3).Add(text.New(strings.ReplaceAll(doc.Category, "_", " "), props.Text{Size: 9})),
+			col.New(2).Add(text.New(formatFileSize(doc.Size), props.Text{Size: 9, Align: align.Right})),
+			col.New(2).Add(text.New(doc.CreatedAt.Format("Jan 2, 2006"), props.Text{Size: 9, Align: align.Right})),
+		)
+	}
+}
+
+func addDossierSimulation(m core.Maroto, data DossierData) {
+	addChapter(m, DossierSectionSimulation)
+
+	if data.Simulation == nil {
+		addEmptyChapterNote(m, "No saved simulations for this client.")
+		return
+	}
+
+	name := data.SimulationName
+	if name == "" {
+		name = "Monte Carlo simulation"
+	}
+	m.AddRow(8,
+		col.New(12).Add(text.New(fmt.Sprintf("%s, run %s", name, data.SimulationRunAt.Format("January 2, 2006")), props.Text{
+			Size:  10,
+			Style: fontstyle.Italic,
+		})),
+	)
+
+	summary := data.Simulation.Summary
+	rows := [][2]string{
+		{"Success rate", fmt.Sprintf("%.1f%%", summary.SuccessRate)},
+		{"Starting net worth", formatCurrency(summary.StartingNetWorth)},
+		{"Horizon", fmt.Sprintf("%d years, %d scenarios", summary.Years, summary.Simulations)},
+		{"Final value (10th percentile)", formatCurrency(summary.FinalP10)},
+		{"Final value (median)", formatCurrency(summary.FinalP50)},
+		{"Final value (90th percentile)", formatCurrency(summary.FinalP90)},
+	}
+	for _, item := range rows {
+		m.AddRow(7,
+			col.New(6).Add(text.New(item[0], props.Text{Size: 10})),
+			col.New(6).Add(text.New(item[1], props.Text{Size: 10, Style: fontstyle.Bold, Align: align.Right})),
+		)
+	}
+	m.AddRow(5)
+
+	if len(data.Simulation.Insights) > 0 {
+		addInsightsSection(m, data.Simulation.Insights, defaultHeadingColor)
+	}
+}
+
+func addDossierCompliance(m core.Maroto, data DossierData) {
+	addChapter(m, DossierSectionCompliance)
+
+	m.AddRow(10,
+		col.New(12).Add(text.New("Signature Requests", props.Text{Size: 12, Style: fontstyle.Bold})),
+	)
+	if len(data.SignatureRequests) == 0 {
+		addEmptyChapterNote(m, "No signature requests.")
+	} else {
+		m.AddRow(8,
+			col.New(5).Add(text.New("Document", props.Text{Size: 10, Style: fontstyle.Bold})),
+			col.New(2).Add(text.New("Status", props.Text{Size: 10, Style: fontstyle.Bold})),
+			col.New(2).Add(text.New("Requested", props.Text{Size: 10, Style: fontstyle.Bold, Align: align.Right})),
+			col.New(3).Add(text.New("Signed", props.Text{Size: 10, Style: fontstyle.Bold, Align: align.Right})),
+		)
+		for _, req := range data.SignatureRequests {
+			signed := "-"
+			if req.SignedAt != nil {
+				signed = req.SignedAt.Format("Jan 2, 2006")
+			}
+			m.AddRow(6,
+				col.New(5).Add(text.New(req.DocumentName, props.Text{Size: 9})),
+				col.New(2).Add(text.New(req.Status, props.Text{Size: 9})),
+				col.New(2).Add(text.New(req.CreatedAt.Format("Jan 2, 2006"), props.Text{Size: 9, Align: align.Right})),
+				col.New(3).Add(text.New(signed, props.Text{Size: 9, Align: align.Right})),
+			)
+		}
+	}
+	m.AddRow(5)
+
+	m.AddRow(10,
+		col.New(12).Add(text.New("Account Activity", props.Text{Size: 12, Style: fontstyle.Bold})),
+	)
+	if len(data.AuditEvents) == 0 {
+		addEmptyChapterNote(m, "No audit events recorded.")
+		return
+	}
+	m.AddRow(8,
+		col.New(3).Add(text.New("Date", props.Text{Size: 10, Style: fontstyle.Bold})),
+		col.New(3).Add(text.New("Event", props.Text{Size: 10, Style: fontstyle.Bold})),
+		col.New(2).Add(text.New("IP Address", props.Text{Size: 10, Style: fontstyle.Bold})),
+		col.New(4).Add(text.New("Details", props.Text{Size: 10, Style: fontstyle.Bold})),
+	)
+	for _, event := range data.AuditEvents {
+		m.AddRow(6,
+			col.New(3).Add(text.New(event.CreatedAt.Format("Jan 2, 2006 15:04"), props.Text{Size: 9})),
+			col.New(3).Add(text.New(strings.ReplaceAll(event.Action, "_", " "), props.Text{Size: 9})),
+			col.New(2).Add(text.New(event.IPAddress, props.Text{Size: 9})),
+			col.New(4).Add(text.New(event.Details, props.Text{Size: 9})),
+		)
+	}
+}
+
+// formatFileSize renders a byte count for the document manifest
+func formatFileSize(size int64) string {
+	switch {
+	case size >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(size)/(1<<20))
+	case size >= 1<<10:
+		return fmt.Sprintf("%.0f KB", float64(size)/(1<<10))
+	}
+	return fmt.Sprintf("%d B", size)
+}
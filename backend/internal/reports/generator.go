@@ -2,15 +2,19 @@ package reports
 
 import (
 	"fmt"
+	"net/http"
 	"time"
 
+	"github.com/finviz/backend/internal/charts"
 	"github.com/finviz/backend/internal/models"
 	"github.com/johnfercher/maroto/v2"
 	"github.com/johnfercher/maroto/v2/pkg/components/col"
+	"github.com/johnfercher/maroto/v2/pkg/components/image"
 	"github.com/johnfercher/maroto/v2/pkg/components/line"
 	"github.com/johnfercher/maroto/v2/pkg/components/text"
 	"github.com/johnfercher/maroto/v2/pkg/config"
 	"github.com/johnfercher/maroto/v2/pkg/consts/align"
+	"github.com/johnfercher/maroto/v2/pkg/consts/extension"
 	"github.com/johnfercher/maroto/v2/pkg/consts/fontstyle"
 	"github.com/johnfercher/maroto/v2/pkg/core"
 	"github.com/johnfercher/maroto/v2/pkg/props"
@@ -18,16 +22,76 @@ import (
 
 // ReportData contains all information needed for a financial plan report
 type ReportData struct {
-	ClientName   string
-	AdvisorName  string
-	GeneratedAt  time.Time
-	Assets       []models.Asset
-	Debts        []models.Debt
-	Simulation   *models.MonteCarloResponse
-	Params       *models.SimulationParams
-	TotalAssets  float64
-	TotalDebts   float64
-	NetWorth     float64
+	Title       string // defaults to "Financial Plan Report"
+	ClientName  string
+	AdvisorName string
+	GeneratedAt time.Time
+	Assets      []models.Asset
+	Debts       []models.Debt
+	Simulation  *models.MonteCarloResponse
+	Params      *models.SimulationParams
+	TotalAssets float64
+	TotalDebts  float64
+	NetWorth    float64
+	Branding    *Branding         // white-label header and colors; nil uses FinViz defaults
+	Scenarios   []ScenarioSummary // alternatives compared against Simulation, the base scenario
+}
+
+// ScenarioSummary is a labeled alternative plan for the scenario comparison
+type ScenarioSummary struct {
+	Label  string // e.g. "Retire 2 years later"
+	Result models.MonteCarloResponse
+}
+
+// Branding replaces the FinViz look with an advisor's firm identity
+type Branding struct {
+	FirmName          string
+	LogoBytes         []byte // PNG or JPEG
+	PrimaryColorHex   string // section headings, e.g. "#005293"
+	SecondaryColorHex string // header byline and rule
+}
+
+// defaultHeadingColor is FinViz blue (#005293)
+var defaultHeadingColor = &props.Color{Red: 0, Green: 82, Blue: 147}
+
+// headingColor returns the color for the report title and section headings
+func (b *Branding) headingColor() *props.Color {
+	if b == nil {
+		return defaultHeadingColor
+	}
+	if c, err := parseHexColor(b.PrimaryColorHex); err == nil {
+		return c
+	}
+	return defaultHeadingColor
+}
+
+// accentColor returns the color for the header byline and rule, or nil for the default
+func (b *Branding) accentColor() *props.Color {
+	if b == nil {
+		return nil
+	}
+	if c, err := parseHexColor(b.SecondaryColorHex); err == nil {
+		return c
+	}
+	return nil
+}
+
+// parseHexColor parses a "#RRGGBB" color
+func parseHexColor(hex string) (*props.Color, error) {
+	var r, g, b int
+	if len(hex) != 7 {
+		return nil, fmt.Errorf("invalid color %q", hex)
+	}
+	if _, err := fmt.Sscanf(hex, "#%02x%02x%02x", &r, &g, &b); err != nil {
+		return nil, fmt.Errorf("invalid color %q", hex)
+	}
+	return &props.Color{Red: r, Green: g, Blue: b}, nil
+}
+
+// ValidHexColor reports whether a color is in "#RRGGBB" form
+func ValidHexColor(hex string) bool {
+	_, err := parseHexColor(hex)
+	return err == nil
 }
 
 // GenerateFinancialPlanReport creates a PDF report for a financial plan
@@ -56,24 +120,31 @@ func GenerateFinancialPlanReport(data ReportData) ([]byte, error) {
 		addProjectionSection(m, data)
 	}
 
+	// Scenario Comparison
+	if len(data.Scenarios) > 0 {
+		addScenarioComparisonSection(m, data)
+	}
+
+	heading := data.Branding.headingColor()
+
 	// Asset Details
 	if len(data.Assets) > 0 {
-		addAssetTable(m, data.Assets)
+		addAssetTable(m, data.Assets, heading)
 	}
 
 	// Debt Details
 	if len(data.Debts) > 0 {
-		addDebtTable(m, data.Debts)
+		addDebtTable(m, data.Debts, heading)
 	}
 
 	// Milestones
 	if data.Simulation != nil && len(data.Simulation.Milestones) > 0 {
-		addMilestonesSection(m, data.Simulation.Milestones)
+		addMilestonesSection(m, data.Simulation.Milestones, heading)
 	}
 
 	// Insights/Recommendations
 	if data.Simulation != nil && len(data.Simulation.Insights) > 0 {
-		addInsightsSection(m, data.Simulation.Insights)
+		addInsightsSection(m, data.Simulation.Insights, heading)
 	}
 
 	// Disclaimer
@@ -88,13 +159,24 @@ func GenerateFinancialPlanReport(data ReportData) ([]byte, error) {
 }
 
 func addHeader(m core.Maroto, data ReportData) {
+	title := data.Title
+	if title == "" {
+		title = "Financial Plan Report"
+	}
+	heading := data.Branding.headingColor()
+	accent := data.Branding.accentColor()
+
+	if b := data.Branding; b != nil {
+		addBrandingRow(m, b, heading)
+	}
+
 	m.AddRow(20,
 		col.New(12).Add(
-			text.New("Financial Plan Report", props.Text{
+			text.New(title, props.Text{
 				Size:  24,
 				Style: fontstyle.Bold,
 				Align: align.Center,
-				Color: &props.Color{Red: 0, Green: 82, Blue: 147},
+				Color: heading,
 			}),
 		),
 	)
@@ -118,22 +200,66 @@ func addHeader(m core.Maroto, data ReportData) {
 		m.AddRow(6,
 			col.New(12).Add(
 				text.New(fmt.Sprintf("Prepared by: %s", data.AdvisorName), props.Text{
-					Size: 10,
+					Size:  10,
+					Color: accent,
 				}),
 			),
 		)
 	}
 
-	m.AddRow(5, line.NewCol(12))
+	m.AddRow(5, line.NewCol(12, props.Line{Color: accent}))
+}
+
+// addBrandingRow puts the firm's logo at the top left with the firm name beside it
+func addBrandingRow(m core.Maroto, b *Branding, heading *props.Color) {
+	logoExt, hasLogo := logoExtension(b.LogoBytes)
+
+	nameCol := col.New(12)
+	if hasLogo {
+		nameCol = col.New(9)
+	}
+	if b.FirmName != "" {
+		nameCol.Add(text.New(b.FirmName, props.Text{
+			Size:  14,
+			Style: fontstyle.Bold,
+			Align: align.Right,
+			Color: heading,
+		}))
+	}
+
+	if !hasLogo {
+		m.AddRow(10, nameCol)
+		return
+	}
+	m.AddRow(18,
+		col.New(3).Add(image.NewFromBytes(b.LogoBytes, logoExt, props.Rect{
+			Percent: 100,
+		})),
+		nameCol,
+	)
+}
+
+// logoExtension identifies a PNG or JPEG logo from its content
+func logoExtension(logo []byte) (extension.Type, bool) {
+	switch http.DetectContentType(logo) {
+	case "image/png":
+		return extension.Png, true
+	case "image/jpeg":
+		return extension.Jpeg, true
+	default:
+		return "", false
+	}
 }
 
 func addExecutiveSummary(m core.Maroto, data ReportData) {
+	heading := data.Branding.headingColor()
+
 	m.AddRow(12,
 		col.New(12).Add(
 			text.New("Executive Summary", props.Text{
 				Size:  16,
 				Style: fontstyle.Bold,
-				Color: &props.Color{Red: 0, Green: 82, Blue: 147},
+				Color: heading,
 			}),
 		),
 	)
@@ -169,12 +295,14 @@ func addExecutiveSummary(m core.Maroto, data ReportData) {
 }
 
 func addNetWorthSection(m core.Maroto, data ReportData) {
+	heading := data.Branding.headingColor()
+
 	m.AddRow(12,
 		col.New(12).Add(
 			text.New("Net Worth Summary", props.Text{
 				Size:  16,
 				Style: fontstyle.Bold,
-				Color: &props.Color{Red: 0, Green: 82, Blue: 147},
+				Color: heading,
 			}),
 		),
 	)
@@ -242,33 +370,31 @@ func addNetWorthSection(m core.Maroto, data ReportData) {
 }
 
 func addProjectionSection(m core.Maroto, data ReportData) {
+	heading := data.Branding.headingColor()
+
+	title := "Retirement Projection"
+	if len(data.Scenarios) > 0 {
+		title += ": Base Scenario"
+	}
+
 	m.AddRow(12,
 		col.New(12).Add(
-			text.New("Retirement Projection", props.Text{
+			text.New(title, props.Text{
 				Size:  16,
 				Style: fontstyle.Bold,
-				Color: &props.Color{Red: 0, Green: 82, Blue: 147},
+				Color: heading,
 			}),
 		),
 	)
 
 	summary := data.Simulation.Summary
 
-	// Success rate highlight
-	successColor := &props.Color{Red: 0, Green: 150, Blue: 100}
-	if summary.SuccessRate < 80 {
-		successColor = &props.Color{Red: 200, Green: 150, Blue: 0}
-	}
-	if summary.SuccessRate < 50 {
-		successColor = &props.Color{Red: 200, Green: 50, Blue: 50}
-	}
-
 	m.AddRow(8,
 		col.New(12).Add(
 			text.New(fmt.Sprintf("Success Rate: %.1f%%", summary.SuccessRate), props.Text{
 				Size:  14,
 				Style: fontstyle.Bold,
-				Color: successColor,
+				Color: successRateColor(summary.SuccessRate),
 			}),
 		),
 	)
@@ -283,15 +409,19 @@ func addProjectionSection(m core.Maroto, data ReportData) {
 		),
 	)
 
-	// Projection outcomes table
+	// Projection outcomes table - nominal future dollars alongside inflation-adjusted
+	// (today's dollars) values so the real purchasing power is clear
 	m.AddRow(10,
-		col.New(4).Add(
+		col.New(3).Add(
 			text.New("Scenario", props.Text{Size: 10, Style: fontstyle.Bold}),
 		),
-		col.New(4).Add(
-			text.New("Final Net Worth", props.Text{Size: 10, Style: fontstyle.Bold, Align: align.Right}),
+		col.New(3).Add(
+			text.New("Final Net Worth (Nominal)", props.Text{Size: 10, Style: fontstyle.Bold, Align: align.Right}),
 		),
-		col.New(4).Add(
+		col.New(3).Add(
+			text.New("In Today's Dollars", props.Text{Size: 10, Style: fontstyle.Bold, Align: align.Right}),
+		),
+		col.New(3).Add(
 			text.New("Description", props.Text{Size: 10, Style: fontstyle.Bold}),
 		),
 	)
@@ -299,27 +429,59 @@ func addProjectionSection(m core.Maroto, data ReportData) {
 	scenarios := []struct {
 		name  string
 		value float64
+		real  float64
 		desc  string
 	}{
-		{"Conservative (P10)", summary.FinalP10, "Worst 10% of outcomes"},
-		{"Median (P50)", summary.FinalP50, "Typical outcome"},
-		{"Optimistic (P90)", summary.FinalP90, "Best 10% of outcomes"},
+		{"Conservative (P10)", summary.FinalP10, summary.RealFinalP10, "Worst 10% of outcomes"},
+		{"Median (P50)", summary.FinalP50, summary.RealFinalP50, "Typical outcome"},
+		{"Optimistic (P90)", summary.FinalP90, summary.RealFinalP90, "Best 10% of outcomes"},
 	}
 
 	for _, s := range scenarios {
 		m.AddRow(8,
-			col.New(4).Add(
+			col.New(3).Add(
 				text.New(s.name, props.Text{Size: 9}),
 			),
-			col.New(4).Add(
+			col.New(3).Add(
 				text.New(formatCurrency(s.value), props.Text{Size: 9, Align: align.Right}),
 			),
-			col.New(4).Add(
+			col.New(3).Add(
+				text.New(formatCurrency(s.real), props.Text{Size: 9, Align: align.Right}),
+			),
+			col.New(3).Add(
 				text.New(s.desc, props.Text{Size: 9, Color: &props.Color{Red: 100, Green: 100, Blue: 100}}),
 			),
 		)
 	}
 
+	inflationNote := "Nominal values are in future dollars; today's dollars adjust for assumed inflation."
+	if data.Params != nil && data.Params.InflationRate > 0 {
+		inflationNote = fmt.Sprintf("Nominal values are in future dollars; today's dollars adjust for %.1f%% annual inflation over %d years.",
+			data.Params.InflationRate*100, summary.Years)
+	}
+	m.AddRow(6,
+		col.New(12).Add(
+			text.New(inflationNote, props.Text{
+				Size:  8,
+				Style: fontstyle.Italic,
+				Color: &props.Color{Red: 100, Green: 100, Blue: 100},
+			}),
+		),
+	)
+
+	// Projection chart (skipped if there is no yearly data or rendering fails)
+	if len(data.Simulation.Projections) > 0 {
+		if chartBytes, err := charts.GenerateProjectionChart(data.Simulation.Projections); err == nil {
+			m.AddRow(3)
+			m.AddRow(85,
+				image.NewFromBytesCol(12, chartBytes, extension.Png, props.Rect{
+					Center:  true,
+					Percent: 100,
+				}),
+			)
+		}
+	}
+
 	// Parameters used
 	if data.Params != nil {
 		m.AddRow(10,
@@ -377,13 +539,94 @@ func addProjectionSection(m core.Maroto, data ReportData) {
 	m.AddRow(5)
 }
 
-func addAssetTable(m core.Maroto, assets []models.Asset) {
+// successRateColor highlights a success rate green, yellow (below 80%) or red (below 50%)
+func successRateColor(rate float64) *props.Color {
+	switch {
+	case rate < 50:
+		return &props.Color{Red: 200, Green: 50, Blue: 50}
+	case rate < 80:
+		return &props.Color{Red: 200, Green: 150, Blue: 0}
+	default:
+		return &props.Color{Red: 0, Green: 150, Blue: 100}
+	}
+}
+
+// addScenarioComparisonSection puts the base plan and each alternative side
+// by side: one column per scenario, one row per final-year percentile
+func addScenarioComparisonSection(m core.Maroto, data ReportData) {
+	heading := data.Branding.headingColor()
+
+	m.AddRow(12,
+		col.New(12).Add(
+			text.New("Scenario Comparison", props.Text{
+				Size:  16,
+				Style: fontstyle.Bold,
+				Color: heading,
+			}),
+		),
+	)
+
+	scenarios := data.Scenarios
+	if data.Simulation != nil {
+		scenarios = append([]ScenarioSummary{{Label: "Base Scenario", Result: *data.Simulation}}, scenarios...)
+	}
+
+	// Split the 12-column grid: equal scenario columns, the rest for row labels
+	colSize := 9 / len(scenarios)
+	labelSize := 12 - colSize*len(scenarios)
+
+	header := []core.Col{col.New(labelSize)}
+	for _, sc := range scenarios {
+		header = append(header, col.New(colSize).Add(
+			text.New(sc.Label, props.Text{Size: 9, Style: fontstyle.Bold, Align: align.Right}),
+		))
+	}
+	m.AddRow(10, header...)
+
+	successRow := []core.Col{col.New(labelSize).Add(text.New("Success Rate", props.Text{Size: 9, Style: fontstyle.Bold}))}
+	for _, sc := range scenarios {
+		rate := sc.Result.Summary.SuccessRate
+		successRow = append(successRow, col.New(colSize).Add(
+			text.New(fmt.Sprintf("%.1f%%", rate), props.Text{
+				Size:  9,
+				Style: fontstyle.Bold,
+				Align: align.Right,
+				Color: successRateColor(rate),
+			}),
+		))
+	}
+	m.AddRow(7, successRow...)
+
+	percentiles := []struct {
+		label string
+		value func(models.ProjectionSummary) float64
+	}{
+		{"Final P10", func(s models.ProjectionSummary) float64 { return s.FinalP10 }},
+		{"Final P25", func(s models.ProjectionSummary) float64 { return s.FinalP25 }},
+		{"Final P50", func(s models.ProjectionSummary) float64 { return s.FinalP50 }},
+		{"Final P75", func(s models.ProjectionSummary) float64 { return s.FinalP75 }},
+		{"Final P90", func(s models.ProjectionSummary) float64 { return s.FinalP90 }},
+	}
+	for _, p := range percentiles {
+		row := []core.Col{col.New(labelSize).Add(text.New(p.label, props.Text{Size: 9}))}
+		for _, sc := range scenarios {
+			row = append(row, col.New(colSize).Add(
+				text.New(formatCurrency(p.value(sc.Result.Summary)), props.Text{Size: 9, Align: align.Right}),
+			))
+		}
+		m.AddRow(6, row...)
+	}
+
+	m.AddRow(5)
+}
+
+func addAssetTable(m core.Maroto, assets []models.Asset, heading *props.Color) {
 	m.AddRow(12,
 		col.New(12).Add(
 			text.New("Asset Details", props.Text{
 				Size:  16,
 				Style: fontstyle.Bold,
-				Color: &props.Color{Red: 0, Green: 82, Blue: 147},
+				Color: heading,
 			}),
 		),
 	)
@@ -410,13 +653,13 @@ func addAssetTable(m core.Maroto, assets []models.Asset) {
 	m.AddRow(5)
 }
 
-func addDebtTable(m core.Maroto, debts []models.Debt) {
+func addDebtTable(m core.Maroto, debts []models.Debt, heading *props.Color) {
 	m.AddRow(12,
 		col.New(12).Add(
 			text.New("Debt Details", props.Text{
 				Size:  16,
 				Style: fontstyle.Bold,
-				Color: &props.Color{Red: 0, Green: 82, Blue: 147},
+				Color: heading,
 			}),
 		),
 	)
@@ -450,13 +693,13 @@ func addDebtTable(m core.Maroto, debts []models.Debt) {
 	m.AddRow(5)
 }
 
-func addMilestonesSection(m core.Maroto, milestones []models.Milestone) {
+func addMilestonesSection(m core.Maroto, milestones []models.Milestone, heading *props.Color) {
 	m.AddRow(12,
 		col.New(12).Add(
 			text.New("Financial Milestones", props.Text{
 				Size:  16,
 				Style: fontstyle.Bold,
-				Color: &props.Color{Red: 0, Green: 82, Blue: 147},
+				Color: heading,
 			}),
 		),
 	)
@@ -478,13 +721,13 @@ func addMilestonesSection(m core.Maroto, milestones []models.Milestone) {
 	m.AddRow(5)
 }
 
-func addInsightsSection(m core.Maroto, insights []models.Insight) {
+func addInsightsSection(m core.Maroto, insights []models.Insight, heading *props.Color) {
 	m.AddRow(12,
 		col.New(12).Add(
 			text.New("Recommendations", props.Text{
 				Size:  16,
 				Style: fontstyle.Bold,
-				Color: &props.Color{Red: 0, Green: 82, Blue: 147},
+				Color: heading,
 			}),
 		),
 	)
@@ -0,0 +1,145 @@
+package reports
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/finviz/backend/internal/models"
+	"github.com/tealeg/xlsx/v3"
+)
+
+// Spreadsheet number formats
+const (
+	xlsxCurrencyFormat = `"$"#,##0`
+	xlsxPercentFormat  = `0.0%`
+)
+
+// GenerateProjectionXLSX creates a workbook of the simulation projections:
+// a Projections sheet with one row per year, an Inputs sheet with the
+// simulation parameters, and a Milestones sheet
+func GenerateProjectionXLSX(data ReportData) ([]byte, error) {
+	if data.Simulation == nil {
+		return nil, fmt.Errorf("no simulation to export")
+	}
+
+	file := xlsx.NewFile()
+
+	if err := addProjectionsSheet(file, data.Simulation.Projections); err != nil {
+		return nil, err
+	}
+	if err := addInputsSheet(file, data); err != nil {
+		return nil, err
+	}
+	if err := addMilestonesSheet(file, data.Simulation.Milestones); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := file.Write(&buf); err != nil {
+		return nil, fmt.Errorf("failed to write workbook: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func addProjectionsSheet(file *xlsx.File, projections []models.YearProjection) error {
+	sheet, err := file.AddSheet("Projections")
+	if err != nil {
+		return err
+	}
+
+	addSheetHeader(sheet, "Year", "Age", "P10", "P25", "P50", "P75", "P90")
+	for _, p := range projections {
+		row := sheet.AddRow()
+		row.AddCell().SetInt(p.Year)
+		row.AddCell().SetInt(p.Age)
+		for _, v := range []float64{p.P10, p.P25, p.P50, p.P75, p.P90} {
+			row.AddCell().SetFloatWithFormat(v, xlsxCurrencyFormat)
+		}
+	}
+	return nil
+}
+
+func addInputsSheet(file *xlsx.File, data ReportData) error {
+	sheet, err := file.AddSheet("Inputs")
+	if err != nil {
+		return err
+	}
+
+	addSheetHeader(sheet, "Parameter", "Value")
+	addSheetLabelRow(sheet, "Client", data.ClientName)
+	if data.AdvisorName != "" {
+		addSheetLabelRow(sheet, "Advisor", data.AdvisorName)
+	}
+	addSheetLabelRow(sheet, "Generated", data.GeneratedAt.Format("January 2, 2006"))
+
+	summary := data.Simulation.Summary
+	addSheetValueRow(sheet, "Starting Net Worth", summary.StartingNetWorth, xlsxCurrencyFormat)
+	addSheetValueRow(sheet, "Success Rate", summary.SuccessRate/100, xlsxPercentFormat)
+	addSheetValueRow(sheet, "Simulations", float64(summary.Simulations), "0")
+
+	p := data.Params
+	if p == nil {
+		return nil
+	}
+	addSheetValueRow(sheet, "Current Age", float64(p.CurrentAge), "0")
+	addSheetValueRow(sheet, "Retirement Age", float64(p.RetirementAge), "0")
+	addSheetValueRow(sheet, "Time Horizon (Years)", float64(p.TimeHorizonYears), "0")
+	addSheetValueRow(sheet, "Monthly Contribution", p.MonthlyContribution, xlsxCurrencyFormat)
+	addSheetValueRow(sheet, "Contribution Growth", p.ContributionGrowth, xlsxPercentFormat)
+	addSheetValueRow(sheet, "Expected Return", p.ExpectedReturn, xlsxPercentFormat)
+	addSheetValueRow(sheet, "Volatility", p.Volatility, xlsxPercentFormat)
+	addSheetValueRow(sheet, "Inflation Rate", p.InflationRate, xlsxPercentFormat)
+	addSheetValueRow(sheet, "Monthly Retirement Spending", p.RetirementSpending, xlsxCurrencyFormat)
+	addSheetLabelRow(sheet, "Withdrawal Strategy", p.WithdrawalStrategy)
+	addSheetValueRow(sheet, "Retirement Tax Rate", p.RetirementTaxRate, xlsxPercentFormat)
+	addSheetValueRow(sheet, "Monthly Social Security", p.SocialSecurityAmount, xlsxCurrencyFormat)
+	addSheetValueRow(sheet, "Social Security Claim Age", float64(p.SocialSecurityClaimAge), "0")
+	addSheetValueRow(sheet, "Monthly Pension", p.PensionIncome, xlsxCurrencyFormat)
+	return nil
+}
+
+func addMilestonesSheet(file *xlsx.File, milestones []models.Milestone) error {
+	sheet, err := file.AddSheet("Milestones")
+	if err != nil {
+		return err
+	}
+
+	addSheetHeader(sheet, "Milestone", "Target", "Median Year", "Probability")
+	for _, ms := range milestones {
+		row := sheet.AddRow()
+		row.AddCell().SetString(ms.Description)
+		row.AddCell().SetFloatWithFormat(ms.TargetAmount, xlsxCurrencyFormat)
+		if ms.MedianYear > 0 {
+			row.AddCell().SetInt(ms.MedianYear)
+		} else {
+			row.AddCell().SetString("Not reached")
+		}
+		row.AddCell().SetFloatWithFormat(ms.ProbabilityPct/100, xlsxPercentFormat)
+	}
+	return nil
+}
+
+func addSheetHeader(sheet *xlsx.Sheet, headers ...string) {
+	style := xlsx.NewStyle()
+	style.Font.Bold = true
+	style.ApplyFont = true
+
+	row := sheet.AddRow()
+	for _, h := range headers {
+		cell := row.AddCell()
+		cell.SetString(h)
+		cell.SetStyle(style)
+	}
+}
+
+func addSheetLabelRow(sheet *xlsx.Sheet, label, value string) {
+	row := sheet.AddRow()
+	row.AddCell().SetString(label)
+	row.AddCell().SetString(value)
+}
+
+func addSheetValueRow(sheet *xlsx.Sheet, label string, value float64, format string) {
+	row := sheet.AddRow()
+	row.AddCell().SetString(label)
+	row.AddCell().SetFloatWithFormat(value, format)
+}
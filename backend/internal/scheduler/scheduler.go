@@ -0,0 +1,57 @@
+package scheduler
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// JobRunner runs a job on a standard five-field cron schedule
+// (minute hour day-of-month month day-of-week), e.g. "0 6 1 * *" for 6am on
+// the first of every month. Times are in the server's local time zone.
+type JobRunner struct {
+	name     string
+	schedule cron.Schedule
+	job      func()
+}
+
+// NewJobRunner parses the cron expression for a job
+func NewJobRunner(name, spec string, job func()) (*JobRunner, error) {
+	schedule, err := cron.ParseStandard(spec)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cron expression %q: %w", spec, err)
+	}
+	return &JobRunner{name: name, schedule: schedule, job: job}, nil
+}
+
+// Run waits for each scheduled time and runs the job. It blocks forever, so
+// start it in its own goroutine. A run that overlaps the next scheduled time
+// delays it rather than running concurrently.
+func (j *JobRunner) Run() {
+	for {
+		next := j.schedule.Next(time.Now())
+		log.Printf("Scheduled job %s: next run at %s", j.name, next.Format(time.RFC3339))
+		time.Sleep(time.Until(next))
+
+		start := time.Now()
+		j.job()
+		log.Printf("Scheduled job %s finished in %s", j.name, time.Since(start).Round(time.Second))
+	}
+}
+
+// Retry calls fn until it succeeds or has been tried attempts times, waiting
+// delay between tries. It returns the last error.
+func Retry(attempts int, delay time.Duration, fn func() error) error {
+	var err error
+	for i := 0; i < attempts; i++ {
+		if err = fn(); err == nil {
+			return nil
+		}
+		if i < attempts-1 {
+			time.Sleep(delay)
+		}
+	}
+	return err
+}
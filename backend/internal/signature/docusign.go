@@ -0,0 +1,124 @@
+package signature
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ErrNotConfigured is returned when the DocuSign credentials are missing
+var ErrNotConfigured = errors.New("docusign is not configured")
+
+// DocuSignProvider sends envelopes through the DocuSign eSignature REST API
+type DocuSignProvider struct {
+	baseURL     string
+	accountID   string
+	accessToken string
+	httpClient  *http.Client
+}
+
+// NewDocuSignProvider creates a DocuSign provider from environment variables
+func NewDocuSignProvider() *DocuSignProvider {
+	baseURL := os.Getenv("DOCUSIGN_BASE_URL")
+	if baseURL == "" {
+		baseURL = "https://demo.docusign.net/restapi"
+	}
+
+	return &DocuSignProvider{
+		baseURL:     strings.TrimRight(baseURL, "/"),
+		accountID:   os.Getenv("DOCUSIGN_ACCOUNT_ID"),
+		accessToken: os.Getenv("DOCUSIGN_ACCESS_TOKEN"),
+		httpClient:  &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Name returns the DocuSign provider identifier
+func (p *DocuSignProvider) Name() string {
+	return ProviderDocuSign
+}
+
+// IsConfigured returns true if the account ID and access token are set
+func (p *DocuSignProvider) IsConfigured() bool {
+	return p.accountID != "" && p.accessToken != ""
+}
+
+// Send creates and sends an envelope with a single signer, returning the envelope ID
+func (p *DocuSignProvider) Send(req Request) (string, error) {
+	if !p.IsConfigured() {
+		return "", ErrNotConfigured
+	}
+
+	ext := strings.TrimPrefix(filepath.Ext(req.DocumentName), ".")
+	if ext == "" {
+		ext = "pdf"
+	}
+
+	envelope := map[string]interface{}{
+		"emailSubject": req.Subject,
+		"emailBlurb":   req.Message,
+		"status":       "sent",
+		"documents": []map[string]interface{}{
+			{
+				"documentId":     "1",
+				"name":           req.DocumentName,
+				"fileExtension":  ext,
+				"documentBase64": base64.StdEncoding.EncodeToString(req.DocumentData),
+			},
+		},
+		"recipients": map[string]interface{}{
+			"signers": []map[string]interface{}{
+				{
+					"recipientId":  "1",
+					"routingOrder": "1",
+					"name":         req.SignerName,
+					"email":        req.SignerEmail,
+				},
+			},
+		},
+	}
+
+	body, err := json.Marshal(envelope)
+	if err != nil {
+		return "", err
+	}
+
+	url := fmt.Sprintf("%s/v2.1/accounts/%s/envelopes", p.baseURL, p.accountID)
+	httpReq, err := http.NewRequest("POST", url, bytes.NewBuffer(body))
+	if err != nil {
+		return "", err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Authorization", "Bearer "+p.accessToken)
+
+	resp, err := p.httpClient.Do(httpReq)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	if resp.StatusCode >= 400 {
+		return "", fmt.Errorf("docusign API error: %d - %s", resp.StatusCode, string(respBody))
+	}
+
+	var result struct {
+		EnvelopeID string `json:"envelopeId"`
+	}
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return "", err
+	}
+
+	return result.EnvelopeID, nil
+}
@@ -0,0 +1,60 @@
+package signature
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"os"
+)
+
+// Provider names stored in document_signature_requests.external_provider
+const (
+	ProviderStub      = "stub"
+	ProviderDocuSign  = "docusign"
+	ProviderHelloSign = "hellosign"
+)
+
+// Request contains everything a provider needs to send a document for signature
+type Request struct {
+	DocumentName string
+	DocumentData []byte
+	MimeType     string
+	SignerName   string
+	SignerEmail  string
+	Subject      string
+	Message      string
+}
+
+// Provider sends documents to an e-signature service
+type Provider interface {
+	// Name returns the provider identifier stored with the request
+	Name() string
+	// Send creates the signature request and returns the provider's ID for it
+	Send(req Request) (string, error)
+}
+
+// NewProvider returns the provider selected by SIGNATURE_PROVIDER (stub by default)
+func NewProvider() Provider {
+	switch os.Getenv("SIGNATURE_PROVIDER") {
+	case ProviderDocuSign:
+		return NewDocuSignProvider()
+	default:
+		return &StubProvider{}
+	}
+}
+
+// StubProvider marks requests as sent without contacting an external service
+type StubProvider struct{}
+
+// Name returns the stub provider identifier
+func (p *StubProvider) Name() string {
+	return ProviderStub
+}
+
+// Send returns a random external ID; signing is simulated via the API
+func (p *StubProvider) Send(req Request) (string, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "stub-" + hex.EncodeToString(b), nil
+}
@@ -0,0 +1,51 @@
+package simulation
+
+import "github.com/finviz/backend/internal/simulation/historical"
+
+// historicalReturns replays actual market history in place of random draws.
+// Simulation i starts in the i-th year of the record and wraps around to the
+// start when the horizon runs past 2023. A nil *historicalReturns falls back
+// to normalRandom, so the simulation loop can call it unconditionally.
+type historicalReturns struct {
+	years       []historical.Year
+	equityShare float64 // stock share of the blended portfolio return
+}
+
+func newHistoricalReturns(equityShare float64) *historicalReturns {
+	return &historicalReturns{years: historical.Returns(), equityShare: equityShare}
+}
+
+// sequences is the number of distinct starting years
+func (h *historicalReturns) sequences() int {
+	return len(h.years)
+}
+
+func (h *historicalReturns) at(sim, year int) historical.Year {
+	return h.years[(sim+year)%len(h.years)]
+}
+
+// portfolio returns the blended stock/bond return for a simulation year
+func (h *historicalReturns) portfolio(sim, year int, mean, volatility float64) float64 {
+	if h == nil {
+		return normalRandom(mean, volatility)
+	}
+	y := h.at(sim, year)
+	return h.equityShare*y.Stocks + (1-h.equityShare)*y.Bonds
+}
+
+// bonds returns the bond return for a simulation year
+func (h *historicalReturns) bonds(sim, year int, mean, volatility float64) float64 {
+	if h == nil {
+		return normalRandom(mean, volatility)
+	}
+	return h.at(sim, year).Bonds
+}
+
+// equities returns the stock return for a simulation year, or the already
+// drawn random return when not backtesting
+func (h *historicalReturns) equities(sim, year int, drawn float64) float64 {
+	if h == nil {
+		return drawn
+	}
+	return h.at(sim, year).Stocks
+}
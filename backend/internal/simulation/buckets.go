@@ -0,0 +1,107 @@
+package simulation
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/finviz/backend/internal/models"
+)
+
+// Bucket strategy assumptions
+const (
+	cashBucketReturn = 0.02 // money market / high-yield savings, no volatility
+
+	// Refill triggers and targets, in years of the current year's withdrawal.
+	// Cash covers years 1-3 of spending and bonds years 4-10.
+	cashRefillTriggerYears = 1
+	cashTargetYears        = 3
+	bondRefillTriggerYears = 5
+	bondTargetYears        = 7
+)
+
+// bucketPortfolio holds the three pools of the bucket strategy
+type bucketPortfolio struct {
+	cash     float64
+	bonds    float64
+	equities float64
+}
+
+// newBucketPortfolio splits a portfolio value by the strategy's percentages
+func newBucketPortfolio(value float64, strategy *models.BucketStrategy) bucketPortfolio {
+	if value < 0 {
+		value = 0
+	}
+	return bucketPortfolio{
+		cash:     value * strategy.Bucket1Pct,
+		bonds:    value * strategy.Bucket2Pct,
+		equities: value * strategy.Bucket3Pct,
+	}
+}
+
+func (b *bucketPortfolio) total() float64 {
+	return b.cash + b.bonds + b.equities
+}
+
+// refill tops up cash from bonds when cash drops below one year of expenses,
+// then bonds from equities when bonds drop below five years
+func (b *bucketPortfolio) refill(annualExpenses float64) {
+	if annualExpenses <= 0 {
+		return
+	}
+	if b.cash < annualExpenses*cashRefillTriggerYears {
+		move := math.Min(annualExpenses*cashTargetYears-b.cash, b.bonds)
+		b.cash += move
+		b.bonds -= move
+	}
+	if b.bonds < annualExpenses*bondRefillTriggerYears {
+		move := math.Min(annualExpenses*bondTargetYears-b.bonds, b.equities)
+		b.bonds += move
+		b.equities -= move
+	}
+}
+
+// withdraw takes an amount from cash first, then bonds, then equities
+func (b *bucketPortfolio) withdraw(amount float64) {
+	for _, pool := range []*float64{&b.cash, &b.bonds, &b.equities} {
+		if amount <= 0 {
+			return
+		}
+		take := math.Min(amount, *pool)
+		*pool -= take
+		amount -= take
+	}
+}
+
+// grow applies one year of returns to each pool and returns the blended portfolio return
+func (b *bucketPortfolio) grow(bondReturn, equityReturn float64) float64 {
+	before := b.total()
+	b.cash *= 1 + cashBucketReturn
+	b.bonds = math.Max(0, b.bonds*(1+bondReturn))
+	b.equities = math.Max(0, b.equities*(1+equityReturn))
+	if before <= 0 {
+		return 0
+	}
+	return b.total()/before - 1
+}
+
+// bucketComparisonInsight reports how the bucket strategy compares with a single portfolio
+func bucketComparisonInsight(bucketRate, singleRate float64) models.Insight {
+	gap := bucketRate - singleRate
+	insightType := "info"
+	summary := "about the same as"
+	if gap >= 2 {
+		insightType = "success"
+		summary = fmt.Sprintf("%.0f points higher than", gap)
+	} else if gap <= -2 {
+		insightType = "warning"
+		summary = fmt.Sprintf("%.0f points lower than", -gap)
+	}
+	return models.Insight{
+		Type:  insightType,
+		Code:  "bucket_strategy_comparison",
+		Title: "Bucket Strategy vs. Single Portfolio",
+		Message: fmt.Sprintf("The bucket strategy gives a %.0f%% success rate, %s a single unified portfolio (%.0f%%). "+
+			"Its main benefit is behavioral: near-term spending sits in cash, so market drops don't force selling equities.",
+			bucketRate, summary, singleRate),
+	}
+}
@@ -0,0 +1,104 @@
+package simulation
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/finviz/backend/internal/models"
+)
+
+// Charitable giving rules
+const (
+	qcdAnnualLimit = 105000.0 // 2024 QCD cap per person
+	qcdMinAge      = 70.5     // QCDs allowed from age 70½
+)
+
+// charitableGiftActive reports whether gifts are scheduled in a 1-based simulation year
+func charitableGiftActive(cg *models.CharitableGiving, simYear int) bool {
+	return simYear >= cg.StartYear && (cg.EndYear == 0 || simYear <= cg.EndYear)
+}
+
+// charitableGiftYears counts the scheduled gift years within the horizon
+func charitableGiftYears(cg *models.CharitableGiving, horizonYears int) int {
+	last := cg.EndYear
+	if last == 0 || last > horizonYears {
+		last = horizonYears
+	}
+	if last < cg.StartYear {
+		return 0
+	}
+	return last - cg.StartYear + 1
+}
+
+// charitableGiftCost returns what a year's gift costs the portfolio, including
+// the tax on withdrawing it. Cash gifts are grossed up at the retirement tax
+// rate (no deduction, assuming the standard deduction). QCDs after 70½ are
+// paid straight from the IRA: the part that satisfies the RMD on rmdBalance,
+// up to the annual cap, is never taxed. A DAF is funded once in StartYear with
+// every scheduled year's gifts, and that contribution is deducted that year.
+func charitableGiftCost(cg *models.CharitableGiving, simYear, age, horizonYears int, rmdBalance, taxRate float64) float64 {
+	if cg == nil || cg.AnnualGiftAmount <= 0 || !charitableGiftActive(cg, simYear) {
+		return 0
+	}
+
+	grossUp := func(amount float64) float64 {
+		if taxRate <= 0 || taxRate >= 1 {
+			return amount
+		}
+		return amount / (1 - taxRate)
+	}
+
+	gift := cg.AnnualGiftAmount
+	switch cg.GiftType {
+	case models.GiftTypeQCD:
+		if float64(age) < qcdMinAge {
+			return grossUp(gift)
+		}
+		excluded := math.Min(math.Min(gift, RequiredMinimumDistribution(rmdBalance, age)), qcdAnnualLimit)
+		return excluded + grossUp(gift-excluded)
+	case models.GiftTypeDAF:
+		if simYear != cg.StartYear {
+			return 0 // grants come out of the already-funded DAF
+		}
+		contribution := gift * float64(charitableGiftYears(cg, horizonYears))
+		return grossUp(contribution) - contribution*taxRate
+	default:
+		return grossUp(gift)
+	}
+}
+
+// charitableInsight suggests QCDs over cash gifts once the client is old enough
+func charitableInsight(params *models.SimulationParams) *models.Insight {
+	cg := params.CharitableGiving
+	if cg == nil || cg.AnnualGiftAmount <= 0 || params.RetirementTaxRate <= 0 {
+		return nil
+	}
+
+	// Only relevant if gifting continues past age 70½
+	lastYear := cg.StartYear + charitableGiftYears(cg, params.TimeHorizonYears) - 1
+	if float64(params.CurrentAge+lastYear) < qcdMinAge {
+		return nil
+	}
+
+	savings := math.Min(cg.AnnualGiftAmount, qcdAnnualLimit) * params.RetirementTaxRate
+	switch cg.GiftType {
+	case models.GiftTypeCash:
+		return &models.Insight{
+			Type:  "opportunity",
+			Code:  "charitable_qcd",
+			Title: "Give Through Qualified Charitable Distributions",
+			Message: fmt.Sprintf("Gifting via QCD rather than cash saves approximately %s in taxes annually based on your marginal rate (%.0f%%). "+
+				"After age 70½, QCDs go directly from your IRA to charity and count toward your required minimum distribution.",
+				formatCurrency(savings), params.RetirementTaxRate*100),
+		}
+	case models.GiftTypeQCD:
+		return &models.Insight{
+			Type:  "success",
+			Code:  "charitable_qcd",
+			Title: "Qualified Charitable Distributions",
+			Message: fmt.Sprintf("Giving through QCDs after age 70½ keeps up to %s a year of required distributions out of taxable income, "+
+				"saving approximately %s in taxes annually compared with cash gifts.", formatCurrency(qcdAnnualLimit), formatCurrency(savings)),
+		}
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package simulation
+
+import "github.com/finviz/backend/internal/models"
+
+// Social Security COLA assumptions spanning a plausible policy/economic range
+var colaScenarios = []float64{0, 0.015, 0.025, 0.04}
+
+// RunCOLASensitivity re-runs a plan at each COLA assumption and reports how
+// much the success rate moves across them
+func RunCOLASensitivity(assets []models.Asset, debts []models.Debt, params *models.SimulationParams) models.COLASensitivityResponse {
+	params.ApplyDefaults()
+
+	scenarios := make([]models.COLAScenario, 0, len(colaScenarios))
+	var baseline models.MonteCarloResponse
+	minRate, maxRate := 100.0, 0.0
+
+	for _, cola := range colaScenarios {
+		scenarioParams := *params
+		scenarioCOLA := cola
+		scenarioParams.CostOfLivingAdjustment = &scenarioCOLA
+
+		result := RunMonteCarloWithParams(assets, debts, &scenarioParams)
+		if cola == models.DefaultCostOfLivingAdjustment {
+			baseline = result
+		}
+
+		rate := result.Summary.SuccessRate
+		if rate < minRate {
+			minRate = rate
+		}
+		if rate > maxRate {
+			maxRate = rate
+		}
+
+		scenarios = append(scenarios, models.COLAScenario{
+			CostOfLivingAdjustment: cola,
+			SuccessRate:            rate,
+			FinalP10:               result.Summary.FinalP10,
+			FinalP50:               result.Summary.FinalP50,
+			FinalP90:               result.Summary.FinalP90,
+		})
+	}
+
+	spread := maxRate - minRate
+	return models.COLASensitivityResponse{
+		Scenarios:         scenarios,
+		SuccessRateSpread: spread,
+		Insights: generateInsights(params, baseline.Summary.StartingNetWorth, baseline.Summary.SuccessRate,
+			baseline.Summary.AccumulationShortfallPct, baseline.Projections, &spread),
+	}
+}
@@ -0,0 +1,115 @@
+package historical
+
+// Year is one calendar year of US market total returns, as decimals
+type Year struct {
+	Year   int
+	Stocks float64 // S&P 500 including dividends
+	Bonds  float64 // 10-year US Treasury
+}
+
+// annualReturns covers 1928-2023 (Damodaran, NYU Stern historical returns dataset)
+var annualReturns = []Year{
+	{1928, 0.4381, 0.0084},
+	{1929, -0.0830, 0.0420},
+	{1930, -0.2512, 0.0454},
+	{1931, -0.4384, -0.0256},
+	{1932, -0.0864, 0.0879},
+	{1933, 0.4998, 0.0186},
+	{1934, -0.0119, 0.0796},
+	{1935, 0.4674, 0.0447},
+	{1936, 0.3194, 0.0502},
+	{1937, -0.3534, 0.0138},
+	{1938, 0.2928, 0.0421},
+	{1939, -0.0110, 0.0441},
+	{1940, -0.1067, 0.0540},
+	{1941, -0.1277, -0.0202},
+	{1942, 0.1917, 0.0229},
+	{1943, 0.2506, 0.0249},
+	{1944, 0.1903, 0.0258},
+	{1945, 0.3582, 0.0380},
+	{1946, -0.0843, 0.0313},
+	{1947, 0.0520, 0.0092},
+	{1948, 0.0570, 0.0195},
+	{1949, 0.1830, 0.0466},
+	{1950, 0.3081, 0.0043},
+	{1951, 0.2368, -0.0030},
+	{1952, 0.1815, 0.0227},
+	{1953, -0.0121, 0.0414},
+	{1954, 0.5256, 0.0329},
+	{1955, 0.3260, -0.0134},
+	{1956, 0.0744, -0.0226},
+	{1957, -0.1046, 0.0680},
+	{1958, 0.4372, -0.0210},
+	{1959, 0.1206, -0.0265},
+	{1960, 0.0034, 0.1164},
+	{1961, 0.2664, 0.0206},
+	{1962, -0.0881, 0.0569},
+	{1963, 0.2261, 0.0168},
+	{1964, 0.1642, 0.0373},
+	{1965, 0.1240, 0.0072},
+	{1966, -0.0997, 0.0291},
+	{1967, 0.2380, -0.0158},
+	{1968, 0.1081, 0.0327},
+	{1969, -0.0824, -0.0501},
+	{1970, 0.0356, 0.1675},
+	{1971, 0.1422, 0.0979},
+	{1972, 0.1876, 0.0282},
+	{1973, -0.1431, 0.0366},
+	{1974, -0.2590, 0.0199},
+	{1975, 0.3700, 0.0361},
+	{1976, 0.2383, 0.1598},
+	{1977, -0.0698, 0.0129},
+	{1978, 0.0651, -0.0078},
+	{1979, 0.1852, 0.0067},
+	{1980, 0.3174, -0.0299},
+	{1981, -0.0470, 0.0820},
+	{1982, 0.2042, 0.3281},
+	{1983, 0.2234, 0.0320},
+	{1984, 0.0615, 0.1373},
+	{1985, 0.3124, 0.2571},
+	{1986, 0.1849, 0.2428},
+	{1987, 0.0581, -0.0496},
+	{1988, 0.1654, 0.0822},
+	{1989, 0.3148, 0.1769},
+	{1990, -0.0306, 0.0624},
+	{1991, 0.3023, 0.1500},
+	{1992, 0.0749, 0.0936},
+	{1993, 0.0997, 0.1421},
+	{1994, 0.0133, -0.0804},
+	{1995, 0.3720, 0.2348},
+	{1996, 0.2268, 0.0143},
+	{1997, 0.3310, 0.0994},
+	{1998, 0.2834, 0.1492},
+	{1999, 0.2089, -0.0825},
+	{2000, -0.0903, 0.1666},
+	{2001, -0.1185, 0.0557},
+	{2002, -0.2197, 0.1512},
+	{2003, 0.2836, 0.0038},
+	{2004, 0.1074, 0.0449},
+	{2005, 0.0483, 0.0287},
+	{2006, 0.1561, 0.0196},
+	{2007, 0.0548, 0.1021},
+	{2008, -0.3655, 0.2010},
+	{2009, 0.2594, -0.1112},
+	{2010, 0.1482, 0.0846},
+	{2011, 0.0210, 0.1604},
+	{2012, 0.1589, 0.0297},
+	{2013, 0.3215, -0.0910},
+	{2014, 0.1352, 0.1075},
+	{2015, 0.0138, 0.0128},
+	{2016, 0.1177, 0.0069},
+	{2017, 0.2161, 0.0280},
+	{2018, -0.0423, -0.0002},
+	{2019, 0.3121, 0.0964},
+	{2020, 0.1802, 0.1133},
+	{2021, 0.2847, -0.0442},
+	{2022, -0.1804, -0.1783},
+	{2023, 0.2606, 0.0388},
+}
+
+// Returns loads the historical record, oldest year first
+func Returns() []Year {
+	years := make([]Year, len(annualReturns))
+	copy(years, annualReturns)
+	return years
+}
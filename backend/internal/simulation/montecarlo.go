@@ -38,6 +38,49 @@ func RunMonteCarloWithParams(assets []models.Asset, debts []models.Debt, params
 	// Apply defaults for any missing values
 	params.ApplyDefaults()
 
+	response := runMonteCarlo(assets, debts, params, nil)
+	successRate := response.Summary.SuccessRate
+
+	// Replay the plan against every historical starting year
+	if params.RunHistoricalTest {
+		backtest := runMonteCarlo(assets, debts, params, newHistoricalReturns(*params.HistoricalEquityAllocation))
+		passRate := backtest.Summary.SuccessRate
+		response.HistoricalPassRate = &passRate
+	}
+
+	// Compare against the same plan without spouse modeling
+	if params.JointSimulation {
+		singleParams := *params
+		singleParams.JointSimulation = false
+		singleParams.RunHistoricalTest = false
+		single := RunMonteCarloWithParams(assets, debts, &singleParams)
+		if insight := jointComparisonInsight(successRate, single.Summary.SuccessRate); insight != nil {
+			response.Insights = append(response.Insights, *insight)
+		}
+	}
+
+	// Compare against the same plan with a single unified portfolio
+	if params.BucketStrategy != nil {
+		singleParams := *params
+		singleParams.BucketStrategy = nil
+		singleParams.RunHistoricalTest = false
+		single := RunMonteCarloWithParams(assets, debts, &singleParams)
+		singleRate := single.Summary.SuccessRate
+		response.Summary.SingleBucketSuccessRate = &singleRate
+		response.Insights = append(response.Insights, bucketComparisonInsight(successRate, singleRate))
+	}
+
+	return response
+}
+
+// runMonteCarlo simulates the plan once per return sequence: NumSimulations
+// random sequences, or one per starting year when history is set
+func runMonteCarlo(assets []models.Asset, debts []models.Debt, params *models.SimulationParams, history *historicalReturns) models.MonteCarloResponse {
+	numSims := NumSimulations
+	if history != nil {
+		numSims = history.sequences()
+	}
+
 	// Calculate starting net worth
 	var totalAssets, totalDebts float64
 	for _, a := range assets {
@@ -59,17 +102,19 @@ func RunMonteCarloWithParams(assets []models.Asset, debts []models.Debt, params
 
 	// Track results per year per simulation
 	// results[sim][year] = net worth
-	results := make([][]float64, NumSimulations)
-	contributions := make([][]float64, NumSimulations)
-	withdrawals := make([][]float64, NumSimulations)
+	results := make([][]float64, numSims)
+	contributions := make([][]float64, numSims)
+	withdrawals := make([][]float64, numSims)
+	rmds := make([][]float64, numSims)
 
 	// Enhanced tracking for advanced metrics
-	simTrackers := make([]SimulationTracker, NumSimulations)
+	simTrackers := make([]SimulationTracker, numSims)
 
-	for sim := 0; sim < NumSimulations; sim++ {
+	for sim := 0; sim < numSims; sim++ {
 		results[sim] = make([]float64, years)
 		contributions[sim] = make([]float64, years)
 		withdrawals[sim] = make([]float64, years)
+		rmds[sim] = make([]float64, years)
 		// Initialize enhanced tracker
 		simTrackers[sim] = SimulationTracker{
 			NetWorth:    make([]float64, years),
@@ -80,14 +125,25 @@ func RunMonteCarloWithParams(assets []models.Asset, debts []models.Debt, params
 		}
 	}
 
-	// Track success (didn't run out of money)
-	successCount := 0
-	accumulationWarningCount := 0
-
 	// Determine if this is an accumulation-only simulation
 	isAccumulationOnly := retirementYear >= years
 
-	for sim := 0; sim < NumSimulations; sim++ {
+	// Income streams are deterministic, so compute the after-tax total per year once
+	streamIncome := calculateIncomeStreams(params.IncomeStreams, years, params.RetirementTaxRate)
+
+	// Social Security benefit after early-claiming reductions or delayed credits
+	effectiveSSBenefit := applySSAdjustment(params.SocialSecurityAmount, params.SocialSecurityAge, params.SocialSecurityClaimAge)
+
+	// Charitable gifts and surplus RMDs are taxed like other withdrawals
+	withdrawalTaxRate := params.RetirementTaxRate
+	if params.TaxFreeWithdrawals {
+		withdrawalTaxRate = 0
+	}
+
+	// simulate runs one simulation. It writes only to its own results[sim],
+	// contributions[sim], withdrawals[sim] and simTrackers[sim], so
+	// simulations can run concurrently without locking.
+	simulate := func(sim int) simOutcome {
 		// Initialize portfolio value
 		portfolioValue := startingNetWorth
 		peakValue := startingNetWorth
@@ -98,17 +154,21 @@ func RunMonteCarloWithParams(assets []models.Asset, debts []models.Debt, params
 			debtValues[i] = d.CurrentBalance
 		}
 
+		// Tax-deferred share of the portfolio, drawn down by RMDs
+		taxDeferred := math.Min(params.TaxDeferredBalance, math.Max(startingNetWorth, 0))
+
 		// Track cumulative contributions/withdrawals
 		var totalContrib, totalWithdraw float64
 
-		// Current monthly contribution (will grow with inflation)
-		monthlyContrib := params.MonthlyContribution
+		// Current monthly salary contribution (grows with ContributionGrowth)
+		salaryContrib := params.MonthlyContribution
 
 		// Current monthly spending (will grow with inflation)
 		monthlySpending := params.RetirementSpending
 
-		// Track Social Security benefit with COLA adjustments (state variable)
-		ssBenefitAnnual := params.SocialSecurityAmount * 12
+		// Track Social Security benefit with COLA adjustments (state variable),
+		// starting from the benefit adjusted for the claim age
+		ssBenefitAnnual := effectiveSSBenefit * 12
 
 		success := true
 		accumulationWarning := false
@@ -116,8 +176,24 @@ func RunMonteCarloWithParams(assets []models.Asset, debts []models.Debt, params
 		// Track final net worth for accumulation-only success calculation
 		var finalNetWorth float64
 
-		// Track portfolio value at start of retirement for "fixed" withdrawal strategy
-		retirementStartingValue := 0.0
+		// Portfolio value at the start of retirement and last year's withdrawal,
+		// for the "fixed" and "guardrails" strategies
+		withdrawalState := WithdrawalState{CurrentInflationAdj: params.InflationRate}
+
+		// A working spouse's savings and Social Security, if any
+		spouse := newSpouseIncome(params.Spouse, params.SocialSecurityAge, *params.CostOfLivingAdjustment)
+
+		// Bucket strategy pools, funded from the portfolio when retirement begins
+		var buckets bucketPortfolio
+		bucketsActive := false
+
+		// Joint simulations draw a lifetime for each spouse; the plan ends at the last death
+		var primaryDeathAge, spouseDeathAge int
+		bothDiedInHorizon := false
+		if params.JointSimulation {
+			primaryDeathAge = sampleDeathAge(params.CurrentAge, params.Sex)
+			spouseDeathAge = sampleDeathAge(params.SpouseAge, params.SpouseSex)
+		}
 
 		for year := 0; year < years; year++ {
 			age := params.CurrentAge + year
@@ -125,46 +201,97 @@ func RunMonteCarloWithParams(assets []models.Asset, debts []models.Debt, params
 
 			var yearContribution, yearWithdrawal float64
 
+			primaryAlive, spouseAlive := true, false
+			if params.JointSimulation {
+				primaryAlive = age < primaryDeathAge
+				spouseAlive = params.SpouseAge+year < spouseDeathAge
+				if !primaryAlive && !spouseAlive {
+					// Both have died - hold the estate value for the remaining years
+					bothDiedInHorizon = true
+					results[sim][year] = finalNetWorth
+					simTrackers[sim].NetWorth[year] = finalNetWorth
+					continue
+				}
+			}
+
+			spouseContribution, spouseSS := spouse.next(year)
+			if params.JointSimulation && !spouseAlive {
+				spouseContribution, spouseSS = 0, 0
+			}
+
 			if !isRetired {
 				// ACCUMULATION PHASE
 
 				// Calculate annual contribution with employer match
-				annualContrib := monthlyContrib * 12
+				annualContrib := salaryContrib * 12
 				employerMatch := calculateEmployerMatch(annualContrib, params.EmployerMatch, params.EmployerMatchLimit)
-				totalAnnualContrib := annualContrib + employerMatch
+
+				// Other income streams and the spouse's savings and benefits are
+				// invested alongside salary contributions
+				totalAnnualContrib := annualContrib + employerMatch + streamIncome[year] + spouseContribution + spouseSS
 
 				portfolioValue += totalAnnualContrib
 				yearContribution = totalAnnualContrib
 				totalContrib += totalAnnualContrib
 
-				// Grow contribution for next year (salary increase)
-				monthlyContrib *= (1 + params.ContributionGrowth)
+				// Grow salary contribution for next year (salary increase)
+				salaryContrib *= (1 + params.ContributionGrowth)
 			} else {
 				// DISTRIBUTION PHASE
 
 				// Capture portfolio value at start of retirement (first year of distribution)
-				if retirementStartingValue == 0 {
-					retirementStartingValue = portfolioValue
+				if withdrawalState.InitialValue == 0 {
+					withdrawalState.InitialValue = portfolioValue
+				}
+				if params.BucketStrategy != nil && !bucketsActive {
+					buckets = newBucketPortfolio(portfolioValue, params.BucketStrategy)
+					bucketsActive = true
+				}
+
+				// Single-person household spends less once a spouse has died
+				annualSpending := monthlySpending * 12
+				if params.JointSimulation && (!primaryAlive || !spouseAlive) {
+					annualSpending *= 0.75
 				}
 
 				// Calculate withdrawal based on strategy
-				yearWithdrawal = calculateWithdrawal(portfolioValue, monthlySpending*12, params.WithdrawalStrategy, retirementStartingValue)
+				yearWithdrawal = calculateWithdrawal(portfolioValue, annualSpending, params.WithdrawalStrategy, withdrawalState)
+				withdrawalState.LastYearWithdrawal = yearWithdrawal
 
-				// Add Social Security if eligible
-				ssAge := params.SocialSecurityAge
+				// Add Social Security once claimed
+				ssAge := params.SocialSecurityClaimAge
+				var primarySS float64
 				if age >= ssAge && params.SocialSecurityAmount > 0 {
 					// Apply COLA for years after start (not first year receiving)
 					if age > ssAge {
-						ssBenefitAnnual *= 1.025 // 2.5% average COLA
+						ssBenefitAnnual *= 1 + *params.CostOfLivingAdjustment
+					}
+					if params.JointSimulation {
+						// Spousal and survivor benefits based on the primary earner's record.
+						// A spouse with their own benefit gets that instead of the spousal
+						// estimate, but can still take the survivor benefit.
+						spouseEligible := params.SpouseAge+year >= ssAge && (params.Spouse == nil || !primaryAlive)
+						primarySS = jointSocialSecurity(ssBenefitAnnual, primaryAlive, spouseAlive, spouseEligible)
+					} else {
+						primarySS = ssBenefitAnnual
 					}
-					yearWithdrawal -= ssBenefitAnnual // Reduces needed withdrawal
+					yearWithdrawal -= primarySS // Reduces needed withdrawal
 				}
 
+				// Spouse's own Social Security; a survivor keeps the larger of the two benefits
+				if params.JointSimulation && !primaryAlive {
+					spouseSS = math.Max(0, spouseSS-primarySS)
+				}
+				yearWithdrawal -= spouseSS
+
 				// Add pension if any
 				if params.PensionIncome > 0 {
 					yearWithdrawal -= params.PensionIncome * 12
 				}
 
+				// Subtract active income streams (rental, dividends, side gig)
+				yearWithdrawal -= streamIncome[year]
+
 				// Ensure withdrawal need is non-negative
 				if yearWithdrawal < 0 {
 					yearWithdrawal = 0
@@ -173,7 +300,7 @@ func RunMonteCarloWithParams(assets []models.Asset, debts []models.Debt, params
 				// Calculate gross withdrawal needed (pre-tax)
 				// To have X after taxes at rate T, you need X / (1 - T) gross
 				grossWithdrawal := yearWithdrawal
-				if params.RetirementTaxRate > 0 && params.RetirementTaxRate < 1 {
+				if !params.TaxFreeWithdrawals && params.RetirementTaxRate > 0 && params.RetirementTaxRate < 1 {
 					grossWithdrawal = yearWithdrawal / (1 - params.RetirementTaxRate)
 				}
 
@@ -185,9 +312,37 @@ func RunMonteCarloWithParams(assets []models.Asset, debts []models.Debt, params
 					grossWithdrawal = portfolioValue
 				}
 
+				// Required minimum distributions force money out of the tax-deferred
+				// balance whatever the strategy; what spending doesn't need is taxed
+				// and reinvested
+				rmd := RequiredMinimumDistribution(taxDeferred, age)
+				var rmdSurplus float64
+				if rmd > grossWithdrawal {
+					rmdSurplus = rmd - grossWithdrawal
+					grossWithdrawal = rmd
+					yearWithdrawal = math.Max(yearWithdrawal, rmd*(1-withdrawalTaxRate))
+				}
+				rmds[sim][year] = rmd
+
+				// Spending comes from taxable money first, then tax-deferred
+				fromTaxDeferred := math.Max(rmd, grossWithdrawal-(portfolioValue-taxDeferred))
+				taxDeferred -= math.Min(fromTaxDeferred, taxDeferred)
+
+				// Bucket strategy: refill cash/bonds, then spend from cash first
+				if bucketsActive {
+					buckets.refill(grossWithdrawal)
+					buckets.withdraw(grossWithdrawal)
+				}
+
 				portfolioValue -= grossWithdrawal
+				portfolioValue += rmdSurplus * (1 - withdrawalTaxRate)
 				totalWithdraw += grossWithdrawal
 
+				// A spouse who is still working keeps saving
+				portfolioValue += spouseContribution
+				yearContribution = spouseContribution
+				totalContrib += spouseContribution
+
 				// Grow spending for inflation (for next year's calculation)
 				monthlySpending *= (1 + params.InflationRate)
 			}
@@ -199,6 +354,25 @@ func RunMonteCarloWithParams(assets []models.Asset, debts []models.Debt, params
 				}
 			}
 
+			// Charitable gifts, including the tax cost of withdrawing them
+			if params.CharitableGiving != nil {
+				// Without a tax-deferred balance, QCDs treat the whole portfolio as an IRA
+				rmdBalance := portfolioValue
+				if params.TaxDeferredBalance > 0 {
+					rmdBalance = taxDeferred
+				}
+				portfolioValue -= charitableGiftCost(params.CharitableGiving, year+1, age, years, rmdBalance, withdrawalTaxRate)
+			}
+
+			// Keep the buckets in sync with event cash flows (income lands in cash)
+			if bucketsActive {
+				if delta := portfolioValue - buckets.total(); delta > 0 {
+					buckets.cash += delta
+				} else {
+					buckets.withdraw(-delta)
+				}
+			}
+
 			// Pay down debts (simplified: minimum payments)
 			for i, d := range debts {
 				if debtValues[i] > 0 {
@@ -227,17 +401,24 @@ func RunMonteCarloWithParams(assets []models.Asset, debts []models.Debt, params
 			if params.EnableGlidePath {
 				// Use age-adjusted return and volatility (target-date style)
 				glideReturn, glideVolatility := calculateGlidePathParams(age, params.RetirementAge)
-				annualReturn = normalRandom(glideReturn, glideVolatility)
+				annualReturn = history.portfolio(sim, year, glideReturn, glideVolatility)
 			} else {
 				// Use static return and volatility
-				annualReturn = normalRandom(params.ExpectedReturn, params.Volatility)
+				annualReturn = history.portfolio(sim, year, params.ExpectedReturn, params.Volatility)
+			}
+
+			if bucketsActive {
+				// Equities earn the portfolio return; bonds and cash have their own
+				bondReturn := history.bonds(sim, year, params.BucketStrategy.Bucket2Return, params.BucketStrategy.Bucket2Volatility)
+				annualReturn = buckets.grow(bondReturn, history.equities(sim, year, annualReturn))
+				portfolioValue = buckets.total()
 			}
 
 			// Track the return for sequence analysis
 			simTrackers[sim].Returns[year] = annualReturn
 
 			// Apply return to portfolio (not debts)
-			if portfolioValue > 0 {
+			if portfolioValue > 0 && !bucketsActive {
 				portfolioValue *= (1 + annualReturn)
 			}
 
@@ -246,6 +427,9 @@ func RunMonteCarloWithParams(assets []models.Asset, debts []models.Debt, params
 				portfolioValue = 0
 			}
 
+			// The tax-deferred share earns the same return and can't exceed the portfolio
+			taxDeferred = math.Min(taxDeferred*(1+annualReturn), portfolioValue)
+
 			// Track peak value for drawdown analysis
 			if portfolioValue > peakValue {
 				peakValue = portfolioValue
@@ -289,27 +473,31 @@ func RunMonteCarloWithParams(assets []models.Asset, debts []models.Debt, params
 			}
 		}
 
+		// Joint simulations succeed only if money remains when the last spouse dies
+		if bothDiedInHorizon && finalNetWorth <= 0 {
+			success = false
+		}
+
 		// Store final tracker state
 		simTrackers[sim].Success = success
 		simTrackers[sim].PeakValue = peakValue
 
-		if success {
-			successCount++
-		}
-		if accumulationWarning {
-			accumulationWarningCount++
-		}
+		return simOutcome{success: success, accumulationWarning: accumulationWarning}
 	}
 
+	// Track success (didn't run out of money)
+	successCount, accumulationWarningCount := runSimulations(numSims, simulate)
+
 	// Calculate percentiles for each year
 	projections := make([]models.YearProjection, years)
 	for year := 0; year < years; year++ {
-		yearValues := make([]float64, NumSimulations)
-		var totalContrib, totalWithdraw float64
-		for sim := 0; sim < NumSimulations; sim++ {
+		yearValues := make([]float64, numSims)
+		var totalContrib, totalWithdraw, totalRMD float64
+		for sim := 0; sim < numSims; sim++ {
 			yearValues[sim] = results[sim][year]
 			totalContrib += contributions[sim][year]
 			totalWithdraw += withdrawals[sim][year]
+			totalRMD += rmds[sim][year]
 		}
 		sort.Float64s(yearValues)
 
@@ -327,15 +515,19 @@ func RunMonteCarloWithParams(assets []models.Asset, debts []models.Debt, params
 			P75:           percentile(yearValues, 75),
 			P90:           percentile(yearValues, 90),
 			Phase:         phase,
-			Contributions: totalContrib / float64(NumSimulations),
-			Withdrawals:   totalWithdraw / float64(NumSimulations),
+			Contributions: totalContrib / float64(numSims),
+			Withdrawals:   totalWithdraw / float64(numSims),
+			RMDAmount:     totalRMD / float64(numSims),
 		}
+		projections[year].RealP10 = toRealValue(projections[year].P10, params.InflationRate, year+1)
+		projections[year].RealP50 = toRealValue(projections[year].P50, params.InflationRate, year+1)
+		projections[year].RealP90 = toRealValue(projections[year].P90, params.InflationRate, year+1)
 	}
 
 	// Calculate final year statistics
-	finalValues := make([]float64, NumSimulations)
+	finalValues := make([]float64, numSims)
 	var totalContribSum, totalWithdrawSum float64
-	for sim := 0; sim < NumSimulations; sim++ {
+	for sim := 0; sim < numSims; sim++ {
 		finalValues[sim] = results[sim][years-1]
 		for year := 0; year < years; year++ {
 			totalContribSum += contributions[sim][year]
@@ -344,11 +536,13 @@ func RunMonteCarloWithParams(assets []models.Asset, debts []models.Debt, params
 	}
 	sort.Float64s(finalValues)
 
-	successRate := float64(successCount) / float64(NumSimulations) * 100
+	successRate := float64(successCount) / float64(numSims) * 100
 
 	// Calculate enhanced metrics
 	enhancedMetrics := calculateEnhancedMetrics(simTrackers, params, retirementYear, years)
 
+	shortfallPct := float64(accumulationWarningCount) / float64(numSims)
+
 	response := models.MonteCarloResponse{
 		Projections: projections,
 		Summary: models.ProjectionSummary{
@@ -359,21 +553,68 @@ func RunMonteCarloWithParams(assets []models.Asset, debts []models.Debt, params
 			FinalP75:             percentile(finalValues, 75),
 			FinalP90:             percentile(finalValues, 90),
 			Years:                years,
-			Simulations:          NumSimulations,
+			Simulations:          numSims,
 			SuccessRate:          successRate,
 			RetirementYear:       retirementYear,
-			TotalContributions:   totalContribSum / float64(NumSimulations),
-			TotalWithdrawals:     totalWithdrawSum / float64(NumSimulations),
+			TotalContributions:   totalContribSum / float64(numSims),
+			TotalWithdrawals:     totalWithdrawSum / float64(numSims),
 			AccumulationWarnings: accumulationWarningCount,
 			EnhancedMetrics:      enhancedMetrics,
+
+			AccumulationShortfallPct: shortfallPct,
+			MedianInflectionYear:     findMedianInflectionYear(projections, startingNetWorth, retirementYear),
+
+			RealFinalP10: toRealValue(percentile(finalValues, 10), params.InflationRate, years),
+			RealFinalP50: toRealValue(percentile(finalValues, 50), params.InflationRate, years),
+			RealFinalP90: toRealValue(percentile(finalValues, 90), params.InflationRate, years),
 		},
 		Milestones: calculateMilestones(results, startingNetWorth),
-		Insights:   generateInsights(params, startingNetWorth, successRate, projections),
+		Insights:   generateInsights(params, startingNetWorth, successRate, shortfallPct, projections, nil),
 	}
 
 	return response
 }
 
+// findMedianInflectionYear returns the first accumulation year where the median
+// net worth falls from the prior year - contributions losing to debt interest or
+// market losses. Returns nil if the median grows every year before retirement.
+func findMedianInflectionYear(projections []models.YearProjection, startingNetWorth float64, retirementYear int) *int {
+	prev := startingNetWorth
+	for i := 0; i < len(projections) && i < retirementYear; i++ {
+		if projections[i].P50 < prev {
+			year := projections[i].Year
+			return &year
+		}
+		prev = projections[i].P50
+	}
+	return nil
+}
+
+// toRealValue converts a nominal value in the given year to today's dollars
+func toRealValue(nominal, inflationRate float64, year int) float64 {
+	return nominal / math.Pow(1+inflationRate, float64(year))
+}
+
+// jointComparisonInsight flags a large gap between joint and single-life success rates
+func jointComparisonInsight(jointRate, singleRate float64) *models.Insight {
+	gap := jointRate - singleRate
+	if math.Abs(gap) <= 10 {
+		return nil
+	}
+
+	direction := "lower"
+	if gap > 0 {
+		direction = "higher"
+	}
+	return &models.Insight{
+		Type:  "info",
+		Code:  "joint_vs_single_comparison",
+		Title: "Spouse Changes the Outlook",
+		Message: fmt.Sprintf("Modeling both lifetimes gives a %.0f%% success rate, %.0f points %s than a single-life projection (%.0f%%). "+
+			"Review survivor income and spending assumptions together.", jointRate, math.Abs(gap), direction, singleRate),
+	}
+}
+
 // calculateEmployerMatch calculates the employer 401k match
 func calculateEmployerMatch(annualContrib, matchRate, matchLimit float64) float64 {
 	if matchRate <= 0 {
@@ -386,30 +627,79 @@ func calculateEmployerMatch(annualContrib, matchRate, matchLimit float64) float6
 	return match
 }
 
+// calculateIncomeStreams returns the combined after-tax stream income for each simulation year
+func calculateIncomeStreams(streams []models.IncomeStream, years int, taxRate float64) []float64 {
+	totals := make([]float64, years)
+	for _, stream := range streams {
+		// Negative amounts model recurring payments (e.g., a purchase loan)
+		if stream.MonthlyAmount == 0 {
+			continue
+		}
+		startYear := stream.StartYear
+		if startYear < 1 {
+			startYear = 1
+		}
+		for year := 0; year < years; year++ {
+			simYear := year + 1
+			if simYear < startYear || (stream.EndYear > 0 && simYear > stream.EndYear) {
+				continue
+			}
+			annual := stream.MonthlyAmount * 12 * math.Pow(1+stream.GrowthRate, float64(simYear-startYear))
+			if stream.IsTaxable && annual > 0 && taxRate > 0 && taxRate < 1 {
+				annual *= 1 - taxRate
+			}
+			totals[year] += annual
+		}
+	}
+	return totals
+}
+
+// Guyton-Klinger guardrails: spending starts at gkInitialRate of the
+// retirement portfolio, and is cut or raised by gkAdjustment whenever the
+// current withdrawal rate drifts more than 20% past that initial rate
+const (
+	gkInitialRate  = 0.04
+	gkCeilingRatio = 1.2 // cut spending above 4.8%
+	gkFloorRatio   = 0.8 // raise spending below 3.2%
+	gkAdjustment   = 0.10
+)
+
+// WithdrawalState carries a simulation's withdrawal history from one
+// retirement year to the next
+type WithdrawalState struct {
+	InitialValue        float64 // portfolio value when retirement began
+	LastYearWithdrawal  float64 // strategy withdrawal last year (0 in the first retirement year)
+	CurrentInflationAdj float64 // inflation applied to last year's withdrawal
+}
+
 // calculateWithdrawal determines withdrawal amount based on strategy
-func calculateWithdrawal(portfolioValue, desiredSpending float64, strategy string, initialValue float64) float64 {
+func calculateWithdrawal(portfolioValue, desiredSpending float64, strategy string, state WithdrawalState) float64 {
 	switch strategy {
 	case "fixed":
 		// Classic 4% rule - 4% of initial portfolio
-		return initialValue * 0.04
+		return state.InitialValue * 0.04
 	case "dynamic":
 		// 4% of current portfolio value
 		return portfolioValue * 0.04
 	case "guardrails":
-		// 4% with 3-5% guardrails based on performance
 		if portfolioValue <= 0 {
 			return 0 // Can't withdraw from empty portfolio
 		}
-		baseWithdrawal := initialValue * 0.04
-		currentRate := baseWithdrawal / portfolioValue
-		if currentRate < 0.03 {
-			// Portfolio doing well, can withdraw more
-			return portfolioValue * 0.03
-		} else if currentRate > 0.05 {
-			// Portfolio struggling, reduce withdrawal
-			return portfolioValue * 0.05
+		if state.LastYearWithdrawal == 0 {
+			return state.InitialValue * gkInitialRate
+		}
+
+		// Last year's spending, inflation-adjusted, unless a guardrail is hit
+		withdrawal := state.LastYearWithdrawal * (1 + state.CurrentInflationAdj)
+		currentRate := withdrawal / portfolioValue
+		if currentRate > gkInitialRate*gkCeilingRatio {
+			// Portfolio struggling, cut spending
+			withdrawal *= 1 - gkAdjustment
+		} else if currentRate < gkInitialRate*gkFloorRatio {
+			// Portfolio doing well, give spending a raise
+			withdrawal *= 1 + gkAdjustment
 		}
-		return baseWithdrawal
+		return withdrawal
 	default:
 		// Default to desired spending
 		return desiredSpending
@@ -463,7 +753,7 @@ func calculateMilestones(results [][]float64, startingNetWorth float64) []models
 		yearSum := 0
 		yearCount := 0
 
-		for sim := 0; sim < NumSimulations; sim++ {
+		for sim := range results {
 			for year := 0; year < len(results[sim]); year++ {
 				if results[sim][year] >= target {
 					reachedCount++
@@ -479,7 +769,7 @@ func calculateMilestones(results [][]float64, startingNetWorth float64) []models
 				Description:    formatCurrency(target) + " net worth",
 				TargetAmount:   target,
 				MedianYear:     yearSum / yearCount,
-				ProbabilityPct: float64(reachedCount) / float64(NumSimulations) * 100,
+				ProbabilityPct: float64(reachedCount) / float64(len(results)) * 100,
 			})
 		}
 	}
@@ -487,10 +777,75 @@ func calculateMilestones(results [][]float64, startingNetWorth float64) []models
 	return milestones
 }
 
-// generateInsights creates actionable recommendations
-func generateInsights(params *models.SimulationParams, startingNetWorth, successRate float64, projections []models.YearProjection) []models.Insight {
+// GoalMilestone is calculateMilestones for a single target, working from a
+// saved run's percentile projections instead of the raw paths. MedianYear is
+// the first year the P50 projection reaches the target (0 if it never does).
+// ProbabilityPct is the best single-year share of outcomes at or above the
+// target, interpolated between percentiles, so it slightly understates the
+// chance of touching the target at some point.
+func GoalMilestone(projections []models.YearProjection, target float64) models.Milestone {
+	milestone := models.Milestone{
+		Description:  formatCurrency(target) + " goal",
+		TargetAmount: target,
+	}
+
+	for _, p := range projections {
+		if milestone.MedianYear == 0 && p.P50 >= target {
+			milestone.MedianYear = p.Year
+		}
+		milestone.ProbabilityPct = math.Max(milestone.ProbabilityPct, probabilityAtOrAbove(p, target))
+	}
+
+	return milestone
+}
+
+// probabilityAtOrAbove estimates the percent of outcomes in a year at or
+// above target. Outside the P10-P90 band it returns the conservative end of
+// what the percentiles show: 90 below P10 and 0 above P90.
+func probabilityAtOrAbove(p models.YearProjection, target float64) float64 {
+	type point struct{ pct, value float64 }
+	points := []point{{10, p.P10}}
+	if p.P25 > 0 {
+		points = append(points, point{25, p.P25})
+	}
+	points = append(points, point{50, p.P50})
+	if p.P75 > 0 {
+		points = append(points, point{75, p.P75})
+	}
+	points = append(points, point{90, p.P90})
+
+	if target <= points[0].value {
+		return 90
+	}
+	for i := 1; i < len(points); i++ {
+		lo, hi := points[i-1], points[i]
+		if target <= hi.value {
+			pct := hi.pct
+			if hi.value > lo.value {
+				pct = lo.pct + (target-lo.value)/(hi.value-lo.value)*(hi.pct-lo.pct)
+			}
+			return 100 - pct
+		}
+	}
+	return 0
+}
+
+// generateInsights creates actionable recommendations.
+// colaSpread is the success-rate spread across COLA scenarios, or nil when
+// the sensitivity analysis wasn't run.
+func generateInsights(params *models.SimulationParams, startingNetWorth, successRate, accumulationShortfallPct float64, projections []models.YearProjection, colaSpread *float64) []models.Insight {
 	insights := []models.Insight{}
 
+	// Accumulation shortfall - listed first since it undermines the rest of the plan
+	if accumulationShortfallPct > 0.10 {
+		insights = append(insights, models.Insight{
+			Type:    "warning",
+			Code:    "accumulation_shortfall",
+			Title:   "Net Worth May Go Negative Before Retirement",
+			Message: fmt.Sprintf("In %.0f%% of simulations your net worth drops below zero before retirement. Consider paying down high-interest debt first or increasing your monthly contributions.", accumulationShortfallPct*100),
+		})
+	}
+
 	// Success rate insights
 	if successRate >= 90 {
 		insights = append(insights, models.Insight{
@@ -536,6 +891,64 @@ func generateInsights(params *models.SimulationParams, startingNetWorth, success
 		})
 	}
 
+	// Income diversification insights
+	if len(params.IncomeStreams) > 0 {
+		streamCount := len(params.IncomeStreams)
+		if params.SocialSecurityAmount > 0 {
+			streamCount++
+		}
+		if params.PensionIncome > 0 {
+			streamCount++
+		}
+		insightType := "info"
+		if streamCount >= 3 {
+			insightType = "success"
+		}
+		insights = append(insights, models.Insight{
+			Type:    insightType,
+			Title:   "Income Diversification",
+			Message: fmt.Sprintf("Your plan includes %d separate income streams. Multiple sources reduce reliance on portfolio withdrawals.", streamCount),
+		})
+	}
+
+	// Social Security COLA sensitivity
+	if colaSpread != nil {
+		sensitivity := "only modestly"
+		if *colaSpread >= 10 {
+			sensitivity = "significantly"
+		} else if *colaSpread >= 5 {
+			sensitivity = "moderately"
+		}
+		insights = append(insights, models.Insight{
+			Type:  "info",
+			Code:  "ss_cola_sensitivity",
+			Title: "Social Security COLA Sensitivity",
+			Message: fmt.Sprintf("Future Social Security cost-of-living adjustments depend on inflation and policy; 0%%-4%% is a plausible range. "+
+				"Across that range your success rate varies by %.1f percentage points, so your plan depends %s on COLA assumptions.", *colaSpread, sensitivity),
+		})
+	}
+
+	// Real (inflation-adjusted) ending wealth vs. 20 years of retirement spending
+	if params.RetirementSpending > 0 && len(projections) > 0 {
+		realFinalP50 := projections[len(projections)-1].RealP50
+		target := params.RetirementSpending * 12 * 20
+		if realFinalP50 < target {
+			insights = append(insights, models.Insight{
+				Type:  "warning",
+				Code:  "real_wealth_shortfall",
+				Title: "Inflation Erodes Your Ending Wealth",
+				Message: fmt.Sprintf("Your median ending net worth of %s is worth about %s in today's dollars, "+
+					"less than 20 years of your planned retirement spending (%s). Consider saving more or planning for lower spending.",
+					formatCurrency(projections[len(projections)-1].P50), formatCurrency(realFinalP50), formatCurrency(target)),
+			})
+		}
+	}
+
+	// Charitable giving: QCD vs. cash
+	if insight := charitableInsight(params); insight != nil {
+		insights = append(insights, *insight)
+	}
+
 	// Retirement age insights
 	if params.RetirementAge < 62 && successRate < 80 {
 		insights = append(insights, models.Insight{
@@ -1097,6 +1510,7 @@ func runBehavioralSimulation(assets []models.Asset, debts []models.Debt, params
 
 	successCount := 0
 	isAccumulationOnly := retirementYear >= years
+	streamIncome := calculateIncomeStreams(params.IncomeStreams, years, params.RetirementTaxRate)
 
 	for sim := 0; sim < NumSimulations; sim++ {
 		portfolioValue := startingNetWorth
@@ -1108,12 +1522,12 @@ func runBehavioralSimulation(assets []models.Asset, debts []models.Debt, params
 		}
 
 		var totalContrib, totalWithdraw float64
-		monthlyContrib := params.MonthlyContribution
+		salaryContrib := params.MonthlyContribution
 		monthlySpending := params.RetirementSpending
-		ssBenefitAnnual := params.SocialSecurityAmount * 12
+		ssBenefitAnnual := applySSAdjustment(params.SocialSecurityAmount, params.SocialSecurityAge, params.SocialSecurityClaimAge) * 12
 
 		success := true
-		retirementStartingValue := 0.0
+		withdrawalState := WithdrawalState{CurrentInflationAdj: params.InflationRate}
 
 		// Behavioral state
 		behavState := BehavioralState{}
@@ -1125,24 +1539,25 @@ func runBehavioralSimulation(assets []models.Asset, debts []models.Debt, params
 			var yearContribution, yearWithdrawal float64
 
 			if !isRetired {
-				annualContrib := monthlyContrib * 12
+				annualContrib := salaryContrib * 12
 				employerMatch := calculateEmployerMatch(annualContrib, params.EmployerMatch, params.EmployerMatchLimit)
-				totalAnnualContrib := annualContrib + employerMatch
+				totalAnnualContrib := annualContrib + employerMatch + streamIncome[year]
 				portfolioValue += totalAnnualContrib
 				yearContribution = totalAnnualContrib
 				totalContrib += totalAnnualContrib
-				monthlyContrib *= (1 + params.ContributionGrowth)
+				salaryContrib *= (1 + params.ContributionGrowth)
 			} else {
-				if retirementStartingValue == 0 {
-					retirementStartingValue = portfolioValue
+				if withdrawalState.InitialValue == 0 {
+					withdrawalState.InitialValue = portfolioValue
 				}
 
-				yearWithdrawal = calculateWithdrawal(portfolioValue, monthlySpending*12, params.WithdrawalStrategy, retirementStartingValue)
+				yearWithdrawal = calculateWithdrawal(portfolioValue, monthlySpending*12, params.WithdrawalStrategy, withdrawalState)
+				withdrawalState.LastYearWithdrawal = yearWithdrawal
 
-				ssAge := params.SocialSecurityAge
+				ssAge := params.SocialSecurityClaimAge
 				if age >= ssAge && params.SocialSecurityAmount > 0 {
 					if age > ssAge {
-						ssBenefitAnnual *= 1.025
+						ssBenefitAnnual *= 1 + *params.CostOfLivingAdjustment
 					}
 					yearWithdrawal -= ssBenefitAnnual
 				}
@@ -1151,12 +1566,14 @@ func runBehavioralSimulation(assets []models.Asset, debts []models.Debt, params
 					yearWithdrawal -= params.PensionIncome * 12
 				}
 
+				yearWithdrawal -= streamIncome[year]
+
 				if yearWithdrawal < 0 {
 					yearWithdrawal = 0
 				}
 
 				grossWithdrawal := yearWithdrawal
-				if params.RetirementTaxRate > 0 && params.RetirementTaxRate < 1 {
+				if !params.TaxFreeWithdrawals && params.RetirementTaxRate > 0 && params.RetirementTaxRate < 1 {
 					grossWithdrawal = yearWithdrawal / (1 - params.RetirementTaxRate)
 				}
 
@@ -1245,6 +1662,9 @@ func runBehavioralSimulation(assets []models.Asset, debts []models.Debt, params
 			Contributions: totalContrib / float64(NumSimulations),
 			Withdrawals:   totalWithdraw / float64(NumSimulations),
 		}
+		projections[year].RealP10 = toRealValue(projections[year].P10, params.InflationRate, year+1)
+		projections[year].RealP50 = toRealValue(projections[year].P50, params.InflationRate, year+1)
+		projections[year].RealP90 = toRealValue(projections[year].P90, params.InflationRate, year+1)
 	}
 
 	finalValues := make([]float64, NumSimulations)
@@ -1273,6 +1693,9 @@ func runBehavioralSimulation(assets []models.Asset, debts []models.Debt, params
 			RetirementYear:     retirementYear,
 			TotalContributions: totalContribSum / float64(NumSimulations),
 			TotalWithdrawals:   totalWithdrawSum / float64(NumSimulations),
+			RealFinalP10:       toRealValue(percentile(finalValues, 10), params.InflationRate, years),
+			RealFinalP50:       toRealValue(percentile(finalValues, 50), params.InflationRate, years),
+			RealFinalP90:       toRealValue(percentile(finalValues, 90), params.InflationRate, years),
 		},
 	}
 }
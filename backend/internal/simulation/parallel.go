@@ -0,0 +1,65 @@
+package simulation
+
+import (
+	"runtime"
+	"sync"
+)
+
+// Workers is the number of goroutines RunMonteCarloWithParams spreads its
+// simulations across. Set it to 1 to run serially.
+var Workers = runtime.NumCPU()
+
+// simOutcome is what a single simulation reports back for the summary counts
+type simOutcome struct {
+	success             bool
+	accumulationWarning bool
+}
+
+// simTally is one worker's counts over its share of the simulations
+type simTally struct {
+	successes            int
+	accumulationWarnings int
+}
+
+// runSimulations calls simulate for every simulation index, splitting the
+// indices into contiguous ranges, one per worker. Each worker sends its tally
+// over a channel once its range is done.
+func runSimulations(n int, simulate func(sim int) simOutcome) (successCount, accumulationWarningCount int) {
+	workers := Workers
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > n {
+		workers = n
+	}
+
+	tallies := make(chan simTally, workers)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		start, end := w*n/workers, (w+1)*n/workers
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var t simTally
+			for sim := start; sim < end; sim++ {
+				outcome := simulate(sim)
+				if outcome.success {
+					t.successes++
+				}
+				if outcome.accumulationWarning {
+					t.accumulationWarnings++
+				}
+			}
+			tallies <- t
+		}()
+	}
+
+	wg.Wait()
+	close(tallies)
+
+	for t := range tallies {
+		successCount += t.successes
+		accumulationWarningCount += t.accumulationWarnings
+	}
+	return successCount, accumulationWarningCount
+}
@@ -0,0 +1,32 @@
+package simulation
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/finviz/backend/internal/models"
+)
+
+// BenchmarkRunMonteCarlo compares a full run on one worker with one spread
+// across every CPU
+func BenchmarkRunMonteCarlo(b *testing.B) {
+	assets := []models.Asset{{CurrentValue: 500_000}}
+	prev := Workers
+	b.Cleanup(func() { Workers = prev })
+
+	for _, bc := range []struct {
+		name    string
+		workers int
+	}{
+		{"serial", 1},
+		{"parallel", runtime.NumCPU()},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			Workers = bc.workers
+			for i := 0; i < b.N; i++ {
+				params := models.DefaultSimulationParams()
+				RunMonteCarloWithParams(assets, nil, &params)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package simulation
+
+import (
+	"math"
+	"sort"
+
+	"github.com/finviz/backend/internal/models"
+)
+
+// sensitivityStep is the relative nudge applied to each parameter, both ways
+const sensitivityStep = 0.10
+
+// sensitivityParam reads and writes one tunable plan parameter
+type sensitivityParam struct {
+	name string
+	get  func(p *models.SimulationParams) float64
+	set  func(p *models.SimulationParams, v float64)
+}
+
+var sensitivityParams = []sensitivityParam{
+	{
+		name: "monthlyContribution",
+		get:  func(p *models.SimulationParams) float64 { return p.MonthlyContribution },
+		set:  func(p *models.SimulationParams, v float64) { p.MonthlyContribution = v },
+	},
+	{
+		name: "retirementAge",
+		get:  func(p *models.SimulationParams) float64 { return float64(p.RetirementAge) },
+		set:  func(p *models.SimulationParams, v float64) { p.RetirementAge = int(v) },
+	},
+	{
+		name: "expectedReturn",
+		get:  func(p *models.SimulationParams) float64 { return p.ExpectedReturn },
+		set:  func(p *models.SimulationParams, v float64) { p.ExpectedReturn = v },
+	},
+	{
+		name: "volatility",
+		get:  func(p *models.SimulationParams) float64 { return p.Volatility },
+		set:  func(p *models.SimulationParams, v float64) { p.Volatility = v },
+	},
+	{
+		name: "retirementSpending",
+		get:  func(p *models.SimulationParams) float64 { return p.RetirementSpending },
+		set:  func(p *models.SimulationParams, v float64) { p.RetirementSpending = v },
+	},
+}
+
+// RunSensitivityAnalysis re-runs a plan with each key parameter nudged 10%
+// up and down and ranks the parameters by how far the success rate moves
+func RunSensitivityAnalysis(assets []models.Asset, debts []models.Debt, params *models.SimulationParams) models.SensitivityResponse {
+	params.ApplyDefaults()
+
+	baseParams := *params
+	baseParams.RunHistoricalTest = false
+	baseRate := RunMonteCarloWithParams(assets, debts, &baseParams).Summary.SuccessRate
+
+	results := []models.SensitivityResult{}
+	for _, sp := range sensitivityParams {
+		base := sp.get(&baseParams)
+		if base == 0 {
+			continue // nothing to nudge (e.g. no contributions)
+		}
+
+		for _, change := range []float64{sensitivityStep, -sensitivityStep} {
+			perturbed := perturbValue(sp.name, base, change, &baseParams)
+			if perturbed == base {
+				continue
+			}
+
+			scenarioParams := baseParams
+			sp.set(&scenarioParams, perturbed)
+			rate := RunMonteCarloWithParams(assets, debts, &scenarioParams).Summary.SuccessRate
+
+			results = append(results, models.SensitivityResult{
+				Param:          sp.name,
+				Change:         change,
+				BaseValue:      base,
+				PerturbedValue: perturbed,
+				BaseRate:       baseRate,
+				PerturbedRate:  rate,
+				Delta:          rate - baseRate,
+			})
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return math.Abs(results[i].Delta) > math.Abs(results[j].Delta)
+	})
+
+	return models.SensitivityResponse{
+		BaseSuccessRate: baseRate,
+		Results:         results,
+	}
+}
+
+// perturbValue applies a relative change to a parameter. Retirement age moves
+// in whole years (at least one) and can't drop below the current age.
+func perturbValue(name string, base, change float64, params *models.SimulationParams) float64 {
+	if name != "retirementAge" {
+		return base * (1 + change)
+	}
+
+	years := math.Max(1, math.Round(math.Abs(base*change)))
+	age := base + math.Copysign(years, change)
+	return math.Max(age, float64(params.CurrentAge))
+}
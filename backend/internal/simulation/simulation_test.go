@@ -0,0 +1,65 @@
+package simulation
+
+import (
+	"math"
+	"testing"
+
+	"github.com/finviz/backend/internal/models"
+)
+
+func TestCalculateWithdrawalGuardrails(t *testing.T) {
+	tests := []struct {
+		name      string
+		portfolio float64
+		state     WithdrawalState
+		want      float64
+	}{
+		{"first retirement year takes the initial rate", 1_000_000, WithdrawalState{InitialValue: 1_000_000}, 40_000},
+		{"inside the guardrails keeps inflation-adjusted spending", 1_000_000, WithdrawalState{InitialValue: 1_000_000, LastYearWithdrawal: 40_000, CurrentInflationAdj: 0.03}, 41_200},
+		{"above the ceiling cuts spending", 700_000, WithdrawalState{InitialValue: 1_000_000, LastYearWithdrawal: 40_000}, 36_000},
+		{"below the floor raises spending", 1_500_000, WithdrawalState{InitialValue: 1_000_000, LastYearWithdrawal: 40_000}, 44_000},
+		{"empty portfolio withdraws nothing", 0, WithdrawalState{InitialValue: 1_000_000, LastYearWithdrawal: 40_000}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := calculateWithdrawal(tt.portfolio, 0, "guardrails", tt.state)
+			if math.Abs(got-tt.want) > 0.01 {
+				t.Errorf("calculateWithdrawal() = %.2f, want %.2f", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestGuardrailsRuinRate checks that guardrails spending runs out of money
+// less often than the fixed 4% rule for a $1M portfolio retiring today
+func TestGuardrailsRuinRate(t *testing.T) {
+	ruinRate := func(strategy string) float64 {
+		params := models.DefaultSimulationParams()
+		params.CurrentAge = 65
+		params.RetirementAge = 65
+		params.TimeHorizonYears = 30
+		params.WithdrawalStrategy = strategy
+		result := RunMonteCarloWithParams([]models.Asset{{CurrentValue: 1_000_000}}, nil, &params)
+		return 100 - result.Summary.SuccessRate
+	}
+
+	fixed := ruinRate("fixed")
+	guardrails := ruinRate("guardrails")
+	t.Logf("ruin rate: fixed %.1f%%, guardrails %.1f%%", fixed, guardrails)
+
+	// With NumSimulations runs each rate is within about a point of its true
+	// value, so a 10 point gap doesn't flake
+	if guardrails > fixed-10 {
+		t.Errorf("guardrails ruin rate %.1f%% should be well below fixed %.1f%%", guardrails, fixed)
+	}
+}
+
+func TestApplySSAdjustmentEarlyClaim(t *testing.T) {
+	// Five years early at a full retirement age of 67 is a 30% reduction
+	if got := applySSAdjustment(2000, 67, 62); math.Abs(got-1400) > 0.01 {
+		t.Errorf("applySSAdjustment(2000, 67, 62) = %.2f, want 1400", got)
+	}
+	if got := applySSAdjustment(2000, 67, 67); got != 2000 {
+		t.Errorf("applySSAdjustment(2000, 67, 67) = %.2f, want 2000", got)
+	}
+}
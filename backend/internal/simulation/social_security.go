@@ -0,0 +1,88 @@
+package simulation
+
+import "math"
+
+// Social Security claiming adjustments relative to full retirement age
+const (
+	ssEarlyReductionFirst36 = 5.0 / 9.0 / 100  // per month, first 36 months early
+	ssEarlyReductionBeyond  = 5.0 / 12.0 / 100 // per month beyond 36 months early
+	ssDelayedCreditPerMonth = 2.0 / 3.0 / 100  // 8% per year of delay
+	ssMaxCreditAge          = 70               // delayed credits stop accruing at 70
+)
+
+// PIA formula (2024): 90% of AIME up to the first bend point, 32% up to the
+// second and 15% above it. AIME averages the highest 35 years of earnings.
+const (
+	ssBendPoint1       = 1174.0
+	ssBendPoint2       = 7078.0
+	ssComputationYears = 35
+)
+
+// applySSAdjustment converts a monthly benefit quoted at full retirement age
+// into the benefit actually paid when claiming at claimAge
+func applySSAdjustment(fraBenefit float64, fraAge, claimAge int) float64 {
+	return fraBenefit * ClaimingFactor(fraAge*12, claimAge*12)
+}
+
+// ClaimingFactor is the share of the full retirement age benefit paid when
+// claiming at claimMonths of age, given full retirement age in months
+func ClaimingFactor(fraMonths, claimMonths int) float64 {
+	if claimMonths < fraMonths {
+		monthsEarly := float64(fraMonths - claimMonths)
+		reduction := monthsEarly * ssEarlyReductionFirst36
+		if monthsEarly > 36 {
+			reduction = 36*ssEarlyReductionFirst36 + (monthsEarly-36)*ssEarlyReductionBeyond
+		}
+		return 1 - reduction
+	}
+
+	if claimMonths > ssMaxCreditAge*12 {
+		claimMonths = ssMaxCreditAge * 12
+	}
+	if claimMonths > fraMonths {
+		return 1 + float64(claimMonths-fraMonths)*ssDelayedCreditPerMonth
+	}
+	return 1
+}
+
+// FullRetirementAgeMonths returns the Social Security full retirement age, in
+// months, for someone born in birthYear: 65 through 1937, rising two months a
+// year to 66 for 1943-1954, then again to 67 from 1960
+func FullRetirementAgeMonths(birthYear int) int {
+	switch {
+	case birthYear <= 1937:
+		return 65 * 12
+	case birthYear <= 1942:
+		return 65*12 + (birthYear-1937)*2
+	case birthYear <= 1954:
+		return 66 * 12
+	case birthYear <= 1959:
+		return 66*12 + (birthYear-1954)*2
+	default:
+		return 67 * 12
+	}
+}
+
+// EstimateAIME approximates Average Indexed Monthly Earnings for a steady
+// career earning annualIncome (in today's dollars, capped at the wage base)
+// for yearsWorked years. Years short of 35 count as zero earnings.
+func EstimateAIME(annualIncome, wageBase float64, yearsWorked int) float64 {
+	years := math.Min(float64(yearsWorked), ssComputationYears)
+	if years <= 0 || annualIncome <= 0 {
+		return 0
+	}
+	return math.Min(annualIncome, wageBase) * years / (ssComputationYears * 12)
+}
+
+// EstimatePIA applies the bend point formula to AIME, giving the monthly
+// benefit at full retirement age
+func EstimatePIA(aime float64) float64 {
+	switch {
+	case aime <= ssBendPoint1:
+		return aime * 0.90
+	case aime <= ssBendPoint2:
+		return ssBendPoint1*0.90 + (aime-ssBendPoint1)*0.32
+	default:
+		return ssBendPoint1*0.90 + (ssBendPoint2-ssBendPoint1)*0.32 + (aime-ssBendPoint2)*0.15
+	}
+}
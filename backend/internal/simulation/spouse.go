@@ -0,0 +1,49 @@
+package simulation
+
+import "github.com/finviz/backend/internal/models"
+
+// spouseIncome tracks a working spouse's contributions and Social Security
+// through one simulation. A nil *spouseIncome contributes nothing, so the
+// simulation loop can call it unconditionally.
+type spouseIncome struct {
+	params   *models.SpouseParams
+	ssAge    int
+	cola     float64
+	monthly  float64 // current monthly contribution (grows with SpouseContributionGrowth)
+	ssAnnual float64 // current annual benefit (grows with COLA once claimed)
+}
+
+func newSpouseIncome(p *models.SpouseParams, ssAge int, cola float64) *spouseIncome {
+	if p == nil {
+		return nil
+	}
+	return &spouseIncome{
+		params:   p,
+		ssAge:    ssAge,
+		cola:     cola,
+		monthly:  p.SpouseMonthlyContribution,
+		ssAnnual: p.SpouseSocialSecurityAmount * 12,
+	}
+}
+
+// next returns the spouse's contribution and Social Security for a
+// simulation year and advances the raise and COLA for the following year
+func (s *spouseIncome) next(year int) (contribution, socialSecurity float64) {
+	if s == nil {
+		return 0, 0
+	}
+
+	age := s.params.SpouseCurrentAge + year
+	if age < s.params.SpouseRetirementAge {
+		contribution = s.monthly * 12
+		s.monthly *= 1 + *s.params.SpouseContributionGrowth
+	}
+	if age >= s.ssAge && s.ssAnnual > 0 {
+		// COLA applies from the second year of benefits
+		if age > s.ssAge {
+			s.ssAnnual *= 1 + s.cola
+		}
+		socialSecurity = s.ssAnnual
+	}
+	return contribution, socialSecurity
+}
@@ -0,0 +1,82 @@
+// Package snapshots records a daily history of each user's net worth, which
+// otherwise only exists as the current sum of their assets and debts.
+package snapshots
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/finviz/backend/internal/db"
+	"github.com/finviz/backend/internal/models"
+)
+
+// DailySchedule runs the snapshot just before midnight so each row holds the
+// day's closing values (cron format, server local time)
+const DailySchedule = "55 23 * * *"
+
+// TakeDailySnapshots records today's asset and debt totals for every user
+// with at least one asset or debt. Running it again the same day replaces
+// that day's snapshot.
+func TakeDailySnapshots() {
+	n, err := recordSnapshots("")
+	if err != nil {
+		fmt.Printf("Error taking net worth snapshots: %v\n", err)
+		return
+	}
+	fmt.Printf("Net worth snapshots recorded (%d rows affected)\n", n)
+}
+
+// TakeUserSnapshot records (or replaces) today's snapshot for one user, so
+// the history reflects a change without waiting for the nightly run
+func TakeUserSnapshot(userID int) error {
+	_, err := recordSnapshots("AND u.id = ?", userID)
+	return err
+}
+
+// recordSnapshots upserts today's totals for users with assets or debts,
+// narrowed by an optional extra condition on u
+func recordSnapshots(filter string, args ...interface{}) (int64, error) {
+	result, err := db.DB.Exec(`
+		INSERT INTO net_worth_history (user_id, total_assets, total_debts, net_worth, snapshot_date)
+		SELECT u.id, COALESCE(a.total, 0), COALESCE(d.total, 0), COALESCE(a.total, 0) - COALESCE(d.total, 0), CURDATE()
+		FROM users u
+		LEFT JOIN (SELECT user_id, SUM(current_value) AS total FROM assets WHERE deleted_at IS NULL GROUP BY user_id) a ON a.user_id = u.id
+		LEFT JOIN (SELECT user_id, SUM(current_balance) AS total FROM debts GROUP BY user_id) d ON d.user_id = u.id
+		WHERE (a.user_id IS NOT NULL OR d.user_id IS NOT NULL) `+filter+`
+		ON DUPLICATE KEY UPDATE
+			total_assets = VALUES(total_assets),
+			total_debts = VALUES(total_debts),
+			net_worth = VALUES(net_worth)
+	`, args...)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// History returns a user's snapshots on or after since, oldest first. A zero
+// since returns the full history.
+func History(userID int, since time.Time) ([]models.NetWorthSnapshot, error) {
+	rows, err := db.DB.Query(`
+		SELECT snapshot_date, total_assets, total_debts, net_worth
+		FROM net_worth_history
+		WHERE user_id = ? AND snapshot_date >= ?
+		ORDER BY snapshot_date
+	`, userID, since.Format("2006-01-02"))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	history := []models.NetWorthSnapshot{}
+	for rows.Next() {
+		var s models.NetWorthSnapshot
+		var date time.Time
+		if err := rows.Scan(&date, &s.TotalAssets, &s.TotalDebts, &s.NetWorth); err != nil {
+			return nil, err
+		}
+		s.Date = date.Format("2006-01-02")
+		history = append(history, s)
+	}
+	return history, rows.Err()
+}
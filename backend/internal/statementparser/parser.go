@@ -0,0 +1,277 @@
+package statementparser
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/finviz/backend/internal/taxparser"
+)
+
+// Institution identifies the bank that issued a statement
+type Institution string
+
+const (
+	InstitutionChase         Institution = "chase"
+	InstitutionWellsFargo    Institution = "wells_fargo"
+	InstitutionBankOfAmerica Institution = "bank_of_america"
+	InstitutionUnknown       Institution = "unknown"
+)
+
+// Transaction is a single parsed statement line. Amount follows the Plaid
+// convention: positive = money out (expense), negative = money in (income).
+type Transaction struct {
+	Date    string  `json:"date"` // YYYY-MM-DD
+	Name    string  `json:"name"`
+	Amount  float64 `json:"amount"`
+	Pending bool    `json:"pending"`
+}
+
+// ParsedStatement contains transactions extracted from a bank statement
+type ParsedStatement struct {
+	Institution  Institution   `json:"institution"`
+	Transactions []Transaction `json:"transactions"`
+	ParseErrors  []string      `json:"parse_errors,omitempty"`
+}
+
+// statementFormat describes how one institution lays out transaction rows
+type statementFormat struct {
+	institution Institution
+	markers     []string       // upper-case header text identifying the bank
+	line        *regexp.Regexp // groups: date, description, amount
+	// signedAmounts is true when debits carry a minus sign; otherwise the
+	// direction comes from the statement section the row appears in
+	signedAmounts bool
+}
+
+// amountPattern matches 1,234.56 / -1,234.56 / $1,234.56 / -$1,234.56
+const amountPattern = `-?\$?[\d,]+\.\d{2}`
+
+var formats = []statementFormat{
+	{
+		// 01/15 CARD PURCHASE 01/14 STARBUCKS  -5.75  1,234.56
+		institution:   InstitutionChase,
+		markers:       []string{"JPMORGAN CHASE", "CHASE.COM", "CHASE BANK"},
+		line:          regexp.MustCompile(`^(\d{2}/\d{2})\s+(.+?)\s+(` + amountPattern + `)(?:\s+` + amountPattern + `)?$`),
+		signedAmounts: true,
+	},
+	{
+		// 1/15 Purchase authorized on 01/14 Safeway  45.67  1,234.56
+		institution:   InstitutionWellsFargo,
+		markers:       []string{"WELLS FARGO"},
+		line:          regexp.MustCompile(`^(\d{1,2}/\d{1,2})\s+(.+?)\s+(\$?[\d,]+\.\d{2})(?:\s+\$?[\d,]+\.\d{2})?$`),
+		signedAmounts: false,
+	},
+	{
+		// 01/15/24 CHECKCARD 0114 TARGET  -45.67
+		institution:   InstitutionBankOfAmerica,
+		markers:       []string{"BANK OF AMERICA", "BANKOFAMERICA.COM"},
+		line:          regexp.MustCompile(`^(\d{2}/\d{2}/\d{2,4})\s+(.+?)\s+(` + amountPattern + `)$`),
+		signedAmounts: true,
+	},
+}
+
+// genericFormat is used when no institution is recognized
+var genericFormat = statementFormat{
+	institution:   InstitutionUnknown,
+	line:          regexp.MustCompile(`^(\d{1,2}/\d{1,2}(?:/\d{2,4})?)\s+(.+?)\s+(` + amountPattern + `)(?:\s+` + amountPattern + `)?$`),
+	signedAmounts: true,
+}
+
+// Section headings that tell us which way unsigned amounts move
+var (
+	creditSectionRegex = regexp.MustCompile(`(?i)deposits?(?:\s+and\s+other|/)\s*(?:additions|credits)|^credits$`)
+	debitSectionRegex  = regexp.MustCompile(`(?i)withdrawals?(?:\s+and\s+other|/)\s*(?:subtractions|debits)|checks\s+paid|atm\s+and\s+debit\s+card|electronic\s+withdrawals|^debits$`)
+)
+
+// Descriptions that indicate money coming in when the section is unknown
+var creditKeywords = []string{"DEPOSIT", "PAYROLL", "DIRECT DEP", "TRANSFER FROM", "INTEREST PAYMENT", "REFUND", "ZELLE FROM"}
+
+// Statement period, e.g. "January 1, 2024 through January 31, 2024"
+var longDateRegex = regexp.MustCompile(`(?i)(January|February|March|April|May|June|July|August|September|October|November|December)\s+(\d{1,2}),?\s+(\d{4})`)
+
+// ParsePDFContent extracts text from a PDF bank statement and parses its transactions
+func ParsePDFContent(pdfBytes []byte) (*ParsedStatement, error) {
+	text, err := taxparser.ExtractText(pdfBytes)
+	if err != nil {
+		return nil, err
+	}
+	return ParseText(text), nil
+}
+
+// ParseText parses transactions from the plain text of a bank statement
+func ParseText(text string) *ParsedStatement {
+	format := detectFormat(text)
+	result := &ParsedStatement{
+		Institution:  format.institution,
+		Transactions: []Transaction{},
+	}
+
+	periodEnd, hasPeriod := statementPeriodEnd(text)
+	if !hasPeriod {
+		result.ParseErrors = append(result.ParseErrors, "Could not find statement period; assuming the current year for dates without a year")
+		periodEnd = time.Now()
+	}
+
+	inCredits := false
+	inDebits := false
+	for _, rawLine := range strings.Split(text, "\n") {
+		line := strings.Join(strings.Fields(rawLine), " ")
+		if line == "" {
+			continue
+		}
+
+		// Track the current section for formats with unsigned amounts
+		if creditSectionRegex.MatchString(line) {
+			inCredits, inDebits = true, false
+			continue
+		}
+		if debitSectionRegex.MatchString(line) {
+			inCredits, inDebits = false, true
+			continue
+		}
+
+		match := format.line.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+
+		date, err := resolveDate(match[1], periodEnd)
+		if err != nil {
+			result.ParseErrors = append(result.ParseErrors, fmt.Sprintf("Invalid date %q in line: %s", match[1], line))
+			continue
+		}
+
+		amount, err := parseAmount(match[3])
+		if err != nil {
+			result.ParseErrors = append(result.ParseErrors, fmt.Sprintf("Invalid amount %q in line: %s", match[3], line))
+			continue
+		}
+
+		name := strings.TrimSpace(match[2])
+
+		// Statements show debits as negative; Plaid stores expenses as positive
+		if format.signedAmounts {
+			amount = -amount
+		} else {
+			isCredit := inCredits
+			if !inCredits && !inDebits {
+				isCredit = looksLikeCredit(name)
+			}
+			if isCredit {
+				amount = -amount
+			}
+		}
+
+		result.Transactions = append(result.Transactions, Transaction{
+			Date:    date.Format("2006-01-02"),
+			Name:    name,
+			Amount:  amount,
+			Pending: false,
+		})
+	}
+
+	if len(result.Transactions) == 0 {
+		result.ParseErrors = append(result.ParseErrors, "No transactions found in statement")
+	}
+
+	return result
+}
+
+// detectFormat picks the institution format from the statement header
+func detectFormat(text string) statementFormat {
+	header := text
+	if len(header) > 2000 {
+		header = header[:2000]
+	}
+	header = strings.ToUpper(header)
+
+	for _, f := range formats {
+		for _, marker := range f.markers {
+			if strings.Contains(header, marker) {
+				return f
+			}
+		}
+	}
+	return genericFormat
+}
+
+// statementPeriodEnd returns the latest long-form date in the statement header,
+// which is the end of the statement period
+func statementPeriodEnd(text string) (time.Time, bool) {
+	header := text
+	if len(header) > 3000 {
+		header = header[:3000]
+	}
+
+	var latest time.Time
+	for _, m := range longDateRegex.FindAllStringSubmatch(header, -1) {
+		t, err := time.Parse("January 2 2006", m[1]+" "+m[2]+" "+m[3])
+		if err != nil {
+			continue
+		}
+		if t.After(latest) {
+			latest = t
+		}
+	}
+	return latest, !latest.IsZero()
+}
+
+// resolveDate parses MM/DD, MM/DD/YY or MM/DD/YYYY. Dates without a year take
+// the statement year, rolling back a year for December rows on a January statement.
+func resolveDate(raw string, periodEnd time.Time) (time.Time, error) {
+	parts := strings.Split(raw, "/")
+	if len(parts) < 2 {
+		return time.Time{}, fmt.Errorf("invalid date")
+	}
+	month, err := strconv.Atoi(parts[0])
+	if err != nil || month < 1 || month > 12 {
+		return time.Time{}, fmt.Errorf("invalid month")
+	}
+	day, err := strconv.Atoi(parts[1])
+	if err != nil || day < 1 || day > 31 {
+		return time.Time{}, fmt.Errorf("invalid day")
+	}
+
+	var year int
+	if len(parts) == 3 {
+		year, err = strconv.Atoi(parts[2])
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid year")
+		}
+		if year < 100 {
+			year += 2000
+		}
+	} else {
+		year = periodEnd.Year()
+		if time.Month(month) > periodEnd.Month() {
+			year--
+		}
+	}
+
+	date := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+	if date.Day() != day {
+		return time.Time{}, fmt.Errorf("invalid day for month")
+	}
+	return date, nil
+}
+
+// parseAmount parses a statement amount, keeping its sign
+func parseAmount(raw string) (float64, error) {
+	cleaned := strings.ReplaceAll(raw, "$", "")
+	cleaned = strings.ReplaceAll(cleaned, ",", "")
+	return strconv.ParseFloat(cleaned, 64)
+}
+
+// looksLikeCredit guesses whether an unsigned row is money coming in
+func looksLikeCredit(description string) bool {
+	upper := strings.ToUpper(description)
+	for _, kw := range creditKeywords {
+		if strings.Contains(upper, kw) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,180 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// Timeout for a single S3 request
+const s3RequestTimeout = 60 * time.Second
+
+// Health checks need an answer quickly
+const s3PingTimeout = 5 * time.Second
+
+// S3Storage implements Storage for an S3 (or S3-compatible) bucket.
+// Encryption happens client-side with the same AES-GCM scheme as local storage,
+// so files can move between backends without re-encrypting. With
+// ServerSideEncryption set, S3 encrypts objects at rest instead, and those
+// objects can be downloaded directly through presigned URLs.
+type S3Storage struct {
+	Client               *s3.Client
+	Presigner            *s3.PresignClient
+	Bucket               string
+	EncryptionKey        []byte
+	ServerSideEncryption bool
+}
+
+// NewS3Storage creates S3 storage configured from S3_BUCKET, AWS_REGION,
+// AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY. S3_ENDPOINT optionally points
+// at an S3-compatible service such as MinIO. S3_SERVER_SIDE_ENCRYPTION=true
+// switches new uploads from client-side to server-side encryption.
+func NewS3Storage(encryptionKeyStr string) (*S3Storage, error) {
+	bucket := os.Getenv("S3_BUCKET")
+	if bucket == "" {
+		return nil, fmt.Errorf("S3_BUCKET is required for s3 storage")
+	}
+
+	opts := []func(*config.LoadOptions) error{}
+	if region := os.Getenv("AWS_REGION"); region != "" {
+		opts = append(opts, config.WithRegion(region))
+	}
+	if keyID, secret := os.Getenv("AWS_ACCESS_KEY_ID"), os.Getenv("AWS_SECRET_ACCESS_KEY"); keyID != "" && secret != "" {
+		opts = append(opts, config.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(keyID, secret, "")))
+	}
+
+	cfg, err := config.LoadDefaultConfig(context.Background(), opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+
+	client := s3.NewFromConfig(cfg, func(o *s3.Options) {
+		if endpoint := os.Getenv("S3_ENDPOINT"); endpoint != "" {
+			o.BaseEndpoint = aws.String(endpoint)
+			o.UsePathStyle = true // MinIO and most S3-compatible services need path-style addressing
+		}
+	})
+
+	return &S3Storage{
+		Client:               client,
+		Presigner:            s3.NewPresignClient(client),
+		Bucket:               bucket,
+		EncryptionKey:        deriveKey(encryptionKeyStr),
+		ServerSideEncryption: os.Getenv("S3_SERVER_SIDE_ENCRYPTION") == "true",
+	}, nil
+}
+
+// Save uploads a file with optional encryption and returns its object key.
+// With ServerSideEncryption the object is stored as-is and S3 encrypts it.
+func (s *S3Storage) Save(data []byte, filename string, encrypt bool) (string, error) {
+	key := uniqueStoragePath(filename)
+
+	input := &s3.PutObjectInput{
+		Bucket: aws.String(s.Bucket),
+		Key:    aws.String(key),
+	}
+
+	body := data
+	if encrypt && s.ServerSideEncryption {
+		input.ServerSideEncryption = types.ServerSideEncryptionAes256
+	} else if encrypt {
+		var err error
+		body, err = encryptData(s.EncryptionKey, data)
+		if err != nil {
+			return "", fmt.Errorf("encryption failed: %w", err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s3RequestTimeout)
+	defer cancel()
+
+	input.Body = bytes.NewReader(body)
+	input.ContentLength = aws.Int64(int64(len(body)))
+	_, err := s.Client.PutObject(ctx, input)
+	if err != nil {
+		return "", fmt.Errorf("failed to upload file: %w", err)
+	}
+
+	return key, nil
+}
+
+// Load downloads a file with optional decryption
+func (s *S3Storage) Load(path string, encrypted bool) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s3RequestTimeout)
+	defer cancel()
+
+	out, err := s.Client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.Bucket),
+		Key:    aws.String(path),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	defer out.Body.Close()
+
+	data, err := io.ReadAll(out.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	if encrypted {
+		decrypted, err := decryptData(s.EncryptionKey, data)
+		if err != nil {
+			return nil, fmt.Errorf("decryption failed: %w", err)
+		}
+		return decrypted, nil
+	}
+
+	return data, nil
+}
+
+// Delete removes an object from the bucket
+func (s *S3Storage) Delete(path string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), s3RequestTimeout)
+	defer cancel()
+
+	_, err := s.Client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(s.Bucket),
+		Key:    aws.String(path),
+	})
+	return err
+}
+
+// GetURL returns empty - objects are private and downloads go through the API
+func (s *S3Storage) GetURL(path string) string {
+	return ""
+}
+
+// Ping checks the bucket exists and the credentials can reach it
+func (s *S3Storage) Ping() error {
+	ctx, cancel := context.WithTimeout(context.Background(), s3PingTimeout)
+	defer cancel()
+
+	_, err := s.Client.HeadBucket(ctx, &s3.HeadBucketInput{Bucket: aws.String(s.Bucket)})
+	return err
+}
+
+// Presign returns a GET URL for an object, valid for expirySeconds. Objects
+// encrypted client-side come back as ciphertext, so only presign plain ones.
+func (s *S3Storage) Presign(storagePath string, expirySeconds int) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s3RequestTimeout)
+	defer cancel()
+
+	req, err := s.Presigner.PresignGetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.Bucket),
+		Key:    aws.String(storagePath),
+	}, s3.WithPresignExpires(time.Duration(expirySeconds)*time.Second))
+	if err != nil {
+		return "", fmt.Errorf("failed to presign URL: %w", err)
+	}
+	return req.URL, nil
+}
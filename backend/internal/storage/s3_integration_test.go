@@ -0,0 +1,134 @@
+//go:build integration
+
+// These tests run S3Storage against a real S3-compatible service. Start MinIO
+// and point the usual settings at it, then run with the integration tag:
+//
+//	docker run -d -p 9000:9000 minio/minio server /data
+//	S3_ENDPOINT=http://localhost:9000 S3_BUCKET=finviz-test AWS_REGION=us-east-1 \
+//	AWS_ACCESS_KEY_ID=minioadmin AWS_SECRET_ACCESS_KEY=minioadmin \
+//	go test -tags integration ./internal/storage
+//
+// The bucket is created if it doesn't exist.
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// newTestS3Storage connects to the service in S3_ENDPOINT, skipping the test
+// when it isn't set
+func newTestS3Storage(t *testing.T) *S3Storage {
+	t.Helper()
+	if os.Getenv("S3_ENDPOINT") == "" {
+		t.Skip("S3_ENDPOINT not set")
+	}
+
+	s, err := NewS3Storage("integration-test-key")
+	if err != nil {
+		t.Fatalf("NewS3Storage: %v", err)
+	}
+
+	_, err = s.Client.CreateBucket(context.Background(), &s3.CreateBucketInput{Bucket: aws.String(s.Bucket)})
+	var owned *types.BucketAlreadyOwnedByYou
+	var exists *types.BucketAlreadyExists
+	if err != nil && !errors.As(err, &owned) && !errors.As(err, &exists) {
+		t.Fatalf("creating bucket %s: %v", s.Bucket, err)
+	}
+	return s
+}
+
+func TestS3StorageRoundTrip(t *testing.T) {
+	s := newTestS3Storage(t)
+	data := []byte("2024 W-2 wages: 85,000.00")
+
+	for _, encrypt := range []bool{false, true} {
+		key, err := s.Save(data, "w2.pdf", encrypt)
+		if err != nil {
+			t.Fatalf("Save(encrypt=%v): %v", encrypt, err)
+		}
+		t.Cleanup(func() { s.Delete(key) })
+
+		got, err := s.Load(key, encrypt)
+		if err != nil {
+			t.Fatalf("Load(encrypt=%v): %v", encrypt, err)
+		}
+		if !bytes.Equal(got, data) {
+			t.Errorf("Load(encrypt=%v) = %q, want %q", encrypt, got, data)
+		}
+
+		raw, err := s.Load(key, false)
+		if err != nil {
+			t.Fatalf("Load raw: %v", err)
+		}
+		if encrypt && bytes.Equal(raw, data) {
+			t.Error("client-side encrypted object was stored as plaintext")
+		}
+	}
+}
+
+func TestS3StorageDelete(t *testing.T) {
+	s := newTestS3Storage(t)
+
+	key, err := s.Save([]byte("statement"), "statement.pdf", true)
+	if err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if err := s.Delete(key); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := s.Load(key, true); err == nil {
+		t.Error("Load after Delete succeeded")
+	}
+}
+
+func TestS3StoragePing(t *testing.T) {
+	s := newTestS3Storage(t)
+	if err := s.Ping(); err != nil {
+		t.Errorf("Ping: %v", err)
+	}
+
+	missing := *s
+	missing.Bucket = s.Bucket + "-missing"
+	if err := missing.Ping(); err == nil {
+		t.Error("Ping succeeded for a bucket that doesn't exist")
+	}
+}
+
+func TestS3StoragePresign(t *testing.T) {
+	s := newTestS3Storage(t)
+	data := []byte("shared document")
+
+	key, err := s.Save(data, "shared.pdf", false)
+	if err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	t.Cleanup(func() { s.Delete(key) })
+
+	url, err := s.Presign(key, 60)
+	if err != nil {
+		t.Fatalf("Presign: %v", err)
+	}
+
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("GET presigned URL: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("GET presigned URL: status %d", resp.StatusCode)
+	}
+	got, _ := io.ReadAll(resp.Body)
+	if !bytes.Equal(got, data) {
+		t.Errorf("presigned download = %q, want %q", got, data)
+	}
+}
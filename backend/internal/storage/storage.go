@@ -12,6 +12,12 @@ import (
 	"time"
 )
 
+// Storage backend names for STORAGE_BACKEND
+const (
+	BackendLocal = "local"
+	BackendS3    = "s3"
+)
+
 // Storage interface for file operations
 type Storage interface {
 	// Save stores a file and returns the storage path
@@ -22,50 +28,49 @@ type Storage interface {
 	Delete(path string) error
 	// GetURL returns a download URL (for S3) or empty for local
 	GetURL(path string) string
+	// Ping checks the backend is reachable, for health checks
+	Ping() error
+}
+
+// PresignedURLStorage is implemented by backends that can hand out short-lived
+// URLs for downloading a file directly, bypassing the API server
+type PresignedURLStorage interface {
+	// Presign returns a URL for the file that expires after expirySeconds
+	Presign(storagePath string, expirySeconds int) (string, error)
 }
 
-// LocalStorage implements Storage for local filesystem
-type LocalStorage struct {
+// LocalEncryptedStorage implements Storage for local filesystem
+type LocalEncryptedStorage struct {
 	BasePath      string
 	EncryptionKey []byte
 }
 
-// NewLocalStorage creates a new local storage instance
-func NewLocalStorage(basePath string, encryptionKeyStr string) (*LocalStorage, error) {
+// NewLocalEncryptedStorage creates a new local storage instance
+func NewLocalEncryptedStorage(basePath string, encryptionKeyStr string) (*LocalEncryptedStorage, error) {
 	// Create base directory if it doesn't exist
 	if err := os.MkdirAll(basePath, 0755); err != nil {
 		return nil, fmt.Errorf("failed to create storage directory: %w", err)
 	}
 
-	// Derive encryption key from string using SHA-256
-	key := sha256.Sum256([]byte(encryptionKeyStr))
-
-	return &LocalStorage{
+	return &LocalEncryptedStorage{
 		BasePath:      basePath,
-		EncryptionKey: key[:],
+		EncryptionKey: deriveKey(encryptionKeyStr),
 	}, nil
 }
 
 // Save stores a file with optional encryption
-func (s *LocalStorage) Save(data []byte, filename string, encrypt bool) (string, error) {
-	// Generate unique path based on date and random suffix
-	now := time.Now()
-	dirPath := filepath.Join(s.BasePath, fmt.Sprintf("%d/%02d", now.Year(), now.Month()))
+func (s *LocalEncryptedStorage) Save(data []byte, filename string, encrypt bool) (string, error) {
+	relPath := uniqueStoragePath(filename)
+	fullPath := filepath.Join(s.BasePath, relPath)
 
-	if err := os.MkdirAll(dirPath, 0755); err != nil {
+	if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
 		return "", fmt.Errorf("failed to create directory: %w", err)
 	}
 
-	// Generate unique filename
-	randBytes := make([]byte, 8)
-	rand.Read(randBytes)
-	uniqueName := fmt.Sprintf("%d_%x_%s", now.UnixNano(), randBytes, sanitizeFilename(filename))
-	fullPath := filepath.Join(dirPath, uniqueName)
-
 	var dataToWrite []byte
 	if encrypt {
 		var err error
-		dataToWrite, err = s.encrypt(data)
+		dataToWrite, err = encryptData(s.EncryptionKey, data)
 		if err != nil {
 			return "", fmt.Errorf("encryption failed: %w", err)
 		}
@@ -78,12 +83,11 @@ func (s *LocalStorage) Save(data []byte, filename string, encrypt bool) (string,
 	}
 
 	// Return relative path from base
-	relPath, _ := filepath.Rel(s.BasePath, fullPath)
 	return relPath, nil
 }
 
 // Load retrieves a file with optional decryption
-func (s *LocalStorage) Load(path string, encrypted bool) ([]byte, error) {
+func (s *LocalEncryptedStorage) Load(path string, encrypted bool) ([]byte, error) {
 	fullPath := filepath.Join(s.BasePath, path)
 
 	data, err := os.ReadFile(fullPath)
@@ -92,7 +96,7 @@ func (s *LocalStorage) Load(path string, encrypted bool) ([]byte, error) {
 	}
 
 	if encrypted {
-		decrypted, err := s.decrypt(data)
+		decrypted, err := decryptData(s.EncryptionKey, data)
 		if err != nil {
 			return nil, fmt.Errorf("decryption failed: %w", err)
 		}
@@ -103,19 +107,44 @@ func (s *LocalStorage) Load(path string, encrypted bool) ([]byte, error) {
 }
 
 // Delete removes a file
-func (s *LocalStorage) Delete(path string) error {
+func (s *LocalEncryptedStorage) Delete(path string) error {
 	fullPath := filepath.Join(s.BasePath, path)
 	return os.Remove(fullPath)
 }
 
 // GetURL returns empty for local storage (direct file access)
-func (s *LocalStorage) GetURL(path string) string {
+func (s *LocalEncryptedStorage) GetURL(path string) string {
 	return ""
 }
 
-// encrypt data using AES-GCM
-func (s *LocalStorage) encrypt(plaintext []byte) ([]byte, error) {
-	block, err := aes.NewCipher(s.EncryptionKey)
+// Ping checks the storage directory can be written to
+func (s *LocalEncryptedStorage) Ping() error {
+	f, err := os.CreateTemp(s.BasePath, ".ping-*")
+	if err != nil {
+		return fmt.Errorf("storage directory not writable: %w", err)
+	}
+	f.Close()
+	return os.Remove(f.Name())
+}
+
+// deriveKey derives the AES-256 encryption key from a configured string using SHA-256
+func deriveKey(encryptionKeyStr string) []byte {
+	key := sha256.Sum256([]byte(encryptionKeyStr))
+	return key[:]
+}
+
+// uniqueStoragePath builds a unique relative path based on date and random suffix
+func uniqueStoragePath(filename string) string {
+	now := time.Now()
+	randBytes := make([]byte, 8)
+	rand.Read(randBytes)
+	uniqueName := fmt.Sprintf("%d_%x_%s", now.UnixNano(), randBytes, sanitizeFilename(filename))
+	return filepath.ToSlash(filepath.Join(fmt.Sprintf("%d/%02d", now.Year(), now.Month()), uniqueName))
+}
+
+// encryptData encrypts data using AES-GCM
+func encryptData(key, plaintext []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
 	if err != nil {
 		return nil, err
 	}
@@ -134,9 +163,9 @@ func (s *LocalStorage) encrypt(plaintext []byte) ([]byte, error) {
 	return ciphertext, nil
 }
 
-// decrypt data using AES-GCM
-func (s *LocalStorage) decrypt(ciphertext []byte) ([]byte, error) {
-	block, err := aes.NewCipher(s.EncryptionKey)
+// decryptData decrypts data using AES-GCM
+func decryptData(key, ciphertext []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
 	if err != nil {
 		return nil, err
 	}
@@ -183,12 +212,34 @@ func sanitizeFilename(name string) string {
 // Global storage instance
 var DefaultStorage Storage
 
-// InitStorage initializes the default storage
+// EncryptsClientSide reports whether DefaultStorage encrypts files before
+// storing them. S3 storage with server-side encryption stores plain objects,
+// which is what lets them be downloaded through presigned URLs.
+func EncryptsClientSide() bool {
+	if s, ok := DefaultStorage.(*S3Storage); ok {
+		return !s.ServerSideEncryption
+	}
+	return true
+}
+
+// InitStorage initializes the default storage, selecting the backend from
+// STORAGE_BACKEND ("local" or "s3", default local)
 func InitStorage(basePath string, encryptionKey string) error {
-	storage, err := NewLocalStorage(basePath, encryptionKey)
+	var (
+		store Storage
+		err   error
+	)
+	switch backend := os.Getenv("STORAGE_BACKEND"); backend {
+	case "", BackendLocal:
+		store, err = NewLocalEncryptedStorage(basePath, encryptionKey)
+	case BackendS3:
+		store, err = NewS3Storage(encryptionKey)
+	default:
+		return fmt.Errorf("unknown STORAGE_BACKEND %q (use 'local' or 's3')", backend)
+	}
 	if err != nil {
 		return err
 	}
-	DefaultStorage = storage
+	DefaultStorage = store
 	return nil
 }
@@ -0,0 +1,104 @@
+package taxcalc
+
+import (
+	"math"
+	"time"
+)
+
+// EstimatedTaxInput contains the inputs for a quarterly estimated tax calculation
+type EstimatedTaxInput struct {
+	AnnualNetIncome float64 // Net self-employment income
+	FilingStatus    string
+	OtherIncome     float64 // Wages, interest, etc.
+	Withholding     float64 // Projected federal withholding for the year
+	PriorYearTax    float64 // Optional: total prior-year tax for the safe harbor test
+	TaxYear         int
+}
+
+// EstimatedTaxResult is the breakdown of a quarterly estimated tax calculation
+type EstimatedTaxResult struct {
+	SelfEmploymentTax float64
+	QBIDeduction      float64
+	TaxableIncome     float64
+	IncomeTax         float64
+	TotalAnnualTax    float64
+	EffectiveRate     float64 // Percentage of total income
+	QuarterlyAmount   float64
+	DueDates          [4]time.Time
+	PenaltyRisk       bool
+}
+
+// CalculateEstimatedTax computes SE tax, income tax and the four quarterly payments
+func CalculateEstimatedTax(in EstimatedTaxInput) EstimatedTaxResult {
+	seTax := SelfEmploymentTax(in.AnnualNetIncome)
+
+	// Half of SE tax is an above-the-line deduction
+	agi := in.AnnualNetIncome + in.OtherIncome - seTax/2
+	if agi < 0 {
+		agi = 0
+	}
+
+	taxableBeforeQBI := agi - StandardDeduction(in.FilingStatus)
+	if taxableBeforeQBI < 0 {
+		taxableBeforeQBI = 0
+	}
+
+	// QBI: 20% of qualified business income, limited to 20% of taxable income
+	qbi := in.AnnualNetIncome - seTax/2
+	if qbi < 0 {
+		qbi = 0
+	}
+	qbiDeduction := math.Min(qbi*QBIDeductionRate, taxableBeforeQBI*QBIDeductionRate)
+
+	taxableIncome := taxableBeforeQBI - qbiDeduction
+	incomeTax := FederalIncomeTax(taxableIncome, in.FilingStatus)
+	totalTax := incomeTax + seTax
+
+	effectiveRate := 0.0
+	if totalIncome := in.AnnualNetIncome + in.OtherIncome; totalIncome > 0 {
+		effectiveRate = totalTax / totalIncome * 100
+	}
+
+	quarterly := (totalTax - in.Withholding) / 4
+	if quarterly < 0 {
+		quarterly = 0
+	}
+
+	// Safe harbor: withholding covers 90% of this year's tax or 100% of last year's
+	penaltyRisk := in.Withholding < totalTax*0.90
+	if penaltyRisk && in.PriorYearTax > 0 && in.Withholding >= in.PriorYearTax {
+		penaltyRisk = false
+	}
+
+	return EstimatedTaxResult{
+		SelfEmploymentTax: seTax,
+		QBIDeduction:      qbiDeduction,
+		TaxableIncome:     taxableIncome,
+		IncomeTax:         incomeTax,
+		TotalAnnualTax:    totalTax,
+		EffectiveRate:     effectiveRate,
+		QuarterlyAmount:   quarterly,
+		DueDates:          EstimatedPaymentDueDates(in.TaxYear),
+		PenaltyRisk:       penaltyRisk,
+	}
+}
+
+// EstimatedPaymentDueDates returns the IRS due dates for a tax year's quarterly
+// payments, rolled forward to Monday when they fall on a weekend
+func EstimatedPaymentDueDates(taxYear int) [4]time.Time {
+	dates := [4]time.Time{
+		time.Date(taxYear, time.April, 15, 0, 0, 0, 0, time.UTC),
+		time.Date(taxYear, time.June, 15, 0, 0, 0, 0, time.UTC),
+		time.Date(taxYear, time.September, 15, 0, 0, 0, 0, time.UTC),
+		time.Date(taxYear+1, time.January, 15, 0, 0, 0, 0, time.UTC),
+	}
+	for i, d := range dates {
+		switch d.Weekday() {
+		case time.Saturday:
+			dates[i] = d.AddDate(0, 0, 2)
+		case time.Sunday:
+			dates[i] = d.AddDate(0, 0, 1)
+		}
+	}
+	return dates
+}
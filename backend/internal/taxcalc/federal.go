@@ -0,0 +1,137 @@
+package taxcalc
+
+import "math"
+
+// Filing status constants
+const (
+	FilingSingle            = "single"
+	FilingMarriedJointly    = "married_filing_jointly"
+	FilingMarriedSeparately = "married_filing_separate"
+	FilingHeadOfHousehold   = "head_of_household"
+)
+
+// 2024 Social Security wage base and self-employment rates
+const (
+	SocialSecurityWageBase = 168600.0
+	SelfEmploymentTaxRate  = 0.153  // 12.4% Social Security + 2.9% Medicare
+	MedicareTaxRate        = 0.029  // Applies to all SE earnings
+	SelfEmploymentFactor   = 0.9235 // Portion of net earnings subject to SE tax
+	QBIDeductionRate       = 0.20
+)
+
+// Bracket is an upper bound and marginal rate for one tax bracket
+type Bracket struct {
+	UpTo float64
+	Rate float64
+}
+
+// 2024 federal income tax brackets by filing status
+var federalBrackets = map[string][]Bracket{
+	FilingSingle: {
+		{11600, 0.10},
+		{47150, 0.12},
+		{100525, 0.22},
+		{191950, 0.24},
+		{243725, 0.32},
+		{609350, 0.35},
+		{math.MaxFloat64, 0.37},
+	},
+	FilingMarriedJointly: {
+		{23200, 0.10},
+		{94300, 0.12},
+		{201050, 0.22},
+		{383900, 0.24},
+		{487450, 0.32},
+		{731200, 0.35},
+		{math.MaxFloat64, 0.37},
+	},
+	FilingMarriedSeparately: {
+		{11600, 0.10},
+		{47150, 0.12},
+		{100525, 0.22},
+		{191950, 0.24},
+		{243725, 0.32},
+		{365600, 0.35},
+		{math.MaxFloat64, 0.37},
+	},
+	FilingHeadOfHousehold: {
+		{16550, 0.10},
+		{63100, 0.12},
+		{100500, 0.22},
+		{191950, 0.24},
+		{243700, 0.32},
+		{609350, 0.35},
+		{math.MaxFloat64, 0.37},
+	},
+}
+
+// 2024 standard deductions by filing status
+var standardDeductions = map[string]float64{
+	FilingSingle:            14600,
+	FilingMarriedJointly:    29200,
+	FilingMarriedSeparately: 14600,
+	FilingHeadOfHousehold:   21900,
+}
+
+// IsValidFilingStatus reports whether the filing status is supported
+func IsValidFilingStatus(status string) bool {
+	_, ok := federalBrackets[status]
+	return ok
+}
+
+// Brackets returns the federal brackets for a filing status (single if unknown)
+func Brackets(filingStatus string) []Bracket {
+	if b, ok := federalBrackets[filingStatus]; ok {
+		return b
+	}
+	return federalBrackets[FilingSingle]
+}
+
+// StandardDeduction returns the standard deduction for a filing status (single if unknown)
+func StandardDeduction(filingStatus string) float64 {
+	if d, ok := standardDeductions[filingStatus]; ok {
+		return d
+	}
+	return standardDeductions[FilingSingle]
+}
+
+// FederalIncomeTax applies the progressive brackets to taxable income
+func FederalIncomeTax(taxableIncome float64, filingStatus string) float64 {
+	if taxableIncome <= 0 {
+		return 0
+	}
+
+	var tax float64
+	lower := 0.0
+	for _, b := range Brackets(filingStatus) {
+		if taxableIncome <= lower {
+			break
+		}
+		tax += (math.Min(taxableIncome, b.UpTo) - lower) * b.Rate
+		lower = b.UpTo
+	}
+	return tax
+}
+
+// MarginalRate returns the marginal bracket rate for the given taxable income
+func MarginalRate(taxableIncome float64, filingStatus string) float64 {
+	for _, b := range Brackets(filingStatus) {
+		if taxableIncome <= b.UpTo {
+			return b.Rate
+		}
+	}
+	return 0.37
+}
+
+// SelfEmploymentTax computes SE tax on net self-employment income
+func SelfEmploymentTax(netIncome float64) float64 {
+	if netIncome <= 0 {
+		return 0
+	}
+	base := netIncome * SelfEmploymentFactor
+	tax := math.Min(base, SocialSecurityWageBase) * SelfEmploymentTaxRate
+	if base > SocialSecurityWageBase {
+		tax += (base - SocialSecurityWageBase) * MedicareTaxRate
+	}
+	return tax
+}
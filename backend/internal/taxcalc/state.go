@@ -0,0 +1,45 @@
+package taxcalc
+
+import "strings"
+
+// StateIncomeTaxRates holds a representative marginal income tax rate for
+// each state, by two-letter code, used to value state deductions such as
+// 529 plan contributions. States without an income tax are left out. It's a
+// variable so deployments can adjust rates without a code change.
+var StateIncomeTaxRates = map[string]float64{
+	"AL": 0.05,
+	"AR": 0.044,
+	"AZ": 0.025,
+	"CO": 0.0425,
+	"CT": 0.0699,
+	"GA": 0.0539,
+	"IA": 0.057,
+	"ID": 0.058,
+	"IL": 0.0495,
+	"KS": 0.057,
+	"LA": 0.0425,
+	"MA": 0.05,
+	"MD": 0.0575,
+	"MI": 0.0425,
+	"MO": 0.048,
+	"MS": 0.047,
+	"MT": 0.059,
+	"NE": 0.0584,
+	"NM": 0.059,
+	"NY": 0.0685,
+	"OH": 0.035,
+	"OK": 0.0475,
+	"OR": 0.099,
+	"RI": 0.0599,
+	"SC": 0.064,
+	"UT": 0.0465,
+	"VA": 0.0575,
+	"WI": 0.053,
+	"WV": 0.0512,
+}
+
+// StateIncomeTaxRate returns the rate for a state code, or 0 for states
+// without an income tax or not in the table
+func StateIncomeTaxRate(state string) float64 {
+	return StateIncomeTaxRates[strings.ToUpper(strings.TrimSpace(state))]
+}
@@ -17,6 +17,7 @@ const (
 	DocType1040    TaxDocumentType = "form_1040"
 	DocTypeW2      TaxDocumentType = "form_w2"
 	DocType1099    TaxDocumentType = "form_1099"
+	DocType8606    TaxDocumentType = "form_8606"
 	DocTypeUnknown TaxDocumentType = "unknown"
 )
 
@@ -51,37 +52,25 @@ type ExtractedTaxData struct {
 	IncomeType  string   `json:"income_type,omitempty"` // DIV, INT, MISC, NEC
 	GrossIncome *float64 `json:"gross_income,omitempty"`
 
+	// Form 8606 fields
+	TraditionalIRABasis     *float64 `json:"traditional_ira_basis,omitempty"`    // line 14: after-tax basis carried forward
+	TotalRothConversions    *float64 `json:"total_roth_conversions,omitempty"`   // line 16: amount converted to Roth
+	NontaxableDistributions *float64 `json:"nontaxable_distributions,omitempty"` // line 13: basis recovered this year
+
 	// Parsing metadata
 	RawText     string   `json:"-"` // For debugging, not returned
 	Confidence  float64  `json:"confidence"`
 	ParseErrors []string `json:"parse_errors,omitempty"`
+	ParseError  string   `json:"parse_error,omitempty"` // Set when the file could not be parsed at all (batch parsing)
 }
 
 // ParsePDFContent extracts and parses tax data from PDF bytes
 func ParsePDFContent(pdfBytes []byte) (*ExtractedTaxData, error) {
-	// Extract text from PDF
-	reader := bytes.NewReader(pdfBytes)
-	pdfReader, err := pdf.NewReader(reader, int64(len(pdfBytes)))
+	rawText, err := ExtractText(pdfBytes)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read PDF: %w", err)
+		return nil, err
 	}
 
-	var textBuilder strings.Builder
-	for pageNum := 1; pageNum <= pdfReader.NumPage(); pageNum++ {
-		page := pdfReader.Page(pageNum)
-		if page.V.IsNull() {
-			continue
-		}
-		text, err := page.GetPlainText(nil)
-		if err != nil {
-			continue
-		}
-		textBuilder.WriteString(text)
-		textBuilder.WriteString("\n---PAGE BREAK---\n")
-	}
-
-	rawText := textBuilder.String()
-
 	// Detect document type
 	docType := detectDocumentType(rawText)
 
@@ -94,6 +83,8 @@ func ParsePDFContent(pdfBytes []byte) (*ExtractedTaxData, error) {
 		data = parseW2(rawText)
 	case DocType1099:
 		data = parse1099(rawText)
+	case DocType8606:
+		data = parse8606(rawText)
 	default:
 		data = &ExtractedTaxData{
 			DocumentType: DocTypeUnknown,
@@ -106,9 +97,44 @@ func ParsePDFContent(pdfBytes []byte) (*ExtractedTaxData, error) {
 	return data, nil
 }
 
+// ExtractText returns the plain text of every page in a PDF, separated by page-break markers
+func ExtractText(pdfBytes []byte) (string, error) {
+	reader := bytes.NewReader(pdfBytes)
+	pdfReader, err := pdf.NewReader(reader, int64(len(pdfBytes)))
+	if err != nil {
+		return "", fmt.Errorf("failed to read PDF: %w", err)
+	}
+
+	var textBuilder strings.Builder
+	for pageNum := 1; pageNum <= pdfReader.NumPage(); pageNum++ {
+		page := pdfReader.Page(pageNum)
+		if page.V.IsNull() {
+			continue
+		}
+		text, err := page.GetPlainText(nil)
+		if err != nil {
+			continue
+		}
+		textBuilder.WriteString(text)
+		textBuilder.WriteString("\n---PAGE BREAK---\n")
+	}
+
+	return textBuilder.String(), nil
+}
+
+// LooksLikeTaxForm reports whether extracted text appears to be a 1040, W-2, 1099 or 8606
+func LooksLikeTaxForm(text string) bool {
+	return detectDocumentType(text) != DocTypeUnknown
+}
+
 func detectDocumentType(text string) TaxDocumentType {
 	textUpper := strings.ToUpper(text)
 
+	// Checked first: Form 8606 says "Attach to Form 1040"
+	if strings.Contains(textUpper, "FORM 8606") ||
+		strings.Contains(textUpper, "NONDEDUCTIBLE IRAS") {
+		return DocType8606
+	}
 	if strings.Contains(textUpper, "FORM 1040") ||
 		strings.Contains(textUpper, "U.S. INDIVIDUAL INCOME TAX RETURN") {
 		return DocType1040
@@ -405,6 +431,60 @@ func parse1099(text string) *ExtractedTaxData {
 	return data
 }
 
+// parse8606 extracts IRA basis and Roth conversion data from Form 8606
+func parse8606(text string) *ExtractedTaxData {
+	data := &ExtractedTaxData{
+		DocumentType: DocType8606,
+		Confidence:   0.5,
+	}
+
+	// Extract tax year
+	yearRegex := regexp.MustCompile(`(?i)(?:8606|tax\s*year)[^\d]*(\d{4})`)
+	if match := yearRegex.FindStringSubmatch(text); len(match) > 1 {
+		if year, err := strconv.Atoi(match[1]); err == nil && year >= 2018 && year <= 2030 {
+			data.TaxYear = year
+			data.Confidence += 0.1
+		}
+	}
+
+	// Line 14: Total basis in traditional IRAs - the key input for future withdrawals
+	data.TraditionalIRABasis = extractLineValue(text, []string{
+		`(?i)total\s*basis\s*in\s*traditional\s*IRAs[^\d]*(?:\d{4}[^\d]*)?\$?([\d,]+(?:\.\d{2})?)`,
+		`(?i)line\s*14[^\d]*\$?([\d,]+(?:\.\d{2})?)`,
+	})
+	if data.TraditionalIRABasis != nil {
+		data.Confidence += 0.15
+	}
+
+	// Line 16: Amount converted from traditional, SEP, and SIMPLE IRAs to Roth IRAs
+	data.TotalRothConversions = extractLineValue(text, []string{
+		`(?i)(?:amount\s*)?converted[^\d]*to\s*Roth\s*IRAs?[^\d]*\$?([\d,]+(?:\.\d{2})?)`,
+		`(?i)line\s*16[^\d]*\$?([\d,]+(?:\.\d{2})?)`,
+	})
+	if data.TotalRothConversions != nil {
+		data.Confidence += 0.15
+	}
+
+	// Line 13: Nontaxable portion of this year's distributions
+	data.NontaxableDistributions = extractLineValue(text, []string{
+		`(?i)nontaxable\s*portion\s*of\s*all\s*your\s*distributions[^\d]*\$?([\d,]+(?:\.\d{2})?)`,
+		`(?i)line\s*13[^\d]*\$?([\d,]+(?:\.\d{2})?)`,
+	})
+	if data.NontaxableDistributions != nil {
+		data.Confidence += 0.1
+	}
+
+	if data.TraditionalIRABasis == nil && data.TotalRothConversions == nil && data.NontaxableDistributions == nil {
+		data.ParseErrors = append(data.ParseErrors, "Could not find IRA basis or conversion amounts")
+	}
+
+	if data.Confidence > 1.0 {
+		data.Confidence = 1.0
+	}
+
+	return data
+}
+
 // extractLineValue tries multiple regex patterns and returns the first matching value
 func extractLineValue(text string, patterns []string) *float64 {
 	for _, pattern := range patterns {
@@ -0,0 +1,47 @@
+package taxparser
+
+import "github.com/finviz/backend/internal/models"
+
+// defaultSavingsRate is the share of income assumed to go to retirement
+// savings when estimating a monthly contribution from a tax return
+const defaultSavingsRate = 0.10
+
+// Rough effective federal rate in retirement by filing status. Retirement
+// income is usually lower than working income, so these sit a bracket or so
+// below a typical earner's marginal rate.
+var retirementTaxRates = map[string]float64{
+	"single":                    0.15,
+	"married_filing_jointly":    0.12,
+	"married_filing_separately": 0.15,
+	"head_of_household":         0.12,
+	"qualifying_widow":          0.12,
+}
+
+// ToSimulationParams pre-fills simulation inputs from a parsed tax document.
+// Only fields the document supports are set; everything else stays zero so
+// ApplyDefaults fills it when the simulation runs. CurrentAge is never set:
+// tax forms carry no birth date, so callers combine TaxYear with a known age.
+func ToSimulationParams(data *ExtractedTaxData) models.SimulationParams {
+	var params models.SimulationParams
+	if data == nil {
+		return params
+	}
+
+	// AGI from a 1040 is the best income figure; W-2 wages confirm income
+	// when only the wage statement was uploaded
+	income := 0.0
+	if data.AGI != nil {
+		income = *data.AGI
+	} else if data.WagesTips != nil {
+		income = *data.WagesTips
+	}
+	if income > 0 {
+		params.MonthlyContribution = income / 12 * defaultSavingsRate
+	}
+
+	if rate, ok := retirementTaxRates[data.FilingStatus]; ok {
+		params.RetirementTaxRate = rate
+	}
+
+	return params
+}
@@ -1,7 +1,8 @@
-import React from 'react';
+import React, { useState } from 'react';
 import Chart from 'react-apexcharts';
+import { useApi } from '../../hooks/useApi';
 
-// Render A2UI artifacts (charts, tables, metric cards)
+// Render A2UI artifacts (charts, tables, metric cards, generated documents)
 export default function ChatArtifact({ artifact }) {
   if (!artifact || !artifact.type) return null;
 
@@ -12,6 +13,8 @@ export default function ChatArtifact({ artifact }) {
       return <TableArtifact artifact={artifact} />;
     case 'metric_card':
       return <MetricCardArtifact artifact={artifact} />;
+    case 'document':
+      return <DocumentArtifact artifact={artifact} />;
     default:
       return null;
   }
@@ -164,6 +167,39 @@ function MetricCardArtifact({ artifact }) {
   );
 }
 
+// Document artifact: a report Aurelia saved to the vault, with a download button
+function DocumentArtifact({ artifact }) {
+  const { document_id, filename, size } = artifact;
+  const { downloadDocument } = useApi();
+  const [downloading, setDownloading] = useState(false);
+  const [error, setError] = useState(null);
+
+  const handleDownload = async () => {
+    setDownloading(true);
+    setError(null);
+    try {
+      await downloadDocument(document_id, filename);
+    } catch (err) {
+      setError(err.message);
+    } finally {
+      setDownloading(false);
+    }
+  };
+
+  return (
+    <div className="chat-artifact chat-artifact-document">
+      <div className="chat-document-info">
+        <div className="chat-document-name">{filename}</div>
+        {size > 0 && <div className="chat-document-size">{Math.max(1, Math.round(size / 1024))} KB</div>}
+        {error && <div className="chat-document-error">{error}</div>}
+      </div>
+      <button className="btn btn-secondary btn-sm" onClick={handleDownload} disabled={downloading}>
+        {downloading ? 'Downloading...' : 'Download'}
+      </button>
+    </div>
+  );
+}
+
 // Helper to format currency values
 function formatCurrency(value) {
   if (typeof value !== 'number') return value;
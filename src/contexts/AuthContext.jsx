@@ -4,6 +4,22 @@ const API_BASE_URL = import.meta.env.VITE_API_URL || 'http://localhost:8085';
 
 const AuthContext = createContext(null);
 
+// In-flight session refresh, shared so concurrent 401s rotate the refresh
+// token only once
+let refreshPromise = null;
+
+function storeSession(data) {
+  localStorage.setItem('token', data.token);
+  if (data.refreshToken) {
+    localStorage.setItem('refreshToken', data.refreshToken);
+  }
+}
+
+function clearSession() {
+  localStorage.removeItem('token');
+  localStorage.removeItem('refreshToken');
+}
+
 export function AuthProvider({ children }) {
   const [user, setUser] = useState(null);
   const [token, setToken] = useState(() => localStorage.getItem('token'));
@@ -30,15 +46,18 @@ export function AuthProvider({ children }) {
       if (response.ok) {
         const userData = await response.json();
         setUser(userData);
+      } else if (await refreshSession(token)) {
+        // The access token expired while the app was closed; refreshSession
+        // stored the new session and user
       } else {
         // Token is invalid, clear it
-        localStorage.removeItem('token');
+        clearSession();
         setToken(null);
         setUser(null);
       }
     } catch (err) {
       console.error('Failed to fetch user:', err);
-      localStorage.removeItem('token');
+      clearSession();
       setToken(null);
       setUser(null);
     } finally {
@@ -63,7 +82,7 @@ export function AuthProvider({ children }) {
         throw new Error(data.error || 'Login failed');
       }
 
-      localStorage.setItem('token', data.token);
+      storeSession(data);
       setToken(data.token);
       setUser(data.user);
       return data;
@@ -90,7 +109,7 @@ export function AuthProvider({ children }) {
         throw new Error(data.error || 'Registration failed');
       }
 
-      localStorage.setItem('token', data.token);
+      storeSession(data);
       setToken(data.token);
       setUser(data.user);
       return data;
@@ -101,11 +120,52 @@ export function AuthProvider({ children }) {
   }, []);
 
   const logout = useCallback(() => {
-    localStorage.removeItem('token');
+    clearSession();
     setToken(null);
     setUser(null);
   }, []);
 
+  // refreshSession trades the stored refresh token for a new access token and
+  // returns it, or null if the session can't be renewed. staleToken is the
+  // access token that was rejected; if another request has already replaced
+  // it, the current one is returned without refreshing again.
+  const refreshSession = useCallback((staleToken) => {
+    const current = localStorage.getItem('token');
+    if (current && current !== staleToken) {
+      return Promise.resolve(current);
+    }
+
+    if (!refreshPromise) {
+      refreshPromise = (async () => {
+        const refreshToken = localStorage.getItem('refreshToken');
+        if (!refreshToken) return null;
+
+        try {
+          const response = await fetch(`${API_BASE_URL}/api/auth/refresh`, {
+            method: 'POST',
+            headers: {
+              'Content-Type': 'application/json',
+            },
+            body: JSON.stringify({ refreshToken }),
+          });
+          if (!response.ok) return null;
+
+          const data = await response.json();
+          storeSession(data);
+          setToken(data.token);
+          setUser(data.user);
+          return data.token;
+        } catch (err) {
+          console.error('Failed to refresh session:', err);
+          return null;
+        }
+      })().finally(() => {
+        refreshPromise = null;
+      });
+    }
+    return refreshPromise;
+  }, []);
+
   // Role-based helpers
   const isAdvisor = user?.role === 'advisor';
   const isClient = user?.role === 'client' || (user && !user.role);
@@ -121,6 +181,7 @@ export function AuthProvider({ children }) {
     login,
     register,
     logout,
+    refreshSession,
   };
 
   return (
@@ -26,16 +26,26 @@ export function ClientProvider({ children }) {
 
     setLoadingClients(true);
     try {
-      const response = await fetch(`${API_BASE_URL}/api/advisor/clients`, {
-        headers: {
-          'Authorization': `Bearer ${token}`,
-        },
-      });
+      // Page through the cursor-paginated list so the switcher has every client
+      const allClients = [];
+      let cursor = null;
+      do {
+        const params = new URLSearchParams({ limit: '100' });
+        if (cursor) params.set('cursor', cursor);
+
+        const response = await fetch(`${API_BASE_URL}/api/advisor/clients?${params}`, {
+          headers: {
+            'Authorization': `Bearer ${token}`,
+          },
+        });
+        if (!response.ok) break;
 
-      if (response.ok) {
         const data = await response.json();
-        setClients(data || []);
-      }
+        allClients.push(...(data.clients || []));
+        cursor = data.next_cursor;
+      } while (cursor);
+
+      setClients(allClients);
     } catch (err) {
       console.error('Failed to fetch clients:', err);
     } finally {
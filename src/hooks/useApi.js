@@ -13,7 +13,7 @@ export function setClientContextRef(ctx) {
 export function useApi() {
   const [loading, setLoading] = useState(false);
   const [error, setError] = useState(null);
-  const { token, logout, isAdvisor } = useAuth();
+  const { token, logout, refreshSession, isAdvisor } = useAuth();
 
   // Try to get active client from context ref
   const getActiveClientId = () => {
@@ -58,6 +58,33 @@ export function useApi() {
     return endpoint;
   };
 
+  // authFetch sends a request with the access token. On a 401 it renews the
+  // session once (concurrent failures share one refresh) and retries; if the
+  // session can't be renewed the user is logged out.
+  const authFetch = useCallback(async (url, options = {}) => {
+    const send = (accessToken) => {
+      const headers = { ...options.headers };
+      if (accessToken) {
+        headers['Authorization'] = `Bearer ${accessToken}`;
+      }
+      return fetch(url, { ...options, headers });
+    };
+
+    let response = await send(token);
+    if (response.status === 401) {
+      const renewed = await refreshSession(token);
+      if (renewed) {
+        response = await send(renewed);
+      }
+    }
+
+    if (response.status === 401) {
+      logout();
+      throw new Error('Session expired. Please log in again.');
+    }
+    return response;
+  }, [token, refreshSession, logout]);
+
   const request = useCallback(async (endpoint, options = {}) => {
     // Apply client context transformation
     const contextualEndpoint = getContextualEndpoint(endpoint);
@@ -65,27 +92,14 @@ export function useApi() {
     setError(null);
 
     try {
-      const headers = {
-        'Content-Type': 'application/json',
-        ...options.headers,
-      };
-
-      // Add auth header if token exists
-      if (token) {
-        headers['Authorization'] = `Bearer ${token}`;
-      }
-
-      const response = await fetch(`${API_BASE_URL}${contextualEndpoint}`, {
+      const response = await authFetch(`${API_BASE_URL}${contextualEndpoint}`, {
         ...options,
-        headers,
+        headers: {
+          'Content-Type': 'application/json',
+          ...options.headers,
+        },
       });
 
-      if (response.status === 401) {
-        // Token is invalid or expired
-        logout();
-        throw new Error('Session expired. Please log in again.');
-      }
-
       if (!response.ok) {
         const errorData = await response.json().catch(() => ({}));
         throw new Error(errorData.error || `HTTP error ${response.status}`);
@@ -99,7 +113,7 @@ export function useApi() {
     } finally {
       setLoading(false);
     }
-  }, [token, logout]);
+  }, [authFetch]);
 
   // Assets API
   const getAssets = useCallback(() => request('/api/assets'), [request]);
@@ -150,24 +164,14 @@ export function useApi() {
   const generateReport = useCallback(async (options = {}) => {
     const contextualEndpoint = getContextualEndpoint('/api/reports/generate');
 
-    const headers = {
-      'Content-Type': 'application/json',
-    };
-    if (token) {
-      headers['Authorization'] = `Bearer ${token}`;
-    }
-
-    const response = await fetch(`${API_BASE_URL}${contextualEndpoint}`, {
+    const response = await authFetch(`${API_BASE_URL}${contextualEndpoint}`, {
       method: 'POST',
-      headers,
+      headers: {
+        'Content-Type': 'application/json',
+      },
       body: JSON.stringify(options),
     });
 
-    if (response.status === 401) {
-      logout();
-      throw new Error('Session expired. Please log in again.');
-    }
-
     if (!response.ok) {
       const errorData = await response.json().catch(() => ({}));
       throw new Error(errorData.error || `HTTP error ${response.status}`);
@@ -183,7 +187,7 @@ export function useApi() {
     }
 
     return { blob, filename };
-  }, [token, logout]);
+  }, [authFetch]);
 
   // CSV Import API
   const importCSV = useCallback(async (file, type) => {
@@ -195,22 +199,11 @@ export function useApi() {
       formData.append('file', file);
       formData.append('type', type);
 
-      const headers = {};
-      if (token) {
-        headers['Authorization'] = `Bearer ${token}`;
-      }
-
-      const response = await fetch(`${API_BASE_URL}/api/import/csv`, {
+      const response = await authFetch(`${API_BASE_URL}/api/import/csv`, {
         method: 'POST',
-        headers,
         body: formData,
       });
 
-      if (response.status === 401) {
-        logout();
-        throw new Error('Session expired. Please log in again.');
-      }
-
       if (!response.ok) {
         const errorData = await response.json().catch(() => ({}));
         throw new Error(errorData.error || `HTTP error ${response.status}`);
@@ -223,7 +216,7 @@ export function useApi() {
     } finally {
       setLoading(false);
     }
-  }, [token, logout]);
+  }, [authFetch]);
 
   // Plaid API
   const getPlaidStatus = useCallback(() => {
@@ -254,12 +247,23 @@ export function useApi() {
   }), [request]);
 
   // Transactions API
-  const getTransactions = useCallback((startDate, endDate, category) => {
+  // The endpoint is paginated; follow the cursor so charts get the full range
+  const getTransactions = useCallback(async (startDate, endDate, category) => {
     const params = new URLSearchParams();
     if (startDate) params.append('start_date', startDate);
     if (endDate) params.append('end_date', endDate);
     if (category) params.append('category', category);
-    return request(`/api/transactions?${params.toString()}`);
+    params.append('limit', '200');
+
+    const transactions = [];
+    let cursor = null;
+    do {
+      if (cursor) params.set('cursor', cursor);
+      const page = await request(`/api/transactions?${params.toString()}`);
+      transactions.push(...(page.items || []));
+      cursor = page.hasMore ? page.nextCursor : null;
+    } while (cursor);
+    return transactions;
   }, [request]);
 
   const getTransactionSummary = useCallback((startDate, endDate) => {
@@ -388,20 +392,11 @@ export function useApi() {
         if (metadata.year) formData.append('year', metadata.year.toString());
         if (metadata.clientId) formData.append('client_id', metadata.clientId.toString());
 
-        const headers = {};
-        if (token) headers['Authorization'] = `Bearer ${token}`;
-
-        const response = await fetch(`${API_BASE_URL}/api/documents/upload`, {
+        const response = await authFetch(`${API_BASE_URL}/api/documents/upload`, {
           method: 'POST',
-          headers,
           body: formData,
         });
 
-        if (response.status === 401) {
-          logout();
-          throw new Error('Session expired');
-        }
-
         if (!response.ok) {
           const errorData = await response.json().catch(() => ({}));
           throw new Error(errorData.error || `Upload failed`);
@@ -414,17 +409,9 @@ export function useApi() {
       } finally {
         setLoading(false);
       }
-    }, [token, logout]),
+    }, [authFetch]),
     downloadDocument: useCallback(async (docId, filename) => {
-      const headers = {};
-      if (token) headers['Authorization'] = `Bearer ${token}`;
-
-      const response = await fetch(`${API_BASE_URL}/api/documents/${docId}/download`, { headers });
-
-      if (response.status === 401) {
-        logout();
-        throw new Error('Session expired');
-      }
+      const response = await authFetch(`${API_BASE_URL}/api/documents/${docId}/download`);
 
       if (!response.ok) {
         throw new Error('Download failed');
@@ -439,7 +426,7 @@ export function useApi() {
       a.click();
       window.URL.revokeObjectURL(url);
       a.remove();
-    }, [token, logout]),
+    }, [authFetch]),
     deleteDocument: useCallback((docId) => request(`/api/documents/${docId}`, {
       method: 'DELETE',
     }), [request]),
@@ -8,6 +8,7 @@ export function useChat() {
   const [loading, setLoading] = useState(false);
   const [error, setError] = useState(null);
   const [artifacts, setArtifacts] = useState([]);
+  const [conversationId, setConversationId] = useState(null);
   const { token, logout } = useAuth();
 
   // Check if chat is configured
@@ -39,8 +40,9 @@ export function useChat() {
     setError(null);
 
     try {
-      // Build message history for context
-      const messageHistory = [...messages, userMessage].map(m => ({
+      // A saved conversation already has the history server-side; otherwise
+      // send everything so far to start one
+      const messageHistory = (conversationId ? [userMessage] : [...messages, userMessage]).map(m => ({
         role: m.role,
         content: m.content,
       }));
@@ -53,6 +55,7 @@ export function useChat() {
         },
         body: JSON.stringify({
           messages: messageHistory,
+          ...(conversationId && { conversationId }),
         }),
       });
 
@@ -67,6 +70,9 @@ export function useChat() {
       }
 
       const data = await response.json();
+      if (data.conversationId) {
+        setConversationId(data.conversationId);
+      }
 
       // Add assistant message to state
       const assistantMessage = {
@@ -104,13 +110,14 @@ export function useChat() {
     } finally {
       setLoading(false);
     }
-  }, [messages, token, logout]);
+  }, [messages, conversationId, token, logout]);
 
   // Clear conversation
   const clearMessages = useCallback(() => {
     setMessages([]);
     setArtifacts([]);
     setError(null);
+    setConversationId(null);
   }, []);
 
   // Get initial greeting message